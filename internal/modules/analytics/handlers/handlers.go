@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	analyticsmodels "github.com/hsrvms/autoparts/internal/modules/analytics/models"
+	"github.com/hsrvms/autoparts/internal/modules/analytics/services"
+	"github.com/labstack/echo/v4"
+)
+
+type AnalyticsHandler struct {
+	service services.AnalyticsService
+}
+
+func NewAnalyticsHandler(service services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: service,
+	}
+}
+
+// GetSalesAnalytics handles the sales time series with optional grouping and comparison
+func (h *AnalyticsHandler) GetSalesAnalytics(c echo.Context) error {
+	query := &analyticsmodels.SalesQuery{
+		Granularity: c.QueryParam("granularity"),
+		GroupBy:     c.QueryParam("group_by"),
+		Compare:     c.QueryParam("compare"),
+		Method:      c.QueryParam("method"),
+	}
+
+	var err error
+	if query.StartDate, err = optionalDate(c, "start_date"); err != nil {
+		return err
+	}
+	if query.EndDate, err = optionalDate(c, "end_date"); err != nil {
+		return err
+	}
+	if query.Limit, err = optionalInt(c, "limit"); err != nil {
+		return err
+	}
+
+	query.CategoryID = optionalID(c, "category_id")
+	query.SupplierID = optionalID(c, "supplier_id")
+	query.ItemID = optionalID(c, "item_id")
+
+	ctx := c.Request().Context()
+	result, err := h.service.GetSalesAnalytics(ctx, query)
+	if err != nil {
+		return analyticsError(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetTopSellers handles retrieval of the best selling items in a date range
+func (h *AnalyticsHandler) GetTopSellers(c echo.Context) error {
+	query, err := rankingQuery(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	items, err := h.service.GetTopSellers(ctx, query)
+	if err != nil {
+		return analyticsError(err)
+	}
+
+	return c.JSON(http.StatusOK, items)
+}
+
+// GetSlowMovers handles retrieval of stocked items that sold least in a date range
+func (h *AnalyticsHandler) GetSlowMovers(c echo.Context) error {
+	query, err := rankingQuery(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	items, err := h.service.GetSlowMovers(ctx, query)
+	if err != nil {
+		return analyticsError(err)
+	}
+
+	return c.JSON(http.StatusOK, items)
+}
+
+// RefreshSummaries handles an on-demand refresh of the analytics views
+func (h *AnalyticsHandler) RefreshSummaries(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.service.RefreshSummaries(ctx); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func rankingQuery(c echo.Context) (*analyticsmodels.RankingQuery, error) {
+	query := &analyticsmodels.RankingQuery{
+		RankBy: c.QueryParam("rank_by"),
+		Method: c.QueryParam("method"),
+	}
+
+	var err error
+	if query.StartDate, err = optionalDate(c, "start_date"); err != nil {
+		return nil, err
+	}
+	if query.EndDate, err = optionalDate(c, "end_date"); err != nil {
+		return nil, err
+	}
+	if query.Limit, err = optionalInt(c, "limit"); err != nil {
+		return nil, err
+	}
+
+	query.CategoryID = optionalID(c, "category_id")
+	query.SupplierID = optionalID(c, "supplier_id")
+
+	return query, nil
+}
+
+func analyticsError(err error) error {
+	switch err {
+	case services.ErrInvalidGranularity, services.ErrInvalidGroupBy, services.ErrInvalidCompare,
+		services.ErrInvalidRankBy, services.ErrInvalidDateRange, services.ErrTooManyPeriods,
+		services.ErrInvalidLimit, services.ErrInvalidMethod:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func optionalDate(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := parseDate(value)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name)
+	}
+	return date, nil
+}
+
+func optionalInt(c echo.Context, name string) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name)
+	}
+	return n, nil
+}
+
+func optionalID(c echo.Context, name string) *int {
+	if id, err := strconv.Atoi(c.QueryParam(name)); err == nil {
+		return &id
+	}
+	return nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
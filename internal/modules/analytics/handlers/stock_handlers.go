@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	analyticsmodels "github.com/hsrvms/autoparts/internal/modules/analytics/models"
+	"github.com/hsrvms/autoparts/internal/modules/analytics/services"
+	"github.com/labstack/echo/v4"
+)
+
+// GetDeadStock handles the dead and slow-moving stock report
+func (h *AnalyticsHandler) GetDeadStock(c echo.Context) error {
+	query := &analyticsmodels.DeadStockQuery{
+		StockFilter: stockFilter(c),
+		Status:      c.QueryParam("status"),
+	}
+
+	var err error
+	if query.DeadAfterDays, err = optionalInt(c, "dead_after_days"); err != nil {
+		return err
+	}
+	if query.LookbackDays, err = optionalInt(c, "lookback_days"); err != nil {
+		return err
+	}
+	if query.SlowCoverMonths, err = optionalFloat(c, "slow_cover_months"); err != nil {
+		return err
+	}
+	if query.MinCapital, err = optionalFloat(c, "min_capital"); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetDeadStock(ctx, query)
+	if err != nil {
+		return stockError(err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetClassification handles the ABC/XYZ classification of items
+func (h *AnalyticsHandler) GetClassification(c echo.Context) error {
+	query := &analyticsmodels.ClassificationQuery{
+		StockFilter: stockFilter(c),
+		Basis:       c.QueryParam("basis"),
+		Method:      c.QueryParam("method"),
+	}
+
+	var err error
+	if query.Months, err = optionalInt(c, "months"); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetClassification(ctx, query)
+	if err != nil {
+		return stockError(err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// ApplyStockAction handles bulk deactivation or clearance marking of items
+func (h *AnalyticsHandler) ApplyStockAction(c echo.Context) error {
+	var req analyticsmodels.StockActionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.service.ApplyStockAction(ctx, &req)
+	if err != nil {
+		return stockError(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func stockFilter(c echo.Context) analyticsmodels.StockFilter {
+	return analyticsmodels.StockFilter{
+		CategoryID:      optionalID(c, "category_id"),
+		SupplierID:      optionalID(c, "supplier_id"),
+		IncludeInactive: c.QueryParam("include_inactive") == "true",
+	}
+}
+
+func stockError(err error) error {
+	switch err {
+	case services.ErrInvalidStockStatus, services.ErrInvalidDeadAfter, services.ErrInvalidLookback,
+		services.ErrInvalidSlowCover, services.ErrInvalidMinCapital, services.ErrInvalidMonths,
+		services.ErrInvalidBasis, services.ErrInvalidStockAction, services.ErrInvalidItemID,
+		services.ErrNoItems, services.ErrTooManyItems, services.ErrInvalidMethod:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func optionalFloat(c echo.Context, name string) (float64, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name)
+	}
+	return n, nil
+}
@@ -0,0 +1,123 @@
+package analyticsmodels
+
+import "time"
+
+// Time-series granularities
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// Series groupings
+const (
+	GroupByNone        = "none"
+	GroupByItem        = "item"
+	GroupByCategory    = "category"
+	GroupBySupplier    = "supplier"
+	GroupBySalesperson = "salesperson"
+)
+
+// Comparison windows
+const (
+	CompareNone           = "none"
+	ComparePreviousPeriod = "previous_period"
+	ComparePreviousYear   = "previous_year"
+)
+
+// Costing methods, matching the cost columns of the daily summary
+const (
+	MethodFIFO    = "fifo"
+	MethodAverage = "average"
+)
+
+// Ranking measures for top sellers
+const (
+	RankByRevenue = "revenue"
+	RankByUnits   = "units"
+	RankByProfit  = "profit"
+)
+
+type SalesQuery struct {
+	StartDate   time.Time `query:"start_date"`
+	EndDate     time.Time `query:"end_date"`
+	Granularity string    `query:"granularity"`
+	GroupBy     string    `query:"group_by"`
+	Compare     string    `query:"compare"`
+	CategoryID  *int      `query:"category_id"`
+	SupplierID  *int      `query:"supplier_id"`
+	ItemID      *int      `query:"item_id"`
+	Method      string    `query:"method"`
+	Limit       int       `query:"limit"`
+}
+
+// SalesBucket is one aggregated row: a group within a period
+type SalesBucket struct {
+	PeriodStart time.Time `json:"period_start" db:"period_start"`
+	GroupKey    string    `json:"-" db:"group_key"`
+	GroupLabel  string    `json:"-" db:"group_label"`
+	SaleCount   int       `json:"sale_count" db:"sale_count"`
+	Units       int       `json:"units" db:"units"`
+	Revenue     float64   `json:"revenue" db:"revenue"`
+	GrossProfit float64   `json:"gross_profit" db:"gross_profit"`
+}
+
+type SalesTotals struct {
+	SaleCount   int     `json:"sale_count"`
+	Units       int     `json:"units"`
+	Revenue     float64 `json:"revenue"`
+	GrossProfit float64 `json:"gross_profit"`
+}
+
+// Comparison holds the same measures for the comparison window and the
+// percentage change from it. Changes are nil when the baseline is zero.
+type Comparison struct {
+	StartDate            time.Time    `json:"start_date"`
+	EndDate              time.Time    `json:"end_date"`
+	Totals               *SalesTotals `json:"totals"`
+	RevenueChangePct     *float64     `json:"revenue_change_pct"`
+	UnitsChangePct       *float64     `json:"units_change_pct"`
+	GrossProfitChangePct *float64     `json:"gross_profit_change_pct"`
+}
+
+type SalesSeries struct {
+	Key        string         `json:"key"`
+	Label      string         `json:"label"`
+	Points     []*SalesBucket `json:"points"`
+	Totals     *SalesTotals   `json:"totals"`
+	Comparison *Comparison    `json:"comparison,omitempty"`
+}
+
+type SalesAnalytics struct {
+	StartDate   time.Time      `json:"start_date"`
+	EndDate     time.Time      `json:"end_date"`
+	Granularity string         `json:"granularity"`
+	GroupBy     string         `json:"group_by"`
+	Method      string         `json:"method"`
+	Series      []*SalesSeries `json:"series"`
+	Totals      *SalesTotals   `json:"totals"`
+	Comparison  *Comparison    `json:"comparison,omitempty"`
+}
+
+type ItemRanking struct {
+	ItemID       int        `json:"item_id" db:"item_id"`
+	PartNumber   string     `json:"part_number" db:"part_number"`
+	Description  string     `json:"description" db:"description"`
+	CategoryName *string    `json:"category_name,omitempty" db:"category_name"`
+	CurrentStock int        `json:"current_stock" db:"current_stock"`
+	SaleCount    int        `json:"sale_count" db:"sale_count"`
+	Units        int        `json:"units" db:"units"`
+	Revenue      float64    `json:"revenue" db:"revenue"`
+	GrossProfit  float64    `json:"gross_profit" db:"gross_profit"`
+	LastSaleDate *time.Time `json:"last_sale_date,omitempty" db:"last_sale_date"`
+}
+
+type RankingQuery struct {
+	StartDate  time.Time `query:"start_date"`
+	EndDate    time.Time `query:"end_date"`
+	RankBy     string    `query:"rank_by"`
+	CategoryID *int      `query:"category_id"`
+	SupplierID *int      `query:"supplier_id"`
+	Method     string    `query:"method"`
+	Limit      int       `query:"limit"`
+}
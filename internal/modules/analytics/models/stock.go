@@ -0,0 +1,158 @@
+package analyticsmodels
+
+import "time"
+
+// Stock health statuses in the dead-stock report
+const (
+	StockStatusDead    = "dead"
+	StockStatusSlow    = "slow"
+	StockStatusHealthy = "healthy"
+	StockStatusAtRisk  = "at_risk" // Filter only: dead or slow
+	StockStatusAll     = "all"     // Filter only
+)
+
+// ABC classification bases
+const (
+	ClassifyByRevenue = "revenue"
+	ClassifyByProfit  = "profit"
+	ClassifyByUnits   = "units"
+)
+
+// Bulk actions on items picked from the stock reports
+const (
+	StockActionDeactivate      = "deactivate"
+	StockActionActivate        = "activate"
+	StockActionClearance       = "clearance"
+	StockActionRemoveClearance = "remove_clearance"
+)
+
+type StockFilter struct {
+	CategoryID      *int `query:"category_id"`
+	SupplierID      *int `query:"supplier_id"`
+	IncludeInactive bool `query:"include_inactive"`
+}
+
+// StockProfile is an item's stock position together with its sales and
+// purchase history, the raw material for the dead-stock report.
+type StockProfile struct {
+	ItemID           int        `db:"item_id"`
+	PartNumber       string     `db:"part_number"`
+	Description      string     `db:"description"`
+	CategoryName     *string    `db:"category_name"`
+	SupplierName     *string    `db:"supplier_name"`
+	CurrentStock     int        `db:"current_stock"`
+	IsActive         bool       `db:"is_active"`
+	IsClearance      bool       `db:"is_clearance"`
+	LastSaleDate     *time.Time `db:"last_sale_date"`
+	LastPurchaseDate *time.Time `db:"last_purchase_date"`
+	UnitsSold        int        `db:"units_sold"`
+	TiedUpCapital    float64    `db:"tied_up_capital"`
+	CreatedAt        time.Time  `db:"created_at"`
+}
+
+type DeadStockQuery struct {
+	StockFilter
+	Status          string  `query:"status"`
+	DeadAfterDays   int     `query:"dead_after_days"`
+	SlowCoverMonths float64 `query:"slow_cover_months"`
+	LookbackDays    int     `query:"lookback_days"`
+	MinCapital      float64 `query:"min_capital"`
+}
+
+type DeadStockItem struct {
+	ItemID            int        `json:"item_id"`
+	PartNumber        string     `json:"part_number"`
+	Description       string     `json:"description"`
+	CategoryName      *string    `json:"category_name,omitempty"`
+	SupplierName      *string    `json:"supplier_name,omitempty"`
+	CurrentStock      int        `json:"current_stock"`
+	IsActive          bool       `json:"is_active"`
+	IsClearance       bool       `json:"is_clearance"`
+	Status            string     `json:"status"`
+	LastSaleDate      *time.Time `json:"last_sale_date,omitempty"`
+	LastPurchaseDate  *time.Time `json:"last_purchase_date,omitempty"`
+	DaysSinceLastSale *int       `json:"days_since_last_sale"`
+	IdleDays          int        `json:"idle_days"`
+	UnitsSold         int        `json:"units_sold"`
+	MonthlyDemand     float64    `json:"monthly_demand"`
+	MonthsOfCover     *float64   `json:"months_of_cover"`
+	TiedUpCapital     float64    `json:"tied_up_capital"`
+}
+
+type StockStatusTotals struct {
+	Items         int     `json:"items"`
+	Units         int     `json:"units"`
+	TiedUpCapital float64 `json:"tied_up_capital"`
+}
+
+type DeadStockReport struct {
+	AsOf            time.Time                     `json:"as_of"`
+	DeadAfterDays   int                           `json:"dead_after_days"`
+	SlowCoverMonths float64                       `json:"slow_cover_months"`
+	LookbackDays    int                           `json:"lookback_days"`
+	Items           []*DeadStockItem              `json:"items"`
+	Totals          map[string]*StockStatusTotals `json:"totals"`
+}
+
+type ClassificationQuery struct {
+	StockFilter
+	Months int    `query:"months"`
+	Basis  string `query:"basis"`
+	Method string `query:"method"`
+}
+
+// MonthlyDemand is one item's sales in one calendar month
+type MonthlyDemand struct {
+	ItemID      int       `db:"item_id"`
+	Month       time.Time `db:"month"`
+	Units       int       `db:"units"`
+	Revenue     float64   `db:"revenue"`
+	GrossProfit float64   `db:"gross_profit"`
+}
+
+type ItemClassification struct {
+	ItemID        int      `json:"item_id"`
+	PartNumber    string   `json:"part_number"`
+	Description   string   `json:"description"`
+	CategoryName  *string  `json:"category_name,omitempty"`
+	SupplierName  *string  `json:"supplier_name,omitempty"`
+	CurrentStock  int      `json:"current_stock"`
+	TiedUpCapital float64  `json:"tied_up_capital"`
+	Units         int      `json:"units"`
+	Revenue       float64  `json:"revenue"`
+	GrossProfit   float64  `json:"gross_profit"`
+	SharePct      float64  `json:"share_pct"`
+	CumulativePct float64  `json:"cumulative_pct"`
+	DemandCV      *float64 `json:"demand_cv"`
+	ABC           string   `json:"abc"`
+	XYZ           string   `json:"xyz"`
+	Class         string   `json:"class"`
+}
+
+type ClassificationCell struct {
+	Items         int     `json:"items"`
+	Value         float64 `json:"value"`
+	TiedUpCapital float64 `json:"tied_up_capital"`
+}
+
+type ClassificationReport struct {
+	StartDate  time.Time                      `json:"start_date"`
+	EndDate    time.Time                      `json:"end_date"`
+	Months     int                            `json:"months"`
+	Basis      string                         `json:"basis"`
+	Method     string                         `json:"method"`
+	Thresholds map[string]float64             `json:"thresholds"`
+	Items      []*ItemClassification          `json:"items"`
+	Matrix     map[string]*ClassificationCell `json:"matrix"`
+}
+
+type StockActionRequest struct {
+	Action  string `json:"action"`
+	ItemIDs []int  `json:"item_ids"`
+}
+
+type StockActionResult struct {
+	Action   string `json:"action"`
+	Updated  []int  `json:"updated"`
+	NotFound []int  `json:"not_found"`
+}
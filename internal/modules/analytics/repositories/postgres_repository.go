@@ -0,0 +1,270 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	analyticsmodels "github.com/hsrvms/autoparts/internal/modules/analytics/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+)
+
+type PostgresAnalyticsRepository struct {
+	db *db.Database
+}
+
+func NewPostgresAnalyticsRepository(database *db.Database) AnalyticsRepository {
+	return &PostgresAnalyticsRepository{
+		db: database,
+	}
+}
+
+// dailySales yields per-day totals for $1..$2 (inclusive dates), costed by
+// the given method. Completed days come from the sales_daily_summary
+// materialised view; anything after the last day it holds is aggregated live
+// from sales, so results are current even when the view has not been
+// refreshed yet.
+func dailySales(method string) string {
+	costColumn := analyticsmodels.MethodFIFO
+	if method == analyticsmodels.MethodAverage {
+		costColumn = analyticsmodels.MethodAverage
+	}
+
+	return fmt.Sprintf(`
+	WITH daily AS (
+		SELECT day, item_id, sold_by, sale_count, quantity, revenue, cost_%[1]s as cost
+		FROM sales_daily_summary
+		WHERE day >= $1::date AND day <= $2::date
+		UNION ALL
+		SELECT
+			date_trunc('day', s.date)::date, s.item_id, COALESCE(s.sold_by, ''),
+			COUNT(*), SUM(s.quantity), SUM(s.total_price),
+			SUM(COALESCE(s.cogs_%[1]s, s.quantity * i.buy_price))
+		FROM sales s
+		JOIN items i ON s.item_id = i.item_id
+		WHERE s.date >= GREATEST($1::date, (
+			SELECT COALESCE(MAX(day) + 1, '-infinity'::date) FROM sales_daily_summary
+		))
+		AND s.date < $2::date + 1
+		GROUP BY 1, 2, 3
+	)
+`, costColumn)
+}
+
+// Grouping key and label for each series grouping
+var salesGroups = map[string][2]string{
+	analyticsmodels.GroupByNone:        {"''", "'All sales'"},
+	analyticsmodels.GroupByItem:        {"i.item_id::text", "i.part_number || ' - ' || i.description"},
+	analyticsmodels.GroupByCategory:    {"COALESCE(i.category_id::text, '')", "COALESCE(c.category_name, 'Uncategorized')"},
+	analyticsmodels.GroupBySupplier:    {"COALESCE(i.supplier_id::text, '')", "COALESCE(sp.name, 'No supplier')"},
+	analyticsmodels.GroupBySalesperson: {"d.sold_by", "COALESCE(NULLIF(d.sold_by, ''), 'Unassigned')"},
+}
+
+// categoryTree matches a category and all of its descendants
+const categoryTree = `
+	i.category_id IN (
+		WITH RECURSIVE tree AS (
+			SELECT category_id FROM categories WHERE category_id = $%d
+			UNION ALL
+			SELECT ch.category_id FROM categories ch
+			JOIN tree t ON ch.parent_category_id = t.category_id
+		)
+		SELECT category_id FROM tree
+	)`
+
+// GetSalesBuckets aggregates sales between start and end into periods of the
+// given granularity and the query's grouping. An empty granularity collapses
+// the whole window into a single period.
+func (r *PostgresAnalyticsRepository) GetSalesBuckets(ctx context.Context, query *analyticsmodels.SalesQuery, start, end time.Time, granularity string) ([]*analyticsmodels.SalesBucket, error) {
+	group, ok := salesGroups[query.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported grouping %q", query.GroupBy)
+	}
+
+	periodExpr := "$1::date"
+	switch granularity {
+	case "":
+	case analyticsmodels.GranularityDay, analyticsmodels.GranularityWeek, analyticsmodels.GranularityMonth:
+		periodExpr = fmt.Sprintf("date_trunc('%s', d.day)::date", granularity)
+	default:
+		return nil, fmt.Errorf("unsupported granularity %q", granularity)
+	}
+
+	sql := dailySales(query.Method) + fmt.Sprintf(`
+		SELECT
+			%s as period_start,
+			%s as group_key,
+			%s as group_label,
+			SUM(d.sale_count)::int as sale_count,
+			SUM(d.quantity)::int as units,
+			SUM(d.revenue) as revenue,
+			SUM(d.revenue - d.cost) as gross_profit
+		FROM daily d
+		JOIN items i ON d.item_id = i.item_id
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		LEFT JOIN suppliers sp ON i.supplier_id = sp.supplier_id
+		WHERE 1=1
+	`, periodExpr, group[0], group[1])
+
+	params := []interface{}{start, end}
+	paramCount := 3
+
+	if query.CategoryID != nil {
+		sql += " AND " + fmt.Sprintf(categoryTree, paramCount)
+		params = append(params, *query.CategoryID)
+		paramCount++
+	}
+
+	if query.SupplierID != nil {
+		sql += fmt.Sprintf(" AND i.supplier_id = $%d", paramCount)
+		params = append(params, *query.SupplierID)
+		paramCount++
+	}
+
+	if query.ItemID != nil {
+		sql += fmt.Sprintf(" AND i.item_id = $%d", paramCount)
+		params = append(params, *query.ItemID)
+		paramCount++
+	}
+
+	sql += " GROUP BY 1, 2, 3 ORDER BY 1, 6 DESC"
+
+	rows, err := r.db.Pool.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []*analyticsmodels.SalesBucket
+	for rows.Next() {
+		bucket := &analyticsmodels.SalesBucket{}
+		err := rows.Scan(
+			&bucket.PeriodStart,
+			&bucket.GroupKey,
+			&bucket.GroupLabel,
+			&bucket.SaleCount,
+			&bucket.Units,
+			&bucket.Revenue,
+			&bucket.GrossProfit,
+		)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+var rankOrder = map[string]string{
+	analyticsmodels.RankByRevenue: "revenue DESC",
+	analyticsmodels.RankByUnits:   "units DESC",
+	analyticsmodels.RankByProfit:  "gross_profit DESC",
+}
+
+func (r *PostgresAnalyticsRepository) GetTopSellers(ctx context.Context, query *analyticsmodels.RankingQuery) ([]*analyticsmodels.ItemRanking, error) {
+	order, ok := rankOrder[query.RankBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ranking %q", query.RankBy)
+	}
+
+	sql := dailySales(query.Method) + `
+		SELECT
+			i.item_id, i.part_number, i.description, c.category_name, i.current_stock,
+			SUM(d.sale_count)::int as sale_count,
+			SUM(d.quantity)::int as units,
+			SUM(d.revenue) as revenue,
+			SUM(d.revenue - d.cost) as gross_profit,
+			MAX(d.day)::timestamptz as last_sale_date
+		FROM daily d
+		JOIN items i ON d.item_id = i.item_id
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		WHERE 1=1
+	`
+
+	sql, params := r.rankingFilters(sql, query)
+	sql += fmt.Sprintf(" GROUP BY i.item_id, c.category_name ORDER BY %s, i.part_number LIMIT $%d", order, len(params)+1)
+	params = append(params, query.Limit)
+
+	return r.queryRankings(ctx, sql, params)
+}
+
+// GetSlowMovers lists active items holding stock that sold least in the
+// window, including items that did not sell at all.
+func (r *PostgresAnalyticsRepository) GetSlowMovers(ctx context.Context, query *analyticsmodels.RankingQuery) ([]*analyticsmodels.ItemRanking, error) {
+	sql := dailySales(query.Method) + `
+		SELECT
+			i.item_id, i.part_number, i.description, c.category_name, i.current_stock,
+			COALESCE(SUM(d.sale_count), 0)::int as sale_count,
+			COALESCE(SUM(d.quantity), 0)::int as units,
+			COALESCE(SUM(d.revenue), 0) as revenue,
+			COALESCE(SUM(d.revenue - d.cost), 0) as gross_profit,
+			(SELECT MAX(s.date) FROM sales s WHERE s.item_id = i.item_id) as last_sale_date
+		FROM items i
+		LEFT JOIN daily d ON d.item_id = i.item_id
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		WHERE i.is_active = TRUE AND i.current_stock > 0
+	`
+
+	sql, params := r.rankingFilters(sql, query)
+	sql += fmt.Sprintf(" GROUP BY i.item_id, c.category_name ORDER BY units ASC, i.current_stock DESC, i.part_number LIMIT $%d", len(params)+1)
+	params = append(params, query.Limit)
+
+	return r.queryRankings(ctx, sql, params)
+}
+
+func (r *PostgresAnalyticsRepository) rankingFilters(sql string, query *analyticsmodels.RankingQuery) (string, []interface{}) {
+	params := []interface{}{query.StartDate, query.EndDate}
+	paramCount := 3
+
+	if query.CategoryID != nil {
+		sql += " AND " + fmt.Sprintf(categoryTree, paramCount)
+		params = append(params, *query.CategoryID)
+		paramCount++
+	}
+
+	if query.SupplierID != nil {
+		sql += fmt.Sprintf(" AND i.supplier_id = $%d", paramCount)
+		params = append(params, *query.SupplierID)
+		paramCount++
+	}
+
+	return sql, params
+}
+
+func (r *PostgresAnalyticsRepository) queryRankings(ctx context.Context, sql string, params []interface{}) ([]*analyticsmodels.ItemRanking, error) {
+	rows, err := r.db.Pool.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*analyticsmodels.ItemRanking
+	for rows.Next() {
+		item := &analyticsmodels.ItemRanking{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.Description,
+			&item.CategoryName,
+			&item.CurrentStock,
+			&item.SaleCount,
+			&item.Units,
+			&item.Revenue,
+			&item.GrossProfit,
+			&item.LastSaleDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// RefreshSalesSummary rebuilds the daily summary without blocking readers
+func (r *PostgresAnalyticsRepository) RefreshSalesSummary(ctx context.Context) error {
+	_, err := r.db.Pool.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY sales_daily_summary`)
+	return err
+}
@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	analyticsmodels "github.com/hsrvms/autoparts/internal/modules/analytics/models"
+)
+
+// tiedUpCapital values an item's stock at the cost of its open FIFO layers.
+// Units not covered by a layer fall back to the average cost, then buy price.
+const tiedUpCapital = `
+	COALESCE(l.remaining_value, 0) +
+	GREATEST(i.current_stock - COALESCE(l.remaining_units, 0), 0) * COALESCE(i.average_cost, i.buy_price)
+`
+
+// GetStockProfiles returns every item matching the filter with its last sale
+// and purchase dates, units sold since the given date and the capital tied up
+// in its current stock.
+func (r *PostgresAnalyticsRepository) GetStockProfiles(ctx context.Context, filter *analyticsmodels.StockFilter, since time.Time) ([]*analyticsmodels.StockProfile, error) {
+	sql := `
+		WITH sold AS (
+			SELECT
+				item_id,
+				MAX(date) as last_sale_date,
+				COALESCE(SUM(quantity) FILTER (WHERE date >= $1), 0) as units_sold
+			FROM sales
+			GROUP BY item_id
+		),
+		purchased AS (
+			SELECT item_id, MAX(date) as last_purchase_date
+			FROM purchases
+			GROUP BY item_id
+		),
+		layers AS (
+			SELECT
+				item_id,
+				SUM(remaining_quantity) as remaining_units,
+				SUM(remaining_quantity * unit_cost) as remaining_value
+			FROM cost_layers
+			WHERE remaining_quantity > 0
+			GROUP BY item_id
+		)
+		SELECT
+			i.item_id, i.part_number, i.description, c.category_name, sp.name,
+			i.current_stock, COALESCE(i.is_active, FALSE), i.is_clearance,
+			sd.last_sale_date, p.last_purchase_date,
+			COALESCE(sd.units_sold, 0)::int,
+			` + tiedUpCapital + ` as tied_up_capital,
+			i.created_at
+		FROM items i
+		LEFT JOIN sold sd ON sd.item_id = i.item_id
+		LEFT JOIN purchased p ON p.item_id = i.item_id
+		LEFT JOIN layers l ON l.item_id = i.item_id
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		LEFT JOIN suppliers sp ON i.supplier_id = sp.supplier_id
+		WHERE 1=1
+	`
+
+	sql, params := stockFilters(sql, filter, []interface{}{since})
+	sql += " ORDER BY i.part_number"
+
+	rows, err := r.db.Pool.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*analyticsmodels.StockProfile
+	for rows.Next() {
+		profile := &analyticsmodels.StockProfile{}
+		err := rows.Scan(
+			&profile.ItemID,
+			&profile.PartNumber,
+			&profile.Description,
+			&profile.CategoryName,
+			&profile.SupplierName,
+			&profile.CurrentStock,
+			&profile.IsActive,
+			&profile.IsClearance,
+			&profile.LastSaleDate,
+			&profile.LastPurchaseDate,
+			&profile.UnitsSold,
+			&profile.TiedUpCapital,
+			&profile.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// GetMonthlyDemand returns units, revenue and gross profit per item and
+// calendar month for $1..$2 (inclusive dates), costed by the given method.
+// Months without sales are omitted.
+func (r *PostgresAnalyticsRepository) GetMonthlyDemand(ctx context.Context, filter *analyticsmodels.StockFilter, start, end time.Time, method string) ([]*analyticsmodels.MonthlyDemand, error) {
+	sql := dailySales(method) + `
+		SELECT
+			d.item_id,
+			date_trunc('month', d.day)::date as month,
+			SUM(d.quantity)::int as units,
+			SUM(d.revenue) as revenue,
+			SUM(d.revenue - d.cost) as gross_profit
+		FROM daily d
+		JOIN items i ON d.item_id = i.item_id
+		WHERE 1=1
+	`
+
+	sql, params := stockFilters(sql, filter, []interface{}{start, end})
+	sql += " GROUP BY 1, 2 ORDER BY 1, 2"
+
+	rows, err := r.db.Pool.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var demand []*analyticsmodels.MonthlyDemand
+	for rows.Next() {
+		month := &analyticsmodels.MonthlyDemand{}
+		err := rows.Scan(
+			&month.ItemID,
+			&month.Month,
+			&month.Units,
+			&month.Revenue,
+			&month.GrossProfit,
+		)
+		if err != nil {
+			return nil, err
+		}
+		demand = append(demand, month)
+	}
+
+	return demand, rows.Err()
+}
+
+// Column assignment for each bulk stock action
+var stockActions = map[string]string{
+	analyticsmodels.StockActionDeactivate:      "is_active = FALSE",
+	analyticsmodels.StockActionActivate:        "is_active = TRUE",
+	analyticsmodels.StockActionClearance:       "is_clearance = TRUE",
+	analyticsmodels.StockActionRemoveClearance: "is_clearance = FALSE",
+}
+
+// ApplyStockAction updates the given items and returns the IDs that exist
+func (r *PostgresAnalyticsRepository) ApplyStockAction(ctx context.Context, action string, itemIDs []int) ([]int, error) {
+	assignment, ok := stockActions[action]
+	if !ok {
+		return nil, fmt.Errorf("unsupported stock action %q", action)
+	}
+
+	sql := fmt.Sprintf(`
+		UPDATE items SET %s, updated_at = CURRENT_TIMESTAMP
+		WHERE item_id = ANY($1)
+		RETURNING item_id
+	`, assignment)
+
+	rows, err := r.db.Pool.Query(ctx, sql, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var updated []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		updated = append(updated, id)
+	}
+
+	return updated, rows.Err()
+}
+
+func stockFilters(sql string, filter *analyticsmodels.StockFilter, params []interface{}) (string, []interface{}) {
+	paramCount := len(params) + 1
+
+	if !filter.IncludeInactive {
+		sql += " AND i.is_active = TRUE"
+	}
+
+	if filter.CategoryID != nil {
+		sql += " AND " + fmt.Sprintf(categoryTree, paramCount)
+		params = append(params, *filter.CategoryID)
+		paramCount++
+	}
+
+	if filter.SupplierID != nil {
+		sql += fmt.Sprintf(" AND i.supplier_id = $%d", paramCount)
+		params = append(params, *filter.SupplierID)
+		paramCount++
+	}
+
+	return sql, params
+}
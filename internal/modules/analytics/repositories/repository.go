@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	analyticsmodels "github.com/hsrvms/autoparts/internal/modules/analytics/models"
+)
+
+type AnalyticsRepository interface {
+	GetSalesBuckets(ctx context.Context, query *analyticsmodels.SalesQuery, start, end time.Time, granularity string) ([]*analyticsmodels.SalesBucket, error)
+	GetTopSellers(ctx context.Context, query *analyticsmodels.RankingQuery) ([]*analyticsmodels.ItemRanking, error)
+	GetSlowMovers(ctx context.Context, query *analyticsmodels.RankingQuery) ([]*analyticsmodels.ItemRanking, error)
+	RefreshSalesSummary(ctx context.Context) error
+
+	// Stock health
+	GetStockProfiles(ctx context.Context, filter *analyticsmodels.StockFilter, since time.Time) ([]*analyticsmodels.StockProfile, error)
+	GetMonthlyDemand(ctx context.Context, filter *analyticsmodels.StockFilter, start, end time.Time, method string) ([]*analyticsmodels.MonthlyDemand, error)
+	ApplyStockAction(ctx context.Context, action string, itemIDs []int) ([]int, error)
+}
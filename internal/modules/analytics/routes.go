@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/hsrvms/autoparts/internal/modules/analytics/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/analytics/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/analytics/services"
+	"github.com/hsrvms/autoparts/pkg/config"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/scheduler"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database, cfg config.InventoryConfig) {
+	// Initialize repository
+	repo := repositories.NewPostgresAnalyticsRepository(database)
+
+	// Initialize service
+	service := services.NewAnalyticsService(repo, cfg.CostingMethod)
+
+	// Initialize handler
+	handler := handlers.NewAnalyticsHandler(service)
+
+	// Register routes
+	analytics := api.Group("/analytics")
+	analytics.GET("/sales", handler.GetSalesAnalytics)
+	analytics.GET("/top-sellers", handler.GetTopSellers)
+	analytics.GET("/slow-movers", handler.GetSlowMovers)
+	analytics.POST("/refresh", handler.RefreshSummaries)
+	analytics.GET("/dead-stock", handler.GetDeadStock)
+	analytics.POST("/dead-stock/actions", handler.ApplyStockAction)
+	analytics.GET("/abc-xyz", handler.GetClassification)
+}
+
+// RegisterJobs schedules the refresh of the analytics materialised views
+func RegisterJobs(s *scheduler.Scheduler, database *db.Database, cfg config.JobsConfig) {
+	service := services.NewAnalyticsService(repositories.NewPostgresAnalyticsRepository(database), "")
+
+	s.Every("analytics-refresh", cfg.AnalyticsRefreshInterval, func(ctx context.Context) error {
+		return service.RefreshSummaries(ctx)
+	})
+}
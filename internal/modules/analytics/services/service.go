@@ -0,0 +1,458 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	analyticsmodels "github.com/hsrvms/autoparts/internal/modules/analytics/models"
+	"github.com/hsrvms/autoparts/internal/modules/analytics/repositories"
+)
+
+const (
+	DefaultSeriesLimit  = 10
+	DefaultRankingLimit = 20
+	DefaultRankingDays  = 90
+	maxLimit            = 500
+	maxPeriods          = 1000
+	otherSeriesKey      = "other"
+)
+
+var (
+	ErrInvalidGranularity = errors.New("granularity must be day, week or month")
+	ErrInvalidGroupBy     = errors.New("group_by must be none, item, category, supplier or salesperson")
+	ErrInvalidCompare     = errors.New("compare must be none, previous_period or previous_year")
+	ErrInvalidRankBy      = errors.New("rank_by must be revenue, units or profit")
+	ErrInvalidDateRange   = errors.New("start date must not be after end date")
+	ErrTooManyPeriods     = errors.New("date range has too many periods for this granularity")
+	ErrInvalidLimit       = errors.New("limit must be between 1 and 500")
+	ErrInvalidMethod      = errors.New("costing method must be 'fifo' or 'average'")
+)
+
+type AnalyticsService interface {
+	GetSalesAnalytics(ctx context.Context, query *analyticsmodels.SalesQuery) (*analyticsmodels.SalesAnalytics, error)
+	GetTopSellers(ctx context.Context, query *analyticsmodels.RankingQuery) ([]*analyticsmodels.ItemRanking, error)
+	GetSlowMovers(ctx context.Context, query *analyticsmodels.RankingQuery) ([]*analyticsmodels.ItemRanking, error)
+	RefreshSummaries(ctx context.Context) error
+
+	// Stock health
+	GetDeadStock(ctx context.Context, query *analyticsmodels.DeadStockQuery) (*analyticsmodels.DeadStockReport, error)
+	GetClassification(ctx context.Context, query *analyticsmodels.ClassificationQuery) (*analyticsmodels.ClassificationReport, error)
+	ApplyStockAction(ctx context.Context, req *analyticsmodels.StockActionRequest) (*analyticsmodels.StockActionResult, error)
+}
+
+type analyticsService struct {
+	repo          repositories.AnalyticsRepository
+	defaultMethod string
+}
+
+func NewAnalyticsService(repo repositories.AnalyticsRepository, defaultMethod string) AnalyticsService {
+	if defaultMethod != analyticsmodels.MethodAverage {
+		defaultMethod = analyticsmodels.MethodFIFO
+	}
+
+	return &analyticsService{
+		repo:          repo,
+		defaultMethod: defaultMethod,
+	}
+}
+
+// GetSalesAnalytics returns revenue, units and gross profit per period, one
+// series per group. Groups beyond the limit are folded into an "Other"
+// series, and every series has a point for every period so it can be charted
+// directly.
+func (s *analyticsService) GetSalesAnalytics(ctx context.Context, query *analyticsmodels.SalesQuery) (*analyticsmodels.SalesAnalytics, error) {
+	if err := normalizeSalesQuery(query, s.defaultMethod); err != nil {
+		return nil, err
+	}
+
+	periods := periodStarts(query.StartDate, query.EndDate, query.Granularity)
+	if len(periods) > maxPeriods {
+		return nil, ErrTooManyPeriods
+	}
+
+	buckets, err := s.repo.GetSalesBuckets(ctx, query, query.StartDate, query.EndDate, query.Granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &analyticsmodels.SalesAnalytics{
+		StartDate:   query.StartDate,
+		EndDate:     query.EndDate,
+		Granularity: query.Granularity,
+		GroupBy:     query.GroupBy,
+		Method:      query.Method,
+		Series:      buildSeries(buckets, periods, query.Limit),
+		Totals:      &analyticsmodels.SalesTotals{},
+	}
+	for _, series := range result.Series {
+		addTotals(result.Totals, series.Totals)
+	}
+
+	if query.Compare == analyticsmodels.CompareNone {
+		return result, nil
+	}
+
+	prevStart, prevEnd := comparisonWindow(query.StartDate, query.EndDate, query.Compare)
+	prevBuckets, err := s.repo.GetSalesBuckets(ctx, query, prevStart, prevEnd, "")
+	if err != nil {
+		return nil, err
+	}
+
+	prevByGroup := make(map[string]*analyticsmodels.SalesTotals)
+	prevTotal := &analyticsmodels.SalesTotals{}
+	for _, b := range prevBuckets {
+		totals := bucketTotals(b)
+		prevByGroup[b.GroupKey] = totals
+		addTotals(prevTotal, totals)
+	}
+
+	result.Comparison = compare(prevStart, prevEnd, result.Totals, prevTotal)
+
+	var otherPrev *analyticsmodels.SalesTotals
+	for _, series := range result.Series {
+		prev, ok := prevByGroup[series.Key]
+		if !ok {
+			prev = &analyticsmodels.SalesTotals{}
+		}
+		delete(prevByGroup, series.Key)
+
+		if series.Key == otherSeriesKey {
+			otherPrev = prev
+			continue
+		}
+		series.Comparison = compare(prevStart, prevEnd, series.Totals, prev)
+	}
+
+	// Groups that only sold in the comparison window belong to "Other"
+	if otherPrev != nil {
+		for _, prev := range prevByGroup {
+			addTotals(otherPrev, prev)
+		}
+		for _, series := range result.Series {
+			if series.Key == otherSeriesKey {
+				series.Comparison = compare(prevStart, prevEnd, series.Totals, otherPrev)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (s *analyticsService) GetTopSellers(ctx context.Context, query *analyticsmodels.RankingQuery) ([]*analyticsmodels.ItemRanking, error) {
+	if err := normalizeRankingQuery(query, s.defaultMethod); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetTopSellers(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return roundRankings(items), nil
+}
+
+func (s *analyticsService) GetSlowMovers(ctx context.Context, query *analyticsmodels.RankingQuery) ([]*analyticsmodels.ItemRanking, error) {
+	if err := normalizeRankingQuery(query, s.defaultMethod); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetSlowMovers(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return roundRankings(items), nil
+}
+
+// RefreshSummaries rebuilds the materialised views behind the analytics API.
+// It is run by the scheduler.
+func (s *analyticsService) RefreshSummaries(ctx context.Context) error {
+	return s.repo.RefreshSalesSummary(ctx)
+}
+
+// Helper functions
+
+func normalizeSalesQuery(query *analyticsmodels.SalesQuery, defaultMethod string) error {
+	if query.Granularity == "" {
+		query.Granularity = analyticsmodels.GranularityDay
+	}
+	if query.GroupBy == "" {
+		query.GroupBy = analyticsmodels.GroupByNone
+	}
+	if query.Compare == "" {
+		query.Compare = analyticsmodels.CompareNone
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultSeriesLimit
+	}
+
+	var defaultStart func(end time.Time) time.Time
+	switch query.Granularity {
+	case analyticsmodels.GranularityDay:
+		defaultStart = func(end time.Time) time.Time { return end.AddDate(0, 0, -29) }
+	case analyticsmodels.GranularityWeek:
+		defaultStart = func(end time.Time) time.Time { return end.AddDate(0, 0, -7*12+1) }
+	case analyticsmodels.GranularityMonth:
+		defaultStart = func(end time.Time) time.Time { return end.AddDate(-1, 0, 1) }
+	default:
+		return ErrInvalidGranularity
+	}
+
+	switch query.GroupBy {
+	case analyticsmodels.GroupByNone, analyticsmodels.GroupByItem, analyticsmodels.GroupByCategory,
+		analyticsmodels.GroupBySupplier, analyticsmodels.GroupBySalesperson:
+	default:
+		return ErrInvalidGroupBy
+	}
+
+	switch query.Compare {
+	case analyticsmodels.CompareNone, analyticsmodels.ComparePreviousPeriod, analyticsmodels.ComparePreviousYear:
+	default:
+		return ErrInvalidCompare
+	}
+
+	if err := normalizeMethod(&query.Method, defaultMethod); err != nil {
+		return err
+	}
+
+	if query.Limit < 1 || query.Limit > maxLimit {
+		return ErrInvalidLimit
+	}
+
+	query.EndDate = dateOf(query.EndDate)
+	if query.EndDate.IsZero() {
+		query.EndDate = dateOf(time.Now())
+	}
+	query.StartDate = dateOf(query.StartDate)
+	if query.StartDate.IsZero() {
+		query.StartDate = defaultStart(query.EndDate)
+	}
+	if query.StartDate.After(query.EndDate) {
+		return ErrInvalidDateRange
+	}
+
+	return nil
+}
+
+func normalizeRankingQuery(query *analyticsmodels.RankingQuery, defaultMethod string) error {
+	if query.RankBy == "" {
+		query.RankBy = analyticsmodels.RankByRevenue
+	}
+	switch query.RankBy {
+	case analyticsmodels.RankByRevenue, analyticsmodels.RankByUnits, analyticsmodels.RankByProfit:
+	default:
+		return ErrInvalidRankBy
+	}
+
+	if err := normalizeMethod(&query.Method, defaultMethod); err != nil {
+		return err
+	}
+
+	if query.Limit == 0 {
+		query.Limit = DefaultRankingLimit
+	}
+	if query.Limit < 1 || query.Limit > maxLimit {
+		return ErrInvalidLimit
+	}
+
+	query.EndDate = dateOf(query.EndDate)
+	if query.EndDate.IsZero() {
+		query.EndDate = dateOf(time.Now())
+	}
+	query.StartDate = dateOf(query.StartDate)
+	if query.StartDate.IsZero() {
+		query.StartDate = query.EndDate.AddDate(0, 0, -DefaultRankingDays+1)
+	}
+	if query.StartDate.After(query.EndDate) {
+		return ErrInvalidDateRange
+	}
+
+	return nil
+}
+
+// normalizeMethod falls back to the configured costing method
+func normalizeMethod(method *string, defaultMethod string) error {
+	if *method == "" {
+		*method = defaultMethod
+	}
+	if *method != analyticsmodels.MethodFIFO && *method != analyticsmodels.MethodAverage {
+		return ErrInvalidMethod
+	}
+	return nil
+}
+
+// buildSeries pivots buckets into one series per group, ordered by revenue.
+// Groups past the limit are merged into a single "Other" series.
+func buildSeries(buckets []*analyticsmodels.SalesBucket, periods []time.Time, limit int) []*analyticsmodels.SalesSeries {
+	byKey := make(map[string]*analyticsmodels.SalesSeries)
+	points := make(map[string]map[time.Time]*analyticsmodels.SalesBucket)
+	var order []*analyticsmodels.SalesSeries
+
+	for _, b := range buckets {
+		series, ok := byKey[b.GroupKey]
+		if !ok {
+			series = &analyticsmodels.SalesSeries{
+				Key:    b.GroupKey,
+				Label:  b.GroupLabel,
+				Totals: &analyticsmodels.SalesTotals{},
+			}
+			byKey[b.GroupKey] = series
+			points[b.GroupKey] = make(map[time.Time]*analyticsmodels.SalesBucket)
+			order = append(order, series)
+		}
+		addTotals(series.Totals, bucketTotals(b))
+		points[b.GroupKey][dateOf(b.PeriodStart)] = b
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Totals.Revenue > order[j].Totals.Revenue
+	})
+
+	if len(order) > limit {
+		other := &analyticsmodels.SalesSeries{
+			Key:    otherSeriesKey,
+			Label:  "Other",
+			Totals: &analyticsmodels.SalesTotals{},
+		}
+		otherPoints := make(map[time.Time]*analyticsmodels.SalesBucket)
+		for _, series := range order[limit:] {
+			addTotals(other.Totals, series.Totals)
+			for period, b := range points[series.Key] {
+				p, ok := otherPoints[period]
+				if !ok {
+					p = &analyticsmodels.SalesBucket{PeriodStart: period}
+					otherPoints[period] = p
+				}
+				p.SaleCount += b.SaleCount
+				p.Units += b.Units
+				p.Revenue += b.Revenue
+				p.GrossProfit += b.GrossProfit
+			}
+		}
+		points[otherSeriesKey] = otherPoints
+		order = append(order[:limit], other)
+	}
+
+	for _, series := range order {
+		series.Points = make([]*analyticsmodels.SalesBucket, 0, len(periods))
+		for _, period := range periods {
+			b, ok := points[series.Key][period]
+			if !ok {
+				b = &analyticsmodels.SalesBucket{PeriodStart: period}
+			}
+			b.Revenue = roundCents(b.Revenue)
+			b.GrossProfit = roundCents(b.GrossProfit)
+			series.Points = append(series.Points, b)
+		}
+		series.Totals.Revenue = roundCents(series.Totals.Revenue)
+		series.Totals.GrossProfit = roundCents(series.Totals.GrossProfit)
+	}
+
+	if order == nil {
+		order = []*analyticsmodels.SalesSeries{}
+	}
+	return order
+}
+
+// periodStarts lists the start of every period overlapping start..end, using
+// the same boundaries as PostgreSQL's date_trunc (weeks start on Monday).
+func periodStarts(start, end time.Time, granularity string) []time.Time {
+	var current time.Time
+	var next func(time.Time) time.Time
+
+	switch granularity {
+	case analyticsmodels.GranularityWeek:
+		current = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case analyticsmodels.GranularityMonth:
+		current = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		current = start
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	}
+
+	var periods []time.Time
+	for !current.After(end) && len(periods) <= maxPeriods {
+		periods = append(periods, current)
+		current = next(current)
+	}
+	return periods
+}
+
+// comparisonWindow returns the window compared against: the same number of
+// days immediately before, or the same dates a year earlier.
+func comparisonWindow(start, end time.Time, mode string) (time.Time, time.Time) {
+	if mode == analyticsmodels.ComparePreviousYear {
+		return start.AddDate(-1, 0, 0), end.AddDate(-1, 0, 0)
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	prevEnd := start.AddDate(0, 0, -1)
+	return prevEnd.AddDate(0, 0, -(days - 1)), prevEnd
+}
+
+func compare(start, end time.Time, current, previous *analyticsmodels.SalesTotals) *analyticsmodels.Comparison {
+	previous.Revenue = roundCents(previous.Revenue)
+	previous.GrossProfit = roundCents(previous.GrossProfit)
+
+	return &analyticsmodels.Comparison{
+		StartDate:            start,
+		EndDate:              end,
+		Totals:               previous,
+		RevenueChangePct:     changePct(current.Revenue, previous.Revenue),
+		UnitsChangePct:       changePct(float64(current.Units), float64(previous.Units)),
+		GrossProfitChangePct: changePct(current.GrossProfit, previous.GrossProfit),
+	}
+}
+
+func changePct(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	pct := roundCents((current - previous) / math.Abs(previous) * 100)
+	return &pct
+}
+
+func bucketTotals(b *analyticsmodels.SalesBucket) *analyticsmodels.SalesTotals {
+	return &analyticsmodels.SalesTotals{
+		SaleCount:   b.SaleCount,
+		Units:       b.Units,
+		Revenue:     b.Revenue,
+		GrossProfit: b.GrossProfit,
+	}
+}
+
+func addTotals(total, t *analyticsmodels.SalesTotals) {
+	total.SaleCount += t.SaleCount
+	total.Units += t.Units
+	total.Revenue = roundCents(total.Revenue + t.Revenue)
+	total.GrossProfit = roundCents(total.GrossProfit + t.GrossProfit)
+}
+
+func roundRankings(items []*analyticsmodels.ItemRanking) []*analyticsmodels.ItemRanking {
+	if items == nil {
+		return []*analyticsmodels.ItemRanking{}
+	}
+	for _, item := range items {
+		item.Revenue = roundCents(item.Revenue)
+		item.GrossProfit = roundCents(item.GrossProfit)
+	}
+	return items
+}
+
+// dateOf drops the time of day, keeping the calendar date as UTC midnight to
+// match DATE values read from the database.
+func dateOf(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,447 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	analyticsmodels "github.com/hsrvms/autoparts/internal/modules/analytics/models"
+)
+
+const (
+	DefaultDeadAfterDays   = 365
+	DefaultSlowCoverMonths = 12
+	DefaultLookbackDays    = 365
+	DefaultClassifyMonths  = 12
+	maxHistoryDays         = 3650
+	daysPerMonth           = 365.25 / 12
+
+	// Cumulative share of the basis covered by A and B items, in percent
+	abcThresholdA = 80
+	abcThresholdB = 95
+
+	// Coefficient of variation of monthly demand for X and Y items
+	xyzThresholdX = 0.5
+	xyzThresholdY = 1.0
+)
+
+var (
+	ErrInvalidStockStatus = errors.New("status must be dead, slow, healthy, at_risk or all")
+	ErrInvalidDeadAfter   = errors.New("dead_after_days must be between 1 and 3650")
+	ErrInvalidLookback    = errors.New("lookback_days must be between 1 and 3650")
+	ErrInvalidSlowCover   = errors.New("slow_cover_months must be greater than 0")
+	ErrInvalidMinCapital  = errors.New("min_capital cannot be negative")
+	ErrInvalidMonths      = errors.New("months must be between 3 and 36")
+	ErrInvalidBasis       = errors.New("basis must be revenue, profit or units")
+	ErrInvalidStockAction = errors.New("action must be deactivate, activate, clearance or remove_clearance")
+	ErrInvalidItemID      = errors.New("item IDs must be positive")
+	ErrNoItems            = errors.New("at least one item ID is required")
+	ErrTooManyItems       = errors.New("at most 500 items can be updated at once")
+)
+
+// GetDeadStock reports stocked items by how long they have sat unsold and how
+// many months the current stock would last at recent demand. Items idle for
+// at least DeadAfterDays are dead; items with more than SlowCoverMonths of
+// cover, or no sales in the lookback window, are slow.
+func (s *analyticsService) GetDeadStock(ctx context.Context, query *analyticsmodels.DeadStockQuery) (*analyticsmodels.DeadStockReport, error) {
+	if err := normalizeDeadStockQuery(query); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	since := dateOf(now).AddDate(0, 0, -query.LookbackDays+1)
+
+	profiles, err := s.repo.GetStockProfiles(ctx, &query.StockFilter, since)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &analyticsmodels.DeadStockReport{
+		AsOf:            now,
+		DeadAfterDays:   query.DeadAfterDays,
+		SlowCoverMonths: query.SlowCoverMonths,
+		LookbackDays:    query.LookbackDays,
+		Items:           []*analyticsmodels.DeadStockItem{},
+		Totals: map[string]*analyticsmodels.StockStatusTotals{
+			analyticsmodels.StockStatusDead:    {},
+			analyticsmodels.StockStatusSlow:    {},
+			analyticsmodels.StockStatusHealthy: {},
+		},
+	}
+
+	lookbackMonths := float64(query.LookbackDays) / daysPerMonth
+	for _, p := range profiles {
+		if p.CurrentStock <= 0 {
+			continue
+		}
+
+		item := &analyticsmodels.DeadStockItem{
+			ItemID:           p.ItemID,
+			PartNumber:       p.PartNumber,
+			Description:      p.Description,
+			CategoryName:     p.CategoryName,
+			SupplierName:     p.SupplierName,
+			CurrentStock:     p.CurrentStock,
+			IsActive:         p.IsActive,
+			IsClearance:      p.IsClearance,
+			LastSaleDate:     p.LastSaleDate,
+			LastPurchaseDate: p.LastPurchaseDate,
+			UnitsSold:        p.UnitsSold,
+			MonthlyDemand:    roundCents(float64(p.UnitsSold) / lookbackMonths),
+			TiedUpCapital:    roundCents(p.TiedUpCapital),
+		}
+
+		// Never-sold stock has been idle since it was last bought, or since
+		// the item was created if it has no purchases either
+		idleSince := p.CreatedAt
+		if p.LastPurchaseDate != nil {
+			idleSince = *p.LastPurchaseDate
+		}
+		if p.LastSaleDate != nil {
+			idleSince = *p.LastSaleDate
+			days := daysBetween(*p.LastSaleDate, now)
+			item.DaysSinceLastSale = &days
+		}
+		item.IdleDays = daysBetween(idleSince, now)
+
+		if p.UnitsSold > 0 {
+			cover := roundCents(float64(p.CurrentStock) / (float64(p.UnitsSold) / lookbackMonths))
+			item.MonthsOfCover = &cover
+		}
+
+		switch {
+		case item.IdleDays >= query.DeadAfterDays:
+			item.Status = analyticsmodels.StockStatusDead
+		case item.MonthsOfCover == nil || *item.MonthsOfCover > query.SlowCoverMonths:
+			item.Status = analyticsmodels.StockStatusSlow
+		default:
+			item.Status = analyticsmodels.StockStatusHealthy
+		}
+
+		totals := report.Totals[item.Status]
+		totals.Items++
+		totals.Units += item.CurrentStock
+		totals.TiedUpCapital = roundCents(totals.TiedUpCapital + item.TiedUpCapital)
+
+		if matchesStockStatus(item.Status, query.Status) && item.TiedUpCapital >= query.MinCapital {
+			report.Items = append(report.Items, item)
+		}
+	}
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		if report.Items[i].TiedUpCapital != report.Items[j].TiedUpCapital {
+			return report.Items[i].TiedUpCapital > report.Items[j].TiedUpCapital
+		}
+		return report.Items[i].IdleDays > report.Items[j].IdleDays
+	})
+
+	return report, nil
+}
+
+// GetClassification ranks items into ABC classes by their share of the chosen
+// basis over the last complete months, and into XYZ classes by how steady
+// their monthly demand was.
+func (s *analyticsService) GetClassification(ctx context.Context, query *analyticsmodels.ClassificationQuery) (*analyticsmodels.ClassificationReport, error) {
+	if err := normalizeClassificationQuery(query, s.defaultMethod); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := thisMonth.AddDate(0, -query.Months, 0)
+	end := thisMonth.AddDate(0, 0, -1)
+
+	profiles, err := s.repo.GetStockProfiles(ctx, &query.StockFilter, start)
+	if err != nil {
+		return nil, err
+	}
+
+	demand, err := s.repo.GetMonthlyDemand(ctx, &query.StockFilter, start, end, query.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	monthly := make(map[int][]float64)
+	byItem := make(map[int]*analyticsmodels.ItemClassification)
+	items := make([]*analyticsmodels.ItemClassification, 0, len(profiles))
+	for _, p := range profiles {
+		item := &analyticsmodels.ItemClassification{
+			ItemID:        p.ItemID,
+			PartNumber:    p.PartNumber,
+			Description:   p.Description,
+			CategoryName:  p.CategoryName,
+			SupplierName:  p.SupplierName,
+			CurrentStock:  p.CurrentStock,
+			TiedUpCapital: roundCents(p.TiedUpCapital),
+		}
+		byItem[p.ItemID] = item
+		monthly[p.ItemID] = make([]float64, query.Months)
+		items = append(items, item)
+	}
+
+	for _, d := range demand {
+		item, ok := byItem[d.ItemID]
+		if !ok {
+			continue
+		}
+		item.Units += d.Units
+		item.Revenue += d.Revenue
+		item.GrossProfit += d.GrossProfit
+
+		month := dateOf(d.Month)
+		index := (month.Year()-start.Year())*12 + int(month.Month()) - int(start.Month())
+		if index >= 0 && index < query.Months {
+			monthly[d.ItemID][index] += float64(d.Units)
+		}
+	}
+
+	var total float64
+	values := make(map[int]float64, len(items))
+	for _, item := range items {
+		item.Revenue = roundCents(item.Revenue)
+		item.GrossProfit = roundCents(item.GrossProfit)
+		values[item.ItemID] = classificationValue(item, query.Basis)
+		total += values[item.ItemID]
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return values[items[i].ItemID] > values[items[j].ItemID]
+	})
+
+	report := &analyticsmodels.ClassificationReport{
+		StartDate: start,
+		EndDate:   end,
+		Months:    query.Months,
+		Basis:     query.Basis,
+		Method:    query.Method,
+		Thresholds: map[string]float64{
+			"a": abcThresholdA,
+			"b": abcThresholdB,
+			"x": xyzThresholdX,
+			"y": xyzThresholdY,
+		},
+		Items:  items,
+		Matrix: make(map[string]*analyticsmodels.ClassificationCell),
+	}
+	for _, abc := range []string{"A", "B", "C"} {
+		for _, xyz := range []string{"X", "Y", "Z"} {
+			report.Matrix[abc+xyz] = &analyticsmodels.ClassificationCell{}
+		}
+	}
+
+	var cumulative float64
+	for _, item := range items {
+		value := values[item.ItemID]
+
+		// An item is classed by the share covered before it, so the item
+		// that crosses a threshold still falls in the higher class
+		item.ABC = "C"
+		if value > 0 {
+			before := cumulative / total * 100
+			switch {
+			case before < abcThresholdA:
+				item.ABC = "A"
+			case before < abcThresholdB:
+				item.ABC = "B"
+			}
+			cumulative += value
+			item.SharePct = roundCents(value / total * 100)
+		}
+		if total > 0 {
+			item.CumulativePct = roundCents(cumulative / total * 100)
+		}
+
+		item.XYZ = "Z"
+		if cv, ok := coefficientOfVariation(monthly[item.ItemID]); ok {
+			rounded := roundCents(cv)
+			item.DemandCV = &rounded
+			switch {
+			case cv <= xyzThresholdX:
+				item.XYZ = "X"
+			case cv <= xyzThresholdY:
+				item.XYZ = "Y"
+			}
+		}
+
+		item.Class = item.ABC + item.XYZ
+		cell := report.Matrix[item.Class]
+		cell.Items++
+		cell.Value = roundCents(cell.Value + value)
+		cell.TiedUpCapital = roundCents(cell.TiedUpCapital + item.TiedUpCapital)
+	}
+
+	return report, nil
+}
+
+// ApplyStockAction deactivates, reactivates or flags items for clearance in
+// bulk, typically with IDs picked from the dead-stock report.
+func (s *analyticsService) ApplyStockAction(ctx context.Context, req *analyticsmodels.StockActionRequest) (*analyticsmodels.StockActionResult, error) {
+	switch req.Action {
+	case analyticsmodels.StockActionDeactivate, analyticsmodels.StockActionActivate,
+		analyticsmodels.StockActionClearance, analyticsmodels.StockActionRemoveClearance:
+	default:
+		return nil, ErrInvalidStockAction
+	}
+
+	ids := make([]int, 0, len(req.ItemIDs))
+	seen := make(map[int]bool)
+	for _, id := range req.ItemIDs {
+		if id <= 0 {
+			return nil, ErrInvalidItemID
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoItems
+	}
+	if len(ids) > maxLimit {
+		return nil, ErrTooManyItems
+	}
+
+	updated, err := s.repo.ApplyStockAction(ctx, req.Action, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &analyticsmodels.StockActionResult{
+		Action:   req.Action,
+		Updated:  []int{},
+		NotFound: []int{},
+	}
+	found := make(map[int]bool, len(updated))
+	for _, id := range updated {
+		found[id] = true
+	}
+	for _, id := range ids {
+		if found[id] {
+			result.Updated = append(result.Updated, id)
+		} else {
+			result.NotFound = append(result.NotFound, id)
+		}
+	}
+
+	return result, nil
+}
+
+// Helper functions
+
+func normalizeDeadStockQuery(query *analyticsmodels.DeadStockQuery) error {
+	if query.Status == "" {
+		query.Status = analyticsmodels.StockStatusAtRisk
+	}
+	switch query.Status {
+	case analyticsmodels.StockStatusDead, analyticsmodels.StockStatusSlow, analyticsmodels.StockStatusHealthy,
+		analyticsmodels.StockStatusAtRisk, analyticsmodels.StockStatusAll:
+	default:
+		return ErrInvalidStockStatus
+	}
+
+	if query.DeadAfterDays == 0 {
+		query.DeadAfterDays = DefaultDeadAfterDays
+	}
+	if query.DeadAfterDays < 1 || query.DeadAfterDays > maxHistoryDays {
+		return ErrInvalidDeadAfter
+	}
+
+	if query.LookbackDays == 0 {
+		query.LookbackDays = DefaultLookbackDays
+	}
+	if query.LookbackDays < 1 || query.LookbackDays > maxHistoryDays {
+		return ErrInvalidLookback
+	}
+
+	if query.SlowCoverMonths == 0 {
+		query.SlowCoverMonths = DefaultSlowCoverMonths
+	}
+	if query.SlowCoverMonths < 0 {
+		return ErrInvalidSlowCover
+	}
+
+	if query.MinCapital < 0 {
+		return ErrInvalidMinCapital
+	}
+
+	return nil
+}
+
+func normalizeClassificationQuery(query *analyticsmodels.ClassificationQuery, defaultMethod string) error {
+	if query.Months == 0 {
+		query.Months = DefaultClassifyMonths
+	}
+	if query.Months < 3 || query.Months > 36 {
+		return ErrInvalidMonths
+	}
+
+	if query.Basis == "" {
+		query.Basis = analyticsmodels.ClassifyByRevenue
+	}
+	switch query.Basis {
+	case analyticsmodels.ClassifyByRevenue, analyticsmodels.ClassifyByProfit, analyticsmodels.ClassifyByUnits:
+	default:
+		return ErrInvalidBasis
+	}
+
+	return normalizeMethod(&query.Method, defaultMethod)
+}
+
+func matchesStockStatus(status, filter string) bool {
+	switch filter {
+	case analyticsmodels.StockStatusAll:
+		return true
+	case analyticsmodels.StockStatusAtRisk:
+		return status != analyticsmodels.StockStatusHealthy
+	default:
+		return status == filter
+	}
+}
+
+// classificationValue is the item's contribution to the ABC basis. Loss
+// making items contribute nothing to a profit ranking.
+func classificationValue(item *analyticsmodels.ItemClassification, basis string) float64 {
+	switch basis {
+	case analyticsmodels.ClassifyByProfit:
+		return math.Max(item.GrossProfit, 0)
+	case analyticsmodels.ClassifyByUnits:
+		return float64(item.Units)
+	default:
+		return item.Revenue
+	}
+}
+
+// coefficientOfVariation is the population standard deviation of the series
+// divided by its mean. It is undefined for a series with no demand.
+func coefficientOfVariation(series []float64) (float64, bool) {
+	if len(series) == 0 {
+		return 0, false
+	}
+
+	var sum float64
+	for _, v := range series {
+		sum += v
+	}
+	mean := sum / float64(len(series))
+	if mean == 0 {
+		return 0, false
+	}
+
+	var variance float64
+	for _, v := range series {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(series))
+
+	return math.Sqrt(variance) / mean, true
+}
+
+func daysBetween(from, to time.Time) int {
+	days := int(to.Sub(from).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
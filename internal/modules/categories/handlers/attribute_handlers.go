@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hsrvms/autoparts/internal/modules/categories/models"
+	"github.com/hsrvms/autoparts/internal/modules/categories/services"
+	"github.com/labstack/echo/v4"
+)
+
+// AttributeHandler handles HTTP requests for category attributes
+type AttributeHandler struct {
+	service services.AttributeService
+}
+
+// NewAttributeHandler creates a new category attribute handler
+func NewAttributeHandler(service services.AttributeService) *AttributeHandler {
+	return &AttributeHandler{
+		service: service,
+	}
+}
+
+// GetAttributes returns the attributes of a category, inherited ones included
+func (h *AttributeHandler) GetAttributes(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid category ID")
+	}
+
+	ctx := c.Request().Context()
+	attributes, err := h.service.GetAttributes(ctx, id)
+	if err != nil {
+		return attributeError(err)
+	}
+
+	return c.JSON(http.StatusOK, attributes)
+}
+
+// CreateAttribute adds an attribute to a category
+func (h *AttributeHandler) CreateAttribute(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid category ID")
+	}
+
+	attribute := new(models.CategoryAttribute)
+	if err := c.Bind(attribute); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	attribute.CategoryID = id
+
+	ctx := c.Request().Context()
+	created, err := h.service.CreateAttribute(ctx, attribute)
+	if err != nil {
+		return attributeError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateAttribute updates an attribute of a category
+func (h *AttributeHandler) UpdateAttribute(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid category ID")
+	}
+	attributeID, err := strconv.Atoi(c.Param("attributeId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid attribute ID")
+	}
+
+	attribute := new(models.CategoryAttribute)
+	if err := c.Bind(attribute); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Ensure IDs in URL match IDs in body
+	attribute.CategoryID = id
+	attribute.AttributeID = attributeID
+
+	ctx := c.Request().Context()
+	updated, err := h.service.UpdateAttribute(ctx, attribute)
+	if err != nil {
+		return attributeError(err)
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteAttribute deletes an attribute of a category
+func (h *AttributeHandler) DeleteAttribute(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid category ID")
+	}
+	attributeID, err := strconv.Atoi(c.Param("attributeId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid attribute ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.DeleteAttribute(ctx, id, attributeID); err != nil {
+		return attributeError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// attributeError maps attribute service errors to HTTP errors
+func attributeError(err error) error {
+	switch err {
+	case services.ErrCategoryNotFound, services.ErrAttributeNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrInvalidAttributeKey, services.ErrAttributeLabelRequired, services.ErrInvalidAttributeType,
+		services.ErrAttributeValuesRequired, services.ErrAttributeValuesNotEnum, services.ErrAttributeBoundsNotNumber,
+		services.ErrInvalidAttributeBounds:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrDuplicateAttributeKey, services.ErrAttributeInUse:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -113,6 +113,8 @@ func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
 		case services.ErrParentCategoryNotFound, services.ErrCircularReference:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrAttributeKeyConflict:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
@@ -121,7 +123,8 @@ func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 	return c.JSON(http.StatusOK, category)
 }
 
-// DeleteCategory deletes a category
+// DeleteCategory deletes a category. With reassign_to, its items and
+// subcategories move to that category first.
 func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -129,6 +132,29 @@ func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
+	if reassignTo := c.QueryParam("reassign_to"); reassignTo != "" {
+		targetID, err := strconv.Atoi(reassignTo)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid category ID to reassign to")
+		}
+
+		result, err := h.service.DeleteAndReassign(ctx, id, targetID)
+		if err != nil {
+			switch err {
+			case services.ErrCategoryNotFound:
+				return echo.NewHTTPError(http.StatusNotFound, err.Error())
+			case services.ErrReassignTargetNotFound, services.ErrReassignIntoSubtree:
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			case services.ErrAttributeKeyConflict:
+				return echo.NewHTTPError(http.StatusConflict, err.Error())
+			default:
+				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+
 	err = h.service.DeleteCategory(ctx, id)
 	if err != nil {
 		switch err {
@@ -154,3 +180,54 @@ func (h *CategoryHandler) GetCategoryTree(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, tree)
 }
+
+// MoveCategory moves a category and everything below it to a new parent
+func (h *CategoryHandler) MoveCategory(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid category ID")
+	}
+
+	request := new(models.MoveRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	category, err := h.service.MoveCategory(ctx, id, request)
+	if err != nil {
+		switch err {
+		case services.ErrCategoryNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrParentCategoryNotFound, services.ErrCircularReference, services.ErrInvalidPosition:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrAttributeKeyConflict:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, category)
+}
+
+// ReorderCategories sets the order of the subcategories of a parent
+func (h *CategoryHandler) ReorderCategories(c echo.Context) error {
+	request := new(models.ReorderRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	categories, err := h.service.ReorderCategories(ctx, request)
+	if err != nil {
+		switch err {
+		case services.ErrParentCategoryNotFound, services.ErrInvalidSiblingOrder:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, categories)
+}
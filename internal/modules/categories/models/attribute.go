@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// Attribute data types
+const (
+	AttributeText    = "text"
+	AttributeNumber  = "number"
+	AttributeBoolean = "boolean"
+	AttributeEnum    = "enum"
+)
+
+// CategoryAttribute is a specification the items of a category carry, such
+// as the thread size of a filter. Attributes apply to the category that
+// defines them and to every category below it.
+type CategoryAttribute struct {
+	AttributeID   int       `json:"attribute_id" db:"attribute_id"`
+	CategoryID    int       `json:"category_id" db:"category_id"`
+	AttributeKey  string    `json:"attribute_key" db:"attribute_key"`
+	Label         string    `json:"label" db:"label"`
+	DataType      string    `json:"data_type" db:"data_type"`
+	Unit          *string   `json:"unit,omitempty" db:"unit"`
+	AllowedValues []string  `json:"allowed_values,omitempty" db:"allowed_values"`
+	MinValue      *float64  `json:"min_value,omitempty" db:"min_value"`
+	MaxValue      *float64  `json:"max_value,omitempty" db:"max_value"`
+	IsRequired    bool      `json:"is_required" db:"is_required"`
+	SortOrder     int       `json:"sort_order" db:"sort_order"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+
+	// Joined fields (not from database)
+	CategoryName string `json:"category_name,omitempty" db:"-"`
+	Inherited    bool   `json:"inherited" db:"-"` // Defined by a category above the one requested
+}
@@ -10,6 +10,7 @@ type Category struct {
 	CategoryName     string    `json:"category_name" db:"category_name"`
 	Description      *string   `json:"description,omitempty" db:"description"`
 	ParentCategoryID *int      `json:"parent_category_id,omitempty" db:"parent_category_id"`
+	SortOrder        int       `json:"sort_order" db:"sort_order"` // Set through move and reorder
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
 
@@ -21,4 +22,32 @@ type Category struct {
 type CategoryTreeNode struct {
 	Category      *Category           `json:"category"`
 	Subcategories []*CategoryTreeNode `json:"subcategories,omitempty"`
+
+	// Items filed directly under the category, and under it or any category below it
+	ItemCount      int `json:"item_count"`
+	TotalItemCount int `json:"total_item_count"`
+}
+
+// MoveRequest places a category under a new parent, or at the top level when
+// ParentCategoryID is nil. Position is its zero-based place among its new
+// siblings; it goes last when none is given.
+type MoveRequest struct {
+	ParentCategoryID *int `json:"parent_category_id"`
+	Position         *int `json:"position,omitempty"`
+}
+
+// ReorderRequest lists every subcategory of a parent, or every top level
+// category when ParentCategoryID is nil, in their new order
+type ReorderRequest struct {
+	ParentCategoryID *int  `json:"parent_category_id"`
+	CategoryIDs      []int `json:"category_ids"`
+}
+
+// ReassignResult reports what a deleted category's items and subcategories
+// were moved to
+type ReassignResult struct {
+	CategoryID              int   `json:"category_id"`
+	ReassignedTo            int   `json:"reassigned_to"`
+	ItemsReassigned         int64 `json:"items_reassigned"`
+	SubcategoriesReassigned int64 `json:"subcategories_reassigned"`
 }
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/hsrvms/autoparts/internal/modules/categories/models"
+)
+
+// AttributeRepository defines the interface for category attribute data access
+type AttributeRepository interface {
+	GetEffectiveAttributes(ctx context.Context, categoryID int) ([]*models.CategoryAttribute, error)
+	GetByID(ctx context.Context, attributeID int) (*models.CategoryAttribute, error)
+	KeyInUse(ctx context.Context, categoryID int, key string, excludeID int) (bool, error)
+	CountValues(ctx context.Context, attributeID int) (int, error)
+	Create(ctx context.Context, attribute *models.CategoryAttribute) (int, error)
+	Update(ctx context.Context, attribute *models.CategoryAttribute) error
+	Delete(ctx context.Context, attributeID int) error
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hsrvms/autoparts/internal/modules/categories/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresAttributeRepository implements AttributeRepository for PostgreSQL
+type PostgresAttributeRepository struct {
+	db *db.Database
+}
+
+// NewPostgresAttributeRepository creates a new PostgreSQL attribute repository
+func NewPostgresAttributeRepository(database *db.Database) AttributeRepository {
+	return &PostgresAttributeRepository{
+		db: database,
+	}
+}
+
+// GetEffectiveAttributes retrieves the attributes of a category together with
+// those it inherits, the topmost category's first
+func (r *PostgresAttributeRepository) GetEffectiveAttributes(ctx context.Context, categoryID int) ([]*models.CategoryAttribute, error) {
+	query := `
+		SELECT a.attribute_id, a.category_id, a.attribute_key, a.label, a.data_type, a.unit,
+			a.allowed_values, a.min_value, a.max_value, a.is_required, a.sort_order,
+			a.created_at, a.updated_at, c.category_name, l.depth > 0
+		FROM category_lineage($1) l
+		JOIN category_attributes a ON a.category_id = l.category_id
+		JOIN categories c ON c.category_id = a.category_id
+		ORDER BY l.depth DESC, a.sort_order, a.label
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []*models.CategoryAttribute{}
+	for rows.Next() {
+		attribute := &models.CategoryAttribute{}
+		err := rows.Scan(
+			&attribute.AttributeID,
+			&attribute.CategoryID,
+			&attribute.AttributeKey,
+			&attribute.Label,
+			&attribute.DataType,
+			&attribute.Unit,
+			&attribute.AllowedValues,
+			&attribute.MinValue,
+			&attribute.MaxValue,
+			&attribute.IsRequired,
+			&attribute.SortOrder,
+			&attribute.CreatedAt,
+			&attribute.UpdatedAt,
+			&attribute.CategoryName,
+			&attribute.Inherited,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, attribute)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
+
+// GetByID retrieves an attribute by its ID
+func (r *PostgresAttributeRepository) GetByID(ctx context.Context, attributeID int) (*models.CategoryAttribute, error) {
+	query := `
+		SELECT a.attribute_id, a.category_id, a.attribute_key, a.label, a.data_type, a.unit,
+			a.allowed_values, a.min_value, a.max_value, a.is_required, a.sort_order,
+			a.created_at, a.updated_at, c.category_name
+		FROM category_attributes a
+		JOIN categories c ON c.category_id = a.category_id
+		WHERE a.attribute_id = $1
+	`
+
+	attribute := &models.CategoryAttribute{}
+	err := r.db.Pool.QueryRow(ctx, query, attributeID).Scan(
+		&attribute.AttributeID,
+		&attribute.CategoryID,
+		&attribute.AttributeKey,
+		&attribute.Label,
+		&attribute.DataType,
+		&attribute.Unit,
+		&attribute.AllowedValues,
+		&attribute.MinValue,
+		&attribute.MaxValue,
+		&attribute.IsRequired,
+		&attribute.SortOrder,
+		&attribute.CreatedAt,
+		&attribute.UpdatedAt,
+		&attribute.CategoryName,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil // No attribute found
+		}
+		return nil, err
+	}
+
+	return attribute, nil
+}
+
+// KeyInUse reports whether a category, a category above it or a category
+// below it already has an attribute with the key, other than excludeID
+func (r *PostgresAttributeRepository) KeyInUse(ctx context.Context, categoryID int, key string, excludeID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM category_attributes a
+			WHERE a.attribute_key = $2
+				AND a.attribute_id <> $3
+				AND a.category_id IN (
+					SELECT category_id FROM category_lineage($1)
+					UNION
+					SELECT category_id FROM category_subtree($1)
+				)
+		)
+	`
+
+	var inUse bool
+	err := r.db.Pool.QueryRow(ctx, query, categoryID, key, excludeID).Scan(&inUse)
+	return inUse, err
+}
+
+// CountValues counts the items that have a value for the attribute
+func (r *PostgresAttributeRepository) CountValues(ctx context.Context, attributeID int) (int, error) {
+	query := `SELECT COUNT(*) FROM item_attribute_values WHERE attribute_id = $1`
+
+	var count int
+	err := r.db.Pool.QueryRow(ctx, query, attributeID).Scan(&count)
+	return count, err
+}
+
+// Create adds a new attribute to a category
+func (r *PostgresAttributeRepository) Create(ctx context.Context, attribute *models.CategoryAttribute) (int, error) {
+	query := `
+		INSERT INTO category_attributes (
+			category_id, attribute_key, label, data_type, unit, allowed_values,
+			min_value, max_value, is_required, sort_order
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING attribute_id
+	`
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx,
+		query,
+		attribute.CategoryID,
+		attribute.AttributeKey,
+		attribute.Label,
+		attribute.DataType,
+		attribute.Unit,
+		attribute.AllowedValues,
+		attribute.MinValue,
+		attribute.MaxValue,
+		attribute.IsRequired,
+		attribute.SortOrder,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update modifies an existing attribute
+func (r *PostgresAttributeRepository) Update(ctx context.Context, attribute *models.CategoryAttribute) error {
+	query := `
+		UPDATE category_attributes
+		SET attribute_key = $2, label = $3, data_type = $4, unit = $5, allowed_values = $6,
+			min_value = $7, max_value = $8, is_required = $9, sort_order = $10
+		WHERE attribute_id = $1
+	`
+
+	_, err := r.db.Pool.Exec(
+		ctx,
+		query,
+		attribute.AttributeID,
+		attribute.AttributeKey,
+		attribute.Label,
+		attribute.DataType,
+		attribute.Unit,
+		attribute.AllowedValues,
+		attribute.MinValue,
+		attribute.MaxValue,
+		attribute.IsRequired,
+		attribute.SortOrder,
+	)
+
+	return err
+}
+
+// Delete removes an attribute and the values items have for it
+func (r *PostgresAttributeRepository) Delete(ctx context.Context, attributeID int) error {
+	query := `DELETE FROM category_attributes WHERE attribute_id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, attributeID)
+	return err
+}
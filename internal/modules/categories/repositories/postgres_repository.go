@@ -3,12 +3,26 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 
 	"github.com/hsrvms/autoparts/internal/modules/categories/models"
 	"github.com/hsrvms/autoparts/pkg/db"
 	"github.com/jackc/pgx/v5"
 )
 
+// ErrCircularParent is returned when, by the time a category is moved, its
+// new parent has come to lie below it
+var ErrCircularParent = errors.New("new parent lies below the category")
+
+// nextSortOrderQuery gives the sort order that places a category after the
+// subcategories of the parent in the numbered parameter
+const nextSortOrderQuery = `
+	SELECT COALESCE(MAX(sort_order) + 1, 0)
+	FROM categories
+	WHERE parent_category_id IS NOT DISTINCT FROM $%d
+`
+
 // PostgresCategoryRepository implements CategoryRepository for PostgreSQL
 type PostgresCategoryRepository struct {
 	db *db.Database
@@ -24,7 +38,7 @@ func NewPostgresCategoryRepository(database *db.Database) CategoryRepository {
 // GetAll retrieves all categories from the database
 func (r *PostgresCategoryRepository) GetAll(ctx context.Context) ([]*models.Category, error) {
 	query := `
-		SELECT category_id, category_name, description, parent_category_id, created_at, updated_at
+		SELECT category_id, category_name, description, parent_category_id, sort_order, created_at, updated_at
 		FROM categories
 		ORDER BY category_name
 	`
@@ -43,6 +57,7 @@ func (r *PostgresCategoryRepository) GetAll(ctx context.Context) ([]*models.Cate
 			&category.CategoryName,
 			&category.Description,
 			&category.ParentCategoryID,
+			&category.SortOrder,
 			&category.CreatedAt,
 			&category.UpdatedAt,
 		)
@@ -62,7 +77,7 @@ func (r *PostgresCategoryRepository) GetAll(ctx context.Context) ([]*models.Cate
 // GetByID retrieves a category by its ID
 func (r *PostgresCategoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
 	query := `
-		SELECT category_id, category_name, description, parent_category_id, created_at, updated_at
+		SELECT category_id, category_name, description, parent_category_id, sort_order, created_at, updated_at
 		FROM categories
 		WHERE category_id = $1
 	`
@@ -73,6 +88,7 @@ func (r *PostgresCategoryRepository) GetByID(ctx context.Context, id int) (*mode
 		&category.CategoryName,
 		&category.Description,
 		&category.ParentCategoryID,
+		&category.SortOrder,
 		&category.CreatedAt,
 		&category.UpdatedAt,
 	)
@@ -90,10 +106,10 @@ func (r *PostgresCategoryRepository) GetByID(ctx context.Context, id int) (*mode
 // GetSubcategories retrieves all subcategories for a parent category
 func (r *PostgresCategoryRepository) GetSubcategories(ctx context.Context, parentID int) ([]*models.Category, error) {
 	query := `
-		SELECT category_id, category_name, description, parent_category_id, created_at, updated_at
+		SELECT category_id, category_name, description, parent_category_id, sort_order, created_at, updated_at
 		FROM categories
 		WHERE parent_category_id = $1
-		ORDER BY category_name
+		ORDER BY sort_order, category_name
 	`
 
 	rows, err := r.db.Pool.Query(ctx, query, parentID)
@@ -110,6 +126,7 @@ func (r *PostgresCategoryRepository) GetSubcategories(ctx context.Context, paren
 			&category.CategoryName,
 			&category.Description,
 			&category.ParentCategoryID,
+			&category.SortOrder,
 			&category.CreatedAt,
 			&category.UpdatedAt,
 		)
@@ -126,11 +143,11 @@ func (r *PostgresCategoryRepository) GetSubcategories(ctx context.Context, paren
 	return subcategories, nil
 }
 
-// Create adds a new category to the database
+// Create adds a new category to the database, after its siblings
 func (r *PostgresCategoryRepository) Create(ctx context.Context, category *models.Category) (int, error) {
 	query := `
-		INSERT INTO categories (category_name, description, parent_category_id)
-		VALUES ($1, $2, $3)
+		INSERT INTO categories (category_name, description, parent_category_id, sort_order)
+		VALUES ($1, $2, $3, (` + fmt.Sprintf(nextSortOrderQuery, 3) + `))
 		RETURNING category_id
 	`
 
@@ -150,15 +167,30 @@ func (r *PostgresCategoryRepository) Create(ctx context.Context, category *model
 	return id, nil
 }
 
-// Update modifies an existing category
+// Update modifies an existing category. A category given a new parent goes
+// after its new siblings.
 func (r *PostgresCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockTree(ctx, tx, category.CategoryID, category.ParentCategoryID); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE categories
-		SET category_name = $2, description = $3, parent_category_id = $4
+		SET category_name = $2, description = $3, parent_category_id = $4,
+			sort_order = CASE
+				WHEN parent_category_id IS DISTINCT FROM $4 THEN (` + fmt.Sprintf(nextSortOrderQuery, 4) + `)
+				ELSE sort_order
+			END
 		WHERE category_id = $1
 	`
 
-	_, err := r.db.Pool.Exec(
+	_, err = tx.Exec(
 		ctx,
 		query,
 		category.CategoryID,
@@ -166,8 +198,11 @@ func (r *PostgresCategoryRepository) Update(ctx context.Context, category *model
 		category.Description,
 		category.ParentCategoryID,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return tx.Commit(ctx)
 }
 
 // Delete removes a category from the database
@@ -177,6 +212,187 @@ func (r *PostgresCategoryRepository) Delete(ctx context.Context, id int) error {
 	return err
 }
 
+// GetSiblings retrieves the subcategories of a parent, or the top level
+// categories when parentID is nil, in their sort order
+func (r *PostgresCategoryRepository) GetSiblings(ctx context.Context, parentID *int) ([]*models.Category, error) {
+	query := `
+		SELECT category_id, category_name, description, parent_category_id, sort_order, created_at, updated_at
+		FROM categories
+		WHERE parent_category_id IS NOT DISTINCT FROM $1
+		ORDER BY sort_order, category_name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	siblings := []*models.Category{}
+	for rows.Next() {
+		category := &models.Category{}
+		err := rows.Scan(
+			&category.CategoryID,
+			&category.CategoryName,
+			&category.Description,
+			&category.ParentCategoryID,
+			&category.SortOrder,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		siblings = append(siblings, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return siblings, nil
+}
+
+// IsInSubtree reports whether a category is the root category or lies
+// anywhere below it
+func (r *PostgresCategoryRepository) IsInSubtree(ctx context.Context, rootID, categoryID int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM category_subtree($1) WHERE category_id = $2)`
+
+	var inSubtree bool
+	err := r.db.Pool.QueryRow(ctx, query, rootID, categoryID).Scan(&inSubtree)
+	return inSubtree, err
+}
+
+// HasAttributeKeyConflict reports whether placing a category under the parent
+// would repeat an attribute key along a path through the tree: the keys of
+// the category and those below it against the parent and those above it
+func (r *PostgresCategoryRepository) HasAttributeKeyConflict(ctx context.Context, categoryID int, parentID *int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM category_attributes moved
+			JOIN category_attributes above ON above.attribute_key = moved.attribute_key
+			WHERE moved.category_id IN (SELECT category_id FROM category_subtree($1))
+				AND above.category_id IN (SELECT category_id FROM category_lineage($2::int))
+		)
+	`
+
+	var conflict bool
+	err := r.db.Pool.QueryRow(ctx, query, categoryID, parentID).Scan(&conflict)
+	return conflict, err
+}
+
+// Move places a category under a new parent at a position among its new
+// siblings, last when position is nil, and renumbers the siblings
+func (r *PostgresCategoryRepository) Move(ctx context.Context, categoryID int, parentID *int, position *int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockTree(ctx, tx, categoryID, parentID); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT category_id
+		FROM categories
+		WHERE parent_category_id IS NOT DISTINCT FROM $1 AND category_id <> $2
+		ORDER BY sort_order, category_name
+		FOR UPDATE
+	`, parentID, categoryID)
+	if err != nil {
+		return err
+	}
+	siblingIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return err
+	}
+
+	index := len(siblingIDs)
+	if position != nil && *position < index {
+		index = *position
+	}
+	order := make([]int, 0, len(siblingIDs)+1)
+	order = append(order, siblingIDs[:index]...)
+	order = append(order, categoryID)
+	order = append(order, siblingIDs[index:]...)
+
+	_, err = tx.Exec(ctx, `UPDATE categories SET parent_category_id = $2 WHERE category_id = $1`, categoryID, parentID)
+	if err != nil {
+		return err
+	}
+
+	if err := renumber(ctx, tx, order); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Reorder sets the sort order of sibling categories to their place in the list
+func (r *PostgresCategoryRepository) Reorder(ctx context.Context, categoryIDs []int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := renumber(ctx, tx, categoryIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteAndReassign moves a category's items and subcategories to another
+// category, the subcategories after the target's own, and then deletes it
+func (r *PostgresCategoryRepository) DeleteAndReassign(ctx context.Context, id, targetID int) (*models.ReassignResult, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockTree(ctx, tx, id, &targetID); err != nil {
+		return nil, err
+	}
+
+	result := &models.ReassignResult{
+		CategoryID:   id,
+		ReassignedTo: targetID,
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE categories c
+		SET parent_category_id = $2,
+			sort_order = n.next_order + c.sort_order
+		FROM (`+fmt.Sprintf(nextSortOrderQuery, 2)+`) AS n(next_order)
+		WHERE c.parent_category_id = $1
+	`, id, targetID)
+	if err != nil {
+		return nil, err
+	}
+	result.SubcategoriesReassigned = tag.RowsAffected()
+
+	tag, err = tx.Exec(ctx, `UPDATE items SET category_id = $2 WHERE category_id = $1`, id, targetID)
+	if err != nil {
+		return nil, err
+	}
+	result.ItemsReassigned = tag.RowsAffected()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM categories WHERE category_id = $1`, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // GetCategoryTree builds a hierarchical tree of categories
 func (r *PostgresCategoryRepository) GetCategoryTree(ctx context.Context) ([]*models.CategoryTreeNode, error) {
 	// First, get all categories
@@ -185,6 +401,16 @@ func (r *PostgresCategoryRepository) GetCategoryTree(ctx context.Context) ([]*mo
 		return nil, err
 	}
 
+	// Siblings follow their sort order, then their names as GetAll returns them
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].SortOrder < categories[j].SortOrder
+	})
+
+	itemCounts, err := r.getItemCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a map for quick lookup
 	categoryMap := make(map[int]*models.Category)
 	for _, category := range categories {
@@ -208,14 +434,18 @@ func (r *PostgresCategoryRepository) GetCategoryTree(ctx context.Context) ([]*mo
 
 	// Process child categories
 	for _, rootNode := range rootNodes {
-		buildSubtree(rootNode, categories)
+		buildSubtree(rootNode, categories, itemCounts)
 	}
 
 	return rootNodes, nil
 }
 
-// Helper function to build the category subtree
-func buildSubtree(node *models.CategoryTreeNode, allCategories []*models.Category) {
+// Helper function to build the category subtree, adding up the items of
+// each node and the nodes below it
+func buildSubtree(node *models.CategoryTreeNode, allCategories []*models.Category, itemCounts map[int]int) {
+	node.ItemCount = itemCounts[node.Category.CategoryID]
+	node.TotalItemCount = node.ItemCount
+
 	for _, category := range allCategories {
 		if category.ParentCategoryID != nil && *category.ParentCategoryID == node.Category.CategoryID {
 			childNode := &models.CategoryTreeNode{
@@ -223,7 +453,74 @@ func buildSubtree(node *models.CategoryTreeNode, allCategories []*models.Categor
 				Subcategories: []*models.CategoryTreeNode{},
 			}
 			node.Subcategories = append(node.Subcategories, childNode)
-			buildSubtree(childNode, allCategories)
+			buildSubtree(childNode, allCategories, itemCounts)
+			node.TotalItemCount += childNode.TotalItemCount
 		}
 	}
 }
+
+// getItemCounts counts the items filed directly under each category
+func (r *PostgresCategoryRepository) getItemCounts(ctx context.Context) (map[int]int, error) {
+	query := `
+		SELECT category_id, COUNT(*)
+		FROM items
+		WHERE category_id IS NOT NULL
+		GROUP BY category_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var categoryID, count int
+		if err := rows.Scan(&categoryID, &count); err != nil {
+			return nil, err
+		}
+		counts[categoryID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// Helper function to number categories in the order given
+func renumber(ctx context.Context, tx pgx.Tx, categoryIDs []int) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE categories c
+		SET sort_order = o.position - 1
+		FROM unnest($1::int[]) WITH ORDINALITY AS o(category_id, position)
+		WHERE c.category_id = o.category_id
+	`, categoryIDs)
+	return err
+}
+
+// lockTree holds off other changes to the category tree until the
+// transaction ends and then checks that parentID does not lie in the subtree
+// of categoryID, which the service checked before the transaction began
+func lockTree(ctx context.Context, tx pgx.Tx, categoryID int, parentID *int) error {
+	if _, err := tx.Exec(ctx, `LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+	if parentID == nil {
+		return nil
+	}
+
+	var circular bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM category_subtree($1) WHERE category_id = $2)
+	`, categoryID, *parentID).Scan(&circular)
+	if err != nil {
+		return err
+	}
+	if circular {
+		return ErrCircularParent
+	}
+	return nil
+}
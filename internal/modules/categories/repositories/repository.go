@@ -14,4 +14,10 @@ type CategoryRepository interface {
 	Update(ctx context.Context, categoty *models.Category) error
 	Delete(ctx context.Context, id int) error
 	GetCategoryTree(ctx context.Context) ([]*models.CategoryTreeNode, error)
+	GetSiblings(ctx context.Context, parentID *int) ([]*models.Category, error)
+	IsInSubtree(ctx context.Context, rootID, categoryID int) (bool, error)
+	HasAttributeKeyConflict(ctx context.Context, categoryID int, parentID *int) (bool, error)
+	Move(ctx context.Context, categoryID int, parentID *int, position *int) error
+	Reorder(ctx context.Context, categoryIDs []int) error
+	DeleteAndReassign(ctx context.Context, id, targetID int) (*models.ReassignResult, error)
 }
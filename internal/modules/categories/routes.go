@@ -13,6 +13,10 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	service := services.NewCategoryService(repo)
 	handler := handlers.NewCategoryHandler(service)
 
+	attributeRepo := repositories.NewPostgresAttributeRepository(database)
+	attributeService := services.NewAttributeService(attributeRepo, repo)
+	attributeHandler := handlers.NewAttributeHandler(attributeService)
+
 	categories := api.Group("/categories")
 	categories.GET("", handler.GetAllCategories)
 	categories.GET("/:id", handler.GetCategoryByID)
@@ -21,4 +25,11 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	categories.PUT("/:id", handler.UpdateCategory)
 	categories.DELETE("/:id", handler.DeleteCategory)
 	categories.GET("/tree", handler.GetCategoryTree)
+	categories.POST("/:id/move", handler.MoveCategory)
+	categories.PUT("/order", handler.ReorderCategories)
+
+	categories.GET("/:id/attributes", attributeHandler.GetAttributes)
+	categories.POST("/:id/attributes", attributeHandler.CreateAttribute)
+	categories.PUT("/:id/attributes/:attributeId", attributeHandler.UpdateAttribute)
+	categories.DELETE("/:id/attributes/:attributeId", attributeHandler.DeleteAttribute)
 }
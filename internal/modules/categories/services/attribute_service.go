@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/hsrvms/autoparts/internal/modules/categories/models"
+	"github.com/hsrvms/autoparts/internal/modules/categories/repositories"
+)
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// AttributeService defines the interface for category attribute operations
+type AttributeService interface {
+	GetAttributes(ctx context.Context, categoryID int) ([]*models.CategoryAttribute, error)
+	CreateAttribute(ctx context.Context, attribute *models.CategoryAttribute) (*models.CategoryAttribute, error)
+	UpdateAttribute(ctx context.Context, attribute *models.CategoryAttribute) (*models.CategoryAttribute, error)
+	DeleteAttribute(ctx context.Context, categoryID, attributeID int) error
+}
+
+// attributeService implements AttributeService
+type attributeService struct {
+	repo         repositories.AttributeRepository
+	categoryRepo repositories.CategoryRepository
+}
+
+// NewAttributeService creates a new category attribute service
+func NewAttributeService(repo repositories.AttributeRepository, categoryRepo repositories.CategoryRepository) AttributeService {
+	return &attributeService{
+		repo:         repo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// GetAttributes returns the attributes items of a category carry, including
+// those inherited from the categories above it
+func (s *attributeService) GetAttributes(ctx context.Context, categoryID int) ([]*models.CategoryAttribute, error) {
+	if err := s.checkCategory(ctx, categoryID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetEffectiveAttributes(ctx, categoryID)
+}
+
+// CreateAttribute adds an attribute to a category. The key must not be used
+// along any path through the category, so an item never sees it twice.
+func (s *attributeService) CreateAttribute(ctx context.Context, attribute *models.CategoryAttribute) (*models.CategoryAttribute, error) {
+	if err := s.checkCategory(ctx, attribute.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := validateAttribute(attribute); err != nil {
+		return nil, err
+	}
+
+	inUse, err := s.repo.KeyInUse(ctx, attribute.CategoryID, attribute.AttributeKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, ErrDuplicateAttributeKey
+	}
+
+	id, err := s.repo.Create(ctx, attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// UpdateAttribute modifies an attribute of a category. Its key and data type
+// are fixed once items have values for it; values that no longer fit
+// narrowed choices or bounds are rejected the next time the item is saved.
+func (s *attributeService) UpdateAttribute(ctx context.Context, attribute *models.CategoryAttribute) (*models.CategoryAttribute, error) {
+	existing, err := s.getAttribute(ctx, attribute.CategoryID, attribute.AttributeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAttribute(attribute); err != nil {
+		return nil, err
+	}
+
+	if attribute.AttributeKey != existing.AttributeKey || attribute.DataType != existing.DataType {
+		count, err := s.repo.CountValues(ctx, attribute.AttributeID)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrAttributeInUse
+		}
+	}
+
+	if attribute.AttributeKey != existing.AttributeKey {
+		inUse, err := s.repo.KeyInUse(ctx, attribute.CategoryID, attribute.AttributeKey, attribute.AttributeID)
+		if err != nil {
+			return nil, err
+		}
+		if inUse {
+			return nil, ErrDuplicateAttributeKey
+		}
+	}
+
+	if err := s.repo.Update(ctx, attribute); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, attribute.AttributeID)
+}
+
+// DeleteAttribute removes an attribute of a category along with the values
+// items have for it
+func (s *attributeService) DeleteAttribute(ctx context.Context, categoryID, attributeID int) error {
+	if _, err := s.getAttribute(ctx, categoryID, attributeID); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, attributeID)
+}
+
+// Helper functions
+
+// checkCategory makes sure a category exists
+func (s *attributeService) checkCategory(ctx context.Context, categoryID int) error {
+	category, err := s.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// getAttribute returns an attribute defined by the category itself
+func (s *attributeService) getAttribute(ctx context.Context, categoryID, attributeID int) (*models.CategoryAttribute, error) {
+	if err := s.checkCategory(ctx, categoryID); err != nil {
+		return nil, err
+	}
+
+	attribute, err := s.repo.GetByID(ctx, attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if attribute == nil || attribute.CategoryID != categoryID {
+		return nil, ErrAttributeNotFound
+	}
+	return attribute, nil
+}
+
+// validateAttribute normalises an attribute definition and checks that its
+// settings suit its data type
+func validateAttribute(attribute *models.CategoryAttribute) error {
+	attribute.AttributeKey = strings.TrimSpace(attribute.AttributeKey)
+	attribute.Label = strings.TrimSpace(attribute.Label)
+	attribute.DataType = strings.ToLower(strings.TrimSpace(attribute.DataType))
+
+	if !attributeKeyPattern.MatchString(attribute.AttributeKey) || len(attribute.AttributeKey) > 50 {
+		return ErrInvalidAttributeKey
+	}
+	if attribute.Label == "" {
+		return ErrAttributeLabelRequired
+	}
+
+	switch attribute.DataType {
+	case models.AttributeText, models.AttributeNumber, models.AttributeBoolean, models.AttributeEnum:
+	default:
+		return ErrInvalidAttributeType
+	}
+
+	if attribute.Unit != nil {
+		unit := strings.TrimSpace(*attribute.Unit)
+		if unit == "" {
+			attribute.Unit = nil
+		} else {
+			attribute.Unit = &unit
+		}
+	}
+	if attribute.DataType != models.AttributeNumber &&
+		(attribute.Unit != nil || attribute.MinValue != nil || attribute.MaxValue != nil) {
+		return ErrAttributeBoundsNotNumber
+	}
+	if attribute.MinValue != nil && attribute.MaxValue != nil && *attribute.MinValue > *attribute.MaxValue {
+		return ErrInvalidAttributeBounds
+	}
+
+	values := []string{}
+	seen := make(map[string]bool)
+	for _, value := range attribute.AllowedValues {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[strings.ToLower(value)] {
+			continue
+		}
+		seen[strings.ToLower(value)] = true
+		values = append(values, value)
+	}
+
+	if attribute.DataType == models.AttributeEnum {
+		if len(values) == 0 {
+			return ErrAttributeValuesRequired
+		}
+		attribute.AllowedValues = values
+	} else {
+		if len(values) > 0 {
+			return ErrAttributeValuesNotEnum
+		}
+		attribute.AllowedValues = nil
+	}
+
+	return nil
+}
@@ -7,5 +7,24 @@ var (
 	ErrCategoryNotFound         = errors.New("category not found")
 	ErrParentCategoryNotFound   = errors.New("parent category not found")
 	ErrCategoryHasSubcategories = errors.New("category has subcategories and cannot be deleted")
-	ErrCircularReference        = errors.New("circular reference detected: a category cannot be its own parent")
+	ErrCircularReference        = errors.New("circular reference detected: a category cannot be placed under itself or one of its subcategories")
+	ErrInvalidPosition          = errors.New("position cannot be negative")
+	ErrInvalidSiblingOrder      = errors.New("category_ids must list every subcategory of the parent exactly once")
+	ErrReassignTargetNotFound   = errors.New("category to reassign to not found")
+	ErrReassignIntoSubtree      = errors.New("cannot reassign to the category being deleted or one of its subcategories")
+	ErrAttributeKeyConflict     = errors.New("the new parent or a category above it defines an attribute key already used by the category being moved or a category below it")
+)
+
+// Attribute errors
+var (
+	ErrAttributeNotFound        = errors.New("attribute not found")
+	ErrInvalidAttributeKey      = errors.New("attribute key must start with a lowercase letter and contain only lowercase letters, digits and underscores")
+	ErrAttributeLabelRequired   = errors.New("attribute label is required")
+	ErrInvalidAttributeType     = errors.New("attribute data type must be text, number, boolean or enum")
+	ErrAttributeValuesRequired  = errors.New("enum attributes need at least one allowed value")
+	ErrAttributeValuesNotEnum   = errors.New("only enum attributes have allowed values")
+	ErrAttributeBoundsNotNumber = errors.New("only number attributes have a unit, minimum or maximum")
+	ErrInvalidAttributeBounds   = errors.New("attribute minimum cannot be greater than its maximum")
+	ErrDuplicateAttributeKey    = errors.New("attribute key is already used by this category, a category above it or a category below it")
+	ErrAttributeInUse           = errors.New("items have values for this attribute; its key and data type cannot be changed")
 )
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github.com/hsrvms/autoparts/internal/modules/categories/models"
 	"github.com/hsrvms/autoparts/internal/modules/categories/repositories"
@@ -16,6 +17,9 @@ type CategoryService interface {
 	UpdateCategory(ctx context.Context, category *models.Category) error
 	DeleteCategory(ctx context.Context, id int) error
 	GetCategoryTree(ctx context.Context) ([]*models.CategoryTreeNode, error)
+	MoveCategory(ctx context.Context, id int, request *models.MoveRequest) (*models.Category, error)
+	ReorderCategories(ctx context.Context, request *models.ReorderRequest) ([]*models.Category, error)
+	DeleteAndReassign(ctx context.Context, id, targetID int) (*models.ReassignResult, error)
 }
 
 // categoryService implements CategoryService
@@ -88,23 +92,18 @@ func (s *categoryService) UpdateCategory(ctx context.Context, category *models.C
 		return ErrCategoryNotFound
 	}
 
-	// Check if parent category exists (if one is specified)
-	if category.ParentCategoryID != nil {
-		parent, err := s.repo.GetByID(ctx, *category.ParentCategoryID)
-		if err != nil {
-			return err
-		}
-		if parent == nil {
-			return ErrParentCategoryNotFound
-		}
+	// Check the new parent exists and is not below the category
+	if err := s.checkNewParent(ctx, category.CategoryID, category.ParentCategoryID); err != nil {
+		return err
+	}
 
-		// Prevent circular references
-		if *category.ParentCategoryID == category.CategoryID {
+	if err := s.repo.Update(ctx, category); err != nil {
+		if errors.Is(err, repositories.ErrCircularParent) {
 			return ErrCircularReference
 		}
+		return err
 	}
-
-	return s.repo.Update(ctx, category)
+	return nil
 }
 
 // DeleteCategory removes a category
@@ -134,3 +133,156 @@ func (s *categoryService) DeleteCategory(ctx context.Context, id int) error {
 func (s *categoryService) GetCategoryTree(ctx context.Context) ([]*models.CategoryTreeNode, error) {
 	return s.repo.GetCategoryTree(ctx)
 }
+
+// MoveCategory places a category, with everything below it, under a new
+// parent at a position among its new siblings
+func (s *categoryService) MoveCategory(ctx context.Context, id int, request *models.MoveRequest) (*models.Category, error) {
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	if request.Position != nil && *request.Position < 0 {
+		return nil, ErrInvalidPosition
+	}
+	if err := s.checkNewParent(ctx, id, request.ParentCategoryID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Move(ctx, id, request.ParentCategoryID, request.Position); err != nil {
+		if errors.Is(err, repositories.ErrCircularParent) {
+			return nil, ErrCircularReference
+		}
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// ReorderCategories sets the order of the subcategories of a parent. The
+// list must hold each of them exactly once.
+func (s *categoryService) ReorderCategories(ctx context.Context, request *models.ReorderRequest) ([]*models.Category, error) {
+	if request.ParentCategoryID != nil {
+		parent, err := s.repo.GetByID(ctx, *request.ParentCategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, ErrParentCategoryNotFound
+		}
+	}
+
+	siblings, err := s.repo.GetSiblings(ctx, request.ParentCategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(request.CategoryIDs) != len(siblings) {
+		return nil, ErrInvalidSiblingOrder
+	}
+
+	listed := make(map[int]bool, len(request.CategoryIDs))
+	for _, id := range request.CategoryIDs {
+		listed[id] = true
+	}
+	for _, sibling := range siblings {
+		if !listed[sibling.CategoryID] {
+			return nil, ErrInvalidSiblingOrder
+		}
+	}
+
+	if err := s.repo.Reorder(ctx, request.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSiblings(ctx, request.ParentCategoryID)
+}
+
+// DeleteAndReassign removes a category after moving its items and
+// subcategories to the target category. Items lose the values of
+// attributes the target does not have.
+func (s *categoryService) DeleteAndReassign(ctx context.Context, id, targetID int) (*models.ReassignResult, error) {
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	target, err := s.repo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrReassignTargetNotFound
+	}
+
+	inSubtree, err := s.repo.IsInSubtree(ctx, id, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if inSubtree {
+		return nil, ErrReassignIntoSubtree
+	}
+
+	subcategories, err := s.repo.GetSubcategories(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, subcategory := range subcategories {
+		conflict, err := s.repo.HasAttributeKeyConflict(ctx, subcategory.CategoryID, &targetID)
+		if err != nil {
+			return nil, err
+		}
+		if conflict {
+			return nil, ErrAttributeKeyConflict
+		}
+	}
+
+	result, err := s.repo.DeleteAndReassign(ctx, id, targetID)
+	if errors.Is(err, repositories.ErrCircularParent) {
+		return nil, ErrReassignIntoSubtree
+	}
+	return result, err
+}
+
+// Helper functions
+
+// checkNewParent makes sure a category can be placed under the parent: the
+// parent exists, is not the category or below it, and repeats none of the
+// attribute keys of the category's subtree
+func (s *categoryService) checkNewParent(ctx context.Context, categoryID int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+
+	parent, err := s.repo.GetByID(ctx, *parentID)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return ErrParentCategoryNotFound
+	}
+
+	// Prevent circular references at any depth
+	inSubtree, err := s.repo.IsInSubtree(ctx, categoryID, *parentID)
+	if err != nil {
+		return err
+	}
+	if inSubtree {
+		return ErrCircularReference
+	}
+
+	conflict, err := s.repo.HasAttributeKeyConflict(ctx, categoryID, parentID)
+	if err != nil {
+		return err
+	}
+	if conflict {
+		return ErrAttributeKeyConflict
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	coremodels "github.com/hsrvms/autoparts/internal/modules/cores/models"
+	"github.com/hsrvms/autoparts/internal/modules/cores/services"
+	"github.com/labstack/echo/v4"
+)
+
+type CoreHandler struct {
+	service services.CoreService
+}
+
+func NewCoreHandler(service services.CoreService) *CoreHandler {
+	return &CoreHandler{
+		service: service,
+	}
+}
+
+// GetSaleCore handles retrieval of the core deposit on a sale and the cores
+// still owed against it
+func (h *CoreHandler) GetSaleCore(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sale ID")
+	}
+
+	ctx := c.Request().Context()
+	core, err := h.service.GetSaleCore(ctx, id)
+	if err != nil {
+		return coreError(err)
+	}
+
+	return c.JSON(http.StatusOK, core)
+}
+
+// GetCoreReturns handles retrieval of cores returned by customers
+func (h *CoreHandler) GetCoreReturns(c echo.Context) error {
+	filter := &coremodels.CoreReturnFilter{}
+
+	if saleID, err := strconv.Atoi(c.QueryParam("sale_id")); err == nil {
+		filter.SaleID = &saleID
+	}
+
+	if itemID, err := strconv.Atoi(c.QueryParam("item_id")); err == nil {
+		filter.ItemID = &itemID
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		date, err := parseDate(startDate)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid start_date")
+		}
+		filter.StartDate = &date
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		date, err := parseDate(endDate)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid end_date")
+		}
+		filter.EndDate = &date
+	}
+
+	ctx := c.Request().Context()
+	returns, err := h.service.GetAllReturns(ctx, filter)
+	if err != nil {
+		return coreError(err)
+	}
+
+	return c.JSON(http.StatusOK, returns)
+}
+
+// GetCoreReturnByID handles retrieval of a single core return
+func (h *CoreHandler) GetCoreReturnByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid core return ID")
+	}
+
+	ctx := c.Request().Context()
+	coreReturn, err := h.service.GetReturnByID(ctx, id)
+	if err != nil {
+		return coreError(err)
+	}
+
+	return c.JSON(http.StatusOK, coreReturn)
+}
+
+// CreateCoreReturn handles a customer bringing back an old unit for their
+// deposit
+func (h *CoreHandler) CreateCoreReturn(c echo.Context) error {
+	coreReturn := new(coremodels.CoreReturn)
+	if err := c.Bind(coreReturn); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.CreateReturn(ctx, coreReturn)
+	if err != nil {
+		return coreError(err)
+	}
+
+	created, err := h.service.GetReturnByID(ctx, id)
+	if err != nil {
+		return coreError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// GetCoreStock handles retrieval of cores on hand and deposits outstanding
+func (h *CoreHandler) GetCoreStock(c echo.Context) error {
+	filter := &coremodels.CoreStockFilter{
+		OnHandOnly: c.QueryParam("on_hand_only") == "true",
+	}
+
+	if supplierID, err := strconv.Atoi(c.QueryParam("supplier_id")); err == nil {
+		filter.SupplierID = &supplierID
+	}
+
+	ctx := c.Request().Context()
+	stock, err := h.service.GetCoreStock(ctx, filter)
+	if err != nil {
+		return coreError(err)
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// GetSupplierReturns handles retrieval of cores sent back to suppliers
+func (h *CoreHandler) GetSupplierReturns(c echo.Context) error {
+	filter := &coremodels.SupplierReturnFilter{}
+
+	if supplierID, err := strconv.Atoi(c.QueryParam("supplier_id")); err == nil {
+		filter.SupplierID = &supplierID
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	ctx := c.Request().Context()
+	returns, err := h.service.GetAllSupplierReturns(ctx, filter)
+	if err != nil {
+		return coreError(err)
+	}
+
+	return c.JSON(http.StatusOK, returns)
+}
+
+// GetSupplierReturnByID handles retrieval of a supplier core return with its lines
+func (h *CoreHandler) GetSupplierReturnByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier core return ID")
+	}
+
+	ctx := c.Request().Context()
+	supplierReturn, err := h.service.GetSupplierReturnByID(ctx, id)
+	if err != nil {
+		return coreError(err)
+	}
+
+	return c.JSON(http.StatusOK, supplierReturn)
+}
+
+// CreateSupplierReturn handles sending cores on hand back to a supplier
+func (h *CoreHandler) CreateSupplierReturn(c echo.Context) error {
+	supplierReturn := new(coremodels.SupplierReturn)
+	if err := c.Bind(supplierReturn); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.CreateSupplierReturn(ctx, supplierReturn)
+	if err != nil {
+		return coreError(err)
+	}
+
+	created, err := h.service.GetSupplierReturnByID(ctx, id)
+	if err != nil {
+		return coreError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// CreditSupplierReturn handles recording the supplier's credit for returned cores
+func (h *CoreHandler) CreditSupplierReturn(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier core return ID")
+	}
+
+	req := new(coremodels.CreditRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	supplierReturn, err := h.service.MarkCredited(ctx, id, req)
+	if err != nil {
+		return coreError(err)
+	}
+
+	return c.JSON(http.StatusOK, supplierReturn)
+}
+
+func coreError(err error) error {
+	switch err {
+	case services.ErrInvalidQuantity, services.ErrInvalidCondition, services.ErrInvalidRefundMethod,
+		services.ErrInvalidRefundAmount, services.ErrInvalidStatus, services.ErrSupplierRequired,
+		services.ErrLinesRequired, services.ErrUnitCreditRequired, services.ErrInvalidCredit:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrSaleNotFound, services.ErrCoreReturnNotFound, services.ErrSupplierReturnNotFound,
+		services.ErrSupplierNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrSupplierReturnCredited:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrNoCoreDeposit, services.ErrCoresNotOwed, services.ErrItemHasNoCores,
+		services.ErrInsufficientCores, services.ErrAccountRefundRequired:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
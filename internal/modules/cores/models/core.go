@@ -0,0 +1,125 @@
+package coremodels
+
+import "time"
+
+// Condition of a returned core
+const (
+	ConditionGood    = "good"
+	ConditionDamaged = "damaged"
+)
+
+// Supplier core return statuses
+const (
+	SupplierReturnSent     = "sent"
+	SupplierReturnCredited = "credited"
+)
+
+// SaleCore is the core deposit taken on a sale and what has been returned
+// against it
+type SaleCore struct {
+	SaleID            int     `json:"sale_id"`
+	TransactionNumber string  `json:"transaction_number"`
+	ItemID            int     `json:"item_id"`
+	ItemPartNumber    string  `json:"item_part_number"`
+	ItemDescription   string  `json:"item_description"`
+	Quantity          int     `json:"quantity"`
+	CoreDeposit       float64 `json:"core_deposit"`
+	PaymentMethod     string  `json:"payment_method"`
+	QuantityReturned  int     `json:"quantity_returned"`
+	AmountRefunded    float64 `json:"amount_refunded"`
+	QuantityOwed      int     `json:"quantity_owed"`
+	DepositHeld       float64 `json:"deposit_held"`
+}
+
+// CoreReturn is an old unit brought back by a customer. The refund is paid
+// out at the counter, or credited to the account for account sales; a
+// damaged core may be refunded less than its deposit.
+type CoreReturn struct {
+	CoreReturnID int       `json:"core_return_id" db:"core_return_id"`
+	SaleID       int       `json:"sale_id" db:"sale_id"`
+	ItemID       int       `json:"item_id" db:"item_id"`
+	Quantity     int       `json:"quantity" db:"quantity"`
+	Condition    string    `json:"condition" db:"condition"`
+	RefundAmount *float64  `json:"refund_amount,omitempty" db:"refund_amount"` // Defaults to the deposit taken for the units
+	RefundMethod string    `json:"refund_method" db:"refund_method"`
+	PaymentID    *int      `json:"payment_id,omitempty" db:"payment_id"` // Account credit for account sales
+	ReceivedBy   *string   `json:"received_by,omitempty" db:"received_by"`
+	Notes        *string   `json:"notes,omitempty" db:"notes"`
+	ReceivedAt   time.Time `json:"received_at" db:"received_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	TransactionNumber string `json:"transaction_number,omitempty" db:"transaction_number"`
+	ItemPartNumber    string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription   string `json:"item_description,omitempty" db:"item_description"`
+}
+
+type CoreReturnFilter struct {
+	SaleID    *int       `query:"sale_id"`
+	ItemID    *int       `query:"item_id"`
+	StartDate *time.Time `query:"start_date"`
+	EndDate   *time.Time `query:"end_date"`
+}
+
+// CoreStock is the cores of an item held for return to the supplier, with
+// the deposits still out with customers
+type CoreStock struct {
+	ItemID             int      `json:"item_id"`
+	ItemPartNumber     string   `json:"item_part_number"`
+	ItemDescription    string   `json:"item_description"`
+	CoreCharge         *float64 `json:"core_charge,omitempty"`
+	SupplierID         *int     `json:"supplier_id,omitempty"`
+	SupplierName       *string  `json:"supplier_name,omitempty"`
+	Received           int      `json:"received"`
+	ReturnedToSupplier int      `json:"returned_to_supplier"`
+	OnHand             int      `json:"on_hand"`
+	Outstanding        int      `json:"outstanding"` // Sold with a deposit and not yet brought back
+	DepositsHeld       float64  `json:"deposits_held"`
+}
+
+type CoreStockFilter struct {
+	SupplierID *int `query:"supplier_id"`
+	OnHandOnly bool `query:"on_hand_only"`
+}
+
+// SupplierReturn is a shipment of cores back to a supplier for credit
+type SupplierReturn struct {
+	SupplierReturnID int                   `json:"supplier_return_id" db:"core_supplier_return_id"`
+	ReturnNumber     string                `json:"return_number" db:"return_number"`
+	SupplierID       int                   `json:"supplier_id" db:"supplier_id"`
+	Status           string                `json:"status" db:"status"`
+	ExpectedCredit   float64               `json:"expected_credit" db:"expected_credit"`
+	CreditAmount     *float64              `json:"credit_amount,omitempty" db:"credit_amount"`
+	CreditReference  *string               `json:"credit_reference,omitempty" db:"credit_reference"`
+	SentAt           time.Time             `json:"sent_at" db:"sent_at"`
+	CreditedAt       *time.Time            `json:"credited_at,omitempty" db:"credited_at"`
+	Notes            *string               `json:"notes,omitempty" db:"notes"`
+	CreatedBy        *string               `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt        time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at" db:"updated_at"`
+	Lines            []*SupplierReturnLine `json:"lines" db:"-"`
+
+	// Additional fields for API responses
+	SupplierName string `json:"supplier_name,omitempty" db:"supplier_name"`
+}
+
+type SupplierReturnLine struct {
+	ItemID     int      `json:"item_id" db:"item_id"`
+	Quantity   int      `json:"quantity" db:"quantity"`
+	UnitCredit *float64 `json:"unit_credit,omitempty" db:"unit_credit"` // Defaults to the item's core charge
+
+	// Additional fields for API responses
+	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+}
+
+type SupplierReturnFilter struct {
+	SupplierID *int    `query:"supplier_id"`
+	Status     *string `query:"status"`
+}
+
+// CreditRequest records the credit a supplier gave for a core return
+type CreditRequest struct {
+	CreditAmount    float64 `json:"credit_amount"`
+	CreditReference *string `json:"credit_reference,omitempty"`
+}
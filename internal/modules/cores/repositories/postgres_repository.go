@@ -0,0 +1,593 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	coremodels "github.com/hsrvms/autoparts/internal/modules/cores/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresCoreRepository struct {
+	db *db.Database
+}
+
+func NewPostgresCoreRepository(database *db.Database) CoreRepository {
+	return &PostgresCoreRepository{
+		db: database,
+	}
+}
+
+const coreReturnQuery = `
+	SELECT
+		cr.core_return_id, cr.sale_id, cr.item_id, cr.quantity, cr.condition,
+		cr.refund_amount, cr.refund_method, cr.payment_id, cr.received_by, cr.notes,
+		cr.received_at, cr.created_at,
+		COALESCE(s.transaction_number, ''),
+		i.part_number as item_part_number,
+		i.description as item_description
+	FROM core_returns cr
+	JOIN sales s ON cr.sale_id = s.sale_id
+	JOIN items i ON cr.item_id = i.item_id
+`
+
+// Cores received from customers and sent back to suppliers per item, and the
+// deposits on sales still waiting for the old unit
+const coreStockQuery = `
+	SELECT
+		i.item_id, i.part_number, i.description, i.core_charge, i.supplier_id, su.name,
+		COALESCE(rc.quantity, 0), COALESCE(sr.quantity, 0),
+		COALESCE(rc.quantity, 0) - COALESCE(sr.quantity, 0),
+		COALESCE(sc.owed, 0), COALESCE(sc.held, 0)
+	FROM items i
+	LEFT JOIN suppliers su ON i.supplier_id = su.supplier_id
+	LEFT JOIN (
+		SELECT item_id, SUM(quantity) AS quantity FROM core_returns GROUP BY item_id
+	) rc ON rc.item_id = i.item_id
+	LEFT JOIN (
+		SELECT item_id, SUM(quantity) AS quantity FROM core_supplier_return_lines GROUP BY item_id
+	) sr ON sr.item_id = i.item_id
+	LEFT JOIN (
+		SELECT
+			s.item_id,
+			SUM(s.quantity - COALESCE(r.quantity, 0)) AS owed,
+			SUM(s.core_deposit / s.quantity * (s.quantity - COALESCE(r.quantity, 0))) AS held
+		FROM sales s
+		LEFT JOIN (
+			SELECT sale_id, SUM(quantity) AS quantity FROM core_returns GROUP BY sale_id
+		) r ON r.sale_id = s.sale_id
+		WHERE s.core_deposit > 0
+		GROUP BY s.item_id
+	) sc ON sc.item_id = i.item_id
+	WHERE (i.core_charge IS NOT NULL OR rc.quantity IS NOT NULL)
+`
+
+const supplierReturnQuery = `
+	SELECT
+		csr.core_supplier_return_id, csr.return_number, csr.supplier_id, csr.status,
+		csr.expected_credit, csr.credit_amount, csr.credit_reference, csr.sent_at,
+		csr.credited_at, csr.notes, csr.created_by, csr.created_at, csr.updated_at,
+		su.name as supplier_name
+	FROM core_supplier_returns csr
+	JOIN suppliers su ON csr.supplier_id = su.supplier_id
+`
+
+func scanCoreReturn(row pgx.Row) (*coremodels.CoreReturn, error) {
+	coreReturn := &coremodels.CoreReturn{}
+	err := row.Scan(
+		&coreReturn.CoreReturnID,
+		&coreReturn.SaleID,
+		&coreReturn.ItemID,
+		&coreReturn.Quantity,
+		&coreReturn.Condition,
+		&coreReturn.RefundAmount,
+		&coreReturn.RefundMethod,
+		&coreReturn.PaymentID,
+		&coreReturn.ReceivedBy,
+		&coreReturn.Notes,
+		&coreReturn.ReceivedAt,
+		&coreReturn.CreatedAt,
+		&coreReturn.TransactionNumber,
+		&coreReturn.ItemPartNumber,
+		&coreReturn.ItemDescription,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return coreReturn, nil
+}
+
+func scanCoreStock(row pgx.Row) (*coremodels.CoreStock, error) {
+	stock := &coremodels.CoreStock{}
+	err := row.Scan(
+		&stock.ItemID,
+		&stock.ItemPartNumber,
+		&stock.ItemDescription,
+		&stock.CoreCharge,
+		&stock.SupplierID,
+		&stock.SupplierName,
+		&stock.Received,
+		&stock.ReturnedToSupplier,
+		&stock.OnHand,
+		&stock.Outstanding,
+		&stock.DepositsHeld,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+func scanSupplierReturn(row pgx.Row) (*coremodels.SupplierReturn, error) {
+	supplierReturn := &coremodels.SupplierReturn{}
+	err := row.Scan(
+		&supplierReturn.SupplierReturnID,
+		&supplierReturn.ReturnNumber,
+		&supplierReturn.SupplierID,
+		&supplierReturn.Status,
+		&supplierReturn.ExpectedCredit,
+		&supplierReturn.CreditAmount,
+		&supplierReturn.CreditReference,
+		&supplierReturn.SentAt,
+		&supplierReturn.CreditedAt,
+		&supplierReturn.Notes,
+		&supplierReturn.CreatedBy,
+		&supplierReturn.CreatedAt,
+		&supplierReturn.UpdatedAt,
+		&supplierReturn.SupplierName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return supplierReturn, nil
+}
+
+func (r *PostgresCoreRepository) GetSaleCore(ctx context.Context, saleID int) (*coremodels.SaleCore, error) {
+	query := `
+		SELECT
+			s.sale_id, COALESCE(s.transaction_number, ''), s.item_id, i.part_number,
+			i.description, s.quantity, s.core_deposit, s.payment_method,
+			COALESCE(SUM(cr.quantity), 0), COALESCE(SUM(cr.refund_amount), 0)
+		FROM sales s
+		JOIN items i ON s.item_id = i.item_id
+		LEFT JOIN core_returns cr ON cr.sale_id = s.sale_id
+		WHERE s.sale_id = $1
+		GROUP BY s.sale_id, i.item_id
+	`
+
+	core := &coremodels.SaleCore{}
+	err := r.db.Pool.QueryRow(ctx, query, saleID).Scan(
+		&core.SaleID,
+		&core.TransactionNumber,
+		&core.ItemID,
+		&core.ItemPartNumber,
+		&core.ItemDescription,
+		&core.Quantity,
+		&core.CoreDeposit,
+		&core.PaymentMethod,
+		&core.QuantityReturned,
+		&core.AmountRefunded,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return core, nil
+}
+
+func (r *PostgresCoreRepository) GetAllReturns(ctx context.Context, filter *coremodels.CoreReturnFilter) ([]*coremodels.CoreReturn, error) {
+	query := coreReturnQuery + " WHERE 1=1"
+
+	var params []interface{}
+	paramCount := 1
+
+	if filter.SaleID != nil {
+		query += fmt.Sprintf(" AND cr.sale_id = $%d", paramCount)
+		params = append(params, *filter.SaleID)
+		paramCount++
+	}
+
+	if filter.ItemID != nil {
+		query += fmt.Sprintf(" AND cr.item_id = $%d", paramCount)
+		params = append(params, *filter.ItemID)
+		paramCount++
+	}
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(" AND cr.received_at >= $%d", paramCount)
+		params = append(params, *filter.StartDate)
+		paramCount++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(" AND cr.received_at <= $%d", paramCount)
+		params = append(params, *filter.EndDate)
+		paramCount++
+	}
+
+	query += " ORDER BY cr.received_at DESC, cr.core_return_id DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var returns []*coremodels.CoreReturn
+	for rows.Next() {
+		coreReturn, err := scanCoreReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, coreReturn)
+	}
+
+	return returns, rows.Err()
+}
+
+func (r *PostgresCoreRepository) GetReturnByID(ctx context.Context, id int) (*coremodels.CoreReturn, error) {
+	coreReturn, err := scanCoreReturn(r.db.Pool.QueryRow(ctx, coreReturnQuery+" WHERE cr.core_return_id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return coreReturn, nil
+}
+
+// CreateReturn records a core brought back against a sale. The sale is
+// locked so two returns at once cannot refund the same deposit twice. A
+// refund to the account is raised as a credit on the customer's account and
+// set against the sale's invoice when it has one.
+func (r *PostgresCoreRepository) CreateReturn(ctx context.Context, coreReturn *coremodels.CoreReturn) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var owed int
+	err = tx.QueryRow(ctx, `
+		SELECT s.quantity - COALESCE((
+			SELECT SUM(quantity) FROM core_returns WHERE sale_id = s.sale_id
+		), 0)
+		FROM sales s
+		WHERE s.sale_id = $1
+		FOR UPDATE
+	`, coreReturn.SaleID).Scan(&owed)
+	if err != nil {
+		return 0, err
+	}
+	if coreReturn.Quantity > owed {
+		return 0, errors.New("more cores returned than are owed on the sale")
+	}
+
+	query := `
+		INSERT INTO core_returns (
+			sale_id, item_id, quantity, condition, refund_amount,
+			refund_method, received_by, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING core_return_id
+	`
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		coreReturn.SaleID,
+		coreReturn.ItemID,
+		coreReturn.Quantity,
+		coreReturn.Condition,
+		coreReturn.RefundAmount,
+		coreReturn.RefundMethod,
+		coreReturn.ReceivedBy,
+		coreReturn.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if coreReturn.RefundMethod == "account" && *coreReturn.RefundAmount > 0 {
+		if err := creditAccount(ctx, tx, id, coreReturn); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// creditAccount records the refund of a core return as a core_credit payment
+// from the sale's customer. A credit on an invoiced sale is allocated to the
+// invoice; on a sale not yet invoiced it is allocated when the invoice is
+// raised.
+func creditAccount(ctx context.Context, tx pgx.Tx, coreReturnID int, coreReturn *coremodels.CoreReturn) error {
+	var customerID int
+	var invoiceID *int
+	err := tx.QueryRow(ctx, `SELECT customer_id, invoice_id FROM sales WHERE sale_id = $1`, coreReturn.SaleID).
+		Scan(&customerID, &invoiceID)
+	if err != nil {
+		return err
+	}
+
+	var paymentID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO payments (customer_id, amount, method, reference, received_by, notes)
+		VALUES ($1, $2::numeric, 'core_credit', $3, $4, 'Core deposit refund')
+		RETURNING payment_id
+	`, customerID, *coreReturn.RefundAmount, fmt.Sprintf("CORE-%d", coreReturnID), coreReturn.ReceivedBy).Scan(&paymentID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE core_returns SET payment_id = $2 WHERE core_return_id = $1`, coreReturnID, paymentID); err != nil {
+		return err
+	}
+	coreReturn.PaymentID = &paymentID
+
+	if invoiceID == nil {
+		return nil
+	}
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		UPDATE invoices SET
+			amount_paid = amount_paid + $2::numeric,
+			status = CASE WHEN amount_paid + $2::numeric >= total_amount THEN 'paid' ELSE 'partially_paid' END
+		WHERE invoice_id = $1 AND status IN ('open', 'partially_paid')
+			AND amount_paid + $2::numeric <= total_amount
+		RETURNING status
+	`, *invoiceID, *coreReturn.RefundAmount).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Less is left to pay on the invoice; the credit stays on the account
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO payment_allocations (payment_id, invoice_id, amount)
+		VALUES ($1, $2, $3::numeric)
+	`, paymentID, *invoiceID, *coreReturn.RefundAmount)
+	if err != nil {
+		return err
+	}
+
+	saleStatus := "partially_paid"
+	if status == "paid" {
+		saleStatus = "paid"
+	}
+	_, err = tx.Exec(ctx, `UPDATE sales SET payment_status = $2 WHERE invoice_id = $1`, *invoiceID, saleStatus)
+	return err
+}
+
+func (r *PostgresCoreRepository) GetCoreStock(ctx context.Context, filter *coremodels.CoreStockFilter) ([]*coremodels.CoreStock, error) {
+	query := coreStockQuery
+
+	var params []interface{}
+	paramCount := 1
+
+	if filter.SupplierID != nil {
+		query += fmt.Sprintf(" AND i.supplier_id = $%d", paramCount)
+		params = append(params, *filter.SupplierID)
+		paramCount++
+	}
+
+	if filter.OnHandOnly {
+		query += " AND COALESCE(rc.quantity, 0) > COALESCE(sr.quantity, 0)"
+	}
+
+	query += " ORDER BY i.part_number"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []*coremodels.CoreStock
+	for rows.Next() {
+		item, err := scanCoreStock(rows)
+		if err != nil {
+			return nil, err
+		}
+		stock = append(stock, item)
+	}
+
+	return stock, rows.Err()
+}
+
+func (r *PostgresCoreRepository) GetItemCoreStock(ctx context.Context, itemID int) (*coremodels.CoreStock, error) {
+	stock, err := scanCoreStock(r.db.Pool.QueryRow(ctx, coreStockQuery+" AND i.item_id = $1", itemID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+func (r *PostgresCoreRepository) GetAllSupplierReturns(ctx context.Context, filter *coremodels.SupplierReturnFilter) ([]*coremodels.SupplierReturn, error) {
+	query := supplierReturnQuery + " WHERE 1=1"
+
+	var params []interface{}
+	paramCount := 1
+
+	if filter.SupplierID != nil {
+		query += fmt.Sprintf(" AND csr.supplier_id = $%d", paramCount)
+		params = append(params, *filter.SupplierID)
+		paramCount++
+	}
+
+	if filter.Status != nil {
+		query += fmt.Sprintf(" AND csr.status = $%d", paramCount)
+		params = append(params, *filter.Status)
+		paramCount++
+	}
+
+	query += " ORDER BY csr.sent_at DESC, csr.core_supplier_return_id DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var returns []*coremodels.SupplierReturn
+	for rows.Next() {
+		supplierReturn, err := scanSupplierReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, supplierReturn)
+	}
+
+	return returns, rows.Err()
+}
+
+func (r *PostgresCoreRepository) GetSupplierReturnByID(ctx context.Context, id int) (*coremodels.SupplierReturn, error) {
+	supplierReturn, err := scanSupplierReturn(r.db.Pool.QueryRow(ctx, supplierReturnQuery+" WHERE csr.core_supplier_return_id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT l.item_id, l.quantity, l.unit_credit, i.part_number, i.description
+		FROM core_supplier_return_lines l
+		JOIN items i ON l.item_id = i.item_id
+		WHERE l.core_supplier_return_id = $1
+		ORDER BY i.part_number
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line := &coremodels.SupplierReturnLine{}
+		err := rows.Scan(
+			&line.ItemID,
+			&line.Quantity,
+			&line.UnitCredit,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+		)
+		if err != nil {
+			return nil, err
+		}
+		supplierReturn.Lines = append(supplierReturn.Lines, line)
+	}
+
+	return supplierReturn, rows.Err()
+}
+
+// CreateSupplierReturn sends cores back to a supplier. Cores on hand are
+// checked again inside the transaction, with the item rows locked, so the
+// same cores cannot go out on two returns.
+func (r *PostgresCoreRepository) CreateSupplierReturn(ctx context.Context, supplierReturn *coremodels.SupplierReturn) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, line := range supplierReturn.Lines {
+		var onHand int
+		err := tx.QueryRow(ctx, `
+			SELECT
+				COALESCE((SELECT SUM(quantity) FROM core_returns WHERE item_id = i.item_id), 0) -
+				COALESCE((SELECT SUM(quantity) FROM core_supplier_return_lines WHERE item_id = i.item_id), 0)
+			FROM items i
+			WHERE i.item_id = $1
+			FOR UPDATE
+		`, line.ItemID).Scan(&onHand)
+		if err != nil {
+			return 0, err
+		}
+		if line.Quantity > onHand {
+			return 0, fmt.Errorf("only %d cores on hand for item %d", onHand, line.ItemID)
+		}
+	}
+
+	var id int
+	if err := tx.QueryRow(ctx, `SELECT nextval('core_supplier_return_id_seq')`).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO core_supplier_returns (
+			core_supplier_return_id, return_number, supplier_id, expected_credit,
+			notes, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		id,
+		fmt.Sprintf("CR-%06d", id),
+		supplierReturn.SupplierID,
+		supplierReturn.ExpectedCredit,
+		supplierReturn.Notes,
+		supplierReturn.CreatedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range supplierReturn.Lines {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO core_supplier_return_lines (
+				core_supplier_return_id, item_id, quantity, unit_credit
+			) VALUES ($1, $2, $3, $4)
+		`, id, line.ItemID, line.Quantity, line.UnitCredit)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresCoreRepository) MarkCredited(ctx context.Context, id int, req *coremodels.CreditRequest) error {
+	query := `
+		UPDATE core_supplier_returns SET
+			status = 'credited',
+			credit_amount = $2,
+			credit_reference = $3,
+			credited_at = CURRENT_TIMESTAMP
+		WHERE core_supplier_return_id = $1 AND status = 'sent'
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, req.CreditAmount, req.CreditReference)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("core return has already been credited")
+	}
+
+	return nil
+}
+
+func (r *PostgresCoreRepository) SupplierExists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM suppliers WHERE supplier_id = $1)`, id).Scan(&exists)
+	return exists, err
+}
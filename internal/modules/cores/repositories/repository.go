@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+
+	coremodels "github.com/hsrvms/autoparts/internal/modules/cores/models"
+)
+
+type CoreRepository interface {
+	GetSaleCore(ctx context.Context, saleID int) (*coremodels.SaleCore, error)
+
+	// Customer core returns
+	GetAllReturns(ctx context.Context, filter *coremodels.CoreReturnFilter) ([]*coremodels.CoreReturn, error)
+	GetReturnByID(ctx context.Context, id int) (*coremodels.CoreReturn, error)
+	CreateReturn(ctx context.Context, coreReturn *coremodels.CoreReturn) (int, error)
+
+	// Core inventory
+	GetCoreStock(ctx context.Context, filter *coremodels.CoreStockFilter) ([]*coremodels.CoreStock, error)
+	GetItemCoreStock(ctx context.Context, itemID int) (*coremodels.CoreStock, error)
+
+	// Returns to suppliers
+	GetAllSupplierReturns(ctx context.Context, filter *coremodels.SupplierReturnFilter) ([]*coremodels.SupplierReturn, error)
+	GetSupplierReturnByID(ctx context.Context, id int) (*coremodels.SupplierReturn, error)
+	CreateSupplierReturn(ctx context.Context, supplierReturn *coremodels.SupplierReturn) (int, error)
+	MarkCredited(ctx context.Context, id int, req *coremodels.CreditRequest) error
+	SupplierExists(ctx context.Context, id int) (bool, error)
+}
@@ -0,0 +1,33 @@
+package cores
+
+import (
+	"github.com/hsrvms/autoparts/internal/modules/cores/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/cores/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/cores/services"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repository
+	repo := repositories.NewPostgresCoreRepository(database)
+
+	// Initialize service
+	service := services.NewCoreService(repo)
+
+	// Initialize handler
+	handler := handlers.NewCoreHandler(service)
+
+	// Register routes
+	api.GET("/sales/:id/core", handler.GetSaleCore)
+
+	cores := api.Group("/cores")
+	cores.GET("/stock", handler.GetCoreStock)
+	cores.GET("/returns", handler.GetCoreReturns)
+	cores.POST("/returns", handler.CreateCoreReturn)
+	cores.GET("/returns/:id", handler.GetCoreReturnByID)
+	cores.GET("/supplier-returns", handler.GetSupplierReturns)
+	cores.POST("/supplier-returns", handler.CreateSupplierReturn)
+	cores.GET("/supplier-returns/:id", handler.GetSupplierReturnByID)
+	cores.POST("/supplier-returns/:id/credit", handler.CreditSupplierReturn)
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	coremodels "github.com/hsrvms/autoparts/internal/modules/cores/models"
+	"github.com/hsrvms/autoparts/internal/modules/cores/repositories"
+)
+
+var (
+	ErrSaleNotFound           = errors.New("sale not found")
+	ErrCoreReturnNotFound     = errors.New("core return not found")
+	ErrSupplierReturnNotFound = errors.New("supplier core return not found")
+	ErrInvalidQuantity        = errors.New("quantity must be greater than zero")
+	ErrInvalidCondition       = errors.New("condition must be good or damaged")
+	ErrInvalidRefundMethod    = errors.New("refund method must be cash, card or bank_transfer")
+	ErrAccountRefundRequired  = errors.New("core deposits on account sales are credited to the account")
+	ErrNoCoreDeposit          = errors.New("no core deposit was taken on this sale")
+	ErrCoresNotOwed           = errors.New("quantity exceeds the cores still owed on the sale")
+	ErrInvalidRefundAmount    = errors.New("refund amount cannot be negative or exceed the deposit taken for the cores")
+	ErrInvalidStatus          = errors.New("status must be sent or credited")
+	ErrSupplierRequired       = errors.New("supplier is required")
+	ErrSupplierNotFound       = errors.New("supplier not found")
+	ErrLinesRequired          = errors.New("at least one line is required")
+	ErrItemHasNoCores         = errors.New("item has no core charge or cores on hand")
+	ErrInsufficientCores      = errors.New("quantity exceeds the cores on hand")
+	ErrUnitCreditRequired     = errors.New("unit credit is required for items without a core charge")
+	ErrInvalidCredit          = errors.New("credit cannot be negative")
+	ErrSupplierReturnCredited = errors.New("supplier core return has already been credited")
+)
+
+type CoreService interface {
+	GetSaleCore(ctx context.Context, saleID int) (*coremodels.SaleCore, error)
+
+	GetAllReturns(ctx context.Context, filter *coremodels.CoreReturnFilter) ([]*coremodels.CoreReturn, error)
+	GetReturnByID(ctx context.Context, id int) (*coremodels.CoreReturn, error)
+	CreateReturn(ctx context.Context, coreReturn *coremodels.CoreReturn) (int, error)
+
+	GetCoreStock(ctx context.Context, filter *coremodels.CoreStockFilter) ([]*coremodels.CoreStock, error)
+
+	GetAllSupplierReturns(ctx context.Context, filter *coremodels.SupplierReturnFilter) ([]*coremodels.SupplierReturn, error)
+	GetSupplierReturnByID(ctx context.Context, id int) (*coremodels.SupplierReturn, error)
+	CreateSupplierReturn(ctx context.Context, supplierReturn *coremodels.SupplierReturn) (int, error)
+	MarkCredited(ctx context.Context, id int, req *coremodels.CreditRequest) (*coremodels.SupplierReturn, error)
+}
+
+type coreService struct {
+	repo repositories.CoreRepository
+}
+
+func NewCoreService(repo repositories.CoreRepository) CoreService {
+	return &coreService{
+		repo: repo,
+	}
+}
+
+func (s *coreService) GetSaleCore(ctx context.Context, saleID int) (*coremodels.SaleCore, error) {
+	core, err := s.repo.GetSaleCore(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if core == nil {
+		return nil, ErrSaleNotFound
+	}
+
+	if core.CoreDeposit > 0 {
+		core.QuantityOwed = core.Quantity - core.QuantityReturned
+		core.DepositHeld = roundCents(core.CoreDeposit / float64(core.Quantity) * float64(core.QuantityOwed))
+	}
+
+	return core, nil
+}
+
+func (s *coreService) GetAllReturns(ctx context.Context, filter *coremodels.CoreReturnFilter) ([]*coremodels.CoreReturn, error) {
+	returns, err := s.repo.GetAllReturns(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if returns == nil {
+		returns = []*coremodels.CoreReturn{}
+	}
+
+	return returns, nil
+}
+
+func (s *coreService) GetReturnByID(ctx context.Context, id int) (*coremodels.CoreReturn, error) {
+	coreReturn, err := s.repo.GetReturnByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if coreReturn == nil {
+		return nil, ErrCoreReturnNotFound
+	}
+
+	return coreReturn, nil
+}
+
+// CreateReturn takes old units back against the deposit on a sale and
+// records the refund paid. The refund defaults to the deposit taken for the
+// units; a damaged core may be refunded less.
+func (s *coreService) CreateReturn(ctx context.Context, coreReturn *coremodels.CoreReturn) (int, error) {
+	if coreReturn.Quantity == 0 {
+		coreReturn.Quantity = 1
+	}
+	if coreReturn.Quantity < 0 {
+		return 0, ErrInvalidQuantity
+	}
+
+	if coreReturn.Condition == "" {
+		coreReturn.Condition = coremodels.ConditionGood
+	}
+	if coreReturn.Condition != coremodels.ConditionGood && coreReturn.Condition != coremodels.ConditionDamaged {
+		return 0, ErrInvalidCondition
+	}
+
+	core, err := s.GetSaleCore(ctx, coreReturn.SaleID)
+	if err != nil {
+		return 0, err
+	}
+
+	// The deposit on an account sale was billed to the customer's account,
+	// so it is credited back there rather than paid out
+	if core.PaymentMethod == "account" {
+		if coreReturn.RefundMethod == "" {
+			coreReturn.RefundMethod = "account"
+		}
+		if coreReturn.RefundMethod != "account" {
+			return 0, ErrAccountRefundRequired
+		}
+	} else {
+		switch coreReturn.RefundMethod {
+		case "cash", "card", "bank_transfer":
+		default:
+			return 0, ErrInvalidRefundMethod
+		}
+	}
+	if core.CoreDeposit == 0 {
+		return 0, ErrNoCoreDeposit
+	}
+	if coreReturn.Quantity > core.QuantityOwed {
+		return 0, ErrCoresNotOwed
+	}
+
+	deposit := roundCents(core.CoreDeposit / float64(core.Quantity) * float64(coreReturn.Quantity))
+	if coreReturn.RefundAmount == nil {
+		coreReturn.RefundAmount = &deposit
+	}
+	if *coreReturn.RefundAmount < 0 || *coreReturn.RefundAmount > deposit {
+		return 0, ErrInvalidRefundAmount
+	}
+
+	coreReturn.ItemID = core.ItemID
+
+	return s.repo.CreateReturn(ctx, coreReturn)
+}
+
+func (s *coreService) GetCoreStock(ctx context.Context, filter *coremodels.CoreStockFilter) ([]*coremodels.CoreStock, error) {
+	stock, err := s.repo.GetCoreStock(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if stock == nil {
+		stock = []*coremodels.CoreStock{}
+	}
+
+	return stock, nil
+}
+
+func (s *coreService) GetAllSupplierReturns(ctx context.Context, filter *coremodels.SupplierReturnFilter) ([]*coremodels.SupplierReturn, error) {
+	if filter.Status != nil && *filter.Status != coremodels.SupplierReturnSent && *filter.Status != coremodels.SupplierReturnCredited {
+		return nil, ErrInvalidStatus
+	}
+
+	returns, err := s.repo.GetAllSupplierReturns(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if returns == nil {
+		returns = []*coremodels.SupplierReturn{}
+	}
+
+	return returns, nil
+}
+
+func (s *coreService) GetSupplierReturnByID(ctx context.Context, id int) (*coremodels.SupplierReturn, error) {
+	supplierReturn, err := s.repo.GetSupplierReturnByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if supplierReturn == nil {
+		return nil, ErrSupplierReturnNotFound
+	}
+	if supplierReturn.Lines == nil {
+		supplierReturn.Lines = []*coremodels.SupplierReturnLine{}
+	}
+
+	return supplierReturn, nil
+}
+
+// CreateSupplierReturn sends cores on hand back to the supplier. Each line is
+// credited at the item's core charge unless a unit credit is given.
+func (s *coreService) CreateSupplierReturn(ctx context.Context, supplierReturn *coremodels.SupplierReturn) (int, error) {
+	if supplierReturn.SupplierID <= 0 {
+		return 0, ErrSupplierRequired
+	}
+
+	exists, err := s.repo.SupplierExists(ctx, supplierReturn.SupplierID)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, ErrSupplierNotFound
+	}
+
+	lines, err := mergeLines(supplierReturn.Lines)
+	if err != nil {
+		return 0, err
+	}
+
+	var expected float64
+	for _, line := range lines {
+		stock, err := s.repo.GetItemCoreStock(ctx, line.ItemID)
+		if err != nil {
+			return 0, err
+		}
+		if stock == nil {
+			return 0, ErrItemHasNoCores
+		}
+		if line.Quantity > stock.OnHand {
+			return 0, ErrInsufficientCores
+		}
+
+		if line.UnitCredit == nil {
+			if stock.CoreCharge == nil {
+				return 0, ErrUnitCreditRequired
+			}
+			line.UnitCredit = stock.CoreCharge
+		}
+		if *line.UnitCredit < 0 {
+			return 0, ErrInvalidCredit
+		}
+
+		expected += *line.UnitCredit * float64(line.Quantity)
+	}
+
+	supplierReturn.Lines = lines
+	supplierReturn.ExpectedCredit = roundCents(expected)
+
+	return s.repo.CreateSupplierReturn(ctx, supplierReturn)
+}
+
+// MarkCredited records the credit the supplier gave, which may differ from
+// the credit expected when cores are rejected
+func (s *coreService) MarkCredited(ctx context.Context, id int, req *coremodels.CreditRequest) (*coremodels.SupplierReturn, error) {
+	supplierReturn, err := s.GetSupplierReturnByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if supplierReturn.Status != coremodels.SupplierReturnSent {
+		return nil, ErrSupplierReturnCredited
+	}
+	if req.CreditAmount < 0 {
+		return nil, ErrInvalidCredit
+	}
+
+	if err := s.repo.MarkCredited(ctx, id, req); err != nil {
+		return nil, err
+	}
+
+	return s.GetSupplierReturnByID(ctx, id)
+}
+
+// Helper functions
+
+// mergeLines combines lines for the same item, which may only carry one unit
+// credit between them
+func mergeLines(lines []*coremodels.SupplierReturnLine) ([]*coremodels.SupplierReturnLine, error) {
+	if len(lines) == 0 {
+		return nil, ErrLinesRequired
+	}
+
+	var merged []*coremodels.SupplierReturnLine
+	byItem := make(map[int]*coremodels.SupplierReturnLine)
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+
+		if existing, ok := byItem[line.ItemID]; ok {
+			existing.Quantity += line.Quantity
+			if existing.UnitCredit == nil {
+				existing.UnitCredit = line.UnitCredit
+			}
+			continue
+		}
+
+		byItem[line.ItemID] = line
+		merged = append(merged, line)
+	}
+
+	return merged, nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	customermodels "github.com/hsrvms/autoparts/internal/modules/customers/models"
+	"github.com/hsrvms/autoparts/internal/modules/customers/services"
+	"github.com/labstack/echo/v4"
+)
+
+type CustomerHandler struct {
+	service services.CustomerService
+}
+
+func NewCustomerHandler(service services.CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		service: service,
+	}
+}
+
+// GetCustomers handles retrieval of all customers with optional filtering
+func (h *CustomerHandler) GetCustomers(c echo.Context) error {
+	filter := &customermodels.CustomerFilter{}
+
+	if search := c.QueryParam("search"); search != "" {
+		filter.SearchTerm = &search
+	}
+
+	if isActive := c.QueryParam("is_active"); isActive != "" {
+		active := isActive == "true"
+		filter.IsActive = &active
+	}
+
+	ctx := c.Request().Context()
+	customers, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, customers)
+}
+
+// GetCustomerByID handles retrieval of a single customer
+func (h *CustomerHandler) GetCustomerByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	ctx := c.Request().Context()
+	customer, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrCustomerNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, customer)
+}
+
+// CreateCustomer handles creation of a new customer
+func (h *CustomerHandler) CreateCustomer(c echo.Context) error {
+	// New customers are active unless the request says otherwise
+	customer := &customermodels.Customer{IsActive: true}
+	if err := c.Bind(customer); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, customer)
+	if err != nil {
+		switch err {
+		case services.ErrCustomerNameRequired, services.ErrInvalidCreditLimit,
+			services.ErrInvalidPaymentTerms, services.ErrInvalidCustomerEmail:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrDuplicateCustomerName:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	customer.CustomerID = id
+	return c.JSON(http.StatusCreated, customer)
+}
+
+// UpdateCustomer handles updating an existing customer
+func (h *CustomerHandler) UpdateCustomer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	customer := new(customermodels.Customer)
+	if err := c.Bind(customer); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	customer.CustomerID = id
+
+	ctx := c.Request().Context()
+	err = h.service.Update(ctx, customer)
+	if err != nil {
+		switch err {
+		case services.ErrCustomerNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrCustomerNameRequired, services.ErrInvalidCreditLimit,
+			services.ErrInvalidPaymentTerms, services.ErrInvalidCustomerEmail:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrDuplicateCustomerName:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, customer)
+}
+
+// DeleteCustomer handles deletion of a customer
+func (h *CustomerHandler) DeleteCustomer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	ctx := c.Request().Context()
+	err = h.service.Delete(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrCustomerNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrCustomerHasAccountItems:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
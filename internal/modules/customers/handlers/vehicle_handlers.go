@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	customermodels "github.com/hsrvms/autoparts/internal/modules/customers/models"
+	"github.com/hsrvms/autoparts/internal/modules/customers/services"
+	"github.com/labstack/echo/v4"
+)
+
+type VehicleHandler struct {
+	service services.VehicleService
+}
+
+func NewVehicleHandler(service services.VehicleService) *VehicleHandler {
+	return &VehicleHandler{
+		service: service,
+	}
+}
+
+// GetVehicles handles looking up customer vehicles, by VIN or plate at the counter
+func (h *VehicleHandler) GetVehicles(c echo.Context) error {
+	filter := &customermodels.CustomerVehicleFilter{}
+
+	if customerID := c.QueryParam("customer_id"); customerID != "" {
+		id, err := strconv.Atoi(customerID)
+		if err == nil {
+			filter.CustomerID = &id
+		}
+	}
+
+	if vin := c.QueryParam("vin"); vin != "" {
+		filter.VIN = &vin
+	}
+
+	if plate := c.QueryParam("license_plate"); plate != "" {
+		filter.LicensePlate = &plate
+	}
+
+	if isActive := c.QueryParam("is_active"); isActive != "" {
+		active := isActive == "true"
+		filter.IsActive = &active
+	}
+
+	ctx := c.Request().Context()
+	vehicles, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return vehicleError(err)
+	}
+
+	return c.JSON(http.StatusOK, vehicles)
+}
+
+// GetCustomerVehicles handles listing the vehicles a customer has registered
+func (h *VehicleHandler) GetCustomerVehicles(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	ctx := c.Request().Context()
+	vehicles, err := h.service.GetCustomerVehicles(ctx, id)
+	if err != nil {
+		return vehicleError(err)
+	}
+
+	return c.JSON(http.StatusOK, vehicles)
+}
+
+// GetVehicleByID handles retrieval of a single customer vehicle
+func (h *VehicleHandler) GetVehicleByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid vehicle ID")
+	}
+
+	ctx := c.Request().Context()
+	vehicle, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return vehicleError(err)
+	}
+
+	return c.JSON(http.StatusOK, vehicle)
+}
+
+// CreateVehicle handles registering a vehicle to a customer
+func (h *VehicleHandler) CreateVehicle(c echo.Context) error {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	vehicle := &customermodels.CustomerVehicle{IsActive: true}
+	if err := c.Bind(vehicle); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	vehicle.CustomerID = customerID
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, vehicle)
+	if err != nil {
+		return vehicleError(err)
+	}
+
+	vehicle.VehicleID = id
+	return c.JSON(http.StatusCreated, vehicle)
+}
+
+// UpdateVehicle handles updating a customer vehicle
+func (h *VehicleHandler) UpdateVehicle(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid vehicle ID")
+	}
+
+	vehicle := new(customermodels.CustomerVehicle)
+	if err := c.Bind(vehicle); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	vehicle.VehicleID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.Update(ctx, vehicle); err != nil {
+		return vehicleError(err)
+	}
+
+	return c.JSON(http.StatusOK, vehicle)
+}
+
+// DeleteVehicle handles deletion of a customer vehicle
+func (h *VehicleHandler) DeleteVehicle(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid vehicle ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Delete(ctx, id); err != nil {
+		return vehicleError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetVehicleHistory handles listing every part sold for a customer vehicle
+func (h *VehicleHandler) GetVehicleHistory(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid vehicle ID")
+	}
+
+	ctx := c.Request().Context()
+	history, err := h.service.GetHistory(ctx, id)
+	if err != nil {
+		return vehicleError(err)
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
+func vehicleError(err error) error {
+	switch err {
+	case services.ErrInvalidVehicleID, services.ErrInvalidCustomerID, services.ErrSubmodelRequired,
+		services.ErrSubmodelNotFound, services.ErrInvalidModelYear, services.ErrInvalidVIN,
+		services.ErrInvalidMileage:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrVehicleNotFound, services.ErrCustomerNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrDuplicateVIN, services.ErrVehicleHasSales, services.ErrCustomerInactive:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,24 @@
+package customermodels
+
+import "time"
+
+type Customer struct {
+	CustomerID       int       `json:"customer_id" db:"customer_id"`
+	Name             string    `json:"name" db:"name"`
+	ContactPerson    *string   `json:"contact_person,omitempty" db:"contact_person"`
+	Phone            *string   `json:"phone,omitempty" db:"phone"`
+	Email            *string   `json:"email,omitempty" db:"email"`
+	Address          *string   `json:"address,omitempty" db:"address"`
+	TaxID            *string   `json:"tax_id,omitempty" db:"tax_id"`
+	CreditLimit      float64   `json:"credit_limit" db:"credit_limit"`
+	PaymentTermsDays int       `json:"payment_terms_days" db:"payment_terms_days"`
+	IsActive         bool      `json:"is_active" db:"is_active"`
+	Notes            *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CustomerFilter struct {
+	SearchTerm *string `query:"search"`
+	IsActive   *bool   `query:"is_active"`
+}
@@ -0,0 +1,69 @@
+package customermodels
+
+import "time"
+
+// CustomerVehicle is a vehicle a customer has registered with us
+type CustomerVehicle struct {
+	VehicleID    int       `json:"vehicle_id" db:"vehicle_id"`
+	CustomerID   int       `json:"customer_id" db:"customer_id"`
+	SubmodelID   int       `json:"submodel_id" db:"submodel_id"`
+	ModelYear    int       `json:"model_year" db:"model_year"`
+	VIN          *string   `json:"vin,omitempty" db:"vin"`
+	LicensePlate *string   `json:"license_plate,omitempty" db:"license_plate"`
+	Mileage      *int      `json:"mileage,omitempty" db:"mileage"` // Latest known, raised by sales that record a reading
+	IsActive     bool      `json:"is_active" db:"is_active"`
+	Notes        *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	CustomerName string `json:"customer_name,omitempty" db:"-"`
+	MakeName     string `json:"make_name,omitempty" db:"-"`
+	ModelName    string `json:"model_name,omitempty" db:"-"`
+	SubmodelName string `json:"submodel_name,omitempty" db:"-"`
+}
+
+type CustomerVehicleFilter struct {
+	CustomerID   *int    `query:"customer_id"`
+	VIN          *string `query:"vin"`
+	LicensePlate *string `query:"license_plate"`
+	IsActive     *bool   `query:"is_active"`
+}
+
+// VehicleSale is one sale recorded against a customer vehicle
+type VehicleSale struct {
+	SaleID            int       `json:"sale_id"`
+	Date              time.Time `json:"date"`
+	TransactionNumber *string   `json:"transaction_number,omitempty"`
+	ItemID            int       `json:"item_id"`
+	PartNumber        string    `json:"part_number"`
+	Description       string    `json:"description"`
+	CategoryName      *string   `json:"category_name,omitempty"`
+	Quantity          int       `json:"quantity"`
+	PricePerUnit      float64   `json:"price_per_unit"`
+	TotalPrice        float64   `json:"total_price"`
+	VehicleMileage    *int      `json:"vehicle_mileage,omitempty"`
+}
+
+// VehiclePart sums up the sales of one part for a vehicle, to show when it
+// was last fitted and what may be due next
+type VehiclePart struct {
+	ItemID         int       `json:"item_id"`
+	PartNumber     string    `json:"part_number"`
+	Description    string    `json:"description"`
+	CategoryName   *string   `json:"category_name,omitempty"`
+	TimesBought    int       `json:"times_bought"`
+	TotalQuantity  int       `json:"total_quantity"`
+	LastSoldAt     time.Time `json:"last_sold_at"`
+	LastMileage    *int      `json:"last_mileage,omitempty"`
+	MileageSince   *int      `json:"mileage_since,omitempty"` // Driven since the part was last bought, when both readings are known
+	IsActive       bool      `json:"is_active"`
+	AvailableStock int       `json:"available_stock"`
+}
+
+// VehicleHistory is every part sold for a vehicle, newest first
+type VehicleHistory struct {
+	Vehicle *CustomerVehicle `json:"vehicle"`
+	Parts   []*VehiclePart   `json:"parts"`
+	Sales   []*VehicleSale   `json:"sales"`
+}
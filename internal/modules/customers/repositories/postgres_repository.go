@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	customermodels "github.com/hsrvms/autoparts/internal/modules/customers/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresCustomerRepository struct {
+	db *db.Database
+}
+
+func NewPostgresCustomerRepository(database *db.Database) CustomerRepository {
+	return &PostgresCustomerRepository{
+		db: database,
+	}
+}
+
+const customerColumns = `
+	customer_id, name, contact_person, phone, email, address, tax_id,
+	credit_limit, payment_terms_days, is_active, notes, created_at, updated_at
+`
+
+func scanCustomer(row pgx.Row) (*customermodels.Customer, error) {
+	customer := &customermodels.Customer{}
+	err := row.Scan(
+		&customer.CustomerID,
+		&customer.Name,
+		&customer.ContactPerson,
+		&customer.Phone,
+		&customer.Email,
+		&customer.Address,
+		&customer.TaxID,
+		&customer.CreditLimit,
+		&customer.PaymentTermsDays,
+		&customer.IsActive,
+		&customer.Notes,
+		&customer.CreatedAt,
+		&customer.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+func (r *PostgresCustomerRepository) GetAll(ctx context.Context, filter *customermodels.CustomerFilter) ([]*customermodels.Customer, error) {
+	query := `SELECT ` + customerColumns + ` FROM customers WHERE 1=1`
+
+	params := []interface{}{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.SearchTerm != nil {
+			query += fmt.Sprintf(" AND (name ILIKE $%d OR contact_person ILIKE $%d OR email ILIKE $%d OR phone ILIKE $%d)",
+				paramCount, paramCount, paramCount, paramCount)
+			params = append(params, "%"+*filter.SearchTerm+"%")
+			paramCount++
+		}
+
+		if filter.IsActive != nil {
+			query += fmt.Sprintf(" AND is_active = $%d", paramCount)
+			params = append(params, *filter.IsActive)
+			paramCount++
+		}
+	}
+
+	query += " ORDER BY name"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var customers []*customermodels.Customer
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+
+	return customers, rows.Err()
+}
+
+func (r *PostgresCustomerRepository) GetByID(ctx context.Context, id int) (*customermodels.Customer, error) {
+	query := `SELECT ` + customerColumns + ` FROM customers WHERE customer_id = $1`
+
+	customer, err := scanCustomer(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (r *PostgresCustomerRepository) GetByName(ctx context.Context, name string) (*customermodels.Customer, error) {
+	query := `SELECT ` + customerColumns + ` FROM customers WHERE LOWER(name) = LOWER($1)`
+
+	customer, err := scanCustomer(r.db.Pool.QueryRow(ctx, query, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (r *PostgresCustomerRepository) Create(ctx context.Context, customer *customermodels.Customer) (int, error) {
+	query := `
+		INSERT INTO customers (
+			name, contact_person, phone, email, address, tax_id,
+			credit_limit, payment_terms_days, is_active, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING customer_id
+	`
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		customer.Name,
+		customer.ContactPerson,
+		customer.Phone,
+		customer.Email,
+		customer.Address,
+		customer.TaxID,
+		customer.CreditLimit,
+		customer.PaymentTermsDays,
+		customer.IsActive,
+		customer.Notes,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresCustomerRepository) Update(ctx context.Context, customer *customermodels.Customer) error {
+	query := `
+		UPDATE customers SET
+			name = $2,
+			contact_person = $3,
+			phone = $4,
+			email = $5,
+			address = $6,
+			tax_id = $7,
+			credit_limit = $8,
+			payment_terms_days = $9,
+			is_active = $10,
+			notes = $11
+		WHERE customer_id = $1
+	`
+
+	result, err := r.db.Pool.Exec(
+		ctx, query,
+		customer.CustomerID,
+		customer.Name,
+		customer.ContactPerson,
+		customer.Phone,
+		customer.Email,
+		customer.Address,
+		customer.TaxID,
+		customer.CreditLimit,
+		customer.PaymentTermsDays,
+		customer.IsActive,
+		customer.Notes,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("customer not found")
+	}
+
+	return nil
+}
+
+func (r *PostgresCustomerRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM customers WHERE customer_id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("customer not found")
+	}
+
+	return nil
+}
+
+// HasAccountActivity reports whether the customer has invoices or payments,
+// which must be kept for the receivables ledger.
+func (r *PostgresCustomerRepository) HasAccountActivity(ctx context.Context, id int) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM invoices WHERE customer_id = $1)
+			OR EXISTS (SELECT 1 FROM payments WHERE customer_id = $1)
+	`
+
+	var exists bool
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,345 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	customermodels "github.com/hsrvms/autoparts/internal/modules/customers/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresVehicleRepository struct {
+	db *db.Database
+}
+
+func NewPostgresVehicleRepository(database *db.Database) VehicleRepository {
+	return &PostgresVehicleRepository{
+		db: database,
+	}
+}
+
+const vehicleColumns = `
+	v.vehicle_id, v.customer_id, v.submodel_id, v.model_year, v.vin,
+	v.license_plate, v.mileage, v.is_active, v.notes, v.created_at, v.updated_at,
+	cu.name, mk.make_name, m.model_name, s.submodel_name
+`
+
+const vehicleJoins = `
+	FROM customer_vehicles v
+	JOIN customers cu ON v.customer_id = cu.customer_id
+	JOIN vehicle_submodels s ON v.submodel_id = s.submodel_id
+	JOIN vehicle_models m ON s.model_id = m.model_id
+	JOIN vehicle_makes mk ON m.make_id = mk.make_id
+`
+
+func scanVehicle(row pgx.Row) (*customermodels.CustomerVehicle, error) {
+	vehicle := &customermodels.CustomerVehicle{}
+	err := row.Scan(
+		&vehicle.VehicleID,
+		&vehicle.CustomerID,
+		&vehicle.SubmodelID,
+		&vehicle.ModelYear,
+		&vehicle.VIN,
+		&vehicle.LicensePlate,
+		&vehicle.Mileage,
+		&vehicle.IsActive,
+		&vehicle.Notes,
+		&vehicle.CreatedAt,
+		&vehicle.UpdatedAt,
+		&vehicle.CustomerName,
+		&vehicle.MakeName,
+		&vehicle.ModelName,
+		&vehicle.SubmodelName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return vehicle, nil
+}
+
+func (r *PostgresVehicleRepository) GetAll(ctx context.Context, filter *customermodels.CustomerVehicleFilter) ([]*customermodels.CustomerVehicle, error) {
+	query := `SELECT ` + vehicleColumns + vehicleJoins + ` WHERE 1=1`
+
+	params := []interface{}{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.CustomerID != nil {
+			query += fmt.Sprintf(" AND v.customer_id = $%d", paramCount)
+			params = append(params, *filter.CustomerID)
+			paramCount++
+		}
+
+		if filter.VIN != nil {
+			query += fmt.Sprintf(" AND v.vin = UPPER($%d)", paramCount)
+			params = append(params, *filter.VIN)
+			paramCount++
+		}
+
+		// Plates are matched without spaces or dashes, as they are read out
+		// differently at the counter
+		if filter.LicensePlate != nil {
+			query += fmt.Sprintf(` AND REGEXP_REPLACE(UPPER(v.license_plate), '[^A-Z0-9]', '', 'g')
+				= REGEXP_REPLACE(UPPER($%d), '[^A-Z0-9]', '', 'g')`, paramCount)
+			params = append(params, *filter.LicensePlate)
+			paramCount++
+		}
+
+		if filter.IsActive != nil {
+			query += fmt.Sprintf(" AND v.is_active = $%d", paramCount)
+			params = append(params, *filter.IsActive)
+			paramCount++
+		}
+	}
+
+	query += " ORDER BY cu.name, mk.make_name, m.model_name, v.model_year"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vehicles []*customermodels.CustomerVehicle
+	for rows.Next() {
+		vehicle, err := scanVehicle(rows)
+		if err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, vehicle)
+	}
+
+	return vehicles, rows.Err()
+}
+
+func (r *PostgresVehicleRepository) GetByID(ctx context.Context, id int) (*customermodels.CustomerVehicle, error) {
+	query := `SELECT ` + vehicleColumns + vehicleJoins + ` WHERE v.vehicle_id = $1`
+
+	vehicle, err := scanVehicle(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return vehicle, nil
+}
+
+func (r *PostgresVehicleRepository) GetByVIN(ctx context.Context, vin string) (*customermodels.CustomerVehicle, error) {
+	query := `SELECT ` + vehicleColumns + vehicleJoins + ` WHERE v.vin = $1`
+
+	vehicle, err := scanVehicle(r.db.Pool.QueryRow(ctx, query, vin))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return vehicle, nil
+}
+
+func (r *PostgresVehicleRepository) Create(ctx context.Context, vehicle *customermodels.CustomerVehicle) (int, error) {
+	query := `
+		INSERT INTO customer_vehicles (
+			customer_id, submodel_id, model_year, vin, license_plate,
+			mileage, is_active, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING vehicle_id
+	`
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		vehicle.CustomerID,
+		vehicle.SubmodelID,
+		vehicle.ModelYear,
+		vehicle.VIN,
+		vehicle.LicensePlate,
+		vehicle.Mileage,
+		vehicle.IsActive,
+		vehicle.Notes,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresVehicleRepository) Update(ctx context.Context, vehicle *customermodels.CustomerVehicle) error {
+	query := `
+		UPDATE customer_vehicles SET
+			submodel_id = $2,
+			model_year = $3,
+			vin = $4,
+			license_plate = $5,
+			mileage = $6,
+			is_active = $7,
+			notes = $8
+		WHERE vehicle_id = $1
+	`
+
+	result, err := r.db.Pool.Exec(
+		ctx, query,
+		vehicle.VehicleID,
+		vehicle.SubmodelID,
+		vehicle.ModelYear,
+		vehicle.VIN,
+		vehicle.LicensePlate,
+		vehicle.Mileage,
+		vehicle.IsActive,
+		vehicle.Notes,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("customer vehicle not found")
+	}
+
+	return nil
+}
+
+func (r *PostgresVehicleRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM customer_vehicles WHERE vehicle_id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("customer vehicle not found")
+	}
+
+	return nil
+}
+
+// HasSales reports whether any sale was recorded against the vehicle
+func (r *PostgresVehicleRepository) HasSales(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM sales WHERE customer_vehicle_id = $1)
+	`, id).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// GetSubmodelYears returns the years a submodel was built, or nils when
+// there is no such submodel
+func (r *PostgresVehicleRepository) GetSubmodelYears(ctx context.Context, submodelID int) (*int, *int, error) {
+	var yearFrom int
+	var yearTo *int
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT year_from, year_to FROM vehicle_submodels WHERE submodel_id = $1
+	`, submodelID).Scan(&yearFrom, &yearTo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	return &yearFrom, yearTo, nil
+}
+
+// GetSales lists the sales recorded against the vehicle, newest first
+func (r *PostgresVehicleRepository) GetSales(ctx context.Context, id int) ([]*customermodels.VehicleSale, error) {
+	query := `
+		SELECT s.sale_id, s.date, s.transaction_number, s.item_id, i.part_number,
+			   i.description, c.category_name, s.quantity, s.price_per_unit,
+			   s.total_price, s.vehicle_mileage
+		FROM sales s
+		JOIN items i ON s.item_id = i.item_id
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		WHERE s.customer_vehicle_id = $1
+		ORDER BY s.date DESC, s.sale_id DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []*customermodels.VehicleSale
+	for rows.Next() {
+		sale := &customermodels.VehicleSale{}
+		err := rows.Scan(
+			&sale.SaleID,
+			&sale.Date,
+			&sale.TransactionNumber,
+			&sale.ItemID,
+			&sale.PartNumber,
+			&sale.Description,
+			&sale.CategoryName,
+			&sale.Quantity,
+			&sale.PricePerUnit,
+			&sale.TotalPrice,
+			&sale.VehicleMileage,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, sale)
+	}
+
+	return sales, rows.Err()
+}
+
+// GetParts sums up the vehicle's sales by part, most recently bought first.
+// The last mileage is the reading on the latest sale of the part that has one.
+func (r *PostgresVehicleRepository) GetParts(ctx context.Context, id int) ([]*customermodels.VehiclePart, error) {
+	query := `
+		SELECT i.item_id, i.part_number, i.description, c.category_name,
+			   COUNT(*), SUM(s.quantity), MAX(s.date),
+			   (ARRAY_AGG(s.vehicle_mileage ORDER BY s.date DESC)
+					FILTER (WHERE s.vehicle_mileage IS NOT NULL))[1],
+			   i.is_active, available_stock(i.item_id)
+		FROM sales s
+		JOIN items i ON s.item_id = i.item_id
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		WHERE s.customer_vehicle_id = $1
+		GROUP BY i.item_id, i.part_number, i.description, c.category_name, i.is_active
+		ORDER BY MAX(s.date) DESC, i.part_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var parts []*customermodels.VehiclePart
+	for rows.Next() {
+		part := &customermodels.VehiclePart{}
+		err := rows.Scan(
+			&part.ItemID,
+			&part.PartNumber,
+			&part.Description,
+			&part.CategoryName,
+			&part.TimesBought,
+			&part.TotalQuantity,
+			&part.LastSoldAt,
+			&part.LastMileage,
+			&part.IsActive,
+			&part.AvailableStock,
+		)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	return parts, rows.Err()
+}
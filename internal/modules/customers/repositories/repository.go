@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	customermodels "github.com/hsrvms/autoparts/internal/modules/customers/models"
+)
+
+type CustomerRepository interface {
+	GetAll(ctx context.Context, filter *customermodels.CustomerFilter) ([]*customermodels.Customer, error)
+	GetByID(ctx context.Context, id int) (*customermodels.Customer, error)
+	GetByName(ctx context.Context, name string) (*customermodels.Customer, error)
+	Create(ctx context.Context, customer *customermodels.Customer) (int, error)
+	Update(ctx context.Context, customer *customermodels.Customer) error
+	Delete(ctx context.Context, id int) error
+	HasAccountActivity(ctx context.Context, id int) (bool, error)
+}
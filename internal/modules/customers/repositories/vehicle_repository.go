@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	customermodels "github.com/hsrvms/autoparts/internal/modules/customers/models"
+)
+
+type VehicleRepository interface {
+	GetAll(ctx context.Context, filter *customermodels.CustomerVehicleFilter) ([]*customermodels.CustomerVehicle, error)
+	GetByID(ctx context.Context, id int) (*customermodels.CustomerVehicle, error)
+	GetByVIN(ctx context.Context, vin string) (*customermodels.CustomerVehicle, error)
+	Create(ctx context.Context, vehicle *customermodels.CustomerVehicle) (int, error)
+	Update(ctx context.Context, vehicle *customermodels.CustomerVehicle) error
+	Delete(ctx context.Context, id int) error
+	HasSales(ctx context.Context, id int) (bool, error)
+	GetSubmodelYears(ctx context.Context, submodelID int) (yearFrom *int, yearTo *int, err error)
+	GetSales(ctx context.Context, id int) ([]*customermodels.VehicleSale, error)
+	GetParts(ctx context.Context, id int) ([]*customermodels.VehiclePart, error)
+}
@@ -0,0 +1,42 @@
+package customers
+
+import (
+	"github.com/hsrvms/autoparts/internal/modules/customers/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/customers/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/customers/services"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repository
+	repo := repositories.NewPostgresCustomerRepository(database)
+	vehicleRepo := repositories.NewPostgresVehicleRepository(database)
+
+	// Initialize service
+	service := services.NewCustomerService(repo)
+	vehicleService := services.NewVehicleService(vehicleRepo, repo)
+
+	// Initialize handler
+	handler := handlers.NewCustomerHandler(service)
+	vehicleHandler := handlers.NewVehicleHandler(vehicleService)
+
+	// Register routes
+	customers := api.Group("/customers")
+	customers.GET("", handler.GetCustomers)
+	customers.GET("/:id", handler.GetCustomerByID)
+	customers.POST("", handler.CreateCustomer)
+	customers.PUT("/:id", handler.UpdateCustomer)
+	customers.DELETE("/:id", handler.DeleteCustomer)
+
+	// Customer vehicle routes
+	customers.GET("/:id/vehicles", vehicleHandler.GetCustomerVehicles)
+	customers.POST("/:id/vehicles", vehicleHandler.CreateVehicle)
+
+	vehicles := api.Group("/customer-vehicles")
+	vehicles.GET("", vehicleHandler.GetVehicles)
+	vehicles.GET("/:id", vehicleHandler.GetVehicleByID)
+	vehicles.PUT("/:id", vehicleHandler.UpdateVehicle)
+	vehicles.DELETE("/:id", vehicleHandler.DeleteVehicle)
+	vehicles.GET("/:id/history", vehicleHandler.GetVehicleHistory)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+
+	customermodels "github.com/hsrvms/autoparts/internal/modules/customers/models"
+	"github.com/hsrvms/autoparts/internal/modules/customers/repositories"
+)
+
+var (
+	ErrCustomerNotFound        = errors.New("customer not found")
+	ErrInvalidCustomerID       = errors.New("invalid customer ID")
+	ErrCustomerNameRequired    = errors.New("customer name is required")
+	ErrDuplicateCustomerName   = errors.New("customer name already exists")
+	ErrInvalidCreditLimit      = errors.New("credit limit cannot be negative")
+	ErrInvalidPaymentTerms     = errors.New("payment terms days cannot be negative")
+	ErrInvalidCustomerEmail    = errors.New("invalid customer email format")
+	ErrCustomerHasAccountItems = errors.New("cannot delete customer with invoices or payments")
+)
+
+type CustomerService interface {
+	GetAll(ctx context.Context, filter *customermodels.CustomerFilter) ([]*customermodels.Customer, error)
+	GetByID(ctx context.Context, id int) (*customermodels.Customer, error)
+	Create(ctx context.Context, customer *customermodels.Customer) (int, error)
+	Update(ctx context.Context, customer *customermodels.Customer) error
+	Delete(ctx context.Context, id int) error
+}
+
+type customerService struct {
+	repo repositories.CustomerRepository
+}
+
+func NewCustomerService(repo repositories.CustomerRepository) CustomerService {
+	return &customerService{
+		repo: repo,
+	}
+}
+
+func (s *customerService) GetAll(ctx context.Context, filter *customermodels.CustomerFilter) ([]*customermodels.Customer, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *customerService) GetByID(ctx context.Context, id int) (*customermodels.Customer, error) {
+	if id <= 0 {
+		return nil, ErrInvalidCustomerID
+	}
+
+	customer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	return customer, nil
+}
+
+func (s *customerService) Create(ctx context.Context, customer *customermodels.Customer) (int, error) {
+	if err := s.validateCustomer(customer); err != nil {
+		return 0, err
+	}
+
+	existing, err := s.repo.GetByName(ctx, customer.Name)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil {
+		return 0, ErrDuplicateCustomerName
+	}
+
+	return s.repo.Create(ctx, customer)
+}
+
+func (s *customerService) Update(ctx context.Context, customer *customermodels.Customer) error {
+	if customer.CustomerID <= 0 {
+		return ErrInvalidCustomerID
+	}
+
+	if err := s.validateCustomer(customer); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByID(ctx, customer.CustomerID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrCustomerNotFound
+	}
+
+	// Check for name uniqueness if name is being changed
+	if existing.Name != customer.Name {
+		byName, err := s.repo.GetByName(ctx, customer.Name)
+		if err != nil {
+			return err
+		}
+		if byName != nil && byName.CustomerID != customer.CustomerID {
+			return ErrDuplicateCustomerName
+		}
+	}
+
+	return s.repo.Update(ctx, customer)
+}
+
+func (s *customerService) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidCustomerID
+	}
+
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrCustomerNotFound
+	}
+
+	// Invoices and payments are part of the receivables ledger; such
+	// customers should be deactivated instead of deleted.
+	hasActivity, err := s.repo.HasAccountActivity(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasActivity {
+		return ErrCustomerHasAccountItems
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// Helper functions
+func (s *customerService) validateCustomer(customer *customermodels.Customer) error {
+	if customer.Name == "" {
+		return ErrCustomerNameRequired
+	}
+	if customer.CreditLimit < 0 {
+		return ErrInvalidCreditLimit
+	}
+	if customer.PaymentTermsDays < 0 {
+		return ErrInvalidPaymentTerms
+	}
+	if customer.Email != nil && *customer.Email != "" {
+		if _, err := mail.ParseAddress(*customer.Email); err != nil {
+			return ErrInvalidCustomerEmail
+		}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	customermodels "github.com/hsrvms/autoparts/internal/modules/customers/models"
+	"github.com/hsrvms/autoparts/internal/modules/customers/repositories"
+)
+
+var (
+	ErrVehicleNotFound  = errors.New("customer vehicle not found")
+	ErrInvalidVehicleID = errors.New("invalid vehicle ID")
+	ErrSubmodelNotFound = errors.New("submodel not found")
+	ErrInvalidModelYear = errors.New("model year must be within the submodel's years")
+	ErrInvalidVIN       = errors.New("VIN must be 17 letters and digits, without I, O or Q")
+	ErrDuplicateVIN     = errors.New("VIN is already registered to a vehicle")
+	ErrInvalidMileage   = errors.New("mileage cannot be negative")
+	ErrVehicleHasSales  = errors.New("cannot delete a vehicle with sales, deactivate it instead")
+	ErrCustomerInactive = errors.New("customer is inactive")
+	ErrSubmodelRequired = errors.New("submodel is required")
+)
+
+type VehicleService interface {
+	GetAll(ctx context.Context, filter *customermodels.CustomerVehicleFilter) ([]*customermodels.CustomerVehicle, error)
+	GetCustomerVehicles(ctx context.Context, customerID int) ([]*customermodels.CustomerVehicle, error)
+	GetByID(ctx context.Context, id int) (*customermodels.CustomerVehicle, error)
+	Create(ctx context.Context, vehicle *customermodels.CustomerVehicle) (int, error)
+	Update(ctx context.Context, vehicle *customermodels.CustomerVehicle) error
+	Delete(ctx context.Context, id int) error
+	GetHistory(ctx context.Context, id int) (*customermodels.VehicleHistory, error)
+}
+
+type vehicleService struct {
+	repo      repositories.VehicleRepository
+	customers repositories.CustomerRepository
+}
+
+func NewVehicleService(repo repositories.VehicleRepository, customers repositories.CustomerRepository) VehicleService {
+	return &vehicleService{
+		repo:      repo,
+		customers: customers,
+	}
+}
+
+func (s *vehicleService) GetAll(ctx context.Context, filter *customermodels.CustomerVehicleFilter) ([]*customermodels.CustomerVehicle, error) {
+	vehicles, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if vehicles == nil {
+		vehicles = []*customermodels.CustomerVehicle{}
+	}
+	return vehicles, nil
+}
+
+func (s *vehicleService) GetCustomerVehicles(ctx context.Context, customerID int) ([]*customermodels.CustomerVehicle, error) {
+	if _, err := s.getCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	return s.GetAll(ctx, &customermodels.CustomerVehicleFilter{CustomerID: &customerID})
+}
+
+func (s *vehicleService) GetByID(ctx context.Context, id int) (*customermodels.CustomerVehicle, error) {
+	if id <= 0 {
+		return nil, ErrInvalidVehicleID
+	}
+
+	vehicle, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+
+	return vehicle, nil
+}
+
+func (s *vehicleService) Create(ctx context.Context, vehicle *customermodels.CustomerVehicle) (int, error) {
+	customer, err := s.getCustomer(ctx, vehicle.CustomerID)
+	if err != nil {
+		return 0, err
+	}
+	if !customer.IsActive {
+		return 0, ErrCustomerInactive
+	}
+
+	if err := s.validateVehicle(ctx, vehicle); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(ctx, vehicle)
+}
+
+// Update changes a vehicle's details. The vehicle stays with the customer
+// it was registered to.
+func (s *vehicleService) Update(ctx context.Context, vehicle *customermodels.CustomerVehicle) error {
+	existing, err := s.GetByID(ctx, vehicle.VehicleID)
+	if err != nil {
+		return err
+	}
+	vehicle.CustomerID = existing.CustomerID
+
+	if err := s.validateVehicle(ctx, vehicle); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, vehicle)
+}
+
+func (s *vehicleService) Delete(ctx context.Context, id int) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	// Deleting would take the vehicle's service history with it
+	hasSales, err := s.repo.HasSales(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasSales {
+		return ErrVehicleHasSales
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// GetHistory lists every part sold for a vehicle, with how far the vehicle
+// has been driven since each was last bought where the readings allow
+func (s *vehicleService) GetHistory(ctx context.Context, id int) (*customermodels.VehicleHistory, error) {
+	vehicle, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := s.repo.GetParts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if parts == nil {
+		parts = []*customermodels.VehiclePart{}
+	}
+
+	for _, part := range parts {
+		if vehicle.Mileage != nil && part.LastMileage != nil {
+			since := *vehicle.Mileage - *part.LastMileage
+			part.MileageSince = &since
+		}
+	}
+
+	sales, err := s.repo.GetSales(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sales == nil {
+		sales = []*customermodels.VehicleSale{}
+	}
+
+	return &customermodels.VehicleHistory{
+		Vehicle: vehicle,
+		Parts:   parts,
+		Sales:   sales,
+	}, nil
+}
+
+// Helper functions
+func (s *vehicleService) getCustomer(ctx context.Context, customerID int) (*customermodels.Customer, error) {
+	if customerID <= 0 {
+		return nil, ErrInvalidCustomerID
+	}
+
+	customer, err := s.customers.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	return customer, nil
+}
+
+func (s *vehicleService) validateVehicle(ctx context.Context, vehicle *customermodels.CustomerVehicle) error {
+	if vehicle.SubmodelID <= 0 {
+		return ErrSubmodelRequired
+	}
+	if vehicle.Mileage != nil && *vehicle.Mileage < 0 {
+		return ErrInvalidMileage
+	}
+
+	yearFrom, yearTo, err := s.repo.GetSubmodelYears(ctx, vehicle.SubmodelID)
+	if err != nil {
+		return err
+	}
+	if yearFrom == nil {
+		return ErrSubmodelNotFound
+	}
+	if vehicle.ModelYear < *yearFrom || (yearTo != nil && vehicle.ModelYear > *yearTo) {
+		return ErrInvalidModelYear
+	}
+
+	vehicle.LicensePlate = trimmedOrNil(vehicle.LicensePlate)
+	if vehicle.LicensePlate != nil {
+		plate := strings.ToUpper(*vehicle.LicensePlate)
+		vehicle.LicensePlate = &plate
+	}
+
+	vehicle.VIN = trimmedOrNil(vehicle.VIN)
+	if vehicle.VIN != nil {
+		vin := strings.ToUpper(*vehicle.VIN)
+		if !isValidVIN(vin) {
+			return ErrInvalidVIN
+		}
+		vehicle.VIN = &vin
+
+		existing, err := s.repo.GetByVIN(ctx, vin)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.VehicleID != vehicle.VehicleID {
+			return ErrDuplicateVIN
+		}
+	}
+
+	return nil
+}
+
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// isValidVIN checks the form of a VIN only; check digits are not used
+// outside North America
+func isValidVIN(vin string) bool {
+	if len(vin) != 17 {
+		return false
+	}
+	for _, ch := range vin {
+		switch {
+		case ch == 'I' || ch == 'O' || ch == 'Q':
+			return false
+		case ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -43,6 +43,8 @@ func (h *DashboardHandler) GetStats(c echo.Context) error {
 			return c.String(http.StatusOK, formatNumber(stats.LowStockCount))
 		case "today-sales":
 			return c.String(http.StatusOK, formatCurrency(stats.TodaySales))
+		case "today-gross-profit":
+			return c.String(http.StatusOK, formatCurrency(stats.TodayGrossProfit))
 		case "active-items":
 			return c.String(http.StatusOK, formatNumber(stats.ActiveItems))
 		case "supplier-count":
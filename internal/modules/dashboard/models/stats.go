@@ -3,10 +3,11 @@ package dashboardmodels
 import "time"
 
 type Stats struct {
-	LowStockCount int     `json:"low_stock_count"`
-	TodaySales    float64 `json:"today_sales"`
-	ActiveItems   int     `json:"active_items"`
-	SupplierCount int     `json:"supplier_count"`
+	LowStockCount    int     `json:"low_stock_count"`
+	TodaySales       float64 `json:"today_sales"`
+	TodayGrossProfit float64 `json:"today_gross_profit"`
+	ActiveItems      int     `json:"active_items"`
+	SupplierCount    int     `json:"supplier_count"`
 }
 
 type Activity struct {
@@ -34,10 +34,13 @@ func (r *PostgresDashboardRepository) GetStats(ctx context.Context) (*dashboardm
 	// Get today's sales
 	today := time.Now().Format("2006-01-02")
 	err = r.db.Pool.QueryRow(ctx, `
-        SELECT COALESCE(SUM(total_price), 0)
-        FROM sales
-        WHERE DATE(date) = $1
-    `, today).Scan(&stats.TodaySales)
+        SELECT
+            COALESCE(SUM(s.total_price), 0),
+            COALESCE(SUM(s.total_price - COALESCE(s.cogs_fifo, s.quantity * i.buy_price)), 0)
+        FROM sales s
+        JOIN items i ON s.item_id = i.item_id
+        WHERE DATE(s.date) = $1
+    `, today).Scan(&stats.TodaySales, &stats.TodayGrossProfit)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	forecastmodels "github.com/hsrvms/autoparts/internal/modules/forecasting/models"
+	"github.com/hsrvms/autoparts/internal/modules/forecasting/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ForecastHandler struct {
+	service services.ForecastService
+}
+
+func NewForecastHandler(service services.ForecastService) *ForecastHandler {
+	return &ForecastHandler{
+		service: service,
+	}
+}
+
+// GetItemForecast handles retrieval of the demand forecast for an item
+func (h *ForecastHandler) GetItemForecast(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	weeks := 0
+	if value := c.QueryParam("weeks"); value != "" {
+		weeks, err = strconv.Atoi(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid weeks")
+		}
+	}
+
+	refresh := c.QueryParam("refresh") == "true"
+
+	ctx := c.Request().Context()
+	forecast, err := h.service.GetForecast(ctx, id, weeks, refresh)
+	if err != nil {
+		return forecastError(err)
+	}
+
+	if c.QueryParam("include_history") == "true" {
+		history, err := h.service.GetDemandHistory(ctx, id, forecastmodels.IntervalWeek, nil)
+		if err != nil {
+			return forecastError(err)
+		}
+		forecast.History = history
+	}
+
+	return c.JSON(http.StatusOK, forecast)
+}
+
+// GetItemDemandHistory handles retrieval of daily or weekly sales totals for an item
+func (h *ForecastHandler) GetItemDemandHistory(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	var since *time.Time
+	if value := c.QueryParam("since"); value != "" {
+		date, err := parseDate(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid since date")
+		}
+		since = &date
+	}
+
+	ctx := c.Request().Context()
+	history, err := h.service.GetDemandHistory(ctx, id, c.QueryParam("interval"), since)
+	if err != nil {
+		return forecastError(err)
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
+// GetMinimumStockRecommendations handles listing forecast-based minimum stock levels
+func (h *ForecastHandler) GetMinimumStockRecommendations(c echo.Context) error {
+	filter := &forecastmodels.RecommendationFilter{}
+
+	if value := c.QueryParam("min_confidence"); value != "" {
+		confidence, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid min_confidence")
+		}
+		filter.MinConfidence = &confidence
+	}
+
+	filter.ChangedOnly = c.QueryParam("changed_only") == "true"
+
+	ctx := c.Request().Context()
+	recommendations, err := h.service.GetRecommendations(ctx, filter)
+	if err != nil {
+		return forecastError(err)
+	}
+
+	return c.JSON(http.StatusOK, recommendations)
+}
+
+// ApplyMinimumStockRecommendation handles setting an item's minimum stock from its forecast
+func (h *ForecastHandler) ApplyMinimumStockRecommendation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	forecast, err := h.service.ApplyRecommendation(ctx, id)
+	if err != nil {
+		return forecastError(err)
+	}
+
+	return c.JSON(http.StatusOK, forecast)
+}
+
+// RefreshForecasts handles recomputing forecasts for all active items
+func (h *ForecastHandler) RefreshForecasts(c echo.Context) error {
+	weeks := 0
+	if value := c.QueryParam("weeks"); value != "" {
+		var err error
+		weeks, err = strconv.Atoi(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid weeks")
+		}
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.service.RefreshAll(ctx, weeks)
+	if err != nil {
+		return forecastError(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func forecastError(err error) error {
+	switch err {
+	case services.ErrInvalidItemID, services.ErrInvalidHorizon, services.ErrInvalidInterval,
+		services.ErrInvalidMinConfidence, services.ErrInvalidHistoryStart:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrItemNotFound, services.ErrForecastNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrNoRecommendation:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
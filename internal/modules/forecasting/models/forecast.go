@@ -0,0 +1,84 @@
+package forecastmodels
+
+import "time"
+
+// Demand aggregation intervals
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// Confidence levels reported alongside the numeric confidence
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// DemandPoint is the quantity sold in one day or week
+type DemandPoint struct {
+	PeriodStart time.Time `json:"period_start" db:"period_start"`
+	Quantity    int       `json:"quantity" db:"quantity"`
+}
+
+type ForecastPeriod struct {
+	WeekStart  time.Time `json:"week_start" db:"week_start"`
+	Quantity   float64   `json:"quantity" db:"quantity"`
+	LowerBound float64   `json:"lower_bound" db:"lower_bound"`
+	UpperBound float64   `json:"upper_bound" db:"upper_bound"`
+}
+
+type ItemForecast struct {
+	ItemID                  int       `json:"item_id" db:"item_id"`
+	Method                  string    `json:"method" db:"method"`
+	HistoryWeeks            int       `json:"history_weeks" db:"history_weeks"`
+	HorizonWeeks            int       `json:"horizon_weeks" db:"horizon_weeks"`
+	AverageWeeklyDemand     float64   `json:"average_weekly_demand" db:"average_weekly_demand"`
+	WeeklyTrend             float64   `json:"weekly_trend" db:"weekly_trend"`
+	Seasonal                bool      `json:"seasonal" db:"seasonal"`
+	ErrorRMSE               *float64  `json:"error_rmse,omitempty" db:"error_rmse"`
+	Confidence              float64   `json:"confidence" db:"confidence"`
+	LeadTimeDays            int       `json:"lead_time_days" db:"lead_time_days"`
+	RecommendedMinimumStock *int      `json:"recommended_minimum_stock,omitempty" db:"recommended_minimum_stock"`
+	GeneratedAt             time.Time `json:"generated_at" db:"generated_at"`
+
+	// Additional fields for API responses
+	PartNumber          string            `json:"part_number,omitempty" db:"part_number"`
+	Description         string            `json:"description,omitempty" db:"description"`
+	CurrentMinimumStock int               `json:"current_minimum_stock" db:"minimum_stock"`
+	ConfidenceLevel     string            `json:"confidence_level" db:"-"`
+	Periods             []*ForecastPeriod `json:"periods" db:"-"`
+	History             []*DemandPoint    `json:"history,omitempty" db:"-"`
+}
+
+// ForecastItem is an item the forecasting job works on
+type ForecastItem struct {
+	ItemID       int    `db:"item_id"`
+	PartNumber   string `db:"part_number"`
+	Description  string `db:"description"`
+	MinimumStock int    `db:"minimum_stock"`
+	LeadTimeDays int    `db:"lead_time_days"`
+}
+
+type RecommendationFilter struct {
+	MinConfidence *float64 `query:"min_confidence"`
+	ChangedOnly   bool     `query:"changed_only"`
+}
+
+type MinimumStockRecommendation struct {
+	ItemID                  int       `json:"item_id" db:"item_id"`
+	PartNumber              string    `json:"part_number" db:"part_number"`
+	Description             string    `json:"description" db:"description"`
+	CurrentMinimumStock     int       `json:"current_minimum_stock" db:"minimum_stock"`
+	RecommendedMinimumStock int       `json:"recommended_minimum_stock" db:"recommended_minimum_stock"`
+	Confidence              float64   `json:"confidence" db:"confidence"`
+	ConfidenceLevel         string    `json:"confidence_level" db:"-"`
+	GeneratedAt             time.Time `json:"generated_at" db:"generated_at"`
+}
+
+// RefreshResult summarises a forecasting run
+type RefreshResult struct {
+	ItemsForecast int       `json:"items_forecast"`
+	HorizonWeeks  int       `json:"horizon_weeks"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
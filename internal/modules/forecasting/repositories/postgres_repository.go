@@ -0,0 +1,312 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	forecastmodels "github.com/hsrvms/autoparts/internal/modules/forecasting/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresForecastRepository struct {
+	db *db.Database
+}
+
+func NewPostgresForecastRepository(database *db.Database) ForecastRepository {
+	return &PostgresForecastRepository{
+		db: database,
+	}
+}
+
+// GetForecastItems returns the active items to forecast. Kits hold no stock
+// and are left out; their sales are forecast as demand for their components.
+func (r *PostgresForecastRepository) GetForecastItems(ctx context.Context, itemID *int) ([]*forecastmodels.ForecastItem, error) {
+	query := `
+		SELECT
+			i.item_id, i.part_number, i.description, i.minimum_stock,
+			COALESCE(s.lead_time_days, 7) as lead_time_days
+		FROM items i
+		LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
+		WHERE i.is_active = TRUE AND NOT i.is_kit
+	`
+
+	var params []interface{}
+	if itemID != nil {
+		query += " AND i.item_id = $1"
+		params = append(params, *itemID)
+	}
+
+	query += " ORDER BY i.item_id"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*forecastmodels.ForecastItem
+	for rows.Next() {
+		item := &forecastmodels.ForecastItem{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.Description,
+			&item.MinimumStock,
+			&item.LeadTimeDays,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// GetDemand aggregates sales quantities per item into days or ISO weeks
+// (starting Monday), with kit sales counted against their components.
+// Periods with no sales are not returned.
+func (r *PostgresForecastRepository) GetDemand(ctx context.Context, itemID *int, since time.Time, interval string) (map[int][]*forecastmodels.DemandPoint, error) {
+	if interval != forecastmodels.IntervalDay && interval != forecastmodels.IntervalWeek {
+		return nil, fmt.Errorf("unsupported interval %q", interval)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT item_id, date_trunc('%s', date)::date as period_start, SUM(quantity)
+		FROM item_sales_demand
+		WHERE date >= $1
+	`, interval)
+
+	params := []interface{}{since}
+	if itemID != nil {
+		query += " AND item_id = $2"
+		params = append(params, *itemID)
+	}
+
+	query += " GROUP BY item_id, period_start ORDER BY item_id, period_start"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	demand := make(map[int][]*forecastmodels.DemandPoint)
+	for rows.Next() {
+		var id int
+		point := &forecastmodels.DemandPoint{}
+		if err := rows.Scan(&id, &point.PeriodStart, &point.Quantity); err != nil {
+			return nil, err
+		}
+		demand[id] = append(demand[id], point)
+	}
+
+	return demand, rows.Err()
+}
+
+func (r *PostgresForecastRepository) GetForecast(ctx context.Context, itemID int) (*forecastmodels.ItemForecast, error) {
+	query := `
+		SELECT
+			f.item_id, f.method, f.history_weeks, f.horizon_weeks,
+			f.average_weekly_demand, f.weekly_trend, f.seasonal, f.error_rmse,
+			f.confidence, f.lead_time_days, f.recommended_minimum_stock,
+			f.generated_at,
+			i.part_number, i.description, i.minimum_stock
+		FROM item_forecasts f
+		JOIN items i ON f.item_id = i.item_id
+		WHERE f.item_id = $1
+	`
+
+	forecast := &forecastmodels.ItemForecast{}
+	err := r.db.Pool.QueryRow(ctx, query, itemID).Scan(
+		&forecast.ItemID,
+		&forecast.Method,
+		&forecast.HistoryWeeks,
+		&forecast.HorizonWeeks,
+		&forecast.AverageWeeklyDemand,
+		&forecast.WeeklyTrend,
+		&forecast.Seasonal,
+		&forecast.ErrorRMSE,
+		&forecast.Confidence,
+		&forecast.LeadTimeDays,
+		&forecast.RecommendedMinimumStock,
+		&forecast.GeneratedAt,
+		&forecast.PartNumber,
+		&forecast.Description,
+		&forecast.CurrentMinimumStock,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT week_start, quantity, lower_bound, upper_bound
+		FROM item_forecast_periods
+		WHERE item_id = $1
+		ORDER BY week_start
+	`, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		period := &forecastmodels.ForecastPeriod{}
+		err := rows.Scan(
+			&period.WeekStart,
+			&period.Quantity,
+			&period.LowerBound,
+			&period.UpperBound,
+		)
+		if err != nil {
+			return nil, err
+		}
+		forecast.Periods = append(forecast.Periods, period)
+	}
+
+	return forecast, rows.Err()
+}
+
+// SaveForecast replaces the stored forecast for an item
+func (r *PostgresForecastRepository) SaveForecast(ctx context.Context, forecast *forecastmodels.ItemForecast) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO item_forecasts (
+			item_id, method, history_weeks, horizon_weeks,
+			average_weekly_demand, weekly_trend, seasonal, error_rmse,
+			confidence, lead_time_days, recommended_minimum_stock, generated_at
+		) VALUES ($1, $2, $3, $4, $5::numeric, $6::numeric, $7, $8::numeric, $9::numeric, $10, $11, $12)
+		ON CONFLICT (item_id) DO UPDATE SET
+			method = EXCLUDED.method,
+			history_weeks = EXCLUDED.history_weeks,
+			horizon_weeks = EXCLUDED.horizon_weeks,
+			average_weekly_demand = EXCLUDED.average_weekly_demand,
+			weekly_trend = EXCLUDED.weekly_trend,
+			seasonal = EXCLUDED.seasonal,
+			error_rmse = EXCLUDED.error_rmse,
+			confidence = EXCLUDED.confidence,
+			lead_time_days = EXCLUDED.lead_time_days,
+			recommended_minimum_stock = EXCLUDED.recommended_minimum_stock,
+			generated_at = EXCLUDED.generated_at
+	`,
+		forecast.ItemID,
+		forecast.Method,
+		forecast.HistoryWeeks,
+		forecast.HorizonWeeks,
+		forecast.AverageWeeklyDemand,
+		forecast.WeeklyTrend,
+		forecast.Seasonal,
+		forecast.ErrorRMSE,
+		forecast.Confidence,
+		forecast.LeadTimeDays,
+		forecast.RecommendedMinimumStock,
+		forecast.GeneratedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM item_forecast_periods WHERE item_id = $1`, forecast.ItemID)
+	if err != nil {
+		return err
+	}
+
+	for _, period := range forecast.Periods {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO item_forecast_periods (
+				item_id, week_start, quantity, lower_bound, upper_bound
+			) VALUES ($1, $2, $3::numeric, $4::numeric, $5::numeric)
+		`, forecast.ItemID, period.WeekStart, period.Quantity, period.LowerBound, period.UpperBound)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresForecastRepository) GetRecommendations(ctx context.Context, filter *forecastmodels.RecommendationFilter) ([]*forecastmodels.MinimumStockRecommendation, error) {
+	query := `
+		SELECT
+			f.item_id, i.part_number, i.description, i.minimum_stock,
+			f.recommended_minimum_stock, f.confidence, f.generated_at
+		FROM item_forecasts f
+		JOIN items i ON f.item_id = i.item_id
+		WHERE f.recommended_minimum_stock IS NOT NULL
+		AND i.is_active = TRUE
+	`
+
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.MinConfidence != nil {
+			query += fmt.Sprintf(" AND f.confidence >= $%d", paramCount)
+			params = append(params, *filter.MinConfidence)
+			paramCount++
+		}
+
+		if filter.ChangedOnly {
+			query += " AND f.recommended_minimum_stock <> i.minimum_stock"
+		}
+	}
+
+	query += " ORDER BY ABS(f.recommended_minimum_stock - i.minimum_stock) DESC, i.part_number"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recommendations []*forecastmodels.MinimumStockRecommendation
+	for rows.Next() {
+		rec := &forecastmodels.MinimumStockRecommendation{}
+		err := rows.Scan(
+			&rec.ItemID,
+			&rec.PartNumber,
+			&rec.Description,
+			&rec.CurrentMinimumStock,
+			&rec.RecommendedMinimumStock,
+			&rec.Confidence,
+			&rec.GeneratedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		recommendations = append(recommendations, rec)
+	}
+
+	return recommendations, rows.Err()
+}
+
+// ApplyMinimumStock sets an item's minimum stock. A reorder-up-to level below
+// the new minimum is raised to match so the item stays valid.
+func (r *PostgresForecastRepository) ApplyMinimumStock(ctx context.Context, itemID int, minimumStock int) error {
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE items
+		SET minimum_stock = $2,
+			reorder_up_to = GREATEST(reorder_up_to, $2)
+		WHERE item_id = $1
+	`, itemID, minimumStock)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("item not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	forecastmodels "github.com/hsrvms/autoparts/internal/modules/forecasting/models"
+)
+
+type ForecastRepository interface {
+	GetForecastItems(ctx context.Context, itemID *int) ([]*forecastmodels.ForecastItem, error)
+	GetDemand(ctx context.Context, itemID *int, since time.Time, interval string) (map[int][]*forecastmodels.DemandPoint, error)
+	GetForecast(ctx context.Context, itemID int) (*forecastmodels.ItemForecast, error)
+	SaveForecast(ctx context.Context, forecast *forecastmodels.ItemForecast) error
+	GetRecommendations(ctx context.Context, filter *forecastmodels.RecommendationFilter) ([]*forecastmodels.MinimumStockRecommendation, error)
+	ApplyMinimumStock(ctx context.Context, itemID int, minimumStock int) error
+}
@@ -0,0 +1,43 @@
+package forecasting
+
+import (
+	"context"
+
+	"github.com/hsrvms/autoparts/internal/modules/forecasting/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/forecasting/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/forecasting/services"
+	"github.com/hsrvms/autoparts/pkg/config"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/scheduler"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repository
+	repo := repositories.NewPostgresForecastRepository(database)
+
+	// Initialize service
+	service := services.NewForecastService(repo)
+
+	// Initialize handler
+	handler := handlers.NewForecastHandler(service)
+
+	// Register routes
+	api.GET("/items/:id/forecast", handler.GetItemForecast)
+	api.GET("/items/:id/demand-history", handler.GetItemDemandHistory)
+	api.POST("/items/:id/forecast/apply-minimum-stock", handler.ApplyMinimumStockRecommendation)
+
+	forecasts := api.Group("/forecasts")
+	forecasts.GET("/minimum-stock", handler.GetMinimumStockRecommendations)
+	forecasts.POST("/refresh", handler.RefreshForecasts)
+}
+
+// RegisterJobs schedules the periodic forecast refresh
+func RegisterJobs(s *scheduler.Scheduler, database *db.Database, cfg config.JobsConfig) {
+	service := services.NewForecastService(repositories.NewPostgresForecastRepository(database))
+
+	s.Every("demand-forecast", cfg.ForecastInterval, func(ctx context.Context) error {
+		_, err := service.RefreshAll(ctx, cfg.ForecastHorizonWeeks)
+		return err
+	})
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	forecastmodels "github.com/hsrvms/autoparts/internal/modules/forecasting/models"
+)
+
+// Forecasting methods recorded on each forecast
+const (
+	MethodNoHistory        = "no_history"
+	MethodMovingAverage    = "moving_average"
+	MethodHolt             = "holt_linear_trend"
+	MethodHoltSeasonal     = "holt_linear_trend_seasonal"
+	minWeeksForTrend       = 8
+	minWeeksForSeasonality = 52
+	movingAverageWeeks     = 4
+	fullHistoryWeeks       = 26
+)
+
+// Smoothing constants for Holt's method and the z-scores used for the
+// forecast interval (80%) and safety stock (95% service level).
+const (
+	alpha        = 0.3
+	beta         = 0.1
+	intervalZ    = 1.28
+	safetyStockZ = 1.65
+)
+
+// demandModel is a fitted forecast for a single weekly demand series
+type demandModel struct {
+	method   string
+	level    float64
+	trend    float64
+	seasonal bool
+	indices  [12]float64
+	rmse     *float64
+	mean     float64
+	conf     float64
+}
+
+// fitDemandModel fits the weekly series. weeks[i] is the week starting
+// start + 7*i days. Short series fall back to a moving average; series of a
+// year or more get monthly seasonal indices on top of Holt's linear trend.
+func fitDemandModel(start time.Time, weeks []float64) *demandModel {
+	m := &demandModel{}
+	for i := range m.indices {
+		m.indices[i] = 1
+	}
+
+	n := len(weeks)
+	if n == 0 {
+		m.method = MethodNoHistory
+		return m
+	}
+
+	var total float64
+	for _, y := range weeks {
+		total += y
+	}
+	m.mean = total / float64(n)
+
+	if n < minWeeksForTrend {
+		m.method = MethodMovingAverage
+		window := weeks[max(0, n-movingAverageWeeks):]
+		var sum float64
+		for _, y := range window {
+			sum += y
+		}
+		m.level = sum / float64(len(window))
+		m.conf = historyFactor(n) * 0.5
+		return m
+	}
+
+	if n >= minWeeksForSeasonality && m.mean > 0 {
+		m.seasonal = true
+		m.indices = monthlyIndices(start, weeks, m.mean)
+	}
+
+	// Initialise from the first few weeks, then smooth through the series
+	// collecting one-step-ahead errors.
+	var init float64
+	for i, y := range weeks[:movingAverageWeeks] {
+		init += y / m.index(weekAt(start, i))
+	}
+	m.level = init / movingAverageWeeks
+
+	var sqErr, absErr, actual float64
+	var count int
+	for i, y := range weeks {
+		idx := m.index(weekAt(start, i))
+		predicted := math.Max(0, (m.level+m.trend)*idx)
+		if i >= movingAverageWeeks {
+			diff := y - predicted
+			sqErr += diff * diff
+			absErr += math.Abs(diff)
+			actual += y
+			count++
+		}
+
+		prevLevel := m.level
+		m.level = alpha*(y/idx) + (1-alpha)*(m.level+m.trend)
+		m.trend = beta*(m.level-prevLevel) + (1-beta)*m.trend
+	}
+
+	m.method = MethodHolt
+	if m.seasonal {
+		m.method = MethodHoltSeasonal
+	}
+
+	if count > 0 {
+		rmse := math.Sqrt(sqErr / float64(count))
+		m.rmse = &rmse
+	}
+
+	// Confidence is one minus the weighted absolute percentage error,
+	// discounted while history is short. A series with no sales at all is
+	// easy to predict but says little, so it gets a neutral score.
+	if actual > 0 {
+		m.conf = clamp(1-absErr/actual, 0, 1) * historyFactor(n)
+	} else {
+		m.conf = 0.5 * historyFactor(n)
+	}
+
+	return m
+}
+
+// predict returns the forecast for the week h weeks after the last observed
+// week (h >= 1) together with its interval.
+func (m *demandModel) predict(weekStart time.Time, h int) *forecastmodels.ForecastPeriod {
+	quantity := math.Max(0, (m.level+float64(h)*m.trend)*m.index(weekStart))
+
+	spread := 0.0
+	if m.rmse != nil {
+		spread = intervalZ * *m.rmse * math.Sqrt(float64(h))
+	}
+
+	return &forecastmodels.ForecastPeriod{
+		WeekStart:  weekStart,
+		Quantity:   round2(quantity),
+		LowerBound: round2(math.Max(0, quantity-spread)),
+		UpperBound: round2(quantity + spread),
+	}
+}
+
+// recommendMinimum sizes minimum stock to cover forecast demand over the
+// supplier lead time plus safety stock for forecast error. Lead times under a
+// week are treated as a week.
+func (m *demandModel) recommendMinimum(periods []*forecastmodels.ForecastPeriod, leadTimeDays int) *int {
+	if m.method == MethodNoHistory || len(periods) == 0 {
+		return nil
+	}
+
+	leadWeeks := math.Max(float64(leadTimeDays), 7) / 7
+
+	var demand float64
+	remaining := leadWeeks
+	for _, p := range periods {
+		if remaining <= 0 {
+			break
+		}
+		share := math.Min(1, remaining)
+		demand += p.Quantity * share
+		remaining -= share
+	}
+	if remaining > 0 {
+		demand += periods[len(periods)-1].Quantity * remaining
+	}
+
+	safety := 0.0
+	if m.rmse != nil {
+		safety = safetyStockZ * *m.rmse * math.Sqrt(leadWeeks)
+	}
+
+	recommended := int(math.Ceil(demand + safety))
+	return &recommended
+}
+
+func (m *demandModel) index(weekStart time.Time) float64 {
+	return m.indices[weekStart.Month()-1]
+}
+
+// monthlyIndices returns demand in each calendar month relative to the overall
+// mean, shrunk towards 1 for months with few observations.
+func monthlyIndices(start time.Time, weeks []float64, mean float64) [12]float64 {
+	var sums [12]float64
+	var counts [12]int
+	for i, y := range weeks {
+		month := weekAt(start, i).Month() - 1
+		sums[month] += y
+		counts[month]++
+	}
+
+	const prior = 4.0
+	var indices [12]float64
+	for i := range indices {
+		if counts[i] == 0 {
+			indices[i] = 1
+			continue
+		}
+		raw := sums[i] / float64(counts[i]) / mean
+		w := float64(counts[i])
+		indices[i] = clamp((raw*w+prior)/(w+prior), 0.25, 4)
+	}
+	return indices
+}
+
+// historyFactor scales confidence from 0 to 1 over the first half year
+func historyFactor(weeks int) float64 {
+	return math.Min(1, float64(weeks)/fullHistoryWeeks)
+}
+
+func confidenceLevel(confidence float64) string {
+	switch {
+	case confidence >= 0.7:
+		return forecastmodels.ConfidenceHigh
+	case confidence >= 0.4:
+		return forecastmodels.ConfidenceMedium
+	default:
+		return forecastmodels.ConfidenceLow
+	}
+}
+
+// weekStartOf returns the Monday starting the week that contains t, as a UTC
+// date to match DATE values read from the database.
+func weekStartOf(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func weekAt(start time.Time, i int) time.Time {
+	return start.AddDate(0, 0, 7*i)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
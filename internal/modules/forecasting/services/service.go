@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	forecastmodels "github.com/hsrvms/autoparts/internal/modules/forecasting/models"
+	"github.com/hsrvms/autoparts/internal/modules/forecasting/repositories"
+)
+
+const (
+	DefaultHorizonWeeks = 12
+	MaxHorizonWeeks     = 52
+	historyWeeks        = 104
+)
+
+var (
+	ErrInvalidItemID        = errors.New("invalid item ID")
+	ErrItemNotFound         = errors.New("item not found, inactive or a kit")
+	ErrForecastNotFound     = errors.New("no forecast for this item yet")
+	ErrNoRecommendation     = errors.New("forecast has no minimum stock recommendation")
+	ErrInvalidHorizon       = errors.New("weeks must be between 1 and 52")
+	ErrInvalidInterval      = errors.New("interval must be 'day' or 'week'")
+	ErrInvalidMinConfidence = errors.New("min_confidence must be between 0 and 1")
+	ErrInvalidHistoryStart  = errors.New("history start cannot be in the future")
+)
+
+type ForecastService interface {
+	GetForecast(ctx context.Context, itemID int, weeks int, refresh bool) (*forecastmodels.ItemForecast, error)
+	GetDemandHistory(ctx context.Context, itemID int, interval string, since *time.Time) ([]*forecastmodels.DemandPoint, error)
+	GetRecommendations(ctx context.Context, filter *forecastmodels.RecommendationFilter) ([]*forecastmodels.MinimumStockRecommendation, error)
+	ApplyRecommendation(ctx context.Context, itemID int) (*forecastmodels.ItemForecast, error)
+	RefreshAll(ctx context.Context, horizonWeeks int) (*forecastmodels.RefreshResult, error)
+}
+
+type forecastService struct {
+	repo repositories.ForecastRepository
+}
+
+func NewForecastService(repo repositories.ForecastRepository) ForecastService {
+	return &forecastService{
+		repo: repo,
+	}
+}
+
+// GetForecast returns the stored forecast for an item, trimmed to the
+// requested horizon. The forecast is recomputed when asked to, when none is
+// stored yet, or when the stored one no longer covers the horizon.
+func (s *forecastService) GetForecast(ctx context.Context, itemID int, weeks int, refresh bool) (*forecastmodels.ItemForecast, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+	if weeks == 0 {
+		weeks = DefaultHorizonWeeks
+	}
+	if weeks < 1 || weeks > MaxHorizonWeeks {
+		return nil, ErrInvalidHorizon
+	}
+
+	now := time.Now()
+
+	if !refresh {
+		stored, err := s.repo.GetForecast(ctx, itemID)
+		if err != nil {
+			return nil, err
+		}
+		if stored != nil {
+			periods := upcomingPeriods(stored.Periods, weekStartOf(now))
+			if len(periods) >= weeks {
+				stored.Periods = periods[:weeks]
+				stored.ConfidenceLevel = confidenceLevel(stored.Confidence)
+				return stored, nil
+			}
+		}
+	}
+
+	items, err := s.repo.GetForecastItems(ctx, &itemID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrItemNotFound
+	}
+
+	since := weekStartOf(now).AddDate(0, 0, -7*historyWeeks)
+	demand, err := s.repo.GetDemand(ctx, &itemID, since, forecastmodels.IntervalWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := buildForecast(items[0], demand[itemID], now, weeks)
+	if err := s.repo.SaveForecast(ctx, forecast); err != nil {
+		return nil, err
+	}
+
+	return forecast, nil
+}
+
+func (s *forecastService) GetDemandHistory(ctx context.Context, itemID int, interval string, since *time.Time) ([]*forecastmodels.DemandPoint, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+	if interval == "" {
+		interval = forecastmodels.IntervalWeek
+	}
+	if interval != forecastmodels.IntervalDay && interval != forecastmodels.IntervalWeek {
+		return nil, ErrInvalidInterval
+	}
+
+	now := time.Now()
+	var start time.Time
+	switch {
+	case since != nil:
+		if since.After(now) {
+			return nil, ErrInvalidHistoryStart
+		}
+		start = *since
+	case interval == forecastmodels.IntervalDay:
+		start = now.AddDate(0, 0, -90)
+	default:
+		start = weekStartOf(now).AddDate(-1, 0, 0)
+	}
+
+	demand, err := s.repo.GetDemand(ctx, &itemID, start, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	points := demand[itemID]
+	if points == nil {
+		points = []*forecastmodels.DemandPoint{}
+	}
+
+	return points, nil
+}
+
+func (s *forecastService) GetRecommendations(ctx context.Context, filter *forecastmodels.RecommendationFilter) ([]*forecastmodels.MinimumStockRecommendation, error) {
+	if filter != nil && filter.MinConfidence != nil {
+		if *filter.MinConfidence < 0 || *filter.MinConfidence > 1 {
+			return nil, ErrInvalidMinConfidence
+		}
+	}
+
+	recommendations, err := s.repo.GetRecommendations(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rec := range recommendations {
+		rec.ConfidenceLevel = confidenceLevel(rec.Confidence)
+	}
+
+	return recommendations, nil
+}
+
+// ApplyRecommendation copies the forecast's recommended minimum stock onto
+// the item.
+func (s *forecastService) ApplyRecommendation(ctx context.Context, itemID int) (*forecastmodels.ItemForecast, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	forecast, err := s.repo.GetForecast(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if forecast == nil {
+		return nil, ErrForecastNotFound
+	}
+	if forecast.RecommendedMinimumStock == nil {
+		return nil, ErrNoRecommendation
+	}
+
+	if err := s.repo.ApplyMinimumStock(ctx, itemID, *forecast.RecommendedMinimumStock); err != nil {
+		return nil, err
+	}
+
+	forecast.CurrentMinimumStock = *forecast.RecommendedMinimumStock
+	forecast.Periods = upcomingPeriods(forecast.Periods, weekStartOf(time.Now()))
+	forecast.ConfidenceLevel = confidenceLevel(forecast.Confidence)
+
+	return forecast, nil
+}
+
+// RefreshAll recomputes and stores forecasts for every active item. It is
+// run by the scheduler and can also be triggered by hand.
+func (s *forecastService) RefreshAll(ctx context.Context, horizonWeeks int) (*forecastmodels.RefreshResult, error) {
+	if horizonWeeks == 0 {
+		horizonWeeks = DefaultHorizonWeeks
+	}
+	if horizonWeeks < 1 || horizonWeeks > MaxHorizonWeeks {
+		return nil, ErrInvalidHorizon
+	}
+
+	now := time.Now()
+
+	items, err := s.repo.GetForecastItems(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	since := weekStartOf(now).AddDate(0, 0, -7*historyWeeks)
+	demand, err := s.repo.GetDemand(ctx, nil, since, forecastmodels.IntervalWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		forecast := buildForecast(item, demand[item.ItemID], now, horizonWeeks)
+		if err := s.repo.SaveForecast(ctx, forecast); err != nil {
+			return nil, err
+		}
+	}
+
+	return &forecastmodels.RefreshResult{
+		ItemsForecast: len(items),
+		HorizonWeeks:  horizonWeeks,
+		GeneratedAt:   now,
+	}, nil
+}
+
+// Helper functions
+
+// buildForecast fits a model to an item's weekly sales and projects it over
+// the horizon starting with the current week. The current week is still in
+// progress, so history stops at the end of the previous week and starts at the
+// item's first recorded sale.
+func buildForecast(item *forecastmodels.ForecastItem, points []*forecastmodels.DemandPoint, now time.Time, horizonWeeks int) *forecastmodels.ItemForecast {
+	current := weekStartOf(now)
+
+	var start time.Time
+	var weeks []float64
+	for _, p := range points {
+		week := weekStartOf(p.PeriodStart)
+		if !week.Before(current) {
+			continue
+		}
+		if weeks == nil {
+			start = week
+			weeks = make([]float64, weeksBetween(start, current))
+		}
+		weeks[weeksBetween(start, week)] += float64(p.Quantity)
+	}
+
+	model := fitDemandModel(start, weeks)
+
+	periods := make([]*forecastmodels.ForecastPeriod, 0, horizonWeeks)
+	for h := 1; h <= horizonWeeks; h++ {
+		periods = append(periods, model.predict(weekAt(current, h-1), h))
+	}
+
+	forecast := &forecastmodels.ItemForecast{
+		ItemID:                  item.ItemID,
+		Method:                  model.method,
+		HistoryWeeks:            len(weeks),
+		HorizonWeeks:            horizonWeeks,
+		AverageWeeklyDemand:     round2(model.mean),
+		WeeklyTrend:             math.Round(model.trend*10000) / 10000,
+		Seasonal:                model.seasonal,
+		Confidence:              math.Round(model.conf*1000) / 1000,
+		LeadTimeDays:            item.LeadTimeDays,
+		RecommendedMinimumStock: model.recommendMinimum(periods, item.LeadTimeDays),
+		GeneratedAt:             now,
+		PartNumber:              item.PartNumber,
+		Description:             item.Description,
+		CurrentMinimumStock:     item.MinimumStock,
+		Periods:                 periods,
+	}
+	if model.rmse != nil {
+		rmse := round2(*model.rmse)
+		forecast.ErrorRMSE = &rmse
+	}
+	forecast.ConfidenceLevel = confidenceLevel(forecast.Confidence)
+
+	return forecast
+}
+
+func upcomingPeriods(periods []*forecastmodels.ForecastPeriod, from time.Time) []*forecastmodels.ForecastPeriod {
+	upcoming := []*forecastmodels.ForecastPeriod{}
+	for _, p := range periods {
+		if !p.WeekStart.Before(from) {
+			upcoming = append(upcoming, p)
+		}
+	}
+	return upcoming
+}
+
+func weeksBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / (24 * 7)))
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/services"
+	"github.com/labstack/echo/v4"
+)
+
+type AttributeHandler struct {
+	service services.AttributeService
+}
+
+func NewAttributeHandler(service services.AttributeService) *AttributeHandler {
+	return &AttributeHandler{
+		service: service,
+	}
+}
+
+// GetItemAttributes handles the retrieval of an item's attribute values
+func (h *AttributeHandler) GetItemAttributes(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	attributes, err := h.service.GetItemAttributes(ctx, id)
+	if err != nil {
+		return attributeError(err)
+	}
+
+	return c.JSON(http.StatusOK, attributes)
+}
+
+// SaveItemAttributes handles replacing an item's attribute values
+func (h *AttributeHandler) SaveItemAttributes(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	update := new(inventorymodels.ItemAttributeUpdate)
+	if err := c.Bind(update); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	attributes, err := h.service.SaveItemAttributes(ctx, id, update)
+	if err != nil {
+		return attributeError(err)
+	}
+
+	return c.JSON(http.StatusOK, attributes)
+}
+
+func attributeError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidItemID):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrItemNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrItemHasNoCategory), errors.Is(err, services.ErrUnknownAttribute),
+		errors.Is(err, services.ErrInvalidAttributeValue), errors.Is(err, services.ErrAttributeOutOfRange),
+		errors.Is(err, services.ErrAttributeNotAllowed), errors.Is(err, services.ErrRequiredAttribute):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// parseAttributeFilters reads attr.<key>=value, attr.<key>.min and
+// attr.<key>.max query parameters. Bounds that are not numbers are ignored,
+// as with the other item filters.
+func parseAttributeFilters(params url.Values) []inventorymodels.AttributeFilter {
+	filters := make(map[string]*inventorymodels.AttributeFilter)
+	for name, values := range params {
+		if !strings.HasPrefix(name, "attr.") || len(values) == 0 || values[0] == "" {
+			continue
+		}
+
+		key := strings.TrimPrefix(name, "attr.")
+		bound := ""
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key, bound = key[:i], key[i+1:]
+		}
+		if key == "" {
+			continue
+		}
+
+		filter, ok := filters[key]
+		if !ok {
+			filter = &inventorymodels.AttributeFilter{Key: key}
+		}
+
+		value := values[0]
+		switch bound {
+		case "":
+			filter.Value = &value
+		case "min", "max":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			if bound == "min" {
+				filter.Min = &number
+			} else {
+				filter.Max = &number
+			}
+		default:
+			continue
+		}
+		filters[key] = filter
+	}
+
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]inventorymodels.AttributeFilter, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *filters[key])
+	}
+	return result
+}
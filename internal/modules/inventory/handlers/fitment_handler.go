@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/services"
+	"github.com/labstack/echo/v4"
+)
+
+type FitmentHandler struct {
+	service services.FitmentService
+}
+
+func NewFitmentHandler(service services.FitmentService) *FitmentHandler {
+	return &FitmentHandler{
+		service: service,
+	}
+}
+
+// ImportFitment handles a bulk fitment import. The file is sent either as a
+// multipart "file" field or as the request body; the format comes from the
+// format query parameter, else the file extension or content type.
+func (h *FitmentHandler) ImportFitment(c echo.Context) error {
+	opts := &inventorymodels.FitmentImportOptions{
+		Format:        strings.ToLower(c.QueryParam("format")),
+		CreateMissing: c.QueryParam("create_missing") == "true",
+		DryRun:        c.QueryParam("dry_run") == "true",
+	}
+
+	var body io.Reader = c.Request().Body
+	name := ""
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+
+	if strings.HasPrefix(contentType, echo.MIMEMultipartForm) {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		defer file.Close()
+
+		body = file
+		name = header.Filename
+		contentType = header.Header.Get(echo.HeaderContentType)
+	}
+
+	if opts.Format == "" {
+		opts.Format = fitmentFormat(name, contentType)
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.service.Import(ctx, body, opts)
+	if err != nil {
+		return fitmentError(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// fitmentFormat guesses the format of an upload from its name or content type
+func fitmentFormat(name, contentType string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xml":
+		return inventorymodels.FitmentFormatACES
+	case ".csv":
+		return inventorymodels.FitmentFormatCSV
+	}
+
+	switch {
+	case strings.Contains(contentType, "xml"):
+		return inventorymodels.FitmentFormatACES
+	case strings.Contains(contentType, "csv"):
+		return inventorymodels.FitmentFormatCSV
+	}
+	return ""
+}
+
+func fitmentError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidFitmentFormat):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrInvalidFitmentFile):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -58,6 +58,25 @@ func (h *InventoryHandler) GetItems(c echo.Context) error {
 		filter.IsActive = &active
 	}
 
+	if isClearance := c.QueryParam("is_clearance"); isClearance != "" {
+		clearance := isClearance == "true"
+		filter.IsClearance = &clearance
+	}
+
+	if isKit := c.QueryParam("is_kit"); isKit != "" {
+		kit := isKit == "true"
+		filter.IsKit = &kit
+	}
+
+	if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+		id, err := strconv.Atoi(warehouseID)
+		if err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+
+	filter.Attributes = parseAttributeFilters(c.QueryParams())
+
 	ctx := c.Request().Context()
 	items, err := h.service.GetItems(ctx, filter)
 	if err != nil {
@@ -116,6 +135,26 @@ func (h *InventoryHandler) GetItemByBarcode(c echo.Context) error {
 	return c.JSON(http.StatusOK, item)
 }
 
+// GetItemByPartNumber handles the retrieval of an item by its part number,
+// redirecting superseded part numbers to the current part
+func (h *InventoryHandler) GetItemByPartNumber(c echo.Context) error {
+	partNumber := c.Param("partNumber")
+	if partNumber == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "part number is required")
+	}
+
+	ctx := c.Request().Context()
+	item, err := h.service.GetItemByPartNumber(ctx, partNumber)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if item == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "item not found")
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
 // CreateItem handles the creation of a new item
 func (h *InventoryHandler) CreateItem(c echo.Context) error {
 	item := new(inventorymodels.Item)
@@ -127,6 +166,9 @@ func (h *InventoryHandler) CreateItem(c echo.Context) error {
 	id, err := h.service.CreateItem(ctx, item)
 	if err != nil {
 		switch err {
+		case services.ErrInvalidTrackingMode, services.ErrInvalidWarranty, services.ErrInvalidCoreCharge,
+			services.ErrTrackedStock:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		case services.ErrDuplicatePartNumber, services.ErrDuplicateBarcode:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		default:
@@ -155,9 +197,12 @@ func (h *InventoryHandler) UpdateItem(c echo.Context) error {
 	err = h.service.UpdateItem(ctx, item)
 	if err != nil {
 		switch err {
+		case services.ErrInvalidTrackingMode, services.ErrInvalidWarranty, services.ErrInvalidCoreCharge:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		case services.ErrItemNotFound:
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
-		case services.ErrDuplicatePartNumber, services.ErrDuplicateBarcode:
+		case services.ErrDuplicatePartNumber, services.ErrDuplicateBarcode, services.ErrTrackingModeLocked,
+			services.ErrKitHoldsNoStock, services.ErrKitTracking, services.ErrTrackedStock:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -215,7 +260,9 @@ func (h *InventoryHandler) AddCompatibility(c echo.Context) error {
 	id, err := h.service.AddCompatibility(ctx, compatibility)
 	if err != nil {
 		switch err {
-		case services.ErrItemNotFound:
+		case services.ErrInvalidItemID, services.ErrInvalidSubmodelID, services.ErrInvalidPosition, services.ErrInvalidCompatYears:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrItemNotFound, services.ErrSubmodelNotFound:
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
 		case services.ErrCompatibilityExists:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
@@ -228,7 +275,48 @@ func (h *InventoryHandler) AddCompatibility(c echo.Context) error {
 	return c.JSON(http.StatusCreated, compatibility)
 }
 
-// RemoveCompatibility handles removing a compatibility
+// UpdateCompatibility handles changing the notes and fitment qualifiers of a compatibility
+func (h *InventoryHandler) UpdateCompatibility(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	submodelID, err := strconv.Atoi(c.Param("submodelId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid submodel ID")
+	}
+
+	compatibility := new(inventorymodels.Compatibility)
+	if err := c.Bind(compatibility); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	compatibility.ItemID = itemID
+	compatibility.SubmodelID = submodelID
+
+	ctx := c.Request().Context()
+	err = h.service.UpdateCompatibility(ctx, compatibility)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidItemID, services.ErrInvalidSubmodelID, services.ErrInvalidPosition, services.ErrInvalidCompatYears:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrSubmodelNotFound, services.ErrCompatNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrCompatAmbiguous, services.ErrCompatibilityExists:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			if err.Error() == "compatibility not found" {
+				return echo.NewHTTPError(http.StatusNotFound, err.Error())
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, compatibility)
+}
+
+// RemoveCompatibility handles removing a compatibility, or every fitment of
+// the item to the submodel when no compat_id is given
 func (h *InventoryHandler) RemoveCompatibility(c echo.Context) error {
 	itemID, err := strconv.Atoi(c.Param("itemId"))
 	if err != nil {
@@ -240,8 +328,17 @@ func (h *InventoryHandler) RemoveCompatibility(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid submodel ID")
 	}
 
+	var compatID *int
+	if id := c.QueryParam("compat_id"); id != "" {
+		cid, err := strconv.Atoi(id)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid compatibility ID")
+		}
+		compatID = &cid
+	}
+
 	ctx := c.Request().Context()
-	err = h.service.RemoveCompatibility(ctx, itemID, submodelID)
+	err = h.service.RemoveCompatibility(ctx, itemID, submodelID, compatID)
 	if err != nil {
 		if err.Error() == "compatibility not found" {
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
@@ -252,17 +349,40 @@ func (h *InventoryHandler) RemoveCompatibility(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
-// GetCompatibleItems handles retrieving all compatible items for a vehicle submodel
+// GetCompatibleItems handles retrieving all compatible items for a vehicle submodel,
+// optionally only those that fit a model year, position and engine code
 func (h *InventoryHandler) GetCompatibleItems(c echo.Context) error {
 	submodelID, err := strconv.Atoi(c.Param("submodelId"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid submodel ID")
 	}
 
+	filter := &inventorymodels.CompatibleItemFilter{}
+	if year := c.QueryParam("year"); year != "" {
+		modelYear, err := strconv.Atoi(year)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid year")
+		}
+		filter.ModelYear = &modelYear
+	}
+
+	if position := c.QueryParam("position"); position != "" {
+		filter.Position = &position
+	}
+
+	if engineCode := c.QueryParam("engine_code"); engineCode != "" {
+		filter.EngineCode = &engineCode
+	}
+
 	ctx := c.Request().Context()
-	items, err := h.service.GetCompatibleItems(ctx, submodelID)
+	items, err := h.service.GetCompatibleItems(ctx, submodelID, filter)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		switch err {
+		case services.ErrInvalidSubmodelID, services.ErrInvalidPosition:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
 	}
 
 	return c.JSON(http.StatusOK, items)
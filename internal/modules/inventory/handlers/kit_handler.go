@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/services"
+	"github.com/labstack/echo/v4"
+)
+
+type KitHandler struct {
+	service services.KitService
+}
+
+func NewKitHandler(service services.KitService) *KitHandler {
+	return &KitHandler{
+		service: service,
+	}
+}
+
+// GetKit handles the retrieval of a kit's components, price and availability
+func (h *KitHandler) GetKit(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	var warehouseID *int
+	if warehouse := c.QueryParam("warehouse_id"); warehouse != "" {
+		wid, err := strconv.Atoi(warehouse)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+		}
+		warehouseID = &wid
+	}
+
+	ctx := c.Request().Context()
+	kit, err := h.service.GetKit(ctx, id, warehouseID)
+	if err != nil {
+		return kitError(err)
+	}
+
+	return c.JSON(http.StatusOK, kit)
+}
+
+// SaveKit handles setting an item up as a kit or changing its components and pricing
+func (h *KitHandler) SaveKit(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	update := new(inventorymodels.KitUpdate)
+	if err := c.Bind(update); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	kit, err := h.service.SaveKit(ctx, id, update)
+	if err != nil {
+		return kitError(err)
+	}
+
+	return c.JSON(http.StatusOK, kit)
+}
+
+// RemoveKit handles turning a kit back into an ordinary item
+func (h *KitHandler) RemoveKit(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.RemoveKit(ctx, id); err != nil {
+		return kitError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func kitError(err error) error {
+	switch err {
+	case services.ErrInvalidItemID, services.ErrInvalidKitPricing, services.ErrInvalidKitDiscount,
+		services.ErrKitNeedsComponents, services.ErrInvalidKitComponent, services.ErrInvalidPrice:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrItemNotFound, services.ErrNotKit, services.ErrKitComponentNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrKitHoldsNoStock, services.ErrKitTracking, services.ErrNestedKit:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
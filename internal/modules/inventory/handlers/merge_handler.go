@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/services"
+	"github.com/labstack/echo/v4"
+)
+
+type MergeHandler struct {
+	service services.MergeService
+}
+
+func NewMergeHandler(service services.MergeService) *MergeHandler {
+	return &MergeHandler{
+		service: service,
+	}
+}
+
+// GetDuplicateItems handles listing items that look like duplicates of one another
+func (h *MergeHandler) GetDuplicateItems(c echo.Context) error {
+	filter := &inventorymodels.DuplicateItemFilter{}
+
+	if threshold := c.QueryParam("threshold"); threshold != "" {
+		t, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, services.ErrInvalidMergeThreshold.Error())
+		}
+		filter.Threshold = &t
+	}
+
+	if categoryID, err := strconv.Atoi(c.QueryParam("category_id")); err == nil {
+		filter.CategoryID = &categoryID
+	}
+
+	ctx := c.Request().Context()
+	duplicates, err := h.service.GetDuplicates(ctx, filter)
+	if err != nil {
+		return mergeError(err)
+	}
+
+	return c.JSON(http.StatusOK, duplicates)
+}
+
+// MergeItems handles merging a duplicate item into the item in the path
+func (h *MergeHandler) MergeItems(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	request := new(inventorymodels.MergeRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	record, err := h.service.MergeItems(ctx, id, request)
+	if err != nil {
+		return mergeError(err)
+	}
+
+	return c.JSON(http.StatusOK, record)
+}
+
+// GetItemMerges handles listing past item merges
+func (h *MergeHandler) GetItemMerges(c echo.Context) error {
+	var itemID *int
+	if id, err := strconv.Atoi(c.QueryParam("item_id")); err == nil {
+		itemID = &id
+	}
+
+	ctx := c.Request().Context()
+	records, err := h.service.GetMergeHistory(ctx, itemID)
+	if err != nil {
+		return mergeError(err)
+	}
+
+	return c.JSON(http.StatusOK, records)
+}
+
+func mergeError(err error) error {
+	switch err {
+	case services.ErrInvalidMergeThreshold, services.ErrInvalidItemID, services.ErrInvalidDuplicateID:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrItemNotFound, services.ErrMergedItemNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrMergeSupersession, services.ErrMergeSharedPurchaseOrder, services.ErrMergeSharedTransfer,
+		services.ErrMergeSharedSerial, services.ErrMergeSharedCoreReturn:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrMergeIntoSelf, services.ErrMergeKit, services.ErrMergeTrackingMismatch:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
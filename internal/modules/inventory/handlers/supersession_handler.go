@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/services"
+	"github.com/labstack/echo/v4"
+)
+
+type SupersessionHandler struct {
+	service services.SupersessionService
+}
+
+func NewSupersessionHandler(service services.SupersessionService) *SupersessionHandler {
+	return &SupersessionHandler{
+		service: service,
+	}
+}
+
+// GetSupersessionHistory handles the retrieval of the parts an item replaced and was replaced by
+func (h *SupersessionHandler) GetSupersessionHistory(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	history, err := h.service.GetHistory(ctx, id)
+	if err != nil {
+		return supersessionError(err)
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
+// Supersede handles recording that an item has been replaced by a new part
+func (h *SupersessionHandler) Supersede(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	supersession := new(inventorymodels.Supersession)
+	if err := c.Bind(supersession); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	supersession.OldItemID = id
+
+	ctx := c.Request().Context()
+	created, err := h.service.Supersede(ctx, supersession)
+	if err != nil {
+		return supersessionError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// RemoveSupersession handles undoing an item's supersession
+func (h *SupersessionHandler) RemoveSupersession(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.RemoveSupersession(ctx, id); err != nil {
+		return supersessionError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func supersessionError(err error) error {
+	switch err {
+	case services.ErrInvalidItemID:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrItemNotFound, services.ErrReplacementNotFound, services.ErrSupersessionNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrAlreadySuperseded, services.ErrSupersessionLoop:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/services"
+	"github.com/labstack/echo/v4"
+)
+
+type TrackingHandler struct {
+	service services.TrackingService
+}
+
+func NewTrackingHandler(service services.TrackingService) *TrackingHandler {
+	return &TrackingHandler{
+		service: service,
+	}
+}
+
+// GetItemSerials handles listing the serial numbered units of an item
+func (h *TrackingHandler) GetItemSerials(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	filter := &inventorymodels.SerialFilter{}
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	ctx := c.Request().Context()
+	serials, err := h.service.GetItemSerials(ctx, id, filter)
+	if err != nil {
+		return trackingError(err)
+	}
+
+	return c.JSON(http.StatusOK, serials)
+}
+
+// GetItemLots handles listing the lots an item was received in
+func (h *TrackingHandler) GetItemLots(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	lots, err := h.service.GetItemLots(ctx, id)
+	if err != nil {
+		return trackingError(err)
+	}
+
+	return c.JSON(http.StatusOK, lots)
+}
+
+// FindSerial handles looking up who bought a serial numbered unit
+func (h *TrackingHandler) FindSerial(c echo.Context) error {
+	ctx := c.Request().Context()
+	serials, err := h.service.FindSerial(ctx, c.Param("serialNumber"))
+	if err != nil {
+		return trackingError(err)
+	}
+
+	return c.JSON(http.StatusOK, serials)
+}
+
+// TraceLot handles tracing where a lot went
+func (h *TrackingHandler) TraceLot(c echo.Context) error {
+	ctx := c.Request().Context()
+	lots, err := h.service.TraceLot(ctx, c.Param("lotNumber"))
+	if err != nil {
+		return trackingError(err)
+	}
+
+	return c.JSON(http.StatusOK, lots)
+}
+
+func trackingError(err error) error {
+	switch err {
+	case services.ErrInvalidItemID, services.ErrInvalidSerialStatus:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrItemNotFound, services.ErrSerialNotFound, services.ErrLotNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,58 @@
+package inventorymodels
+
+// Attribute data types, as defined on the item's category
+const (
+	AttributeText    = "text"
+	AttributeNumber  = "number"
+	AttributeBoolean = "boolean"
+	AttributeEnum    = "enum"
+)
+
+// ItemAttribute is one attribute of the item's category, or of a category
+// above it, with the item's value for it
+type ItemAttribute struct {
+	AttributeID   int         `json:"attribute_id"`
+	AttributeKey  string      `json:"attribute_key"`
+	Label         string      `json:"label"`
+	DataType      string      `json:"data_type"`
+	Unit          *string     `json:"unit,omitempty"`
+	AllowedValues []string    `json:"allowed_values,omitempty"`
+	MinValue      *float64    `json:"min_value,omitempty"`
+	MaxValue      *float64    `json:"max_value,omitempty"`
+	IsRequired    bool        `json:"is_required"`
+	CategoryID    int         `json:"category_id"` // Category defining the attribute
+	CategoryName  string      `json:"category_name"`
+	Value         interface{} `json:"value"` // string, number or boolean; null when not set
+}
+
+// ItemAttributes lists an item's attributes. MissingRequired holds the keys
+// of required attributes the item has no value for yet.
+type ItemAttributes struct {
+	ItemID          int              `json:"item_id"`
+	CategoryID      *int             `json:"category_id,omitempty"`
+	Attributes      []*ItemAttribute `json:"attributes"`
+	MissingRequired []string         `json:"missing_required"`
+}
+
+// ItemAttributeUpdate replaces all of an item's attribute values, by key.
+// Attributes left out or set to null are cleared.
+type ItemAttributeUpdate struct {
+	Values map[string]interface{} `json:"values"`
+}
+
+// AttributeValue is a validated value, held in the field for its data type
+type AttributeValue struct {
+	AttributeID int
+	Text        *string // text and enum attributes
+	Number      *float64
+	Boolean     *bool
+}
+
+// AttributeFilter matches items by an attribute value: Value compares
+// equal, ignoring case for text, and Min and Max bound numbers
+type AttributeFilter struct {
+	Key   string
+	Value *string
+	Min   *float64
+	Max   *float64
+}
@@ -2,11 +2,28 @@ package inventorymodels
 
 import "time"
 
+// Fitment positions. Combined positions fit either of their parts, so a
+// front_left part is found when asking for front or for left.
+const (
+	PositionFront      = "front"
+	PositionRear       = "rear"
+	PositionLeft       = "left"
+	PositionRight      = "right"
+	PositionFrontLeft  = "front_left"
+	PositionFrontRight = "front_right"
+	PositionRearLeft   = "rear_left"
+	PositionRearRight  = "rear_right"
+)
+
 type Compatibility struct {
 	CompatID   int       `json:"compat_id" db:"compat_id"`
 	ItemID     int       `json:"item_id" db:"item_id"`
 	SubmodelID int       `json:"submodel_id" db:"submodel_id"`
 	Notes      *string   `json:"notes,omitempty" db:"notes"`
+	YearFrom   *int      `json:"year_from,omitempty" db:"year_from"` // Defaults to the submodel's years
+	YearTo     *int      `json:"year_to,omitempty" db:"year_to"`
+	Position   *string   `json:"position,omitempty" db:"position"`
+	EngineCode *string   `json:"engine_code,omitempty" db:"engine_code"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 
 	// Additional fields for API responses
@@ -14,3 +31,11 @@ type Compatibility struct {
 	MakeName     string `json:"make_name,omitempty" db:"-"`
 	SubmodelName string `json:"submodel_name,omitempty" db:"-"`
 }
+
+// CompatibleItemFilter narrows a submodel's compatible items to exact fits.
+// Compatibilities without a position or engine code fit any.
+type CompatibleItemFilter struct {
+	ModelYear  *int    `query:"year"`
+	Position   *string `query:"position"`
+	EngineCode *string `query:"engine_code"`
+}
@@ -0,0 +1,93 @@
+package inventorymodels
+
+// Fitment import formats
+const (
+	FitmentFormatACES = "aces"
+	FitmentFormatCSV  = "csv"
+)
+
+// FitmentRecord is one application read from a fitment file: a part that
+// fits a make, model and optionally a submodel over a range of years
+type FitmentRecord struct {
+	Ref        string  `json:"ref"` // Line number for CSV, App id for ACES
+	Delete     bool    `json:"delete,omitempty"`
+	PartNumber string  `json:"part_number"`
+	Make       string  `json:"make"`
+	Model      string  `json:"model"`
+	Submodel   string  `json:"submodel,omitempty"` // Empty matches every submodel in the years
+	YearFrom   int     `json:"year_from"`
+	YearTo     *int    `json:"year_to,omitempty"`
+	Position   *string `json:"position,omitempty"`
+	EngineCode *string `json:"engine_code,omitempty"`
+	Notes      *string `json:"notes,omitempty"`
+}
+
+// VehicleCatalogueEntry is one row of the vehicle tables, for matching. Makes
+// without models and models without submodels have the later fields nil.
+type VehicleCatalogueEntry struct {
+	MakeID       int
+	MakeName     string
+	ModelID      *int
+	ModelName    *string
+	SubmodelID   *int
+	SubmodelName *string
+	YearFrom     *int
+	YearTo       *int
+}
+
+// FitmentVehicle is a vehicle to create because a fitment names it and the
+// catalogue does not have it yet
+type FitmentVehicle struct {
+	Make     string
+	Model    string
+	Submodel string
+	YearFrom int
+	YearTo   *int
+}
+
+// FitmentLink is a compatibility row to write, to an existing submodel or
+// to one created by the import. Years are set only where the record covers
+// fewer years than the submodel.
+type FitmentLink struct {
+	ItemID     int
+	SubmodelID int
+	Vehicle    *FitmentVehicle
+	YearFrom   *int
+	YearTo     *int
+	Position   *string
+	EngineCode *string
+	Notes      *string
+}
+
+// FitmentPlan is what an import will write once every record is matched
+type FitmentPlan struct {
+	Add    []*FitmentLink
+	Remove []*FitmentLink
+}
+
+// FitmentImportResult reports an import. With dry_run nothing is kept but
+// the counts are what the import would have written.
+type FitmentImportResult struct {
+	Format           string              `json:"format"`
+	DryRun           bool                `json:"dry_run"`
+	Records          int                 `json:"records"`
+	Matched          int                 `json:"matched"`
+	Added            int                 `json:"added"`
+	AlreadyLinked    int                 `json:"already_linked"`
+	Removed          int                 `json:"removed"`
+	MakesCreated     int                 `json:"makes_created"`
+	ModelsCreated    int                 `json:"models_created"`
+	SubmodelsCreated int                 `json:"submodels_created"`
+	Unmatched        []*UnmatchedFitment `json:"unmatched"`
+}
+
+type UnmatchedFitment struct {
+	*FitmentRecord
+	Reason string `json:"reason"`
+}
+
+type FitmentImportOptions struct {
+	Format        string
+	CreateMissing bool // Create makes, models and named submodels not in the catalogue
+	DryRun        bool
+}
@@ -2,6 +2,13 @@ package inventorymodels
 
 import "time"
 
+// Warranty units
+const (
+	WarrantyDays   = "days"
+	WarrantyMonths = "months"
+	WarrantyYears  = "years"
+)
+
 type Item struct {
 	ItemID         int       `json:"item_id" db:"item_id"`
 	PartNumber     string    `json:"part_number" db:"part_number"`
@@ -10,7 +17,9 @@ type Item struct {
 	BuyPrice       float64   `json:"buy_price" db:"buy_price"`
 	SellPrice      float64   `json:"sell_price" db:"sell_price"`
 	CurrentStock   int       `json:"current_stock" db:"current_stock"`
+	AvailableStock int       `json:"available_stock" db:"available_stock"` // On hand less reservations, read only
 	MinimumStock   int       `json:"minimum_stock" db:"minimum_stock"`
+	ReorderUpTo    *int      `json:"reorder_up_to,omitempty" db:"reorder_up_to"`
 	Barcode        *string   `json:"barcode,omitempty" db:"barcode"`
 	SupplierID     *int      `json:"supplier_id,omitempty" db:"supplier_id"`
 	LocationAisle  *string   `json:"location_aisle,omitempty" db:"location_aisle"`
@@ -18,26 +27,59 @@ type Item struct {
 	LocationBin    *string   `json:"location_bin,omitempty" db:"location_bin"`
 	WeightKg       *float64  `json:"weight_kg,omitempty" db:"weight_kg"`
 	DimensionsCm   *string   `json:"dimensions_cm,omitempty" db:"dimensions_cm"`
-	WarrantyPeriod *string   `json:"warranty_period,omitempty" db:"warranty_period"`
+	WarrantyPeriod *string   `json:"warranty_period,omitempty" db:"warranty_period"` // Free text, superseded by WarrantyLength and WarrantyUnit
+	WarrantyLength *int      `json:"warranty_length,omitempty" db:"warranty_length"`
+	WarrantyUnit   *string   `json:"warranty_unit,omitempty" db:"warranty_unit"` // days, months or years
 	ImageURL       *string   `json:"image_url,omitempty" db:"image_url"`
 	IsActive       bool      `json:"is_active" db:"is_active"`
+	IsClearance    bool      `json:"is_clearance" db:"is_clearance"`
+	TrackingMode   string    `json:"tracking_mode" db:"tracking_mode"`       // none, serial or lot
+	CoreCharge     *float64  `json:"core_charge,omitempty" db:"core_charge"` // Deposit per unit, refunded when the old unit is returned
 	Notes          *string   `json:"notes,omitempty" db:"notes"`
+	AverageCost    *float64  `json:"average_cost,omitempty" db:"average_cost"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 
+	// Kits are set up through the kit endpoints and are read only here
+	IsKit              bool     `json:"is_kit" db:"is_kit"`
+	KitPricing         *string  `json:"kit_pricing,omitempty" db:"kit_pricing"` // fixed or discount
+	KitDiscountPercent *float64 `json:"kit_discount_percent,omitempty" db:"kit_discount_percent"`
+
+	// Supersession, read only. Looking an item up by a superseded part number
+	// returns the current part with RedirectedFrom set to the number asked for.
+	SupersededBy   *int    `json:"superseded_by,omitempty" db:"superseded_by"` // Item that directly replaces this one
+	RedirectedFrom *string `json:"redirected_from,omitempty" db:"-"`
+
 	// Additional fields for API responses
-	CategoryName *string `json:"category_name,omitempty" db:"-"`
-	SupplierName *string `json:"supplier_name,omitempty" db:"-"`
+	CategoryName *string       `json:"category_name,omitempty" db:"-"`
+	SupplierName *string       `json:"supplier_name,omitempty" db:"-"`
+	Locations    []*StockLevel `json:"locations,omitempty" db:"-"`
+}
+
+// StockLevel is the quantity of an item held at one warehouse
+type StockLevel struct {
+	WarehouseID   int     `json:"warehouse_id" db:"warehouse_id"`
+	WarehouseCode string  `json:"warehouse_code" db:"warehouse_code"`
+	WarehouseName string  `json:"warehouse_name" db:"warehouse_name"`
+	BinID         *int    `json:"bin_id,omitempty" db:"bin_id"`
+	BinCode       *string `json:"bin_code,omitempty" db:"bin_code"`
+	Quantity      int     `json:"quantity" db:"quantity"`
 }
 
 type ItemFilter struct {
-	CategoryID *int    `query:"category_id"`
-	SupplierID *int    `query:"supplier_id"`
-	PartNumber *string `query:"part_number"`
-	SearchTerm *string `query:"search"`
-	LowStock   *bool   `query:"low_stock"`
-	MakeID     *int    `query:"make_id"`
-	ModelID    *int    `query:"model_id"`
-	SubmodelID *int    `query:"submodel_id"`
-	IsActive   *bool   `query:"is_active"`
+	CategoryID  *int    `query:"category_id"`
+	SupplierID  *int    `query:"supplier_id"`
+	PartNumber  *string `query:"part_number"`
+	SearchTerm  *string `query:"search"`
+	LowStock    *bool   `query:"low_stock"`
+	MakeID      *int    `query:"make_id"`
+	ModelID     *int    `query:"model_id"`
+	SubmodelID  *int    `query:"submodel_id"`
+	IsActive    *bool   `query:"is_active"`
+	IsClearance *bool   `query:"is_clearance"`
+	IsKit       *bool   `query:"is_kit"`
+	WarehouseID *int    `query:"warehouse_id"`
+
+	// Attribute values, given as attr.<key>=value, attr.<key>.min and attr.<key>.max
+	Attributes []AttributeFilter `query:"-"`
 }
@@ -0,0 +1,45 @@
+package inventorymodels
+
+// Kit pricing. A fixed kit sells at its own sell price; a discount kit sells
+// at its components' total less a percentage.
+const (
+	KitPricingFixed    = "fixed"
+	KitPricingDiscount = "discount"
+)
+
+// Kit is an item sold as a bundle of component items, such as a timing belt
+// job. It holds no stock of its own: selling one takes its components out of
+// stock, and it is available as far as its components are.
+type Kit struct {
+	ItemID          int             `json:"item_id"`
+	PartNumber      string          `json:"part_number"`
+	Description     string          `json:"description"`
+	Pricing         string          `json:"pricing"`
+	DiscountPercent *float64        `json:"discount_percent,omitempty"`
+	ComponentsPrice float64         `json:"components_price"` // Components' sell prices times their quantities
+	SellPrice       float64         `json:"sell_price"`
+	BuyPrice        float64         `json:"buy_price"`
+	WarehouseID     *int            `json:"warehouse_id,omitempty"` // Availability is for this warehouse, or all of them when nil
+	AvailableStock  int             `json:"available_stock"`        // Complete kits the free component stock makes up
+	Components      []*KitComponent `json:"components"`
+}
+
+// KitComponent is one item that goes into a kit
+type KitComponent struct {
+	ItemID         int     `json:"item_id"`
+	PartNumber     string  `json:"part_number"`
+	Description    string  `json:"description"`
+	Quantity       int     `json:"quantity"` // Units per kit
+	SellPrice      float64 `json:"sell_price"`
+	IsActive       bool    `json:"is_active"`
+	AvailableStock int     `json:"available_stock"`
+}
+
+// KitUpdate sets an item up as a kit, replacing any components it had. Only
+// the item ID and quantity of each component are read.
+type KitUpdate struct {
+	Pricing         string          `json:"pricing"`
+	DiscountPercent *float64        `json:"discount_percent,omitempty"` // Required for discount pricing
+	SellPrice       *float64        `json:"sell_price,omitempty"`       // Fixed pricing only; the item's sell price is kept when nil
+	Components      []*KitComponent `json:"components"`
+}
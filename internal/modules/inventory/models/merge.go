@@ -0,0 +1,59 @@
+package inventorymodels
+
+import "time"
+
+// Why two items were reported as duplicates
+const (
+	DuplicateSamePartNumber = "part_number" // Part numbers equal apart from spacing and punctuation
+	DuplicateSimilar        = "similar"     // Part numbers and descriptions both alike
+)
+
+// DuplicateItem is a pair of items that look like the same part. The older
+// item comes first as the suggested survivor.
+type DuplicateItem struct {
+	ItemID               int     `json:"item_id"`
+	PartNumber           string  `json:"part_number"`
+	Description          string  `json:"description"`
+	DuplicateID          int     `json:"duplicate_id"`
+	DuplicatePartNumber  string  `json:"duplicate_part_number"`
+	DuplicateDescription string  `json:"duplicate_description"`
+	Reason               string  `json:"reason"`
+	Similarity           float64 `json:"similarity"`
+}
+
+type DuplicateItemFilter struct {
+	Threshold  *float64 `query:"threshold"`
+	CategoryID *int     `query:"category_id"`
+}
+
+// ItemMergeConflicts counts the records that would hold both items once
+// merged, where the schema allows the item only once
+type ItemMergeConflicts struct {
+	PurchaseOrders    int
+	StockTransfers    int
+	SerialNumbers     int
+	CoreReturns       int
+	SupersessionChain bool // One item supersedes the other, directly or through a chain
+}
+
+// MergeRequest names the duplicate to merge into the item in the path
+type MergeRequest struct {
+	DuplicateID int     `json:"duplicate_id"`
+	MergedBy    *string `json:"merged_by,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+// MergeRecord is the audit entry of a merge. Repointed counts the rows moved
+// to the survivor, and the rows combined with or dropped in favour of the
+// survivor's, by table.
+type MergeRecord struct {
+	MergeID    int              `json:"merge_id"`
+	EntityType string           `json:"entity_type"`
+	SurvivorID int              `json:"survivor_id"`
+	MergedID   int              `json:"merged_id"`
+	MergedName string           `json:"merged_name"`
+	Repointed  map[string]int64 `json:"repointed"`
+	MergedBy   *string          `json:"merged_by,omitempty"`
+	Notes      *string          `json:"notes,omitempty"`
+	MergedAt   time.Time        `json:"merged_at"`
+}
@@ -0,0 +1,32 @@
+package inventorymodels
+
+import "time"
+
+// Supersession records a manufacturer replacing an old part number with a
+// new one
+type Supersession struct {
+	SupersessionID      int       `json:"supersession_id" db:"supersession_id"`
+	OldItemID           int       `json:"old_item_id" db:"old_item_id"`
+	NewItemID           int       `json:"new_item_id" db:"new_item_id"`
+	SupersededOn        time.Time `json:"superseded_on" db:"superseded_on"`
+	IsDirectInterchange bool      `json:"is_direct_interchange" db:"is_direct_interchange"` // The new part fits wherever the old one did
+	Notes               *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	OldPartNumber  string `json:"old_part_number,omitempty" db:"old_part_number"`
+	OldDescription string `json:"old_description,omitempty" db:"old_description"`
+	NewPartNumber  string `json:"new_part_number,omitempty" db:"new_part_number"`
+	NewDescription string `json:"new_description,omitempty" db:"new_description"`
+}
+
+// SupersessionHistory is every supersession an item is part of: the parts
+// it replaced, directly or through earlier supersessions, and the parts that
+// have replaced it since, oldest first
+type SupersessionHistory struct {
+	ItemID            int             `json:"item_id"`
+	PartNumber        string          `json:"part_number"`
+	CurrentItemID     int             `json:"current_item_id"`
+	CurrentPartNumber string          `json:"current_part_number"`
+	Supersessions     []*Supersession `json:"supersessions"`
+}
@@ -0,0 +1,80 @@
+package inventorymodels
+
+import "time"
+
+// Item tracking modes. Serial tracked items record a serial number per unit
+// and lot tracked items a batch number per receipt.
+const (
+	TrackingNone   = "none"
+	TrackingSerial = "serial"
+	TrackingLot    = "lot"
+)
+
+// Serial statuses
+const (
+	SerialInStock = "in_stock"
+	SerialSold    = "sold"
+)
+
+// SerialUnit is one serial numbered unit, with the receipt it came in on and
+// the sale it went out on
+type SerialUnit struct {
+	SerialID     int        `json:"serial_id" db:"serial_id"`
+	ItemID       int        `json:"item_id" db:"item_id"`
+	SerialNumber string     `json:"serial_number" db:"serial_number"`
+	Status       string     `json:"status" db:"status"`
+	PurchaseID   *int       `json:"purchase_id,omitempty" db:"purchase_id"`
+	WarehouseID  *int       `json:"warehouse_id,omitempty" db:"warehouse_id"` // Nil while in transit
+	SaleID       *int       `json:"sale_id,omitempty" db:"sale_id"`
+	ReceivedAt   time.Time  `json:"received_at" db:"received_at"`
+	SoldAt       *time.Time `json:"sold_at,omitempty" db:"sold_at"`
+
+	// Additional fields for API responses
+	ItemPartNumber    string    `json:"item_part_number" db:"item_part_number"`
+	ItemDescription   string    `json:"item_description" db:"item_description"`
+	WarehouseCode     *string   `json:"warehouse_code,omitempty" db:"warehouse_code"`
+	SupplierName      *string   `json:"supplier_name,omitempty" db:"supplier_name"`
+	InvoiceNumber     *string   `json:"invoice_number,omitempty" db:"invoice_number"`
+	TransactionNumber *string   `json:"transaction_number,omitempty" db:"transaction_number"`
+	Customer          *Customer `json:"customer,omitempty" db:"-"`
+}
+
+// Lot is a batch of an item received on one purchase
+type Lot struct {
+	LotID             int        `json:"lot_id" db:"lot_id"`
+	ItemID            int        `json:"item_id" db:"item_id"`
+	LotNumber         string     `json:"lot_number" db:"lot_number"`
+	PurchaseID        *int       `json:"purchase_id,omitempty" db:"purchase_id"`
+	QuantityReceived  int        `json:"quantity_received" db:"quantity_received"`
+	QuantityRemaining int        `json:"quantity_remaining" db:"quantity_remaining"`
+	ExpiryDate        *time.Time `json:"expiry_date,omitempty" db:"expiry_date"`
+	ReceivedAt        time.Time  `json:"received_at" db:"received_at"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string     `json:"item_part_number" db:"item_part_number"`
+	ItemDescription string     `json:"item_description" db:"item_description"`
+	SupplierName    *string    `json:"supplier_name,omitempty" db:"supplier_name"`
+	InvoiceNumber   *string    `json:"invoice_number,omitempty" db:"invoice_number"`
+	Sales           []*LotSale `json:"sales,omitempty" db:"-"`
+}
+
+// LotSale is a sale drawn from a lot, for recalls
+type LotSale struct {
+	SaleID            int       `json:"sale_id" db:"sale_id"`
+	Date              time.Time `json:"date" db:"date"`
+	TransactionNumber string    `json:"transaction_number" db:"transaction_number"`
+	Quantity          int       `json:"quantity" db:"quantity"`
+	Customer          *Customer `json:"customer,omitempty" db:"-"`
+}
+
+// Customer is who a tracked unit was sold to, as recorded on the sale
+type Customer struct {
+	CustomerID *int    `json:"customer_id,omitempty"`
+	Name       *string `json:"name,omitempty"`
+	Phone      *string `json:"phone,omitempty"`
+	Email      *string `json:"email,omitempty"`
+}
+
+type SerialFilter struct {
+	Status *string `query:"status"`
+}
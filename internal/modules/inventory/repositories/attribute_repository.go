@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+type AttributeRepository interface {
+	GetItemAttributes(ctx context.Context, itemID, categoryID int) ([]*inventorymodels.ItemAttribute, error)
+	SaveItemAttributes(ctx context.Context, itemID int, values []*inventorymodels.AttributeValue) error
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+type FitmentRepository interface {
+	GetVehicleCatalogue(ctx context.Context) ([]*inventorymodels.VehicleCatalogueEntry, error)
+	GetItemIDsByPartNumber(ctx context.Context, partNumbers []string) (map[string]int, error)
+	ApplyFitment(ctx context.Context, plan *inventorymodels.FitmentPlan, result *inventorymodels.FitmentImportResult, commit bool) error
+}
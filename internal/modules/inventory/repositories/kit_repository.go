@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+type KitRepository interface {
+	GetKitComponents(ctx context.Context, kitItemID int, warehouseID *int) ([]*inventorymodels.KitComponent, error)
+	GetKitAvailability(ctx context.Context, kitItemID int, warehouseID *int) (int, error)
+	SaveKit(ctx context.Context, kitItemID int, update *inventorymodels.KitUpdate) error
+	RemoveKit(ctx context.Context, kitItemID int) error
+}
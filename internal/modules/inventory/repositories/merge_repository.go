@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+// MergeRepository defines the interface for finding duplicate items and
+// merging them
+type MergeRepository interface {
+	GetDuplicateItems(ctx context.Context, threshold float64, categoryID *int) ([]*inventorymodels.DuplicateItem, error)
+	GetMergeConflicts(ctx context.Context, survivorID, duplicateID int) (*inventorymodels.ItemMergeConflicts, error)
+	MergeItems(ctx context.Context, survivorID int, request *inventorymodels.MergeRequest) (*inventorymodels.MergeRecord, error)
+	GetMergeHistory(ctx context.Context, itemID *int) ([]*inventorymodels.MergeRecord, error)
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+)
+
+type PostgresAttributeRepository struct {
+	db *db.Database
+}
+
+func NewPostgresAttributeRepository(database *db.Database) AttributeRepository {
+	return &PostgresAttributeRepository{
+		db: database,
+	}
+}
+
+// GetItemAttributes returns the attributes of the category and the
+// categories above it, the topmost category's first, with the item's values
+func (r *PostgresAttributeRepository) GetItemAttributes(ctx context.Context, itemID, categoryID int) ([]*inventorymodels.ItemAttribute, error) {
+	query := `
+		SELECT a.attribute_id, a.attribute_key, a.label, a.data_type, a.unit, a.allowed_values,
+			   a.min_value, a.max_value, a.is_required, a.category_id, c.category_name,
+			   v.value_text, v.value_number, v.value_boolean
+		FROM category_lineage($2) l
+		JOIN category_attributes a ON a.category_id = l.category_id
+		JOIN categories c ON c.category_id = a.category_id
+		LEFT JOIN item_attribute_values v ON v.attribute_id = a.attribute_id AND v.item_id = $1
+		ORDER BY l.depth DESC, a.sort_order, a.label
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, itemID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attributes []*inventorymodels.ItemAttribute
+	for rows.Next() {
+		attribute := &inventorymodels.ItemAttribute{}
+		var text *string
+		var number *float64
+		var boolean *bool
+		err := rows.Scan(
+			&attribute.AttributeID, &attribute.AttributeKey, &attribute.Label, &attribute.DataType,
+			&attribute.Unit, &attribute.AllowedValues, &attribute.MinValue, &attribute.MaxValue,
+			&attribute.IsRequired, &attribute.CategoryID, &attribute.CategoryName,
+			&text, &number, &boolean,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case text != nil:
+			attribute.Value = *text
+		case number != nil:
+			attribute.Value = *number
+		case boolean != nil:
+			attribute.Value = *boolean
+		}
+		attributes = append(attributes, attribute)
+	}
+
+	return attributes, rows.Err()
+}
+
+// SaveItemAttributes replaces all of the item's attribute values
+func (r *PostgresAttributeRepository) SaveItemAttributes(ctx context.Context, itemID int, values []*inventorymodels.AttributeValue) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM item_attribute_values WHERE item_id = $1`, itemID); err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO item_attribute_values (item_id, attribute_id, value_text, value_number, value_boolean)
+			VALUES ($1, $2, $3, $4, $5)
+		`, itemID, value.AttributeID, value.Text, value.Number, value.Boolean)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
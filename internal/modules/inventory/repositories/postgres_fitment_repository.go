@@ -0,0 +1,355 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// Compatibility rows are written this many at a time
+const fitmentBatchSize = 500
+
+type PostgresFitmentRepository struct {
+	db *db.Database
+}
+
+func NewPostgresFitmentRepository(database *db.Database) FitmentRepository {
+	return &PostgresFitmentRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresFitmentRepository) GetVehicleCatalogue(ctx context.Context) ([]*inventorymodels.VehicleCatalogueEntry, error) {
+	query := `
+		SELECT mk.make_id, mk.make_name, m.model_id, m.model_name,
+			   s.submodel_id, s.submodel_name, s.year_from, s.year_to
+		FROM vehicle_makes mk
+		LEFT JOIN vehicle_models m ON m.make_id = mk.make_id
+		LEFT JOIN vehicle_submodels s ON s.model_id = m.model_id
+		ORDER BY mk.make_id, m.model_id, s.submodel_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var catalogue []*inventorymodels.VehicleCatalogueEntry
+	for rows.Next() {
+		entry := &inventorymodels.VehicleCatalogueEntry{}
+		err := rows.Scan(
+			&entry.MakeID,
+			&entry.MakeName,
+			&entry.ModelID,
+			&entry.ModelName,
+			&entry.SubmodelID,
+			&entry.SubmodelName,
+			&entry.YearFrom,
+			&entry.YearTo,
+		)
+		if err != nil {
+			return nil, err
+		}
+		catalogue = append(catalogue, entry)
+	}
+
+	return catalogue, rows.Err()
+}
+
+func (r *PostgresFitmentRepository) GetItemIDsByPartNumber(ctx context.Context, partNumbers []string) (map[string]int, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT part_number, item_id FROM items WHERE part_number = ANY($1)
+	`, partNumbers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	itemIDs := make(map[string]int)
+	for rows.Next() {
+		var partNumber string
+		var itemID int
+		if err := rows.Scan(&partNumber, &itemID); err != nil {
+			return nil, err
+		}
+		itemIDs[partNumber] = itemID
+	}
+
+	return itemIDs, rows.Err()
+}
+
+// ApplyFitment writes an import plan in one transaction: vehicles the import
+// creates first, then removals, then additions in batches. Links already in
+// place are left alone and counted. Without commit the transaction is rolled
+// back, which gives a dry run exact counts.
+func (r *PostgresFitmentRepository) ApplyFitment(ctx context.Context, plan *inventorymodels.FitmentPlan, result *inventorymodels.FitmentImportResult, commit bool) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	vehicles := &fitmentVehicles{
+		tx:        tx,
+		result:    result,
+		makes:     make(map[string]int),
+		models:    make(map[string]int),
+		submodels: make(map[string]int),
+	}
+
+	for _, link := range plan.Add {
+		if link.Vehicle != nil {
+			link.SubmodelID, err = vehicles.submodelID(ctx, link.Vehicle)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Removals take out the one fitment with the same years, position and
+	// engine, leaving the item's other fitments to the submodel
+	removals := dedupeLinks(plan.Remove)
+	for start := 0; start < len(removals); start += fitmentBatchSize {
+		batch := removals[start:min(start+fitmentBatchSize, len(removals))]
+		columns := linkColumns(batch)
+
+		tag, err := tx.Exec(ctx, `
+			DELETE FROM compatibility c
+			USING unnest($1::int[], $2::int[], $3::int[], $4::int[], $5::text[], $6::text[])
+				AS d(item_id, submodel_id, year_from, year_to, position, engine_code)
+			WHERE c.item_id = d.item_id AND c.submodel_id = d.submodel_id
+				AND c.year_from IS NOT DISTINCT FROM d.year_from
+				AND c.year_to IS NOT DISTINCT FROM d.year_to
+				AND c.position IS NOT DISTINCT FROM d.position
+				AND c.engine_code IS NOT DISTINCT FROM d.engine_code
+		`, columns.itemIDs, columns.submodelIDs, columns.yearsFrom, columns.yearsTo, columns.positions, columns.engineCodes)
+		if err != nil {
+			return err
+		}
+		result.Removed += int(tag.RowsAffected())
+	}
+
+	// A fitment already on file keeps its row and takes any new notes
+	additions := dedupeLinks(plan.Add)
+	for start := 0; start < len(additions); start += fitmentBatchSize {
+		batch := additions[start:min(start+fitmentBatchSize, len(additions))]
+		columns := linkColumns(batch)
+
+		rows, err := tx.Query(ctx, `
+			INSERT INTO compatibility (
+				item_id, submodel_id, year_from, year_to, position, engine_code, notes
+			)
+			SELECT * FROM unnest(
+				$1::int[], $2::int[], $3::int[], $4::int[], $5::text[], $6::text[], $7::text[]
+			)
+			ON CONFLICT (
+				item_id, submodel_id, (COALESCE(year_from, 0)), (COALESCE(year_to, 0)),
+				(COALESCE(position, '')), (COALESCE(engine_code, ''))
+			) DO UPDATE SET notes = COALESCE(EXCLUDED.notes, compatibility.notes)
+			RETURNING xmax = 0
+		`, columns.itemIDs, columns.submodelIDs, columns.yearsFrom, columns.yearsTo, columns.positions, columns.engineCodes, columns.notes)
+		if err != nil {
+			return err
+		}
+		inserted, err := pgx.CollectRows(rows, pgx.RowTo[bool])
+		if err != nil {
+			return err
+		}
+		for _, added := range inserted {
+			if added {
+				result.Added++
+			} else {
+				result.AlreadyLinked++
+			}
+		}
+	}
+
+	if !commit {
+		return nil
+	}
+	return tx.Commit(ctx)
+}
+
+// Helper functions
+
+// fitmentVehicles finds or creates the makes, models and submodels an import
+// names, remembering each so it is only looked up once
+type fitmentVehicles struct {
+	tx        pgx.Tx
+	result    *inventorymodels.FitmentImportResult
+	makes     map[string]int
+	models    map[string]int
+	submodels map[string]int
+}
+
+func (v *fitmentVehicles) submodelID(ctx context.Context, vehicle *inventorymodels.FitmentVehicle) (int, error) {
+	key := strings.ToLower(fmt.Sprintf("%s|%s|%s|%d", vehicle.Make, vehicle.Model, vehicle.Submodel, vehicle.YearFrom))
+	if id, ok := v.submodels[key]; ok {
+		return id, nil
+	}
+
+	modelID, err := v.modelID(ctx, vehicle)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = v.tx.QueryRow(ctx, `
+		SELECT submodel_id FROM vehicle_submodels
+		WHERE model_id = $1 AND LOWER(submodel_name) = LOWER($2) AND year_from = $3
+	`, modelID, vehicle.Submodel, vehicle.YearFrom).Scan(&id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Engine and body details are left blank for the catalogue to fill in
+		err = v.tx.QueryRow(ctx, `
+			INSERT INTO vehicle_submodels (
+				model_id, submodel_name, year_from, year_to, engine_type,
+				engine_displacement, fuel_type, transmission_type, body_type
+			) VALUES ($1, $2, $3, $4, '', 0, '', '', '')
+			RETURNING submodel_id
+		`, modelID, vehicle.Submodel, vehicle.YearFrom, vehicle.YearTo).Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+		v.result.SubmodelsCreated++
+	}
+
+	v.submodels[key] = id
+	return id, nil
+}
+
+func (v *fitmentVehicles) modelID(ctx context.Context, vehicle *inventorymodels.FitmentVehicle) (int, error) {
+	key := strings.ToLower(vehicle.Make + "|" + vehicle.Model)
+	if id, ok := v.models[key]; ok {
+		return id, nil
+	}
+
+	makeID, err := v.makeID(ctx, vehicle.Make)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = v.tx.QueryRow(ctx, `
+		SELECT model_id FROM vehicle_models WHERE make_id = $1 AND LOWER(model_name) = LOWER($2)
+	`, makeID, vehicle.Model).Scan(&id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = v.tx.QueryRow(ctx, `
+			INSERT INTO vehicle_models (make_id, model_name) VALUES ($1, $2) RETURNING model_id
+		`, makeID, vehicle.Model).Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+		v.result.ModelsCreated++
+	}
+
+	v.models[key] = id
+	return id, nil
+}
+
+func (v *fitmentVehicles) makeID(ctx context.Context, name string) (int, error) {
+	key := strings.ToLower(name)
+	if id, ok := v.makes[key]; ok {
+		return id, nil
+	}
+
+	var id int
+	err := v.tx.QueryRow(ctx, `
+		SELECT make_id FROM vehicle_makes WHERE LOWER(make_name) = LOWER($1)
+	`, name).Scan(&id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = v.tx.QueryRow(ctx, `
+			INSERT INTO vehicle_makes (make_name, country) VALUES ($1, '') RETURNING make_id
+		`, name).Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+		v.result.MakesCreated++
+	}
+
+	v.makes[key] = id
+	return id, nil
+}
+
+// dedupeLinks keeps the first link for each fitment: item, submodel, years,
+// position and engine code, as the unique_item_fitment index has them
+func dedupeLinks(links []*inventorymodels.FitmentLink) []*inventorymodels.FitmentLink {
+	type fitment struct {
+		itemID, submodelID, yearFrom, yearTo int
+		position, engineCode                 string
+	}
+
+	seen := make(map[fitment]bool)
+	var unique []*inventorymodels.FitmentLink
+	for _, link := range links {
+		key := fitment{itemID: link.ItemID, submodelID: link.SubmodelID}
+		if link.YearFrom != nil {
+			key.yearFrom = *link.YearFrom
+		}
+		if link.YearTo != nil {
+			key.yearTo = *link.YearTo
+		}
+		if link.Position != nil {
+			key.position = *link.Position
+		}
+		if link.EngineCode != nil {
+			key.engineCode = *link.EngineCode
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, link)
+	}
+	return unique
+}
+
+// fitmentColumns holds a batch of links column by column, for unnest
+type fitmentColumns struct {
+	itemIDs     []int
+	submodelIDs []int
+	yearsFrom   []*int
+	yearsTo     []*int
+	positions   []*string
+	engineCodes []*string
+	notes       []*string
+}
+
+func linkColumns(links []*inventorymodels.FitmentLink) *fitmentColumns {
+	columns := &fitmentColumns{
+		itemIDs:     make([]int, len(links)),
+		submodelIDs: make([]int, len(links)),
+		yearsFrom:   make([]*int, len(links)),
+		yearsTo:     make([]*int, len(links)),
+		positions:   make([]*string, len(links)),
+		engineCodes: make([]*string, len(links)),
+		notes:       make([]*string, len(links)),
+	}
+	for i, link := range links {
+		columns.itemIDs[i] = link.ItemID
+		columns.submodelIDs[i] = link.SubmodelID
+		columns.yearsFrom[i] = link.YearFrom
+		columns.yearsTo[i] = link.YearTo
+		columns.positions[i] = link.Position
+		columns.engineCodes[i] = link.EngineCode
+		columns.notes[i] = link.Notes
+	}
+	return columns
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+)
+
+type PostgresKitRepository struct {
+	db *db.Database
+}
+
+func NewPostgresKitRepository(database *db.Database) KitRepository {
+	return &PostgresKitRepository{
+		db: database,
+	}
+}
+
+// GetKitComponents returns the kit's components with their stock free to
+// sell at the warehouse, or across all warehouses when none is given
+func (r *PostgresKitRepository) GetKitComponents(ctx context.Context, kitItemID int, warehouseID *int) ([]*inventorymodels.KitComponent, error) {
+	query := `
+		SELECT kc.component_item_id, i.part_number, i.description, kc.quantity,
+			   i.sell_price, i.is_active, available_stock(i.item_id, $2::int)
+		FROM kit_components kc
+		JOIN items i ON i.item_id = kc.component_item_id
+		WHERE kc.kit_item_id = $1
+		ORDER BY i.part_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, kitItemID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var components []*inventorymodels.KitComponent
+	for rows.Next() {
+		component := &inventorymodels.KitComponent{}
+		err := rows.Scan(
+			&component.ItemID, &component.PartNumber, &component.Description, &component.Quantity,
+			&component.SellPrice, &component.IsActive, &component.AvailableStock,
+		)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+
+	return components, rows.Err()
+}
+
+// GetKitAvailability returns how many complete kits the free component stock
+// makes up at the warehouse, or across all warehouses when none is given
+func (r *PostgresKitRepository) GetKitAvailability(ctx context.Context, kitItemID int, warehouseID *int) (int, error) {
+	var available int
+	err := r.db.Pool.QueryRow(ctx, `SELECT kit_available_stock($1, $2::int)`, kitItemID, warehouseID).Scan(&available)
+	if err != nil {
+		return 0, err
+	}
+
+	return available, nil
+}
+
+// SaveKit marks the item as a kit, replaces its components and brings its
+// prices in line with them
+func (r *PostgresKitRepository) SaveKit(ctx context.Context, kitItemID int, update *inventorymodels.KitUpdate) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE items
+		SET is_kit = TRUE, kit_pricing = $2, kit_discount_percent = $3,
+			sell_price = COALESCE($4, sell_price)
+		WHERE item_id = $1
+	`, kitItemID, update.Pricing, update.DiscountPercent, update.SellPrice)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("item not found")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM kit_components WHERE kit_item_id = $1`, kitItemID); err != nil {
+		return err
+	}
+
+	// Kits are not forecast, their demand counts towards the components
+	if _, err := tx.Exec(ctx, `DELETE FROM item_forecasts WHERE item_id = $1`, kitItemID); err != nil {
+		return err
+	}
+
+	componentIDs := make([]int, len(update.Components))
+	quantities := make([]int, len(update.Components))
+	for i, component := range update.Components {
+		componentIDs[i] = component.ItemID
+		quantities[i] = component.Quantity
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO kit_components (kit_item_id, component_item_id, quantity)
+		SELECT $1, component_item_id, quantity
+		FROM unnest($2::int[], $3::int[]) AS c(component_item_id, quantity)
+	`, kitItemID, componentIDs, quantities)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT refresh_kit_prices($1)`, kitItemID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RemoveKit turns a kit back into an ordinary item without components. Its
+// prices are left as they were.
+func (r *PostgresKitRepository) RemoveKit(ctx context.Context, kitItemID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM kit_components WHERE kit_item_id = $1`, kitItemID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE items
+		SET is_kit = FALSE, kit_pricing = NULL, kit_discount_percent = NULL
+		WHERE item_id = $1 AND is_kit
+	`, kitItemID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("kit not found")
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,342 @@
+package repositories
+
+import (
+	"context"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+)
+
+type PostgresMergeRepository struct {
+	db *db.Database
+}
+
+func NewPostgresMergeRepository(database *db.Database) MergeRepository {
+	return &PostgresMergeRepository{
+		db: database,
+	}
+}
+
+// GetDuplicateItems pairs items whose part numbers are the same apart from
+// spacing and punctuation, or whose part numbers and descriptions are both
+// at least as alike as the threshold. Kits, which cannot be merged, and
+// items linked by a supersession are left out.
+func (r *PostgresMergeRepository) GetDuplicateItems(ctx context.Context, threshold float64, categoryID *int) ([]*inventorymodels.DuplicateItem, error) {
+	query := `
+		SELECT item_id, part_number, description, duplicate_id, duplicate_part_number, duplicate_description,
+			   CASE WHEN same_part THEN 'part_number' ELSE 'similar' END,
+			   CASE WHEN same_part THEN 1 ELSE (part_score + description_score) / 2 END AS score
+		FROM (
+			SELECT a.item_id, a.part_number, a.description,
+				   b.item_id AS duplicate_id, b.part_number AS duplicate_part_number, b.description AS duplicate_description,
+				   normalize_code(a.part_number) = normalize_code(b.part_number) AS same_part,
+				   similarity(normalize_code(a.part_number), normalize_code(b.part_number)) AS part_score,
+				   name_similarity(a.description, b.description) AS description_score
+			FROM items a
+			JOIN items b ON b.item_id > a.item_id AND NOT b.is_kit
+			WHERE NOT a.is_kit
+				AND ($2::int IS NULL OR (a.category_id = $2 AND b.category_id = $2))
+				AND NOT EXISTS (
+					SELECT 1 FROM item_supersessions s
+					WHERE (s.old_item_id = a.item_id AND s.new_item_id = b.item_id)
+					   OR (s.old_item_id = b.item_id AND s.new_item_id = a.item_id)
+				)
+		) pairs
+		WHERE same_part OR (part_score >= $1 AND description_score >= $1)
+		ORDER BY score DESC, part_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, threshold, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var duplicates []*inventorymodels.DuplicateItem
+	for rows.Next() {
+		duplicate := &inventorymodels.DuplicateItem{}
+		err := rows.Scan(
+			&duplicate.ItemID, &duplicate.PartNumber, &duplicate.Description,
+			&duplicate.DuplicateID, &duplicate.DuplicatePartNumber, &duplicate.DuplicateDescription,
+			&duplicate.Reason, &duplicate.Similarity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+
+	return duplicates, rows.Err()
+}
+
+func (r *PostgresMergeRepository) GetMergeConflicts(ctx context.Context, survivorID, duplicateID int) (*inventorymodels.ItemMergeConflicts, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM purchase_order_lines s
+			 JOIN purchase_order_lines d ON d.purchase_order_id = s.purchase_order_id AND d.item_id = $2
+			 WHERE s.item_id = $1)::int,
+			(SELECT COUNT(*) FROM stock_transfer_lines s
+			 JOIN stock_transfer_lines d ON d.transfer_id = s.transfer_id AND d.item_id = $2
+			 WHERE s.item_id = $1)::int,
+			(SELECT COUNT(*) FROM item_serials s
+			 JOIN item_serials d ON d.serial_number = s.serial_number AND d.item_id = $2
+			 WHERE s.item_id = $1)::int,
+			(SELECT COUNT(*) FROM core_supplier_return_lines s
+			 JOIN core_supplier_return_lines d ON d.core_supplier_return_id = s.core_supplier_return_id AND d.item_id = $2
+			 WHERE s.item_id = $1)::int,
+			EXISTS (SELECT 1 FROM supersession_chain($1) c WHERE c.new_item_id = $2)
+				OR EXISTS (SELECT 1 FROM supersession_chain($2) c WHERE c.new_item_id = $1)
+	`
+
+	conflicts := &inventorymodels.ItemMergeConflicts{}
+	err := r.db.Pool.QueryRow(ctx, query, survivorID, duplicateID).Scan(
+		&conflicts.PurchaseOrders, &conflicts.StockTransfers, &conflicts.SerialNumbers,
+		&conflicts.CoreReturns, &conflicts.SupersessionChain,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+// MergeItems merges the duplicate item into the survivor in one transaction:
+//   - every order, purchase, sale, cost layer, movement, reservation, tracked
+//     unit, claim and core return of the duplicate moves to the survivor.
+//     Sales keep the warranty and core deposit they were made with.
+//   - the duplicate's stock is booked into the survivor's at each warehouse
+//     and the survivor's average cost becomes the weighted average of both.
+//   - fitment and kit places the survivor already has are kept, the rest
+//     move over; in kits holding both items the quantities are added up.
+//   - the duplicate's supersessions pass to the survivor where it has none.
+//   - the duplicate's cost history and forecast, which only describe the
+//     duplicate, are deleted with it.
+func (r *PostgresMergeRepository) MergeItems(ctx context.Context, survivorID int, request *inventorymodels.MergeRequest) (*inventorymodels.MergeRecord, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	record := &inventorymodels.MergeRecord{
+		EntityType: "item",
+		SurvivorID: survivorID,
+		MergedID:   request.DuplicateID,
+		Repointed:  map[string]int64{},
+		MergedBy:   request.MergedBy,
+		Notes:      request.Notes,
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('autoparts.merging', 'on', true)`); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `SELECT item_id FROM items WHERE item_id IN ($1, $2) ORDER BY item_id FOR UPDATE`, survivorID, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The duplicate as it was before its stock moved
+	var merged []byte
+	err = tx.QueryRow(ctx, `SELECT d.part_number, to_jsonb(d) FROM items d WHERE d.item_id = $1`, request.DuplicateID).
+		Scan(&record.MergedName, &merged)
+	if err != nil {
+		return nil, err
+	}
+
+	// Average cost over the stock of both, and details the survivor is missing
+	_, err = tx.Exec(ctx, `
+		UPDATE items s
+		SET average_cost = CASE
+				WHEN s.current_stock + d.current_stock > 0 THEN
+					(COALESCE(s.average_cost, s.buy_price) * s.current_stock +
+					 COALESCE(d.average_cost, d.buy_price) * d.current_stock) / (s.current_stock + d.current_stock)
+				ELSE s.average_cost
+			END,
+			category_id = COALESCE(s.category_id, d.category_id),
+			supplier_id = COALESCE(s.supplier_id, d.supplier_id),
+			weight_kg = COALESCE(s.weight_kg, d.weight_kg),
+			dimensions_cm = COALESCE(s.dimensions_cm, d.dimensions_cm),
+			image_url = COALESCE(s.image_url, d.image_url)
+		FROM items d
+		WHERE s.item_id = $1 AND d.item_id = $2
+	`, survivorID, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []struct {
+		table string
+		query string
+	}{
+		// Transfer lines first, so the stock totals below count the
+		// duplicate's stock in transit
+		{"stock_transfer_lines", `UPDATE stock_transfer_lines SET item_id = $1 WHERE item_id = $2`},
+		{"purchase_order_lines", `UPDATE purchase_order_lines SET item_id = $1 WHERE item_id = $2`},
+		{"purchases", `UPDATE purchases SET item_id = $1 WHERE item_id = $2`},
+		{"sales", `UPDATE sales SET item_id = $1 WHERE item_id = $2`},
+		{"cost_layers", `UPDATE cost_layers SET item_id = $1 WHERE item_id = $2`},
+		{"cost_layer_consumptions", `UPDATE cost_layer_consumptions SET item_id = $1 WHERE item_id = $2`},
+		{"stock_movements", `UPDATE stock_movements SET item_id = $1 WHERE item_id = $2`},
+		{"stock_reservations", `UPDATE stock_reservations SET item_id = $1 WHERE item_id = $2`},
+		{"special_orders", `UPDATE special_orders SET item_id = $1 WHERE item_id = $2`},
+		{"item_serials", `UPDATE item_serials SET item_id = $1 WHERE item_id = $2`},
+		{"item_lots", `UPDATE item_lots SET item_id = $1 WHERE item_id = $2`},
+		{"warranty_claims", `UPDATE warranty_claims SET item_id = $1 WHERE item_id = $2`},
+		{"core_returns", `UPDATE core_returns SET item_id = $1 WHERE item_id = $2`},
+		{"core_supplier_return_lines", `UPDATE core_supplier_return_lines SET item_id = $1 WHERE item_id = $2`},
+		{"compatibility_dropped", `
+			DELETE FROM compatibility d
+			WHERE d.item_id = $2
+				AND EXISTS (
+					SELECT 1 FROM compatibility s
+					WHERE s.item_id = $1 AND s.submodel_id = d.submodel_id
+						AND s.year_from IS NOT DISTINCT FROM d.year_from AND s.year_to IS NOT DISTINCT FROM d.year_to
+						AND s.position IS NOT DISTINCT FROM d.position AND s.engine_code IS NOT DISTINCT FROM d.engine_code
+				)
+		`},
+		{"compatibility", `UPDATE compatibility SET item_id = $1 WHERE item_id = $2`},
+		{"item_attribute_values", `
+			UPDATE item_attribute_values d SET item_id = $1
+			WHERE d.item_id = $2
+				AND NOT EXISTS (SELECT 1 FROM item_attribute_values s WHERE s.item_id = $1 AND s.attribute_id = d.attribute_id)
+				AND d.attribute_id IN (
+					SELECT a.attribute_id
+					FROM items i
+					CROSS JOIN LATERAL category_lineage(i.category_id) l
+					JOIN category_attributes a ON a.category_id = l.category_id
+					WHERE i.item_id = $1
+				)
+		`},
+		{"kit_components_combined", `
+			UPDATE kit_components s
+			SET quantity = s.quantity + d.quantity
+			FROM kit_components d
+			WHERE s.component_item_id = $1 AND d.component_item_id = $2 AND d.kit_item_id = s.kit_item_id
+		`},
+		{"kit_components_dropped", `
+			DELETE FROM kit_components d
+			WHERE d.component_item_id = $2
+				AND EXISTS (SELECT 1 FROM kit_components s WHERE s.component_item_id = $1 AND s.kit_item_id = d.kit_item_id)
+		`},
+		{"kit_components", `UPDATE kit_components SET component_item_id = $1 WHERE component_item_id = $2`},
+		{"item_supersessions", `UPDATE item_supersessions SET new_item_id = $1 WHERE new_item_id = $2`},
+		{"item_supersessions", `
+			UPDATE item_supersessions SET old_item_id = $1
+			WHERE old_item_id = $2
+				AND NOT EXISTS (SELECT 1 FROM item_supersessions WHERE old_item_id = $1)
+		`},
+		{"item_forecasts_dropped", `DELETE FROM item_forecasts WHERE item_id = $2`},
+		{"item_stock", `
+			INSERT INTO item_stock (item_id, warehouse_id, bin_id)
+			SELECT $1, warehouse_id, bin_id FROM item_stock WHERE item_id = $2
+			ON CONFLICT (item_id, warehouse_id) DO UPDATE
+			SET bin_id = COALESCE(item_stock.bin_id, EXCLUDED.bin_id)
+		`},
+	}
+	for _, statement := range statements {
+		result, err := tx.Exec(ctx, statement.query, survivorID, request.DuplicateID)
+		if err != nil {
+			return nil, err
+		}
+		if n := result.RowsAffected(); n > 0 {
+			record.Repointed[statement.table] += n
+		}
+	}
+
+	// Add the duplicate's stock to the survivor's locations, then empty the
+	// duplicate's. Its movements were repointed above, so the quantities
+	// change without a new movement. The touch on the survivor's rows brings
+	// its total in line when only stock in transit moved.
+	_, err = tx.Exec(ctx, `
+		UPDATE item_stock s
+		SET quantity = s.quantity + d.quantity
+		FROM item_stock d
+		WHERE s.item_id = $1 AND d.item_id = $2
+			AND d.warehouse_id = s.warehouse_id AND d.quantity <> 0
+	`, survivorID, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM item_stock WHERE item_id = $1`, request.DuplicateID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE item_stock SET quantity = quantity WHERE item_id = $1`, survivorID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO item_cost_history (item_id, effective_at, quantity_on_hand, average_cost, source)
+		SELECT item_id, CURRENT_TIMESTAMP, current_stock, COALESCE(average_cost, buy_price), 'adjustment'
+		FROM items
+		WHERE item_id = $1
+	`, survivorID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		SELECT refresh_kit_prices(kit_item_id)
+		FROM kit_components
+		WHERE component_item_id = $1
+	`, survivorID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO merge_audit (
+			entity_type, survivor_id, merged_id, merged_name, merged_record, repointed, merged_by, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING merge_id, merged_at
+	`,
+		record.EntityType, record.SurvivorID, record.MergedID, record.MergedName, merged,
+		record.Repointed, record.MergedBy, record.Notes,
+	).Scan(&record.MergeID, &record.MergedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM items WHERE item_id = $1`, request.DuplicateID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetMergeHistory returns past item merges, newest first, optionally those
+// an item took part in
+func (r *PostgresMergeRepository) GetMergeHistory(ctx context.Context, itemID *int) ([]*inventorymodels.MergeRecord, error) {
+	query := `
+		SELECT merge_id, entity_type, survivor_id, merged_id, merged_name, repointed,
+			   merged_by, notes, merged_at
+		FROM merge_audit
+		WHERE entity_type = 'item'
+			AND ($1::int IS NULL OR survivor_id = $1 OR merged_id = $1)
+		ORDER BY merged_at DESC, merge_id DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*inventorymodels.MergeRecord
+	for rows.Next() {
+		record := &inventorymodels.MergeRecord{}
+		err := rows.Scan(
+			&record.MergeID, &record.EntityType, &record.SurvivorID, &record.MergedID, &record.MergedName,
+			&record.Repointed, &record.MergedBy, &record.Notes, &record.MergedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
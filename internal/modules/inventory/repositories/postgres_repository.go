@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
 	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
 	"github.com/hsrvms/autoparts/pkg/db"
@@ -20,15 +21,50 @@ func NewPostgresInventoryRepository(database *db.Database) InventoryRepository {
 	}
 }
 
+const itemColumns = `
+	i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
+	i.sell_price, i.current_stock, i.minimum_stock, i.reorder_up_to, i.barcode,
+	i.supplier_id, i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
+	i.dimensions_cm, i.warranty_period, i.warranty_length, i.warranty_unit,
+	i.image_url, i.is_active, i.notes,
+	i.is_clearance, i.tracking_mode, i.core_charge, i.average_cost, i.created_at, i.updated_at,
+	i.is_kit, i.kit_pricing, i.kit_discount_percent,
+	(SELECT ss.new_item_id FROM item_supersessions ss WHERE ss.old_item_id = i.item_id) as superseded_by,
+	c.category_name, s.name as supplier_name,
+	available_stock(i.item_id) as available_stock
+`
+
+// supersededMatchQuery selects the current parts for superseded items whose
+// part number matches the pattern in the numbered parameter
+const supersededMatchQuery = `
+	SELECT current_item_id(ss.old_item_id)
+	FROM item_supersessions ss
+	JOIN items o ON o.item_id = ss.old_item_id
+	WHERE o.part_number ILIKE $%d
+`
+
+func scanItem(row pgx.Row) (*inventorymodels.Item, error) {
+	item := &inventorymodels.Item{}
+	err := row.Scan(
+		&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
+		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
+		&item.ReorderUpTo, &item.Barcode, &item.SupplierID, &item.LocationAisle,
+		&item.LocationShelf, &item.LocationBin, &item.WeightKg, &item.DimensionsCm,
+		&item.WarrantyPeriod, &item.WarrantyLength, &item.WarrantyUnit,
+		&item.ImageURL, &item.IsActive, &item.Notes,
+		&item.IsClearance, &item.TrackingMode, &item.CoreCharge, &item.AverageCost, &item.CreatedAt, &item.UpdatedAt,
+		&item.IsKit, &item.KitPricing, &item.KitDiscountPercent, &item.SupersededBy,
+		&item.CategoryName, &item.SupplierName, &item.AvailableStock,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
 func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inventorymodels.ItemFilter) ([]*inventorymodels.Item, error) {
 	query := `
-		SELECT
-			i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-			i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
-			i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
-			i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
-			i.created_at, i.updated_at,
-			c.category_name, s.name as supplier_name
+		SELECT ` + itemColumns + `
 		FROM items i
 		LEFT JOIN categories c ON i.category_id = c.category_id
 		LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
@@ -52,20 +88,21 @@ func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inve
 			paramCount++
 		}
 
+		// Part numbers also find the current part for superseded numbers
 		if filter.PartNumber != nil {
-			query += fmt.Sprintf(" AND i.part_number ILIKE $%d", paramCount)
+			query += fmt.Sprintf(" AND (i.part_number ILIKE $%d OR i.item_id IN ("+supersededMatchQuery+"))", paramCount, paramCount)
 			params = append(params, "%"+*filter.PartNumber+"%")
 			paramCount++
 		}
 
 		if filter.SearchTerm != nil {
-			query += fmt.Sprintf(" AND (i.part_number ILIKE $%d OR i.description ILIKE $%d)", paramCount, paramCount)
+			query += fmt.Sprintf(" AND (i.part_number ILIKE $%d OR i.description ILIKE $%d OR i.item_id IN ("+supersededMatchQuery+"))", paramCount, paramCount, paramCount)
 			params = append(params, "%"+*filter.SearchTerm+"%")
 			paramCount++
 		}
 
 		if filter.LowStock != nil && *filter.LowStock {
-			query += " AND i.current_stock <= i.minimum_stock"
+			query += " AND i.current_stock <= i.minimum_stock AND NOT i.is_kit"
 		}
 
 		if filter.IsActive != nil {
@@ -73,6 +110,60 @@ func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inve
 			params = append(params, *filter.IsActive)
 			paramCount++
 		}
+
+		if filter.IsClearance != nil {
+			query += fmt.Sprintf(" AND i.is_clearance = $%d", paramCount)
+			params = append(params, *filter.IsClearance)
+			paramCount++
+		}
+
+		if filter.IsKit != nil {
+			query += fmt.Sprintf(" AND i.is_kit = $%d", paramCount)
+			params = append(params, *filter.IsKit)
+			paramCount++
+		}
+
+		if filter.WarehouseID != nil {
+			query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM item_stock st WHERE st.item_id = i.item_id AND st.warehouse_id = $%d AND st.quantity > 0)", paramCount)
+			params = append(params, *filter.WarehouseID)
+			paramCount++
+		}
+
+		for _, attribute := range filter.Attributes {
+			query += fmt.Sprintf(`
+				AND EXISTS (
+					SELECT 1 FROM item_attribute_values av
+					JOIN category_attributes ca ON ca.attribute_id = av.attribute_id
+					WHERE av.item_id = i.item_id AND ca.attribute_key = $%d`, paramCount)
+			params = append(params, attribute.Key)
+			paramCount++
+
+			if attribute.Value != nil {
+				query += fmt.Sprintf(" AND (lower(av.value_text) = lower($%d) OR av.value_boolean::text = lower($%d)", paramCount, paramCount)
+				params = append(params, *attribute.Value)
+				paramCount++
+
+				if number, err := strconv.ParseFloat(*attribute.Value, 64); err == nil {
+					query += fmt.Sprintf(" OR av.value_number = $%d", paramCount)
+					params = append(params, number)
+					paramCount++
+				}
+				query += ")"
+			}
+
+			if attribute.Min != nil {
+				query += fmt.Sprintf(" AND av.value_number >= $%d", paramCount)
+				params = append(params, *attribute.Min)
+				paramCount++
+			}
+
+			if attribute.Max != nil {
+				query += fmt.Sprintf(" AND av.value_number <= $%d", paramCount)
+				params = append(params, *attribute.Max)
+				paramCount++
+			}
+			query += ")"
+		}
 	}
 
 	query += " ORDER BY i.part_number"
@@ -85,15 +176,7 @@ func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inve
 
 	var items []*inventorymodels.Item
 	for rows.Next() {
-		item := &inventorymodels.Item{}
-		err := rows.Scan(
-			&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-			&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-			&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-			&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-			&item.CategoryName, &item.SupplierName,
-		)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -105,28 +188,14 @@ func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inve
 
 func (r *PostgresInventoryRepository) GetItemByID(ctx context.Context, id int) (*inventorymodels.Item, error) {
 	query := `
-		SELECT
-			i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-			i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
-			i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
-			i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
-			i.created_at, i.updated_at,
-			c.category_name, s.name as supplier_name
+		SELECT ` + itemColumns + `
 		FROM items i
 		LEFT JOIN categories c ON i.category_id = c.category_id
 		LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
 		WHERE i.item_id = $1
 	`
 
-	item := &inventorymodels.Item{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-		&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-		&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-		&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-		&item.CategoryName, &item.SupplierName,
-	)
+	item, err := scanItem(r.db.Pool.QueryRow(ctx, query, id))
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -140,28 +209,14 @@ func (r *PostgresInventoryRepository) GetItemByID(ctx context.Context, id int) (
 
 func (r *PostgresInventoryRepository) GetItemByPartNumber(ctx context.Context, partNumber string) (*inventorymodels.Item, error) {
 	query := `
-		SELECT
-			i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-			i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
-			i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
-			i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
-			i.created_at, i.updated_at,
-			c.category_name, s.name as supplier_name
+		SELECT ` + itemColumns + `
 		FROM items i
 		LEFT JOIN categories c ON i.category_id = c.category_id
 		LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
 		WHERE i.part_number = $1
 	`
 
-	item := &inventorymodels.Item{}
-	err := r.db.Pool.QueryRow(ctx, query, partNumber).Scan(
-		&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-		&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-		&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-		&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-		&item.CategoryName, &item.SupplierName,
-	)
+	item, err := scanItem(r.db.Pool.QueryRow(ctx, query, partNumber))
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -175,28 +230,14 @@ func (r *PostgresInventoryRepository) GetItemByPartNumber(ctx context.Context, p
 
 func (r *PostgresInventoryRepository) GetItemByBarcode(ctx context.Context, barcode string) (*inventorymodels.Item, error) {
 	query := `
-		SELECT
-			i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-			i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
-			i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
-			i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
-			i.created_at, i.updated_at,
-			c.category_name, s.name as supplier_name
+		SELECT ` + itemColumns + `
 		FROM items i
 		LEFT JOIN categories c ON i.category_id = c.category_id
 		LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
 		WHERE i.barcode = $1
 	`
 
-	item := &inventorymodels.Item{}
-	err := r.db.Pool.QueryRow(ctx, query, barcode).Scan(
-		&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-		&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-		&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-		&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-		&item.CategoryName, &item.SupplierName,
-	)
+	item, err := scanItem(r.db.Pool.QueryRow(ctx, query, barcode))
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -214,10 +255,12 @@ func (r *PostgresInventoryRepository) CreateItem(ctx context.Context, item *inve
 			part_number, description, category_id, buy_price, sell_price,
 			current_stock, minimum_stock, barcode, supplier_id, location_aisle,
 			location_shelf, location_bin, weight_kg, dimensions_cm,
-			warranty_period, image_url, is_active, notes
+			warranty_period, image_url, is_active, notes, reorder_up_to,
+			tracking_mode, warranty_length, warranty_unit, core_charge
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-			$11, $12, $13, $14, $15, $16, $17, $18
+			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			$21, $22, $23
 		)
 		RETURNING item_id
 	`
@@ -229,7 +272,8 @@ func (r *PostgresInventoryRepository) CreateItem(ctx context.Context, item *inve
 		item.SellPrice, item.CurrentStock, item.MinimumStock, item.Barcode,
 		item.SupplierID, item.LocationAisle, item.LocationShelf, item.LocationBin,
 		item.WeightKg, item.DimensionsCm, item.WarrantyPeriod, item.ImageURL,
-		item.IsActive, item.Notes,
+		item.IsActive, item.Notes, item.ReorderUpTo, item.TrackingMode,
+		item.WarrantyLength, item.WarrantyUnit, item.CoreCharge,
 	).Scan(&id)
 
 	if err != nil {
@@ -247,7 +291,9 @@ func (r *PostgresInventoryRepository) UpdateItem(ctx context.Context, item *inve
 			minimum_stock = $8, barcode = $9, supplier_id = $10,
 			location_aisle = $11, location_shelf = $12, location_bin = $13,
 			weight_kg = $14, dimensions_cm = $15, warranty_period = $16,
-			image_url = $17, is_active = $18, notes = $19
+			image_url = $17, is_active = $18, notes = $19,
+			reorder_up_to = $20, tracking_mode = $21,
+			warranty_length = $22, warranty_unit = $23, core_charge = $24
 		WHERE item_id = $1
 	`
 
@@ -257,7 +303,8 @@ func (r *PostgresInventoryRepository) UpdateItem(ctx context.Context, item *inve
 		item.BuyPrice, item.SellPrice, item.CurrentStock, item.MinimumStock,
 		item.Barcode, item.SupplierID, item.LocationAisle, item.LocationShelf,
 		item.LocationBin, item.WeightKg, item.DimensionsCm, item.WarrantyPeriod,
-		item.ImageURL, item.IsActive, item.Notes,
+		item.ImageURL, item.IsActive, item.Notes, item.ReorderUpTo,
+		item.TrackingMode, item.WarrantyLength, item.WarrantyUnit, item.CoreCharge,
 	)
 
 	if err != nil {
@@ -286,11 +333,35 @@ func (r *PostgresInventoryRepository) DeleteItem(ctx context.Context, id int) er
 	return nil
 }
 
+// GetCurrentItemID returns the item that currently replaces the given one,
+// following its supersessions; an item that has not been superseded is its
+// own current item
+func (r *PostgresInventoryRepository) GetCurrentItemID(ctx context.Context, itemID int) (int, error) {
+	var currentID int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT current_item_id($1)`, itemID).Scan(&currentID); err != nil {
+		return 0, err
+	}
+
+	return currentID, nil
+}
+
+// IsKitComponent reports whether the item goes into any kit
+func (r *PostgresInventoryRepository) IsKitComponent(ctx context.Context, itemID int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM kit_components WHERE component_item_id = $1)`
+
+	var exists bool
+	if err := r.db.Pool.QueryRow(ctx, query, itemID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
 func (r *PostgresInventoryRepository) GetCompatibilities(ctx context.Context, itemID int) ([]*inventorymodels.Compatibility, error) {
 	query := `
         SELECT
-            c.compat_id, c.item_id, c.submodel_id, c.notes, c.created_at,
-            m.model_name, mk.make_name, s.submodel_name
+            c.compat_id, c.item_id, c.submodel_id, c.notes, c.year_from, c.year_to,
+            c.position, c.engine_code, c.created_at, m.model_name, mk.make_name, s.submodel_name
         FROM compatibility c
         JOIN vehicle_submodels s ON c.submodel_id = s.submodel_id
         JOIN vehicle_models m ON s.model_id = m.model_id
@@ -313,6 +384,10 @@ func (r *PostgresInventoryRepository) GetCompatibilities(ctx context.Context, it
 			&compatibility.ItemID,
 			&compatibility.SubmodelID,
 			&compatibility.Notes,
+			&compatibility.YearFrom,
+			&compatibility.YearTo,
+			&compatibility.Position,
+			&compatibility.EngineCode,
 			&compatibility.CreatedAt,
 			&compatibility.ModelName,
 			&compatibility.MakeName,
@@ -329,8 +404,10 @@ func (r *PostgresInventoryRepository) GetCompatibilities(ctx context.Context, it
 
 func (r *PostgresInventoryRepository) AddCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) (int, error) {
 	query := `
-        INSERT INTO compatibility (item_id, submodel_id, notes)
-        VALUES ($1, $2, $3)
+        INSERT INTO compatibility (
+            item_id, submodel_id, notes, year_from, year_to, position, engine_code
+        )
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
         RETURNING compat_id
     `
 
@@ -340,6 +417,10 @@ func (r *PostgresInventoryRepository) AddCompatibility(ctx context.Context, comp
 		compatibility.ItemID,
 		compatibility.SubmodelID,
 		compatibility.Notes,
+		compatibility.YearFrom,
+		compatibility.YearTo,
+		compatibility.Position,
+		compatibility.EngineCode,
 	).Scan(&id)
 
 	if err != nil {
@@ -349,13 +430,39 @@ func (r *PostgresInventoryRepository) AddCompatibility(ctx context.Context, comp
 	return id, nil
 }
 
-func (r *PostgresInventoryRepository) RemoveCompatibility(ctx context.Context, itemID, submodelID int) error {
+func (r *PostgresInventoryRepository) UpdateCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) error {
+	query := `
+        UPDATE compatibility
+        SET notes = $3, year_from = $4, year_to = $5, position = $6, engine_code = $7
+        WHERE compat_id = $8 AND item_id = $1 AND submodel_id = $2
+        RETURNING compat_id, created_at
+    `
+
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		compatibility.ItemID,
+		compatibility.SubmodelID,
+		compatibility.Notes,
+		compatibility.YearFrom,
+		compatibility.YearTo,
+		compatibility.Position,
+		compatibility.EngineCode,
+		compatibility.CompatID,
+	).Scan(&compatibility.CompatID, &compatibility.CreatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("compatibility not found")
+	}
+	return err
+}
+
+func (r *PostgresInventoryRepository) RemoveCompatibility(ctx context.Context, itemID, submodelID int, compatID *int) error {
 	query := `
         DELETE FROM compatibility
-        WHERE item_id = $1 AND submodel_id = $2
+        WHERE item_id = $1 AND submodel_id = $2 AND ($3::int IS NULL OR compat_id = $3)
     `
 
-	result, err := r.db.Pool.Exec(ctx, query, itemID, submodelID)
+	result, err := r.db.Pool.Exec(ctx, query, itemID, submodelID, compatID)
 	if err != nil {
 		return err
 	}
@@ -367,24 +474,49 @@ func (r *PostgresInventoryRepository) RemoveCompatibility(ctx context.Context, i
 	return nil
 }
 
-func (r *PostgresInventoryRepository) GetCompatibleItems(ctx context.Context, submodelID int) ([]*inventorymodels.Item, error) {
+func (r *PostgresInventoryRepository) GetCompatibleItems(ctx context.Context, submodelID int, filter *inventorymodels.CompatibleItemFilter) ([]*inventorymodels.Item, error) {
 	query := `
-        SELECT
-            i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-            i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
-            i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
-            i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
-            i.created_at, i.updated_at,
-            c.category_name, s.name as supplier_name
+        SELECT ` + itemColumns + `
         FROM items i
         LEFT JOIN categories c ON i.category_id = c.category_id
         LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
-        JOIN compatibility comp ON i.item_id = comp.item_id
-        WHERE comp.submodel_id = $1 AND i.is_active = true
-        ORDER BY i.part_number
+        WHERE i.is_active = true AND EXISTS (
+            SELECT 1
+            FROM compatibility comp
+            JOIN vehicle_submodels vs ON comp.submodel_id = vs.submodel_id
+            WHERE comp.item_id = i.item_id AND comp.submodel_id = $1
     `
 
-	rows, err := r.db.Pool.Query(ctx, query, submodelID)
+	params := []interface{}{submodelID}
+	paramCount := 2
+
+	if filter.ModelYear != nil {
+		// Compatibilities without their own years take the submodel's
+		query += fmt.Sprintf(` AND COALESCE(comp.year_from, vs.year_from) <= $%d
+            AND COALESCE(comp.year_to, vs.year_to, $%d) >= $%d`, paramCount, paramCount, paramCount)
+		params = append(params, *filter.ModelYear)
+		paramCount++
+	}
+
+	if filter.Position != nil {
+		// front_left fits front and left, and a left part fits front_left
+		query += fmt.Sprintf(` AND (comp.position IS NULL
+            OR string_to_array(comp.position, '_') <@ string_to_array($%d, '_')
+            OR string_to_array(comp.position, '_') @> string_to_array($%d, '_'))`, paramCount, paramCount)
+		params = append(params, *filter.Position)
+		paramCount++
+	}
+
+	if filter.EngineCode != nil {
+		query += fmt.Sprintf(" AND (comp.engine_code IS NULL OR UPPER(comp.engine_code) = UPPER($%d))", paramCount)
+		params = append(params, *filter.EngineCode)
+		paramCount++
+	}
+
+	// An item with several fitments to the submodel is listed once
+	query += ") ORDER BY i.part_number"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
 	if err != nil {
 		return nil, err
 	}
@@ -392,15 +524,7 @@ func (r *PostgresInventoryRepository) GetCompatibleItems(ctx context.Context, su
 
 	var items []*inventorymodels.Item
 	for rows.Next() {
-		item := &inventorymodels.Item{}
-		err := rows.Scan(
-			&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-			&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-			&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-			&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-			&item.CategoryName, &item.SupplierName,
-		)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -410,19 +534,31 @@ func (r *PostgresInventoryRepository) GetCompatibleItems(ctx context.Context, su
 	return items, rows.Err()
 }
 
+// GetSubmodelYears returns the years a submodel was built, or nils when
+// there is no such submodel
+func (r *PostgresInventoryRepository) GetSubmodelYears(ctx context.Context, submodelID int) (*int, *int, error) {
+	var yearFrom int
+	var yearTo *int
+	err := r.db.Pool.QueryRow(ctx, `
+        SELECT year_from, year_to FROM vehicle_submodels WHERE submodel_id = $1
+    `, submodelID).Scan(&yearFrom, &yearTo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	return &yearFrom, yearTo, nil
+}
+
 func (r *PostgresInventoryRepository) GetLowStockItems(ctx context.Context) ([]*inventorymodels.Item, error) {
 	query := `
-        SELECT
-            i.item_id, i.part_number, i.description, i.category_id, i.buy_price,
-            i.sell_price, i.current_stock, i.minimum_stock, i.barcode, i.supplier_id,
-            i.location_aisle, i.location_shelf, i.location_bin, i.weight_kg,
-            i.dimensions_cm, i.warranty_period, i.image_url, i.is_active, i.notes,
-            i.created_at, i.updated_at,
-            c.category_name, s.name as supplier_name
+        SELECT ` + itemColumns + `
         FROM items i
         LEFT JOIN categories c ON i.category_id = c.category_id
         LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
-        WHERE i.current_stock <= i.minimum_stock AND i.is_active = true
+        WHERE i.current_stock <= i.minimum_stock AND i.is_active = true AND NOT i.is_kit
         ORDER BY i.current_stock ASC, i.part_number
     `
 
@@ -434,15 +570,7 @@ func (r *PostgresInventoryRepository) GetLowStockItems(ctx context.Context) ([]*
 
 	var items []*inventorymodels.Item
 	for rows.Next() {
-		item := &inventorymodels.Item{}
-		err := rows.Scan(
-			&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-			&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-			&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-			&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-			&item.CategoryName, &item.SupplierName,
-		)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -451,3 +579,38 @@ func (r *PostgresInventoryRepository) GetLowStockItems(ctx context.Context) ([]*
 
 	return items, rows.Err()
 }
+
+// GetStockLevels returns the item's stock at each warehouse that has held it
+func (r *PostgresInventoryRepository) GetStockLevels(ctx context.Context, itemID int) ([]*inventorymodels.StockLevel, error) {
+	query := `
+		SELECT warehouse_id, warehouse_code, warehouse_name, bin_id, bin_code, quantity
+		FROM item_stock_levels
+		WHERE item_id = $1
+		ORDER BY warehouse_code
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var levels []*inventorymodels.StockLevel
+	for rows.Next() {
+		level := &inventorymodels.StockLevel{}
+		err := rows.Scan(
+			&level.WarehouseID,
+			&level.WarehouseCode,
+			&level.WarehouseName,
+			&level.BinID,
+			&level.BinCode,
+			&level.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+
+	return levels, rows.Err()
+}
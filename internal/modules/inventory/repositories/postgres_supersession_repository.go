@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresSupersessionRepository struct {
+	db *db.Database
+}
+
+func NewPostgresSupersessionRepository(database *db.Database) SupersessionRepository {
+	return &PostgresSupersessionRepository{
+		db: database,
+	}
+}
+
+const supersessionColumns = `
+	s.supersession_id, s.old_item_id, s.new_item_id, s.superseded_on,
+	s.is_direct_interchange, s.notes, s.created_at,
+	o.part_number, o.description, n.part_number, n.description
+`
+
+func scanSupersession(row pgx.Row) (*inventorymodels.Supersession, error) {
+	supersession := &inventorymodels.Supersession{}
+	err := row.Scan(
+		&supersession.SupersessionID, &supersession.OldItemID, &supersession.NewItemID, &supersession.SupersededOn,
+		&supersession.IsDirectInterchange, &supersession.Notes, &supersession.CreatedAt,
+		&supersession.OldPartNumber, &supersession.OldDescription, &supersession.NewPartNumber, &supersession.NewDescription,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return supersession, nil
+}
+
+// GetSupersession returns the supersession that replaced the item, or nil if
+// it has not been superseded
+func (r *PostgresSupersessionRepository) GetSupersession(ctx context.Context, oldItemID int) (*inventorymodels.Supersession, error) {
+	query := `
+		SELECT ` + supersessionColumns + `
+		FROM item_supersessions s
+		JOIN items o ON o.item_id = s.old_item_id
+		JOIN items n ON n.item_id = s.new_item_id
+		WHERE s.old_item_id = $1
+	`
+
+	supersession, err := scanSupersession(r.db.Pool.QueryRow(ctx, query, oldItemID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return supersession, nil
+}
+
+// GetSupersessionHistory returns the supersessions leading to the item and
+// on from it, oldest first
+func (r *PostgresSupersessionRepository) GetSupersessionHistory(ctx context.Context, itemID int) ([]*inventorymodels.Supersession, error) {
+	query := `
+		WITH RECURSIVE predecessors AS (
+			SELECT s.supersession_id, s.old_item_id, 1 AS depth
+			FROM item_supersessions s
+			WHERE s.new_item_id = $1
+			UNION ALL
+			SELECT s.supersession_id, s.old_item_id, p.depth + 1
+			FROM predecessors p
+			JOIN item_supersessions s ON s.new_item_id = p.old_item_id
+			WHERE p.depth < 50
+		),
+		links AS (
+			SELECT supersession_id FROM predecessors
+			UNION
+			SELECT supersession_id FROM supersession_chain($1)
+		)
+		SELECT ` + supersessionColumns + `
+		FROM links l
+		JOIN item_supersessions s ON s.supersession_id = l.supersession_id
+		JOIN items o ON o.item_id = s.old_item_id
+		JOIN items n ON n.item_id = s.new_item_id
+		ORDER BY s.superseded_on, s.supersession_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var supersessions []*inventorymodels.Supersession
+	for rows.Next() {
+		supersession, err := scanSupersession(rows)
+		if err != nil {
+			return nil, err
+		}
+		supersessions = append(supersessions, supersession)
+	}
+
+	return supersessions, rows.Err()
+}
+
+func (r *PostgresSupersessionRepository) CreateSupersession(ctx context.Context, supersession *inventorymodels.Supersession) (int, error) {
+	query := `
+		INSERT INTO item_supersessions (old_item_id, new_item_id, superseded_on, is_direct_interchange, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING supersession_id, created_at
+	`
+
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		supersession.OldItemID, supersession.NewItemID, supersession.SupersededOn,
+		supersession.IsDirectInterchange, supersession.Notes,
+	).Scan(&supersession.SupersessionID, &supersession.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return supersession.SupersessionID, nil
+}
+
+func (r *PostgresSupersessionRepository) DeleteSupersession(ctx context.Context, oldItemID int) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM item_supersessions WHERE old_item_id = $1`, oldItemID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("supersession not found")
+	}
+
+	return nil
+}
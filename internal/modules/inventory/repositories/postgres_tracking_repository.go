@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresTrackingRepository struct {
+	db *db.Database
+}
+
+func NewPostgresTrackingRepository(database *db.Database) TrackingRepository {
+	return &PostgresTrackingRepository{
+		db: database,
+	}
+}
+
+const serialQuery = `
+	SELECT
+		sn.serial_id, sn.item_id, sn.serial_number, sn.status, sn.purchase_id,
+		sn.warehouse_id, sn.sale_id, sn.received_at, sn.sold_at,
+		i.part_number, i.description, w.code, su.name, p.invoice_number,
+		s.transaction_number, s.customer_id, COALESCE(cu.name, s.customer_name),
+		COALESCE(s.customer_phone, cu.phone), COALESCE(s.customer_email, cu.email)
+	FROM item_serials sn
+	JOIN items i ON sn.item_id = i.item_id
+	LEFT JOIN warehouses w ON sn.warehouse_id = w.warehouse_id
+	LEFT JOIN purchases p ON sn.purchase_id = p.purchase_id
+	LEFT JOIN suppliers su ON p.supplier_id = su.supplier_id
+	LEFT JOIN sales s ON sn.sale_id = s.sale_id
+	LEFT JOIN customers cu ON s.customer_id = cu.customer_id
+`
+
+const lotQuery = `
+	SELECT
+		l.lot_id, l.item_id, l.lot_number, l.purchase_id, l.quantity_received,
+		l.quantity_remaining, l.expiry_date, l.received_at,
+		i.part_number, i.description, su.name, p.invoice_number
+	FROM item_lots l
+	JOIN items i ON l.item_id = i.item_id
+	LEFT JOIN purchases p ON l.purchase_id = p.purchase_id
+	LEFT JOIN suppliers su ON p.supplier_id = su.supplier_id
+`
+
+func (r *PostgresTrackingRepository) GetItemSerials(ctx context.Context, itemID int, filter *inventorymodels.SerialFilter) ([]*inventorymodels.SerialUnit, error) {
+	query := serialQuery + " WHERE sn.item_id = $1"
+	params := []interface{}{itemID}
+
+	if filter.Status != nil {
+		query += " AND sn.status = $2"
+		params = append(params, *filter.Status)
+	}
+
+	query += " ORDER BY sn.received_at, sn.serial_number"
+
+	return r.querySerials(ctx, query, params...)
+}
+
+func (r *PostgresTrackingRepository) GetItemLots(ctx context.Context, itemID int) ([]*inventorymodels.Lot, error) {
+	return r.queryLots(ctx, lotQuery+" WHERE l.item_id = $1 ORDER BY l.received_at, l.lot_id", itemID)
+}
+
+// FindSerials looks a serial number up across all items, as the same number
+// may be used by different manufacturers
+func (r *PostgresTrackingRepository) FindSerials(ctx context.Context, serialNumber string) ([]*inventorymodels.SerialUnit, error) {
+	return r.querySerials(ctx, serialQuery+" WHERE sn.serial_number = $1 ORDER BY i.part_number", serialNumber)
+}
+
+func (r *PostgresTrackingRepository) FindLots(ctx context.Context, lotNumber string) ([]*inventorymodels.Lot, error) {
+	return r.queryLots(ctx, lotQuery+" WHERE l.lot_number = $1 ORDER BY i.part_number, l.received_at", lotNumber)
+}
+
+func (r *PostgresTrackingRepository) GetLotSales(ctx context.Context, lotID int) ([]*inventorymodels.LotSale, error) {
+	query := `
+		SELECT
+			s.sale_id, s.date, s.transaction_number, sl.quantity,
+			s.customer_id, COALESCE(cu.name, s.customer_name),
+			COALESCE(s.customer_phone, cu.phone), COALESCE(s.customer_email, cu.email)
+		FROM sale_lots sl
+		JOIN sales s ON sl.sale_id = s.sale_id
+		LEFT JOIN customers cu ON s.customer_id = cu.customer_id
+		WHERE sl.lot_id = $1
+		ORDER BY s.date, s.sale_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, lotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []*inventorymodels.LotSale
+	for rows.Next() {
+		sale := &inventorymodels.LotSale{Customer: &inventorymodels.Customer{}}
+		err := rows.Scan(
+			&sale.SaleID,
+			&sale.Date,
+			&sale.TransactionNumber,
+			&sale.Quantity,
+			&sale.Customer.CustomerID,
+			&sale.Customer.Name,
+			&sale.Customer.Phone,
+			&sale.Customer.Email,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, sale)
+	}
+
+	return sales, rows.Err()
+}
+
+// Helper functions
+
+func (r *PostgresTrackingRepository) querySerials(ctx context.Context, query string, params ...interface{}) ([]*inventorymodels.SerialUnit, error) {
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var serials []*inventorymodels.SerialUnit
+	for rows.Next() {
+		serial, err := scanSerial(rows)
+		if err != nil {
+			return nil, err
+		}
+		serials = append(serials, serial)
+	}
+
+	return serials, rows.Err()
+}
+
+func scanSerial(row pgx.Row) (*inventorymodels.SerialUnit, error) {
+	serial := &inventorymodels.SerialUnit{}
+	customer := &inventorymodels.Customer{}
+	err := row.Scan(
+		&serial.SerialID,
+		&serial.ItemID,
+		&serial.SerialNumber,
+		&serial.Status,
+		&serial.PurchaseID,
+		&serial.WarehouseID,
+		&serial.SaleID,
+		&serial.ReceivedAt,
+		&serial.SoldAt,
+		&serial.ItemPartNumber,
+		&serial.ItemDescription,
+		&serial.WarehouseCode,
+		&serial.SupplierName,
+		&serial.InvoiceNumber,
+		&serial.TransactionNumber,
+		&customer.CustomerID,
+		&customer.Name,
+		&customer.Phone,
+		&customer.Email,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if serial.SaleID != nil {
+		serial.Customer = customer
+	}
+	return serial, nil
+}
+
+func (r *PostgresTrackingRepository) queryLots(ctx context.Context, query string, params ...interface{}) ([]*inventorymodels.Lot, error) {
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []*inventorymodels.Lot
+	for rows.Next() {
+		lot := &inventorymodels.Lot{}
+		err := rows.Scan(
+			&lot.LotID,
+			&lot.ItemID,
+			&lot.LotNumber,
+			&lot.PurchaseID,
+			&lot.QuantityReceived,
+			&lot.QuantityRemaining,
+			&lot.ExpiryDate,
+			&lot.ReceivedAt,
+			&lot.ItemPartNumber,
+			&lot.ItemDescription,
+			&lot.SupplierName,
+			&lot.InvoiceNumber,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	return lots, rows.Err()
+}
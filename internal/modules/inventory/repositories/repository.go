@@ -16,10 +16,15 @@ type InventoryRepository interface {
 	UpdateItem(ctx context.Context, item *inventorymodels.Item) error
 	DeleteItem(ctx context.Context, id int) error
 	GetLowStockItems(ctx context.Context) ([]*inventorymodels.Item, error)
+	GetStockLevels(ctx context.Context, itemID int) ([]*inventorymodels.StockLevel, error)
+	IsKitComponent(ctx context.Context, itemID int) (bool, error)
+	GetCurrentItemID(ctx context.Context, itemID int) (int, error)
 
 	// Compatibility operations
 	GetCompatibilities(ctx context.Context, itemID int) ([]*inventorymodels.Compatibility, error)
 	AddCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) (int, error)
-	RemoveCompatibility(ctx context.Context, itemID, submodelID int) error
-	GetCompatibleItems(ctx context.Context, submodelID int) ([]*inventorymodels.Item, error)
+	UpdateCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) error
+	RemoveCompatibility(ctx context.Context, itemID, submodelID int, compatID *int) error
+	GetCompatibleItems(ctx context.Context, submodelID int, filter *inventorymodels.CompatibleItemFilter) ([]*inventorymodels.Item, error)
+	GetSubmodelYears(ctx context.Context, submodelID int) (yearFrom *int, yearTo *int, err error)
 }
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+type SupersessionRepository interface {
+	GetSupersession(ctx context.Context, oldItemID int) (*inventorymodels.Supersession, error)
+	GetSupersessionHistory(ctx context.Context, itemID int) ([]*inventorymodels.Supersession, error)
+	CreateSupersession(ctx context.Context, supersession *inventorymodels.Supersession) (int, error)
+	DeleteSupersession(ctx context.Context, oldItemID int) error
+}
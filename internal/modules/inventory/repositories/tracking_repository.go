@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+type TrackingRepository interface {
+	GetItemSerials(ctx context.Context, itemID int, filter *inventorymodels.SerialFilter) ([]*inventorymodels.SerialUnit, error)
+	GetItemLots(ctx context.Context, itemID int) ([]*inventorymodels.Lot, error)
+	FindSerials(ctx context.Context, serialNumber string) ([]*inventorymodels.SerialUnit, error)
+	FindLots(ctx context.Context, lotNumber string) ([]*inventorymodels.Lot, error)
+	GetLotSales(ctx context.Context, lotID int) ([]*inventorymodels.LotSale, error)
+}
@@ -11,12 +11,30 @@ import (
 func RegisterRoutes(api *echo.Group, database *db.Database) {
 	// Initialize repository
 	repo := repositories.NewPostgresInventoryRepository(database)
+	trackingRepo := repositories.NewPostgresTrackingRepository(database)
+	fitmentRepo := repositories.NewPostgresFitmentRepository(database)
+	kitRepo := repositories.NewPostgresKitRepository(database)
+	supersessionRepo := repositories.NewPostgresSupersessionRepository(database)
+	mergeRepo := repositories.NewPostgresMergeRepository(database)
+	attributeRepo := repositories.NewPostgresAttributeRepository(database)
 
 	// Initialize service
 	service := services.NewInventoryService(repo)
+	trackingService := services.NewTrackingService(trackingRepo, repo)
+	fitmentService := services.NewFitmentService(fitmentRepo)
+	kitService := services.NewKitService(kitRepo, repo)
+	supersessionService := services.NewSupersessionService(supersessionRepo, repo)
+	mergeService := services.NewMergeService(mergeRepo, repo)
+	attributeService := services.NewAttributeService(attributeRepo, repo)
 
 	// Initialize handler
 	handler := handlers.NewInventoryHandler(service)
+	trackingHandler := handlers.NewTrackingHandler(trackingService)
+	fitmentHandler := handlers.NewFitmentHandler(fitmentService)
+	kitHandler := handlers.NewKitHandler(kitService)
+	supersessionHandler := handlers.NewSupersessionHandler(supersessionService)
+	mergeHandler := handlers.NewMergeHandler(mergeService)
+	attributeHandler := handlers.NewAttributeHandler(attributeService)
 
 	// Item routes
 	items := api.Group("/items")
@@ -24,6 +42,7 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	items.GET("/low-stock", handler.GetLowStockItems)
 	items.GET("/:id", handler.GetItemByID)
 	items.GET("/barcode/:barcode", handler.GetItemByBarcode)
+	items.GET("/part-number/:partNumber", handler.GetItemByPartNumber)
 	items.POST("", handler.CreateItem)
 	items.PUT("/:id", handler.UpdateItem)
 	items.DELETE("/:id", handler.DeleteItem)
@@ -32,6 +51,33 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	// Compatibility routes
 	items.GET("/:itemId/compatibilities", handler.GetCompatibilities)
 	items.POST("/:itemId/compatibilities", handler.AddCompatibility)
+	items.PUT("/:itemId/compatibilities/:submodelId", handler.UpdateCompatibility)
 	items.DELETE("/:itemId/compatibilities/:submodelId", handler.RemoveCompatibility)
 	api.GET("/submodels/:submodelId/compatible-items", handler.GetCompatibleItems)
+	api.POST("/compatibilities/import", fitmentHandler.ImportFitment)
+
+	// Kit routes
+	items.GET("/:id/kit", kitHandler.GetKit)
+	items.PUT("/:id/kit", kitHandler.SaveKit)
+	items.DELETE("/:id/kit", kitHandler.RemoveKit)
+
+	// Supersession routes
+	items.GET("/:id/supersessions", supersessionHandler.GetSupersessionHistory)
+	items.POST("/:id/supersessions", supersessionHandler.Supersede)
+	items.DELETE("/:id/supersessions", supersessionHandler.RemoveSupersession)
+
+	// Duplicate detection and merge routes
+	items.GET("/duplicates", mergeHandler.GetDuplicateItems)
+	items.GET("/merges", mergeHandler.GetItemMerges)
+	items.POST("/:id/merge", mergeHandler.MergeItems)
+
+	// Attribute routes
+	items.GET("/:id/attributes", attributeHandler.GetItemAttributes)
+	items.PUT("/:id/attributes", attributeHandler.SaveItemAttributes)
+
+	// Serial and lot tracking routes
+	items.GET("/:id/serials", trackingHandler.GetItemSerials)
+	items.GET("/:id/lots", trackingHandler.GetItemLots)
+	api.GET("/serials/:serialNumber", trackingHandler.FindSerial)
+	api.GET("/lots/:lotNumber", trackingHandler.TraceLot)
 }
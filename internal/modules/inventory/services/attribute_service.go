@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
+)
+
+var (
+	ErrItemHasNoCategory     = errors.New("item has no category and so no attributes")
+	ErrUnknownAttribute      = errors.New("attribute is not defined for the item's category")
+	ErrInvalidAttributeValue = errors.New("attribute value does not match its data type")
+	ErrAttributeOutOfRange   = errors.New("attribute value is outside the allowed range")
+	ErrAttributeNotAllowed   = errors.New("attribute value is not one of the allowed values")
+	ErrRequiredAttribute     = errors.New("required attributes have no value")
+)
+
+type AttributeService interface {
+	GetItemAttributes(ctx context.Context, itemID int) (*inventorymodels.ItemAttributes, error)
+	SaveItemAttributes(ctx context.Context, itemID int, update *inventorymodels.ItemAttributeUpdate) (*inventorymodels.ItemAttributes, error)
+}
+
+type attributeService struct {
+	repo     repositories.AttributeRepository
+	itemRepo repositories.InventoryRepository
+}
+
+func NewAttributeService(repo repositories.AttributeRepository, itemRepo repositories.InventoryRepository) AttributeService {
+	return &attributeService{
+		repo:     repo,
+		itemRepo: itemRepo,
+	}
+}
+
+func (s *attributeService) GetItemAttributes(ctx context.Context, itemID int) (*inventorymodels.ItemAttributes, error) {
+	item, err := s.getItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	return s.itemAttributes(ctx, item)
+}
+
+// SaveItemAttributes validates the values against the attributes of the
+// item's category and replaces the item's values with them. Errors name the
+// attribute at fault.
+func (s *attributeService) SaveItemAttributes(ctx context.Context, itemID int, update *inventorymodels.ItemAttributeUpdate) (*inventorymodels.ItemAttributes, error) {
+	item, err := s.getItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.CategoryID == nil {
+		for key, value := range update.Values {
+			if value != nil {
+				return nil, fmt.Errorf("%w: %s", ErrItemHasNoCategory, key)
+			}
+		}
+		return s.itemAttributes(ctx, item)
+	}
+
+	attributes, err := s.repo.GetItemAttributes(ctx, itemID, *item.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*inventorymodels.ItemAttribute, len(attributes))
+	for _, attribute := range attributes {
+		byKey[attribute.AttributeKey] = attribute
+	}
+
+	// Check keys in order so the same request always reports the same error
+	keys := make([]string, 0, len(update.Values))
+	for key := range update.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := []*inventorymodels.AttributeValue{}
+	set := make(map[string]bool)
+	for _, key := range keys {
+		attribute, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAttribute, key)
+		}
+
+		value, err := attributeValue(attribute, update.Values[key])
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			values = append(values, value)
+			set[key] = true
+		}
+	}
+
+	var missing []string
+	for _, attribute := range attributes {
+		if attribute.IsRequired && !set[attribute.AttributeKey] {
+			missing = append(missing, attribute.AttributeKey)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRequiredAttribute, strings.Join(missing, ", "))
+	}
+
+	if err := s.repo.SaveItemAttributes(ctx, itemID, values); err != nil {
+		return nil, err
+	}
+
+	return s.itemAttributes(ctx, item)
+}
+
+// Helper functions
+
+func (s *attributeService) getItem(ctx context.Context, itemID int) (*inventorymodels.Item, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	item, err := s.itemRepo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	return item, nil
+}
+
+func (s *attributeService) itemAttributes(ctx context.Context, item *inventorymodels.Item) (*inventorymodels.ItemAttributes, error) {
+	result := &inventorymodels.ItemAttributes{
+		ItemID:          item.ItemID,
+		CategoryID:      item.CategoryID,
+		Attributes:      []*inventorymodels.ItemAttribute{},
+		MissingRequired: []string{},
+	}
+	if item.CategoryID == nil {
+		return result, nil
+	}
+
+	attributes, err := s.repo.GetItemAttributes(ctx, item.ItemID, *item.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	for _, attribute := range attributes {
+		if attribute.IsRequired && attribute.Value == nil {
+			result.MissingRequired = append(result.MissingRequired, attribute.AttributeKey)
+		}
+	}
+	if attributes != nil {
+		result.Attributes = attributes
+	}
+	return result, nil
+}
+
+// attributeValue converts a value from the request into the field for the
+// attribute's data type. Null and blank text clear the value and give nil.
+func attributeValue(attribute *inventorymodels.ItemAttribute, raw interface{}) (*inventorymodels.AttributeValue, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	value := &inventorymodels.AttributeValue{AttributeID: attribute.AttributeID}
+	key := attribute.AttributeKey
+
+	switch attribute.DataType {
+	case inventorymodels.AttributeText, inventorymodels.AttributeEnum:
+		text, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAttributeValue, key)
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, nil
+		}
+
+		if attribute.DataType == inventorymodels.AttributeEnum {
+			allowed := ""
+			for _, option := range attribute.AllowedValues {
+				if strings.EqualFold(option, text) {
+					allowed = option
+					break
+				}
+			}
+			if allowed == "" {
+				return nil, fmt.Errorf("%w: %s must be one of %s", ErrAttributeNotAllowed, key, strings.Join(attribute.AllowedValues, ", "))
+			}
+			text = allowed
+		}
+		value.Text = &text
+
+	case inventorymodels.AttributeNumber:
+		var number float64
+		switch n := raw.(type) {
+		case float64:
+			number = n
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidAttributeValue, key)
+			}
+			number = parsed
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAttributeValue, key)
+		}
+
+		if (attribute.MinValue != nil && number < *attribute.MinValue) ||
+			(attribute.MaxValue != nil && number > *attribute.MaxValue) {
+			return nil, fmt.Errorf("%w: %s", ErrAttributeOutOfRange, key)
+		}
+		value.Number = &number
+
+	case inventorymodels.AttributeBoolean:
+		boolean, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAttributeValue, key)
+		}
+		value.Boolean = &boolean
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAttributeValue, key)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+// acesApp is one <App> of an ACES-style file. Vehicles are given by name;
+// files that only carry VCdb ids cannot be matched against our catalogue.
+type acesApp struct {
+	ID          string     `xml:"id,attr"`
+	Action      string     `xml:"action,attr"`
+	BaseVehicle *acesRef   `xml:"BaseVehicle"`
+	Make        string     `xml:"Make"`
+	Model       string     `xml:"Model"`
+	SubModel    string     `xml:"SubModel"`
+	Years       *acesYears `xml:"Years"`
+	Position    string     `xml:"Position"`
+	EngineCode  string     `xml:"EngineCode"`
+	Notes       []string   `xml:"Note"`
+	Part        string     `xml:"Part"`
+}
+
+type acesRef struct {
+	ID string `xml:"id,attr"`
+}
+
+type acesYears struct {
+	From string `xml:"from,attr"`
+	To   string `xml:"to,attr"`
+}
+
+// parsedFitment is a record read from a file, or the reason it could not be
+type parsedFitment struct {
+	record *inventorymodels.FitmentRecord
+	reason string
+}
+
+// parseACES reads the <App> elements of an ACES-style XML file one at a time
+func parseACES(r io.Reader) ([]*parsedFitment, error) {
+	decoder := xml.NewDecoder(r)
+
+	var parsed []*parsedFitment
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFitmentFile, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "App" {
+			continue
+		}
+
+		var app acesApp
+		if err := decoder.DecodeElement(&app, &start); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFitmentFile, err)
+		}
+		parsed = append(parsed, app.fitment(len(parsed)+1))
+	}
+
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("%w: no App elements found", ErrInvalidFitmentFile)
+	}
+	return parsed, nil
+}
+
+func (a *acesApp) fitment(position int) *parsedFitment {
+	record := &inventorymodels.FitmentRecord{
+		Ref:        strings.TrimSpace(a.ID),
+		PartNumber: strings.TrimSpace(a.Part),
+		Make:       strings.TrimSpace(a.Make),
+		Model:      strings.TrimSpace(a.Model),
+		Submodel:   strings.TrimSpace(a.SubModel),
+	}
+	if record.Ref == "" {
+		record.Ref = strconv.Itoa(position)
+	}
+
+	var notes []string
+	for _, note := range a.Notes {
+		if note = strings.TrimSpace(note); note != "" {
+			notes = append(notes, note)
+		}
+	}
+	if len(notes) > 0 {
+		joined := strings.Join(notes, "; ")
+		record.Notes = &joined
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(a.Action)) {
+	case "", "A":
+	case "D":
+		record.Delete = true
+	default:
+		return &parsedFitment{record: record, reason: "action must be A or D"}
+	}
+
+	if reason := setFitmentQualifiers(record, a.Position, a.EngineCode); reason != "" {
+		return &parsedFitment{record: record, reason: reason}
+	}
+
+	if record.Make == "" && a.BaseVehicle != nil {
+		return &parsedFitment{record: record, reason: "VCdb vehicle ids are not supported, give Make and Model by name"}
+	}
+
+	var from, to string
+	if a.Years != nil {
+		from, to = a.Years.From, a.Years.To
+	}
+	if reason := setFitmentYears(record, from, to); reason != "" {
+		return &parsedFitment{record: record, reason: reason}
+	}
+
+	return &parsedFitment{record: record, reason: requiredFitmentFields(record)}
+}
+
+// fitmentCSVColumns are the columns a fitment CSV may have, by header name
+var fitmentCSVColumns = []string{
+	"part_number", "make", "model", "submodel", "year_from", "year_to",
+	"position", "engine_code", "notes", "action",
+}
+
+// parseFitmentCSV reads a CSV with a header row. part_number, make, model and
+// year_from are required columns; the rest are optional.
+func parseFitmentCSV(r io.Reader) ([]*parsedFitment, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidFitmentFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFitmentFile, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"part_number", "make", "model", "year_from"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", ErrInvalidFitmentFile, required)
+		}
+	}
+
+	var parsed []*parsedFitment
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		record := &inventorymodels.FitmentRecord{Ref: strconv.Itoa(line)}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				parsed = append(parsed, &parsedFitment{record: record, reason: parseErr.Err.Error()})
+				continue
+			}
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFitmentFile, err)
+		}
+
+		fields := make(map[string]string)
+		blank := true
+		for _, name := range fitmentCSVColumns {
+			if i, ok := columns[name]; ok && i < len(row) {
+				fields[name] = strings.TrimSpace(row[i])
+				if fields[name] != "" {
+					blank = false
+				}
+			}
+		}
+		if blank {
+			continue
+		}
+
+		record.PartNumber = fields["part_number"]
+		record.Make = fields["make"]
+		record.Model = fields["model"]
+		record.Submodel = fields["submodel"]
+		if notes := fields["notes"]; notes != "" {
+			record.Notes = &notes
+		}
+
+		switch strings.ToUpper(fields["action"]) {
+		case "", "A", "ADD":
+		case "D", "DELETE":
+			record.Delete = true
+		default:
+			parsed = append(parsed, &parsedFitment{record: record, reason: "action must be A or D"})
+			continue
+		}
+
+		if reason := setFitmentYears(record, fields["year_from"], fields["year_to"]); reason != "" {
+			parsed = append(parsed, &parsedFitment{record: record, reason: reason})
+			continue
+		}
+
+		if reason := setFitmentQualifiers(record, fields["position"], fields["engine_code"]); reason != "" {
+			parsed = append(parsed, &parsedFitment{record: record, reason: reason})
+			continue
+		}
+
+		parsed = append(parsed, &parsedFitment{record: record, reason: requiredFitmentFields(record)})
+	}
+
+	return parsed, nil
+}
+
+// Helper functions
+func setFitmentYears(record *inventorymodels.FitmentRecord, from, to string) string {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" {
+		return "year from is required"
+	}
+
+	yearFrom, err := strconv.Atoi(from)
+	if err != nil || !validFitmentYear(yearFrom) {
+		return "invalid year from"
+	}
+	record.YearFrom = yearFrom
+
+	if to != "" {
+		yearTo, err := strconv.Atoi(to)
+		if err != nil || !validFitmentYear(yearTo) {
+			return "invalid year to"
+		}
+		if yearTo < yearFrom {
+			return "year to cannot be before year from"
+		}
+		record.YearTo = &yearTo
+	}
+	return ""
+}
+
+// setFitmentQualifiers reads a position, written as front_left, "Front Left"
+// or front-left, and an engine code
+func setFitmentQualifiers(record *inventorymodels.FitmentRecord, position, engineCode string) string {
+	position = strings.ToLower(strings.TrimSpace(position))
+	position = strings.NewReplacer(" ", "_", "-", "_").Replace(position)
+	if position != "" {
+		if !isValidPosition(position) {
+			return "invalid position"
+		}
+		record.Position = &position
+	}
+
+	if engineCode = strings.TrimSpace(engineCode); engineCode != "" {
+		record.EngineCode = &engineCode
+	}
+	return ""
+}
+
+func validFitmentYear(year int) bool {
+	return year >= 1900 && year <= 2100
+}
+
+func requiredFitmentFields(record *inventorymodels.FitmentRecord) string {
+	switch {
+	case record.PartNumber == "":
+		return "part number is required"
+	case record.Make == "":
+		return "make is required"
+	case record.Model == "":
+		return "model is required"
+	}
+	return ""
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
+)
+
+var (
+	ErrInvalidFitmentFormat = errors.New("format must be aces or csv")
+	ErrInvalidFitmentFile   = errors.New("invalid fitment file")
+)
+
+type FitmentService interface {
+	Import(ctx context.Context, r io.Reader, opts *inventorymodels.FitmentImportOptions) (*inventorymodels.FitmentImportResult, error)
+}
+
+type fitmentService struct {
+	repo repositories.FitmentRepository
+}
+
+func NewFitmentService(repo repositories.FitmentRepository) FitmentService {
+	return &fitmentService{
+		repo: repo,
+	}
+}
+
+// Import reads a fitment file, matches each record to the vehicle catalogue
+// and writes the compatibility rows. Records that cannot be matched are
+// reported and skipped; the rest are written together.
+func (s *fitmentService) Import(ctx context.Context, r io.Reader, opts *inventorymodels.FitmentImportOptions) (*inventorymodels.FitmentImportResult, error) {
+	var parsed []*parsedFitment
+	var err error
+	switch opts.Format {
+	case inventorymodels.FitmentFormatACES:
+		parsed, err = parseACES(r)
+	case inventorymodels.FitmentFormatCSV:
+		parsed, err = parseFitmentCSV(r)
+	default:
+		return nil, ErrInvalidFitmentFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &inventorymodels.FitmentImportResult{
+		Format:    opts.Format,
+		DryRun:    opts.DryRun,
+		Records:   len(parsed),
+		Unmatched: []*inventorymodels.UnmatchedFitment{},
+	}
+
+	catalogue, err := s.repo.GetVehicleCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matcher := newFitmentMatcher(catalogue, opts.CreateMissing)
+
+	var partNumbers []string
+	for _, p := range parsed {
+		if p.reason == "" {
+			partNumbers = append(partNumbers, p.record.PartNumber)
+		}
+	}
+	itemIDs, err := s.repo.GetItemIDsByPartNumber(ctx, partNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &inventorymodels.FitmentPlan{}
+	for _, p := range parsed {
+		reason := p.reason
+		if reason == "" {
+			reason = matcher.match(p.record, itemIDs, plan)
+		}
+		if reason != "" {
+			result.Unmatched = append(result.Unmatched, &inventorymodels.UnmatchedFitment{
+				FitmentRecord: p.record,
+				Reason:        reason,
+			})
+			continue
+		}
+		result.Matched++
+	}
+
+	if len(plan.Add) == 0 && len(plan.Remove) == 0 {
+		return result, nil
+	}
+
+	if err := s.repo.ApplyFitment(ctx, plan, result, !opts.DryRun); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Helper functions
+
+type fitmentSubmodel struct {
+	id       int
+	name     string
+	yearFrom int
+	yearTo   *int
+	vehicle  *inventorymodels.FitmentVehicle // Set when the import creates it
+}
+
+type fitmentModel struct {
+	name      string
+	submodels []*fitmentSubmodel
+}
+
+type fitmentMake struct {
+	name   string
+	models map[string]*fitmentModel
+}
+
+// fitmentMatcher matches records by name, ignoring case, against the vehicle
+// catalogue held in memory. Vehicles it creates are added to the catalogue
+// so later records for the same vehicle find them.
+type fitmentMatcher struct {
+	makes         map[string]*fitmentMake
+	createMissing bool
+}
+
+func newFitmentMatcher(catalogue []*inventorymodels.VehicleCatalogueEntry, createMissing bool) *fitmentMatcher {
+	m := &fitmentMatcher{
+		makes:         make(map[string]*fitmentMake),
+		createMissing: createMissing,
+	}
+
+	for _, entry := range catalogue {
+		vehicleMake := m.addMake(entry.MakeName)
+		if entry.ModelID == nil {
+			continue
+		}
+		model := vehicleMake.addModel(*entry.ModelName)
+		if entry.SubmodelID == nil {
+			continue
+		}
+		model.submodels = append(model.submodels, &fitmentSubmodel{
+			id:       *entry.SubmodelID,
+			name:     *entry.SubmodelName,
+			yearFrom: *entry.YearFrom,
+			yearTo:   entry.YearTo,
+		})
+	}
+
+	return m
+}
+
+func (m *fitmentMatcher) addMake(name string) *fitmentMake {
+	key := strings.ToLower(name)
+	if vehicleMake, ok := m.makes[key]; ok {
+		return vehicleMake
+	}
+	vehicleMake := &fitmentMake{name: name, models: make(map[string]*fitmentModel)}
+	m.makes[key] = vehicleMake
+	return vehicleMake
+}
+
+func (mk *fitmentMake) addModel(name string) *fitmentModel {
+	key := strings.ToLower(name)
+	if model, ok := mk.models[key]; ok {
+		return model
+	}
+	model := &fitmentModel{name: name}
+	mk.models[key] = model
+	return model
+}
+
+// match adds the links for a record to the plan, or says why it cannot
+func (m *fitmentMatcher) match(record *inventorymodels.FitmentRecord, itemIDs map[string]int, plan *inventorymodels.FitmentPlan) string {
+	itemID, ok := itemIDs[record.PartNumber]
+	if !ok {
+		return "unknown part number"
+	}
+
+	// Removals only ever apply to vehicles we already have
+	create := m.createMissing && !record.Delete && record.Submodel != ""
+
+	vehicleMake, ok := m.makes[strings.ToLower(record.Make)]
+	if !ok {
+		if !create {
+			return "unknown make"
+		}
+		vehicleMake = m.addMake(record.Make)
+	}
+
+	model, ok := vehicleMake.models[strings.ToLower(record.Model)]
+	if !ok {
+		if !create {
+			return "unknown model"
+		}
+		model = vehicleMake.addModel(record.Model)
+	}
+
+	var matches []*fitmentSubmodel
+	for _, submodel := range model.submodels {
+		if record.Submodel != "" && !strings.EqualFold(submodel.name, record.Submodel) {
+			continue
+		}
+		if yearsOverlap(submodel.yearFrom, submodel.yearTo, record.YearFrom, record.YearTo) {
+			matches = append(matches, submodel)
+		}
+	}
+
+	if len(matches) == 0 {
+		if !create {
+			return "no submodel in the year range"
+		}
+		submodel := &fitmentSubmodel{
+			name:     record.Submodel,
+			yearFrom: record.YearFrom,
+			yearTo:   record.YearTo,
+			vehicle: &inventorymodels.FitmentVehicle{
+				Make:     vehicleMake.name,
+				Model:    model.name,
+				Submodel: record.Submodel,
+				YearFrom: record.YearFrom,
+				YearTo:   record.YearTo,
+			},
+		}
+		model.submodels = append(model.submodels, submodel)
+		matches = append(matches, submodel)
+	}
+
+	for _, submodel := range matches {
+		link := &inventorymodels.FitmentLink{
+			ItemID:     itemID,
+			SubmodelID: submodel.id,
+			Vehicle:    submodel.vehicle,
+			Position:   record.Position,
+			EngineCode: record.EngineCode,
+			Notes:      record.Notes,
+		}
+		if record.YearFrom > submodel.yearFrom {
+			link.YearFrom = &record.YearFrom
+		}
+		if record.YearTo != nil && (submodel.yearTo == nil || *record.YearTo < *submodel.yearTo) {
+			link.YearTo = record.YearTo
+		}
+		if record.Delete {
+			plan.Remove = append(plan.Remove, link)
+		} else {
+			plan.Add = append(plan.Add, link)
+		}
+	}
+	return ""
+}
+
+func yearsOverlap(fromA int, toA *int, fromB int, toB *int) bool {
+	if toA != nil && *toA < fromB {
+		return false
+	}
+	if toB != nil && *toB < fromA {
+		return false
+	}
+	return true
+}
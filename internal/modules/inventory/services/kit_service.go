@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
+)
+
+var (
+	ErrNotKit               = errors.New("item is not a kit")
+	ErrInvalidKitPricing    = errors.New("kit pricing must be fixed or discount")
+	ErrInvalidKitDiscount   = errors.New("discount pricing needs a discount of at least 0 and below 100 percent")
+	ErrKitNeedsComponents   = errors.New("a kit needs at least one component")
+	ErrInvalidKitComponent  = errors.New("each kit component needs another item and a quantity greater than 0, listed once")
+	ErrKitComponentNotFound = errors.New("kit component not found")
+	ErrNestedKit            = errors.New("a kit cannot go into another kit")
+)
+
+type KitService interface {
+	GetKit(ctx context.Context, itemID int, warehouseID *int) (*inventorymodels.Kit, error)
+	SaveKit(ctx context.Context, itemID int, update *inventorymodels.KitUpdate) (*inventorymodels.Kit, error)
+	RemoveKit(ctx context.Context, itemID int) error
+}
+
+type kitService struct {
+	repo     repositories.KitRepository
+	itemRepo repositories.InventoryRepository
+}
+
+func NewKitService(repo repositories.KitRepository, itemRepo repositories.InventoryRepository) KitService {
+	return &kitService{
+		repo:     repo,
+		itemRepo: itemRepo,
+	}
+}
+
+// GetKit returns the kit with its components, its price and how many kits can
+// be made up at the warehouse, or across all warehouses when none is given
+func (s *kitService) GetKit(ctx context.Context, itemID int, warehouseID *int) (*inventorymodels.Kit, error) {
+	item, err := s.getItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if !item.IsKit {
+		return nil, ErrNotKit
+	}
+
+	components, err := s.repo.GetKitComponents(ctx, itemID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if components == nil {
+		components = []*inventorymodels.KitComponent{}
+	}
+
+	available, err := s.repo.GetKitAvailability(ctx, itemID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	kit := &inventorymodels.Kit{
+		ItemID:          item.ItemID,
+		PartNumber:      item.PartNumber,
+		Description:     item.Description,
+		DiscountPercent: item.KitDiscountPercent,
+		SellPrice:       item.SellPrice,
+		BuyPrice:        item.BuyPrice,
+		WarehouseID:     warehouseID,
+		AvailableStock:  available,
+		Components:      components,
+	}
+	if item.KitPricing != nil {
+		kit.Pricing = *item.KitPricing
+	}
+	for _, component := range components {
+		kit.ComponentsPrice += component.SellPrice * float64(component.Quantity)
+	}
+	kit.ComponentsPrice = roundCents(kit.ComponentsPrice)
+
+	return kit, nil
+}
+
+// SaveKit sets the item up as a kit of the given components, or changes an
+// existing kit. A stocked item can only become a kit once its stock is gone.
+func (s *kitService) SaveKit(ctx context.Context, itemID int, update *inventorymodels.KitUpdate) (*inventorymodels.Kit, error) {
+	item, err := s.getItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch update.Pricing {
+	case inventorymodels.KitPricingFixed:
+		update.DiscountPercent = nil
+		if update.SellPrice != nil && *update.SellPrice <= 0 {
+			return nil, ErrInvalidPrice
+		}
+	case inventorymodels.KitPricingDiscount:
+		update.SellPrice = nil
+		if update.DiscountPercent == nil || *update.DiscountPercent < 0 || *update.DiscountPercent >= 100 {
+			return nil, ErrInvalidKitDiscount
+		}
+	default:
+		return nil, ErrInvalidKitPricing
+	}
+
+	if item.TrackingMode != inventorymodels.TrackingNone {
+		return nil, ErrKitTracking
+	}
+	if !item.IsKit {
+		if item.CurrentStock > 0 {
+			return nil, ErrKitHoldsNoStock
+		}
+		isComponent, err := s.itemRepo.IsKitComponent(ctx, itemID)
+		if err != nil {
+			return nil, err
+		}
+		if isComponent {
+			return nil, ErrNestedKit
+		}
+	}
+
+	if err := s.validateComponents(ctx, itemID, update.Components); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveKit(ctx, itemID, update); err != nil {
+		return nil, err
+	}
+
+	return s.GetKit(ctx, itemID, nil)
+}
+
+// RemoveKit turns a kit back into an ordinary stocked item
+func (s *kitService) RemoveKit(ctx context.Context, itemID int) error {
+	item, err := s.getItem(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if !item.IsKit {
+		return ErrNotKit
+	}
+
+	return s.repo.RemoveKit(ctx, itemID)
+}
+
+// Helper functions
+
+func (s *kitService) getItem(ctx context.Context, itemID int) (*inventorymodels.Item, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	item, err := s.itemRepo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	return item, nil
+}
+
+// validateComponents checks each component is an untracked item that is not
+// itself a kit, since selling the kit takes plain units of each from stock
+func (s *kitService) validateComponents(ctx context.Context, kitItemID int, components []*inventorymodels.KitComponent) error {
+	if len(components) == 0 {
+		return ErrKitNeedsComponents
+	}
+
+	seen := make(map[int]bool, len(components))
+	for _, component := range components {
+		if component == nil || component.ItemID <= 0 || component.ItemID == kitItemID ||
+			component.Quantity <= 0 || seen[component.ItemID] {
+			return ErrInvalidKitComponent
+		}
+		seen[component.ItemID] = true
+
+		item, err := s.itemRepo.GetItemByID(ctx, component.ItemID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return ErrKitComponentNotFound
+		}
+		if item.IsKit {
+			return ErrNestedKit
+		}
+		if item.TrackingMode != inventorymodels.TrackingNone {
+			return ErrKitTracking
+		}
+	}
+	return nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
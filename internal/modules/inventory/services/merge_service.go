@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
+)
+
+var (
+	ErrInvalidMergeThreshold    = errors.New("threshold must be greater than 0 and at most 1")
+	ErrInvalidDuplicateID       = errors.New("invalid duplicate item ID")
+	ErrMergeIntoSelf            = errors.New("an item cannot be merged into itself")
+	ErrMergedItemNotFound       = errors.New("duplicate item not found")
+	ErrMergeKit                 = errors.New("kits cannot be merged")
+	ErrMergeTrackingMismatch    = errors.New("items with different tracking modes cannot be merged")
+	ErrMergeSupersession        = errors.New("one item supersedes the other; remove the supersession before merging")
+	ErrMergeSharedPurchaseOrder = errors.New("both items are on the same purchase order; combine the lines before merging")
+	ErrMergeSharedTransfer      = errors.New("both items are on the same stock transfer; combine the lines before merging")
+	ErrMergeSharedSerial        = errors.New("both items have units with the same serial number")
+	ErrMergeSharedCoreReturn    = errors.New("both items are on the same core return to a supplier")
+)
+
+// Similarity two items' part numbers and descriptions both need to be
+// reported as duplicates when no threshold is given
+const defaultDuplicateThreshold = 0.6
+
+type MergeService interface {
+	GetDuplicates(ctx context.Context, filter *inventorymodels.DuplicateItemFilter) ([]*inventorymodels.DuplicateItem, error)
+	MergeItems(ctx context.Context, survivorID int, request *inventorymodels.MergeRequest) (*inventorymodels.MergeRecord, error)
+	GetMergeHistory(ctx context.Context, itemID *int) ([]*inventorymodels.MergeRecord, error)
+}
+
+type mergeService struct {
+	repo     repositories.MergeRepository
+	itemRepo repositories.InventoryRepository
+}
+
+func NewMergeService(repo repositories.MergeRepository, itemRepo repositories.InventoryRepository) MergeService {
+	return &mergeService{
+		repo:     repo,
+		itemRepo: itemRepo,
+	}
+}
+
+func (s *mergeService) GetDuplicates(ctx context.Context, filter *inventorymodels.DuplicateItemFilter) ([]*inventorymodels.DuplicateItem, error) {
+	threshold := defaultDuplicateThreshold
+	if filter.Threshold != nil {
+		threshold = *filter.Threshold
+	}
+	if threshold <= 0 || threshold > 1 {
+		return nil, ErrInvalidMergeThreshold
+	}
+
+	duplicates, err := s.repo.GetDuplicateItems(ctx, threshold, filter.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if duplicates == nil {
+		duplicates = []*inventorymodels.DuplicateItem{}
+	}
+	return duplicates, nil
+}
+
+// MergeItems merges the duplicate item into the survivor. Both have to be
+// ordinary items tracked the same way, and the merge is refused where the
+// schema allows only one of them: the same purchase order, stock transfer,
+// core return or serial number.
+func (s *mergeService) MergeItems(ctx context.Context, survivorID int, request *inventorymodels.MergeRequest) (*inventorymodels.MergeRecord, error) {
+	survivor, err := s.getItem(ctx, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	if request.DuplicateID <= 0 {
+		return nil, ErrInvalidDuplicateID
+	}
+	if request.DuplicateID == survivorID {
+		return nil, ErrMergeIntoSelf
+	}
+	duplicate, err := s.itemRepo.GetItemByID(ctx, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate == nil {
+		return nil, ErrMergedItemNotFound
+	}
+
+	if survivor.IsKit || duplicate.IsKit {
+		return nil, ErrMergeKit
+	}
+	if survivor.TrackingMode != duplicate.TrackingMode {
+		return nil, ErrMergeTrackingMismatch
+	}
+
+	conflicts, err := s.repo.GetMergeConflicts(ctx, survivorID, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case conflicts.SupersessionChain:
+		return nil, ErrMergeSupersession
+	case conflicts.PurchaseOrders > 0:
+		return nil, ErrMergeSharedPurchaseOrder
+	case conflicts.StockTransfers > 0:
+		return nil, ErrMergeSharedTransfer
+	case conflicts.SerialNumbers > 0:
+		return nil, ErrMergeSharedSerial
+	case conflicts.CoreReturns > 0:
+		return nil, ErrMergeSharedCoreReturn
+	}
+
+	return s.repo.MergeItems(ctx, survivorID, request)
+}
+
+func (s *mergeService) GetMergeHistory(ctx context.Context, itemID *int) ([]*inventorymodels.MergeRecord, error) {
+	records, err := s.repo.GetMergeHistory(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*inventorymodels.MergeRecord{}
+	}
+	return records, nil
+}
+
+// Helper functions
+
+func (s *mergeService) getItem(ctx context.Context, itemID int) (*inventorymodels.Item, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	item, err := s.itemRepo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	return item, nil
+}
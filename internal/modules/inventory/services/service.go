@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
 	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
@@ -17,8 +18,20 @@ var (
 	ErrInvalidItemID       = errors.New("invalid item ID")
 	ErrInvalidSubmodelID   = errors.New("invalid submodel ID")
 	ErrCompatibilityExists = errors.New("compatibility already exists")
+	ErrCompatNotFound      = errors.New("compatibility not found")
+	ErrCompatAmbiguous     = errors.New("the item fits this submodel more than once; give the compat_id to change")
 	ErrInvalidPrice        = errors.New("price must be greater than 0")
 	ErrInvalidStock        = errors.New("stock cannot be negative")
+	ErrInvalidTrackingMode = errors.New("tracking mode must be none, serial or lot")
+	ErrTrackingModeLocked  = errors.New("tracking mode can only be changed while the item has no stock")
+	ErrInvalidWarranty     = errors.New("warranty needs a length greater than 0 and a unit of days, months or years")
+	ErrInvalidCoreCharge   = errors.New("core charge must be greater than 0")
+	ErrSubmodelNotFound    = errors.New("submodel not found")
+	ErrInvalidPosition     = errors.New("position must be front, rear, left, right, front_left, front_right, rear_left or rear_right")
+	ErrInvalidCompatYears  = errors.New("compatibility years must be in order and within the submodel's years")
+	ErrKitHoldsNoStock     = errors.New("a kit holds no stock of its own, stock its components instead")
+	ErrKitTracking         = errors.New("kits and kit components cannot be serial or lot tracked")
+	ErrTrackedStock        = errors.New("stock of a serial or lot tracked item only changes through receipts and sales")
 )
 
 type InventoryService interface {
@@ -35,8 +48,9 @@ type InventoryService interface {
 	// Compatibility operations
 	GetCompatibilities(ctx context.Context, itemID int) ([]*inventorymodels.Compatibility, error)
 	AddCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) (int, error)
-	RemoveCompatibility(ctx context.Context, itemID, submodelID int) error
-	GetCompatibleItems(ctx context.Context, submodelID int) ([]*inventorymodels.Item, error)
+	UpdateCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) error
+	RemoveCompatibility(ctx context.Context, itemID, submodelID int, compatID *int) error
+	GetCompatibleItems(ctx context.Context, submodelID int, filter *inventorymodels.CompatibleItemFilter) ([]*inventorymodels.Item, error)
 }
 
 type inventoryService struct {
@@ -69,6 +83,11 @@ func (s *inventoryService) GetItemByID(ctx context.Context, id int) (*inventorym
 		return nil, ErrItemNotFound
 	}
 
+	item.Locations, err = s.repo.GetStockLevels(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return item, nil
 }
 
@@ -77,7 +96,25 @@ func (s *inventoryService) GetItemByPartNumber(ctx context.Context, partNumber s
 		return nil, errors.New("part number is required")
 	}
 
-	return s.repo.GetItemByPartNumber(ctx, partNumber)
+	item, err := s.repo.GetItemByPartNumber(ctx, partNumber)
+	if err != nil || item == nil || item.SupersededBy == nil {
+		return item, err
+	}
+
+	// Superseded part numbers lead to the part that replaces them now
+	currentID, err := s.repo.GetCurrentItemID(ctx, item.ItemID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.GetItemByID(ctx, currentID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return item, nil
+	}
+	current.RedirectedFrom = &item.PartNumber
+	return current, nil
 }
 
 func (s *inventoryService) GetItemByBarcode(ctx context.Context, barcode string) (*inventorymodels.Item, error) {
@@ -89,6 +126,15 @@ func (s *inventoryService) GetItemByBarcode(ctx context.Context, barcode string)
 }
 
 func (s *inventoryService) CreateItem(ctx context.Context, item *inventorymodels.Item) (int, error) {
+	if item.TrackingMode == "" {
+		item.TrackingMode = inventorymodels.TrackingNone
+	}
+
+	// Tracked stock arrives on receipts that record its serials or lots
+	if item.TrackingMode != inventorymodels.TrackingNone && item.CurrentStock != 0 {
+		return 0, ErrTrackedStock
+	}
+
 	// Validate basic item fields
 	if err := s.validateItem(item); err != nil {
 		return 0, err
@@ -141,11 +187,6 @@ func (s *inventoryService) UpdateItem(ctx context.Context, item *inventorymodels
 		return ErrInvalidItemID
 	}
 
-	// Validate required fields
-	if err := s.validateItem(item); err != nil {
-		return err
-	}
-
 	// Check if item exists
 	existing, err := s.repo.GetItemByID(ctx, item.ItemID)
 	if err != nil {
@@ -155,6 +196,48 @@ func (s *inventoryService) UpdateItem(ctx context.Context, item *inventorymodels
 		return ErrItemNotFound
 	}
 
+	// Serials and lots are only recorded from the next receipt, so the mode
+	// cannot change while untracked stock would be left on hand
+	if item.TrackingMode == "" {
+		item.TrackingMode = existing.TrackingMode
+	}
+	if item.TrackingMode != existing.TrackingMode && existing.CurrentStock > 0 {
+		return ErrTrackingModeLocked
+	}
+
+	// Every unit of a tracked item on hand has its serial number or lot
+	if existing.TrackingMode != inventorymodels.TrackingNone && item.CurrentStock != existing.CurrentStock {
+		return ErrTrackedStock
+	}
+
+	// Selling a kit takes untracked units of its components, and a kit's
+	// prices follow its components unless it has a fixed sell price
+	if existing.IsKit {
+		if item.CurrentStock != 0 {
+			return ErrKitHoldsNoStock
+		}
+		if item.TrackingMode != inventorymodels.TrackingNone {
+			return ErrKitTracking
+		}
+		item.BuyPrice = existing.BuyPrice
+		if existing.KitPricing != nil && *existing.KitPricing == inventorymodels.KitPricingDiscount {
+			item.SellPrice = existing.SellPrice
+		}
+	} else if item.TrackingMode != inventorymodels.TrackingNone && item.TrackingMode != existing.TrackingMode {
+		isComponent, err := s.repo.IsKitComponent(ctx, item.ItemID)
+		if err != nil {
+			return err
+		}
+		if isComponent {
+			return ErrKitTracking
+		}
+	}
+
+	// Validate required fields
+	if err := s.validateItem(item); err != nil {
+		return err
+	}
+
 	// Check for duplicate part number if changed
 	if item.PartNumber != existing.PartNumber {
 		existingByPartNumber, err := s.repo.GetItemByPartNumber(ctx, item.PartNumber)
@@ -233,14 +316,19 @@ func (s *inventoryService) AddCompatibility(ctx context.Context, compatibility *
 		return 0, ErrItemNotFound
 	}
 
-	// Check if compatibility already exists
+	if err := s.validateCompatibility(ctx, compatibility); err != nil {
+		return 0, err
+	}
+
+	// Check if the same fitment already exists. The item may fit the
+	// submodel again for other years, positions or engines.
 	compatibilities, err := s.repo.GetCompatibilities(ctx, compatibility.ItemID)
 	if err != nil {
 		return 0, err
 	}
 
 	for _, existing := range compatibilities {
-		if existing.SubmodelID == compatibility.SubmodelID {
+		if sameFitment(existing, compatibility) {
 			return 0, ErrCompatibilityExists
 		}
 	}
@@ -248,7 +336,57 @@ func (s *inventoryService) AddCompatibility(ctx context.Context, compatibility *
 	return s.repo.AddCompatibility(ctx, compatibility)
 }
 
-func (s *inventoryService) RemoveCompatibility(ctx context.Context, itemID, submodelID int) error {
+func (s *inventoryService) UpdateCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) error {
+	if compatibility.ItemID <= 0 {
+		return ErrInvalidItemID
+	}
+	if compatibility.SubmodelID <= 0 {
+		return ErrInvalidSubmodelID
+	}
+
+	if err := s.validateCompatibility(ctx, compatibility); err != nil {
+		return err
+	}
+
+	// Find the fitment to change: the one given by compat_id, or the only
+	// one the item has to the submodel
+	compatibilities, err := s.repo.GetCompatibilities(ctx, compatibility.ItemID)
+	if err != nil {
+		return err
+	}
+
+	var target *inventorymodels.Compatibility
+	var others []*inventorymodels.Compatibility
+	for _, existing := range compatibilities {
+		if existing.SubmodelID != compatibility.SubmodelID {
+			continue
+		}
+		if compatibility.CompatID > 0 && existing.CompatID != compatibility.CompatID {
+			others = append(others, existing)
+			continue
+		}
+		if target != nil {
+			return ErrCompatAmbiguous
+		}
+		target = existing
+	}
+	if target == nil {
+		return ErrCompatNotFound
+	}
+	compatibility.CompatID = target.CompatID
+
+	for _, other := range others {
+		if sameFitment(other, compatibility) {
+			return ErrCompatibilityExists
+		}
+	}
+
+	return s.repo.UpdateCompatibility(ctx, compatibility)
+}
+
+// RemoveCompatibility removes the item's fitment given by compatID, or all
+// of its fitments to the submodel when compatID is nil
+func (s *inventoryService) RemoveCompatibility(ctx context.Context, itemID, submodelID int, compatID *int) error {
 	if itemID <= 0 {
 		return ErrInvalidItemID
 	}
@@ -256,15 +394,18 @@ func (s *inventoryService) RemoveCompatibility(ctx context.Context, itemID, subm
 		return ErrInvalidSubmodelID
 	}
 
-	return s.repo.RemoveCompatibility(ctx, itemID, submodelID)
+	return s.repo.RemoveCompatibility(ctx, itemID, submodelID, compatID)
 }
 
-func (s *inventoryService) GetCompatibleItems(ctx context.Context, submodelID int) ([]*inventorymodels.Item, error) {
+func (s *inventoryService) GetCompatibleItems(ctx context.Context, submodelID int, filter *inventorymodels.CompatibleItemFilter) ([]*inventorymodels.Item, error) {
 	if submodelID <= 0 {
 		return nil, ErrInvalidSubmodelID
 	}
+	if filter.Position != nil && !isValidPosition(*filter.Position) {
+		return nil, ErrInvalidPosition
+	}
 
-	return s.repo.GetCompatibleItems(ctx, submodelID)
+	return s.repo.GetCompatibleItems(ctx, submodelID, filter)
 }
 
 // Helper functions
@@ -287,5 +428,91 @@ func (s *inventoryService) validateItem(item *inventorymodels.Item) error {
 	if item.MinimumStock < 0 {
 		return errors.New("minimum stock cannot be negative")
 	}
+	if item.ReorderUpTo != nil && *item.ReorderUpTo < item.MinimumStock {
+		return errors.New("reorder-up-to level cannot be below minimum stock")
+	}
+	switch item.TrackingMode {
+	case inventorymodels.TrackingNone, inventorymodels.TrackingSerial, inventorymodels.TrackingLot:
+	default:
+		return ErrInvalidTrackingMode
+	}
+	if (item.WarrantyLength == nil) != (item.WarrantyUnit == nil) {
+		return ErrInvalidWarranty
+	}
+	if item.WarrantyLength != nil {
+		if *item.WarrantyLength <= 0 {
+			return ErrInvalidWarranty
+		}
+		switch *item.WarrantyUnit {
+		case inventorymodels.WarrantyDays, inventorymodels.WarrantyMonths, inventorymodels.WarrantyYears:
+		default:
+			return ErrInvalidWarranty
+		}
+	}
+	if item.CoreCharge != nil && *item.CoreCharge <= 0 {
+		return ErrInvalidCoreCharge
+	}
 	return nil
 }
+
+// sameFitment reports whether two compatibilities fit the same submodel over
+// the same years, at the same position and for the same engine
+func sameFitment(a, b *inventorymodels.Compatibility) bool {
+	return a.SubmodelID == b.SubmodelID &&
+		equalPtr(a.YearFrom, b.YearFrom) && equalPtr(a.YearTo, b.YearTo) &&
+		equalPtr(a.Position, b.Position) && equalPtr(a.EngineCode, b.EngineCode)
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// validateCompatibility checks the fitment qualifiers, and that any years
+// given fall within the years the submodel was built
+func (s *inventoryService) validateCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) error {
+	if compatibility.Position != nil && !isValidPosition(*compatibility.Position) {
+		return ErrInvalidPosition
+	}
+	if compatibility.EngineCode != nil {
+		engineCode := strings.TrimSpace(*compatibility.EngineCode)
+		if engineCode == "" {
+			compatibility.EngineCode = nil
+		} else {
+			compatibility.EngineCode = &engineCode
+		}
+	}
+
+	yearFrom, yearTo, err := s.repo.GetSubmodelYears(ctx, compatibility.SubmodelID)
+	if err != nil {
+		return err
+	}
+	if yearFrom == nil {
+		return ErrSubmodelNotFound
+	}
+
+	from, to := compatibility.YearFrom, compatibility.YearTo
+	if from != nil && to != nil && *to < *from {
+		return ErrInvalidCompatYears
+	}
+	if from != nil && (*from < *yearFrom || (yearTo != nil && *from > *yearTo)) {
+		return ErrInvalidCompatYears
+	}
+	if to != nil && (*to < *yearFrom || (yearTo != nil && *to > *yearTo)) {
+		return ErrInvalidCompatYears
+	}
+	return nil
+}
+
+func isValidPosition(position string) bool {
+	switch position {
+	case inventorymodels.PositionFront, inventorymodels.PositionRear,
+		inventorymodels.PositionLeft, inventorymodels.PositionRight,
+		inventorymodels.PositionFrontLeft, inventorymodels.PositionFrontRight,
+		inventorymodels.PositionRearLeft, inventorymodels.PositionRearRight:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
+)
+
+var (
+	ErrSupersessionNotFound = errors.New("item has not been superseded")
+	ErrAlreadySuperseded    = errors.New("item has already been superseded")
+	ErrReplacementNotFound  = errors.New("replacement item not found")
+	ErrSupersessionLoop     = errors.New("the new part cannot be the item itself or a part it replaced")
+)
+
+type SupersessionService interface {
+	GetHistory(ctx context.Context, itemID int) (*inventorymodels.SupersessionHistory, error)
+	Supersede(ctx context.Context, supersession *inventorymodels.Supersession) (*inventorymodels.Supersession, error)
+	RemoveSupersession(ctx context.Context, oldItemID int) error
+}
+
+type supersessionService struct {
+	repo     repositories.SupersessionRepository
+	itemRepo repositories.InventoryRepository
+}
+
+func NewSupersessionService(repo repositories.SupersessionRepository, itemRepo repositories.InventoryRepository) SupersessionService {
+	return &supersessionService{
+		repo:     repo,
+		itemRepo: itemRepo,
+	}
+}
+
+// GetHistory returns the item's supersessions in both directions and the
+// part that replaces it now
+func (s *supersessionService) GetHistory(ctx context.Context, itemID int) (*inventorymodels.SupersessionHistory, error) {
+	item, err := s.getItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &inventorymodels.SupersessionHistory{
+		ItemID:            item.ItemID,
+		PartNumber:        item.PartNumber,
+		CurrentItemID:     item.ItemID,
+		CurrentPartNumber: item.PartNumber,
+	}
+
+	history.Supersessions, err = s.repo.GetSupersessionHistory(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if history.Supersessions == nil {
+		history.Supersessions = []*inventorymodels.Supersession{}
+	}
+
+	// The chain is oldest first, so the last link leading on from the item
+	// names the current part
+	if item.SupersededBy != nil {
+		history.CurrentItemID, err = s.itemRepo.GetCurrentItemID(ctx, itemID)
+		if err != nil {
+			return nil, err
+		}
+		for _, supersession := range history.Supersessions {
+			if supersession.NewItemID == history.CurrentItemID {
+				history.CurrentPartNumber = supersession.NewPartNumber
+			}
+		}
+	}
+
+	return history, nil
+}
+
+// Supersede records the old item being replaced by the new one. Chains are
+// allowed, but an item is only superseded once and never by a part it led to.
+func (s *supersessionService) Supersede(ctx context.Context, supersession *inventorymodels.Supersession) (*inventorymodels.Supersession, error) {
+	old, err := s.getItem(ctx, supersession.OldItemID)
+	if err != nil {
+		return nil, err
+	}
+	if old.SupersededBy != nil {
+		return nil, ErrAlreadySuperseded
+	}
+
+	if supersession.NewItemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+	replacement, err := s.itemRepo.GetItemByID(ctx, supersession.NewItemID)
+	if err != nil {
+		return nil, err
+	}
+	if replacement == nil {
+		return nil, ErrReplacementNotFound
+	}
+
+	// The old item has no successor yet, so it ends the new item's chain only
+	// if the new item already leads to it
+	currentID, err := s.itemRepo.GetCurrentItemID(ctx, supersession.NewItemID)
+	if err != nil {
+		return nil, err
+	}
+	if currentID == supersession.OldItemID {
+		return nil, ErrSupersessionLoop
+	}
+
+	if supersession.SupersededOn.IsZero() {
+		supersession.SupersededOn = time.Now()
+	}
+
+	if _, err := s.repo.CreateSupersession(ctx, supersession); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSupersession(ctx, supersession.OldItemID)
+}
+
+// RemoveSupersession undoes the supersession of the item, making it a
+// current part again
+func (s *supersessionService) RemoveSupersession(ctx context.Context, oldItemID int) error {
+	item, err := s.getItem(ctx, oldItemID)
+	if err != nil {
+		return err
+	}
+	if item.SupersededBy == nil {
+		return ErrSupersessionNotFound
+	}
+
+	return s.repo.DeleteSupersession(ctx, oldItemID)
+}
+
+// Helper functions
+
+func (s *supersessionService) getItem(ctx context.Context, itemID int) (*inventorymodels.Item, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	item, err := s.itemRepo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	return item, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
+)
+
+var (
+	ErrSerialNotFound      = errors.New("serial number not found")
+	ErrLotNotFound         = errors.New("lot not found")
+	ErrInvalidSerialStatus = errors.New("status must be in_stock or sold")
+)
+
+type TrackingService interface {
+	GetItemSerials(ctx context.Context, itemID int, filter *inventorymodels.SerialFilter) ([]*inventorymodels.SerialUnit, error)
+	GetItemLots(ctx context.Context, itemID int) ([]*inventorymodels.Lot, error)
+	FindSerial(ctx context.Context, serialNumber string) ([]*inventorymodels.SerialUnit, error)
+	TraceLot(ctx context.Context, lotNumber string) ([]*inventorymodels.Lot, error)
+}
+
+type trackingService struct {
+	repo     repositories.TrackingRepository
+	itemRepo repositories.InventoryRepository
+}
+
+func NewTrackingService(repo repositories.TrackingRepository, itemRepo repositories.InventoryRepository) TrackingService {
+	return &trackingService{
+		repo:     repo,
+		itemRepo: itemRepo,
+	}
+}
+
+func (s *trackingService) GetItemSerials(ctx context.Context, itemID int, filter *inventorymodels.SerialFilter) ([]*inventorymodels.SerialUnit, error) {
+	if err := s.checkItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	if filter.Status != nil && *filter.Status != inventorymodels.SerialInStock && *filter.Status != inventorymodels.SerialSold {
+		return nil, ErrInvalidSerialStatus
+	}
+
+	serials, err := s.repo.GetItemSerials(ctx, itemID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if serials == nil {
+		serials = []*inventorymodels.SerialUnit{}
+	}
+	return serials, nil
+}
+
+func (s *trackingService) GetItemLots(ctx context.Context, itemID int) ([]*inventorymodels.Lot, error) {
+	if err := s.checkItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+
+	lots, err := s.repo.GetItemLots(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if lots == nil {
+		lots = []*inventorymodels.Lot{}
+	}
+	return lots, nil
+}
+
+// FindSerial returns the units carrying the serial number, with the
+// customer each was sold to
+func (s *trackingService) FindSerial(ctx context.Context, serialNumber string) ([]*inventorymodels.SerialUnit, error) {
+	serials, err := s.repo.FindSerials(ctx, strings.TrimSpace(serialNumber))
+	if err != nil {
+		return nil, err
+	}
+	if len(serials) == 0 {
+		return nil, ErrSerialNotFound
+	}
+	return serials, nil
+}
+
+// TraceLot returns every receipt of the lot number with the sales drawn
+// from it and the quantity still in stock, for recalls
+func (s *trackingService) TraceLot(ctx context.Context, lotNumber string) ([]*inventorymodels.Lot, error) {
+	lots, err := s.repo.FindLots(ctx, strings.TrimSpace(lotNumber))
+	if err != nil {
+		return nil, err
+	}
+	if len(lots) == 0 {
+		return nil, ErrLotNotFound
+	}
+
+	for _, lot := range lots {
+		lot.Sales, err = s.repo.GetLotSales(ctx, lot.LotID)
+		if err != nil {
+			return nil, err
+		}
+		if lot.Sales == nil {
+			lot.Sales = []*inventorymodels.LotSale{}
+		}
+	}
+	return lots, nil
+}
+
+// Helper functions
+
+func (s *trackingService) checkItem(ctx context.Context, itemID int) error {
+	if itemID <= 0 {
+		return ErrInvalidItemID
+	}
+
+	item, err := s.itemRepo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return ErrItemNotFound
+	}
+	return nil
+}
@@ -56,6 +56,18 @@ func (h *PurchaseHandler) GetPurchases(c echo.Context) error {
         filter.InvoiceNumber = &invoiceNumber
     }
 
+    if supplierInvoiceID := c.QueryParam("supplier_invoice_id"); supplierInvoiceID != "" {
+        id, err := strconv.Atoi(supplierInvoiceID)
+        if err == nil {
+            filter.SupplierInvoiceID = &id
+        }
+    }
+
+    if uninvoiced := c.QueryParam("uninvoiced"); uninvoiced == "true" {
+        value := true
+        filter.Uninvoiced = &value
+    }
+
     ctx := c.Request().Context()
     purchases, err := h.service.GetAll(ctx, filter)
     if err != nil {
@@ -99,10 +111,17 @@ func (h *PurchaseHandler) CreatePurchase(c echo.Context) error {
         switch err {
         case services.ErrInvalidSupplierID, services.ErrInvalidItemID,
              services.ErrInvalidQuantity, services.ErrInvalidCostPerUnit,
-             services.ErrInvalidDate:
+             services.ErrInvalidDate, services.ErrInvalidWarehouseID,
+             services.ErrSerialNumbersMismatch, services.ErrLotsMismatch,
+             services.ErrInvalidLot, services.ErrDuplicateLotNumber,
+             services.ErrItemNotTracked:
             return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-        case services.ErrDuplicateInvoiceNumber:
+        case services.ErrItemNotFound:
+            return echo.NewHTTPError(http.StatusNotFound, err.Error())
+        case services.ErrDuplicateInvoiceNumber, services.ErrDuplicateSerialNumber:
             return echo.NewHTTPError(http.StatusConflict, err.Error())
+        case services.ErrItemIsKit:
+            return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
         default:
             return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
         }
@@ -133,9 +152,10 @@ func (h *PurchaseHandler) UpdatePurchase(c echo.Context) error {
             return echo.NewHTTPError(http.StatusNotFound, err.Error())
         case services.ErrInvalidSupplierID, services.ErrInvalidItemID,
              services.ErrInvalidQuantity, services.ErrInvalidCostPerUnit,
-             services.ErrInvalidDate:
+             services.ErrInvalidDate, services.ErrInvalidWarehouseID:
             return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-        case services.ErrDuplicateInvoiceNumber:
+        case services.ErrDuplicateInvoiceNumber, services.ErrPurchaseInvoiced,
+             services.ErrCostedPurchaseLocked:
             return echo.NewHTTPError(http.StatusConflict, err.Error())
         default:
             return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -158,6 +178,8 @@ func (h *PurchaseHandler) DeletePurchase(c echo.Context) error {
         switch err {
         case services.ErrPurchaseNotFound:
             return echo.NewHTTPError(http.StatusNotFound, err.Error())
+        case services.ErrPurchaseInvoiced, services.ErrTrackedReceiptNotDeletable:
+            return echo.NewHTTPError(http.StatusConflict, err.Error())
         default:
             return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
         }
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
+	"github.com/hsrvms/autoparts/internal/modules/purchases/services"
+	"github.com/labstack/echo/v4"
+)
+
+type PurchaseOrderHandler struct {
+	service services.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(service services.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{
+		service: service,
+	}
+}
+
+// GetPurchaseOrders handles retrieval of purchase orders with optional filtering
+func (h *PurchaseOrderHandler) GetPurchaseOrders(c echo.Context) error {
+	filter := &purchasemodels.PurchaseOrderFilter{}
+
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		if id, err := strconv.Atoi(supplierID); err == nil {
+			filter.SupplierID = &id
+		}
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	ctx := c.Request().Context()
+	orders, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, orders)
+}
+
+// GetPurchaseOrderByID handles retrieval of a purchase order with its lines
+func (h *PurchaseOrderHandler) GetPurchaseOrderByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	ctx := c.Request().Context()
+	order, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return purchaseOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// UpdatePurchaseOrderLine handles changing the quantity or cost of a draft line
+func (h *PurchaseOrderHandler) UpdatePurchaseOrderLine(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	lineID, err := strconv.Atoi(c.Param("lineId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid line ID")
+	}
+
+	line := new(purchasemodels.PurchaseOrderLine)
+	if err := c.Bind(line); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	line.PurchaseOrderID = id
+	line.LineID = lineID
+
+	ctx := c.Request().Context()
+	if err := h.service.UpdateLine(ctx, line); err != nil {
+		return purchaseOrderError(err)
+	}
+
+	order, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return purchaseOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// RemovePurchaseOrderLine handles removing a line from a draft purchase order
+func (h *PurchaseOrderHandler) RemovePurchaseOrderLine(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	lineID, err := strconv.Atoi(c.Param("lineId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid line ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.RemoveLine(ctx, id, lineID); err != nil {
+		return purchaseOrderError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SubmitPurchaseOrder handles sending a draft purchase order to the supplier
+func (h *PurchaseOrderHandler) SubmitPurchaseOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	var req struct {
+		ExpectedDate *time.Time `json:"expected_date,omitempty"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	order, err := h.service.Submit(ctx, id, req.ExpectedDate)
+	if err != nil {
+		return purchaseOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// CancelPurchaseOrder handles cancelling a purchase order with no receipts
+func (h *PurchaseOrderHandler) CancelPurchaseOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Cancel(ctx, id); err != nil {
+		return purchaseOrderError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReceivePurchaseOrder handles booking in goods against a purchase order
+func (h *PurchaseOrderHandler) ReceivePurchaseOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase order ID")
+	}
+
+	req := new(purchasemodels.ReceivePurchaseOrderRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	order, err := h.service.Receive(ctx, id, req)
+	if err != nil {
+		return purchaseOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+func purchaseOrderError(err error) error {
+	switch err {
+	case services.ErrInvalidPurchaseOrderID, services.ErrInvalidQuantity,
+		services.ErrInvalidCostPerUnit, services.ErrInvalidExpectedDate,
+		services.ErrInvalidWarehouseID, services.ErrSerialNumbersMismatch,
+		services.ErrLotsMismatch, services.ErrInvalidLot, services.ErrDuplicateLotNumber,
+		services.ErrItemNotTracked:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrPurchaseOrderNotFound, services.ErrPurchaseOrderLineNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrPurchaseOrderNotDraft, services.ErrPurchaseOrderNotOrdered,
+		services.ErrPurchaseOrderNotCancelable, services.ErrDuplicateSerialNumber:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrPurchaseOrderEmpty, services.ErrReceiveExceedsOrdered,
+		services.ErrNothingToReceive, services.ErrItemIsKit:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -3,23 +3,36 @@ package purchasemodels
 import "time"
 
 type Purchase struct {
-	PurchaseID    int       `json:"purchase_id" db:"purchase_id"`
-	Date          time.Time `json:"date" db:"date"`
-	SupplierID    int       `json:"supplier_id" db:"supplier_id"`
-	ItemID        int       `json:"item_id" db:"item_id"`
-	Quantity      int       `json:"quantity" db:"quantity"`
-	CostPerUnit   float64   `json:"cost_per_unit" db:"cost_per_unit"`
-	TotalCost     float64   `json:"total_cost" db:"total_cost"`
-	InvoiceNumber *string   `json:"invoice_number,omitempty" db:"invoice_number"`
-	ReceivedBy    *string   `json:"received_by,omitempty" db:"received_by"`
-	Notes         *string   `json:"notes,omitempty" db:"notes"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	PurchaseID        int           `json:"purchase_id" db:"purchase_id"`
+	Date              time.Time     `json:"date" db:"date"`
+	SupplierID        int           `json:"supplier_id" db:"supplier_id"`
+	ItemID            int           `json:"item_id" db:"item_id"`
+	Quantity          int           `json:"quantity" db:"quantity"`
+	CostPerUnit       float64       `json:"cost_per_unit" db:"cost_per_unit"`
+	TotalCost         float64       `json:"total_cost" db:"total_cost"`
+	InvoiceNumber     *string       `json:"invoice_number,omitempty" db:"invoice_number"`
+	ReceivedBy        *string       `json:"received_by,omitempty" db:"received_by"`
+	Notes             *string       `json:"notes,omitempty" db:"notes"`
+	SupplierInvoiceID *int          `json:"supplier_invoice_id,omitempty" db:"supplier_invoice_id"`
+	PurchaseOrderID   *int          `json:"purchase_order_id,omitempty" db:"purchase_order_id"`
+	WarehouseID       *int          `json:"warehouse_id,omitempty" db:"warehouse_id"`
+	SerialNumbers     []string      `json:"serial_numbers,omitempty" db:"-"` // One per unit, for serial tracked items
+	Lots              []*LotReceipt `json:"lots,omitempty" db:"-"`           // Adding up to the quantity, for lot tracked items
+	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at" db:"updated_at"`
 
 	// Additional fields for API responses
 	SupplierName    string `json:"supplier_name,omitempty" db:"supplier_name"`
 	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
 	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+	WarehouseCode   string `json:"warehouse_code,omitempty" db:"warehouse_code"`
+}
+
+// LotReceipt is the part of a receipt that came in under one lot number
+type LotReceipt struct {
+	LotNumber  string     `json:"lot_number"`
+	Quantity   int        `json:"quantity"`
+	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
 }
 
 type PurchaseFilter struct {
@@ -28,4 +41,8 @@ type PurchaseFilter struct {
 	StartDate     *time.Time `query:"start_date"`
 	EndDate       *time.Time `query:"end_date"`
 	InvoiceNumber *string    `query:"invoice_number"`
+
+	SupplierInvoiceID *int  `query:"supplier_invoice_id"`
+	Uninvoiced        *bool `query:"uninvoiced"`
+	WarehouseID       *int  `query:"warehouse_id"`
 }
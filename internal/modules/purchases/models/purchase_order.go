@@ -0,0 +1,65 @@
+package purchasemodels
+
+import "time"
+
+// Purchase order statuses
+const (
+	OrderStatusDraft     = "draft"
+	OrderStatusOrdered   = "ordered"
+	OrderStatusReceived  = "received"
+	OrderStatusCancelled = "cancelled"
+)
+
+type PurchaseOrder struct {
+	PurchaseOrderID int        `json:"purchase_order_id" db:"purchase_order_id"`
+	PONumber        string     `json:"po_number" db:"po_number"`
+	SupplierID      int        `json:"supplier_id" db:"supplier_id"`
+	Status          string     `json:"status" db:"status"`
+	OrderDate       *time.Time `json:"order_date,omitempty" db:"order_date"`
+	ExpectedDate    *time.Time `json:"expected_date,omitempty" db:"expected_date"`
+	Notes           *string    `json:"notes,omitempty" db:"notes"`
+	CreatedBy       *string    `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	SupplierName string               `json:"supplier_name,omitempty" db:"supplier_name"`
+	TotalCost    float64              `json:"total_cost" db:"total_cost"`
+	Lines        []*PurchaseOrderLine `json:"lines,omitempty" db:"-"`
+}
+
+type PurchaseOrderLine struct {
+	LineID           int     `json:"line_id" db:"line_id"`
+	PurchaseOrderID  int     `json:"purchase_order_id" db:"purchase_order_id"`
+	ItemID           int     `json:"item_id" db:"item_id"`
+	Quantity         int     `json:"quantity" db:"quantity"`
+	QuantityReceived int     `json:"quantity_received" db:"quantity_received"`
+	UnitCost         float64 `json:"unit_cost" db:"unit_cost"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string  `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string  `json:"item_description,omitempty" db:"item_description"`
+	TrackingMode    string  `json:"tracking_mode" db:"tracking_mode"`
+	IsKit           bool    `json:"is_kit" db:"is_kit"`
+	LineTotal       float64 `json:"line_total" db:"-"`
+}
+
+type PurchaseOrderFilter struct {
+	SupplierID *int    `query:"supplier_id"`
+	Status     *string `query:"status"`
+}
+
+// ReceivePurchaseOrderRequest books goods in against an order. Without
+// lines, everything still outstanding is received.
+type ReceivePurchaseOrderRequest struct {
+	ReceivedBy  *string        `json:"received_by,omitempty"`
+	WarehouseID *int           `json:"warehouse_id,omitempty"` // Default warehouse if omitted
+	Lines       []*ReceiveLine `json:"lines,omitempty"`
+}
+
+type ReceiveLine struct {
+	LineID        int           `json:"line_id"`
+	Quantity      int           `json:"quantity"`
+	SerialNumbers []string      `json:"serial_numbers,omitempty"`
+	Lots          []*LotReceipt `json:"lots,omitempty"`
+}
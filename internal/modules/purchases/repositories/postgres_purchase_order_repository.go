@@ -0,0 +1,340 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresPurchaseOrderRepository struct {
+	db *db.Database
+}
+
+func NewPostgresPurchaseOrderRepository(database *db.Database) PurchaseOrderRepository {
+	return &PostgresPurchaseOrderRepository{
+		db: database,
+	}
+}
+
+const purchaseOrderColumns = `
+	po.purchase_order_id, po.po_number, po.supplier_id, po.status,
+	po.order_date, po.expected_date, po.notes, po.created_by,
+	po.created_at, po.updated_at,
+	s.name as supplier_name,
+	COALESCE((
+		SELECT SUM(l.quantity * l.unit_cost)
+		FROM purchase_order_lines l
+		WHERE l.purchase_order_id = po.purchase_order_id
+	), 0) as total_cost
+`
+
+func scanPurchaseOrder(row pgx.Row) (*purchasemodels.PurchaseOrder, error) {
+	order := &purchasemodels.PurchaseOrder{}
+	err := row.Scan(
+		&order.PurchaseOrderID,
+		&order.PONumber,
+		&order.SupplierID,
+		&order.Status,
+		&order.OrderDate,
+		&order.ExpectedDate,
+		&order.Notes,
+		&order.CreatedBy,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&order.SupplierName,
+		&order.TotalCost,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (r *PostgresPurchaseOrderRepository) GetAll(ctx context.Context, filter *purchasemodels.PurchaseOrderFilter) ([]*purchasemodels.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders po
+		JOIN suppliers s ON po.supplier_id = s.supplier_id
+		WHERE 1=1
+	`
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.SupplierID != nil {
+			conditions = append(conditions, fmt.Sprintf("po.supplier_id = $%d", paramCount))
+			params = append(params, *filter.SupplierID)
+			paramCount++
+		}
+
+		if filter.Status != nil {
+			conditions = append(conditions, fmt.Sprintf("po.status = $%d", paramCount))
+			params = append(params, *filter.Status)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY po.created_at DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*purchasemodels.PurchaseOrder
+	for rows.Next() {
+		order, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+func (r *PostgresPurchaseOrderRepository) GetByID(ctx context.Context, id int) (*purchasemodels.PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders po
+		JOIN suppliers s ON po.supplier_id = s.supplier_id
+		WHERE po.purchase_order_id = $1
+	`
+
+	order, err := scanPurchaseOrder(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *PostgresPurchaseOrderRepository) GetLines(ctx context.Context, purchaseOrderID int) ([]*purchasemodels.PurchaseOrderLine, error) {
+	query := `
+		SELECT l.line_id, l.purchase_order_id, l.item_id, l.quantity,
+			l.quantity_received, l.unit_cost,
+			i.part_number as item_part_number,
+			i.description as item_description,
+			i.tracking_mode, i.is_kit
+		FROM purchase_order_lines l
+		JOIN items i ON l.item_id = i.item_id
+		WHERE l.purchase_order_id = $1
+		ORDER BY i.part_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*purchasemodels.PurchaseOrderLine
+	for rows.Next() {
+		line := &purchasemodels.PurchaseOrderLine{}
+		err := rows.Scan(
+			&line.LineID,
+			&line.PurchaseOrderID,
+			&line.ItemID,
+			&line.Quantity,
+			&line.QuantityReceived,
+			&line.UnitCost,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+			&line.TrackingMode,
+			&line.IsKit,
+		)
+		if err != nil {
+			return nil, err
+		}
+		line.LineTotal = float64(line.Quantity) * line.UnitCost
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+func (r *PostgresPurchaseOrderRepository) UpdateLine(ctx context.Context, line *purchasemodels.PurchaseOrderLine) error {
+	query := `
+		UPDATE purchase_order_lines SET
+			quantity = $3,
+			unit_cost = $4
+		WHERE line_id = $1 AND purchase_order_id = $2
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, line.LineID, line.PurchaseOrderID, line.Quantity, line.UnitCost)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("purchase order line not found")
+	}
+
+	return nil
+}
+
+func (r *PostgresPurchaseOrderRepository) RemoveLine(ctx context.Context, purchaseOrderID, lineID int) error {
+	query := `DELETE FROM purchase_order_lines WHERE line_id = $1 AND purchase_order_id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, lineID, purchaseOrderID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("purchase order line not found")
+	}
+
+	return nil
+}
+
+// MarkOrdered moves a draft to ordered. Without an expected date the
+// supplier's lead time is used.
+func (r *PostgresPurchaseOrderRepository) MarkOrdered(ctx context.Context, id int, orderDate time.Time, expectedDate *time.Time) error {
+	query := `
+		UPDATE purchase_orders SET
+			status = 'ordered',
+			order_date = $2,
+			expected_date = COALESCE($3, $2::date + (
+				SELECT lead_time_days FROM suppliers s
+				WHERE s.supplier_id = purchase_orders.supplier_id
+			))
+		WHERE purchase_order_id = $1 AND status = 'draft'
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, orderDate, expectedDate)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("purchase order is not a draft")
+	}
+
+	return nil
+}
+
+func (r *PostgresPurchaseOrderRepository) Cancel(ctx context.Context, id int) error {
+	query := `
+		UPDATE purchase_orders SET status = 'cancelled'
+		WHERE purchase_order_id = $1
+			AND status IN ('draft', 'ordered')
+			AND NOT EXISTS (
+				SELECT 1 FROM purchase_order_lines
+				WHERE purchase_order_id = $1 AND quantity_received > 0
+			)
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("purchase order cannot be cancelled")
+	}
+
+	return nil
+}
+
+// Receive books in the given receipt per line ID. Each receipt is recorded
+// as a purchase (which moves stock through the purchase trigger) with its
+// serial numbers or lots, and the order is marked received once every line
+// is complete. Returns the IDs of the purchases created.
+func (r *PostgresPurchaseOrderRepository) Receive(ctx context.Context, order *purchasemodels.PurchaseOrder, receipts map[int]*purchasemodels.ReceiveLine, receivedBy *string, warehouseID *int) ([]int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	var purchaseIDs []int
+
+	for lineID, receipt := range receipts {
+		quantity := receipt.Quantity
+
+		var itemID int
+		var unitCost float64
+		err := tx.QueryRow(ctx, `
+			UPDATE purchase_order_lines
+			SET quantity_received = quantity_received + $3
+			WHERE line_id = $1 AND purchase_order_id = $2
+				AND quantity_received + $3 <= quantity
+			RETURNING item_id, unit_cost
+		`, lineID, order.PurchaseOrderID, quantity).Scan(&itemID, &unitCost)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("cannot receive %d more on line %d", quantity, lineID)
+			}
+			return nil, err
+		}
+
+		var purchaseID int
+		err = tx.QueryRow(ctx, `
+			INSERT INTO purchases (
+				date, supplier_id, item_id, quantity, cost_per_unit,
+				total_cost, received_by, purchase_order_id, warehouse_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING purchase_id
+		`,
+			now,
+			order.SupplierID,
+			itemID,
+			quantity,
+			unitCost,
+			math.Round(float64(quantity)*unitCost*100)/100,
+			receivedBy,
+			order.PurchaseOrderID,
+			warehouseID,
+		).Scan(&purchaseID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = insertTrackedUnits(ctx, tx, purchaseID, itemID, now, receipt.SerialNumbers, receipt.Lots)
+		if err != nil {
+			return nil, err
+		}
+		purchaseIDs = append(purchaseIDs, purchaseID)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE purchase_orders SET status = 'received'
+		WHERE purchase_order_id = $1
+			AND NOT EXISTS (
+				SELECT 1 FROM purchase_order_lines
+				WHERE purchase_order_id = $1 AND quantity_received < quantity
+			)
+	`, order.PurchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return purchaseIDs, nil
+}
+
+func (r *PostgresPurchaseOrderRepository) ExistingSerials(ctx context.Context, itemID int, serialNumbers []string) ([]string, error) {
+	return existingSerials(ctx, r.db, itemID, serialNumbers)
+}
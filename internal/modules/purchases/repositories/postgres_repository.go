@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
 	"github.com/hsrvms/autoparts/pkg/db"
@@ -21,19 +22,54 @@ func NewPostgresPurchaseRepository(database *db.Database) PurchaseRepository {
     }
 }
 
+const purchaseColumns = `
+    p.purchase_id, p.date, p.supplier_id, p.item_id,
+    p.quantity, p.cost_per_unit, p.total_cost,
+    p.invoice_number, p.received_by, p.notes,
+    p.supplier_invoice_id, p.purchase_order_id, p.warehouse_id,
+    p.created_at, p.updated_at,
+    s.name as supplier_name,
+    i.part_number as item_part_number,
+    i.description as item_description,
+    w.code as warehouse_code
+`
+
+func scanPurchase(row pgx.Row) (*purchasemodels.Purchase, error) {
+    purchase := &purchasemodels.Purchase{}
+    err := row.Scan(
+        &purchase.PurchaseID,
+        &purchase.Date,
+        &purchase.SupplierID,
+        &purchase.ItemID,
+        &purchase.Quantity,
+        &purchase.CostPerUnit,
+        &purchase.TotalCost,
+        &purchase.InvoiceNumber,
+        &purchase.ReceivedBy,
+        &purchase.Notes,
+        &purchase.SupplierInvoiceID,
+        &purchase.PurchaseOrderID,
+        &purchase.WarehouseID,
+        &purchase.CreatedAt,
+        &purchase.UpdatedAt,
+        &purchase.SupplierName,
+        &purchase.ItemPartNumber,
+        &purchase.ItemDescription,
+        &purchase.WarehouseCode,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return purchase, nil
+}
+
 func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *purchasemodels.PurchaseFilter) ([]*purchasemodels.Purchase, error) {
     query := `
-        SELECT
-            p.purchase_id, p.date, p.supplier_id, p.item_id,
-            p.quantity, p.cost_per_unit, p.total_cost,
-            p.invoice_number, p.received_by, p.notes,
-            p.created_at, p.updated_at,
-            s.name as supplier_name,
-            i.part_number as item_part_number,
-            i.description as item_description
+        SELECT ` + purchaseColumns + `
         FROM purchases p
         JOIN suppliers s ON p.supplier_id = s.supplier_id
         JOIN items i ON p.item_id = i.item_id
+        JOIN warehouses w ON p.warehouse_id = w.warehouse_id
         WHERE 1=1
     `
 
@@ -71,6 +107,22 @@ func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *purchas
             params = append(params, "%"+*filter.InvoiceNumber+"%")
             paramCount++
         }
+
+        if filter.SupplierInvoiceID != nil {
+            conditions = append(conditions, fmt.Sprintf("p.supplier_invoice_id = $%d", paramCount))
+            params = append(params, *filter.SupplierInvoiceID)
+            paramCount++
+        }
+
+        if filter.Uninvoiced != nil && *filter.Uninvoiced {
+            conditions = append(conditions, "p.supplier_invoice_id IS NULL")
+        }
+
+        if filter.WarehouseID != nil {
+            conditions = append(conditions, fmt.Sprintf("p.warehouse_id = $%d", paramCount))
+            params = append(params, *filter.WarehouseID)
+            paramCount++
+        }
     }
 
     if len(conditions) > 0 {
@@ -87,24 +139,7 @@ func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *purchas
 
     var purchases []*purchasemodels.Purchase
     for rows.Next() {
-        purchase := &purchasemodels.Purchase{}
-        err := rows.Scan(
-            &purchase.PurchaseID,
-            &purchase.Date,
-            &purchase.SupplierID,
-            &purchase.ItemID,
-            &purchase.Quantity,
-            &purchase.CostPerUnit,
-            &purchase.TotalCost,
-            &purchase.InvoiceNumber,
-            &purchase.ReceivedBy,
-            &purchase.Notes,
-            &purchase.CreatedAt,
-            &purchase.UpdatedAt,
-            &purchase.SupplierName,
-            &purchase.ItemPartNumber,
-            &purchase.ItemDescription,
-        )
+        purchase, err := scanPurchase(rows)
         if err != nil {
             return nil, err
         }
@@ -116,38 +151,15 @@ func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *purchas
 
 func (r *PostgresPurchaseRepository) GetByID(ctx context.Context, id int) (*purchasemodels.Purchase, error) {
     query := `
-        SELECT
-            p.purchase_id, p.date, p.supplier_id, p.item_id,
-            p.quantity, p.cost_per_unit, p.total_cost,
-            p.invoice_number, p.received_by, p.notes,
-            p.created_at, p.updated_at,
-            s.name as supplier_name,
-            i.part_number as item_part_number,
-            i.description as item_description
+        SELECT ` + purchaseColumns + `
         FROM purchases p
         JOIN suppliers s ON p.supplier_id = s.supplier_id
         JOIN items i ON p.item_id = i.item_id
+        JOIN warehouses w ON p.warehouse_id = w.warehouse_id
         WHERE p.purchase_id = $1
     `
 
-    purchase := &purchasemodels.Purchase{}
-    err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-        &purchase.PurchaseID,
-        &purchase.Date,
-        &purchase.SupplierID,
-        &purchase.ItemID,
-        &purchase.Quantity,
-        &purchase.CostPerUnit,
-        &purchase.TotalCost,
-        &purchase.InvoiceNumber,
-        &purchase.ReceivedBy,
-        &purchase.Notes,
-        &purchase.CreatedAt,
-        &purchase.UpdatedAt,
-        &purchase.SupplierName,
-        &purchase.ItemPartNumber,
-        &purchase.ItemDescription,
-    )
+    purchase, err := scanPurchase(r.db.Pool.QueryRow(ctx, query, id))
 
     if err != nil {
         if errors.Is(err, pgx.ErrNoRows) {
@@ -159,18 +171,25 @@ func (r *PostgresPurchaseRepository) GetByID(ctx context.Context, id int) (*purc
     return purchase, nil
 }
 
+// Create records the receipt together with its serial numbers or lots
 func (r *PostgresPurchaseRepository) Create(ctx context.Context, purchase *purchasemodels.Purchase) (int, error) {
+    tx, err := r.db.Pool.Begin(ctx)
+    if err != nil {
+        return 0, err
+    }
+    defer tx.Rollback(ctx)
+
     query := `
         INSERT INTO purchases (
             date, supplier_id, item_id, quantity,
             cost_per_unit, total_cost, invoice_number,
-            received_by, notes
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+            received_by, notes, warehouse_id
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
         RETURNING purchase_id
     `
 
     var id int
-    err := r.db.Pool.QueryRow(
+    err = tx.QueryRow(
         ctx, query,
         purchase.Date,
         purchase.SupplierID,
@@ -181,12 +200,22 @@ func (r *PostgresPurchaseRepository) Create(ctx context.Context, purchase *purch
         purchase.InvoiceNumber,
         purchase.ReceivedBy,
         purchase.Notes,
+        purchase.WarehouseID,
     ).Scan(&id)
 
     if err != nil {
         return 0, err
     }
 
+    err = insertTrackedUnits(ctx, tx, id, purchase.ItemID, purchase.Date, purchase.SerialNumbers, purchase.Lots)
+    if err != nil {
+        return 0, err
+    }
+
+    if err = tx.Commit(ctx); err != nil {
+        return 0, err
+    }
+
     return id, nil
 }
 
@@ -247,38 +276,15 @@ func (r *PostgresPurchaseRepository) Delete(ctx context.Context, id int) error {
 
 func (r *PostgresPurchaseRepository) GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*purchasemodels.Purchase, error) {
     query := `
-        SELECT
-            p.purchase_id, p.date, p.supplier_id, p.item_id,
-            p.quantity, p.cost_per_unit, p.total_cost,
-            p.invoice_number, p.received_by, p.notes,
-            p.created_at, p.updated_at,
-            s.name as supplier_name,
-            i.part_number as item_part_number,
-            i.description as item_description
+        SELECT ` + purchaseColumns + `
         FROM purchases p
         JOIN suppliers s ON p.supplier_id = s.supplier_id
         JOIN items i ON p.item_id = i.item_id
+        JOIN warehouses w ON p.warehouse_id = w.warehouse_id
         WHERE p.invoice_number = $1
     `
 
-    purchase := &purchasemodels.Purchase{}
-    err := r.db.Pool.QueryRow(ctx, query, invoiceNumber).Scan(
-        &purchase.PurchaseID,
-        &purchase.Date,
-        &purchase.SupplierID,
-        &purchase.ItemID,
-        &purchase.Quantity,
-        &purchase.CostPerUnit,
-        &purchase.TotalCost,
-        &purchase.InvoiceNumber,
-        &purchase.ReceivedBy,
-        &purchase.Notes,
-        &purchase.CreatedAt,
-        &purchase.UpdatedAt,
-        &purchase.SupplierName,
-        &purchase.ItemPartNumber,
-        &purchase.ItemDescription,
-    )
+    purchase, err := scanPurchase(r.db.Pool.QueryRow(ctx, query, invoiceNumber))
 
     if err != nil {
         if errors.Is(err, pgx.ErrNoRows) {
@@ -303,3 +309,133 @@ func (r *PostgresPurchaseRepository) GetItemPurchases(ctx context.Context, itemI
     }
     return r.GetAll(ctx, filter)
 }
+
+func (r *PostgresPurchaseRepository) GetItemTrackingMode(ctx context.Context, itemID int) (*string, error) {
+    var mode string
+    err := r.db.Pool.QueryRow(ctx, `SELECT tracking_mode FROM items WHERE item_id = $1`, itemID).Scan(&mode)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, nil
+        }
+        return nil, err
+    }
+
+    return &mode, nil
+}
+
+// IsKit reports whether the item is a kit, which is never purchased itself
+func (r *PostgresPurchaseRepository) IsKit(ctx context.Context, itemID int) (bool, error) {
+    var isKit bool
+    err := r.db.Pool.QueryRow(ctx, `SELECT is_kit FROM items WHERE item_id = $1`, itemID).Scan(&isKit)
+    if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+        return false, err
+    }
+
+    return isKit, nil
+}
+
+func (r *PostgresPurchaseRepository) ExistingSerials(ctx context.Context, itemID int, serialNumbers []string) ([]string, error) {
+    return existingSerials(ctx, r.db, itemID, serialNumbers)
+}
+
+// GetTrackedUnits returns the serial numbers or lots recorded on a receipt
+func (r *PostgresPurchaseRepository) GetTrackedUnits(ctx context.Context, purchaseID int) ([]string, []*purchasemodels.LotReceipt, error) {
+    rows, err := r.db.Pool.Query(ctx, `
+        SELECT serial_number FROM item_serials
+        WHERE purchase_id = $1
+        ORDER BY serial_id
+    `, purchaseID)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    var serials []string
+    for rows.Next() {
+        var serial string
+        if err := rows.Scan(&serial); err != nil {
+            rows.Close()
+            return nil, nil, err
+        }
+        serials = append(serials, serial)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, nil, err
+    }
+
+    rows, err = r.db.Pool.Query(ctx, `
+        SELECT lot_number, quantity_received, expiry_date FROM item_lots
+        WHERE purchase_id = $1
+        ORDER BY lot_id
+    `, purchaseID)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer rows.Close()
+
+    var lots []*purchasemodels.LotReceipt
+    for rows.Next() {
+        lot := &purchasemodels.LotReceipt{}
+        if err := rows.Scan(&lot.LotNumber, &lot.Quantity, &lot.ExpiryDate); err != nil {
+            return nil, nil, err
+        }
+        lots = append(lots, lot)
+    }
+
+    return serials, lots, rows.Err()
+}
+
+// insertTrackedUnits records the serial numbers or lots of a receipt.
+// Serial numbered units are held at the warehouse the purchase was received
+// into.
+func insertTrackedUnits(ctx context.Context, tx pgx.Tx, purchaseID, itemID int, receivedAt time.Time, serialNumbers []string, lots []*purchasemodels.LotReceipt) error {
+    if len(serialNumbers) > 0 {
+        _, err := tx.Exec(ctx, `
+            INSERT INTO item_serials (item_id, serial_number, purchase_id, warehouse_id, received_at)
+            SELECT $1, serial_number, $2, p.warehouse_id, $3
+            FROM unnest($4::text[]) AS serial_number
+            JOIN purchases p ON p.purchase_id = $2
+        `, itemID, purchaseID, receivedAt, serialNumbers)
+        if err != nil {
+            return err
+        }
+    }
+
+    for _, lot := range lots {
+        _, err := tx.Exec(ctx, `
+            INSERT INTO item_lots (
+                item_id, lot_number, purchase_id, quantity_received,
+                quantity_remaining, expiry_date, received_at
+            ) VALUES ($1, $2, $3, $4, $4, $5, $6)
+        `, itemID, lot.LotNumber, purchaseID, lot.Quantity, lot.ExpiryDate, receivedAt)
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// existingSerials returns those of the serial numbers already recorded
+// for the item
+func existingSerials(ctx context.Context, database *db.Database, itemID int, serialNumbers []string) ([]string, error) {
+    rows, err := database.Pool.Query(ctx, `
+        SELECT serial_number FROM item_serials
+        WHERE item_id = $1 AND serial_number = ANY($2::text[])
+    `, itemID, serialNumbers)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var existing []string
+    for rows.Next() {
+        var serial string
+        if err := rows.Scan(&serial); err != nil {
+            return nil, err
+        }
+        existing = append(existing, serial)
+    }
+
+    return existing, rows.Err()
+}
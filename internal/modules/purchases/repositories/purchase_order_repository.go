@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
+)
+
+type PurchaseOrderRepository interface {
+	GetAll(ctx context.Context, filter *purchasemodels.PurchaseOrderFilter) ([]*purchasemodels.PurchaseOrder, error)
+	GetByID(ctx context.Context, id int) (*purchasemodels.PurchaseOrder, error)
+	GetLines(ctx context.Context, purchaseOrderID int) ([]*purchasemodels.PurchaseOrderLine, error)
+	UpdateLine(ctx context.Context, line *purchasemodels.PurchaseOrderLine) error
+	RemoveLine(ctx context.Context, purchaseOrderID, lineID int) error
+	MarkOrdered(ctx context.Context, id int, orderDate time.Time, expectedDate *time.Time) error
+	Cancel(ctx context.Context, id int) error
+	Receive(ctx context.Context, order *purchasemodels.PurchaseOrder, receipts map[int]*purchasemodels.ReceiveLine, receivedBy *string, warehouseID *int) ([]int, error)
+	ExistingSerials(ctx context.Context, itemID int, serialNumbers []string) ([]string, error)
+}
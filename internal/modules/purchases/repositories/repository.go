@@ -15,4 +15,10 @@ type PurchaseRepository interface {
 	GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*purchasemodels.Purchase, error)
 	GetSupplierPurchases(ctx context.Context, supplierID int) ([]*purchasemodels.Purchase, error)
 	GetItemPurchases(ctx context.Context, itemID int) ([]*purchasemodels.Purchase, error)
+
+	// Serial and lot tracking
+	GetItemTrackingMode(ctx context.Context, itemID int) (*string, error)
+	IsKit(ctx context.Context, itemID int) (bool, error)
+	ExistingSerials(ctx context.Context, itemID int, serialNumbers []string) ([]string, error)
+	GetTrackedUnits(ctx context.Context, purchaseID int) ([]string, []*purchasemodels.LotReceipt, error)
 }
@@ -11,12 +11,15 @@ import (
 func RegisterRoutes(api *echo.Group, database *db.Database) {
     // Initialize repository
     repo := repositories.NewPostgresPurchaseRepository(database)
+    orderRepo := repositories.NewPostgresPurchaseOrderRepository(database)
 
     // Initialize service
     service := services.NewPurchaseService(repo)
+    orderService := services.NewPurchaseOrderService(orderRepo)
 
     // Initialize handler
     handler := handlers.NewPurchaseHandler(service)
+    orderHandler := handlers.NewPurchaseOrderHandler(orderService)
 
     // Register routes
     purchases := api.Group("/purchases")
@@ -29,4 +32,14 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
     // Additional routes for supplier and item specific purchases
     api.GET("/suppliers/:supplierId/purchases", handler.GetSupplierPurchases)
     api.GET("/items/:itemId/purchases", handler.GetItemPurchases)
+
+    // Purchase order routes
+    orders := api.Group("/purchase-orders")
+    orders.GET("", orderHandler.GetPurchaseOrders)
+    orders.GET("/:id", orderHandler.GetPurchaseOrderByID)
+    orders.PUT("/:id/lines/:lineId", orderHandler.UpdatePurchaseOrderLine)
+    orders.DELETE("/:id/lines/:lineId", orderHandler.RemovePurchaseOrderLine)
+    orders.POST("/:id/submit", orderHandler.SubmitPurchaseOrder)
+    orders.POST("/:id/cancel", orderHandler.CancelPurchaseOrder)
+    orders.POST("/:id/receive", orderHandler.ReceivePurchaseOrder)
 }
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
+	"github.com/hsrvms/autoparts/internal/modules/purchases/repositories"
+)
+
+var (
+	ErrPurchaseOrderNotFound      = errors.New("purchase order not found")
+	ErrInvalidPurchaseOrderID     = errors.New("invalid purchase order ID")
+	ErrPurchaseOrderLineNotFound  = errors.New("purchase order line not found")
+	ErrPurchaseOrderNotDraft      = errors.New("only draft purchase orders can be changed")
+	ErrPurchaseOrderNotOrdered    = errors.New("only ordered purchase orders can be received")
+	ErrPurchaseOrderEmpty         = errors.New("purchase order has no lines")
+	ErrPurchaseOrderNotCancelable = errors.New("purchase order has receipts or is already closed")
+	ErrInvalidExpectedDate        = errors.New("expected date cannot be before the order date")
+	ErrReceiveExceedsOrdered      = errors.New("received quantity exceeds the quantity outstanding")
+	ErrNothingToReceive           = errors.New("nothing left to receive on this purchase order")
+)
+
+type PurchaseOrderService interface {
+	GetAll(ctx context.Context, filter *purchasemodels.PurchaseOrderFilter) ([]*purchasemodels.PurchaseOrder, error)
+	GetByID(ctx context.Context, id int) (*purchasemodels.PurchaseOrder, error)
+	UpdateLine(ctx context.Context, line *purchasemodels.PurchaseOrderLine) error
+	RemoveLine(ctx context.Context, purchaseOrderID, lineID int) error
+	Submit(ctx context.Context, id int, expectedDate *time.Time) (*purchasemodels.PurchaseOrder, error)
+	Cancel(ctx context.Context, id int) error
+	Receive(ctx context.Context, id int, req *purchasemodels.ReceivePurchaseOrderRequest) (*purchasemodels.PurchaseOrder, error)
+}
+
+type purchaseOrderService struct {
+	repo repositories.PurchaseOrderRepository
+}
+
+func NewPurchaseOrderService(repo repositories.PurchaseOrderRepository) PurchaseOrderService {
+	return &purchaseOrderService{
+		repo: repo,
+	}
+}
+
+func (s *purchaseOrderService) GetAll(ctx context.Context, filter *purchasemodels.PurchaseOrderFilter) ([]*purchasemodels.PurchaseOrder, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *purchaseOrderService) GetByID(ctx context.Context, id int) (*purchasemodels.PurchaseOrder, error) {
+	if id <= 0 {
+		return nil, ErrInvalidPurchaseOrderID
+	}
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrPurchaseOrderNotFound
+	}
+
+	order.Lines, err = s.repo.GetLines(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *purchaseOrderService) UpdateLine(ctx context.Context, line *purchasemodels.PurchaseOrderLine) error {
+	if line.Quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	if line.UnitCost < 0 {
+		return ErrInvalidCostPerUnit
+	}
+
+	order, err := s.GetByID(ctx, line.PurchaseOrderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != purchasemodels.OrderStatusDraft {
+		return ErrPurchaseOrderNotDraft
+	}
+	existing := findLine(order.Lines, line.LineID)
+	if existing == nil {
+		return ErrPurchaseOrderLineNotFound
+	}
+	if existing.IsKit {
+		return ErrItemIsKit
+	}
+
+	return s.repo.UpdateLine(ctx, line)
+}
+
+func (s *purchaseOrderService) RemoveLine(ctx context.Context, purchaseOrderID, lineID int) error {
+	order, err := s.GetByID(ctx, purchaseOrderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != purchasemodels.OrderStatusDraft {
+		return ErrPurchaseOrderNotDraft
+	}
+	if findLine(order.Lines, lineID) == nil {
+		return ErrPurchaseOrderLineNotFound
+	}
+
+	return s.repo.RemoveLine(ctx, purchaseOrderID, lineID)
+}
+
+// Submit marks a draft as sent to the supplier
+func (s *purchaseOrderService) Submit(ctx context.Context, id int, expectedDate *time.Time) (*purchasemodels.PurchaseOrder, error) {
+	order, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != purchasemodels.OrderStatusDraft {
+		return nil, ErrPurchaseOrderNotDraft
+	}
+	if len(order.Lines) == 0 {
+		return nil, ErrPurchaseOrderEmpty
+	}
+
+	orderDate := time.Now()
+	if expectedDate != nil && expectedDate.Before(orderDate.Truncate(24*time.Hour)) {
+		return nil, ErrInvalidExpectedDate
+	}
+
+	if err := s.repo.MarkOrdered(ctx, id, orderDate, expectedDate); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *purchaseOrderService) Cancel(ctx context.Context, id int) error {
+	order, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	switch order.Status {
+	case purchasemodels.OrderStatusReceived, purchasemodels.OrderStatusCancelled:
+		return ErrPurchaseOrderNotCancelable
+	}
+	for _, line := range order.Lines {
+		if line.QuantityReceived > 0 {
+			return ErrPurchaseOrderNotCancelable
+		}
+	}
+
+	return s.repo.Cancel(ctx, id)
+}
+
+// Receive books in goods against an ordered purchase order, creating a
+// purchase for each line received. Serial and lot tracked lines must list
+// their units, so they cannot be received without lines. Kit lines, which
+// can only be removed, block the receipt.
+func (s *purchaseOrderService) Receive(ctx context.Context, id int, req *purchasemodels.ReceivePurchaseOrderRequest) (*purchasemodels.PurchaseOrder, error) {
+	order, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != purchasemodels.OrderStatusOrdered {
+		return nil, ErrPurchaseOrderNotOrdered
+	}
+	if req.WarehouseID != nil && *req.WarehouseID <= 0 {
+		return nil, ErrInvalidWarehouseID
+	}
+
+	receipts := make(map[int]*purchasemodels.ReceiveLine)
+	if len(req.Lines) == 0 {
+		for _, line := range order.Lines {
+			if outstanding := line.Quantity - line.QuantityReceived; outstanding > 0 {
+				receipts[line.LineID] = &purchasemodels.ReceiveLine{LineID: line.LineID, Quantity: outstanding}
+			}
+		}
+	} else {
+		for _, received := range req.Lines {
+			line := findLine(order.Lines, received.LineID)
+			if line == nil {
+				return nil, ErrPurchaseOrderLineNotFound
+			}
+			if received.Quantity <= 0 {
+				return nil, ErrInvalidQuantity
+			}
+
+			receipt, ok := receipts[line.LineID]
+			if !ok {
+				receipt = &purchasemodels.ReceiveLine{LineID: line.LineID}
+				receipts[line.LineID] = receipt
+			}
+			receipt.Quantity += received.Quantity
+			receipt.SerialNumbers = append(receipt.SerialNumbers, received.SerialNumbers...)
+			receipt.Lots = append(receipt.Lots, received.Lots...)
+			if line.QuantityReceived+receipt.Quantity > line.Quantity {
+				return nil, ErrReceiveExceedsOrdered
+			}
+		}
+	}
+
+	if len(receipts) == 0 {
+		return nil, ErrNothingToReceive
+	}
+
+	// Serial and lot tracked lines record their units on receipt
+	for _, receipt := range receipts {
+		line := findLine(order.Lines, receipt.LineID)
+		if line.IsKit {
+			return nil, ErrItemIsKit
+		}
+		err := validateTrackedUnits(ctx, s.repo, line.TrackingMode, line.ItemID, receipt.Quantity, receipt.SerialNumbers, receipt.Lots)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.repo.Receive(ctx, order, receipts, req.ReceivedBy, req.WarehouseID); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Helper functions
+func findLine(lines []*purchasemodels.PurchaseOrderLine, lineID int) *purchasemodels.PurchaseOrderLine {
+	for _, line := range lines {
+		if line.LineID == lineID {
+			return line
+		}
+	}
+	return nil
+}
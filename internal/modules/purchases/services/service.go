@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
@@ -10,14 +11,26 @@ import (
 )
 
 var (
-	ErrPurchaseNotFound       = errors.New("purchase not found")
-	ErrInvalidPurchaseID      = errors.New("invalid purchase ID")
-	ErrInvalidSupplierID      = errors.New("invalid supplier ID")
-	ErrInvalidItemID          = errors.New("invalid item ID")
-	ErrInvalidQuantity        = errors.New("quantity must be greater than 0")
-	ErrInvalidCostPerUnit     = errors.New("cost per unit must be greater than 0")
-	ErrDuplicateInvoiceNumber = errors.New("invoice number already exists")
-	ErrInvalidDate            = errors.New("purchase date cannot be in the future")
+	ErrPurchaseNotFound           = errors.New("purchase not found")
+	ErrInvalidPurchaseID          = errors.New("invalid purchase ID")
+	ErrInvalidSupplierID          = errors.New("invalid supplier ID")
+	ErrInvalidItemID              = errors.New("invalid item ID")
+	ErrInvalidQuantity            = errors.New("quantity must be greater than 0")
+	ErrInvalidCostPerUnit         = errors.New("cost per unit must be greater than 0")
+	ErrDuplicateInvoiceNumber     = errors.New("invoice number already exists")
+	ErrInvalidDate                = errors.New("purchase date cannot be in the future")
+	ErrPurchaseInvoiced           = errors.New("purchase has been invoiced by the supplier and cannot be changed")
+	ErrInvalidWarehouseID         = errors.New("invalid warehouse ID")
+	ErrItemNotFound               = errors.New("item not found")
+	ErrItemIsKit                  = errors.New("kits hold no stock; purchase their components instead")
+	ErrSerialNumbersMismatch      = errors.New("one serial number is required per unit received")
+	ErrLotsMismatch               = errors.New("lot quantities must add up to the quantity received")
+	ErrInvalidLot                 = errors.New("each lot needs a lot number and a quantity greater than 0")
+	ErrDuplicateSerialNumber      = errors.New("serial number is already recorded for this item")
+	ErrDuplicateLotNumber         = errors.New("lot number is listed more than once")
+	ErrItemNotTracked             = errors.New("item is not serial or lot tracked")
+	ErrCostedPurchaseLocked       = errors.New("item, quantity, unit cost and warehouse of a received purchase cannot be changed")
+	ErrTrackedReceiptNotDeletable = errors.New("a receipt of serial numbered or lot tracked units cannot be deleted")
 )
 
 type PurchaseService interface {
@@ -57,6 +70,11 @@ func (s *purchaseService) GetByID(ctx context.Context, id int) (*purchasemodels.
 		return nil, ErrPurchaseNotFound
 	}
 
+	purchase.SerialNumbers, purchase.Lots, err = s.repo.GetTrackedUnits(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return purchase, nil
 }
 
@@ -77,6 +95,26 @@ func (s *purchaseService) Create(ctx context.Context, purchase *purchasemodels.P
 		}
 	}
 
+	// Serial and lot tracked items record their units on receipt
+	mode, err := s.repo.GetItemTrackingMode(ctx, purchase.ItemID)
+	if err != nil {
+		return 0, err
+	}
+	if mode == nil {
+		return 0, ErrItemNotFound
+	}
+	isKit, err := s.repo.IsKit(ctx, purchase.ItemID)
+	if err != nil {
+		return 0, err
+	}
+	if isKit {
+		return 0, ErrItemIsKit
+	}
+	err = validateTrackedUnits(ctx, s.repo, *mode, purchase.ItemID, purchase.Quantity, purchase.SerialNumbers, purchase.Lots)
+	if err != nil {
+		return 0, err
+	}
+
 	// Set date to current time if not provided
 	if purchase.Date.IsZero() {
 		purchase.Date = time.Now()
@@ -108,6 +146,17 @@ func (s *purchaseService) Update(ctx context.Context, purchase *purchasemodels.P
 	if existing == nil {
 		return ErrPurchaseNotFound
 	}
+	if existing.SupplierInvoiceID != nil {
+		return ErrPurchaseInvoiced
+	}
+
+	// The stock movement, cost layer and any serial numbers or lots were
+	// booked for the original item, quantity, cost and warehouse on receipt
+	if purchase.ItemID != existing.ItemID || purchase.Quantity != existing.Quantity ||
+		purchase.CostPerUnit != existing.CostPerUnit ||
+		(purchase.WarehouseID != nil && (existing.WarehouseID == nil || *purchase.WarehouseID != *existing.WarehouseID)) {
+		return ErrCostedPurchaseLocked
+	}
 
 	// Check if invoice number is unique if changed
 	if purchase.InvoiceNumber != nil && *purchase.InvoiceNumber != "" &&
@@ -140,6 +189,19 @@ func (s *purchaseService) Delete(ctx context.Context, id int) error {
 	if existing == nil {
 		return ErrPurchaseNotFound
 	}
+	if existing.SupplierInvoiceID != nil {
+		return ErrPurchaseInvoiced
+	}
+
+	// Deleting the receipt would drop its serial numbers or lots and leave
+	// its stock with nothing to sell it by
+	serials, lots, err := s.repo.GetTrackedUnits(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(serials) > 0 || len(lots) > 0 {
+		return ErrTrackedReceiptNotDeletable
+	}
 
 	return s.repo.Delete(ctx, id)
 }
@@ -175,5 +237,74 @@ func (s *purchaseService) validatePurchase(purchase *purchasemodels.Purchase) er
 	if !purchase.Date.IsZero() && purchase.Date.After(time.Now()) {
 		return ErrInvalidDate
 	}
+	if purchase.WarehouseID != nil && *purchase.WarehouseID <= 0 {
+		return ErrInvalidWarehouseID
+	}
+	return nil
+}
+
+type serialLookup interface {
+	ExistingSerials(ctx context.Context, itemID int, serialNumbers []string) ([]string, error)
+}
+
+// validateTrackedUnits checks the serial numbers or lots given for a receipt
+// against the item's tracking mode: one new serial number per unit, or lots
+// adding up to the quantity
+func validateTrackedUnits(ctx context.Context, lookup serialLookup, mode string, itemID, quantity int, serialNumbers []string, lots []*purchasemodels.LotReceipt) error {
+	switch mode {
+	case "serial":
+		if len(lots) > 0 || len(serialNumbers) != quantity {
+			return ErrSerialNumbersMismatch
+		}
+
+		seen := make(map[string]bool)
+		for i, serial := range serialNumbers {
+			serial = strings.TrimSpace(serial)
+			if serial == "" {
+				return ErrSerialNumbersMismatch
+			}
+			if seen[serial] {
+				return ErrDuplicateSerialNumber
+			}
+			seen[serial] = true
+			serialNumbers[i] = serial
+		}
+
+		existing, err := lookup.ExistingSerials(ctx, itemID, serialNumbers)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return ErrDuplicateSerialNumber
+		}
+
+	case "lot":
+		if len(serialNumbers) > 0 {
+			return ErrLotsMismatch
+		}
+
+		total := 0
+		seen := make(map[string]bool)
+		for _, lot := range lots {
+			lot.LotNumber = strings.TrimSpace(lot.LotNumber)
+			if lot.LotNumber == "" || lot.Quantity <= 0 {
+				return ErrInvalidLot
+			}
+			if seen[lot.LotNumber] {
+				return ErrDuplicateLotNumber
+			}
+			seen[lot.LotNumber] = true
+			total += lot.Quantity
+		}
+		if total != quantity {
+			return ErrLotsMismatch
+		}
+
+	default:
+		if len(serialNumbers) > 0 || len(lots) > 0 {
+			return ErrItemNotTracked
+		}
+	}
+
 	return nil
 }
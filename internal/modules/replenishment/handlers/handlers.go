@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	replenishmentmodels "github.com/hsrvms/autoparts/internal/modules/replenishment/models"
+	"github.com/hsrvms/autoparts/internal/modules/replenishment/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ReplenishmentHandler struct {
+	service services.ReplenishmentService
+}
+
+func NewReplenishmentHandler(service services.ReplenishmentService) *ReplenishmentHandler {
+	return &ReplenishmentHandler{
+		service: service,
+	}
+}
+
+// GetSuggestions handles computing reorder suggestions grouped by supplier
+func (h *ReplenishmentHandler) GetSuggestions(c echo.Context) error {
+	params := &replenishmentmodels.SuggestionParams{}
+
+	if lookback := c.QueryParam("lookback_days"); lookback != "" {
+		days, err := strconv.Atoi(lookback)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid lookback_days")
+		}
+		params.LookbackDays = days
+	}
+
+	if review := c.QueryParam("review_days"); review != "" {
+		days, err := strconv.Atoi(review)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid review_days")
+		}
+		params.ReviewDays = &days
+	}
+
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		if id, err := strconv.Atoi(supplierID); err == nil {
+			params.SupplierID = &id
+		}
+	}
+
+	if categoryID := c.QueryParam("category_id"); categoryID != "" {
+		if id, err := strconv.Atoi(categoryID); err == nil {
+			params.CategoryID = &id
+		}
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetSuggestions(ctx, params)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidLookbackDays, services.ErrInvalidReviewDays:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// CreatePurchaseOrders handles turning approved suggestions into draft purchase orders
+func (h *ReplenishmentHandler) CreatePurchaseOrders(c echo.Context) error {
+	req := new(replenishmentmodels.ApproveSuggestionsRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	orders, err := h.service.CreatePurchaseOrders(ctx, req)
+	if err != nil {
+		switch err {
+		case services.ErrNoLinesApproved, services.ErrInvalidItemID, services.ErrInvalidQuantity,
+			services.ErrInvalidUnitCost, services.ErrSupplierRequired:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrItemNotFound, services.ErrSupplierNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrItemInactive, services.ErrItemIsKit:
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusCreated, orders)
+}
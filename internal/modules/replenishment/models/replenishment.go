@@ -0,0 +1,95 @@
+package replenishmentmodels
+
+import "time"
+
+// ReorderSuggestion is the replenishment engine's view of a single item:
+// the raw inputs it was computed from and the quantity it recommends.
+type ReorderSuggestion struct {
+	ItemID       int     `json:"item_id" db:"item_id"`
+	PartNumber   string  `json:"part_number" db:"part_number"`
+	Description  string  `json:"description" db:"description"`
+	SupplierID   *int    `json:"supplier_id,omitempty" db:"supplier_id"`
+	SupplierName *string `json:"supplier_name,omitempty" db:"supplier_name"`
+	CurrentStock int     `json:"current_stock" db:"current_stock"`
+	MinimumStock int     `json:"minimum_stock" db:"minimum_stock"`
+	ReorderUpTo  *int    `json:"reorder_up_to,omitempty" db:"reorder_up_to"`
+	OnOrder      int     `json:"on_order" db:"on_order"`
+	SoldQuantity int     `json:"sold_quantity" db:"sold_quantity"`
+	LeadTimeDays int     `json:"lead_time_days" db:"lead_time_days"`
+	UnitCost     float64 `json:"unit_cost" db:"buy_price"`
+
+	// Computed by the service
+	DailyVelocity     float64 `json:"daily_velocity" db:"-"`
+	ReorderPoint      int     `json:"reorder_point" db:"-"`
+	TargetLevel       int     `json:"target_level" db:"-"`
+	SuggestedQuantity int     `json:"suggested_quantity" db:"-"`
+	LineTotal         float64 `json:"line_total" db:"-"`
+}
+
+type SupplierSuggestions struct {
+	SupplierID   int                  `json:"supplier_id"`
+	SupplierName string               `json:"supplier_name"`
+	LeadTimeDays int                  `json:"lead_time_days"`
+	Items        []*ReorderSuggestion `json:"items"`
+	TotalCost    float64              `json:"total_cost"`
+}
+
+type SuggestionParams struct {
+	LookbackDays int  `query:"lookback_days"`
+	ReviewDays   *int `query:"review_days"` // Defaults to 14 when omitted; 0 is valid
+	SupplierID   *int `query:"supplier_id"`
+	CategoryID   *int `query:"category_id"`
+}
+
+// SuggestionReport groups suggestions by preferred supplier. Items with no
+// supplier cannot be ordered until one is assigned and are listed apart.
+type SuggestionReport struct {
+	GeneratedAt  time.Time              `json:"generated_at"`
+	LookbackDays int                    `json:"lookback_days"`
+	ReviewDays   int                    `json:"review_days"`
+	Suppliers    []*SupplierSuggestions `json:"suppliers"`
+	Unassigned   []*ReorderSuggestion   `json:"unassigned"`
+	TotalCost    float64                `json:"total_cost"`
+}
+
+// ApproveSuggestionsRequest turns a (possibly edited) suggestion set into
+// draft purchase orders, one per supplier.
+type ApproveSuggestionsRequest struct {
+	CreatedBy *string         `json:"created_by,omitempty"`
+	Notes     *string         `json:"notes,omitempty"`
+	Lines     []*ApprovedLine `json:"lines"`
+}
+
+type ApprovedLine struct {
+	ItemID     int      `json:"item_id"`
+	Quantity   int      `json:"quantity"`
+	UnitCost   *float64 `json:"unit_cost,omitempty"`
+	SupplierID *int     `json:"supplier_id,omitempty"`
+}
+
+// OrderableItem is the item data needed to place an approved line.
+type OrderableItem struct {
+	ItemID     int     `db:"item_id"`
+	PartNumber string  `db:"part_number"`
+	SupplierID *int    `db:"supplier_id"`
+	BuyPrice   float64 `db:"buy_price"`
+	IsActive   bool    `db:"is_active"`
+	IsKit      bool    `db:"is_kit"`
+}
+
+type DraftPurchaseOrder struct {
+	PurchaseOrderID int               `json:"purchase_order_id"`
+	PONumber        string            `json:"po_number"`
+	SupplierID      int               `json:"supplier_id"`
+	SupplierName    string            `json:"supplier_name"`
+	Lines           []*DraftOrderLine `json:"lines"`
+	TotalCost       float64           `json:"total_cost"`
+}
+
+type DraftOrderLine struct {
+	ItemID     int     `json:"item_id"`
+	PartNumber string  `json:"part_number"`
+	Quantity   int     `json:"quantity"`
+	UnitCost   float64 `json:"unit_cost"`
+	LineTotal  float64 `json:"line_total"`
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	replenishmentmodels "github.com/hsrvms/autoparts/internal/modules/replenishment/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+)
+
+type PostgresReplenishmentRepository struct {
+	db *db.Database
+}
+
+func NewPostgresReplenishmentRepository(database *db.Database) ReplenishmentRepository {
+	return &PostgresReplenishmentRepository{
+		db: database,
+	}
+}
+
+// GetCandidates returns every active item with its sales over the lookback
+// window and the quantity still outstanding on draft or open purchase orders.
+// Drafts count as on order so approving suggestions twice does not double up.
+// Kits are never ordered; their sales count towards their components.
+func (r *PostgresReplenishmentRepository) GetCandidates(ctx context.Context, since time.Time, filter *replenishmentmodels.SuggestionParams) ([]*replenishmentmodels.ReorderSuggestion, error) {
+	query := `
+		SELECT
+			i.item_id, i.part_number, i.description,
+			i.supplier_id, s.name as supplier_name,
+			i.current_stock, i.minimum_stock, i.reorder_up_to,
+			COALESCE(oo.quantity, 0) as on_order,
+			COALESCE(sold.quantity, 0) as sold_quantity,
+			COALESCE(s.lead_time_days, 7) as lead_time_days,
+			i.buy_price
+		FROM items i
+		LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
+		LEFT JOIN (
+			SELECT item_id, SUM(quantity) as quantity
+			FROM item_sales_demand
+			WHERE date >= $1
+			GROUP BY item_id
+		) sold ON sold.item_id = i.item_id
+		LEFT JOIN (
+			SELECT l.item_id, SUM(l.quantity - l.quantity_received) as quantity
+			FROM purchase_order_lines l
+			JOIN purchase_orders po ON l.purchase_order_id = po.purchase_order_id
+			WHERE po.status IN ('draft', 'ordered')
+			GROUP BY l.item_id
+		) oo ON oo.item_id = i.item_id
+		WHERE i.is_active = TRUE AND NOT i.is_kit
+	`
+
+	params := []interface{}{since}
+	paramCount := 2
+
+	if filter != nil {
+		if filter.SupplierID != nil {
+			query += fmt.Sprintf(" AND i.supplier_id = $%d", paramCount)
+			params = append(params, *filter.SupplierID)
+			paramCount++
+		}
+
+		if filter.CategoryID != nil {
+			query += fmt.Sprintf(" AND i.category_id = $%d", paramCount)
+			params = append(params, *filter.CategoryID)
+			paramCount++
+		}
+	}
+
+	query += " ORDER BY s.name NULLS LAST, i.part_number"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*replenishmentmodels.ReorderSuggestion
+	for rows.Next() {
+		c := &replenishmentmodels.ReorderSuggestion{}
+		err := rows.Scan(
+			&c.ItemID,
+			&c.PartNumber,
+			&c.Description,
+			&c.SupplierID,
+			&c.SupplierName,
+			&c.CurrentStock,
+			&c.MinimumStock,
+			&c.ReorderUpTo,
+			&c.OnOrder,
+			&c.SoldQuantity,
+			&c.LeadTimeDays,
+			&c.UnitCost,
+		)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+func (r *PostgresReplenishmentRepository) GetOrderableItems(ctx context.Context, itemIDs []int) (map[int]*replenishmentmodels.OrderableItem, error) {
+	query := `
+		SELECT item_id, part_number, supplier_id, buy_price, COALESCE(is_active, TRUE), is_kit
+		FROM items
+		WHERE item_id = ANY($1)
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make(map[int]*replenishmentmodels.OrderableItem)
+	for rows.Next() {
+		item := &replenishmentmodels.OrderableItem{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.SupplierID,
+			&item.BuyPrice,
+			&item.IsActive,
+			&item.IsKit,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items[item.ItemID] = item
+	}
+
+	return items, rows.Err()
+}
+
+func (r *PostgresReplenishmentRepository) GetSupplierNames(ctx context.Context, supplierIDs []int) (map[int]string, error) {
+	rows, err := r.db.Pool.Query(ctx, `SELECT supplier_id, name FROM suppliers WHERE supplier_id = ANY($1)`, supplierIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+
+	return names, rows.Err()
+}
+
+// CreateDraftOrders writes all orders in a single transaction and fills in
+// the generated IDs and PO numbers.
+func (r *PostgresReplenishmentRepository) CreateDraftOrders(ctx context.Context, orders []*replenishmentmodels.DraftPurchaseOrder, createdBy, notes *string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, order := range orders {
+		err := tx.QueryRow(ctx, `SELECT nextval('purchase_order_id_seq')`).Scan(&order.PurchaseOrderID)
+		if err != nil {
+			return err
+		}
+		order.PONumber = fmt.Sprintf("PO-%06d", order.PurchaseOrderID)
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO purchase_orders (
+				purchase_order_id, po_number, supplier_id, status, notes, created_by
+			) VALUES ($1, $2, $3, 'draft', $4, $5)
+		`, order.PurchaseOrderID, order.PONumber, order.SupplierID, notes, createdBy)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range order.Lines {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO purchase_order_lines (
+					purchase_order_id, item_id, quantity, unit_cost
+				) VALUES ($1, $2, $3, $4::numeric)
+			`, order.PurchaseOrderID, line.ItemID, line.Quantity, line.UnitCost)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	replenishmentmodels "github.com/hsrvms/autoparts/internal/modules/replenishment/models"
+)
+
+type ReplenishmentRepository interface {
+	GetCandidates(ctx context.Context, since time.Time, filter *replenishmentmodels.SuggestionParams) ([]*replenishmentmodels.ReorderSuggestion, error)
+	GetOrderableItems(ctx context.Context, itemIDs []int) (map[int]*replenishmentmodels.OrderableItem, error)
+	GetSupplierNames(ctx context.Context, supplierIDs []int) (map[int]string, error)
+	CreateDraftOrders(ctx context.Context, orders []*replenishmentmodels.DraftPurchaseOrder, createdBy, notes *string) error
+}
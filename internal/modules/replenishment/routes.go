@@ -0,0 +1,25 @@
+package replenishment
+
+import (
+	"github.com/hsrvms/autoparts/internal/modules/replenishment/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/replenishment/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/replenishment/services"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repository
+	repo := repositories.NewPostgresReplenishmentRepository(database)
+
+	// Initialize service
+	service := services.NewReplenishmentService(repo)
+
+	// Initialize handler
+	handler := handlers.NewReplenishmentHandler(service)
+
+	// Register routes
+	replenishment := api.Group("/replenishment")
+	replenishment.GET("/suggestions", handler.GetSuggestions)
+	replenishment.POST("/purchase-orders", handler.CreatePurchaseOrders)
+}
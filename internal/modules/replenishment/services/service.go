@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	replenishmentmodels "github.com/hsrvms/autoparts/internal/modules/replenishment/models"
+	"github.com/hsrvms/autoparts/internal/modules/replenishment/repositories"
+)
+
+// Defaults used when the caller does not supply them
+const (
+	DefaultLookbackDays = 90
+	DefaultReviewDays   = 14
+	maxLookbackDays     = 730
+	maxReviewDays       = 365
+)
+
+var (
+	ErrInvalidLookbackDays = errors.New("lookback days must be between 1 and 730")
+	ErrInvalidReviewDays   = errors.New("review days must be between 0 and 365")
+	ErrNoLinesApproved     = errors.New("at least one line is required")
+	ErrInvalidItemID       = errors.New("invalid item ID")
+	ErrInvalidQuantity     = errors.New("quantity must be greater than 0")
+	ErrInvalidUnitCost     = errors.New("unit cost cannot be negative")
+	ErrItemNotFound        = errors.New("item not found")
+	ErrItemInactive        = errors.New("item is not active")
+	ErrItemIsKit           = errors.New("kits are not ordered; order their components instead")
+	ErrSupplierRequired    = errors.New("item has no preferred supplier; supplier_id is required")
+	ErrSupplierNotFound    = errors.New("supplier not found")
+)
+
+type ReplenishmentService interface {
+	GetSuggestions(ctx context.Context, params *replenishmentmodels.SuggestionParams) (*replenishmentmodels.SuggestionReport, error)
+	CreatePurchaseOrders(ctx context.Context, req *replenishmentmodels.ApproveSuggestionsRequest) ([]*replenishmentmodels.DraftPurchaseOrder, error)
+}
+
+type replenishmentService struct {
+	repo repositories.ReplenishmentRepository
+}
+
+func NewReplenishmentService(repo repositories.ReplenishmentRepository) ReplenishmentService {
+	return &replenishmentService{
+		repo: repo,
+	}
+}
+
+// GetSuggestions works out what to order and from whom. For each item the
+// daily sales velocity over the lookback window drives two levels:
+//
+//	reorder point = velocity * lead time + minimum stock
+//	target level  = reorder_up_to, or velocity * (lead time + review days) + minimum stock
+//
+// An item is suggested when its stock position (on hand plus on order) is at
+// or below the reorder point, and the suggestion brings it up to the target.
+func (s *replenishmentService) GetSuggestions(ctx context.Context, params *replenishmentmodels.SuggestionParams) (*replenishmentmodels.SuggestionReport, error) {
+	if params == nil {
+		params = &replenishmentmodels.SuggestionParams{}
+	}
+	if params.LookbackDays == 0 {
+		params.LookbackDays = DefaultLookbackDays
+	}
+	if params.ReviewDays == nil {
+		reviewDays := DefaultReviewDays
+		params.ReviewDays = &reviewDays
+	}
+	if params.LookbackDays < 1 || params.LookbackDays > maxLookbackDays {
+		return nil, ErrInvalidLookbackDays
+	}
+	if *params.ReviewDays < 0 || *params.ReviewDays > maxReviewDays {
+		return nil, ErrInvalidReviewDays
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -params.LookbackDays)
+
+	candidates, err := s.repo.GetCandidates(ctx, since, params)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &replenishmentmodels.SuggestionReport{
+		GeneratedAt:  now,
+		LookbackDays: params.LookbackDays,
+		ReviewDays:   *params.ReviewDays,
+		Suppliers:    []*replenishmentmodels.SupplierSuggestions{},
+		Unassigned:   []*replenishmentmodels.ReorderSuggestion{},
+	}
+
+	bySupplier := make(map[int]*replenishmentmodels.SupplierSuggestions)
+	for _, c := range candidates {
+		if !suggest(c, params.LookbackDays, *params.ReviewDays) {
+			continue
+		}
+
+		if c.SupplierID == nil {
+			report.Unassigned = append(report.Unassigned, c)
+			continue
+		}
+
+		group, ok := bySupplier[*c.SupplierID]
+		if !ok {
+			group = &replenishmentmodels.SupplierSuggestions{
+				SupplierID:   *c.SupplierID,
+				SupplierName: stringValue(c.SupplierName),
+				LeadTimeDays: c.LeadTimeDays,
+			}
+			bySupplier[*c.SupplierID] = group
+			report.Suppliers = append(report.Suppliers, group)
+		}
+		group.Items = append(group.Items, c)
+		group.TotalCost = roundCents(group.TotalCost + c.LineTotal)
+		report.TotalCost = roundCents(report.TotalCost + c.LineTotal)
+	}
+
+	return report, nil
+}
+
+// CreatePurchaseOrders creates one draft purchase order per supplier from the
+// approved lines. Lines default to the item's preferred supplier and current
+// buy price; repeated items for the same supplier are combined.
+func (s *replenishmentService) CreatePurchaseOrders(ctx context.Context, req *replenishmentmodels.ApproveSuggestionsRequest) ([]*replenishmentmodels.DraftPurchaseOrder, error) {
+	if req == nil || len(req.Lines) == 0 {
+		return nil, ErrNoLinesApproved
+	}
+
+	itemIDs := make([]int, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		if line.ItemID <= 0 {
+			return nil, ErrInvalidItemID
+		}
+		if line.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		if line.UnitCost != nil && *line.UnitCost < 0 {
+			return nil, ErrInvalidUnitCost
+		}
+		itemIDs = append(itemIDs, line.ItemID)
+	}
+
+	items, err := s.repo.GetOrderableItems(ctx, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type orderKey struct{ supplierID, itemID int }
+	lines := make(map[orderKey]*replenishmentmodels.DraftOrderLine)
+	var keys []orderKey
+	var supplierIDs []int
+	seenSupplier := make(map[int]bool)
+
+	for _, line := range req.Lines {
+		item, ok := items[line.ItemID]
+		if !ok {
+			return nil, ErrItemNotFound
+		}
+		if !item.IsActive {
+			return nil, ErrItemInactive
+		}
+		if item.IsKit {
+			return nil, ErrItemIsKit
+		}
+
+		supplierID := item.SupplierID
+		if line.SupplierID != nil {
+			supplierID = line.SupplierID
+		}
+		if supplierID == nil {
+			return nil, ErrSupplierRequired
+		}
+
+		unitCost := item.BuyPrice
+		if line.UnitCost != nil {
+			unitCost = *line.UnitCost
+		}
+
+		key := orderKey{*supplierID, line.ItemID}
+		if existing, ok := lines[key]; ok {
+			existing.Quantity += line.Quantity
+			existing.UnitCost = roundCents(unitCost)
+			continue
+		}
+
+		lines[key] = &replenishmentmodels.DraftOrderLine{
+			ItemID:     line.ItemID,
+			PartNumber: item.PartNumber,
+			Quantity:   line.Quantity,
+			UnitCost:   roundCents(unitCost),
+		}
+		keys = append(keys, key)
+
+		if !seenSupplier[*supplierID] {
+			seenSupplier[*supplierID] = true
+			supplierIDs = append(supplierIDs, *supplierID)
+		}
+	}
+
+	names, err := s.repo.GetSupplierNames(ctx, supplierIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make(map[int]*replenishmentmodels.DraftPurchaseOrder)
+	for _, id := range supplierIDs {
+		name, ok := names[id]
+		if !ok {
+			return nil, ErrSupplierNotFound
+		}
+		orders[id] = &replenishmentmodels.DraftPurchaseOrder{
+			SupplierID:   id,
+			SupplierName: name,
+		}
+	}
+
+	for _, key := range keys {
+		line := lines[key]
+		line.LineTotal = roundCents(float64(line.Quantity) * line.UnitCost)
+
+		order := orders[key.supplierID]
+		order.Lines = append(order.Lines, line)
+		order.TotalCost = roundCents(order.TotalCost + line.LineTotal)
+	}
+
+	result := make([]*replenishmentmodels.DraftPurchaseOrder, 0, len(orders))
+	for _, id := range supplierIDs {
+		result = append(result, orders[id])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].SupplierName < result[j].SupplierName
+	})
+
+	if err := s.repo.CreateDraftOrders(ctx, result, req.CreatedBy, req.Notes); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Helper functions
+
+// suggest fills in the computed fields of a candidate and reports whether it
+// needs reordering.
+func suggest(c *replenishmentmodels.ReorderSuggestion, lookbackDays, reviewDays int) bool {
+	c.DailyVelocity = float64(c.SoldQuantity) / float64(lookbackDays)
+	c.ReorderPoint = int(math.Ceil(c.DailyVelocity*float64(c.LeadTimeDays))) + c.MinimumStock
+
+	if c.ReorderUpTo != nil {
+		c.TargetLevel = *c.ReorderUpTo
+	} else {
+		c.TargetLevel = int(math.Ceil(c.DailyVelocity*float64(c.LeadTimeDays+reviewDays))) + c.MinimumStock
+	}
+
+	position := c.CurrentStock + c.OnOrder
+	if position > c.ReorderPoint {
+		return false
+	}
+
+	c.SuggestedQuantity = c.TargetLevel - position
+	if c.SuggestedQuantity <= 0 {
+		c.SuggestedQuantity = 0
+		return false
+	}
+
+	c.LineTotal = roundCents(float64(c.SuggestedQuantity) * c.UnitCost)
+	return true
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	reportmodels "github.com/hsrvms/autoparts/internal/modules/reports/models"
+	"github.com/hsrvms/autoparts/internal/modules/reports/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ReportHandler struct {
+	service services.ReportService
+}
+
+func NewReportHandler(service services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		service: service,
+	}
+}
+
+// GetMarginReport handles the gross margin report as JSON or, with
+// format=csv, as a downloadable CSV file
+func (h *ReportHandler) GetMarginReport(c echo.Context) error {
+	filter := &reportmodels.MarginFilter{
+		GroupBy: c.QueryParam("group_by"),
+		Period:  c.QueryParam("period"),
+		Method:  c.QueryParam("method"),
+	}
+
+	// Allow /reports/margins/:groupBy as a shorthand
+	if groupBy := c.Param("groupBy"); groupBy != "" {
+		filter.GroupBy = groupBy
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		date, err := parseDate(startDate)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid start_date")
+		}
+		filter.StartDate = &date
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		date, err := parseDate(endDate)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid end_date")
+		}
+		// A bare date includes the whole day
+		if len(endDate) == len("2006-01-02") {
+			date = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		filter.EndDate = &date
+	}
+
+	if categoryID := c.QueryParam("category_id"); categoryID != "" {
+		if id, err := strconv.Atoi(categoryID); err == nil {
+			filter.CategoryID = &id
+		}
+	}
+
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		if id, err := strconv.Atoi(supplierID); err == nil {
+			filter.SupplierID = &id
+		}
+	}
+
+	if soldBy := c.QueryParam("sold_by"); soldBy != "" {
+		filter.SoldBy = &soldBy
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetMarginReport(ctx, filter)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidGroupBy, services.ErrInvalidPeriod,
+			services.ErrInvalidMethod, services.ErrInvalidDateRange:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	if c.QueryParam("format") == "csv" {
+		return writeMarginCSV(c, report)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// writeMarginCSV streams the report as CSV, ending with a totals line
+func writeMarginCSV(c echo.Context, report *reportmodels.MarginReport) error {
+	filename := fmt.Sprintf("margins-by-%s-%s-to-%s.csv",
+		report.GroupBy,
+		report.StartDate.Format("20060102"),
+		report.EndDate.Format("20060102"),
+	)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	res.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(res)
+	w.Write([]string{
+		report.GroupBy, "sale_count", "quantity", "revenue",
+		"cost_" + report.Method, "gross_profit", "margin_percent",
+	})
+
+	for _, row := range append(report.Rows, report.Totals) {
+		label := row.Label
+		for i := 0; i < row.Depth; i++ {
+			label = "  " + label
+		}
+		w.Write([]string{
+			label,
+			strconv.Itoa(row.SaleCount),
+			strconv.Itoa(row.Quantity),
+			formatAmount(row.Revenue),
+			formatAmount(row.Cost),
+			formatAmount(row.GrossProfit),
+			formatAmount(row.MarginPercent),
+		})
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,69 @@
+package reportmodels
+
+import "time"
+
+// Margin report groupings
+const (
+	GroupByItem        = "item"
+	GroupByCategory    = "category"
+	GroupBySupplier    = "supplier"
+	GroupBySalesperson = "salesperson"
+	GroupByPeriod      = "period"
+)
+
+// Period lengths for GroupByPeriod
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// Costing methods, matching the COGS columns recorded on each sale
+const (
+	MethodFIFO    = "fifo"
+	MethodAverage = "average"
+)
+
+type MarginFilter struct {
+	StartDate  *time.Time `query:"start_date"`
+	EndDate    *time.Time `query:"end_date"`
+	GroupBy    string     `query:"group_by"`
+	Period     string     `query:"period"`
+	Method     string     `query:"method"`
+	CategoryID *int       `query:"category_id"`
+	SupplierID *int       `query:"supplier_id"`
+	SoldBy     *string    `query:"sold_by"`
+}
+
+// MarginRow is one line of a margin report. For category reports the figures
+// include every subcategory beneath it.
+type MarginRow struct {
+	ID            *int       `json:"id,omitempty"`
+	Label         string     `json:"label"`
+	ParentID      *int       `json:"parent_id,omitempty"`
+	Depth         int        `json:"depth,omitempty"`
+	PeriodStart   *time.Time `json:"period_start,omitempty"`
+	SaleCount     int        `json:"sale_count"`
+	Quantity      int        `json:"quantity"`
+	Revenue       float64    `json:"revenue"`
+	Cost          float64    `json:"cost"`
+	GrossProfit   float64    `json:"gross_profit"`
+	MarginPercent float64    `json:"margin_percent"`
+}
+
+type MarginReport struct {
+	StartDate time.Time    `json:"start_date"`
+	EndDate   time.Time    `json:"end_date"`
+	GroupBy   string       `json:"group_by"`
+	Period    string       `json:"period,omitempty"`
+	Method    string       `json:"method"`
+	Rows      []*MarginRow `json:"rows"`
+	Totals    *MarginRow   `json:"totals"`
+}
+
+// CategoryNode is a category with its parent, used to roll figures up the tree
+type CategoryNode struct {
+	CategoryID       int    `db:"category_id"`
+	CategoryName     string `db:"category_name"`
+	ParentCategoryID *int   `db:"parent_category_id"`
+}
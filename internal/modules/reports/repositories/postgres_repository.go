@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	reportmodels "github.com/hsrvms/autoparts/internal/modules/reports/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+)
+
+type PostgresReportRepository struct {
+	db *db.Database
+}
+
+func NewPostgresReportRepository(database *db.Database) ReportRepository {
+	return &PostgresReportRepository{
+		db: database,
+	}
+}
+
+// Grouping key and label for each report grouping
+var marginGroups = map[string][2]string{
+	reportmodels.GroupByItem:        {"i.item_id", "i.part_number || ' - ' || i.description"},
+	reportmodels.GroupByCategory:    {"i.category_id", "COALESCE(c.category_name, 'Uncategorized')"},
+	reportmodels.GroupBySupplier:    {"i.supplier_id", "COALESCE(sp.name, 'No supplier')"},
+	reportmodels.GroupBySalesperson: {"NULL::int", "COALESCE(NULLIF(s.sold_by, ''), 'Unassigned')"},
+	reportmodels.GroupByPeriod:      {"NULL::int", "''"},
+}
+
+// GetMargins totals revenue and cost of goods sold for the filter's grouping.
+// Cost comes from the COGS recorded on each sale for the chosen method, with
+// the item's buy price as a fallback for sales recorded before costing was
+// tracked. The filter must already be validated.
+func (r *PostgresReportRepository) GetMargins(ctx context.Context, filter *reportmodels.MarginFilter) ([]*reportmodels.MarginRow, error) {
+	group, ok := marginGroups[filter.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported grouping %q", filter.GroupBy)
+	}
+
+	costColumn := "s.cogs_fifo"
+	if filter.Method == reportmodels.MethodAverage {
+		costColumn = "s.cogs_average"
+	}
+
+	periodExpr := "NULL::timestamptz"
+	if filter.GroupBy == reportmodels.GroupByPeriod {
+		switch filter.Period {
+		case reportmodels.PeriodDay, reportmodels.PeriodWeek, reportmodels.PeriodMonth:
+			periodExpr = fmt.Sprintf("date_trunc('%s', s.date)", filter.Period)
+		default:
+			return nil, fmt.Errorf("unsupported period %q", filter.Period)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s as id,
+			%s as label,
+			%s as period_start,
+			COUNT(*) as sale_count,
+			SUM(s.quantity) as quantity,
+			SUM(s.total_price) as revenue,
+			SUM(COALESCE(%s, s.quantity * i.buy_price)) as cost
+		FROM sales s
+		JOIN items i ON s.item_id = i.item_id
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		LEFT JOIN suppliers sp ON i.supplier_id = sp.supplier_id
+		WHERE s.date >= $1 AND s.date <= $2
+	`, group[0], group[1], periodExpr, costColumn)
+
+	params := []interface{}{filter.StartDate, filter.EndDate}
+	paramCount := 3
+
+	if filter.CategoryID != nil {
+		query += fmt.Sprintf(`
+			AND i.category_id IN (
+				WITH RECURSIVE tree AS (
+					SELECT category_id FROM categories WHERE category_id = $%d
+					UNION ALL
+					SELECT ch.category_id FROM categories ch
+					JOIN tree t ON ch.parent_category_id = t.category_id
+				)
+				SELECT category_id FROM tree
+			)`, paramCount)
+		params = append(params, *filter.CategoryID)
+		paramCount++
+	}
+
+	if filter.SupplierID != nil {
+		query += fmt.Sprintf(" AND i.supplier_id = $%d", paramCount)
+		params = append(params, *filter.SupplierID)
+		paramCount++
+	}
+
+	if filter.SoldBy != nil {
+		query += fmt.Sprintf(" AND s.sold_by ILIKE $%d", paramCount)
+		params = append(params, "%"+*filter.SoldBy+"%")
+		paramCount++
+	}
+
+	query += " GROUP BY 1, 2, 3 ORDER BY 3, 6 DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*reportmodels.MarginRow
+	for rows.Next() {
+		row := &reportmodels.MarginRow{}
+		err := rows.Scan(
+			&row.ID,
+			&row.Label,
+			&row.PeriodStart,
+			&row.SaleCount,
+			&row.Quantity,
+			&row.Revenue,
+			&row.Cost,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, row)
+	}
+
+	return results, rows.Err()
+}
+
+func (r *PostgresReportRepository) GetCategories(ctx context.Context) ([]*reportmodels.CategoryNode, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT category_id, category_name, parent_category_id
+		FROM categories
+		ORDER BY category_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*reportmodels.CategoryNode
+	for rows.Next() {
+		node := &reportmodels.CategoryNode{}
+		if err := rows.Scan(&node.CategoryID, &node.CategoryName, &node.ParentCategoryID); err != nil {
+			return nil, err
+		}
+		categories = append(categories, node)
+	}
+
+	return categories, rows.Err()
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	reportmodels "github.com/hsrvms/autoparts/internal/modules/reports/models"
+)
+
+type ReportRepository interface {
+	GetMargins(ctx context.Context, filter *reportmodels.MarginFilter) ([]*reportmodels.MarginRow, error)
+	GetCategories(ctx context.Context) ([]*reportmodels.CategoryNode, error)
+}
@@ -0,0 +1,26 @@
+package reports
+
+import (
+	"github.com/hsrvms/autoparts/internal/modules/reports/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/reports/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/reports/services"
+	"github.com/hsrvms/autoparts/pkg/config"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database, cfg config.InventoryConfig) {
+	// Initialize repository
+	repo := repositories.NewPostgresReportRepository(database)
+
+	// Initialize service
+	service := services.NewReportService(repo, cfg.CostingMethod)
+
+	// Initialize handler
+	handler := handlers.NewReportHandler(service)
+
+	// Register routes
+	reports := api.Group("/reports")
+	reports.GET("/margins", handler.GetMarginReport)
+	reports.GET("/margins/:groupBy", handler.GetMarginReport)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	reportmodels "github.com/hsrvms/autoparts/internal/modules/reports/models"
+	"github.com/hsrvms/autoparts/internal/modules/reports/repositories"
+)
+
+// DefaultReportDays is the report window when no start date is given
+const DefaultReportDays = 30
+
+var (
+	ErrInvalidGroupBy   = errors.New("group_by must be one of item, category, supplier, salesperson or period")
+	ErrInvalidPeriod    = errors.New("period must be day, week or month")
+	ErrInvalidMethod    = errors.New("costing method must be 'fifo' or 'average'")
+	ErrInvalidDateRange = errors.New("start date must be before end date")
+)
+
+type ReportService interface {
+	GetMarginReport(ctx context.Context, filter *reportmodels.MarginFilter) (*reportmodels.MarginReport, error)
+}
+
+type reportService struct {
+	repo          repositories.ReportRepository
+	defaultMethod string
+}
+
+func NewReportService(repo repositories.ReportRepository, defaultMethod string) ReportService {
+	if defaultMethod != reportmodels.MethodAverage {
+		defaultMethod = reportmodels.MethodFIFO
+	}
+
+	return &reportService{
+		repo:          repo,
+		defaultMethod: defaultMethod,
+	}
+}
+
+// GetMarginReport compares realised sale prices with cost of goods sold for
+// the chosen grouping. Category figures are rolled up the category tree, so
+// a parent includes everything sold in its subcategories.
+func (s *reportService) GetMarginReport(ctx context.Context, filter *reportmodels.MarginFilter) (*reportmodels.MarginReport, error) {
+	if filter == nil {
+		filter = &reportmodels.MarginFilter{}
+	}
+	if err := s.normalizeFilter(filter); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.GetMargins(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &reportmodels.MarginReport{
+		StartDate: *filter.StartDate,
+		EndDate:   *filter.EndDate,
+		GroupBy:   filter.GroupBy,
+		Method:    filter.Method,
+		Totals:    &reportmodels.MarginRow{Label: "Total"},
+	}
+	if filter.GroupBy == reportmodels.GroupByPeriod {
+		report.Period = filter.Period
+	}
+
+	for _, row := range rows {
+		addTo(report.Totals, row)
+	}
+	finalize(report.Totals)
+
+	switch filter.GroupBy {
+	case reportmodels.GroupByCategory:
+		categories, err := s.repo.GetCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		report.Rows = rollUpCategories(rows, categories)
+	case reportmodels.GroupByPeriod:
+		for _, row := range rows {
+			row.Label = periodLabel(row.PeriodStart, filter.Period)
+		}
+		report.Rows = rows
+	default:
+		report.Rows = rows
+	}
+
+	if report.Rows == nil {
+		report.Rows = []*reportmodels.MarginRow{}
+	}
+	for _, row := range report.Rows {
+		finalize(row)
+	}
+
+	return report, nil
+}
+
+// Helper functions
+
+func (s *reportService) normalizeFilter(filter *reportmodels.MarginFilter) error {
+	if filter.GroupBy == "" {
+		filter.GroupBy = reportmodels.GroupByItem
+	}
+	switch filter.GroupBy {
+	case reportmodels.GroupByItem, reportmodels.GroupByCategory, reportmodels.GroupBySupplier,
+		reportmodels.GroupBySalesperson, reportmodels.GroupByPeriod:
+	default:
+		return ErrInvalidGroupBy
+	}
+
+	if filter.Period == "" {
+		filter.Period = reportmodels.PeriodMonth
+	}
+	switch filter.Period {
+	case reportmodels.PeriodDay, reportmodels.PeriodWeek, reportmodels.PeriodMonth:
+	default:
+		return ErrInvalidPeriod
+	}
+
+	if filter.Method == "" {
+		filter.Method = s.defaultMethod
+	}
+	if filter.Method != reportmodels.MethodFIFO && filter.Method != reportmodels.MethodAverage {
+		return ErrInvalidMethod
+	}
+
+	if filter.EndDate == nil {
+		end := time.Now()
+		filter.EndDate = &end
+	}
+	if filter.StartDate == nil {
+		start := filter.EndDate.AddDate(0, 0, -DefaultReportDays)
+		filter.StartDate = &start
+	}
+	if !filter.StartDate.Before(*filter.EndDate) {
+		return ErrInvalidDateRange
+	}
+
+	return nil
+}
+
+// rollUpCategories turns per-category totals into a tree-ordered list where
+// each category carries the totals of its whole subtree. Categories with no
+// sales anywhere beneath them are left out; uncategorised sales come last.
+func rollUpCategories(rows []*reportmodels.MarginRow, categories []*reportmodels.CategoryNode) []*reportmodels.MarginRow {
+	own := make(map[int]*reportmodels.MarginRow)
+	var uncategorized *reportmodels.MarginRow
+	for _, row := range rows {
+		if row.ID == nil {
+			uncategorized = row
+			continue
+		}
+		own[*row.ID] = row
+	}
+
+	children := make(map[int][]*reportmodels.CategoryNode)
+	var roots []*reportmodels.CategoryNode
+	for _, node := range categories {
+		if node.ParentCategoryID == nil {
+			roots = append(roots, node)
+		} else {
+			children[*node.ParentCategoryID] = append(children[*node.ParentCategoryID], node)
+		}
+	}
+
+	var result []*reportmodels.MarginRow
+	var visit func(node *reportmodels.CategoryNode, depth int, seen map[int]bool) *reportmodels.MarginRow
+	visit = func(node *reportmodels.CategoryNode, depth int, seen map[int]bool) *reportmodels.MarginRow {
+		if seen[node.CategoryID] {
+			return nil
+		}
+		seen[node.CategoryID] = true
+
+		id := node.CategoryID
+		total := &reportmodels.MarginRow{
+			ID:       &id,
+			Label:    node.CategoryName,
+			ParentID: node.ParentCategoryID,
+			Depth:    depth,
+		}
+		if row, ok := own[id]; ok {
+			addTo(total, row)
+		}
+
+		// Reserve this category's place before its children are appended
+		position := len(result)
+		result = append(result, total)
+
+		for _, child := range children[id] {
+			if sub := visit(child, depth+1, seen); sub != nil {
+				addTo(total, sub)
+			}
+		}
+
+		if total.SaleCount == 0 {
+			result = append(result[:position], result[position+1:]...)
+		}
+		return total
+	}
+
+	seen := make(map[int]bool)
+	for _, root := range roots {
+		visit(root, 0, seen)
+	}
+
+	if uncategorized != nil {
+		uncategorized.Label = "Uncategorized"
+		result = append(result, uncategorized)
+	}
+
+	return result
+}
+
+func addTo(total, row *reportmodels.MarginRow) {
+	total.SaleCount += row.SaleCount
+	total.Quantity += row.Quantity
+	total.Revenue += row.Revenue
+	total.Cost += row.Cost
+}
+
+func finalize(row *reportmodels.MarginRow) {
+	row.Revenue = roundCents(row.Revenue)
+	row.Cost = roundCents(row.Cost)
+	row.GrossProfit = roundCents(row.Revenue - row.Cost)
+	if row.Revenue != 0 {
+		row.MarginPercent = roundCents(row.GrossProfit / row.Revenue * 100)
+	}
+}
+
+func periodLabel(start *time.Time, period string) string {
+	if start == nil {
+		return ""
+	}
+	switch period {
+	case reportmodels.PeriodMonth:
+		return start.Format("2006-01")
+	case reportmodels.PeriodWeek:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return start.Format("2006-01-02")
+	}
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
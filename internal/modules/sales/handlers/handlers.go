@@ -64,6 +64,38 @@ func (h *SaleHandler) GetSales(c echo.Context) error {
 		filter.SoldBy = &soldBy
 	}
 
+	if customerID := c.QueryParam("customer_id"); customerID != "" {
+		if id, err := strconv.Atoi(customerID); err == nil {
+			filter.CustomerID = &id
+		}
+	}
+
+	if paymentMethod := c.QueryParam("payment_method"); paymentMethod != "" {
+		filter.PaymentMethod = &paymentMethod
+	}
+
+	if paymentStatus := c.QueryParam("payment_status"); paymentStatus != "" {
+		filter.PaymentStatus = &paymentStatus
+	}
+
+	if invoiceID := c.QueryParam("invoice_id"); invoiceID != "" {
+		if id, err := strconv.Atoi(invoiceID); err == nil {
+			filter.InvoiceID = &id
+		}
+	}
+
+	if uninvoiced := c.QueryParam("uninvoiced"); uninvoiced != "" {
+		if value, err := strconv.ParseBool(uninvoiced); err == nil {
+			filter.Uninvoiced = &value
+		}
+	}
+
+	if vehicleID := c.QueryParam("customer_vehicle_id"); vehicleID != "" {
+		if id, err := strconv.Atoi(vehicleID); err == nil {
+			filter.CustomerVehicleID = &id
+		}
+	}
+
 	ctx := c.Request().Context()
 	sales, err := h.service.GetAll(ctx, filter)
 	if err != nil {
@@ -107,11 +139,21 @@ func (h *SaleHandler) CreateSale(c echo.Context) error {
 		switch err {
 		case services.ErrInvalidItemID, services.ErrInvalidQuantity,
 			services.ErrInvalidPricePerUnit, services.ErrInvalidDate,
-			services.ErrInvalidCustomerEmail:
+			services.ErrInvalidCustomerEmail, services.ErrInvalidPaymentMethod,
+			services.ErrCustomerRequired, services.ErrCustomerNotFound,
+			services.ErrWarehouseNotFound, services.ErrReservationMismatch,
+			services.ErrSerialNumbersRequired, services.ErrLotsRequired,
+			services.ErrItemNotTracked, services.ErrCustomerVehicleNotFound,
+			services.ErrVehicleNotCustomers, services.ErrInvalidVehicleMileage:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-		case services.ErrDuplicateTransactionNumber:
+		case services.ErrReservationNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrDuplicateTransactionNumber, services.ErrSaleInvoiced,
+			services.ErrReservationNotActive, services.ErrSpecialOrderNotReady:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
-		case services.ErrInsufficientStock:
+		case services.ErrInsufficientStock, services.ErrCreditLimitExceeded,
+			services.ErrCustomerInactive, services.ErrSerialNotInStock,
+			services.ErrLotNotAvailable:
 			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -143,11 +185,16 @@ func (h *SaleHandler) UpdateSale(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
 		case services.ErrInvalidItemID, services.ErrInvalidQuantity,
 			services.ErrInvalidPricePerUnit, services.ErrInvalidDate,
-			services.ErrInvalidCustomerEmail:
+			services.ErrInvalidCustomerEmail, services.ErrInvalidPaymentMethod,
+			services.ErrCustomerRequired, services.ErrCustomerNotFound,
+			services.ErrCustomerVehicleNotFound, services.ErrVehicleNotCustomers,
+			services.ErrInvalidVehicleMileage:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-		case services.ErrDuplicateTransactionNumber:
+		case services.ErrDuplicateTransactionNumber, services.ErrSaleInvoiced,
+			services.ErrCostedSaleLocked:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
-		case services.ErrInsufficientStock:
+		case services.ErrInsufficientStock, services.ErrCreditLimitExceeded,
+			services.ErrCustomerInactive:
 			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -170,6 +217,9 @@ func (h *SaleHandler) DeleteSale(c echo.Context) error {
 		switch err {
 		case services.ErrSaleNotFound:
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrSaleInvoiced, services.ErrCoresReturned, services.ErrTrackedSaleNotDeletable,
+			services.ErrSaleHasClaims:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
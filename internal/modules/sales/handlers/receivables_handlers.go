@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+	"github.com/hsrvms/autoparts/internal/modules/sales/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ReceivablesHandler struct {
+	service services.ReceivablesService
+}
+
+func NewReceivablesHandler(service services.ReceivablesService) *ReceivablesHandler {
+	return &ReceivablesHandler{
+		service: service,
+	}
+}
+
+// GetInvoices handles retrieval of invoices with optional filtering
+func (h *ReceivablesHandler) GetInvoices(c echo.Context) error {
+	filter := &salesmodels.InvoiceFilter{}
+
+	if customerID := c.QueryParam("customer_id"); customerID != "" {
+		if id, err := strconv.Atoi(customerID); err == nil {
+			filter.CustomerID = &id
+		}
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := parseDate(startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := parseDate(endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	if openOnly := c.QueryParam("open_only"); openOnly != "" {
+		if open, err := strconv.ParseBool(openOnly); err == nil {
+			filter.OpenOnly = &open
+		}
+	}
+
+	ctx := c.Request().Context()
+	invoices, err := h.service.GetInvoices(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, invoices)
+}
+
+// GetInvoiceByID handles retrieval of a single invoice with its sales
+func (h *ReceivablesHandler) GetInvoiceByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid invoice ID")
+	}
+
+	ctx := c.Request().Context()
+	invoice, err := h.service.GetInvoiceByID(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidInvoiceID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrInvoiceNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, invoice)
+}
+
+// CreateInvoice handles generating an invoice from one or more account sales
+func (h *ReceivablesHandler) CreateInvoice(c echo.Context) error {
+	req := new(salesmodels.CreateInvoiceRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	invoice, err := h.service.CreateInvoice(ctx, req)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidCustomerID, services.ErrNoSalesToInvoice,
+			services.ErrDuplicateSaleOnInvoice, services.ErrInvalidDueDate:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrCustomerNotFound, services.ErrSaleNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrSaleNotOnAccount, services.ErrSaleCustomerMismatch:
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		case services.ErrSaleAlreadyInvoiced:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusCreated, invoice)
+}
+
+// VoidInvoice handles cancelling an unpaid invoice
+func (h *ReceivablesHandler) VoidInvoice(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid invoice ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.VoidInvoice(ctx, id); err != nil {
+		switch err {
+		case services.ErrInvalidInvoiceID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrInvoiceNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrInvoiceVoid, services.ErrInvoiceHasPayments:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetPayments handles retrieval of customer payments with optional filtering
+func (h *ReceivablesHandler) GetPayments(c echo.Context) error {
+	filter := &salesmodels.PaymentFilter{}
+
+	if customerID := c.QueryParam("customer_id"); customerID != "" {
+		if id, err := strconv.Atoi(customerID); err == nil {
+			filter.CustomerID = &id
+		}
+	}
+
+	if method := c.QueryParam("method"); method != "" {
+		filter.Method = &method
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := parseDate(startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := parseDate(endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	ctx := c.Request().Context()
+	payments, err := h.service.GetPayments(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, payments)
+}
+
+// GetPaymentByID handles retrieval of a single payment with its allocations
+func (h *ReceivablesHandler) GetPaymentByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid payment ID")
+	}
+
+	ctx := c.Request().Context()
+	payment, err := h.service.GetPaymentByID(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidPaymentID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrPaymentNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+// RecordPayment handles recording a customer payment against open invoices
+func (h *ReceivablesHandler) RecordPayment(c echo.Context) error {
+	payment := new(salesmodels.Payment)
+	if err := c.Bind(payment); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	created, err := h.service.RecordPayment(ctx, payment)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidCustomerID, services.ErrInvalidPaymentAmount,
+			services.ErrInvalidReceiptMethod, services.ErrInvalidAllocation,
+			services.ErrAllocationMismatch:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrCustomerNotFound, services.ErrInvoiceNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrAllocationExceedsBalance, services.ErrPaymentExceedsOpenItems:
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// GetAgingReport handles retrieval of aged receivables for all customers
+func (h *ReceivablesHandler) GetAgingReport(c echo.Context) error {
+	asOf := time.Now()
+	if value := c.QueryParam("as_of"); value != "" {
+		date, err := parseDate(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid as_of date")
+		}
+		asOf = date
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetAgingReport(ctx, asOf)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetCustomerAccount handles retrieval of a customer's balance and credit
+func (h *ReceivablesHandler) GetCustomerAccount(c echo.Context) error {
+	customerID, err := strconv.Atoi(c.Param("customerId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	ctx := c.Request().Context()
+	account, err := h.service.GetCustomerAccount(ctx, customerID)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidCustomerID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrCustomerNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, account)
+}
+
+// GetCustomerStatement handles retrieval of a customer statement. The period
+// defaults to the current month.
+func (h *ReceivablesHandler) GetCustomerStatement(c echo.Context) error {
+	customerID, err := strconv.Atoi(c.Param("customerId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid customer ID")
+	}
+
+	end := time.Now()
+	if value := c.QueryParam("end_date"); value != "" {
+		if end, err = parseDate(value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid end_date")
+		}
+	}
+
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+	if value := c.QueryParam("start_date"); value != "" {
+		if start, err = parseDate(value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid start_date")
+		}
+	}
+
+	ctx := c.Request().Context()
+	statement, err := h.service.GetCustomerStatement(ctx, customerID, start, end)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidCustomerID, services.ErrInvalidStatementPeriod:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrCustomerNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, statement)
+}
+
+// parseDate accepts either a plain date (2006-01-02) or an RFC3339 timestamp
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
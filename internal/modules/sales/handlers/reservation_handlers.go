@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+	"github.com/hsrvms/autoparts/internal/modules/sales/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ReservationHandler struct {
+	service services.ReservationService
+}
+
+func NewReservationHandler(service services.ReservationService) *ReservationHandler {
+	return &ReservationHandler{
+		service: service,
+	}
+}
+
+// GetReservations handles retrieval of stock reservations with optional filtering
+func (h *ReservationHandler) GetReservations(c echo.Context) error {
+	filter := &salesmodels.ReservationFilter{}
+
+	if itemID, err := strconv.Atoi(c.QueryParam("item_id")); err == nil {
+		filter.ItemID = &itemID
+	}
+
+	if warehouseID, err := strconv.Atoi(c.QueryParam("warehouse_id")); err == nil {
+		filter.WarehouseID = &warehouseID
+	}
+
+	if customerID, err := strconv.Atoi(c.QueryParam("customer_id")); err == nil {
+		filter.CustomerID = &customerID
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if reference := c.QueryParam("reference"); reference != "" {
+		filter.Reference = &reference
+	}
+
+	ctx := c.Request().Context()
+	reservations, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return reservationError(err)
+	}
+
+	return c.JSON(http.StatusOK, reservations)
+}
+
+// GetReservationByID handles retrieval of a single reservation
+func (h *ReservationHandler) GetReservationByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid reservation ID")
+	}
+
+	ctx := c.Request().Context()
+	reservation, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return reservationError(err)
+	}
+
+	return c.JSON(http.StatusOK, reservation)
+}
+
+// CreateReservation handles reserving stock for a customer or job
+func (h *ReservationHandler) CreateReservation(c echo.Context) error {
+	reservation := new(salesmodels.Reservation)
+	if err := c.Bind(reservation); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, reservation)
+	if err != nil {
+		return reservationError(err)
+	}
+
+	created, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return reservationError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateReservation handles changing the quantity or expiry of an active reservation
+func (h *ReservationHandler) UpdateReservation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid reservation ID")
+	}
+
+	reservation := new(salesmodels.Reservation)
+	if err := c.Bind(reservation); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	reservation.ReservationID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.Update(ctx, reservation); err != nil {
+		return reservationError(err)
+	}
+
+	updated, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return reservationError(err)
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// ReleaseReservation handles giving reserved stock back before it expires
+func (h *ReservationHandler) ReleaseReservation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid reservation ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.Release(ctx, id); err != nil {
+		return reservationError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ConvertReservation handles selling the reserved stock
+func (h *ReservationHandler) ConvertReservation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid reservation ID")
+	}
+
+	req := new(salesmodels.ConvertReservationRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	sale, err := h.service.Convert(ctx, id, req)
+	if err != nil {
+		return reservationError(err)
+	}
+
+	return c.JSON(http.StatusCreated, sale)
+}
+
+func reservationError(err error) error {
+	switch err {
+	case services.ErrInvalidReservationID, services.ErrInvalidReservationStatus,
+		services.ErrInvalidItemID, services.ErrInvalidQuantity, services.ErrReservationCustomer,
+		services.ErrInvalidExpiry, services.ErrWarehouseNotFound, services.ErrCustomerNotFound,
+		services.ErrInvalidPricePerUnit, services.ErrInvalidPaymentMethod, services.ErrCustomerRequired,
+		services.ErrReservationMismatch, services.ErrSerialNumbersRequired, services.ErrLotsRequired,
+		services.ErrItemNotTracked:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrReservationNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrReservationNotActive, services.ErrDuplicateTransactionNumber,
+		services.ErrSpecialOrderNotReady:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrInsufficientAvailable, services.ErrInsufficientStock,
+		services.ErrCustomerInactive, services.ErrCreditLimitExceeded, services.ErrSerialNotInStock,
+		services.ErrLotNotAvailable:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,150 @@
+package salesmodels
+
+import "time"
+
+// Invoice statuses
+const (
+	InvoiceStatusOpen          = "open"
+	InvoiceStatusPartiallyPaid = "partially_paid"
+	InvoiceStatusPaid          = "paid"
+	InvoiceStatusVoid          = "void"
+)
+
+// CustomerAccount summarises a customer's credit position
+type CustomerAccount struct {
+	CustomerID         int     `json:"customer_id"`
+	CustomerName       string  `json:"customer_name"`
+	CreditLimit        float64 `json:"credit_limit"`
+	PaymentTermsDays   int     `json:"payment_terms_days"`
+	IsActive           bool    `json:"is_active"`
+	OutstandingBalance float64 `json:"outstanding_balance"`
+	AvailableCredit    float64 `json:"available_credit"`
+}
+
+type Invoice struct {
+	InvoiceID     int       `json:"invoice_id" db:"invoice_id"`
+	InvoiceNumber string    `json:"invoice_number" db:"invoice_number"`
+	CustomerID    int       `json:"customer_id" db:"customer_id"`
+	IssueDate     time.Time `json:"issue_date" db:"issue_date"`
+	DueDate       time.Time `json:"due_date" db:"due_date"`
+	TotalAmount   float64   `json:"total_amount" db:"total_amount"`
+	AmountPaid    float64   `json:"amount_paid" db:"amount_paid"`
+	Status        string    `json:"status" db:"status"`
+	Notes         *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	CustomerName string  `json:"customer_name,omitempty" db:"customer_name"`
+	BalanceDue   float64 `json:"balance_due" db:"-"`
+	Sales        []*Sale `json:"sales,omitempty" db:"-"`
+}
+
+type InvoiceFilter struct {
+	CustomerID *int       `query:"customer_id"`
+	Status     *string    `query:"status"`
+	StartDate  *time.Time `query:"start_date"`
+	EndDate    *time.Time `query:"end_date"`
+	OpenOnly   *bool      `query:"open_only"`
+}
+
+// CreateInvoiceRequest generates an invoice from one or more account sales
+type CreateInvoiceRequest struct {
+	CustomerID int        `json:"customer_id"`
+	SaleIDs    []int      `json:"sale_ids"`
+	IssueDate  *time.Time `json:"issue_date,omitempty"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	Notes      *string    `json:"notes,omitempty"`
+}
+
+type Payment struct {
+	PaymentID   int       `json:"payment_id" db:"payment_id"`
+	CustomerID  int       `json:"customer_id" db:"customer_id"`
+	PaymentDate time.Time `json:"payment_date" db:"payment_date"`
+	Amount      float64   `json:"amount" db:"amount"`
+	Method      string    `json:"method" db:"method"`
+	Reference   *string   `json:"reference,omitempty" db:"reference"`
+	ReceivedBy  *string   `json:"received_by,omitempty" db:"received_by"`
+	Notes       *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+
+	// Allocations against invoices. When empty on creation the payment is
+	// applied to the customer's oldest open invoices first.
+	Allocations []*PaymentAllocation `json:"allocations,omitempty" db:"-"`
+
+	// Additional fields for API responses
+	CustomerName string `json:"customer_name,omitempty" db:"customer_name"`
+}
+
+type PaymentAllocation struct {
+	AllocationID int       `json:"allocation_id" db:"allocation_id"`
+	PaymentID    int       `json:"payment_id" db:"payment_id"`
+	InvoiceID    int       `json:"invoice_id" db:"invoice_id"`
+	Amount       float64   `json:"amount" db:"amount"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	InvoiceNumber string `json:"invoice_number,omitempty" db:"invoice_number"`
+}
+
+type PaymentFilter struct {
+	CustomerID *int       `query:"customer_id"`
+	Method     *string    `query:"method"`
+	StartDate  *time.Time `query:"start_date"`
+	EndDate    *time.Time `query:"end_date"`
+}
+
+// OpenReceivable is an unpaid document contributing to a customer's balance:
+// either an open invoice or an account sale that has not been invoiced yet.
+type OpenReceivable struct {
+	CustomerID   int       `json:"customer_id"`
+	CustomerName string    `json:"customer_name"`
+	DocumentType string    `json:"document_type"` // "invoice" or "sale"
+	DocumentID   int       `json:"document_id"`
+	Reference    string    `json:"reference"`
+	DocumentDate time.Time `json:"document_date"`
+	DueDate      time.Time `json:"due_date"`
+	Balance      float64   `json:"balance"`
+}
+
+// AgingBuckets splits an amount by days past due
+type AgingBuckets struct {
+	Days0To30  float64 `json:"days_0_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Over90     float64 `json:"days_over_90"`
+	Total      float64 `json:"total"`
+}
+
+type CustomerAging struct {
+	CustomerID   int    `json:"customer_id"`
+	CustomerName string `json:"customer_name"`
+	AgingBuckets
+}
+
+type AgingReport struct {
+	AsOf      time.Time        `json:"as_of"`
+	Customers []*CustomerAging `json:"customers"`
+	Totals    AgingBuckets     `json:"totals"`
+}
+
+type StatementLine struct {
+	Date        time.Time `json:"date"`
+	Type        string    `json:"type"` // "sale" or "payment"
+	Reference   string    `json:"reference"`
+	Description string    `json:"description"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	Balance     float64   `json:"balance"`
+}
+
+type CustomerStatement struct {
+	CustomerID     int              `json:"customer_id"`
+	CustomerName   string           `json:"customer_name"`
+	StartDate      time.Time        `json:"start_date"`
+	EndDate        time.Time        `json:"end_date"`
+	OpeningBalance float64          `json:"opening_balance"`
+	ClosingBalance float64          `json:"closing_balance"`
+	Lines          []*StatementLine `json:"lines"`
+	Aging          AgingBuckets     `json:"aging"`
+}
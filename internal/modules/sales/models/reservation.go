@@ -0,0 +1,61 @@
+package salesmodels
+
+import "time"
+
+// Reservation statuses
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusConverted = "converted"
+	ReservationStatusReleased  = "released"
+	ReservationStatusExpired   = "expired"
+)
+
+// Reservation holds stock for a customer or workshop job. It lowers the
+// stock available to sell without taking it off the shelf.
+type Reservation struct {
+	ReservationID int        `json:"reservation_id" db:"reservation_id"`
+	ItemID        int        `json:"item_id" db:"item_id"`
+	WarehouseID   *int       `json:"warehouse_id,omitempty" db:"warehouse_id"` // Default warehouse if omitted
+	Quantity      int        `json:"quantity" db:"quantity"`
+	CustomerID    *int       `json:"customer_id,omitempty" db:"customer_id"`
+	CustomerName  *string    `json:"customer_name,omitempty" db:"customer_name"`
+	CustomerPhone *string    `json:"customer_phone,omitempty" db:"customer_phone"`
+	Reference     *string    `json:"reference,omitempty" db:"reference"`
+	Status        string     `json:"status" db:"status"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	SaleID        *int       `json:"sale_id,omitempty" db:"sale_id"`
+	Notes         *string    `json:"notes,omitempty" db:"notes"`
+	CreatedBy     *string    `json:"created_by,omitempty" db:"created_by"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string  `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string  `json:"item_description,omitempty" db:"item_description"`
+	ItemSellPrice   float64 `json:"-" db:"item_sell_price"`
+	WarehouseCode   string  `json:"warehouse_code,omitempty" db:"warehouse_code"`
+
+	// Status of the special order the reservation holds units for, if any
+	SpecialOrderStatus *string `json:"special_order_status,omitempty" db:"special_order_status"`
+}
+
+type ReservationFilter struct {
+	ItemID      *int    `query:"item_id"`
+	WarehouseID *int    `query:"warehouse_id"`
+	CustomerID  *int    `query:"customer_id"`
+	Status      *string `query:"status"`
+	Reference   *string `query:"reference"`
+}
+
+// ConvertReservationRequest turns a reservation into a sale of the reserved
+// quantity. The price defaults to the item's sell price.
+type ConvertReservationRequest struct {
+	PricePerUnit      *float64   `json:"price_per_unit,omitempty"`
+	TransactionNumber string     `json:"transaction_number"`
+	PaymentMethod     string     `json:"payment_method"`
+	SoldBy            *string    `json:"sold_by,omitempty"`
+	Notes             *string    `json:"notes,omitempty"`
+	SerialNumbers     []string   `json:"serial_numbers,omitempty"` // Required for serial tracked items
+	Lots              []*SaleLot `json:"lots,omitempty"`           // Required for lot tracked items
+}
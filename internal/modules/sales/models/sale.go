@@ -2,26 +2,76 @@ package salesmodels
 
 import "time"
 
+// Payment methods accepted at the counter. Account sales are charged to a
+// customer's credit account and settled later through invoices.
+const (
+	PaymentMethodCash         = "cash"
+	PaymentMethodCard         = "card"
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodAccount      = "account"
+)
+
+// Payment statuses of a sale
+const (
+	PaymentStatusPaid          = "paid"
+	PaymentStatusUnpaid        = "unpaid"
+	PaymentStatusPartiallyPaid = "partially_paid"
+)
+
 type Sale struct {
-	SaleID            int       `json:"sale_id" db:"sale_id"`
-	Date              time.Time `json:"date" db:"date"`
-	ItemID            int       `json:"item_id" db:"item_id"`
-	Quantity          int       `json:"quantity" db:"quantity"`
-	PricePerUnit      float64   `json:"price_per_unit" db:"price_per_unit"`
-	TotalPrice        float64   `json:"total_price" db:"total_price"`
-	TransactionNumber string    `json:"transaction_number" db:"transaction_number"`
-	CustomerName      *string   `json:"customer_name,omitempty" db:"customer_name"`
-	CustomerPhone     *string   `json:"customer_phone,omitempty" db:"customer_phone"`
-	CustomerEmail     *string   `json:"customer_email,omitempty" db:"customer_email"`
-	SoldBy            *string   `json:"sold_by,omitempty" db:"sold_by"`
-	Notes             *string   `json:"notes,omitempty" db:"notes"`
-	CreatedAt         time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	SaleID            int        `json:"sale_id" db:"sale_id"`
+	Date              time.Time  `json:"date" db:"date"`
+	ItemID            int        `json:"item_id" db:"item_id"`
+	Quantity          int        `json:"quantity" db:"quantity"`
+	PricePerUnit      float64    `json:"price_per_unit" db:"price_per_unit"`
+	TotalPrice        float64    `json:"total_price" db:"total_price"`
+	TransactionNumber string     `json:"transaction_number" db:"transaction_number"`
+	CustomerName      *string    `json:"customer_name,omitempty" db:"customer_name"`
+	CustomerPhone     *string    `json:"customer_phone,omitempty" db:"customer_phone"`
+	CustomerEmail     *string    `json:"customer_email,omitempty" db:"customer_email"`
+	SoldBy            *string    `json:"sold_by,omitempty" db:"sold_by"`
+	Notes             *string    `json:"notes,omitempty" db:"notes"`
+	CustomerID        *int       `json:"customer_id,omitempty" db:"customer_id"`
+	PaymentMethod     string     `json:"payment_method" db:"payment_method"`
+	PaymentStatus     string     `json:"payment_status" db:"payment_status"`
+	DueDate           *time.Time `json:"due_date,omitempty" db:"due_date"`
+	InvoiceID         *int       `json:"invoice_id,omitempty" db:"invoice_id"`
+	CogsFIFO          *float64   `json:"cogs_fifo,omitempty" db:"cogs_fifo"`
+	CogsAverage       *float64   `json:"cogs_average,omitempty" db:"cogs_average"`
+	WarehouseID       *int       `json:"warehouse_id,omitempty" db:"warehouse_id"`
+	CustomerVehicleID *int       `json:"customer_vehicle_id,omitempty" db:"customer_vehicle_id"`
+	VehicleMileage    *int       `json:"vehicle_mileage,omitempty" db:"vehicle_mileage"`
+	CoreDeposit       float64    `json:"core_deposit" db:"core_deposit"`  // Taken on top of the total for items with a core charge, read only
+	ReservationID     *int       `json:"reservation_id,omitempty" db:"-"` // Reservation the sale fulfils, on create
+	SerialNumbers     []string   `json:"serial_numbers,omitempty" db:"-"` // Units sold, for serial tracked items
+	Lots              []*SaleLot `json:"lots,omitempty" db:"-"`           // Lots drawn from, for lot tracked items
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
 
 	// Additional fields for API responses
 	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
 	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
 	CategoryName    string `json:"category_name,omitempty" db:"category_name"`
+	WarehouseCode   string `json:"warehouse_code,omitempty" db:"warehouse_code"`
+
+	// Set on create for things the counter should know about the sale
+	Warnings []string `json:"warnings,omitempty" db:"-"`
+}
+
+// ItemReplacement is the current part for a superseded item, with its stock
+// free to sell at one warehouse
+type ItemReplacement struct {
+	PartNumber            string `json:"part_number"`
+	ReplacementPartNumber string `json:"replacement_part_number"`
+	IsDirectInterchange   bool   `json:"is_direct_interchange"` // Every supersession on the way is a direct interchange
+	AvailableStock        int    `json:"available_stock"`
+}
+
+// SaleLot is the quantity of a sale drawn from one lot
+type SaleLot struct {
+	LotID     int    `json:"lot_id"`
+	LotNumber string `json:"lot_number,omitempty"`
+	Quantity  int    `json:"quantity"`
 }
 
 type SaleFilter struct {
@@ -33,4 +83,11 @@ type SaleFilter struct {
 	CustomerEmail     *string    `query:"customer_email"`
 	TransactionNumber *string    `query:"transaction_number"`
 	SoldBy            *string    `query:"sold_by"`
+	CustomerID        *int       `query:"customer_id"`
+	PaymentMethod     *string    `query:"payment_method"`
+	PaymentStatus     *string    `query:"payment_status"`
+	InvoiceID         *int       `query:"invoice_id"`
+	Uninvoiced        *bool      `query:"uninvoiced"`
+	WarehouseID       *int       `query:"warehouse_id"`
+	CustomerVehicleID *int       `query:"customer_vehicle_id"`
 }
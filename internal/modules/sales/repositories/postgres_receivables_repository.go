@@ -0,0 +1,677 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresReceivablesRepository struct {
+	db *db.Database
+}
+
+func NewPostgresReceivablesRepository(database *db.Database) ReceivablesRepository {
+	return &PostgresReceivablesRepository{
+		db: database,
+	}
+}
+
+// outstandingBalance is the balance of customer c: every account sale with
+// its core deposit minus every payment received and core deposit credited
+const outstandingBalance = `
+	COALESCE((
+		SELECT SUM(s.total_price + s.core_deposit) FROM sales s
+		WHERE s.customer_id = c.customer_id AND s.payment_method = 'account'
+	), 0) - COALESCE((
+		SELECT SUM(p.amount) FROM payments p
+		WHERE p.customer_id = c.customer_id
+	), 0)`
+
+// GetCustomerAccount returns the customer's credit terms together with the
+// outstanding balance
+func (r *PostgresReceivablesRepository) GetCustomerAccount(ctx context.Context, customerID int) (*salesmodels.CustomerAccount, error) {
+	query := `
+		SELECT
+			c.customer_id, c.name, c.credit_limit, c.payment_terms_days, c.is_active,
+			` + outstandingBalance + ` AS outstanding_balance
+		FROM customers c
+		WHERE c.customer_id = $1
+	`
+
+	account := &salesmodels.CustomerAccount{}
+	err := r.db.Pool.QueryRow(ctx, query, customerID).Scan(
+		&account.CustomerID,
+		&account.CustomerName,
+		&account.CreditLimit,
+		&account.PaymentTermsDays,
+		&account.IsActive,
+		&account.OutstandingBalance,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	account.AvailableCredit = account.CreditLimit - account.OutstandingBalance
+	if account.AvailableCredit < 0 {
+		account.AvailableCredit = 0
+	}
+
+	return account, nil
+}
+
+const invoiceColumns = `
+	inv.invoice_id, inv.invoice_number, inv.customer_id, inv.issue_date,
+	inv.due_date, inv.total_amount, inv.amount_paid, inv.status, inv.notes,
+	inv.created_at, inv.updated_at, c.name as customer_name
+`
+
+func scanInvoice(row pgx.Row) (*salesmodels.Invoice, error) {
+	invoice := &salesmodels.Invoice{}
+	err := row.Scan(
+		&invoice.InvoiceID,
+		&invoice.InvoiceNumber,
+		&invoice.CustomerID,
+		&invoice.IssueDate,
+		&invoice.DueDate,
+		&invoice.TotalAmount,
+		&invoice.AmountPaid,
+		&invoice.Status,
+		&invoice.Notes,
+		&invoice.CreatedAt,
+		&invoice.UpdatedAt,
+		&invoice.CustomerName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invoice.BalanceDue = invoice.TotalAmount - invoice.AmountPaid
+	return invoice, nil
+}
+
+func (r *PostgresReceivablesRepository) GetInvoices(ctx context.Context, filter *salesmodels.InvoiceFilter) ([]*salesmodels.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices inv
+		JOIN customers c ON inv.customer_id = c.customer_id
+		WHERE 1=1
+	`
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.CustomerID != nil {
+			conditions = append(conditions, fmt.Sprintf("inv.customer_id = $%d", paramCount))
+			params = append(params, *filter.CustomerID)
+			paramCount++
+		}
+
+		if filter.Status != nil {
+			conditions = append(conditions, fmt.Sprintf("inv.status = $%d", paramCount))
+			params = append(params, *filter.Status)
+			paramCount++
+		}
+
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("inv.issue_date >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("inv.issue_date <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+
+		if filter.OpenOnly != nil && *filter.OpenOnly {
+			conditions = append(conditions, "inv.status IN ('open', 'partially_paid')")
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY inv.due_date, inv.invoice_id"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []*salesmodels.Invoice
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, rows.Err()
+}
+
+func (r *PostgresReceivablesRepository) GetInvoiceByID(ctx context.Context, id int) (*salesmodels.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices inv
+		JOIN customers c ON inv.customer_id = c.customer_id
+		WHERE inv.invoice_id = $1
+	`
+
+	invoice, err := scanInvoice(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// CreateInvoice inserts the invoice and links the given sales to it. The
+// sales must still be uninvoiced account sales of the same customer,
+// otherwise nothing is written.
+func (r *PostgresReceivablesRepository) CreateInvoice(ctx context.Context, invoice *salesmodels.Invoice, saleIDs []int) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	if err := tx.QueryRow(ctx, `SELECT nextval('invoice_id_seq')`).Scan(&id); err != nil {
+		return 0, err
+	}
+	invoice.InvoiceNumber = fmt.Sprintf("INV-%06d", id)
+
+	query := `
+		INSERT INTO invoices (
+			invoice_id, invoice_number, customer_id, issue_date,
+			due_date, total_amount, status, notes
+		) VALUES ($1, $2, $3, $4, $5, $6::numeric, $7, $8)
+	`
+
+	_, err = tx.Exec(
+		ctx, query,
+		id,
+		invoice.InvoiceNumber,
+		invoice.CustomerID,
+		invoice.IssueDate,
+		invoice.DueDate,
+		invoice.TotalAmount,
+		salesmodels.InvoiceStatusOpen,
+		invoice.Notes,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE sales SET invoice_id = $1
+		WHERE sale_id = ANY($2)
+			AND customer_id = $3
+			AND payment_method = 'account'
+			AND invoice_id IS NULL
+	`, id, saleIDs, invoice.CustomerID)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() != int64(len(saleIDs)) {
+		return 0, errors.New("one or more sales are no longer available for invoicing")
+	}
+
+	// Core deposits already credited back on the sales count as paid
+	var credited float64
+	err = tx.QueryRow(ctx, `
+		WITH credits AS (
+			INSERT INTO payment_allocations (payment_id, invoice_id, amount)
+			SELECT p.payment_id, $1, p.amount
+			FROM core_returns cr
+			JOIN payments p ON cr.payment_id = p.payment_id
+			WHERE cr.sale_id = ANY($2)
+				AND NOT EXISTS (SELECT 1 FROM payment_allocations pa WHERE pa.payment_id = p.payment_id)
+			RETURNING amount
+		)
+		SELECT COALESCE(SUM(amount), 0) FROM credits
+	`, id, saleIDs).Scan(&credited)
+	if err != nil {
+		return 0, err
+	}
+
+	if credited > 0 {
+		var status string
+		err = tx.QueryRow(ctx, `
+			UPDATE invoices SET
+				amount_paid = $2::numeric,
+				status = CASE WHEN $2::numeric >= total_amount THEN 'paid' ELSE 'partially_paid' END
+			WHERE invoice_id = $1
+			RETURNING status
+		`, id, credited).Scan(&status)
+		if err != nil {
+			return 0, err
+		}
+
+		saleStatus := salesmodels.PaymentStatusPartiallyPaid
+		if status == salesmodels.InvoiceStatusPaid {
+			saleStatus = salesmodels.PaymentStatusPaid
+		}
+		if _, err := tx.Exec(ctx, `UPDATE sales SET payment_status = $2 WHERE invoice_id = $1`, id, saleStatus); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// VoidInvoice cancels an unpaid invoice and releases its sales so they can
+// be invoiced again.
+func (r *PostgresReceivablesRepository) VoidInvoice(ctx context.Context, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE invoices SET status = 'void'
+		WHERE invoice_id = $1 AND status <> 'void' AND amount_paid = 0
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("invoice cannot be voided")
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE sales SET invoice_id = NULL WHERE invoice_id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+const paymentColumns = `
+	p.payment_id, p.customer_id, p.payment_date, p.amount, p.method,
+	p.reference, p.received_by, p.notes, p.created_at, c.name as customer_name
+`
+
+func scanPayment(row pgx.Row) (*salesmodels.Payment, error) {
+	payment := &salesmodels.Payment{}
+	err := row.Scan(
+		&payment.PaymentID,
+		&payment.CustomerID,
+		&payment.PaymentDate,
+		&payment.Amount,
+		&payment.Method,
+		&payment.Reference,
+		&payment.ReceivedBy,
+		&payment.Notes,
+		&payment.CreatedAt,
+		&payment.CustomerName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (r *PostgresReceivablesRepository) GetPayments(ctx context.Context, filter *salesmodels.PaymentFilter) ([]*salesmodels.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments p
+		JOIN customers c ON p.customer_id = c.customer_id
+		WHERE 1=1
+	`
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.CustomerID != nil {
+			conditions = append(conditions, fmt.Sprintf("p.customer_id = $%d", paramCount))
+			params = append(params, *filter.CustomerID)
+			paramCount++
+		}
+
+		if filter.Method != nil {
+			conditions = append(conditions, fmt.Sprintf("p.method = $%d", paramCount))
+			params = append(params, *filter.Method)
+			paramCount++
+		}
+
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("p.payment_date >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("p.payment_date <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY p.payment_date DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*salesmodels.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+func (r *PostgresReceivablesRepository) GetPaymentByID(ctx context.Context, id int) (*salesmodels.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments p
+		JOIN customers c ON p.customer_id = c.customer_id
+		WHERE p.payment_id = $1
+	`
+
+	payment, err := scanPayment(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT pa.allocation_id, pa.payment_id, pa.invoice_id, pa.amount,
+			pa.created_at, inv.invoice_number
+		FROM payment_allocations pa
+		JOIN invoices inv ON pa.invoice_id = inv.invoice_id
+		WHERE pa.payment_id = $1
+		ORDER BY pa.allocation_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		allocation := &salesmodels.PaymentAllocation{}
+		err := rows.Scan(
+			&allocation.AllocationID,
+			&allocation.PaymentID,
+			&allocation.InvoiceID,
+			&allocation.Amount,
+			&allocation.CreatedAt,
+			&allocation.InvoiceNumber,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payment.Allocations = append(payment.Allocations, allocation)
+	}
+
+	return payment, rows.Err()
+}
+
+// CreatePayment records the payment and applies each allocation to its
+// invoice, updating the invoice status and the payment status of the sales
+// on that invoice.
+func (r *PostgresReceivablesRepository) CreatePayment(ctx context.Context, payment *salesmodels.Payment) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO payments (
+			customer_id, payment_date, amount, method,
+			reference, received_by, notes
+		) VALUES ($1, $2, $3::numeric, $4, $5, $6, $7)
+		RETURNING payment_id
+	`,
+		payment.CustomerID,
+		payment.PaymentDate,
+		payment.Amount,
+		payment.Method,
+		payment.Reference,
+		payment.ReceivedBy,
+		payment.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, allocation := range payment.Allocations {
+		var status string
+		err := tx.QueryRow(ctx, `
+			UPDATE invoices SET
+				amount_paid = amount_paid + $2::numeric,
+				status = CASE
+					WHEN amount_paid + $2::numeric >= total_amount THEN 'paid'
+					ELSE 'partially_paid'
+				END
+			WHERE invoice_id = $1
+				AND customer_id = $3
+				AND status IN ('open', 'partially_paid')
+				AND amount_paid + $2::numeric <= total_amount
+			RETURNING status
+		`, allocation.InvoiceID, allocation.Amount, payment.CustomerID).Scan(&status)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, fmt.Errorf("allocation to invoice %d exceeds its balance", allocation.InvoiceID)
+			}
+			return 0, err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO payment_allocations (payment_id, invoice_id, amount)
+			VALUES ($1, $2, $3::numeric)
+		`, id, allocation.InvoiceID, allocation.Amount)
+		if err != nil {
+			return 0, err
+		}
+
+		saleStatus := salesmodels.PaymentStatusPartiallyPaid
+		if status == salesmodels.InvoiceStatusPaid {
+			saleStatus = salesmodels.PaymentStatusPaid
+		}
+		_, err = tx.Exec(ctx, `UPDATE sales SET payment_status = $2 WHERE invoice_id = $1`,
+			allocation.InvoiceID, saleStatus)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetOpenReceivables reconstructs what was owed at the start of asOf:
+// invoices issued before then less payments received before then, plus
+// account sales that had not been invoiced yet.
+func (r *PostgresReceivablesRepository) GetOpenReceivables(ctx context.Context, customerID *int, asOf time.Time) ([]*salesmodels.OpenReceivable, error) {
+	query := `
+		SELECT customer_id, customer_name, document_type, document_id,
+			reference, document_date, due_date, balance
+		FROM (
+			SELECT
+				c.customer_id, c.name AS customer_name, 'invoice' AS document_type,
+				inv.invoice_id AS document_id, inv.invoice_number AS reference,
+				inv.issue_date::timestamptz AS document_date, inv.due_date,
+				inv.total_amount - COALESCE((
+					SELECT SUM(pa.amount)
+					FROM payment_allocations pa
+					JOIN payments p ON pa.payment_id = p.payment_id
+					WHERE pa.invoice_id = inv.invoice_id AND p.payment_date < $1
+				), 0) AS balance
+			FROM invoices inv
+			JOIN customers c ON inv.customer_id = c.customer_id
+			WHERE inv.status <> 'void' AND inv.issue_date < $1
+
+			UNION ALL
+
+			SELECT
+				c.customer_id, c.name, 'sale', s.sale_id,
+				COALESCE(s.transaction_number, ''), s.date,
+				COALESCE(s.due_date, s.date::date),
+				s.total_price + s.core_deposit - COALESCE((
+					SELECT SUM(p.amount)
+					FROM core_returns cr
+					JOIN payments p ON cr.payment_id = p.payment_id
+					WHERE cr.sale_id = s.sale_id AND p.payment_date < $1
+				), 0)
+			FROM sales s
+			JOIN customers c ON s.customer_id = c.customer_id
+			LEFT JOIN invoices inv ON s.invoice_id = inv.invoice_id
+			WHERE s.payment_method = 'account'
+				AND s.date < $1
+				AND (s.invoice_id IS NULL OR inv.issue_date >= $1)
+		) open_items
+		WHERE balance > 0
+	`
+
+	params := []interface{}{asOf}
+	if customerID != nil {
+		query += " AND customer_id = $2"
+		params = append(params, *customerID)
+	}
+
+	query += " ORDER BY customer_name, due_date, document_id"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receivables []*salesmodels.OpenReceivable
+	for rows.Next() {
+		item := &salesmodels.OpenReceivable{}
+		err := rows.Scan(
+			&item.CustomerID,
+			&item.CustomerName,
+			&item.DocumentType,
+			&item.DocumentID,
+			&item.Reference,
+			&item.DocumentDate,
+			&item.DueDate,
+			&item.Balance,
+		)
+		if err != nil {
+			return nil, err
+		}
+		receivables = append(receivables, item)
+	}
+
+	return receivables, rows.Err()
+}
+
+// GetBalanceBefore returns the customer's account balance immediately
+// before the given time.
+func (r *PostgresReceivablesRepository) GetBalanceBefore(ctx context.Context, customerID int, before time.Time) (float64, error) {
+	query := `
+		SELECT
+			COALESCE((
+				SELECT SUM(total_price + core_deposit) FROM sales
+				WHERE customer_id = $1 AND payment_method = 'account' AND date < $2
+			), 0) - COALESCE((
+				SELECT SUM(amount) FROM payments
+				WHERE customer_id = $1 AND payment_date < $2
+			), 0)
+	`
+
+	var balance float64
+	if err := r.db.Pool.QueryRow(ctx, query, customerID, before).Scan(&balance); err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// GetStatementLines lists account sales (debits) and payments (credits) in
+// [start, end) in date order. Running balances are filled in by the service.
+func (r *PostgresReceivablesRepository) GetStatementLines(ctx context.Context, customerID int, start, end time.Time) ([]*salesmodels.StatementLine, error) {
+	query := `
+		SELECT s.date, 'sale' AS type, COALESCE(s.transaction_number, '') AS reference,
+			CONCAT(i.part_number, ' - ', i.description, ' x', s.quantity) AS description,
+			s.total_price + s.core_deposit AS debit, 0 AS credit
+		FROM sales s
+		JOIN items i ON s.item_id = i.item_id
+		WHERE s.customer_id = $1 AND s.payment_method = 'account'
+			AND s.date >= $2 AND s.date < $3
+
+		UNION ALL
+
+		SELECT p.payment_date, 'payment', COALESCE(p.reference, ''),
+			CASE WHEN p.method = 'core_credit' THEN 'Core deposit credited'
+				ELSE CONCAT('Payment received (', p.method, ')') END,
+			0, p.amount
+		FROM payments p
+		WHERE p.customer_id = $1
+			AND p.payment_date >= $2 AND p.payment_date < $3
+
+		ORDER BY 1, 2 DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, customerID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*salesmodels.StatementLine
+	for rows.Next() {
+		line := &salesmodels.StatementLine{}
+		err := rows.Scan(
+			&line.Date,
+			&line.Type,
+			&line.Reference,
+			&line.Description,
+			&line.Debit,
+			&line.Credit,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
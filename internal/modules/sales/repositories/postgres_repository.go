@@ -11,6 +11,10 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// ErrCreditLimitExceeded is returned when an account sale takes the
+// customer's balance past their credit limit
+var ErrCreditLimitExceeded = errors.New("sale would exceed the customer's credit limit")
+
 type PostgresSaleRepository struct {
     db *db.Database
 }
@@ -21,19 +25,67 @@ func NewPostgresSaleRepository(database *db.Database) SaleRepository {
     }
 }
 
-func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *salesmodels.SaleFilter) ([]*salesmodels.Sale, error) {
-    query := `
-        SELECT
+const saleColumns = `
             s.sale_id, s.date, s.item_id, s.quantity,
             s.price_per_unit, s.total_price, s.transaction_number,
             s.customer_name, s.customer_phone, s.customer_email,
-            s.sold_by, s.notes, s.created_at, s.updated_at,
+            s.sold_by, s.notes, s.customer_id, s.payment_method,
+            s.payment_status, s.due_date, s.invoice_id,
+            s.cogs_fifo, s.cogs_average, s.warehouse_id,
+            s.core_deposit, s.customer_vehicle_id, s.vehicle_mileage,
+            s.created_at, s.updated_at,
             i.part_number as item_part_number,
             i.description as item_description,
-            c.category_name
+            COALESCE(c.category_name, '') as category_name,
+            w.code as warehouse_code
+`
+
+func scanSale(row pgx.Row) (*salesmodels.Sale, error) {
+    sale := &salesmodels.Sale{}
+    err := row.Scan(
+        &sale.SaleID,
+        &sale.Date,
+        &sale.ItemID,
+        &sale.Quantity,
+        &sale.PricePerUnit,
+        &sale.TotalPrice,
+        &sale.TransactionNumber,
+        &sale.CustomerName,
+        &sale.CustomerPhone,
+        &sale.CustomerEmail,
+        &sale.SoldBy,
+        &sale.Notes,
+        &sale.CustomerID,
+        &sale.PaymentMethod,
+        &sale.PaymentStatus,
+        &sale.DueDate,
+        &sale.InvoiceID,
+        &sale.CogsFIFO,
+        &sale.CogsAverage,
+        &sale.WarehouseID,
+        &sale.CoreDeposit,
+        &sale.CustomerVehicleID,
+        &sale.VehicleMileage,
+        &sale.CreatedAt,
+        &sale.UpdatedAt,
+        &sale.ItemPartNumber,
+        &sale.ItemDescription,
+        &sale.CategoryName,
+        &sale.WarehouseCode,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return sale, nil
+}
+
+func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *salesmodels.SaleFilter) ([]*salesmodels.Sale, error) {
+    query := `
+        SELECT ` + saleColumns + `
         FROM sales s
         JOIN items i ON s.item_id = i.item_id
         LEFT JOIN categories c ON i.category_id = c.category_id
+        JOIN warehouses w ON s.warehouse_id = w.warehouse_id
         WHERE 1=1
     `
 
@@ -89,6 +141,46 @@ func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *salesmodels
             params = append(params, *filter.SoldBy)
             paramCount++
         }
+
+        if filter.CustomerID != nil {
+            conditions = append(conditions, fmt.Sprintf("s.customer_id = $%d", paramCount))
+            params = append(params, *filter.CustomerID)
+            paramCount++
+        }
+
+        if filter.PaymentMethod != nil {
+            conditions = append(conditions, fmt.Sprintf("s.payment_method = $%d", paramCount))
+            params = append(params, *filter.PaymentMethod)
+            paramCount++
+        }
+
+        if filter.PaymentStatus != nil {
+            conditions = append(conditions, fmt.Sprintf("s.payment_status = $%d", paramCount))
+            params = append(params, *filter.PaymentStatus)
+            paramCount++
+        }
+
+        if filter.InvoiceID != nil {
+            conditions = append(conditions, fmt.Sprintf("s.invoice_id = $%d", paramCount))
+            params = append(params, *filter.InvoiceID)
+            paramCount++
+        }
+
+        if filter.Uninvoiced != nil && *filter.Uninvoiced {
+            conditions = append(conditions, "s.invoice_id IS NULL")
+        }
+
+        if filter.WarehouseID != nil {
+            conditions = append(conditions, fmt.Sprintf("s.warehouse_id = $%d", paramCount))
+            params = append(params, *filter.WarehouseID)
+            paramCount++
+        }
+
+        if filter.CustomerVehicleID != nil {
+            conditions = append(conditions, fmt.Sprintf("s.customer_vehicle_id = $%d", paramCount))
+            params = append(params, *filter.CustomerVehicleID)
+            paramCount++
+        }
     }
 
     if len(conditions) > 0 {
@@ -105,26 +197,7 @@ func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *salesmodels
 
     var sales []*salesmodels.Sale
     for rows.Next() {
-        sale := &salesmodels.Sale{}
-        err := rows.Scan(
-            &sale.SaleID,
-            &sale.Date,
-            &sale.ItemID,
-            &sale.Quantity,
-            &sale.PricePerUnit,
-            &sale.TotalPrice,
-            &sale.TransactionNumber,
-            &sale.CustomerName,
-            &sale.CustomerPhone,
-            &sale.CustomerEmail,
-            &sale.SoldBy,
-            &sale.Notes,
-            &sale.CreatedAt,
-            &sale.UpdatedAt,
-            &sale.ItemPartNumber,
-            &sale.ItemDescription,
-            &sale.CategoryName,
-        )
+        sale, err := scanSale(rows)
         if err != nil {
             return nil, err
         }
@@ -136,41 +209,15 @@ func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *salesmodels
 
 func (r *PostgresSaleRepository) GetByID(ctx context.Context, id int) (*salesmodels.Sale, error) {
     query := `
-        SELECT
-            s.sale_id, s.date, s.item_id, s.quantity,
-            s.price_per_unit, s.total_price, s.transaction_number,
-            s.customer_name, s.customer_phone, s.customer_email,
-            s.sold_by, s.notes, s.created_at, s.updated_at,
-            i.part_number as item_part_number,
-            i.description as item_description,
-            c.category_name
+        SELECT ` + saleColumns + `
         FROM sales s
         JOIN items i ON s.item_id = i.item_id
         LEFT JOIN categories c ON i.category_id = c.category_id
+        JOIN warehouses w ON s.warehouse_id = w.warehouse_id
         WHERE s.sale_id = $1
     `
 
-    sale := &salesmodels.Sale{}
-    err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-        &sale.SaleID,
-        &sale.Date,
-        &sale.ItemID,
-        &sale.Quantity,
-        &sale.PricePerUnit,
-        &sale.TotalPrice,
-        &sale.TransactionNumber,
-        &sale.CustomerName,
-        &sale.CustomerPhone,
-        &sale.CustomerEmail,
-        &sale.SoldBy,
-        &sale.Notes,
-        &sale.CreatedAt,
-        &sale.UpdatedAt,
-        &sale.ItemPartNumber,
-        &sale.ItemDescription,
-        &sale.CategoryName,
-    )
-
+    sale, err := scanSale(r.db.Pool.QueryRow(ctx, query, id))
     if err != nil {
         if errors.Is(err, pgx.ErrNoRows) {
             return nil, nil
@@ -188,13 +235,19 @@ func (r *PostgresSaleRepository) Create(ctx context.Context, sale *salesmodels.S
     }
     defer tx.Rollback(ctx)
 
+    if err := lockCustomerAccount(ctx, tx, sale); err != nil {
+        return 0, err
+    }
+
     // Insert the sale
     query := `
         INSERT INTO sales (
             date, item_id, quantity, price_per_unit,
             total_price, transaction_number, customer_name,
-            customer_phone, customer_email, sold_by, notes
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+            customer_phone, customer_email, sold_by, notes,
+            customer_id, payment_method, payment_status, due_date,
+            warehouse_id, customer_vehicle_id, vehicle_mileage
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
         RETURNING sale_id
     `
 
@@ -212,12 +265,78 @@ func (r *PostgresSaleRepository) Create(ctx context.Context, sale *salesmodels.S
         sale.CustomerEmail,
         sale.SoldBy,
         sale.Notes,
+        sale.CustomerID,
+        sale.PaymentMethod,
+        sale.PaymentStatus,
+        sale.DueDate,
+        sale.WarehouseID,
+        sale.CustomerVehicleID,
+        sale.VehicleMileage,
     ).Scan(&id)
 
     if err != nil {
         return 0, err
     }
 
+    // Take the serial numbered units or lot quantities sold out of stock
+    if len(sale.SerialNumbers) > 0 {
+        result, err := tx.Exec(ctx, `
+            UPDATE item_serials SET
+                status = 'sold',
+                sale_id = $3,
+                sold_at = $4
+            WHERE item_id = $1 AND serial_number = ANY($2::text[]) AND status = 'in_stock'
+                AND warehouse_id = (SELECT warehouse_id FROM sales WHERE sale_id = $3)
+        `, sale.ItemID, sale.SerialNumbers, id, sale.Date)
+        if err != nil {
+            return 0, err
+        }
+        if result.RowsAffected() != int64(len(sale.SerialNumbers)) {
+            return 0, errors.New("serial number is no longer in stock")
+        }
+    }
+
+    for _, lot := range sale.Lots {
+        result, err := tx.Exec(ctx, `
+            UPDATE item_lots SET quantity_remaining = quantity_remaining - $3
+            WHERE lot_id = $1 AND item_id = $2 AND quantity_remaining >= $3
+        `, lot.LotID, sale.ItemID, lot.Quantity)
+        if err != nil {
+            return 0, err
+        }
+        if result.RowsAffected() == 0 {
+            return 0, errors.New("not enough left in lot")
+        }
+
+        _, err = tx.Exec(ctx, `
+            INSERT INTO sale_lots (sale_id, lot_id, quantity) VALUES ($1, $2, $3)
+        `, id, lot.LotID, lot.Quantity)
+        if err != nil {
+            return 0, err
+        }
+    }
+
+    // Close the reservation the sale fulfils
+    if sale.ReservationID != nil {
+        result, err := tx.Exec(ctx, `
+            UPDATE stock_reservations SET
+                status = 'converted',
+                sale_id = $2,
+                closed_at = CURRENT_TIMESTAMP
+            WHERE reservation_id = $1 AND status = 'active'
+        `, *sale.ReservationID, id)
+        if err != nil {
+            return 0, err
+        }
+        if result.RowsAffected() == 0 {
+            return 0, errors.New("reservation is no longer active")
+        }
+    }
+
+    if err := checkCreditLimit(ctx, tx, sale); err != nil {
+        return 0, err
+    }
+
     // Commit the transaction
     if err = tx.Commit(ctx); err != nil {
         return 0, err
@@ -227,6 +346,16 @@ func (r *PostgresSaleRepository) Create(ctx context.Context, sale *salesmodels.S
 }
 
 func (r *PostgresSaleRepository) Update(ctx context.Context, sale *salesmodels.Sale) error {
+    tx, err := r.db.Pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    if err := lockCustomerAccount(ctx, tx, sale); err != nil {
+        return err
+    }
+
     query := `
         UPDATE sales SET
             date = $2,
@@ -239,11 +368,17 @@ func (r *PostgresSaleRepository) Update(ctx context.Context, sale *salesmodels.S
             customer_phone = $9,
             customer_email = $10,
             sold_by = $11,
-            notes = $12
+            notes = $12,
+            customer_id = $13,
+            payment_method = $14,
+            payment_status = $15,
+            due_date = $16,
+            customer_vehicle_id = $17,
+            vehicle_mileage = $18
         WHERE sale_id = $1
     `
 
-    result, err := r.db.Pool.Exec(
+    result, err := tx.Exec(
         ctx, query,
         sale.SaleID,
         sale.Date,
@@ -257,6 +392,12 @@ func (r *PostgresSaleRepository) Update(ctx context.Context, sale *salesmodels.S
         sale.CustomerEmail,
         sale.SoldBy,
         sale.Notes,
+        sale.CustomerID,
+        sale.PaymentMethod,
+        sale.PaymentStatus,
+        sale.DueDate,
+        sale.CustomerVehicleID,
+        sale.VehicleMileage,
     )
 
     if err != nil {
@@ -267,6 +408,45 @@ func (r *PostgresSaleRepository) Update(ctx context.Context, sale *salesmodels.S
         return errors.New("sale not found")
     }
 
+    if err := checkCreditLimit(ctx, tx, sale); err != nil {
+        return err
+    }
+
+    return tx.Commit(ctx)
+}
+
+// lockCustomerAccount locks the customer of an account sale until the
+// transaction ends, so concurrent account sales for the customer are checked
+// against their credit limit one at a time
+func lockCustomerAccount(ctx context.Context, tx pgx.Tx, sale *salesmodels.Sale) error {
+    if sale.PaymentMethod != salesmodels.PaymentMethodAccount || sale.CustomerID == nil {
+        return nil
+    }
+
+    _, err := tx.Exec(ctx, `SELECT 1 FROM customers WHERE customer_id = $1 FOR UPDATE`, *sale.CustomerID)
+    return err
+}
+
+// checkCreditLimit fails when the customer's balance, with the account sale
+// written, is over their credit limit
+func checkCreditLimit(ctx context.Context, tx pgx.Tx, sale *salesmodels.Sale) error {
+    if sale.PaymentMethod != salesmodels.PaymentMethodAccount || sale.CustomerID == nil {
+        return nil
+    }
+
+    var exceeded bool
+    err := tx.QueryRow(ctx, `
+        SELECT `+outstandingBalance+` > c.credit_limit + 0.005
+        FROM customers c
+        WHERE c.customer_id = $1
+    `, *sale.CustomerID).Scan(&exceeded)
+    if err != nil {
+        return err
+    }
+    if exceeded {
+        return ErrCreditLimitExceeded
+    }
+
     return nil
 }
 
@@ -287,41 +467,15 @@ func (r *PostgresSaleRepository) Delete(ctx context.Context, id int) error {
 
 func (r *PostgresSaleRepository) GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.Sale, error) {
     query := `
-        SELECT
-            s.sale_id, s.date, s.item_id, s.quantity,
-            s.price_per_unit, s.total_price, s.transaction_number,
-            s.customer_name, s.customer_phone, s.customer_email,
-            s.sold_by, s.notes, s.created_at, s.updated_at,
-            i.part_number as item_part_number,
-            i.description as item_description,
-            c.category_name
+        SELECT ` + saleColumns + `
         FROM sales s
         JOIN items i ON s.item_id = i.item_id
         LEFT JOIN categories c ON i.category_id = c.category_id
+        JOIN warehouses w ON s.warehouse_id = w.warehouse_id
         WHERE s.transaction_number = $1
     `
 
-    sale := &salesmodels.Sale{}
-    err := r.db.Pool.QueryRow(ctx, query, transactionNumber).Scan(
-        &sale.SaleID,
-        &sale.Date,
-        &sale.ItemID,
-        &sale.Quantity,
-        &sale.PricePerUnit,
-        &sale.TotalPrice,
-        &sale.TransactionNumber,
-        &sale.CustomerName,
-        &sale.CustomerPhone,
-        &sale.CustomerEmail,
-        &sale.SoldBy,
-        &sale.Notes,
-        &sale.CreatedAt,
-        &sale.UpdatedAt,
-        &sale.ItemPartNumber,
-        &sale.ItemDescription,
-        &sale.CategoryName,
-    )
-
+    sale, err := scanSale(r.db.Pool.QueryRow(ctx, query, transactionNumber))
     if err != nil {
         if errors.Is(err, pgx.ErrNoRows) {
             return nil, nil
@@ -332,6 +486,36 @@ func (r *PostgresSaleRepository) GetByTransactionNumber(ctx context.Context, tra
     return sale, nil
 }
 
+// GetLocationStock returns the item's stock available to sell at the given
+// warehouse, or at the default warehouse when none is given: the stock on
+// hand less active reservations, counting the components held by reserved
+// kits and leaving out excludeReservationID. A kit's stock is the number of
+// kits its components make up there. Returns nil if
+// the warehouse does not exist or is inactive.
+func (r *PostgresSaleRepository) GetLocationStock(ctx context.Context, itemID int, warehouseID *int, excludeReservationID *int) (*int, error) {
+    query := `
+        SELECT CASE
+            WHEN (SELECT is_kit FROM items WHERE item_id = $1) THEN kit_available_stock($1, w.warehouse_id, $3::int)
+            ELSE COALESCE(st.quantity, 0) - reserved_stock($1, w.warehouse_id, $3::int)
+        END
+        FROM warehouses w
+        LEFT JOIN item_stock st ON st.warehouse_id = w.warehouse_id AND st.item_id = $1
+        WHERE w.is_active = TRUE
+            AND (w.warehouse_id = $2::int OR ($2::int IS NULL AND w.is_default))
+    `
+
+    var quantity int
+    err := r.db.Pool.QueryRow(ctx, query, itemID, warehouseID, excludeReservationID).Scan(&quantity)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, nil
+        }
+        return nil, err
+    }
+
+    return &quantity, nil
+}
+
 func (r *PostgresSaleRepository) GetItemSales(ctx context.Context, itemID int) ([]*salesmodels.Sale, error) {
     filter := &salesmodels.SaleFilter{
         ItemID: &itemID,
@@ -345,3 +529,184 @@ func (r *PostgresSaleRepository) GetCustomerSales(ctx context.Context, customerE
     }
     return r.GetAll(ctx, filter)
 }
+
+func (r *PostgresSaleRepository) GetItemTrackingMode(ctx context.Context, itemID int) (*string, error) {
+    var mode string
+    err := r.db.Pool.QueryRow(ctx, `SELECT tracking_mode FROM items WHERE item_id = $1`, itemID).Scan(&mode)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, nil
+        }
+        return nil, err
+    }
+
+    return &mode, nil
+}
+
+// GetInStockSerials returns those of the serial numbers that are in stock
+// for the item at the given warehouse, or at the default warehouse when none
+// is given
+func (r *PostgresSaleRepository) GetInStockSerials(ctx context.Context, itemID int, warehouseID *int, serialNumbers []string) ([]string, error) {
+    query := `
+        SELECT serial_number FROM item_serials
+        WHERE item_id = $1 AND serial_number = ANY($2::text[]) AND status = 'in_stock'
+            AND warehouse_id = COALESCE($3::int, (SELECT warehouse_id FROM warehouses WHERE is_default))
+    `
+
+    rows, err := r.db.Pool.Query(ctx, query, itemID, serialNumbers, warehouseID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var serials []string
+    for rows.Next() {
+        var serial string
+        if err := rows.Scan(&serial); err != nil {
+            return nil, err
+        }
+        serials = append(serials, serial)
+    }
+
+    return serials, rows.Err()
+}
+
+// GetLotsRemaining returns the quantity left in each of the item's lots,
+// keyed by lot ID. Lots of other items are left out.
+func (r *PostgresSaleRepository) GetLotsRemaining(ctx context.Context, itemID int, lotIDs []int) (map[int]int, error) {
+    query := `
+        SELECT lot_id, quantity_remaining FROM item_lots
+        WHERE item_id = $1 AND lot_id = ANY($2::int[])
+    `
+
+    rows, err := r.db.Pool.Query(ctx, query, itemID, lotIDs)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    remaining := make(map[int]int)
+    for rows.Next() {
+        var lotID, quantity int
+        if err := rows.Scan(&lotID, &quantity); err != nil {
+            return nil, err
+        }
+        remaining[lotID] = quantity
+    }
+
+    return remaining, rows.Err()
+}
+
+// GetTrackedUnits returns the serial numbers or lots a sale was made from
+func (r *PostgresSaleRepository) GetTrackedUnits(ctx context.Context, saleID int) ([]string, []*salesmodels.SaleLot, error) {
+    rows, err := r.db.Pool.Query(ctx, `
+        SELECT serial_number FROM item_serials
+        WHERE sale_id = $1
+        ORDER BY serial_number
+    `, saleID)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    var serials []string
+    for rows.Next() {
+        var serial string
+        if err := rows.Scan(&serial); err != nil {
+            rows.Close()
+            return nil, nil, err
+        }
+        serials = append(serials, serial)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, nil, err
+    }
+
+    rows, err = r.db.Pool.Query(ctx, `
+        SELECT sl.lot_id, l.lot_number, sl.quantity
+        FROM sale_lots sl
+        JOIN item_lots l ON sl.lot_id = l.lot_id
+        WHERE sl.sale_id = $1
+        ORDER BY l.lot_number
+    `, saleID)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer rows.Close()
+
+    var lots []*salesmodels.SaleLot
+    for rows.Next() {
+        lot := &salesmodels.SaleLot{}
+        if err := rows.Scan(&lot.LotID, &lot.LotNumber, &lot.Quantity); err != nil {
+            return nil, nil, err
+        }
+        lots = append(lots, lot)
+    }
+
+    return serials, lots, rows.Err()
+}
+
+// GetCoresReturned counts the old units brought back against the sale's
+// core deposit
+func (r *PostgresSaleRepository) GetCoresReturned(ctx context.Context, saleID int) (int, error) {
+    var quantity int
+    err := r.db.Pool.QueryRow(ctx, `
+        SELECT COALESCE(SUM(quantity), 0) FROM core_returns WHERE sale_id = $1
+    `, saleID).Scan(&quantity)
+    return quantity, err
+}
+
+// GetWarrantyClaimCount counts the warranty claims made against the sale
+func (r *PostgresSaleRepository) GetWarrantyClaimCount(ctx context.Context, saleID int) (int, error) {
+    var count int
+    err := r.db.Pool.QueryRow(ctx, `
+        SELECT COUNT(*) FROM warranty_claims WHERE sale_id = $1
+    `, saleID).Scan(&count)
+    return count, err
+}
+
+// GetVehicleCustomerID returns the customer a vehicle is registered to, or
+// nil when there is no such vehicle
+func (r *PostgresSaleRepository) GetVehicleCustomerID(ctx context.Context, vehicleID int) (*int, error) {
+    var customerID int
+    err := r.db.Pool.QueryRow(ctx, `
+        SELECT customer_id FROM customer_vehicles WHERE vehicle_id = $1
+    `, vehicleID).Scan(&customerID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, nil
+        }
+        return nil, err
+    }
+
+    return &customerID, nil
+}
+
+// GetItemReplacement returns the current part for a superseded item with its
+// stock free to sell at the warehouse, or at the default warehouse when none
+// is given. Returns nil if the item has not been superseded.
+func (r *PostgresSaleRepository) GetItemReplacement(ctx context.Context, itemID int, warehouseID *int) (*salesmodels.ItemReplacement, error) {
+    query := `
+        SELECT o.part_number, c.part_number,
+               (SELECT bool_and(ch.is_direct_interchange) FROM supersession_chain(o.item_id) ch),
+               available_stock(c.item_id, w.warehouse_id)
+        FROM items o
+        JOIN items c ON c.item_id = current_item_id(o.item_id)
+        JOIN warehouses w ON w.warehouse_id = $2::int OR ($2::int IS NULL AND w.is_default)
+        WHERE o.item_id = $1 AND c.item_id <> o.item_id
+    `
+
+    replacement := &salesmodels.ItemReplacement{}
+    err := r.db.Pool.QueryRow(ctx, query, itemID, warehouseID).Scan(
+        &replacement.PartNumber, &replacement.ReplacementPartNumber,
+        &replacement.IsDirectInterchange, &replacement.AvailableStock,
+    )
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, nil
+        }
+        return nil, err
+    }
+
+    return replacement, nil
+}
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresReservationRepository struct {
+	db *db.Database
+}
+
+func NewPostgresReservationRepository(database *db.Database) ReservationRepository {
+	return &PostgresReservationRepository{
+		db: database,
+	}
+}
+
+const reservationQuery = `
+	SELECT
+		r.reservation_id, r.item_id, r.warehouse_id, r.quantity, r.customer_id,
+		r.customer_name, r.customer_phone, r.reference, r.status, r.expires_at,
+		r.sale_id, r.notes, r.created_by, r.closed_at, r.created_at, r.updated_at,
+		i.part_number as item_part_number,
+		i.description as item_description,
+		i.sell_price as item_sell_price,
+		w.code as warehouse_code,
+		(SELECT so.status FROM special_orders so
+		 WHERE so.reservation_id = r.reservation_id
+		   AND so.status IN ('requested', 'ordered', 'ready')) as special_order_status
+	FROM stock_reservations r
+	JOIN items i ON r.item_id = i.item_id
+	JOIN warehouses w ON r.warehouse_id = w.warehouse_id
+`
+
+func scanReservation(row pgx.Row) (*salesmodels.Reservation, error) {
+	reservation := &salesmodels.Reservation{}
+	err := row.Scan(
+		&reservation.ReservationID,
+		&reservation.ItemID,
+		&reservation.WarehouseID,
+		&reservation.Quantity,
+		&reservation.CustomerID,
+		&reservation.CustomerName,
+		&reservation.CustomerPhone,
+		&reservation.Reference,
+		&reservation.Status,
+		&reservation.ExpiresAt,
+		&reservation.SaleID,
+		&reservation.Notes,
+		&reservation.CreatedBy,
+		&reservation.ClosedAt,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+		&reservation.ItemPartNumber,
+		&reservation.ItemDescription,
+		&reservation.ItemSellPrice,
+		&reservation.WarehouseCode,
+		&reservation.SpecialOrderStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (r *PostgresReservationRepository) GetAll(ctx context.Context, filter *salesmodels.ReservationFilter) ([]*salesmodels.Reservation, error) {
+	query := reservationQuery + " WHERE 1=1"
+
+	var params []interface{}
+	paramCount := 1
+
+	if filter.ItemID != nil {
+		query += fmt.Sprintf(" AND r.item_id = $%d", paramCount)
+		params = append(params, *filter.ItemID)
+		paramCount++
+	}
+
+	if filter.WarehouseID != nil {
+		query += fmt.Sprintf(" AND r.warehouse_id = $%d", paramCount)
+		params = append(params, *filter.WarehouseID)
+		paramCount++
+	}
+
+	if filter.CustomerID != nil {
+		query += fmt.Sprintf(" AND r.customer_id = $%d", paramCount)
+		params = append(params, *filter.CustomerID)
+		paramCount++
+	}
+
+	if filter.Status != nil {
+		query += fmt.Sprintf(" AND r.status = $%d", paramCount)
+		params = append(params, *filter.Status)
+		paramCount++
+	}
+
+	if filter.Reference != nil {
+		query += fmt.Sprintf(" AND r.reference ILIKE $%d", paramCount)
+		params = append(params, "%"+*filter.Reference+"%")
+		paramCount++
+	}
+
+	query += " ORDER BY r.expires_at, r.reservation_id"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []*salesmodels.Reservation
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, rows.Err()
+}
+
+func (r *PostgresReservationRepository) GetByID(ctx context.Context, id int) (*salesmodels.Reservation, error) {
+	reservation, err := scanReservation(r.db.Pool.QueryRow(ctx, reservationQuery+" WHERE r.reservation_id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (r *PostgresReservationRepository) Create(ctx context.Context, reservation *salesmodels.Reservation) (int, error) {
+	query := `
+		INSERT INTO stock_reservations (
+			item_id, warehouse_id, quantity, customer_id, customer_name,
+			customer_phone, reference, expires_at, notes, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING reservation_id
+	`
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		reservation.ItemID,
+		reservation.WarehouseID,
+		reservation.Quantity,
+		reservation.CustomerID,
+		reservation.CustomerName,
+		reservation.CustomerPhone,
+		reservation.Reference,
+		reservation.ExpiresAt,
+		reservation.Notes,
+		reservation.CreatedBy,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update changes the quantity, expiry and details of an active reservation
+func (r *PostgresReservationRepository) Update(ctx context.Context, reservation *salesmodels.Reservation) error {
+	query := `
+		UPDATE stock_reservations SET
+			quantity = $2,
+			expires_at = $3,
+			customer_name = $4,
+			customer_phone = $5,
+			reference = $6,
+			notes = $7
+		WHERE reservation_id = $1 AND status = 'active'
+	`
+
+	result, err := r.db.Pool.Exec(
+		ctx, query,
+		reservation.ReservationID,
+		reservation.Quantity,
+		reservation.ExpiresAt,
+		reservation.CustomerName,
+		reservation.CustomerPhone,
+		reservation.Reference,
+		reservation.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("reservation is no longer active")
+	}
+
+	return nil
+}
+
+func (r *PostgresReservationRepository) Release(ctx context.Context, id int) error {
+	query := `
+		UPDATE stock_reservations SET status = 'released', closed_at = CURRENT_TIMESTAMP
+		WHERE reservation_id = $1 AND status = 'active'
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("reservation is no longer active")
+	}
+
+	return nil
+}
+
+// ExpireDue closes every active reservation past its expiry and returns how
+// many were closed
+func (r *PostgresReservationRepository) ExpireDue(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE stock_reservations SET status = 'expired', closed_at = expires_at
+		WHERE status = 'active' AND expires_at <= CURRENT_TIMESTAMP
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+)
+
+type ReceivablesRepository interface {
+	GetCustomerAccount(ctx context.Context, customerID int) (*salesmodels.CustomerAccount, error)
+
+	// Invoice operations
+	GetInvoices(ctx context.Context, filter *salesmodels.InvoiceFilter) ([]*salesmodels.Invoice, error)
+	GetInvoiceByID(ctx context.Context, id int) (*salesmodels.Invoice, error)
+	CreateInvoice(ctx context.Context, invoice *salesmodels.Invoice, saleIDs []int) (int, error)
+	VoidInvoice(ctx context.Context, id int) error
+
+	// Payment operations
+	GetPayments(ctx context.Context, filter *salesmodels.PaymentFilter) ([]*salesmodels.Payment, error)
+	GetPaymentByID(ctx context.Context, id int) (*salesmodels.Payment, error)
+	CreatePayment(ctx context.Context, payment *salesmodels.Payment) (int, error)
+
+	// Reporting
+	GetOpenReceivables(ctx context.Context, customerID *int, asOf time.Time) ([]*salesmodels.OpenReceivable, error)
+	GetBalanceBefore(ctx context.Context, customerID int, before time.Time) (float64, error)
+	GetStatementLines(ctx context.Context, customerID int, start, end time.Time) ([]*salesmodels.StatementLine, error)
+}
@@ -13,6 +13,25 @@ type SaleRepository interface {
     Update(ctx context.Context, sale *salesmodels.Sale) error
     Delete(ctx context.Context, id int) error
     GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.Sale, error)
+    GetLocationStock(ctx context.Context, itemID int, warehouseID *int, excludeReservationID *int) (*int, error)
     GetItemSales(ctx context.Context, itemID int) ([]*salesmodels.Sale, error)
     GetCustomerSales(ctx context.Context, customerEmail string) ([]*salesmodels.Sale, error)
+
+    // Serial and lot tracking
+    GetItemTrackingMode(ctx context.Context, itemID int) (*string, error)
+    GetInStockSerials(ctx context.Context, itemID int, warehouseID *int, serialNumbers []string) ([]string, error)
+    GetLotsRemaining(ctx context.Context, itemID int, lotIDs []int) (map[int]int, error)
+    GetTrackedUnits(ctx context.Context, saleID int) ([]string, []*salesmodels.SaleLot, error)
+
+    // Core returns
+    GetCoresReturned(ctx context.Context, saleID int) (int, error)
+
+    // Warranty claims
+    GetWarrantyClaimCount(ctx context.Context, saleID int) (int, error)
+
+    // Customer vehicles
+    GetVehicleCustomerID(ctx context.Context, vehicleID int) (*int, error)
+
+    // Supersessions
+    GetItemReplacement(ctx context.Context, itemID int, warehouseID *int) (*salesmodels.ItemReplacement, error)
 }
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+)
+
+type ReservationRepository interface {
+	GetAll(ctx context.Context, filter *salesmodels.ReservationFilter) ([]*salesmodels.Reservation, error)
+	GetByID(ctx context.Context, id int) (*salesmodels.Reservation, error)
+	Create(ctx context.Context, reservation *salesmodels.Reservation) (int, error)
+	Update(ctx context.Context, reservation *salesmodels.Reservation) error
+	Release(ctx context.Context, id int) error
+	ExpireDue(ctx context.Context) (int64, error)
+}
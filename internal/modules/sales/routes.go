@@ -1,22 +1,32 @@
 package sales
 
 import (
+	"context"
+
 	"github.com/hsrvms/autoparts/internal/modules/sales/handlers"
 	"github.com/hsrvms/autoparts/internal/modules/sales/repositories"
 	"github.com/hsrvms/autoparts/internal/modules/sales/services"
+	"github.com/hsrvms/autoparts/pkg/config"
 	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/scheduler"
 	"github.com/labstack/echo/v4"
 )
 
 func RegisterRoutes(api *echo.Group, database *db.Database) {
     // Initialize repository
     repo := repositories.NewPostgresSaleRepository(database)
+    receivablesRepo := repositories.NewPostgresReceivablesRepository(database)
+    reservationRepo := repositories.NewPostgresReservationRepository(database)
 
     // Initialize service
-    service := services.NewSaleService(repo)
+    service := services.NewSaleService(repo, receivablesRepo, reservationRepo)
+    receivablesService := services.NewReceivablesService(receivablesRepo, repo)
+    reservationService := services.NewReservationService(reservationRepo, repo, receivablesRepo, service)
 
     // Initialize handler
     handler := handlers.NewSaleHandler(service)
+    receivablesHandler := handlers.NewReceivablesHandler(receivablesService)
+    reservationHandler := handlers.NewReservationHandler(reservationService)
 
     // Register routes
     sales := api.Group("/sales")
@@ -27,4 +37,46 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
     sales.DELETE("/:id", handler.DeleteSale)
     sales.GET("/transaction/:transactionNumber", handler.GetByTransactionNumber)
     sales.GET("/customer/:customerEmail", handler.GetCustomerSales)
+
+    // Accounts receivable
+    invoices := api.Group("/invoices")
+    invoices.GET("", receivablesHandler.GetInvoices)
+    invoices.GET("/:id", receivablesHandler.GetInvoiceByID)
+    invoices.POST("", receivablesHandler.CreateInvoice)
+    invoices.POST("/:id/void", receivablesHandler.VoidInvoice)
+
+    payments := api.Group("/payments")
+    payments.GET("", receivablesHandler.GetPayments)
+    payments.GET("/:id", receivablesHandler.GetPaymentByID)
+    payments.POST("", receivablesHandler.RecordPayment)
+
+    api.GET("/receivables/aging", receivablesHandler.GetAgingReport)
+    api.GET("/customers/:customerId/account", receivablesHandler.GetCustomerAccount)
+    api.GET("/customers/:customerId/statement", receivablesHandler.GetCustomerStatement)
+
+    // Stock reservations
+    reservations := api.Group("/reservations")
+    reservations.GET("", reservationHandler.GetReservations)
+    reservations.GET("/:id", reservationHandler.GetReservationByID)
+    reservations.POST("", reservationHandler.CreateReservation)
+    reservations.PUT("/:id", reservationHandler.UpdateReservation)
+    reservations.POST("/:id/release", reservationHandler.ReleaseReservation)
+    reservations.POST("/:id/convert", reservationHandler.ConvertReservation)
+}
+
+// RegisterJobs schedules the expiry of lapsed reservations
+func RegisterJobs(s *scheduler.Scheduler, database *db.Database, cfg config.JobsConfig) {
+    repo := repositories.NewPostgresSaleRepository(database)
+    receivablesRepo := repositories.NewPostgresReceivablesRepository(database)
+    reservationRepo := repositories.NewPostgresReservationRepository(database)
+
+    service := services.NewReservationService(
+        reservationRepo, repo, receivablesRepo,
+        services.NewSaleService(repo, receivablesRepo, reservationRepo),
+    )
+
+    s.Every("reservation-expiry", cfg.ReservationExpiryInterval, func(ctx context.Context) error {
+        _, err := service.ExpireDue(ctx)
+        return err
+    })
 }
@@ -0,0 +1,460 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+	"github.com/hsrvms/autoparts/internal/modules/sales/repositories"
+)
+
+var (
+	ErrInvalidCustomerID        = errors.New("invalid customer ID")
+	ErrInvalidInvoiceID         = errors.New("invalid invoice ID")
+	ErrInvoiceNotFound          = errors.New("invoice not found")
+	ErrNoSalesToInvoice         = errors.New("at least one sale is required to create an invoice")
+	ErrDuplicateSaleOnInvoice   = errors.New("sale is listed more than once")
+	ErrSaleNotOnAccount         = errors.New("only sales on account can be invoiced")
+	ErrSaleCustomerMismatch     = errors.New("sale belongs to a different customer")
+	ErrSaleAlreadyInvoiced      = errors.New("sale has already been invoiced")
+	ErrInvalidDueDate           = errors.New("due date cannot be before the issue date")
+	ErrInvoiceVoid              = errors.New("invoice has been voided")
+	ErrInvoiceHasPayments       = errors.New("invoice has payments allocated and cannot be voided")
+	ErrInvalidPaymentID         = errors.New("invalid payment ID")
+	ErrPaymentNotFound          = errors.New("payment not found")
+	ErrInvalidReceiptMethod     = errors.New("payment method must be cash, card or bank_transfer")
+	ErrInvalidPaymentAmount     = errors.New("payment amount must be greater than 0")
+	ErrAllocationMismatch       = errors.New("allocations must add up to the payment amount")
+	ErrInvalidAllocation        = errors.New("allocation amount must be greater than 0")
+	ErrAllocationExceedsBalance = errors.New("allocation exceeds the invoice balance")
+	ErrPaymentExceedsOpenItems  = errors.New("payment exceeds the customer's open invoices")
+	ErrInvalidStatementPeriod   = errors.New("statement start date must be before the end date")
+)
+
+type ReceivablesService interface {
+	GetCustomerAccount(ctx context.Context, customerID int) (*salesmodels.CustomerAccount, error)
+
+	GetInvoices(ctx context.Context, filter *salesmodels.InvoiceFilter) ([]*salesmodels.Invoice, error)
+	GetInvoiceByID(ctx context.Context, id int) (*salesmodels.Invoice, error)
+	CreateInvoice(ctx context.Context, req *salesmodels.CreateInvoiceRequest) (*salesmodels.Invoice, error)
+	VoidInvoice(ctx context.Context, id int) error
+
+	GetPayments(ctx context.Context, filter *salesmodels.PaymentFilter) ([]*salesmodels.Payment, error)
+	GetPaymentByID(ctx context.Context, id int) (*salesmodels.Payment, error)
+	RecordPayment(ctx context.Context, payment *salesmodels.Payment) (*salesmodels.Payment, error)
+
+	GetAgingReport(ctx context.Context, asOf time.Time) (*salesmodels.AgingReport, error)
+	GetCustomerStatement(ctx context.Context, customerID int, start, end time.Time) (*salesmodels.CustomerStatement, error)
+}
+
+type receivablesService struct {
+	repo      repositories.ReceivablesRepository
+	salesRepo repositories.SaleRepository
+}
+
+func NewReceivablesService(repo repositories.ReceivablesRepository, salesRepo repositories.SaleRepository) ReceivablesService {
+	return &receivablesService{
+		repo:      repo,
+		salesRepo: salesRepo,
+	}
+}
+
+func (s *receivablesService) GetCustomerAccount(ctx context.Context, customerID int) (*salesmodels.CustomerAccount, error) {
+	if customerID <= 0 {
+		return nil, ErrInvalidCustomerID
+	}
+
+	account, err := s.repo.GetCustomerAccount(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	return account, nil
+}
+
+func (s *receivablesService) GetInvoices(ctx context.Context, filter *salesmodels.InvoiceFilter) ([]*salesmodels.Invoice, error) {
+	return s.repo.GetInvoices(ctx, filter)
+}
+
+func (s *receivablesService) GetInvoiceByID(ctx context.Context, id int) (*salesmodels.Invoice, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInvoiceID
+	}
+
+	invoice, err := s.repo.GetInvoiceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotFound
+	}
+
+	invoice.Sales, err = s.salesRepo.GetAll(ctx, &salesmodels.SaleFilter{InvoiceID: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (s *receivablesService) CreateInvoice(ctx context.Context, req *salesmodels.CreateInvoiceRequest) (*salesmodels.Invoice, error) {
+	if req.CustomerID <= 0 {
+		return nil, ErrInvalidCustomerID
+	}
+	if len(req.SaleIDs) == 0 {
+		return nil, ErrNoSalesToInvoice
+	}
+
+	account, err := s.repo.GetCustomerAccount(ctx, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	var total float64
+	seen := make(map[int]bool, len(req.SaleIDs))
+	for _, saleID := range req.SaleIDs {
+		if seen[saleID] {
+			return nil, ErrDuplicateSaleOnInvoice
+		}
+		seen[saleID] = true
+
+		sale, err := s.salesRepo.GetByID(ctx, saleID)
+		if err != nil {
+			return nil, err
+		}
+		if sale == nil {
+			return nil, ErrSaleNotFound
+		}
+		if sale.PaymentMethod != salesmodels.PaymentMethodAccount {
+			return nil, ErrSaleNotOnAccount
+		}
+		if !sameCustomer(sale.CustomerID, &req.CustomerID) {
+			return nil, ErrSaleCustomerMismatch
+		}
+		if sale.InvoiceID != nil {
+			return nil, ErrSaleAlreadyInvoiced
+		}
+		total += sale.TotalPrice + sale.CoreDeposit
+	}
+
+	issueDate := truncateToDay(time.Now())
+	if req.IssueDate != nil {
+		issueDate = truncateToDay(*req.IssueDate)
+	}
+
+	dueDate := issueDate.AddDate(0, 0, account.PaymentTermsDays)
+	if req.DueDate != nil {
+		dueDate = truncateToDay(*req.DueDate)
+	}
+	if dueDate.Before(issueDate) {
+		return nil, ErrInvalidDueDate
+	}
+
+	invoice := &salesmodels.Invoice{
+		CustomerID:  req.CustomerID,
+		IssueDate:   issueDate,
+		DueDate:     dueDate,
+		TotalAmount: roundCents(total),
+		Notes:       req.Notes,
+	}
+
+	id, err := s.repo.CreateInvoice(ctx, invoice, req.SaleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetInvoiceByID(ctx, id)
+}
+
+func (s *receivablesService) VoidInvoice(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidInvoiceID
+	}
+
+	invoice, err := s.repo.GetInvoiceByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if invoice == nil {
+		return ErrInvoiceNotFound
+	}
+	if invoice.Status == salesmodels.InvoiceStatusVoid {
+		return ErrInvoiceVoid
+	}
+	if invoice.AmountPaid > 0 {
+		return ErrInvoiceHasPayments
+	}
+
+	return s.repo.VoidInvoice(ctx, id)
+}
+
+func (s *receivablesService) GetPayments(ctx context.Context, filter *salesmodels.PaymentFilter) ([]*salesmodels.Payment, error) {
+	return s.repo.GetPayments(ctx, filter)
+}
+
+func (s *receivablesService) GetPaymentByID(ctx context.Context, id int) (*salesmodels.Payment, error) {
+	if id <= 0 {
+		return nil, ErrInvalidPaymentID
+	}
+
+	payment, err := s.repo.GetPaymentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	return payment, nil
+}
+
+// RecordPayment stores a customer payment. Explicit allocations are checked
+// against each invoice's balance; without them the payment is applied to the
+// oldest open invoices first.
+func (s *receivablesService) RecordPayment(ctx context.Context, payment *salesmodels.Payment) (*salesmodels.Payment, error) {
+	if err := s.validatePayment(payment); err != nil {
+		return nil, err
+	}
+
+	account, err := s.repo.GetCustomerAccount(ctx, payment.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	if payment.PaymentDate.IsZero() {
+		payment.PaymentDate = time.Now()
+	}
+	payment.Amount = roundCents(payment.Amount)
+
+	openOnly := true
+	openInvoices, err := s.repo.GetInvoices(ctx, &salesmodels.InvoiceFilter{
+		CustomerID: &payment.CustomerID,
+		OpenOnly:   &openOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payment.Allocations) == 0 {
+		payment.Allocations, err = allocateOldestFirst(payment.Amount, openInvoices)
+	} else {
+		err = checkAllocations(payment, openInvoices)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.CreatePayment(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetPaymentByID(ctx, id)
+}
+
+// GetAgingReport buckets every open receivable by how many days past due it
+// was on asOf. Items that are not yet due fall into the 0-30 bucket.
+func (s *receivablesService) GetAgingReport(ctx context.Context, asOf time.Time) (*salesmodels.AgingReport, error) {
+	asOf = truncateToDay(asOf)
+
+	open, err := s.repo.GetOpenReceivables(ctx, nil, asOf.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &salesmodels.AgingReport{
+		AsOf:      asOf,
+		Customers: []*salesmodels.CustomerAging{},
+	}
+
+	var current *salesmodels.CustomerAging
+	for _, item := range open {
+		if current == nil || current.CustomerID != item.CustomerID {
+			current = &salesmodels.CustomerAging{
+				CustomerID:   item.CustomerID,
+				CustomerName: item.CustomerName,
+			}
+			report.Customers = append(report.Customers, current)
+		}
+		addToBucket(&current.AgingBuckets, item, asOf)
+		addToBucket(&report.Totals, item, asOf)
+	}
+
+	return report, nil
+}
+
+// GetCustomerStatement lists account activity in [start, end] with a running
+// balance, followed by the customer's aging at the end of the period.
+func (s *receivablesService) GetCustomerStatement(ctx context.Context, customerID int, start, end time.Time) (*salesmodels.CustomerStatement, error) {
+	if customerID <= 0 {
+		return nil, ErrInvalidCustomerID
+	}
+
+	start = truncateToDay(start)
+	end = truncateToDay(end)
+	if end.Before(start) {
+		return nil, ErrInvalidStatementPeriod
+	}
+	periodEnd := end.AddDate(0, 0, 1)
+
+	account, err := s.repo.GetCustomerAccount(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	opening, err := s.repo.GetBalanceBefore(ctx, customerID, start)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.GetStatementLines(ctx, customerID, start, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := opening
+	for _, line := range lines {
+		balance = roundCents(balance + line.Debit - line.Credit)
+		line.Balance = balance
+	}
+	if lines == nil {
+		lines = []*salesmodels.StatementLine{}
+	}
+
+	open, err := s.repo.GetOpenReceivables(ctx, &customerID, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &salesmodels.CustomerStatement{
+		CustomerID:     account.CustomerID,
+		CustomerName:   account.CustomerName,
+		StartDate:      start,
+		EndDate:        end,
+		OpeningBalance: opening,
+		ClosingBalance: balance,
+		Lines:          lines,
+	}
+	for _, item := range open {
+		addToBucket(&statement.Aging, item, end)
+	}
+
+	return statement, nil
+}
+
+// Helper functions
+func (s *receivablesService) validatePayment(payment *salesmodels.Payment) error {
+	if payment.CustomerID <= 0 {
+		return ErrInvalidCustomerID
+	}
+	if payment.Amount <= 0 {
+		return ErrInvalidPaymentAmount
+	}
+
+	switch payment.Method {
+	case salesmodels.PaymentMethodCash, salesmodels.PaymentMethodCard, salesmodels.PaymentMethodBankTransfer:
+	default:
+		return ErrInvalidReceiptMethod
+	}
+
+	return nil
+}
+
+func allocateOldestFirst(amount float64, invoices []*salesmodels.Invoice) ([]*salesmodels.PaymentAllocation, error) {
+	var allocations []*salesmodels.PaymentAllocation
+	remaining := amount
+
+	// Invoices come back ordered by due date, oldest first
+	for _, invoice := range invoices {
+		if remaining <= 0 {
+			break
+		}
+		applied := math.Min(remaining, roundCents(invoice.BalanceDue))
+		if applied <= 0 {
+			continue
+		}
+		allocations = append(allocations, &salesmodels.PaymentAllocation{
+			InvoiceID: invoice.InvoiceID,
+			Amount:    applied,
+		})
+		remaining = roundCents(remaining - applied)
+	}
+
+	if remaining > 0 {
+		return nil, ErrPaymentExceedsOpenItems
+	}
+
+	return allocations, nil
+}
+
+func checkAllocations(payment *salesmodels.Payment, invoices []*salesmodels.Invoice) error {
+	byID := make(map[int]*salesmodels.Invoice, len(invoices))
+	for _, invoice := range invoices {
+		byID[invoice.InvoiceID] = invoice
+	}
+
+	var total float64
+	allocated := make(map[int]float64)
+	for _, allocation := range payment.Allocations {
+		allocation.Amount = roundCents(allocation.Amount)
+		if allocation.Amount <= 0 {
+			return ErrInvalidAllocation
+		}
+
+		invoice, ok := byID[allocation.InvoiceID]
+		if !ok {
+			return ErrInvoiceNotFound
+		}
+
+		allocated[invoice.InvoiceID] = roundCents(allocated[invoice.InvoiceID] + allocation.Amount)
+		if allocated[invoice.InvoiceID] > roundCents(invoice.BalanceDue) {
+			return ErrAllocationExceedsBalance
+		}
+		total += allocation.Amount
+	}
+
+	if roundCents(total) != payment.Amount {
+		return ErrAllocationMismatch
+	}
+
+	return nil
+}
+
+func addToBucket(buckets *salesmodels.AgingBuckets, item *salesmodels.OpenReceivable, asOf time.Time) {
+	daysPastDue := int(asOf.Sub(truncateToDay(item.DueDate)).Hours() / 24)
+
+	switch {
+	case daysPastDue <= 30:
+		buckets.Days0To30 = roundCents(buckets.Days0To30 + item.Balance)
+	case daysPastDue <= 60:
+		buckets.Days31To60 = roundCents(buckets.Days31To60 + item.Balance)
+	case daysPastDue <= 90:
+		buckets.Days61To90 = roundCents(buckets.Days61To90 + item.Balance)
+	default:
+		buckets.Over90 = roundCents(buckets.Over90 + item.Balance)
+	}
+	buckets.Total = roundCents(buckets.Total + item.Balance)
+}
+
+func truncateToDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+	"github.com/hsrvms/autoparts/internal/modules/sales/repositories"
+)
+
+// DefaultReservationHold is how long a reservation lasts when no expiry is given
+const DefaultReservationHold = 48 * time.Hour
+
+var (
+	ErrReservationNotFound      = errors.New("reservation not found")
+	ErrInvalidReservationID     = errors.New("invalid reservation ID")
+	ErrInvalidReservationStatus = errors.New("status must be active, converted, released or expired")
+	ErrReservationNotActive     = errors.New("reservation is no longer active")
+	ErrReservationCustomer      = errors.New("customer ID or customer name is required")
+	ErrInvalidExpiry            = errors.New("expiry must be in the future")
+	ErrInsufficientAvailable    = errors.New("not enough stock available to reserve")
+	ErrReservationMismatch      = errors.New("sale does not match the reserved item, quantity or warehouse")
+	ErrSpecialOrderNotReady     = errors.New("units held for a special order cannot be sold until the order is ready")
+)
+
+type ReservationService interface {
+	GetAll(ctx context.Context, filter *salesmodels.ReservationFilter) ([]*salesmodels.Reservation, error)
+	GetByID(ctx context.Context, id int) (*salesmodels.Reservation, error)
+	Create(ctx context.Context, reservation *salesmodels.Reservation) (int, error)
+	Update(ctx context.Context, reservation *salesmodels.Reservation) error
+	Release(ctx context.Context, id int) error
+	Convert(ctx context.Context, id int, req *salesmodels.ConvertReservationRequest) (*salesmodels.Sale, error)
+	ExpireDue(ctx context.Context) (int64, error)
+}
+
+type reservationService struct {
+	repo        repositories.ReservationRepository
+	sales       repositories.SaleRepository
+	receivables repositories.ReceivablesRepository
+	saleService SaleService
+}
+
+func NewReservationService(repo repositories.ReservationRepository, sales repositories.SaleRepository, receivables repositories.ReceivablesRepository, saleService SaleService) ReservationService {
+	return &reservationService{
+		repo:        repo,
+		sales:       sales,
+		receivables: receivables,
+		saleService: saleService,
+	}
+}
+
+func (s *reservationService) GetAll(ctx context.Context, filter *salesmodels.ReservationFilter) ([]*salesmodels.Reservation, error) {
+	if filter.Status != nil {
+		switch *filter.Status {
+		case salesmodels.ReservationStatusActive, salesmodels.ReservationStatusConverted,
+			salesmodels.ReservationStatusReleased, salesmodels.ReservationStatusExpired:
+		default:
+			return nil, ErrInvalidReservationStatus
+		}
+	}
+
+	reservations, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if reservations == nil {
+		reservations = []*salesmodels.Reservation{}
+	}
+	return reservations, nil
+}
+
+func (s *reservationService) GetByID(ctx context.Context, id int) (*salesmodels.Reservation, error) {
+	if id <= 0 {
+		return nil, ErrInvalidReservationID
+	}
+
+	reservation, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reservation == nil {
+		return nil, ErrReservationNotFound
+	}
+
+	return reservation, nil
+}
+
+// Create reserves stock at a warehouse, the default one if none is given.
+// Only stock that is not already reserved can be held.
+func (s *reservationService) Create(ctx context.Context, reservation *salesmodels.Reservation) (int, error) {
+	if reservation.ItemID <= 0 {
+		return 0, ErrInvalidItemID
+	}
+	if err := validateReservation(reservation); err != nil {
+		return 0, err
+	}
+
+	if reservation.CustomerID != nil {
+		account, err := s.receivables.GetCustomerAccount(ctx, *reservation.CustomerID)
+		if err != nil {
+			return 0, err
+		}
+		if account == nil {
+			return 0, ErrCustomerNotFound
+		}
+		if !account.IsActive {
+			return 0, ErrCustomerInactive
+		}
+		if reservation.CustomerName == nil {
+			reservation.CustomerName = &account.CustomerName
+		}
+	}
+
+	if err := s.checkAvailable(ctx, reservation, nil); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(ctx, reservation)
+}
+
+// Update changes the quantity, expiry or details of an active reservation.
+// The item, warehouse and customer account stay as they were.
+func (s *reservationService) Update(ctx context.Context, reservation *salesmodels.Reservation) error {
+	current, err := s.GetByID(ctx, reservation.ReservationID)
+	if err != nil {
+		return err
+	}
+	if current.Status != salesmodels.ReservationStatusActive {
+		return ErrReservationNotActive
+	}
+
+	reservation.ItemID = current.ItemID
+	reservation.WarehouseID = current.WarehouseID
+	reservation.CustomerID = current.CustomerID
+	if reservation.CustomerName == nil {
+		reservation.CustomerName = current.CustomerName
+	}
+	if reservation.ExpiresAt.IsZero() {
+		reservation.ExpiresAt = current.ExpiresAt
+	}
+	if err := validateReservation(reservation); err != nil {
+		return err
+	}
+
+	if err := s.checkAvailable(ctx, reservation, &current.ReservationID); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, reservation)
+}
+
+func (s *reservationService) Release(ctx context.Context, id int) error {
+	reservation, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if reservation.Status != salesmodels.ReservationStatusActive {
+		return ErrReservationNotActive
+	}
+
+	return s.repo.Release(ctx, id)
+}
+
+// Convert sells the reserved stock to the reservation's customer and closes
+// the reservation in the same transaction
+func (s *reservationService) Convert(ctx context.Context, id int, req *salesmodels.ConvertReservationRequest) (*salesmodels.Sale, error) {
+	reservation, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isOpen(reservation) {
+		return nil, ErrReservationNotActive
+	}
+
+	price := reservation.ItemSellPrice
+	if req.PricePerUnit != nil {
+		price = *req.PricePerUnit
+	}
+
+	sale := &salesmodels.Sale{
+		ItemID:            reservation.ItemID,
+		Quantity:          reservation.Quantity,
+		PricePerUnit:      price,
+		TransactionNumber: req.TransactionNumber,
+		CustomerName:      reservation.CustomerName,
+		CustomerPhone:     reservation.CustomerPhone,
+		CustomerID:        reservation.CustomerID,
+		PaymentMethod:     req.PaymentMethod,
+		SoldBy:            req.SoldBy,
+		Notes:             req.Notes,
+		WarehouseID:       reservation.WarehouseID,
+		ReservationID:     &reservation.ReservationID,
+		SerialNumbers:     req.SerialNumbers,
+		Lots:              req.Lots,
+	}
+
+	saleID, err := s.saleService.Create(ctx, sale)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saleService.GetByID(ctx, saleID)
+}
+
+// ExpireDue closes the reservations past their expiry. Run by the
+// reservation expiry job.
+func (s *reservationService) ExpireDue(ctx context.Context) (int64, error) {
+	return s.repo.ExpireDue(ctx)
+}
+
+// Helper functions
+
+func (s *reservationService) checkAvailable(ctx context.Context, reservation *salesmodels.Reservation, excludeID *int) error {
+	available, err := s.sales.GetLocationStock(ctx, reservation.ItemID, reservation.WarehouseID, excludeID)
+	if err != nil {
+		return err
+	}
+	if available == nil {
+		return ErrWarehouseNotFound
+	}
+	if *available < reservation.Quantity {
+		return ErrInsufficientAvailable
+	}
+	return nil
+}
+
+func validateReservation(reservation *salesmodels.Reservation) error {
+	if reservation.Quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	if reservation.CustomerID == nil && (reservation.CustomerName == nil || *reservation.CustomerName == "") {
+		return ErrReservationCustomer
+	}
+
+	now := time.Now()
+	if reservation.ExpiresAt.IsZero() {
+		reservation.ExpiresAt = now.Add(DefaultReservationHold)
+	}
+	if !reservation.ExpiresAt.After(now) {
+		return ErrInvalidExpiry
+	}
+	return nil
+}
+
+// isOpen reports whether the reservation still holds stock. An active
+// reservation past its expiry no longer does, even before the expiry job
+// has closed it.
+func isOpen(reservation *salesmodels.Reservation) bool {
+	return reservation.Status == salesmodels.ReservationStatusActive && reservation.ExpiresAt.After(time.Now())
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
@@ -19,6 +21,25 @@ var (
 	ErrInvalidDate                = errors.New("sale date cannot be in the future")
 	ErrInsufficientStock          = errors.New("insufficient stock for sale")
 	ErrInvalidCustomerEmail       = errors.New("invalid customer email format")
+	ErrInvalidPaymentMethod       = errors.New("payment method must be cash, card, bank_transfer or account")
+	ErrCustomerRequired           = errors.New("customer is required for sales on account")
+	ErrCustomerNotFound           = errors.New("customer not found")
+	ErrCustomerInactive           = errors.New("customer account is inactive")
+	ErrCreditLimitExceeded        = errors.New("sale would exceed the customer's credit limit")
+	ErrSaleInvoiced               = errors.New("sale has been invoiced and cannot be changed")
+	ErrWarehouseNotFound          = errors.New("warehouse not found or inactive")
+	ErrSerialNumbersRequired      = errors.New("one serial number is required per unit sold")
+	ErrSerialNotInStock           = errors.New("serial number is not in stock for this item at the sale's warehouse")
+	ErrLotsRequired               = errors.New("lot quantities must add up to the quantity sold")
+	ErrLotNotAvailable            = errors.New("lot does not belong to this item or has too little left")
+	ErrItemNotTracked             = errors.New("item is not serial or lot tracked")
+	ErrTrackedSaleNotDeletable    = errors.New("a sale of serial numbered or lot tracked units cannot be deleted")
+	ErrCostedSaleLocked           = errors.New("item and quantity of a sale cannot be changed once its cost of goods is recorded")
+	ErrCoresReturned              = errors.New("cores have been returned against this sale")
+	ErrSaleHasClaims              = errors.New("warranty claims have been made against this sale")
+	ErrCustomerVehicleNotFound    = errors.New("customer vehicle not found")
+	ErrVehicleNotCustomers        = errors.New("vehicle is not registered to the sale's customer")
+	ErrInvalidVehicleMileage      = errors.New("vehicle mileage needs a customer vehicle and cannot be negative")
 )
 
 type SaleService interface {
@@ -33,12 +54,16 @@ type SaleService interface {
 }
 
 type saleService struct {
-	repo repositories.SaleRepository
+	repo         repositories.SaleRepository
+	receivables  repositories.ReceivablesRepository
+	reservations repositories.ReservationRepository
 }
 
-func NewSaleService(repo repositories.SaleRepository) SaleService {
+func NewSaleService(repo repositories.SaleRepository, receivables repositories.ReceivablesRepository, reservations repositories.ReservationRepository) SaleService {
 	return &saleService{
-		repo: repo,
+		repo:         repo,
+		receivables:  receivables,
+		reservations: reservations,
 	}
 }
 
@@ -59,6 +84,11 @@ func (s *saleService) GetByID(ctx context.Context, id int) (*salesmodels.Sale, e
 		return nil, ErrSaleNotFound
 	}
 
+	sale.SerialNumbers, sale.Lots, err = s.repo.GetTrackedUnits(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return sale, nil
 }
 
@@ -79,6 +109,38 @@ func (s *saleService) Create(ctx context.Context, sale *salesmodels.Sale) (int,
 		}
 	}
 
+	if err := s.checkVehicle(ctx, sale); err != nil {
+		return 0, err
+	}
+
+	// A sale fulfilling a reservation may use the stock it holds
+	if sale.ReservationID != nil {
+		if err := s.checkReservation(ctx, sale); err != nil {
+			return 0, err
+		}
+	}
+
+	// Check the stock available to sell at the warehouse the sale is made from
+	stock, err := s.repo.GetLocationStock(ctx, sale.ItemID, sale.WarehouseID, sale.ReservationID)
+	if err != nil {
+		return 0, err
+	}
+	if stock == nil {
+		return 0, ErrWarehouseNotFound
+	}
+	if *stock < sale.Quantity {
+		return 0, ErrInsufficientStock
+	}
+
+	// Serial and lot tracked items must say which units are sold
+	if err := s.checkTrackedUnits(ctx, sale); err != nil {
+		return 0, err
+	}
+
+	if err := s.checkSupersession(ctx, sale); err != nil {
+		return 0, err
+	}
+
 	// Set date to current time if not provided
 	if sale.Date.IsZero() {
 		sale.Date = time.Now()
@@ -89,7 +151,15 @@ func (s *saleService) Create(ctx context.Context, sale *salesmodels.Sale) (int,
 		sale.TotalPrice = float64(sale.Quantity) * sale.PricePerUnit
 	}
 
-	return s.repo.Create(ctx, sale)
+	if err := s.applyPaymentTerms(ctx, sale); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.Create(ctx, sale)
+	if errors.Is(err, repositories.ErrCreditLimitExceeded) {
+		return 0, ErrCreditLimitExceeded
+	}
+	return id, err
 }
 
 func (s *saleService) Update(ctx context.Context, sale *salesmodels.Sale) error {
@@ -110,6 +180,20 @@ func (s *saleService) Update(ctx context.Context, sale *salesmodels.Sale) error
 	if existing == nil {
 		return ErrSaleNotFound
 	}
+	if existing.InvoiceID != nil {
+		return ErrSaleInvoiced
+	}
+
+	if err := s.checkVehicle(ctx, sale); err != nil {
+		return err
+	}
+
+	// The cost of goods, and any serial numbers, lots and core deposit, were
+	// drawn for the original item and quantity when the sale was made
+	if (sale.ItemID != existing.ItemID || sale.Quantity != existing.Quantity) &&
+		(existing.CogsFIFO != nil || existing.CogsAverage != nil) {
+		return ErrCostedSaleLocked
+	}
 
 	// Check if transaction number is unique if changed
 	if sale.TransactionNumber != existing.TransactionNumber {
@@ -125,7 +209,22 @@ func (s *saleService) Update(ctx context.Context, sale *salesmodels.Sale) error
 	// Recalculate total price
 	sale.TotalPrice = float64(sale.Quantity) * sale.PricePerUnit
 
-	return s.repo.Update(ctx, sale)
+	if sale.Date.IsZero() {
+		sale.Date = existing.Date
+	}
+	if sale.PaymentMethod == "" {
+		sale.PaymentMethod = existing.PaymentMethod
+	}
+
+	if err := s.applyPaymentTerms(ctx, sale); err != nil {
+		return err
+	}
+
+	err = s.repo.Update(ctx, sale)
+	if errors.Is(err, repositories.ErrCreditLimitExceeded) {
+		return ErrCreditLimitExceeded
+	}
+	return err
 }
 
 func (s *saleService) Delete(ctx context.Context, id int) error {
@@ -141,6 +240,35 @@ func (s *saleService) Delete(ctx context.Context, id int) error {
 	if existing == nil {
 		return ErrSaleNotFound
 	}
+	if existing.InvoiceID != nil {
+		return ErrSaleInvoiced
+	}
+
+	returned, err := s.repo.GetCoresReturned(ctx, id)
+	if err != nil {
+		return err
+	}
+	if returned > 0 {
+		return ErrCoresReturned
+	}
+
+	claims, err := s.repo.GetWarrantyClaimCount(ctx, id)
+	if err != nil {
+		return err
+	}
+	if claims > 0 {
+		return ErrSaleHasClaims
+	}
+
+	// Deleting a sale does not put its stock back, so the units it sold
+	// stay sold
+	serials, lots, err := s.repo.GetTrackedUnits(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(serials) > 0 || len(lots) > 0 {
+		return ErrTrackedSaleNotDeletable
+	}
 
 	return s.repo.Delete(ctx, id)
 }
@@ -183,12 +311,221 @@ func (s *saleService) validateSale(sale *salesmodels.Sale) error {
 	if !sale.Date.IsZero() && sale.Date.After(time.Now()) {
 		return ErrInvalidDate
 	}
+	if sale.PaymentMethod != "" && !isValidPaymentMethod(sale.PaymentMethod) {
+		return ErrInvalidPaymentMethod
+	}
 
 	// Additional validations could be added here:
 	// - Check if item exists
-	// - Check if item has sufficient stock
 	// - Validate email format if provided
 	// - etc.
 
 	return nil
 }
+
+// checkVehicle makes sure a sale's vehicle is registered to its customer,
+// taking the vehicle's customer when the sale has none
+func (s *saleService) checkVehicle(ctx context.Context, sale *salesmodels.Sale) error {
+	if sale.VehicleMileage != nil && (sale.CustomerVehicleID == nil || *sale.VehicleMileage < 0) {
+		return ErrInvalidVehicleMileage
+	}
+	if sale.CustomerVehicleID == nil {
+		return nil
+	}
+
+	customerID, err := s.repo.GetVehicleCustomerID(ctx, *sale.CustomerVehicleID)
+	if err != nil {
+		return err
+	}
+	if customerID == nil {
+		return ErrCustomerVehicleNotFound
+	}
+
+	if sale.CustomerID == nil {
+		sale.CustomerID = customerID
+	}
+	if *sale.CustomerID != *customerID {
+		return ErrVehicleNotCustomers
+	}
+	return nil
+}
+
+// checkSupersession warns when a superseded item is sold while its
+// replacement is in stock at the same warehouse. The sale still goes ahead,
+// since old stock is usually sold off first.
+func (s *saleService) checkSupersession(ctx context.Context, sale *salesmodels.Sale) error {
+	replacement, err := s.repo.GetItemReplacement(ctx, sale.ItemID, sale.WarehouseID)
+	if err != nil {
+		return err
+	}
+	if replacement == nil || replacement.AvailableStock <= 0 {
+		return nil
+	}
+
+	warning := fmt.Sprintf("%s has been superseded by %s, which has %d available",
+		replacement.PartNumber, replacement.ReplacementPartNumber, replacement.AvailableStock)
+	if !replacement.IsDirectInterchange {
+		warning += "; it is not a direct interchange"
+	}
+	sale.Warnings = append(sale.Warnings, warning)
+	return nil
+}
+
+// checkReservation makes sure the sale matches the reservation it fulfils,
+// taking the reservation's warehouse when the sale has none. A sale takes
+// the whole reservation, so it has to be for the reserved quantity.
+func (s *saleService) checkReservation(ctx context.Context, sale *salesmodels.Sale) error {
+	reservation, err := s.reservations.GetByID(ctx, *sale.ReservationID)
+	if err != nil {
+		return err
+	}
+	if reservation == nil {
+		return ErrReservationNotFound
+	}
+	if !isOpen(reservation) {
+		return ErrReservationNotActive
+	}
+	if reservation.SpecialOrderStatus != nil && *reservation.SpecialOrderStatus != "ready" {
+		return ErrSpecialOrderNotReady
+	}
+
+	if sale.WarehouseID == nil {
+		sale.WarehouseID = reservation.WarehouseID
+	}
+	if reservation.ItemID != sale.ItemID || sale.Quantity != reservation.Quantity ||
+		*sale.WarehouseID != *reservation.WarehouseID {
+		return ErrReservationMismatch
+	}
+	return nil
+}
+
+// checkTrackedUnits makes sure a sale of a serial tracked item lists one
+// in-stock serial number per unit, and a sale of a lot tracked item draws
+// its quantity from lots with enough left
+func (s *saleService) checkTrackedUnits(ctx context.Context, sale *salesmodels.Sale) error {
+	mode, err := s.repo.GetItemTrackingMode(ctx, sale.ItemID)
+	if err != nil {
+		return err
+	}
+	if mode == nil {
+		return ErrInvalidItemID
+	}
+
+	switch *mode {
+	case "serial":
+		if len(sale.Lots) > 0 || len(sale.SerialNumbers) != sale.Quantity {
+			return ErrSerialNumbersRequired
+		}
+
+		seen := make(map[string]bool)
+		for i, serial := range sale.SerialNumbers {
+			serial = strings.TrimSpace(serial)
+			if serial == "" || seen[serial] {
+				return ErrSerialNumbersRequired
+			}
+			seen[serial] = true
+			sale.SerialNumbers[i] = serial
+		}
+
+		inStock, err := s.repo.GetInStockSerials(ctx, sale.ItemID, sale.WarehouseID, sale.SerialNumbers)
+		if err != nil {
+			return err
+		}
+		if len(inStock) != len(sale.SerialNumbers) {
+			return ErrSerialNotInStock
+		}
+
+	case "lot":
+		if len(sale.SerialNumbers) > 0 {
+			return ErrLotsRequired
+		}
+
+		total := 0
+		requested := make(map[int]int)
+		var lotIDs []int
+		for _, lot := range sale.Lots {
+			if lot.Quantity <= 0 {
+				return ErrLotsRequired
+			}
+			if _, ok := requested[lot.LotID]; !ok {
+				lotIDs = append(lotIDs, lot.LotID)
+			}
+			requested[lot.LotID] += lot.Quantity
+			total += lot.Quantity
+		}
+		if total != sale.Quantity {
+			return ErrLotsRequired
+		}
+
+		remaining, err := s.repo.GetLotsRemaining(ctx, sale.ItemID, lotIDs)
+		if err != nil {
+			return err
+		}
+
+		// Merge repeated lots so each is drawn from once
+		sale.Lots = sale.Lots[:0]
+		for _, lotID := range lotIDs {
+			left, ok := remaining[lotID]
+			if !ok || left < requested[lotID] {
+				return ErrLotNotAvailable
+			}
+			sale.Lots = append(sale.Lots, &salesmodels.SaleLot{LotID: lotID, Quantity: requested[lotID]})
+		}
+
+	default:
+		if len(sale.SerialNumbers) > 0 || len(sale.Lots) > 0 {
+			return ErrItemNotTracked
+		}
+	}
+
+	return nil
+}
+
+// applyPaymentTerms sets the payment status and due date of the sale. Sales
+// on account need an active customer; the repository checks the credit limit
+// as the sale is written.
+func (s *saleService) applyPaymentTerms(ctx context.Context, sale *salesmodels.Sale) error {
+	if sale.PaymentMethod == "" {
+		sale.PaymentMethod = salesmodels.PaymentMethodCash
+	}
+
+	if sale.PaymentMethod != salesmodels.PaymentMethodAccount {
+		sale.PaymentStatus = salesmodels.PaymentStatusPaid
+		sale.DueDate = nil
+		return nil
+	}
+
+	if sale.CustomerID == nil || *sale.CustomerID <= 0 {
+		return ErrCustomerRequired
+	}
+
+	account, err := s.receivables.GetCustomerAccount(ctx, *sale.CustomerID)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return ErrCustomerNotFound
+	}
+	if !account.IsActive {
+		return ErrCustomerInactive
+	}
+
+	dueDate := truncateToDay(sale.Date).AddDate(0, 0, account.PaymentTermsDays)
+
+	sale.PaymentStatus = salesmodels.PaymentStatusUnpaid
+	sale.DueDate = &dueDate
+	return nil
+}
+
+func isValidPaymentMethod(method string) bool {
+	switch method {
+	case salesmodels.PaymentMethodCash, salesmodels.PaymentMethodCard,
+		salesmodels.PaymentMethodBankTransfer, salesmodels.PaymentMethodAccount:
+		return true
+	}
+	return false
+}
+
+func sameCustomer(a, b *int) bool {
+	return a != nil && b != nil && *a == *b
+}
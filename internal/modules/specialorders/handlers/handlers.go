@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	specialordermodels "github.com/hsrvms/autoparts/internal/modules/specialorders/models"
+	"github.com/hsrvms/autoparts/internal/modules/specialorders/services"
+	"github.com/labstack/echo/v4"
+)
+
+type SpecialOrderHandler struct {
+	service services.SpecialOrderService
+}
+
+func NewSpecialOrderHandler(service services.SpecialOrderService) *SpecialOrderHandler {
+	return &SpecialOrderHandler{
+		service: service,
+	}
+}
+
+// GetSpecialOrders handles retrieval of special orders with optional filtering.
+// status=ready lists the orders waiting for the customer to be told.
+func (h *SpecialOrderHandler) GetSpecialOrders(c echo.Context) error {
+	filter := &specialordermodels.SpecialOrderFilter{}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if customerID, err := strconv.Atoi(c.QueryParam("customer_id")); err == nil {
+		filter.CustomerID = &customerID
+	}
+
+	if supplierID, err := strconv.Atoi(c.QueryParam("supplier_id")); err == nil {
+		filter.SupplierID = &supplierID
+	}
+
+	if itemID, err := strconv.Atoi(c.QueryParam("item_id")); err == nil {
+		filter.ItemID = &itemID
+	}
+
+	if search := c.QueryParam("search"); search != "" {
+		filter.SearchTerm = &search
+	}
+
+	ctx := c.Request().Context()
+	orders, err := h.service.GetAll(ctx, filter)
+	if err != nil {
+		return specialOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, orders)
+}
+
+// GetSpecialOrderByID handles retrieval of a single special order
+func (h *SpecialOrderHandler) GetSpecialOrderByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid special order ID")
+	}
+
+	ctx := c.Request().Context()
+	order, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return specialOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// CreateSpecialOrder handles recording a customer's request for a part
+func (h *SpecialOrderHandler) CreateSpecialOrder(c echo.Context) error {
+	order := new(specialordermodels.SpecialOrder)
+	if err := c.Bind(order); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.Create(ctx, order)
+	if err != nil {
+		return specialOrderError(err)
+	}
+
+	created, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return specialOrderError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateSpecialOrder handles changing an order that has not been placed yet
+func (h *SpecialOrderHandler) UpdateSpecialOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid special order ID")
+	}
+
+	order := new(specialordermodels.SpecialOrder)
+	if err := c.Bind(order); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	order.SpecialOrderID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.Update(ctx, order); err != nil {
+		return specialOrderError(err)
+	}
+
+	updated, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		return specialOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// OrderSpecialOrder handles placing a special order on a purchase order
+func (h *SpecialOrderHandler) OrderSpecialOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid special order ID")
+	}
+
+	req := new(specialordermodels.OrderRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	order, err := h.service.Order(ctx, id, req)
+	if err != nil {
+		return specialOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// NotifySpecialOrder handles recording that the customer was told the part is in
+func (h *SpecialOrderHandler) NotifySpecialOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid special order ID")
+	}
+
+	ctx := c.Request().Context()
+	order, err := h.service.MarkNotified(ctx, id)
+	if err != nil {
+		return specialOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// CancelSpecialOrder handles cancelling an order that has not been collected
+func (h *SpecialOrderHandler) CancelSpecialOrder(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid special order ID")
+	}
+
+	ctx := c.Request().Context()
+	order, err := h.service.Cancel(ctx, id)
+	if err != nil {
+		return specialOrderError(err)
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+func specialOrderError(err error) error {
+	switch err {
+	case services.ErrInvalidSpecialOrderID, services.ErrInvalidStatus, services.ErrCustomerRequired,
+		services.ErrPartRequired, services.ErrInvalidQuantity, services.ErrInvalidPrice,
+		services.ErrInvalidDeposit, services.ErrInvalidHoldDays, services.ErrSupplierRequired,
+		services.ErrUnitCostRequired:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrSpecialOrderNotFound, services.ErrCustomerNotFound, services.ErrItemNotFound,
+		services.ErrSupplierNotFound, services.ErrPurchaseOrderNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrSpecialOrderNotRequested, services.ErrSpecialOrderNotReady,
+		services.ErrSpecialOrderNotCancelable, services.ErrPurchaseOrderNotDraft,
+		services.ErrUnitsHeld:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrCustomerInactive, services.ErrPurchaseOrderSupplier, services.ErrItemIsKit:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
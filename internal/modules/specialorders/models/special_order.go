@@ -0,0 +1,104 @@
+package specialordermodels
+
+import "time"
+
+// Special order statuses
+const (
+	StatusRequested = "requested"
+	StatusOrdered   = "ordered"
+	StatusReady     = "ready"
+	StatusCollected = "collected"
+	StatusCancelled = "cancelled"
+)
+
+// DefaultHoldDays is how long the stock is held for the customer once it
+// arrives, when the order does not say
+const DefaultHoldDays = 14
+
+// SpecialOrder is a part ordered in for a specific customer. Without an item
+// the part is described by its part number and description until it is
+// ordered. An order for an item that is never placed on a purchase order is
+// a backorder, filled by the next delivery of that item.
+type SpecialOrder struct {
+	SpecialOrderID   int        `json:"special_order_id" db:"special_order_id"`
+	CustomerID       *int       `json:"customer_id,omitempty" db:"customer_id"`
+	CustomerName     *string    `json:"customer_name,omitempty" db:"customer_name"`
+	CustomerPhone    *string    `json:"customer_phone,omitempty" db:"customer_phone"`
+	CustomerEmail    *string    `json:"customer_email,omitempty" db:"customer_email"`
+	ItemID           *int       `json:"item_id,omitempty" db:"item_id"`
+	PartNumber       *string    `json:"part_number,omitempty" db:"part_number"`
+	Description      *string    `json:"description,omitempty" db:"description"`
+	Quantity         int        `json:"quantity" db:"quantity"`
+	QuantityReceived int        `json:"quantity_received" db:"quantity_received"`
+	SupplierID       *int       `json:"supplier_id,omitempty" db:"supplier_id"`
+	UnitCost         *float64   `json:"unit_cost,omitempty" db:"unit_cost"`
+	QuotedPrice      *float64   `json:"quoted_price,omitempty" db:"quoted_price"`
+	DepositAmount    float64    `json:"deposit_amount" db:"deposit_amount"`
+	Status           string     `json:"status" db:"status"`
+	PurchaseOrderID  *int       `json:"purchase_order_id,omitempty" db:"purchase_order_id"`
+	ReservationID    *int       `json:"reservation_id,omitempty" db:"reservation_id"`
+	SaleID           *int       `json:"sale_id,omitempty" db:"sale_id"`
+	HoldDays         int        `json:"hold_days" db:"hold_days"`
+	OrderedAt        *time.Time `json:"ordered_at,omitempty" db:"ordered_at"`
+	ReadyAt          *time.Time `json:"ready_at,omitempty" db:"ready_at"`
+	NotifiedAt       *time.Time `json:"notified_at,omitempty" db:"notified_at"`
+	ClosedAt         *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	Notes            *string    `json:"notes,omitempty" db:"notes"`
+	CreatedBy        *string    `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	ItemPartNumber      *string    `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription     *string    `json:"item_description,omitempty" db:"item_description"`
+	SupplierName        *string    `json:"supplier_name,omitempty" db:"supplier_name"`
+	PONumber            *string    `json:"po_number,omitempty" db:"po_number"`
+	PurchaseOrderStatus *string    `json:"purchase_order_status,omitempty" db:"purchase_order_status"`
+	HeldUntil           *time.Time `json:"held_until,omitempty" db:"held_until"`
+	BalanceDue          *float64   `json:"balance_due,omitempty" db:"-"` // Quoted total less the deposit
+}
+
+type SpecialOrderFilter struct {
+	Status     *string `query:"status"`
+	CustomerID *int    `query:"customer_id"`
+	SupplierID *int    `query:"supplier_id"`
+	ItemID     *int    `query:"item_id"`
+	SearchTerm *string `query:"search"` // Customer name or part number
+}
+
+// OrderRequest places a requested special order with a supplier. Without a
+// purchase order a new draft is opened; an existing one must be a draft for
+// the same supplier.
+type OrderRequest struct {
+	SupplierID      *int     `json:"supplier_id,omitempty"`
+	UnitCost        *float64 `json:"unit_cost,omitempty"`
+	PurchaseOrderID *int     `json:"purchase_order_id,omitempty"`
+	CreatedBy       *string  `json:"created_by,omitempty"`
+}
+
+// CustomerContact is the part of a customer record copied onto an order
+type CustomerContact struct {
+	CustomerID int
+	Name       string
+	Phone      *string
+	Email      *string
+	IsActive   bool
+}
+
+// ItemSummary is the part of an item used to place an order
+type ItemSummary struct {
+	ItemID      int
+	PartNumber  string
+	Description string
+	SupplierID  *int
+	BuyPrice    float64
+	IsKit       bool
+}
+
+// PurchaseOrderSummary is the part of a purchase order checked before
+// adding a special order to it
+type PurchaseOrderSummary struct {
+	PurchaseOrderID int
+	SupplierID      int
+	Status          string
+}
@@ -0,0 +1,454 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	specialordermodels "github.com/hsrvms/autoparts/internal/modules/specialorders/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresSpecialOrderRepository struct {
+	db *db.Database
+}
+
+func NewPostgresSpecialOrderRepository(database *db.Database) SpecialOrderRepository {
+	return &PostgresSpecialOrderRepository{
+		db: database,
+	}
+}
+
+const specialOrderQuery = `
+	SELECT
+		so.special_order_id, so.customer_id, so.customer_name, so.customer_phone,
+		so.customer_email, so.item_id, so.part_number, so.description, so.quantity,
+		so.quantity_received, so.supplier_id, so.unit_cost, so.quoted_price,
+		so.deposit_amount, so.status, so.purchase_order_id, so.reservation_id,
+		so.sale_id, so.hold_days, so.ordered_at, so.ready_at, so.notified_at,
+		so.closed_at, so.notes, so.created_by, so.created_at, so.updated_at,
+		i.part_number as item_part_number,
+		i.description as item_description,
+		s.name as supplier_name,
+		po.po_number,
+		po.status as purchase_order_status,
+		CASE WHEN r.status = 'active' THEN r.expires_at END as held_until
+	FROM special_orders so
+	LEFT JOIN items i ON so.item_id = i.item_id
+	LEFT JOIN suppliers s ON so.supplier_id = s.supplier_id
+	LEFT JOIN purchase_orders po ON so.purchase_order_id = po.purchase_order_id
+	LEFT JOIN stock_reservations r ON so.reservation_id = r.reservation_id
+`
+
+func scanSpecialOrder(row pgx.Row) (*specialordermodels.SpecialOrder, error) {
+	order := &specialordermodels.SpecialOrder{}
+	err := row.Scan(
+		&order.SpecialOrderID,
+		&order.CustomerID,
+		&order.CustomerName,
+		&order.CustomerPhone,
+		&order.CustomerEmail,
+		&order.ItemID,
+		&order.PartNumber,
+		&order.Description,
+		&order.Quantity,
+		&order.QuantityReceived,
+		&order.SupplierID,
+		&order.UnitCost,
+		&order.QuotedPrice,
+		&order.DepositAmount,
+		&order.Status,
+		&order.PurchaseOrderID,
+		&order.ReservationID,
+		&order.SaleID,
+		&order.HoldDays,
+		&order.OrderedAt,
+		&order.ReadyAt,
+		&order.NotifiedAt,
+		&order.ClosedAt,
+		&order.Notes,
+		&order.CreatedBy,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&order.ItemPartNumber,
+		&order.ItemDescription,
+		&order.SupplierName,
+		&order.PONumber,
+		&order.PurchaseOrderStatus,
+		&order.HeldUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (r *PostgresSpecialOrderRepository) GetAll(ctx context.Context, filter *specialordermodels.SpecialOrderFilter) ([]*specialordermodels.SpecialOrder, error) {
+	query := specialOrderQuery + " WHERE 1=1"
+
+	var params []interface{}
+	paramCount := 1
+
+	if filter.Status != nil {
+		query += fmt.Sprintf(" AND so.status = $%d", paramCount)
+		params = append(params, *filter.Status)
+		paramCount++
+	}
+
+	if filter.CustomerID != nil {
+		query += fmt.Sprintf(" AND so.customer_id = $%d", paramCount)
+		params = append(params, *filter.CustomerID)
+		paramCount++
+	}
+
+	if filter.SupplierID != nil {
+		query += fmt.Sprintf(" AND so.supplier_id = $%d", paramCount)
+		params = append(params, *filter.SupplierID)
+		paramCount++
+	}
+
+	if filter.ItemID != nil {
+		query += fmt.Sprintf(" AND so.item_id = $%d", paramCount)
+		params = append(params, *filter.ItemID)
+		paramCount++
+	}
+
+	if filter.SearchTerm != nil {
+		query += fmt.Sprintf(` AND (
+			so.customer_name ILIKE $%d OR so.part_number ILIKE $%d OR i.part_number ILIKE $%d
+		)`, paramCount, paramCount, paramCount)
+		params = append(params, "%"+*filter.SearchTerm+"%")
+		paramCount++
+	}
+
+	query += " ORDER BY so.created_at DESC, so.special_order_id DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*specialordermodels.SpecialOrder
+	for rows.Next() {
+		order, err := scanSpecialOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+func (r *PostgresSpecialOrderRepository) GetByID(ctx context.Context, id int) (*specialordermodels.SpecialOrder, error) {
+	order, err := scanSpecialOrder(r.db.Pool.QueryRow(ctx, specialOrderQuery+" WHERE so.special_order_id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *PostgresSpecialOrderRepository) Create(ctx context.Context, order *specialordermodels.SpecialOrder) (int, error) {
+	query := `
+		INSERT INTO special_orders (
+			customer_id, customer_name, customer_phone, customer_email, item_id,
+			part_number, description, quantity, supplier_id, unit_cost,
+			quoted_price, deposit_amount, hold_days, notes, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING special_order_id
+	`
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		order.CustomerID,
+		order.CustomerName,
+		order.CustomerPhone,
+		order.CustomerEmail,
+		order.ItemID,
+		order.PartNumber,
+		order.Description,
+		order.Quantity,
+		order.SupplierID,
+		order.UnitCost,
+		order.QuotedPrice,
+		order.DepositAmount,
+		order.HoldDays,
+		order.Notes,
+		order.CreatedBy,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update saves the details of an order that has not been placed yet
+func (r *PostgresSpecialOrderRepository) Update(ctx context.Context, order *specialordermodels.SpecialOrder) error {
+	query := `
+		UPDATE special_orders SET
+			customer_name = $2,
+			customer_phone = $3,
+			customer_email = $4,
+			item_id = $5,
+			part_number = $6,
+			description = $7,
+			quantity = $8,
+			supplier_id = $9,
+			unit_cost = $10,
+			quoted_price = $11,
+			deposit_amount = $12,
+			hold_days = $13,
+			notes = $14
+		WHERE special_order_id = $1 AND status = 'requested'
+	`
+
+	result, err := r.db.Pool.Exec(
+		ctx, query,
+		order.SpecialOrderID,
+		order.CustomerName,
+		order.CustomerPhone,
+		order.CustomerEmail,
+		order.ItemID,
+		order.PartNumber,
+		order.Description,
+		order.Quantity,
+		order.SupplierID,
+		order.UnitCost,
+		order.QuotedPrice,
+		order.DepositAmount,
+		order.HoldDays,
+		order.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("special order has already been placed")
+	}
+
+	return nil
+}
+
+// Order places the special order on a purchase order in one transaction. A
+// part not yet in the catalogue is added as an item with no minimum stock so
+// replenishment leaves it alone, and a new draft purchase order is opened
+// when none is given. The order's SupplierID and UnitCost must be set.
+func (r *PostgresSpecialOrderRepository) Order(ctx context.Context, order *specialordermodels.SpecialOrder, purchaseOrderID *int, createdBy *string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	reference := fmt.Sprintf("Special order %d", order.SpecialOrderID)
+
+	if order.ItemID == nil {
+		sellPrice := *order.UnitCost
+		if order.QuotedPrice != nil {
+			sellPrice = *order.QuotedPrice
+		}
+
+		var itemID int
+		err := tx.QueryRow(ctx, `
+			INSERT INTO items (
+				part_number, description, buy_price, sell_price, minimum_stock,
+				supplier_id, notes
+			) VALUES ($1, $2, $3, $4, 0, $5, $6)
+			RETURNING item_id
+		`,
+			order.PartNumber,
+			order.Description,
+			order.UnitCost,
+			sellPrice,
+			order.SupplierID,
+			"Created for "+reference,
+		).Scan(&itemID)
+		if err != nil {
+			return err
+		}
+		order.ItemID = &itemID
+	}
+
+	if purchaseOrderID == nil {
+		var id int
+		err := tx.QueryRow(ctx, `SELECT nextval('purchase_order_id_seq')`).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO purchase_orders (
+				purchase_order_id, po_number, supplier_id, status, notes, created_by
+			) VALUES ($1, $2, $3, 'draft', $4, $5)
+		`, id, fmt.Sprintf("PO-%06d", id), order.SupplierID, reference, createdBy)
+		if err != nil {
+			return err
+		}
+		purchaseOrderID = &id
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO purchase_order_lines (purchase_order_id, item_id, quantity, unit_cost)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (purchase_order_id, item_id)
+		DO UPDATE SET quantity = purchase_order_lines.quantity + EXCLUDED.quantity
+	`, *purchaseOrderID, *order.ItemID, order.Quantity-order.QuantityReceived, order.UnitCost)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE special_orders SET
+			status = 'ordered',
+			item_id = $2,
+			supplier_id = $3,
+			unit_cost = $4,
+			purchase_order_id = $5,
+			ordered_at = $6
+		WHERE special_order_id = $1 AND status = 'requested'
+	`, order.SpecialOrderID, order.ItemID, order.SupplierID, order.UnitCost, *purchaseOrderID, time.Now())
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("special order has already been placed")
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MarkNotified records that the customer has been told the order is ready
+func (r *PostgresSpecialOrderRepository) MarkNotified(ctx context.Context, id int) error {
+	query := `
+		UPDATE special_orders SET notified_at = CURRENT_TIMESTAMP
+		WHERE special_order_id = $1 AND status = 'ready'
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("special order is not ready")
+	}
+
+	return nil
+}
+
+// Cancel closes the order and releases any stock held for it. Lines already
+// on a purchase order are left for the buyer to adjust.
+func (r *PostgresSpecialOrderRepository) Cancel(ctx context.Context, order *specialordermodels.SpecialOrder) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE special_orders SET status = 'cancelled', closed_at = CURRENT_TIMESTAMP
+		WHERE special_order_id = $1 AND status IN ('requested', 'ordered', 'ready')
+	`, order.SpecialOrderID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("special order is already closed")
+	}
+
+	if order.ReservationID != nil {
+		_, err := tx.Exec(ctx, `
+			UPDATE stock_reservations SET status = 'released', closed_at = CURRENT_TIMESTAMP
+			WHERE reservation_id = $1 AND status = 'active'
+		`, *order.ReservationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresSpecialOrderRepository) GetCustomer(ctx context.Context, id int) (*specialordermodels.CustomerContact, error) {
+	customer := &specialordermodels.CustomerContact{}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT customer_id, name, phone, email, COALESCE(is_active, TRUE)
+		FROM customers
+		WHERE customer_id = $1
+	`, id).Scan(&customer.CustomerID, &customer.Name, &customer.Phone, &customer.Email, &customer.IsActive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+const itemSummaryQuery = `
+	SELECT item_id, part_number, description, supplier_id, buy_price, is_kit
+	FROM items
+`
+
+func (r *PostgresSpecialOrderRepository) queryItem(ctx context.Context, query string, arg interface{}) (*specialordermodels.ItemSummary, error) {
+	item := &specialordermodels.ItemSummary{}
+	err := r.db.Pool.QueryRow(ctx, query, arg).Scan(
+		&item.ItemID,
+		&item.PartNumber,
+		&item.Description,
+		&item.SupplierID,
+		&item.BuyPrice,
+		&item.IsKit,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (r *PostgresSpecialOrderRepository) GetItem(ctx context.Context, id int) (*specialordermodels.ItemSummary, error) {
+	return r.queryItem(ctx, itemSummaryQuery+" WHERE item_id = $1", id)
+}
+
+func (r *PostgresSpecialOrderRepository) GetItemByPartNumber(ctx context.Context, partNumber string) (*specialordermodels.ItemSummary, error) {
+	return r.queryItem(ctx, itemSummaryQuery+" WHERE part_number = $1", partNumber)
+}
+
+func (r *PostgresSpecialOrderRepository) SupplierExists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM suppliers WHERE supplier_id = $1)`, id).Scan(&exists)
+	return exists, err
+}
+
+func (r *PostgresSpecialOrderRepository) GetPurchaseOrder(ctx context.Context, id int) (*specialordermodels.PurchaseOrderSummary, error) {
+	order := &specialordermodels.PurchaseOrderSummary{}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT purchase_order_id, supplier_id, status
+		FROM purchase_orders
+		WHERE purchase_order_id = $1
+	`, id).Scan(&order.PurchaseOrderID, &order.SupplierID, &order.Status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
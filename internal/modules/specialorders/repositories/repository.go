@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+
+	specialordermodels "github.com/hsrvms/autoparts/internal/modules/specialorders/models"
+)
+
+type SpecialOrderRepository interface {
+	GetAll(ctx context.Context, filter *specialordermodels.SpecialOrderFilter) ([]*specialordermodels.SpecialOrder, error)
+	GetByID(ctx context.Context, id int) (*specialordermodels.SpecialOrder, error)
+	Create(ctx context.Context, order *specialordermodels.SpecialOrder) (int, error)
+	Update(ctx context.Context, order *specialordermodels.SpecialOrder) error
+	Order(ctx context.Context, order *specialordermodels.SpecialOrder, purchaseOrderID *int, createdBy *string) error
+	MarkNotified(ctx context.Context, id int) error
+	Cancel(ctx context.Context, order *specialordermodels.SpecialOrder) error
+
+	// Lookups
+	GetCustomer(ctx context.Context, id int) (*specialordermodels.CustomerContact, error)
+	GetItem(ctx context.Context, id int) (*specialordermodels.ItemSummary, error)
+	GetItemByPartNumber(ctx context.Context, partNumber string) (*specialordermodels.ItemSummary, error)
+	SupplierExists(ctx context.Context, id int) (bool, error)
+	GetPurchaseOrder(ctx context.Context, id int) (*specialordermodels.PurchaseOrderSummary, error)
+}
@@ -0,0 +1,30 @@
+package specialorders
+
+import (
+	"github.com/hsrvms/autoparts/internal/modules/specialorders/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/specialorders/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/specialorders/services"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repository
+	repo := repositories.NewPostgresSpecialOrderRepository(database)
+
+	// Initialize service
+	service := services.NewSpecialOrderService(repo)
+
+	// Initialize handler
+	handler := handlers.NewSpecialOrderHandler(service)
+
+	// Register routes
+	orders := api.Group("/special-orders")
+	orders.GET("", handler.GetSpecialOrders)
+	orders.POST("", handler.CreateSpecialOrder)
+	orders.GET("/:id", handler.GetSpecialOrderByID)
+	orders.PUT("/:id", handler.UpdateSpecialOrder)
+	orders.POST("/:id/order", handler.OrderSpecialOrder)
+	orders.POST("/:id/notify", handler.NotifySpecialOrder)
+	orders.POST("/:id/cancel", handler.CancelSpecialOrder)
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	specialordermodels "github.com/hsrvms/autoparts/internal/modules/specialorders/models"
+	"github.com/hsrvms/autoparts/internal/modules/specialorders/repositories"
+)
+
+var (
+	ErrSpecialOrderNotFound      = errors.New("special order not found")
+	ErrInvalidSpecialOrderID     = errors.New("invalid special order ID")
+	ErrInvalidStatus             = errors.New("status must be requested, ordered, ready, collected or cancelled")
+	ErrCustomerRequired          = errors.New("customer ID or customer name is required")
+	ErrCustomerNotFound          = errors.New("customer not found")
+	ErrCustomerInactive          = errors.New("customer account is inactive")
+	ErrPartRequired              = errors.New("item ID or part number and description are required")
+	ErrItemNotFound              = errors.New("item not found")
+	ErrItemIsKit                 = errors.New("kits are not ordered; order their components instead")
+	ErrInvalidQuantity           = errors.New("quantity must be greater than zero")
+	ErrInvalidPrice              = errors.New("prices cannot be negative")
+	ErrInvalidDeposit            = errors.New("deposit cannot be negative or exceed the quoted total")
+	ErrInvalidHoldDays           = errors.New("hold days must be greater than zero")
+	ErrSupplierRequired          = errors.New("supplier is required to place the order")
+	ErrSupplierNotFound          = errors.New("supplier not found")
+	ErrUnitCostRequired          = errors.New("unit cost is required to place the order")
+	ErrPurchaseOrderNotFound     = errors.New("purchase order not found")
+	ErrPurchaseOrderNotDraft     = errors.New("special orders can only be added to draft purchase orders")
+	ErrPurchaseOrderSupplier     = errors.New("purchase order is for a different supplier")
+	ErrSpecialOrderNotRequested  = errors.New("special order has already been placed")
+	ErrSpecialOrderNotReady      = errors.New("special order is not ready for collection")
+	ErrSpecialOrderNotCancelable = errors.New("special order is already collected or cancelled")
+	ErrUnitsHeld                 = errors.New("units already received are held for this order; its part cannot change or its quantity drop below them")
+)
+
+type SpecialOrderService interface {
+	GetAll(ctx context.Context, filter *specialordermodels.SpecialOrderFilter) ([]*specialordermodels.SpecialOrder, error)
+	GetByID(ctx context.Context, id int) (*specialordermodels.SpecialOrder, error)
+	Create(ctx context.Context, order *specialordermodels.SpecialOrder) (int, error)
+	Update(ctx context.Context, order *specialordermodels.SpecialOrder) error
+	Order(ctx context.Context, id int, req *specialordermodels.OrderRequest) (*specialordermodels.SpecialOrder, error)
+	MarkNotified(ctx context.Context, id int) (*specialordermodels.SpecialOrder, error)
+	Cancel(ctx context.Context, id int) (*specialordermodels.SpecialOrder, error)
+}
+
+type specialOrderService struct {
+	repo repositories.SpecialOrderRepository
+}
+
+func NewSpecialOrderService(repo repositories.SpecialOrderRepository) SpecialOrderService {
+	return &specialOrderService{
+		repo: repo,
+	}
+}
+
+func (s *specialOrderService) GetAll(ctx context.Context, filter *specialordermodels.SpecialOrderFilter) ([]*specialordermodels.SpecialOrder, error) {
+	if filter.Status != nil && !isValidStatus(*filter.Status) {
+		return nil, ErrInvalidStatus
+	}
+
+	orders, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []*specialordermodels.SpecialOrder{}
+	}
+
+	for _, order := range orders {
+		setBalanceDue(order)
+	}
+	return orders, nil
+}
+
+func (s *specialOrderService) GetByID(ctx context.Context, id int) (*specialordermodels.SpecialOrder, error) {
+	if id <= 0 {
+		return nil, ErrInvalidSpecialOrderID
+	}
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrSpecialOrderNotFound
+	}
+
+	setBalanceDue(order)
+	return order, nil
+}
+
+// Create records a customer's request for a part. A part number already in
+// the catalogue is linked to that item so the order is filled by its next
+// delivery.
+func (s *specialOrderService) Create(ctx context.Context, order *specialordermodels.SpecialOrder) (int, error) {
+	if order.HoldDays == 0 {
+		order.HoldDays = specialordermodels.DefaultHoldDays
+	}
+	if err := s.validateSpecialOrder(ctx, order); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(ctx, order)
+}
+
+// Update changes the details of an order that has not been placed yet. The
+// customer account stays as it was.
+func (s *specialOrderService) Update(ctx context.Context, order *specialordermodels.SpecialOrder) error {
+	current, err := s.GetByID(ctx, order.SpecialOrderID)
+	if err != nil {
+		return err
+	}
+	if current.Status != specialordermodels.StatusRequested {
+		return ErrSpecialOrderNotRequested
+	}
+
+	order.CustomerID = current.CustomerID
+	if order.CustomerID == nil && order.CustomerName == nil {
+		order.CustomerName = current.CustomerName
+	}
+	if order.ItemID == nil && order.PartNumber == nil {
+		order.ItemID = current.ItemID
+		order.PartNumber = current.PartNumber
+		order.Description = current.Description
+	}
+	if order.HoldDays == 0 {
+		order.HoldDays = current.HoldDays
+	}
+	if err := s.validateSpecialOrder(ctx, order); err != nil {
+		return err
+	}
+
+	// Units received on a backorder are held for it already
+	if current.QuantityReceived > 0 {
+		if order.ItemID == nil || current.ItemID == nil || *order.ItemID != *current.ItemID ||
+			order.Quantity < current.QuantityReceived {
+			return ErrUnitsHeld
+		}
+	}
+
+	return s.repo.Update(ctx, order)
+}
+
+// Order places a requested special order with a supplier. The supplier and
+// unit cost fall back to those on the order and then to the item's.
+func (s *specialOrderService) Order(ctx context.Context, id int, req *specialordermodels.OrderRequest) (*specialordermodels.SpecialOrder, error) {
+	order, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != specialordermodels.StatusRequested {
+		return nil, ErrSpecialOrderNotRequested
+	}
+
+	if req.SupplierID != nil {
+		order.SupplierID = req.SupplierID
+	}
+	if req.UnitCost != nil {
+		order.UnitCost = req.UnitCost
+	}
+
+	if order.ItemID != nil {
+		item, err := s.repo.GetItem(ctx, *order.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, ErrItemNotFound
+		}
+		if item.IsKit {
+			return nil, ErrItemIsKit
+		}
+		if order.SupplierID == nil {
+			order.SupplierID = item.SupplierID
+		}
+		if order.UnitCost == nil {
+			order.UnitCost = &item.BuyPrice
+		}
+	}
+
+	if order.SupplierID == nil {
+		return nil, ErrSupplierRequired
+	}
+	exists, err := s.repo.SupplierExists(ctx, *order.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrSupplierNotFound
+	}
+
+	if order.UnitCost == nil {
+		return nil, ErrUnitCostRequired
+	}
+	if *order.UnitCost < 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	if req.PurchaseOrderID != nil {
+		po, err := s.repo.GetPurchaseOrder(ctx, *req.PurchaseOrderID)
+		if err != nil {
+			return nil, err
+		}
+		if po == nil {
+			return nil, ErrPurchaseOrderNotFound
+		}
+		if po.Status != "draft" {
+			return nil, ErrPurchaseOrderNotDraft
+		}
+		if po.SupplierID != *order.SupplierID {
+			return nil, ErrPurchaseOrderSupplier
+		}
+	}
+
+	if err := s.repo.Order(ctx, order, req.PurchaseOrderID, req.CreatedBy); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// MarkNotified records that the customer has been told their order is
+// ready to collect
+func (s *specialOrderService) MarkNotified(ctx context.Context, id int) (*specialordermodels.SpecialOrder, error) {
+	order, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != specialordermodels.StatusReady {
+		return nil, ErrSpecialOrderNotReady
+	}
+
+	if err := s.repo.MarkNotified(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Cancel closes an order that has not been collected. The deposit is left
+// for the counter staff to refund or keep.
+func (s *specialOrderService) Cancel(ctx context.Context, id int) (*specialordermodels.SpecialOrder, error) {
+	order, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch order.Status {
+	case specialordermodels.StatusCollected, specialordermodels.StatusCancelled:
+		return nil, ErrSpecialOrderNotCancelable
+	}
+
+	if err := s.repo.Cancel(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Helper functions
+
+func (s *specialOrderService) validateSpecialOrder(ctx context.Context, order *specialordermodels.SpecialOrder) error {
+	if order.CustomerID != nil {
+		customer, err := s.repo.GetCustomer(ctx, *order.CustomerID)
+		if err != nil {
+			return err
+		}
+		if customer == nil {
+			return ErrCustomerNotFound
+		}
+		if !customer.IsActive {
+			return ErrCustomerInactive
+		}
+		if order.CustomerName == nil {
+			order.CustomerName = &customer.Name
+		}
+		if order.CustomerPhone == nil {
+			order.CustomerPhone = customer.Phone
+		}
+		if order.CustomerEmail == nil {
+			order.CustomerEmail = customer.Email
+		}
+	} else if order.CustomerName == nil || strings.TrimSpace(*order.CustomerName) == "" {
+		return ErrCustomerRequired
+	}
+
+	if err := s.resolvePart(ctx, order); err != nil {
+		return err
+	}
+
+	if order.Quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	if order.HoldDays <= 0 {
+		return ErrInvalidHoldDays
+	}
+	if (order.UnitCost != nil && *order.UnitCost < 0) || (order.QuotedPrice != nil && *order.QuotedPrice < 0) {
+		return ErrInvalidPrice
+	}
+	if order.DepositAmount < 0 {
+		return ErrInvalidDeposit
+	}
+	if order.QuotedPrice != nil && order.DepositAmount > *order.QuotedPrice*float64(order.Quantity) {
+		return ErrInvalidDeposit
+	}
+
+	if order.SupplierID != nil {
+		exists, err := s.repo.SupplierExists(ctx, *order.SupplierID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrSupplierNotFound
+		}
+	}
+	return nil
+}
+
+// resolvePart links the order to an item, looking the part number up in the
+// catalogue when no item is given
+func (s *specialOrderService) resolvePart(ctx context.Context, order *specialordermodels.SpecialOrder) error {
+	if order.ItemID != nil {
+		item, err := s.repo.GetItem(ctx, *order.ItemID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return ErrItemNotFound
+		}
+		if item.IsKit {
+			return ErrItemIsKit
+		}
+		order.PartNumber = &item.PartNumber
+		order.Description = &item.Description
+		return nil
+	}
+
+	if order.PartNumber == nil || strings.TrimSpace(*order.PartNumber) == "" {
+		return ErrPartRequired
+	}
+	partNumber := strings.TrimSpace(*order.PartNumber)
+	order.PartNumber = &partNumber
+
+	item, err := s.repo.GetItemByPartNumber(ctx, partNumber)
+	if err != nil {
+		return err
+	}
+	if item != nil {
+		if item.IsKit {
+			return ErrItemIsKit
+		}
+		order.ItemID = &item.ItemID
+		order.Description = &item.Description
+		return nil
+	}
+
+	if order.Description == nil || strings.TrimSpace(*order.Description) == "" {
+		return ErrPartRequired
+	}
+	return nil
+}
+
+func isValidStatus(status string) bool {
+	switch status {
+	case specialordermodels.StatusRequested, specialordermodels.StatusOrdered,
+		specialordermodels.StatusReady, specialordermodels.StatusCollected,
+		specialordermodels.StatusCancelled:
+		return true
+	}
+	return false
+}
+
+func setBalanceDue(order *specialordermodels.SpecialOrder) {
+	if order.QuotedPrice == nil {
+		return
+	}
+	balance := *order.QuotedPrice*float64(order.Quantity) - order.DepositAmount
+	order.BalanceDue = &balance
+}
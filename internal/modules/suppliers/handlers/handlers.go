@@ -76,6 +76,8 @@ func (h *SupplierHandler) CreateSupplier(c echo.Context) error {
         switch err {
         case services.ErrDuplicateSupplierName:
             return echo.NewHTTPError(http.StatusConflict, err.Error())
+        case services.ErrInvalidPaymentTerms, services.ErrInvalidLeadTime:
+            return echo.NewHTTPError(http.StatusBadRequest, err.Error())
         default:
             return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
         }
@@ -106,6 +108,8 @@ func (h *SupplierHandler) UpdateSupplier(c echo.Context) error {
             return echo.NewHTTPError(http.StatusNotFound, err.Error())
         case services.ErrDuplicateSupplierName:
             return echo.NewHTTPError(http.StatusConflict, err.Error())
+        case services.ErrInvalidPaymentTerms, services.ErrInvalidLeadTime:
+            return echo.NewHTTPError(http.StatusBadRequest, err.Error())
         default:
             return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
         }
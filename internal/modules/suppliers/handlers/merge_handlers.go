@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+	"github.com/hsrvms/autoparts/internal/modules/suppliers/services"
+	"github.com/labstack/echo/v4"
+)
+
+type MergeHandler struct {
+	service services.MergeService
+}
+
+func NewMergeHandler(service services.MergeService) *MergeHandler {
+	return &MergeHandler{
+		service: service,
+	}
+}
+
+// GetDuplicateSuppliers handles listing suppliers that look like duplicates
+// of one another
+func (h *MergeHandler) GetDuplicateSuppliers(c echo.Context) error {
+	var threshold *float64
+	if t := c.QueryParam("threshold"); t != "" {
+		value, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, services.ErrInvalidMergeThreshold.Error())
+		}
+		threshold = &value
+	}
+
+	ctx := c.Request().Context()
+	duplicates, err := h.service.GetDuplicates(ctx, threshold)
+	if err != nil {
+		return mergeError(err)
+	}
+
+	return c.JSON(http.StatusOK, duplicates)
+}
+
+// MergeSuppliers handles merging a duplicate supplier into the supplier in
+// the path
+func (h *MergeHandler) MergeSuppliers(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier ID")
+	}
+
+	request := new(suppliermodels.MergeRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	record, err := h.service.MergeSuppliers(ctx, id, request)
+	if err != nil {
+		return mergeError(err)
+	}
+
+	return c.JSON(http.StatusOK, record)
+}
+
+// GetSupplierMerges handles listing past supplier merges
+func (h *MergeHandler) GetSupplierMerges(c echo.Context) error {
+	var supplierID *int
+	if id, err := strconv.Atoi(c.QueryParam("supplier_id")); err == nil {
+		supplierID = &id
+	}
+
+	ctx := c.Request().Context()
+	records, err := h.service.GetMergeHistory(ctx, supplierID)
+	if err != nil {
+		return mergeError(err)
+	}
+
+	return c.JSON(http.StatusOK, records)
+}
+
+func mergeError(err error) error {
+	switch err {
+	case services.ErrInvalidMergeThreshold, services.ErrInvalidSupplierID, services.ErrInvalidDuplicateID:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrSupplierNotFound, services.ErrMergedSupplierNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrSharedInvoiceNumbers:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrMergeIntoSelf:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+	"github.com/hsrvms/autoparts/internal/modules/suppliers/services"
+	"github.com/labstack/echo/v4"
+)
+
+type PayablesHandler struct {
+	service services.PayablesService
+}
+
+func NewPayablesHandler(service services.PayablesService) *PayablesHandler {
+	return &PayablesHandler{
+		service: service,
+	}
+}
+
+// GetSupplierInvoices handles retrieval of supplier invoices with optional filtering
+func (h *PayablesHandler) GetSupplierInvoices(c echo.Context) error {
+	filter := &suppliermodels.SupplierInvoiceFilter{}
+
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		if id, err := strconv.Atoi(supplierID); err == nil {
+			filter.SupplierID = &id
+		}
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if dueBefore := c.QueryParam("due_before"); dueBefore != "" {
+		if date, err := parseDate(dueBefore); err == nil {
+			filter.DueBefore = &date
+		}
+	}
+
+	if openOnly := c.QueryParam("open_only"); openOnly == "true" {
+		open := true
+		filter.OpenOnly = &open
+	}
+
+	ctx := c.Request().Context()
+	invoices, err := h.service.GetInvoices(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, invoices)
+}
+
+// GetSupplierInvoiceByID handles retrieval of a supplier invoice with its purchases and payments
+func (h *PayablesHandler) GetSupplierInvoiceByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier invoice ID")
+	}
+
+	ctx := c.Request().Context()
+	invoice, err := h.service.GetInvoiceByID(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidSupplierInvoiceID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrSupplierInvoiceNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, invoice)
+}
+
+// CreateSupplierInvoice handles recording an invoice received from a supplier
+func (h *PayablesHandler) CreateSupplierInvoice(c echo.Context) error {
+	req := new(suppliermodels.CreateSupplierInvoiceRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	invoice, err := h.service.CreateInvoice(ctx, req)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidSupplierID, services.ErrInvoiceNumberRequired,
+			services.ErrNoPurchasesToInvoice, services.ErrDuplicatePurchaseOnInvoice,
+			services.ErrInvalidInvoiceTotal, services.ErrInvalidDueDate:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrSupplierNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrDuplicateInvoiceNumber:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		case services.ErrPurchaseNotAvailable, services.ErrInvalidPaymentTerms:
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusCreated, invoice)
+}
+
+// VoidSupplierInvoice handles cancelling an unpaid supplier invoice
+func (h *PayablesHandler) VoidSupplierInvoice(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier invoice ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.VoidInvoice(ctx, id); err != nil {
+		switch err {
+		case services.ErrInvalidSupplierInvoiceID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrSupplierInvoiceNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrSupplierInvoiceVoid, services.ErrSupplierInvoiceHasPayments:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RecordSupplierPayment handles a full or partial payment of a supplier invoice
+func (h *PayablesHandler) RecordSupplierPayment(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier invoice ID")
+	}
+
+	payment := new(suppliermodels.SupplierPayment)
+	if err := c.Bind(payment); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	payment.SupplierInvoiceID = id
+
+	ctx := c.Request().Context()
+	invoice, err := h.service.RecordPayment(ctx, payment)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidSupplierInvoiceID, services.ErrInvalidPaymentAmount,
+			services.ErrInvalidPaymentMethod:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrSupplierInvoiceNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrSupplierInvoiceVoid, services.ErrSupplierInvoicePaid:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		case services.ErrPaymentExceedsBalance:
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusCreated, invoice)
+}
+
+// GetPayablesAging handles retrieval of aged payables for all suppliers
+func (h *PayablesHandler) GetPayablesAging(c echo.Context) error {
+	asOf := time.Now()
+	if value := c.QueryParam("as_of"); value != "" {
+		date, err := parseDate(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid as_of date")
+		}
+		asOf = date
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetAgingReport(ctx, asOf)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetWeeklyPayables handles retrieval of what we owe each supplier this week
+func (h *PayablesHandler) GetWeeklyPayables(c echo.Context) error {
+	date := time.Now()
+	if value := c.QueryParam("date"); value != "" {
+		parsed, err := parseDate(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid date")
+		}
+		date = parsed
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetWeeklyPayables(ctx, date)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetSupplierPayables handles retrieval of what we owe a single supplier this week
+func (h *PayablesHandler) GetSupplierPayables(c echo.Context) error {
+	supplierID, err := strconv.Atoi(c.Param("supplierId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier ID")
+	}
+
+	date := time.Now()
+	if value := c.QueryParam("date"); value != "" {
+		parsed, err := parseDate(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid date")
+		}
+		date = parsed
+	}
+
+	ctx := c.Request().Context()
+	payables, err := h.service.GetSupplierPayables(ctx, supplierID, date)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidSupplierID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrSupplierNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, payables)
+}
+
+// parseDate accepts either a plain date (2006-01-02) or an RFC3339 timestamp
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,36 @@
+package suppliermodels
+
+import "time"
+
+// DuplicateSupplier is a pair of suppliers that look like the same business.
+// The older supplier comes first as the suggested survivor. Reasons lists
+// what matched: name, email, phone or tax_id.
+type DuplicateSupplier struct {
+	SupplierID    int      `json:"supplier_id"`
+	Name          string   `json:"name"`
+	DuplicateID   int      `json:"duplicate_id"`
+	DuplicateName string   `json:"duplicate_name"`
+	Reasons       []string `json:"reasons"`
+	Similarity    float64  `json:"similarity"` // Of the names
+}
+
+// MergeRequest names the duplicate to merge into the supplier in the path
+type MergeRequest struct {
+	DuplicateID int     `json:"duplicate_id"`
+	MergedBy    *string `json:"merged_by,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+// MergeRecord is the audit entry of a merge. Repointed counts the rows moved
+// to the survivor by table.
+type MergeRecord struct {
+	MergeID    int              `json:"merge_id"`
+	EntityType string           `json:"entity_type"`
+	SurvivorID int              `json:"survivor_id"`
+	MergedID   int              `json:"merged_id"`
+	MergedName string           `json:"merged_name"`
+	Repointed  map[string]int64 `json:"repointed"`
+	MergedBy   *string          `json:"merged_by,omitempty"`
+	Notes      *string          `json:"notes,omitempty"`
+	MergedAt   time.Time        `json:"merged_at"`
+}
@@ -0,0 +1,134 @@
+package suppliermodels
+
+import "time"
+
+// Supplier invoice statuses
+const (
+	InvoiceStatusOpen          = "open"
+	InvoiceStatusPartiallyPaid = "partially_paid"
+	InvoiceStatusPaid          = "paid"
+	InvoiceStatusVoid          = "void"
+)
+
+// PaymentTerms is the structured form of Supplier.PaymentTerms, e.g.
+// "Net 30", "COD", "2/10 Net 30" or "Net 30 EOM".
+type PaymentTerms struct {
+	Raw             string  `json:"raw"`
+	NetDays         int     `json:"net_days"`
+	EndOfMonth      bool    `json:"end_of_month"`
+	DiscountPercent float64 `json:"discount_percent,omitempty"`
+	DiscountDays    int     `json:"discount_days,omitempty"`
+}
+
+type SupplierInvoice struct {
+	SupplierInvoiceID int        `json:"supplier_invoice_id" db:"supplier_invoice_id"`
+	SupplierID        int        `json:"supplier_id" db:"supplier_id"`
+	InvoiceNumber     string     `json:"invoice_number" db:"invoice_number"`
+	InvoiceDate       time.Time  `json:"invoice_date" db:"invoice_date"`
+	DueDate           time.Time  `json:"due_date" db:"due_date"`
+	DiscountDate      *time.Time `json:"discount_date,omitempty" db:"discount_date"`
+	DiscountPercent   *float64   `json:"discount_percent,omitempty" db:"discount_percent"`
+	TotalAmount       float64    `json:"total_amount" db:"total_amount"`
+	// Amount settled so far, including any early payment discount taken
+	AmountPaid float64   `json:"amount_paid" db:"amount_paid"`
+	Status     string    `json:"status" db:"status"`
+	Notes      *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	SupplierName string              `json:"supplier_name,omitempty" db:"supplier_name"`
+	BalanceDue   float64             `json:"balance_due" db:"-"`
+	Purchases    []*InvoicedPurchase `json:"purchases,omitempty" db:"-"`
+	Payments     []*SupplierPayment  `json:"payments,omitempty" db:"-"`
+}
+
+// InvoicedPurchase is a purchase line covered by a supplier invoice
+type InvoicedPurchase struct {
+	PurchaseID      int       `json:"purchase_id" db:"purchase_id"`
+	Date            time.Time `json:"date" db:"date"`
+	ItemID          int       `json:"item_id" db:"item_id"`
+	ItemPartNumber  string    `json:"item_part_number" db:"item_part_number"`
+	ItemDescription string    `json:"item_description" db:"item_description"`
+	Quantity        int       `json:"quantity" db:"quantity"`
+	CostPerUnit     float64   `json:"cost_per_unit" db:"cost_per_unit"`
+	TotalCost       float64   `json:"total_cost" db:"total_cost"`
+}
+
+type SupplierInvoiceFilter struct {
+	SupplierID *int       `query:"supplier_id"`
+	Status     *string    `query:"status"`
+	DueBefore  *time.Time `query:"due_before"`
+	OpenOnly   *bool      `query:"open_only"`
+}
+
+// CreateSupplierInvoiceRequest records a supplier invoice for one or more
+// purchases. The due date is derived from the supplier's payment terms
+// unless given explicitly; the total defaults to the sum of the purchases.
+type CreateSupplierInvoiceRequest struct {
+	SupplierID    int        `json:"supplier_id"`
+	InvoiceNumber string     `json:"invoice_number"`
+	InvoiceDate   *time.Time `json:"invoice_date,omitempty"`
+	DueDate       *time.Time `json:"due_date,omitempty"`
+	PurchaseIDs   []int      `json:"purchase_ids"`
+	TotalAmount   *float64   `json:"total_amount,omitempty"`
+	Notes         *string    `json:"notes,omitempty"`
+}
+
+type SupplierPayment struct {
+	SupplierPaymentID int       `json:"supplier_payment_id" db:"supplier_payment_id"`
+	SupplierInvoiceID int       `json:"supplier_invoice_id" db:"supplier_invoice_id"`
+	PaymentDate       time.Time `json:"payment_date" db:"payment_date"`
+	Amount            float64   `json:"amount" db:"amount"`
+	DiscountTaken     float64   `json:"discount_taken" db:"discount_taken"`
+	Method            string    `json:"method" db:"method"`
+	Reference         *string   `json:"reference,omitempty" db:"reference"`
+	PaidBy            *string   `json:"paid_by,omitempty" db:"paid_by"`
+	Notes             *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	InvoiceNumber string `json:"invoice_number,omitempty" db:"invoice_number"`
+}
+
+// PayablesAgingBuckets splits an amount owed by days past due
+type PayablesAgingBuckets struct {
+	Current    float64 `json:"current"`
+	Days1To30  float64 `json:"days_1_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Over90     float64 `json:"days_over_90"`
+	Total      float64 `json:"total"`
+}
+
+type SupplierAging struct {
+	SupplierID   int    `json:"supplier_id"`
+	SupplierName string `json:"supplier_name"`
+	PayablesAgingBuckets
+}
+
+type PayablesAgingReport struct {
+	AsOf      time.Time            `json:"as_of"`
+	Suppliers []*SupplierAging     `json:"suppliers"`
+	Totals    PayablesAgingBuckets `json:"totals"`
+}
+
+// SupplierPayables is what we owe one supplier for a given week: overdue
+// invoices plus those falling due by the end of the week.
+type SupplierPayables struct {
+	SupplierID        int                `json:"supplier_id"`
+	SupplierName      string             `json:"supplier_name"`
+	PaymentTerms      *PaymentTerms      `json:"payment_terms,omitempty"`
+	Overdue           float64            `json:"overdue"`
+	DueThisWeek       float64            `json:"due_this_week"`
+	TotalDue          float64            `json:"total_due"`
+	DiscountAvailable float64            `json:"discount_available"`
+	Invoices          []*SupplierInvoice `json:"invoices"`
+}
+
+type WeeklyPayablesReport struct {
+	WeekStart time.Time           `json:"week_start"`
+	WeekEnd   time.Time           `json:"week_end"`
+	Suppliers []*SupplierPayables `json:"suppliers"`
+	TotalDue  float64             `json:"total_due"`
+}
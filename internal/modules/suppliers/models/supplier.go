@@ -11,6 +11,7 @@ type Supplier struct {
 	Address       *string   `json:"address,omitempty" db:"address"`
 	TaxID         *string   `json:"tax_id,omitempty" db:"tax_id"`
 	PaymentTerms  *string   `json:"payment_terms,omitempty" db:"payment_terms"`
+	LeadTimeDays  *int      `json:"lead_time_days,omitempty" db:"lead_time_days"`
 	Notes         *string   `json:"notes,omitempty" db:"notes"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
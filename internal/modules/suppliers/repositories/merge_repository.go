@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+)
+
+// MergeRepository defines the interface for finding duplicate suppliers and
+// merging them
+type MergeRepository interface {
+	GetDuplicateSuppliers(ctx context.Context, threshold float64) ([]*suppliermodels.DuplicateSupplier, error)
+	GetSharedInvoiceNumbers(ctx context.Context, survivorID, duplicateID int) ([]string, error)
+	MergeSuppliers(ctx context.Context, survivorID int, request *suppliermodels.MergeRequest) (*suppliermodels.MergeRecord, error)
+	GetMergeHistory(ctx context.Context, supplierID *int) ([]*suppliermodels.MergeRecord, error)
+}
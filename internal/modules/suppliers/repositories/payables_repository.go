@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+)
+
+type PayablesRepository interface {
+	// Supplier invoice operations
+	GetInvoices(ctx context.Context, filter *suppliermodels.SupplierInvoiceFilter) ([]*suppliermodels.SupplierInvoice, error)
+	GetInvoiceByID(ctx context.Context, id int) (*suppliermodels.SupplierInvoice, error)
+	GetInvoiceByNumber(ctx context.Context, supplierID int, invoiceNumber string) (*suppliermodels.SupplierInvoice, error)
+	GetInvoicePurchases(ctx context.Context, invoiceID int) ([]*suppliermodels.InvoicedPurchase, error)
+	GetUninvoicedPurchases(ctx context.Context, supplierID int, purchaseIDs []int) ([]*suppliermodels.InvoicedPurchase, error)
+	CreateInvoice(ctx context.Context, invoice *suppliermodels.SupplierInvoice, purchaseIDs []int) (int, error)
+	VoidInvoice(ctx context.Context, id int) error
+
+	// Payment operations
+	GetInvoicePayments(ctx context.Context, invoiceID int) ([]*suppliermodels.SupplierPayment, error)
+	CreatePayment(ctx context.Context, payment *suppliermodels.SupplierPayment) (int, error)
+
+	// Reporting
+	GetOpenInvoicesAsOf(ctx context.Context, supplierID *int, asOf time.Time) ([]*suppliermodels.SupplierInvoice, error)
+}
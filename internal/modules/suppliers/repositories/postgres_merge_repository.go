@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"context"
+
+	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+)
+
+type PostgresMergeRepository struct {
+	db *db.Database
+}
+
+func NewPostgresMergeRepository(database *db.Database) MergeRepository {
+	return &PostgresMergeRepository{
+		db: database,
+	}
+}
+
+// GetDuplicateSuppliers pairs suppliers with alike names, or the same email,
+// phone number or tax ID, most alike names first
+func (r *PostgresMergeRepository) GetDuplicateSuppliers(ctx context.Context, threshold float64) ([]*suppliermodels.DuplicateSupplier, error) {
+	query := `
+		SELECT supplier_id, name, duplicate_id, duplicate_name,
+			   array_remove(ARRAY[
+				   CASE WHEN same_name THEN 'name' END,
+				   CASE WHEN same_email THEN 'email' END,
+				   CASE WHEN same_phone THEN 'phone' END,
+				   CASE WHEN same_tax_id THEN 'tax_id' END
+			   ], NULL),
+			   score
+		FROM (
+			SELECT a.supplier_id, a.name, b.supplier_id AS duplicate_id, b.name AS duplicate_name,
+				   name_similarity(a.name, b.name) AS score,
+				   name_similarity(a.name, b.name) >= $1 AS same_name,
+				   COALESCE(lower(btrim(a.email)) <> '' AND lower(btrim(a.email)) = lower(btrim(b.email)), FALSE) AS same_email,
+				   COALESCE(length(normalize_code(a.phone)) >= 7 AND normalize_code(a.phone) = normalize_code(b.phone), FALSE) AS same_phone,
+				   COALESCE(normalize_code(a.tax_id) <> '' AND normalize_code(a.tax_id) = normalize_code(b.tax_id), FALSE) AS same_tax_id
+			FROM suppliers a
+			JOIN suppliers b ON b.supplier_id > a.supplier_id
+		) pairs
+		WHERE same_name OR same_email OR same_phone OR same_tax_id
+		ORDER BY score DESC, name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var duplicates []*suppliermodels.DuplicateSupplier
+	for rows.Next() {
+		duplicate := &suppliermodels.DuplicateSupplier{}
+		err := rows.Scan(
+			&duplicate.SupplierID, &duplicate.Name, &duplicate.DuplicateID, &duplicate.DuplicateName,
+			&duplicate.Reasons, &duplicate.Similarity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+
+	return duplicates, rows.Err()
+}
+
+// GetSharedInvoiceNumbers returns the invoice numbers both suppliers have
+// recorded, which would clash once the invoices belong to one supplier
+func (r *PostgresMergeRepository) GetSharedInvoiceNumbers(ctx context.Context, survivorID, duplicateID int) ([]string, error) {
+	query := `
+		SELECT s.invoice_number
+		FROM supplier_invoices s
+		JOIN supplier_invoices d ON d.invoice_number = s.invoice_number AND d.supplier_id = $2
+		WHERE s.supplier_id = $1
+		ORDER BY s.invoice_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, survivorID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var numbers []string
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+
+	return numbers, rows.Err()
+}
+
+// MergeSuppliers moves the duplicate's items, orders, purchases, invoices,
+// claims and core returns to the survivor, fills in contact details the
+// survivor is missing, records the merge and deletes the duplicate
+func (r *PostgresMergeRepository) MergeSuppliers(ctx context.Context, survivorID int, request *suppliermodels.MergeRequest) (*suppliermodels.MergeRecord, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	record := &suppliermodels.MergeRecord{
+		EntityType: "supplier",
+		SurvivorID: survivorID,
+		MergedID:   request.DuplicateID,
+		Repointed:  map[string]int64{},
+		MergedBy:   request.MergedBy,
+		Notes:      request.Notes,
+	}
+
+	tables := []string{
+		"items", "purchase_orders", "purchases", "supplier_invoices",
+		"special_orders", "warranty_claims", "core_supplier_returns",
+	}
+	for _, table := range tables {
+		result, err := tx.Exec(ctx, `UPDATE `+table+` SET supplier_id = $1 WHERE supplier_id = $2`, survivorID, request.DuplicateID)
+		if err != nil {
+			return nil, err
+		}
+		if n := result.RowsAffected(); n > 0 {
+			record.Repointed[table] = n
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE suppliers s
+		SET contact_person = COALESCE(s.contact_person, d.contact_person),
+			phone = COALESCE(s.phone, d.phone),
+			email = COALESCE(s.email, d.email),
+			address = COALESCE(s.address, d.address),
+			tax_id = COALESCE(s.tax_id, d.tax_id),
+			payment_terms = COALESCE(s.payment_terms, d.payment_terms)
+		FROM suppliers d
+		WHERE s.supplier_id = $1 AND d.supplier_id = $2
+	`, survivorID, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO merge_audit (
+			entity_type, survivor_id, merged_id, merged_name, merged_record, repointed, merged_by, notes
+		)
+		SELECT $1, $2, d.supplier_id, d.name, to_jsonb(d), $4, $5, $6
+		FROM suppliers d
+		WHERE d.supplier_id = $3
+		RETURNING merged_name, merge_id, merged_at
+	`,
+		record.EntityType, record.SurvivorID, record.MergedID, record.Repointed, record.MergedBy, record.Notes,
+	).Scan(&record.MergedName, &record.MergeID, &record.MergedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM suppliers WHERE supplier_id = $1`, request.DuplicateID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetMergeHistory returns past supplier merges, newest first, optionally
+// those a supplier took part in
+func (r *PostgresMergeRepository) GetMergeHistory(ctx context.Context, supplierID *int) ([]*suppliermodels.MergeRecord, error) {
+	query := `
+		SELECT merge_id, entity_type, survivor_id, merged_id, merged_name, repointed,
+			   merged_by, notes, merged_at
+		FROM merge_audit
+		WHERE entity_type = 'supplier'
+			AND ($1::int IS NULL OR survivor_id = $1 OR merged_id = $1)
+		ORDER BY merged_at DESC, merge_id DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*suppliermodels.MergeRecord
+	for rows.Next() {
+		record := &suppliermodels.MergeRecord{}
+		err := rows.Scan(
+			&record.MergeID, &record.EntityType, &record.SurvivorID, &record.MergedID, &record.MergedName,
+			&record.Repointed, &record.MergedBy, &record.Notes, &record.MergedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
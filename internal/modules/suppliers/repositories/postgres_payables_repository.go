@@ -0,0 +1,451 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresPayablesRepository struct {
+	db *db.Database
+}
+
+func NewPostgresPayablesRepository(database *db.Database) PayablesRepository {
+	return &PostgresPayablesRepository{
+		db: database,
+	}
+}
+
+const supplierInvoiceColumns = `
+	si.supplier_invoice_id, si.supplier_id, si.invoice_number, si.invoice_date,
+	si.due_date, si.discount_date, si.discount_percent, si.total_amount,
+	si.amount_paid, si.status, si.notes, si.created_at, si.updated_at,
+	s.name as supplier_name
+`
+
+func scanSupplierInvoice(row pgx.Row) (*suppliermodels.SupplierInvoice, error) {
+	invoice := &suppliermodels.SupplierInvoice{}
+	err := row.Scan(
+		&invoice.SupplierInvoiceID,
+		&invoice.SupplierID,
+		&invoice.InvoiceNumber,
+		&invoice.InvoiceDate,
+		&invoice.DueDate,
+		&invoice.DiscountDate,
+		&invoice.DiscountPercent,
+		&invoice.TotalAmount,
+		&invoice.AmountPaid,
+		&invoice.Status,
+		&invoice.Notes,
+		&invoice.CreatedAt,
+		&invoice.UpdatedAt,
+		&invoice.SupplierName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invoice.BalanceDue = invoice.TotalAmount - invoice.AmountPaid
+	return invoice, nil
+}
+
+func (r *PostgresPayablesRepository) GetInvoices(ctx context.Context, filter *suppliermodels.SupplierInvoiceFilter) ([]*suppliermodels.SupplierInvoice, error) {
+	query := `
+		SELECT ` + supplierInvoiceColumns + `
+		FROM supplier_invoices si
+		JOIN suppliers s ON si.supplier_id = s.supplier_id
+		WHERE 1=1
+	`
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.SupplierID != nil {
+			conditions = append(conditions, fmt.Sprintf("si.supplier_id = $%d", paramCount))
+			params = append(params, *filter.SupplierID)
+			paramCount++
+		}
+
+		if filter.Status != nil {
+			conditions = append(conditions, fmt.Sprintf("si.status = $%d", paramCount))
+			params = append(params, *filter.Status)
+			paramCount++
+		}
+
+		if filter.DueBefore != nil {
+			conditions = append(conditions, fmt.Sprintf("si.due_date <= $%d", paramCount))
+			params = append(params, *filter.DueBefore)
+			paramCount++
+		}
+
+		if filter.OpenOnly != nil && *filter.OpenOnly {
+			conditions = append(conditions, "si.status IN ('open', 'partially_paid')")
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY si.due_date, si.supplier_invoice_id"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []*suppliermodels.SupplierInvoice
+	for rows.Next() {
+		invoice, err := scanSupplierInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, rows.Err()
+}
+
+func (r *PostgresPayablesRepository) GetInvoiceByID(ctx context.Context, id int) (*suppliermodels.SupplierInvoice, error) {
+	query := `
+		SELECT ` + supplierInvoiceColumns + `
+		FROM supplier_invoices si
+		JOIN suppliers s ON si.supplier_id = s.supplier_id
+		WHERE si.supplier_invoice_id = $1
+	`
+
+	invoice, err := scanSupplierInvoice(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (r *PostgresPayablesRepository) GetInvoiceByNumber(ctx context.Context, supplierID int, invoiceNumber string) (*suppliermodels.SupplierInvoice, error) {
+	query := `
+		SELECT ` + supplierInvoiceColumns + `
+		FROM supplier_invoices si
+		JOIN suppliers s ON si.supplier_id = s.supplier_id
+		WHERE si.supplier_id = $1 AND si.invoice_number = $2
+	`
+
+	invoice, err := scanSupplierInvoice(r.db.Pool.QueryRow(ctx, query, supplierID, invoiceNumber))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (r *PostgresPayablesRepository) queryPurchases(ctx context.Context, where string, params ...interface{}) ([]*suppliermodels.InvoicedPurchase, error) {
+	query := `
+		SELECT p.purchase_id, p.date, p.item_id, i.part_number, i.description,
+			p.quantity, p.cost_per_unit, p.total_cost
+		FROM purchases p
+		JOIN items i ON p.item_id = i.item_id
+		WHERE ` + where + `
+		ORDER BY p.date, p.purchase_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var purchases []*suppliermodels.InvoicedPurchase
+	for rows.Next() {
+		purchase := &suppliermodels.InvoicedPurchase{}
+		err := rows.Scan(
+			&purchase.PurchaseID,
+			&purchase.Date,
+			&purchase.ItemID,
+			&purchase.ItemPartNumber,
+			&purchase.ItemDescription,
+			&purchase.Quantity,
+			&purchase.CostPerUnit,
+			&purchase.TotalCost,
+		)
+		if err != nil {
+			return nil, err
+		}
+		purchases = append(purchases, purchase)
+	}
+
+	return purchases, rows.Err()
+}
+
+func (r *PostgresPayablesRepository) GetInvoicePurchases(ctx context.Context, invoiceID int) ([]*suppliermodels.InvoicedPurchase, error) {
+	return r.queryPurchases(ctx, "p.supplier_invoice_id = $1", invoiceID)
+}
+
+// GetUninvoicedPurchases returns those of the given purchases that belong to
+// the supplier and are not on an invoice yet.
+func (r *PostgresPayablesRepository) GetUninvoicedPurchases(ctx context.Context, supplierID int, purchaseIDs []int) ([]*suppliermodels.InvoicedPurchase, error) {
+	return r.queryPurchases(ctx,
+		"p.supplier_id = $1 AND p.purchase_id = ANY($2) AND p.supplier_invoice_id IS NULL",
+		supplierID, purchaseIDs)
+}
+
+// CreateInvoice inserts the invoice and links the purchases it covers. If any
+// purchase was invoiced in the meantime nothing is written.
+func (r *PostgresPayablesRepository) CreateInvoice(ctx context.Context, invoice *suppliermodels.SupplierInvoice, purchaseIDs []int) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO supplier_invoices (
+			supplier_id, invoice_number, invoice_date, due_date,
+			discount_date, discount_percent, total_amount, status, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7::numeric, $8, $9)
+		RETURNING supplier_invoice_id
+	`
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		invoice.SupplierID,
+		invoice.InvoiceNumber,
+		invoice.InvoiceDate,
+		invoice.DueDate,
+		invoice.DiscountDate,
+		invoice.DiscountPercent,
+		invoice.TotalAmount,
+		suppliermodels.InvoiceStatusOpen,
+		invoice.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE purchases SET supplier_invoice_id = $1
+		WHERE purchase_id = ANY($2)
+			AND supplier_id = $3
+			AND supplier_invoice_id IS NULL
+	`, id, purchaseIDs, invoice.SupplierID)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() != int64(len(purchaseIDs)) {
+		return 0, errors.New("one or more purchases are no longer available for invoicing")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// VoidInvoice cancels an unpaid supplier invoice and releases its purchases
+func (r *PostgresPayablesRepository) VoidInvoice(ctx context.Context, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE supplier_invoices SET status = 'void'
+		WHERE supplier_invoice_id = $1 AND status <> 'void' AND amount_paid = 0
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("supplier invoice cannot be voided")
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE purchases SET supplier_invoice_id = NULL WHERE supplier_invoice_id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresPayablesRepository) GetInvoicePayments(ctx context.Context, invoiceID int) ([]*suppliermodels.SupplierPayment, error) {
+	query := `
+		SELECT sp.supplier_payment_id, sp.supplier_invoice_id, sp.payment_date,
+			sp.amount, sp.discount_taken, sp.method, sp.reference, sp.paid_by,
+			sp.notes, sp.created_at, si.invoice_number
+		FROM supplier_payments sp
+		JOIN supplier_invoices si ON sp.supplier_invoice_id = si.supplier_invoice_id
+		WHERE sp.supplier_invoice_id = $1
+		ORDER BY sp.payment_date, sp.supplier_payment_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*suppliermodels.SupplierPayment
+	for rows.Next() {
+		payment := &suppliermodels.SupplierPayment{}
+		err := rows.Scan(
+			&payment.SupplierPaymentID,
+			&payment.SupplierInvoiceID,
+			&payment.PaymentDate,
+			&payment.Amount,
+			&payment.DiscountTaken,
+			&payment.Method,
+			&payment.Reference,
+			&payment.PaidBy,
+			&payment.Notes,
+			&payment.CreatedAt,
+			&payment.InvoiceNumber,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+// CreatePayment records a payment and settles that much (plus any discount
+// taken) of the invoice, marking it paid once nothing is left outstanding.
+func (r *PostgresPayablesRepository) CreatePayment(ctx context.Context, payment *suppliermodels.SupplierPayment) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE supplier_invoices SET
+			amount_paid = amount_paid + $2::numeric,
+			status = CASE
+				WHEN amount_paid + $2::numeric >= total_amount THEN 'paid'
+				ELSE 'partially_paid'
+			END
+		WHERE supplier_invoice_id = $1
+			AND status IN ('open', 'partially_paid')
+			AND amount_paid + $2::numeric <= total_amount
+	`, payment.SupplierInvoiceID, payment.Amount+payment.DiscountTaken)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return 0, errors.New("payment exceeds the invoice balance")
+	}
+
+	query := `
+		INSERT INTO supplier_payments (
+			supplier_invoice_id, payment_date, amount, discount_taken,
+			method, reference, paid_by, notes
+		) VALUES ($1, $2, $3::numeric, $4::numeric, $5, $6, $7, $8)
+		RETURNING supplier_payment_id
+	`
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		payment.SupplierInvoiceID,
+		payment.PaymentDate,
+		payment.Amount,
+		payment.DiscountTaken,
+		payment.Method,
+		payment.Reference,
+		payment.PaidBy,
+		payment.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetOpenInvoicesAsOf returns invoices dated before asOf that still had a
+// balance at that moment, ignoring payments made afterwards.
+func (r *PostgresPayablesRepository) GetOpenInvoicesAsOf(ctx context.Context, supplierID *int, asOf time.Time) ([]*suppliermodels.SupplierInvoice, error) {
+	query := `
+		SELECT ` + supplierInvoiceColumns + `, settled
+		FROM (
+			SELECT si.*, COALESCE((
+				SELECT SUM(sp.amount + sp.discount_taken)
+				FROM supplier_payments sp
+				WHERE sp.supplier_invoice_id = si.supplier_invoice_id
+					AND sp.payment_date < $1
+			), 0) AS settled
+			FROM supplier_invoices si
+			WHERE si.status <> 'void' AND si.invoice_date < $1
+		) si
+		JOIN suppliers s ON si.supplier_id = s.supplier_id
+		WHERE si.total_amount > si.settled
+	`
+
+	params := []interface{}{asOf}
+	if supplierID != nil {
+		query += " AND si.supplier_id = $2"
+		params = append(params, *supplierID)
+	}
+
+	query += " ORDER BY s.name, si.due_date, si.supplier_invoice_id"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []*suppliermodels.SupplierInvoice
+	for rows.Next() {
+		invoice := &suppliermodels.SupplierInvoice{}
+		var settled float64
+		err := rows.Scan(
+			&invoice.SupplierInvoiceID,
+			&invoice.SupplierID,
+			&invoice.InvoiceNumber,
+			&invoice.InvoiceDate,
+			&invoice.DueDate,
+			&invoice.DiscountDate,
+			&invoice.DiscountPercent,
+			&invoice.TotalAmount,
+			&invoice.AmountPaid,
+			&invoice.Status,
+			&invoice.Notes,
+			&invoice.CreatedAt,
+			&invoice.UpdatedAt,
+			&invoice.SupplierName,
+			&settled,
+		)
+		if err != nil {
+			return nil, err
+		}
+		invoice.AmountPaid = settled
+		invoice.BalanceDue = invoice.TotalAmount - settled
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, rows.Err()
+}
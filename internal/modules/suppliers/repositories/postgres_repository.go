@@ -24,7 +24,8 @@ func NewPostgresSupplierRepository(database *db.Database) SupplierRepository {
 func (r *PostgresSupplierRepository) GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter) ([]*suppliermodels.Supplier, error) {
     query := `
         SELECT DISTINCT s.supplier_id, s.name, s.contact_person, s.phone, s.email,
-               s.address, s.tax_id, s.payment_terms, s.notes, s.created_at, s.updated_at
+               s.address, s.tax_id, s.payment_terms, s.lead_time_days, s.notes,
+               s.created_at, s.updated_at
         FROM suppliers s
     `
     params := []interface{}{}
@@ -68,6 +69,7 @@ func (r *PostgresSupplierRepository) GetAll(ctx context.Context, filter *supplie
             &supplier.Address,
             &supplier.TaxID,
             &supplier.PaymentTerms,
+            &supplier.LeadTimeDays,
             &supplier.Notes,
             &supplier.CreatedAt,
             &supplier.UpdatedAt,
@@ -84,7 +86,8 @@ func (r *PostgresSupplierRepository) GetAll(ctx context.Context, filter *supplie
 func (r *PostgresSupplierRepository) GetByID(ctx context.Context, id int) (*suppliermodels.Supplier, error) {
     query := `
         SELECT supplier_id, name, contact_person, phone, email,
-               address, tax_id, payment_terms, notes, created_at, updated_at
+               address, tax_id, payment_terms, lead_time_days, notes,
+               created_at, updated_at
         FROM suppliers
         WHERE supplier_id = $1
     `
@@ -99,6 +102,7 @@ func (r *PostgresSupplierRepository) GetByID(ctx context.Context, id int) (*supp
         &supplier.Address,
         &supplier.TaxID,
         &supplier.PaymentTerms,
+        &supplier.LeadTimeDays,
         &supplier.Notes,
         &supplier.CreatedAt,
         &supplier.UpdatedAt,
@@ -118,8 +122,8 @@ func (r *PostgresSupplierRepository) Create(ctx context.Context, supplier *suppl
     query := `
         INSERT INTO suppliers (
             name, contact_person, phone, email, address,
-            tax_id, payment_terms, notes
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            tax_id, payment_terms, lead_time_days, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
         RETURNING supplier_id
     `
 
@@ -133,6 +137,7 @@ func (r *PostgresSupplierRepository) Create(ctx context.Context, supplier *suppl
         supplier.Address,
         supplier.TaxID,
         supplier.PaymentTerms,
+        supplier.LeadTimeDays,
         supplier.Notes,
     ).Scan(&id)
 
@@ -153,7 +158,8 @@ func (r *PostgresSupplierRepository) Update(ctx context.Context, supplier *suppl
             address = $6,
             tax_id = $7,
             payment_terms = $8,
-            notes = $9
+            lead_time_days = $9,
+            notes = $10
         WHERE supplier_id = $1
     `
 
@@ -167,6 +173,7 @@ func (r *PostgresSupplierRepository) Update(ctx context.Context, supplier *suppl
         supplier.Address,
         supplier.TaxID,
         supplier.PaymentTerms,
+        supplier.LeadTimeDays,
         supplier.Notes,
     )
 
@@ -11,18 +11,39 @@ import (
 func RegisterRoutes(api *echo.Group, database *db.Database) {
     // Initialize repository
     repo := repositories.NewPostgresSupplierRepository(database)
+    payablesRepo := repositories.NewPostgresPayablesRepository(database)
+    mergeRepo := repositories.NewPostgresMergeRepository(database)
 
     // Initialize service
     service := services.NewSupplierService(repo)
+    payablesService := services.NewPayablesService(payablesRepo, repo)
+    mergeService := services.NewMergeService(mergeRepo, repo)
 
     // Initialize handler
     handler := handlers.NewSupplierHandler(service)
+    payablesHandler := handlers.NewPayablesHandler(payablesService)
+    mergeHandler := handlers.NewMergeHandler(mergeService)
 
     // Register routes
     suppliers := api.Group("/suppliers")
     suppliers.GET("", handler.GetSuppliers)
+    suppliers.GET("/duplicates", mergeHandler.GetDuplicateSuppliers)
+    suppliers.GET("/merges", mergeHandler.GetSupplierMerges)
     suppliers.GET("/:id", handler.GetSupplierByID)
     suppliers.POST("", handler.CreateSupplier)
     suppliers.PUT("/:id", handler.UpdateSupplier)
     suppliers.DELETE("/:id", handler.DeleteSupplier)
+    suppliers.GET("/:supplierId/payables", payablesHandler.GetSupplierPayables)
+    suppliers.POST("/:id/merge", mergeHandler.MergeSuppliers)
+
+    // Accounts payable
+    invoices := api.Group("/supplier-invoices")
+    invoices.GET("", payablesHandler.GetSupplierInvoices)
+    invoices.GET("/:id", payablesHandler.GetSupplierInvoiceByID)
+    invoices.POST("", payablesHandler.CreateSupplierInvoice)
+    invoices.POST("/:id/void", payablesHandler.VoidSupplierInvoice)
+    invoices.POST("/:id/payments", payablesHandler.RecordSupplierPayment)
+
+    api.GET("/payables/aging", payablesHandler.GetPayablesAging)
+    api.GET("/payables/this-week", payablesHandler.GetWeeklyPayables)
 }
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+	"github.com/hsrvms/autoparts/internal/modules/suppliers/repositories"
+)
+
+var (
+	ErrInvalidMergeThreshold  = errors.New("threshold must be greater than 0 and at most 1")
+	ErrInvalidDuplicateID     = errors.New("invalid duplicate supplier ID")
+	ErrMergeIntoSelf          = errors.New("a supplier cannot be merged into itself")
+	ErrMergedSupplierNotFound = errors.New("duplicate supplier not found")
+	ErrSharedInvoiceNumbers   = errors.New("both suppliers have invoices with the same number; correct them before merging")
+)
+
+// Similarity two supplier names need to be reported as duplicates when no
+// threshold is given
+const defaultDuplicateThreshold = 0.6
+
+type MergeService interface {
+	GetDuplicates(ctx context.Context, threshold *float64) ([]*suppliermodels.DuplicateSupplier, error)
+	MergeSuppliers(ctx context.Context, survivorID int, request *suppliermodels.MergeRequest) (*suppliermodels.MergeRecord, error)
+	GetMergeHistory(ctx context.Context, supplierID *int) ([]*suppliermodels.MergeRecord, error)
+}
+
+type mergeService struct {
+	repo      repositories.MergeRepository
+	suppliers repositories.SupplierRepository
+}
+
+func NewMergeService(repo repositories.MergeRepository, suppliers repositories.SupplierRepository) MergeService {
+	return &mergeService{
+		repo:      repo,
+		suppliers: suppliers,
+	}
+}
+
+func (s *mergeService) GetDuplicates(ctx context.Context, threshold *float64) ([]*suppliermodels.DuplicateSupplier, error) {
+	t := defaultDuplicateThreshold
+	if threshold != nil {
+		t = *threshold
+	}
+	if t <= 0 || t > 1 {
+		return nil, ErrInvalidMergeThreshold
+	}
+
+	duplicates, err := s.repo.GetDuplicateSuppliers(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	if duplicates == nil {
+		duplicates = []*suppliermodels.DuplicateSupplier{}
+	}
+	return duplicates, nil
+}
+
+// MergeSuppliers merges the duplicate supplier into the survivor. Invoice
+// numbers are unique per supplier, so the merge is refused while both have
+// recorded the same one.
+func (s *mergeService) MergeSuppliers(ctx context.Context, survivorID int, request *suppliermodels.MergeRequest) (*suppliermodels.MergeRecord, error) {
+	if survivorID <= 0 {
+		return nil, ErrInvalidSupplierID
+	}
+	if request.DuplicateID <= 0 {
+		return nil, ErrInvalidDuplicateID
+	}
+	if request.DuplicateID == survivorID {
+		return nil, ErrMergeIntoSelf
+	}
+
+	survivor, err := s.suppliers.GetByID(ctx, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	if survivor == nil {
+		return nil, ErrSupplierNotFound
+	}
+	duplicate, err := s.suppliers.GetByID(ctx, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate == nil {
+		return nil, ErrMergedSupplierNotFound
+	}
+
+	shared, err := s.repo.GetSharedInvoiceNumbers(ctx, survivorID, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if len(shared) > 0 {
+		return nil, ErrSharedInvoiceNumbers
+	}
+
+	return s.repo.MergeSuppliers(ctx, survivorID, request)
+}
+
+func (s *mergeService) GetMergeHistory(ctx context.Context, supplierID *int) ([]*suppliermodels.MergeRecord, error) {
+	records, err := s.repo.GetMergeHistory(ctx, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*suppliermodels.MergeRecord{}
+	}
+	return records, nil
+}
@@ -0,0 +1,463 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+	"github.com/hsrvms/autoparts/internal/modules/suppliers/repositories"
+)
+
+var (
+	ErrInvalidSupplierInvoiceID   = errors.New("invalid supplier invoice ID")
+	ErrSupplierInvoiceNotFound    = errors.New("supplier invoice not found")
+	ErrInvoiceNumberRequired      = errors.New("supplier invoice number is required")
+	ErrDuplicateInvoiceNumber     = errors.New("invoice number already recorded for this supplier")
+	ErrNoPurchasesToInvoice       = errors.New("at least one purchase is required")
+	ErrDuplicatePurchaseOnInvoice = errors.New("purchase is listed more than once")
+	ErrPurchaseNotAvailable       = errors.New("purchase not found for this supplier or already invoiced")
+	ErrInvalidInvoiceTotal        = errors.New("invoice total must be greater than 0")
+	ErrInvalidDueDate             = errors.New("due date cannot be before the invoice date")
+	ErrSupplierInvoiceVoid        = errors.New("supplier invoice has been voided")
+	ErrSupplierInvoicePaid        = errors.New("supplier invoice is already paid")
+	ErrSupplierInvoiceHasPayments = errors.New("supplier invoice has payments and cannot be voided")
+	ErrInvalidPaymentAmount       = errors.New("payment amount must be greater than 0")
+	ErrInvalidPaymentMethod       = errors.New("payment method must be cash, card, bank_transfer or cheque")
+	ErrPaymentExceedsBalance      = errors.New("payment exceeds the invoice balance")
+)
+
+type PayablesService interface {
+	GetInvoices(ctx context.Context, filter *suppliermodels.SupplierInvoiceFilter) ([]*suppliermodels.SupplierInvoice, error)
+	GetInvoiceByID(ctx context.Context, id int) (*suppliermodels.SupplierInvoice, error)
+	CreateInvoice(ctx context.Context, req *suppliermodels.CreateSupplierInvoiceRequest) (*suppliermodels.SupplierInvoice, error)
+	VoidInvoice(ctx context.Context, id int) error
+	RecordPayment(ctx context.Context, payment *suppliermodels.SupplierPayment) (*suppliermodels.SupplierInvoice, error)
+
+	GetAgingReport(ctx context.Context, asOf time.Time) (*suppliermodels.PayablesAgingReport, error)
+	GetWeeklyPayables(ctx context.Context, date time.Time) (*suppliermodels.WeeklyPayablesReport, error)
+	GetSupplierPayables(ctx context.Context, supplierID int, date time.Time) (*suppliermodels.SupplierPayables, error)
+}
+
+type payablesService struct {
+	repo         repositories.PayablesRepository
+	supplierRepo repositories.SupplierRepository
+}
+
+func NewPayablesService(repo repositories.PayablesRepository, supplierRepo repositories.SupplierRepository) PayablesService {
+	return &payablesService{
+		repo:         repo,
+		supplierRepo: supplierRepo,
+	}
+}
+
+func (s *payablesService) GetInvoices(ctx context.Context, filter *suppliermodels.SupplierInvoiceFilter) ([]*suppliermodels.SupplierInvoice, error) {
+	return s.repo.GetInvoices(ctx, filter)
+}
+
+func (s *payablesService) GetInvoiceByID(ctx context.Context, id int) (*suppliermodels.SupplierInvoice, error) {
+	if id <= 0 {
+		return nil, ErrInvalidSupplierInvoiceID
+	}
+
+	invoice, err := s.repo.GetInvoiceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrSupplierInvoiceNotFound
+	}
+
+	if invoice.Purchases, err = s.repo.GetInvoicePurchases(ctx, id); err != nil {
+		return nil, err
+	}
+	if invoice.Payments, err = s.repo.GetInvoicePayments(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (s *payablesService) CreateInvoice(ctx context.Context, req *suppliermodels.CreateSupplierInvoiceRequest) (*suppliermodels.SupplierInvoice, error) {
+	if req.SupplierID <= 0 {
+		return nil, ErrInvalidSupplierID
+	}
+	if req.InvoiceNumber == "" {
+		return nil, ErrInvoiceNumberRequired
+	}
+	if len(req.PurchaseIDs) == 0 {
+		return nil, ErrNoPurchasesToInvoice
+	}
+
+	seen := make(map[int]bool, len(req.PurchaseIDs))
+	for _, id := range req.PurchaseIDs {
+		if seen[id] {
+			return nil, ErrDuplicatePurchaseOnInvoice
+		}
+		seen[id] = true
+	}
+
+	supplier, err := s.supplierRepo.GetByID(ctx, req.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, ErrSupplierNotFound
+	}
+
+	existing, err := s.repo.GetInvoiceByNumber(ctx, req.SupplierID, req.InvoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrDuplicateInvoiceNumber
+	}
+
+	purchases, err := s.repo.GetUninvoicedPurchases(ctx, req.SupplierID, req.PurchaseIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(purchases) != len(req.PurchaseIDs) {
+		return nil, ErrPurchaseNotAvailable
+	}
+
+	var total float64
+	for _, purchase := range purchases {
+		total += purchase.TotalCost
+	}
+	if req.TotalAmount != nil {
+		total = *req.TotalAmount
+	}
+	total = roundCents(total)
+	if total <= 0 {
+		return nil, ErrInvalidInvoiceTotal
+	}
+
+	invoiceDate := truncateToDay(time.Now())
+	if req.InvoiceDate != nil {
+		invoiceDate = truncateToDay(*req.InvoiceDate)
+	}
+
+	invoice := &suppliermodels.SupplierInvoice{
+		SupplierID:    req.SupplierID,
+		InvoiceNumber: req.InvoiceNumber,
+		InvoiceDate:   invoiceDate,
+		TotalAmount:   total,
+		Notes:         req.Notes,
+	}
+
+	if req.DueDate != nil {
+		invoice.DueDate = truncateToDay(*req.DueDate)
+		if invoice.DueDate.Before(invoiceDate) {
+			return nil, ErrInvalidDueDate
+		}
+	} else {
+		terms, err := s.supplierTerms(supplier.PaymentTerms)
+		if err != nil {
+			return nil, err
+		}
+		invoice.DueDate = dueDate(terms, invoiceDate)
+		invoice.DiscountDate = discountDate(terms, invoiceDate)
+		if invoice.DiscountDate != nil {
+			invoice.DiscountPercent = &terms.DiscountPercent
+		}
+	}
+
+	id, err := s.repo.CreateInvoice(ctx, invoice, req.PurchaseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetInvoiceByID(ctx, id)
+}
+
+func (s *payablesService) VoidInvoice(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidSupplierInvoiceID
+	}
+
+	invoice, err := s.repo.GetInvoiceByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if invoice == nil {
+		return ErrSupplierInvoiceNotFound
+	}
+	if invoice.Status == suppliermodels.InvoiceStatusVoid {
+		return ErrSupplierInvoiceVoid
+	}
+	if invoice.AmountPaid > 0 {
+		return ErrSupplierInvoiceHasPayments
+	}
+
+	return s.repo.VoidInvoice(ctx, id)
+}
+
+// RecordPayment applies a full or partial payment to a supplier invoice. A
+// payment made by the discount date that settles the invoice net of the
+// early payment discount takes that discount automatically.
+func (s *payablesService) RecordPayment(ctx context.Context, payment *suppliermodels.SupplierPayment) (*suppliermodels.SupplierInvoice, error) {
+	if payment.SupplierInvoiceID <= 0 {
+		return nil, ErrInvalidSupplierInvoiceID
+	}
+	if payment.Amount <= 0 {
+		return nil, ErrInvalidPaymentAmount
+	}
+	if !isValidSupplierPaymentMethod(payment.Method) {
+		return nil, ErrInvalidPaymentMethod
+	}
+
+	invoice, err := s.repo.GetInvoiceByID(ctx, payment.SupplierInvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrSupplierInvoiceNotFound
+	}
+	switch invoice.Status {
+	case suppliermodels.InvoiceStatusVoid:
+		return nil, ErrSupplierInvoiceVoid
+	case suppliermodels.InvoiceStatusPaid:
+		return nil, ErrSupplierInvoicePaid
+	}
+
+	if payment.PaymentDate.IsZero() {
+		payment.PaymentDate = time.Now()
+	}
+	payment.Amount = roundCents(payment.Amount)
+	payment.DiscountTaken = earlyPaymentDiscount(invoice, payment)
+
+	if payment.Amount+payment.DiscountTaken > roundCents(invoice.BalanceDue) {
+		return nil, ErrPaymentExceedsBalance
+	}
+
+	if _, err := s.repo.CreatePayment(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	return s.GetInvoiceByID(ctx, payment.SupplierInvoiceID)
+}
+
+// GetAgingReport buckets what was owed to each supplier on asOf by days
+// past due.
+func (s *payablesService) GetAgingReport(ctx context.Context, asOf time.Time) (*suppliermodels.PayablesAgingReport, error) {
+	asOf = truncateToDay(asOf)
+
+	invoices, err := s.repo.GetOpenInvoicesAsOf(ctx, nil, asOf.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &suppliermodels.PayablesAgingReport{
+		AsOf:      asOf,
+		Suppliers: []*suppliermodels.SupplierAging{},
+	}
+
+	var current *suppliermodels.SupplierAging
+	for _, invoice := range invoices {
+		if current == nil || current.SupplierID != invoice.SupplierID {
+			current = &suppliermodels.SupplierAging{
+				SupplierID:   invoice.SupplierID,
+				SupplierName: invoice.SupplierName,
+			}
+			report.Suppliers = append(report.Suppliers, current)
+		}
+		addToPayablesBucket(&current.PayablesAgingBuckets, invoice, asOf)
+		addToPayablesBucket(&report.Totals, invoice, asOf)
+	}
+
+	return report, nil
+}
+
+// GetWeeklyPayables lists, per supplier, everything that has to be paid by
+// the end of the Monday-Sunday week containing date.
+func (s *payablesService) GetWeeklyPayables(ctx context.Context, date time.Time) (*suppliermodels.WeeklyPayablesReport, error) {
+	weekStart, weekEnd := weekBounds(date)
+
+	openOnly := true
+	invoices, err := s.repo.GetInvoices(ctx, &suppliermodels.SupplierInvoiceFilter{
+		DueBefore: &weekEnd,
+		OpenOnly:  &openOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &suppliermodels.WeeklyPayablesReport{
+		WeekStart: weekStart,
+		WeekEnd:   weekEnd,
+		Suppliers: []*suppliermodels.SupplierPayables{},
+	}
+
+	bySupplier := make(map[int]*suppliermodels.SupplierPayables)
+	for _, invoice := range invoices {
+		payables, ok := bySupplier[invoice.SupplierID]
+		if !ok {
+			payables = &suppliermodels.SupplierPayables{
+				SupplierID:   invoice.SupplierID,
+				SupplierName: invoice.SupplierName,
+				Invoices:     []*suppliermodels.SupplierInvoice{},
+			}
+			bySupplier[invoice.SupplierID] = payables
+			report.Suppliers = append(report.Suppliers, payables)
+		}
+		addWeeklyInvoice(payables, invoice, date, weekEnd)
+	}
+
+	for _, payables := range report.Suppliers {
+		supplier, err := s.supplierRepo.GetByID(ctx, payables.SupplierID)
+		if err != nil {
+			return nil, err
+		}
+		if supplier != nil {
+			payables.PaymentTerms, _ = ParsePaymentTerms(stringValue(supplier.PaymentTerms))
+		}
+		report.TotalDue = roundCents(report.TotalDue + payables.TotalDue)
+	}
+
+	return report, nil
+}
+
+func (s *payablesService) GetSupplierPayables(ctx context.Context, supplierID int, date time.Time) (*suppliermodels.SupplierPayables, error) {
+	if supplierID <= 0 {
+		return nil, ErrInvalidSupplierID
+	}
+
+	supplier, err := s.supplierRepo.GetByID(ctx, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, ErrSupplierNotFound
+	}
+
+	_, weekEnd := weekBounds(date)
+
+	openOnly := true
+	invoices, err := s.repo.GetInvoices(ctx, &suppliermodels.SupplierInvoiceFilter{
+		SupplierID: &supplierID,
+		DueBefore:  &weekEnd,
+		OpenOnly:   &openOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payables := &suppliermodels.SupplierPayables{
+		SupplierID:   supplier.SupplierID,
+		SupplierName: supplier.Name,
+		Invoices:     []*suppliermodels.SupplierInvoice{},
+	}
+	payables.PaymentTerms, _ = ParsePaymentTerms(stringValue(supplier.PaymentTerms))
+
+	for _, invoice := range invoices {
+		addWeeklyInvoice(payables, invoice, date, weekEnd)
+	}
+
+	return payables, nil
+}
+
+// Helper functions
+
+// supplierTerms parses the supplier's payment terms, falling back to the
+// default when none are on file.
+func (s *payablesService) supplierTerms(raw *string) (*suppliermodels.PaymentTerms, error) {
+	terms, err := ParsePaymentTerms(stringValue(raw))
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil {
+		terms = &suppliermodels.PaymentTerms{NetDays: DefaultNetDays}
+	}
+	return terms, nil
+}
+
+func earlyPaymentDiscount(invoice *suppliermodels.SupplierInvoice, payment *suppliermodels.SupplierPayment) float64 {
+	if invoice.DiscountDate == nil || invoice.DiscountPercent == nil {
+		return 0
+	}
+	if truncateToDay(payment.PaymentDate).After(*invoice.DiscountDate) {
+		return 0
+	}
+
+	discount := discountAmount(invoice)
+	balance := roundCents(invoice.BalanceDue)
+	if payment.Amount < balance-discount {
+		return 0
+	}
+
+	return math.Max(0, roundCents(balance-payment.Amount))
+}
+
+func discountAmount(invoice *suppliermodels.SupplierInvoice) float64 {
+	if invoice.DiscountPercent == nil {
+		return 0
+	}
+	return roundCents(invoice.TotalAmount * (*invoice.DiscountPercent) / 100)
+}
+
+func addWeeklyInvoice(payables *suppliermodels.SupplierPayables, invoice *suppliermodels.SupplierInvoice, date, weekEnd time.Time) {
+	today := truncateToDay(date)
+
+	if invoice.DueDate.Before(today) {
+		payables.Overdue = roundCents(payables.Overdue + invoice.BalanceDue)
+	} else {
+		payables.DueThisWeek = roundCents(payables.DueThisWeek + invoice.BalanceDue)
+	}
+	payables.TotalDue = roundCents(payables.TotalDue + invoice.BalanceDue)
+
+	if invoice.DiscountDate != nil && invoice.DiscountPercent != nil &&
+		!invoice.DiscountDate.Before(today) && !invoice.DiscountDate.After(weekEnd) {
+		discount := math.Min(invoice.BalanceDue, discountAmount(invoice))
+		payables.DiscountAvailable = roundCents(payables.DiscountAvailable + discount)
+	}
+
+	payables.Invoices = append(payables.Invoices, invoice)
+}
+
+func addToPayablesBucket(buckets *suppliermodels.PayablesAgingBuckets, invoice *suppliermodels.SupplierInvoice, asOf time.Time) {
+	daysPastDue := int(asOf.Sub(truncateToDay(invoice.DueDate)).Hours() / 24)
+	balance := invoice.BalanceDue
+
+	switch {
+	case daysPastDue <= 0:
+		buckets.Current = roundCents(buckets.Current + balance)
+	case daysPastDue <= 30:
+		buckets.Days1To30 = roundCents(buckets.Days1To30 + balance)
+	case daysPastDue <= 60:
+		buckets.Days31To60 = roundCents(buckets.Days31To60 + balance)
+	case daysPastDue <= 90:
+		buckets.Days61To90 = roundCents(buckets.Days61To90 + balance)
+	default:
+		buckets.Over90 = roundCents(buckets.Over90 + balance)
+	}
+	buckets.Total = roundCents(buckets.Total + balance)
+}
+
+// weekBounds returns the Monday and Sunday of the week containing date
+func weekBounds(date time.Time) (time.Time, time.Time) {
+	day := truncateToDay(date)
+	offset := (int(day.Weekday()) + 6) % 7
+	start := day.AddDate(0, 0, -offset)
+	return start, start.AddDate(0, 0, 6)
+}
+
+func isValidSupplierPaymentMethod(method string) bool {
+	switch method {
+	case "cash", "card", "bank_transfer", "cheque":
+		return true
+	}
+	return false
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+)
+
+var ErrInvalidPaymentTerms = errors.New(`unrecognised payment terms, expected e.g. "Net 30", "COD", "2/10 Net 30" or "Net 30 EOM"`)
+
+// DefaultNetDays applies to suppliers that have no payment terms on file
+const DefaultNetDays = 30
+
+var (
+	immediateTerms = map[string]bool{
+		"COD":              true,
+		"CIA":              true,
+		"CASH":             true,
+		"CASH ON DELIVERY": true,
+		"CASH IN ADVANCE":  true,
+		"DUE ON RECEIPT":   true,
+		"RECEIPT":          true,
+		"IMMEDIATE":        true,
+		"PREPAID":          true,
+	}
+
+	discountPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(?:%\s*/?|/)\s*(\d+)[\s,]*(.*)$`)
+	netPattern      = regexp.MustCompile(`^(?:NET|N)\s*/?\s*(\d+)(\s*EOM)?$`)
+	eomPattern      = regexp.MustCompile(`^(?:(?:NET\s*)?(\d+)\s*EOM|(?:NET\s*)?EOM(?:\s*\+?\s*(\d+))?)$`)
+	daysPattern     = regexp.MustCompile(`^(\d+)$`)
+	dayWordPattern  = regexp.MustCompile(`\bDAYS?\b`)
+)
+
+// ParsePaymentTerms interprets a free-text payment terms string. Empty terms
+// return nil without error.
+//
+// Supported forms:
+//
+//	COD, CIA, Due on receipt     payable immediately
+//	Net 30, N30, 30 days         due 30 days after the invoice date
+//	EOM, Net EOM                 due at the end of the invoice month
+//	Net 30 EOM, 30 EOM, EOM 30   due 30 days after the end of the invoice month
+//	2/10 Net 30, 2% 10 Net 30    2% discount if paid within 10 days, else Net 30
+func ParsePaymentTerms(terms string) (*suppliermodels.PaymentTerms, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(terms))
+	if normalized == "" {
+		return nil, nil
+	}
+	normalized = dayWordPattern.ReplaceAllString(normalized, "")
+	normalized = strings.Join(strings.Fields(normalized), " ")
+
+	parsed := &suppliermodels.PaymentTerms{Raw: terms}
+
+	if immediateTerms[normalized] {
+		return parsed, nil
+	}
+
+	if m := discountPattern.FindStringSubmatch(normalized); m != nil {
+		percent, _ := strconv.ParseFloat(m[1], 64)
+		days, _ := strconv.Atoi(m[2])
+		if percent <= 0 || percent >= 100 {
+			return nil, ErrInvalidPaymentTerms
+		}
+		parsed.DiscountPercent = percent
+		parsed.DiscountDays = days
+		normalized = strings.TrimSpace(m[3])
+	}
+
+	switch {
+	case netPattern.MatchString(normalized):
+		m := netPattern.FindStringSubmatch(normalized)
+		parsed.NetDays, _ = strconv.Atoi(m[1])
+		parsed.EndOfMonth = m[2] != ""
+	case eomPattern.MatchString(normalized):
+		m := eomPattern.FindStringSubmatch(normalized)
+		parsed.EndOfMonth = true
+		if m[1] != "" {
+			parsed.NetDays, _ = strconv.Atoi(m[1])
+		} else if m[2] != "" {
+			parsed.NetDays, _ = strconv.Atoi(m[2])
+		}
+	case daysPattern.MatchString(normalized):
+		parsed.NetDays, _ = strconv.Atoi(normalized)
+	default:
+		return nil, ErrInvalidPaymentTerms
+	}
+
+	if parsed.DiscountDays > parsed.NetDays && !parsed.EndOfMonth {
+		return nil, ErrInvalidPaymentTerms
+	}
+
+	return parsed, nil
+}
+
+// dueDate returns when an invoice dated invoiceDate must be paid in full
+func dueDate(terms *suppliermodels.PaymentTerms, invoiceDate time.Time) time.Time {
+	date := truncateToDay(invoiceDate)
+	if terms == nil {
+		return date.AddDate(0, 0, DefaultNetDays)
+	}
+
+	if terms.EndOfMonth {
+		// First day of the next month minus one day is the month end
+		date = time.Date(date.Year(), date.Month()+1, 1, 0, 0, 0, 0, date.Location()).AddDate(0, 0, -1)
+	}
+
+	return date.AddDate(0, 0, terms.NetDays)
+}
+
+// discountDate returns the last day an early payment discount applies, or
+// nil if the terms offer none.
+func discountDate(terms *suppliermodels.PaymentTerms, invoiceDate time.Time) *time.Time {
+	if terms == nil || terms.DiscountPercent <= 0 {
+		return nil
+	}
+
+	date := truncateToDay(invoiceDate).AddDate(0, 0, terms.DiscountDays)
+	return &date
+}
+
+func truncateToDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -13,8 +13,12 @@ var (
 	ErrInvalidSupplierID     = errors.New("invalid supplier ID")
 	ErrDuplicateSupplierName = errors.New("supplier name already exists")
 	ErrSupplierHasItems      = errors.New("cannot delete supplier with associated items")
+	ErrInvalidLeadTime       = errors.New("lead time cannot be negative")
 )
 
+// DefaultLeadTimeDays is assumed for new suppliers without a lead time
+const DefaultLeadTimeDays = 7
+
 type SupplierService interface {
 	GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter) ([]*suppliermodels.Supplier, error)
 	GetByID(ctx context.Context, id int) (*suppliermodels.Supplier, error)
@@ -72,6 +76,11 @@ func (s *supplierService) Create(ctx context.Context, supplier *suppliermodels.S
 		}
 	}
 
+	if supplier.LeadTimeDays == nil {
+		leadTime := DefaultLeadTimeDays
+		supplier.LeadTimeDays = &leadTime
+	}
+
 	return s.repo.Create(ctx, supplier)
 }
 
@@ -93,6 +102,10 @@ func (s *supplierService) Update(ctx context.Context, supplier *suppliermodels.S
 		return ErrSupplierNotFound
 	}
 
+	if supplier.LeadTimeDays == nil {
+		supplier.LeadTimeDays = existing.LeadTimeDays
+	}
+
 	// Check for name uniqueness if name is being changed
 	if existing.Name != supplier.Name {
 		suppliers, err := s.repo.GetAll(ctx, &suppliermodels.SupplierFilter{
@@ -144,6 +157,14 @@ func (s *supplierService) validateSupplier(supplier *suppliermodels.Supplier) er
 	if supplier.Name == "" {
 		return errors.New("supplier name is required")
 	}
+	if supplier.LeadTimeDays != nil && *supplier.LeadTimeDays < 0 {
+		return ErrInvalidLeadTime
+	}
+	if supplier.PaymentTerms != nil {
+		if _, err := ParsePaymentTerms(*supplier.PaymentTerms); err != nil {
+			return err
+		}
+	}
 	// Add additional validations as needed
 	return nil
 }
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	valuationmodels "github.com/hsrvms/autoparts/internal/modules/valuation/models"
+	"github.com/hsrvms/autoparts/internal/modules/valuation/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ValuationHandler struct {
+	service services.ValuationService
+}
+
+func NewValuationHandler(service services.ValuationService) *ValuationHandler {
+	return &ValuationHandler{
+		service: service,
+	}
+}
+
+// GetValuation handles the inventory valuation report as of a date
+func (h *ValuationHandler) GetValuation(c echo.Context) error {
+	filter := &valuationmodels.ValuationFilter{
+		Method:      c.QueryParam("method"),
+		IncludeZero: c.QueryParam("include_zero") == "true",
+	}
+
+	if asOf := c.QueryParam("as_of"); asOf != "" {
+		date, err := parseDate(asOf)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid as_of date")
+		}
+		// A bare date means the end of that day
+		if len(asOf) == len("2006-01-02") {
+			date = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		filter.AsOf = &date
+	}
+
+	if categoryID := c.QueryParam("category_id"); categoryID != "" {
+		if id, err := strconv.Atoi(categoryID); err == nil {
+			filter.CategoryID = &id
+		}
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetValuation(ctx, filter)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidMethod, services.ErrFutureValuationDate:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetItemCostLayers handles retrieval of an item's cost layers
+func (h *ValuationHandler) GetItemCostLayers(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	openOnly := c.QueryParam("open_only") != "false"
+
+	ctx := c.Request().Context()
+	cost, err := h.service.GetItemCost(ctx, id, openOnly)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidItemID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, cost)
+}
+
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,65 @@
+package valuationmodels
+
+import "time"
+
+// Costing methods
+const (
+	MethodFIFO    = "fifo"
+	MethodAverage = "average"
+)
+
+type ValuationFilter struct {
+	AsOf        *time.Time `query:"as_of"`
+	Method      string     `query:"method"`
+	CategoryID  *int       `query:"category_id"`
+	IncludeZero bool       `query:"include_zero"`
+}
+
+type ItemValuation struct {
+	ItemID       int     `json:"item_id" db:"item_id"`
+	PartNumber   string  `json:"part_number" db:"part_number"`
+	Description  string  `json:"description" db:"description"`
+	CategoryID   *int    `json:"category_id,omitempty" db:"category_id"`
+	CategoryName *string `json:"category_name,omitempty" db:"category_name"`
+	Quantity     int     `json:"quantity" db:"quantity"`
+	UnitCost     float64 `json:"unit_cost" db:"-"`
+	Value        float64 `json:"value" db:"value"`
+}
+
+type CategoryValuation struct {
+	CategoryID   *int    `json:"category_id,omitempty"`
+	CategoryName string  `json:"category_name"`
+	Quantity     int     `json:"quantity"`
+	Value        float64 `json:"value"`
+}
+
+type ValuationReport struct {
+	AsOf          time.Time            `json:"as_of"`
+	Method        string               `json:"method"`
+	Items         []*ItemValuation     `json:"items"`
+	Categories    []*CategoryValuation `json:"categories"`
+	TotalQuantity int                  `json:"total_quantity"`
+	TotalValue    float64              `json:"total_value"`
+}
+
+type CostLayer struct {
+	LayerID           int       `json:"layer_id" db:"layer_id"`
+	ItemID            int       `json:"item_id" db:"item_id"`
+	PurchaseID        *int      `json:"purchase_id,omitempty" db:"purchase_id"`
+	Source            string    `json:"source" db:"source"`
+	ReceivedAt        time.Time `json:"received_at" db:"received_at"`
+	Quantity          int       `json:"quantity" db:"quantity"`
+	RemainingQuantity int       `json:"remaining_quantity" db:"remaining_quantity"`
+	UnitCost          float64   `json:"unit_cost" db:"unit_cost"`
+	RemainingValue    float64   `json:"remaining_value" db:"-"`
+}
+
+// ItemCost shows an item's current cost position under both methods
+type ItemCost struct {
+	ItemID       int          `json:"item_id"`
+	CurrentStock int          `json:"current_stock"`
+	AverageCost  *float64     `json:"average_cost,omitempty"`
+	FIFOValue    float64      `json:"fifo_value"`
+	AverageValue float64      `json:"average_value"`
+	Layers       []*CostLayer `json:"layers"`
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	valuationmodels "github.com/hsrvms/autoparts/internal/modules/valuation/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresValuationRepository struct {
+	db *db.Database
+}
+
+func NewPostgresValuationRepository(database *db.Database) ValuationRepository {
+	return &PostgresValuationRepository{
+		db: database,
+	}
+}
+
+// GetFIFOValuation values stock as the unconsumed part of each cost layer at
+// its own cost. Layers and consumptions after asOf are ignored, so the result
+// is the position as it stood at that moment.
+func (r *PostgresValuationRepository) GetFIFOValuation(ctx context.Context, asOf time.Time, categoryID *int) ([]*valuationmodels.ItemValuation, error) {
+	query := `
+		SELECT
+			i.item_id, i.part_number, i.description, i.category_id, c.category_name,
+			COALESCE(SUM(l.on_hand), 0)::int as quantity,
+			COALESCE(SUM(l.on_hand * l.unit_cost), 0) as value
+		FROM items i
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		LEFT JOIN (
+			SELECT
+				cl.item_id, cl.unit_cost,
+				GREATEST(cl.quantity - COALESCE((
+					SELECT SUM(cc.quantity)
+					FROM cost_layer_consumptions cc
+					WHERE cc.layer_id = cl.layer_id AND cc.consumed_at <= $1
+				), 0), 0) as on_hand
+			FROM cost_layers cl
+			WHERE cl.received_at <= $1
+		) l ON l.item_id = i.item_id
+		WHERE i.created_at <= $1
+		AND ($2::int IS NULL OR i.category_id = $2)
+		GROUP BY i.item_id, c.category_name
+		ORDER BY i.part_number
+	`
+
+	return r.queryValuation(ctx, query, asOf, categoryID)
+}
+
+// GetAverageValuation values stock at the weighted-average cost recorded by
+// the last movement on or before asOf.
+func (r *PostgresValuationRepository) GetAverageValuation(ctx context.Context, asOf time.Time, categoryID *int) ([]*valuationmodels.ItemValuation, error) {
+	query := `
+		SELECT
+			i.item_id, i.part_number, i.description, i.category_id, c.category_name,
+			GREATEST(COALESCE(h.quantity_on_hand, 0), 0) as quantity,
+			GREATEST(COALESCE(h.quantity_on_hand, 0), 0) * COALESCE(h.average_cost, 0) as value
+		FROM items i
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		LEFT JOIN LATERAL (
+			SELECT quantity_on_hand, average_cost
+			FROM item_cost_history
+			WHERE item_id = i.item_id AND effective_at <= $1
+			ORDER BY effective_at DESC, history_id DESC
+			LIMIT 1
+		) h ON TRUE
+		WHERE i.created_at <= $1
+		AND ($2::int IS NULL OR i.category_id = $2)
+		ORDER BY i.part_number
+	`
+
+	return r.queryValuation(ctx, query, asOf, categoryID)
+}
+
+func (r *PostgresValuationRepository) queryValuation(ctx context.Context, query string, asOf time.Time, categoryID *int) ([]*valuationmodels.ItemValuation, error) {
+	rows, err := r.db.Pool.Query(ctx, query, asOf, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*valuationmodels.ItemValuation
+	for rows.Next() {
+		item := &valuationmodels.ItemValuation{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.Description,
+			&item.CategoryID,
+			&item.CategoryName,
+			&item.Quantity,
+			&item.Value,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *PostgresValuationRepository) GetItemCost(ctx context.Context, itemID int) (*valuationmodels.ItemCost, error) {
+	cost := &valuationmodels.ItemCost{ItemID: itemID}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT current_stock, average_cost
+		FROM items
+		WHERE item_id = $1
+	`, itemID).Scan(&cost.CurrentStock, &cost.AverageCost)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return cost, nil
+}
+
+func (r *PostgresValuationRepository) GetCostLayers(ctx context.Context, itemID int, openOnly bool) ([]*valuationmodels.CostLayer, error) {
+	query := `
+		SELECT
+			layer_id, item_id, purchase_id, source, received_at,
+			quantity, remaining_quantity, unit_cost
+		FROM cost_layers
+		WHERE item_id = $1
+	`
+
+	if openOnly {
+		query += " AND remaining_quantity > 0"
+	}
+
+	query += " ORDER BY received_at, layer_id"
+
+	rows, err := r.db.Pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var layers []*valuationmodels.CostLayer
+	for rows.Next() {
+		layer := &valuationmodels.CostLayer{}
+		err := rows.Scan(
+			&layer.LayerID,
+			&layer.ItemID,
+			&layer.PurchaseID,
+			&layer.Source,
+			&layer.ReceivedAt,
+			&layer.Quantity,
+			&layer.RemainingQuantity,
+			&layer.UnitCost,
+		)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	return layers, rows.Err()
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	valuationmodels "github.com/hsrvms/autoparts/internal/modules/valuation/models"
+)
+
+type ValuationRepository interface {
+	GetFIFOValuation(ctx context.Context, asOf time.Time, categoryID *int) ([]*valuationmodels.ItemValuation, error)
+	GetAverageValuation(ctx context.Context, asOf time.Time, categoryID *int) ([]*valuationmodels.ItemValuation, error)
+	GetItemCost(ctx context.Context, itemID int) (*valuationmodels.ItemCost, error)
+	GetCostLayers(ctx context.Context, itemID int, openOnly bool) ([]*valuationmodels.CostLayer, error)
+}
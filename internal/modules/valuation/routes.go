@@ -0,0 +1,25 @@
+package valuation
+
+import (
+	"github.com/hsrvms/autoparts/internal/modules/valuation/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/valuation/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/valuation/services"
+	"github.com/hsrvms/autoparts/pkg/config"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database, cfg config.InventoryConfig) {
+	// Initialize repository
+	repo := repositories.NewPostgresValuationRepository(database)
+
+	// Initialize service
+	service := services.NewValuationService(repo, cfg.CostingMethod)
+
+	// Initialize handler
+	handler := handlers.NewValuationHandler(service)
+
+	// Register routes
+	api.GET("/inventory/valuation", handler.GetValuation)
+	api.GET("/items/:id/cost-layers", handler.GetItemCostLayers)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	valuationmodels "github.com/hsrvms/autoparts/internal/modules/valuation/models"
+	"github.com/hsrvms/autoparts/internal/modules/valuation/repositories"
+)
+
+var (
+	ErrInvalidItemID       = errors.New("invalid item ID")
+	ErrItemNotFound        = errors.New("item not found")
+	ErrInvalidMethod       = errors.New("costing method must be 'fifo' or 'average'")
+	ErrFutureValuationDate = errors.New("valuation date cannot be in the future")
+)
+
+type ValuationService interface {
+	GetValuation(ctx context.Context, filter *valuationmodels.ValuationFilter) (*valuationmodels.ValuationReport, error)
+	GetItemCost(ctx context.Context, itemID int, openOnly bool) (*valuationmodels.ItemCost, error)
+}
+
+type valuationService struct {
+	repo          repositories.ValuationRepository
+	defaultMethod string
+}
+
+// NewValuationService creates the service. defaultMethod is used when a
+// request does not name a costing method; an unknown value falls back to FIFO.
+func NewValuationService(repo repositories.ValuationRepository, defaultMethod string) ValuationService {
+	if !validMethod(defaultMethod) {
+		defaultMethod = valuationmodels.MethodFIFO
+	}
+
+	return &valuationService{
+		repo:          repo,
+		defaultMethod: defaultMethod,
+	}
+}
+
+func (s *valuationService) GetValuation(ctx context.Context, filter *valuationmodels.ValuationFilter) (*valuationmodels.ValuationReport, error) {
+	if filter == nil {
+		filter = &valuationmodels.ValuationFilter{}
+	}
+
+	method := filter.Method
+	if method == "" {
+		method = s.defaultMethod
+	}
+	if !validMethod(method) {
+		return nil, ErrInvalidMethod
+	}
+
+	now := time.Now()
+	asOf := now
+	if filter.AsOf != nil {
+		if filter.AsOf.After(now) {
+			return nil, ErrFutureValuationDate
+		}
+		asOf = *filter.AsOf
+	}
+
+	var items []*valuationmodels.ItemValuation
+	var err error
+	if method == valuationmodels.MethodAverage {
+		items, err = s.repo.GetAverageValuation(ctx, asOf, filter.CategoryID)
+	} else {
+		items, err = s.repo.GetFIFOValuation(ctx, asOf, filter.CategoryID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &valuationmodels.ValuationReport{
+		AsOf:       asOf,
+		Method:     method,
+		Items:      []*valuationmodels.ItemValuation{},
+		Categories: []*valuationmodels.CategoryValuation{},
+	}
+
+	byCategory := make(map[string]*valuationmodels.CategoryValuation)
+	for _, item := range items {
+		if item.Quantity == 0 && !filter.IncludeZero {
+			continue
+		}
+
+		item.Value = roundCents(item.Value)
+		if item.Quantity > 0 {
+			item.UnitCost = math.Round(item.Value/float64(item.Quantity)*10000) / 10000
+		}
+		report.Items = append(report.Items, item)
+
+		name := "Uncategorized"
+		if item.CategoryName != nil {
+			name = *item.CategoryName
+		}
+		category, ok := byCategory[name]
+		if !ok {
+			category = &valuationmodels.CategoryValuation{
+				CategoryID:   item.CategoryID,
+				CategoryName: name,
+			}
+			byCategory[name] = category
+			report.Categories = append(report.Categories, category)
+		}
+		category.Quantity += item.Quantity
+		category.Value = roundCents(category.Value + item.Value)
+
+		report.TotalQuantity += item.Quantity
+		report.TotalValue = roundCents(report.TotalValue + item.Value)
+	}
+
+	sort.Slice(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Value > report.Categories[j].Value
+	})
+
+	return report, nil
+}
+
+// GetItemCost returns an item's cost layers along with its stock valued under
+// both methods.
+func (s *valuationService) GetItemCost(ctx context.Context, itemID int, openOnly bool) (*valuationmodels.ItemCost, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	cost, err := s.repo.GetItemCost(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if cost == nil {
+		return nil, ErrItemNotFound
+	}
+
+	layers, err := s.repo.GetCostLayers(ctx, itemID, openOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	cost.Layers = []*valuationmodels.CostLayer{}
+	for _, layer := range layers {
+		layer.RemainingValue = roundCents(float64(layer.RemainingQuantity) * layer.UnitCost)
+		cost.FIFOValue = roundCents(cost.FIFOValue + layer.RemainingValue)
+		cost.Layers = append(cost.Layers, layer)
+	}
+
+	if cost.AverageCost != nil && cost.CurrentStock > 0 {
+		cost.AverageValue = roundCents(float64(cost.CurrentStock) * *cost.AverageCost)
+	}
+
+	return cost, nil
+}
+
+// Helper functions
+
+func validMethod(method string) bool {
+	return method == valuationmodels.MethodFIFO || method == valuationmodels.MethodAverage
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/internal/modules/vehicles/services"
+	"github.com/labstack/echo/v4"
+)
+
+type CatalogueHandler struct {
+	service services.CatalogueService
+}
+
+func NewCatalogueHandler(service services.CatalogueService) *CatalogueHandler {
+	return &CatalogueHandler{
+		service: service,
+	}
+}
+
+// SearchVehicles handles free-text vehicle searches such as "golf 2017 diesel"
+func (h *CatalogueHandler) SearchVehicles(c echo.Context) error {
+	limit := 0
+	if l := c.QueryParam("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+	}
+
+	ctx := c.Request().Context()
+	search, err := h.service.Search(ctx, c.QueryParam("q"), limit)
+	if err != nil {
+		return catalogueError(err)
+	}
+
+	return c.JSON(http.StatusOK, search)
+}
+
+// GetCatalogueTree handles retrieval of the makes, models and submodels with
+// their compatible item counts
+func (h *CatalogueHandler) GetCatalogueTree(c echo.Context) error {
+	filter := &vehiclemodels.CatalogueTreeFilter{}
+
+	if makeID := c.QueryParam("make_id"); makeID != "" {
+		id, err := strconv.Atoi(makeID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid make ID")
+		}
+		filter.MakeID = &id
+	}
+
+	if year := c.QueryParam("year"); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, services.ErrInvalidTreeYear.Error())
+		}
+		filter.Year = &y
+	}
+
+	ctx := c.Request().Context()
+	tree, err := h.service.GetTree(ctx, filter)
+	if err != nil {
+		return catalogueError(err)
+	}
+
+	return c.JSON(http.StatusOK, tree)
+}
+
+func catalogueError(err error) error {
+	switch err {
+	case services.ErrEmptyVehicleSearch, services.ErrInvalidTreeYear:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
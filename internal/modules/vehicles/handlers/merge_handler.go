@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/internal/modules/vehicles/services"
+	"github.com/labstack/echo/v4"
+)
+
+type MergeHandler struct {
+	service services.MergeService
+}
+
+func NewMergeHandler(service services.MergeService) *MergeHandler {
+	return &MergeHandler{
+		service: service,
+	}
+}
+
+// GetDuplicates handles listing makes, models and submodels that look like
+// duplicates of one another
+func (h *MergeHandler) GetDuplicates(c echo.Context) error {
+	filter := &vehiclemodels.DuplicateFilter{}
+
+	if entityType := c.QueryParam("entity_type"); entityType != "" {
+		filter.EntityType = &entityType
+	}
+
+	if threshold := c.QueryParam("threshold"); threshold != "" {
+		t, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, services.ErrInvalidMergeThreshold.Error())
+		}
+		filter.Threshold = &t
+	}
+
+	ctx := c.Request().Context()
+	candidates, err := h.service.GetDuplicates(ctx, filter)
+	if err != nil {
+		return mergeError(err)
+	}
+
+	return c.JSON(http.StatusOK, candidates)
+}
+
+// MergeMakes handles merging a duplicate make into the make in the path
+func (h *MergeHandler) MergeMakes(c echo.Context) error {
+	return h.merge(c, "invalid make ID", h.service.MergeMakes)
+}
+
+// MergeModels handles merging a duplicate model into the model in the path
+func (h *MergeHandler) MergeModels(c echo.Context) error {
+	return h.merge(c, "invalid model ID", h.service.MergeModels)
+}
+
+// MergeSubmodels handles merging a duplicate submodel into the submodel in
+// the path
+func (h *MergeHandler) MergeSubmodels(c echo.Context) error {
+	return h.merge(c, "invalid submodel ID", h.service.MergeSubmodels)
+}
+
+// GetMergeHistory handles listing past merges of makes, models and submodels
+func (h *MergeHandler) GetMergeHistory(c echo.Context) error {
+	filter := &vehiclemodels.MergeHistoryFilter{}
+
+	if entityType := c.QueryParam("entity_type"); entityType != "" {
+		filter.EntityType = &entityType
+	}
+
+	if recordID, err := strconv.Atoi(c.QueryParam("record_id")); err == nil {
+		filter.RecordID = &recordID
+	}
+
+	ctx := c.Request().Context()
+	records, err := h.service.GetMergeHistory(ctx, filter)
+	if err != nil {
+		return mergeError(err)
+	}
+
+	return c.JSON(http.StatusOK, records)
+}
+
+func (h *MergeHandler) merge(
+	c echo.Context,
+	invalidID string,
+	merge func(context.Context, int, *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error),
+) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, invalidID)
+	}
+
+	request := new(vehiclemodels.MergeRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	record, err := merge(ctx, id, request)
+	if err != nil {
+		return mergeError(err)
+	}
+
+	return c.JSON(http.StatusOK, record)
+}
+
+func mergeError(err error) error {
+	switch err {
+	case services.ErrInvalidMergeEntity, services.ErrInvalidMergeThreshold, services.ErrInvalidDuplicateID:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrMakeNotFound, services.ErrModelNotFound, services.ErrSubmodelNotFound,
+		services.ErrDuplicateMakeNotFound, services.ErrDuplicateModelNotFound, services.ErrDuplicateSubmodelNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrMergeIntoSelf:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
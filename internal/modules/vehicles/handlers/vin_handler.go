@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/internal/modules/vehicles/services"
+	"github.com/labstack/echo/v4"
+)
+
+type VINHandler struct {
+	service services.VINService
+}
+
+func NewVINHandler(service services.VINService) *VINHandler {
+	return &VINHandler{
+		service: service,
+	}
+}
+
+// DecodeVIN handles decoding a VIN into its make, model year and submodels
+func (h *VINHandler) DecodeVIN(c echo.Context) error {
+	ctx := c.Request().Context()
+	decoded, err := h.service.DecodeVIN(ctx, c.Param("vin"))
+	if err != nil {
+		return vinError(err)
+	}
+
+	return c.JSON(http.StatusOK, decoded)
+}
+
+// GetVINCompatibleItems handles retrieving the items that fit the vehicle a
+// VIN decodes to
+func (h *VINHandler) GetVINCompatibleItems(c echo.Context) error {
+	ctx := c.Request().Context()
+	result, err := h.service.GetCompatibleItems(ctx, c.Param("vin"))
+	if err != nil {
+		return vinError(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetAllWMIs handles retrieval of the manufacturer identifiers
+func (h *VINHandler) GetAllWMIs(c echo.Context) error {
+	ctx := c.Request().Context()
+	wmis, err := h.service.GetAllWMIs(ctx)
+	if err != nil {
+		return vinError(err)
+	}
+
+	return c.JSON(http.StatusOK, wmis)
+}
+
+// CreateWMI handles adding a manufacturer identifier for a make
+func (h *VINHandler) CreateWMI(c echo.Context) error {
+	wmi := new(vehiclemodels.WMI)
+	if err := c.Bind(wmi); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	created, err := h.service.CreateWMI(ctx, wmi)
+	if err != nil {
+		return vinError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// DeleteWMI handles removing a manufacturer identifier and its patterns
+func (h *VINHandler) DeleteWMI(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.service.DeleteWMI(ctx, c.Param("wmi")); err != nil {
+		return vinError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetAllPatterns handles retrieval of VIN patterns with optional filtering
+func (h *VINHandler) GetAllPatterns(c echo.Context) error {
+	filter := &vehiclemodels.VINPatternFilter{}
+
+	if wmi := c.QueryParam("wmi"); wmi != "" {
+		filter.WMI = &wmi
+	}
+
+	if submodelID, err := strconv.Atoi(c.QueryParam("submodel_id")); err == nil {
+		filter.SubmodelID = &submodelID
+	}
+
+	ctx := c.Request().Context()
+	patterns, err := h.service.GetAllPatterns(ctx, filter)
+	if err != nil {
+		return vinError(err)
+	}
+
+	return c.JSON(http.StatusOK, patterns)
+}
+
+// CreatePattern handles mapping a VIN pattern to a submodel
+func (h *VINHandler) CreatePattern(c echo.Context) error {
+	pattern := new(vehiclemodels.VINPattern)
+	if err := c.Bind(pattern); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.CreatePattern(ctx, pattern)
+	if err != nil {
+		return vinError(err)
+	}
+
+	created, err := h.service.GetPatternByID(ctx, id)
+	if err != nil {
+		return vinError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// DeletePattern handles removing a VIN pattern
+func (h *VINHandler) DeletePattern(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid VIN pattern ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.DeletePattern(ctx, id); err != nil {
+		return vinError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func vinError(err error) error {
+	switch err {
+	case services.ErrInvalidVIN, services.ErrInvalidCheckDigit, services.ErrInvalidModelYear,
+		services.ErrInvalidWMI, services.ErrInvalidVDSPattern, services.ErrInvalidYearRange,
+		services.ErrInvalidVINPatternID:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrVINNotMatched, services.ErrWMINotFound, services.ErrVINPatternNotFound,
+		services.ErrMakeNotFound, services.ErrSubmodelNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrWMIExists, services.ErrVINPatternExists:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
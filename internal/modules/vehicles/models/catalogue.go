@@ -0,0 +1,57 @@
+package vehiclemodels
+
+// VehicleSearch is a free-text vehicle search such as "golf 2017 diesel",
+// with the tokens it was read as and the submodels that match
+type VehicleSearch struct {
+	Query              string          `json:"query"`
+	Year               *int            `json:"year,omitempty"`
+	FuelType           *string         `json:"fuel_type,omitempty"`
+	EngineDisplacement *float64        `json:"engine_displacement,omitempty"`
+	Terms              []string        `json:"terms"` // Matched against make, model, submodel, engine, transmission and body
+	Matches            []*VehicleMatch `json:"matches"`
+}
+
+// VehicleMatch is a submodel found by a vehicle search. Matches on the make
+// or model name score higher than matches on the submodel or engine.
+type VehicleMatch struct {
+	*Submodel
+	Score int `json:"score"`
+}
+
+// CatalogueTreeFilter narrows the catalogue tree to one make, or to the
+// submodels built in one year
+type CatalogueTreeFilter struct {
+	MakeID *int `query:"make_id"`
+	Year   *int `query:"year"`
+}
+
+// MakeNode is a make in the catalogue tree. Item counts are the active items
+// compatible with any submodel below the node.
+type MakeNode struct {
+	MakeID    int          `json:"make_id"`
+	MakeName  string       `json:"make_name"`
+	ItemCount int          `json:"item_count"`
+	Models    []*ModelNode `json:"models"`
+}
+
+// ModelNode is a model in the catalogue tree
+type ModelNode struct {
+	ModelID   int             `json:"model_id"`
+	MakeID    int             `json:"make_id"`
+	ModelName string          `json:"model_name"`
+	ItemCount int             `json:"item_count"`
+	Submodels []*SubmodelNode `json:"submodels"`
+}
+
+// SubmodelNode is a submodel in the catalogue tree
+type SubmodelNode struct {
+	SubmodelID         int     `json:"submodel_id"`
+	ModelID            int     `json:"model_id"`
+	SubmodelName       string  `json:"submodel_name"`
+	YearFrom           int     `json:"year_from"`
+	YearTo             *int    `json:"year_to,omitempty"`
+	EngineType         string  `json:"engine_type"`
+	EngineDisplacement float64 `json:"engine_displacement"`
+	FuelType           string  `json:"fuel_type"`
+	ItemCount          int     `json:"item_count"`
+}
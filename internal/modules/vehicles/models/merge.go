@@ -0,0 +1,54 @@
+package vehiclemodels
+
+import "time"
+
+// Entity types of vehicle records that can be merged
+const (
+	EntityMake     = "make"
+	EntityModel    = "model"
+	EntitySubmodel = "submodel"
+)
+
+// DuplicateCandidate is a pair of records that look like the same vehicle.
+// The older record comes first as the suggested survivor.
+type DuplicateCandidate struct {
+	EntityType    string  `json:"entity_type"`
+	ID            int     `json:"id"`
+	Name          string  `json:"name"`
+	DuplicateID   int     `json:"duplicate_id"`
+	DuplicateName string  `json:"duplicate_name"`
+	Parent        string  `json:"parent,omitempty"` // Make, or make and model, the pair belongs to
+	Similarity    float64 `json:"similarity"`
+}
+
+type DuplicateFilter struct {
+	EntityType *string  `query:"entity_type"`
+	Threshold  *float64 `query:"threshold"`
+}
+
+// MergeRequest names the duplicate to merge into the record in the path
+type MergeRequest struct {
+	DuplicateID int     `json:"duplicate_id"`
+	MergedBy    *string `json:"merged_by,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+// MergeRecord is the audit entry of a merge. Repointed counts the rows moved
+// to the survivor, and the rows dropped because the survivor already had
+// them, by table.
+type MergeRecord struct {
+	MergeID    int              `json:"merge_id"`
+	EntityType string           `json:"entity_type"`
+	SurvivorID int              `json:"survivor_id"`
+	MergedID   int              `json:"merged_id"`
+	MergedName string           `json:"merged_name"`
+	Repointed  map[string]int64 `json:"repointed"`
+	MergedBy   *string          `json:"merged_by,omitempty"`
+	Notes      *string          `json:"notes,omitempty"`
+	MergedAt   time.Time        `json:"merged_at"`
+}
+
+type MergeHistoryFilter struct {
+	EntityType *string `query:"entity_type"`
+	RecordID   *int    `query:"record_id"` // Survivor or merged record
+}
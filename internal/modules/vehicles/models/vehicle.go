@@ -40,5 +40,6 @@ type Submodel struct {
 
 	// Additional fields for API responses
 	ModelName string `json:"model_name,omitempty" db:"-"`
+	MakeID    int    `json:"make_id,omitempty" db:"-"`
 	MakeName  string `json:"make_name,omitempty" db:"-"`
 }
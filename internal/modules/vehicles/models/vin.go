@@ -0,0 +1,73 @@
+package vehiclemodels
+
+import "time"
+
+// VINDecode is what a VIN tells us about a vehicle. Submodels are found
+// through the locally maintained VIN patterns and are empty when none match.
+type VINDecode struct {
+	VIN             string      `json:"vin"`
+	WMI             string      `json:"wmi"`         // Positions 1-3, the manufacturer
+	VDS             string      `json:"vds"`         // Positions 4-8, model, body and engine
+	VIS             string      `json:"vis"`         // Positions 10-17, year, plant and serial
+	CheckDigit      string      `json:"check_digit"` // Position 9
+	CheckDigitValid bool        `json:"check_digit_valid"`
+	ModelYear       int         `json:"model_year"`
+	MakeID          *int        `json:"make_id,omitempty"`
+	MakeName        *string     `json:"make_name,omitempty"`
+	Manufacturer    *string     `json:"manufacturer,omitempty"`
+	Country         *string     `json:"country,omitempty"`
+	Submodels       []*Submodel `json:"submodels"`
+}
+
+// WMI maps a world manufacturer identifier to a make
+type WMI struct {
+	WMI          string    `json:"wmi" db:"wmi"`
+	MakeID       int       `json:"make_id" db:"make_id"`
+	Manufacturer *string   `json:"manufacturer,omitempty" db:"manufacturer"`
+	Country      *string   `json:"country,omitempty" db:"country"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	MakeName string `json:"make_name,omitempty" db:"-"`
+}
+
+// VINPattern maps VINs of one manufacturer to a submodel. The pattern covers
+// positions 4-8 with * matching any character; when several patterns match,
+// only the most specific are used.
+type VINPattern struct {
+	PatternID  int       `json:"pattern_id" db:"pattern_id"`
+	WMI        string    `json:"wmi" db:"wmi"`
+	VDSPattern string    `json:"vds_pattern" db:"vds_pattern"`
+	YearFrom   *int      `json:"year_from,omitempty" db:"year_from"`
+	YearTo     *int      `json:"year_to,omitempty" db:"year_to"`
+	SubmodelID int       `json:"submodel_id" db:"submodel_id"`
+	Notes      *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	SubmodelName string `json:"submodel_name,omitempty" db:"-"`
+	ModelName    string `json:"model_name,omitempty" db:"-"`
+	MakeName     string `json:"make_name,omitempty" db:"-"`
+}
+
+type VINPatternFilter struct {
+	WMI        *string `query:"wmi"`
+	SubmodelID *int    `query:"submodel_id"`
+}
+
+// CompatibleItem is an active item that fits a decoded vehicle
+type CompatibleItem struct {
+	ItemID         int     `json:"item_id"`
+	PartNumber     string  `json:"part_number"`
+	Description    string  `json:"description"`
+	CategoryName   *string `json:"category_name,omitempty"`
+	SellPrice      float64 `json:"sell_price"`
+	CurrentStock   int     `json:"current_stock"`
+	AvailableStock int     `json:"available_stock"`
+}
+
+type VINCompatibleItems struct {
+	Vehicle *VINDecode        `json:"vehicle"`
+	Items   []*CompatibleItem `json:"items"`
+}
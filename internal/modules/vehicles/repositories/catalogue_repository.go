@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+)
+
+// CatalogueRepository defines the interface for searching and browsing the
+// whole vehicle catalogue
+type CatalogueRepository interface {
+	GetSearchCatalogue(ctx context.Context) ([]*vehiclemodels.Submodel, error)
+	GetMakeNodes(ctx context.Context, filter *vehiclemodels.CatalogueTreeFilter) ([]*vehiclemodels.MakeNode, error)
+	GetModelNodes(ctx context.Context, filter *vehiclemodels.CatalogueTreeFilter) ([]*vehiclemodels.ModelNode, error)
+	GetSubmodelNodes(ctx context.Context, filter *vehiclemodels.CatalogueTreeFilter) ([]*vehiclemodels.SubmodelNode, error)
+}
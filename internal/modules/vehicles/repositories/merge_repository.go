@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+)
+
+// MergeRepository defines the interface for finding duplicate makes, models
+// and submodels and merging them
+type MergeRepository interface {
+	GetDuplicateMakes(ctx context.Context, threshold float64) ([]*vehiclemodels.DuplicateCandidate, error)
+	GetDuplicateModels(ctx context.Context, threshold float64) ([]*vehiclemodels.DuplicateCandidate, error)
+	GetDuplicateSubmodels(ctx context.Context, threshold float64) ([]*vehiclemodels.DuplicateCandidate, error)
+
+	MergeMakes(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error)
+	MergeModels(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error)
+	MergeSubmodels(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error)
+	GetMergeHistory(ctx context.Context, filter *vehiclemodels.MergeHistoryFilter) ([]*vehiclemodels.MergeRecord, error)
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+)
+
+type PostgresCatalogueRepository struct {
+	db *db.Database
+}
+
+func NewPostgresCatalogueRepository(database *db.Database) CatalogueRepository {
+	return &PostgresCatalogueRepository{
+		db: database,
+	}
+}
+
+// catalogueTreeFrom joins the catalogue to the active items compatible with
+// each submodel. $1 limits it to a make and $2 to the submodels built in a
+// year, counting only the items that fit that year.
+const catalogueTreeFrom = `
+	FROM vehicle_makes mk
+	LEFT JOIN vehicle_models m ON m.make_id = mk.make_id
+	LEFT JOIN vehicle_submodels s ON s.model_id = m.model_id
+		AND ($2::int IS NULL OR (s.year_from <= $2 AND COALESCE(s.year_to, $2) >= $2))
+	LEFT JOIN compatibility c ON c.submodel_id = s.submodel_id
+		AND ($2::int IS NULL OR (
+			COALESCE(c.year_from, s.year_from) <= $2 AND COALESCE(c.year_to, s.year_to, $2) >= $2
+		))
+	LEFT JOIN items i ON i.item_id = c.item_id AND i.is_active
+	WHERE ($1::int IS NULL OR mk.make_id = $1)
+`
+
+// GetSearchCatalogue returns every submodel with its make and model names
+func (r *PostgresCatalogueRepository) GetSearchCatalogue(ctx context.Context) ([]*vehiclemodels.Submodel, error) {
+	query := `
+		SELECT s.submodel_id, s.model_id, s.submodel_name, s.year_from, s.year_to,
+			   COALESCE(s.engine_type, ''), COALESCE(s.engine_displacement, 0), COALESCE(s.fuel_type, ''),
+			   COALESCE(s.transmission_type, ''), COALESCE(s.body_type, ''), s.created_at, s.updated_at,
+			   m.model_name, mk.make_id, mk.make_name
+		FROM vehicle_submodels s
+		JOIN vehicle_models m ON s.model_id = m.model_id
+		JOIN vehicle_makes mk ON m.make_id = mk.make_id
+		ORDER BY mk.make_name, m.model_name, s.submodel_name, s.year_from
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submodels []*vehiclemodels.Submodel
+	for rows.Next() {
+		submodel := &vehiclemodels.Submodel{}
+		err := rows.Scan(
+			&submodel.SubmodelID, &submodel.ModelID, &submodel.SubmodelName, &submodel.YearFrom, &submodel.YearTo,
+			&submodel.EngineType, &submodel.EngineDisplacement, &submodel.FuelType,
+			&submodel.TransmissionType, &submodel.BodyType, &submodel.CreatedAt, &submodel.UpdatedAt,
+			&submodel.ModelName, &submodel.MakeID, &submodel.MakeName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		submodels = append(submodels, submodel)
+	}
+
+	return submodels, rows.Err()
+}
+
+func (r *PostgresCatalogueRepository) GetMakeNodes(ctx context.Context, filter *vehiclemodels.CatalogueTreeFilter) ([]*vehiclemodels.MakeNode, error) {
+	query := `
+		SELECT mk.make_id, mk.make_name, COUNT(DISTINCT i.item_id)::int
+	` + catalogueTreeFrom + `
+		GROUP BY mk.make_id, mk.make_name
+		ORDER BY mk.make_name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.MakeID, filter.Year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var makes []*vehiclemodels.MakeNode
+	for rows.Next() {
+		node := &vehiclemodels.MakeNode{}
+		if err := rows.Scan(&node.MakeID, &node.MakeName, &node.ItemCount); err != nil {
+			return nil, err
+		}
+		makes = append(makes, node)
+	}
+
+	return makes, rows.Err()
+}
+
+func (r *PostgresCatalogueRepository) GetModelNodes(ctx context.Context, filter *vehiclemodels.CatalogueTreeFilter) ([]*vehiclemodels.ModelNode, error) {
+	query := `
+		SELECT m.model_id, m.make_id, m.model_name, COUNT(DISTINCT i.item_id)::int
+	` + catalogueTreeFrom + `
+			AND m.model_id IS NOT NULL
+		GROUP BY m.model_id, m.make_id, m.model_name
+		ORDER BY m.model_name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.MakeID, filter.Year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var models []*vehiclemodels.ModelNode
+	for rows.Next() {
+		node := &vehiclemodels.ModelNode{}
+		if err := rows.Scan(&node.ModelID, &node.MakeID, &node.ModelName, &node.ItemCount); err != nil {
+			return nil, err
+		}
+		models = append(models, node)
+	}
+
+	return models, rows.Err()
+}
+
+func (r *PostgresCatalogueRepository) GetSubmodelNodes(ctx context.Context, filter *vehiclemodels.CatalogueTreeFilter) ([]*vehiclemodels.SubmodelNode, error) {
+	query := `
+		SELECT s.submodel_id, s.model_id, s.submodel_name, s.year_from, s.year_to,
+			   COALESCE(s.engine_type, ''), COALESCE(s.engine_displacement, 0), COALESCE(s.fuel_type, ''),
+			   COUNT(DISTINCT i.item_id)::int
+	` + catalogueTreeFrom + `
+			AND s.submodel_id IS NOT NULL
+		GROUP BY s.submodel_id
+		ORDER BY s.submodel_name, s.year_from
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.MakeID, filter.Year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submodels []*vehiclemodels.SubmodelNode
+	for rows.Next() {
+		node := &vehiclemodels.SubmodelNode{}
+		err := rows.Scan(
+			&node.SubmodelID, &node.ModelID, &node.SubmodelName, &node.YearFrom, &node.YearTo,
+			&node.EngineType, &node.EngineDisplacement, &node.FuelType, &node.ItemCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		submodels = append(submodels, node)
+	}
+
+	return submodels, rows.Err()
+}
@@ -0,0 +1,362 @@
+package repositories
+
+import (
+	"context"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresMergeRepository struct {
+	db *db.Database
+}
+
+func NewPostgresMergeRepository(database *db.Database) MergeRepository {
+	return &PostgresMergeRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresMergeRepository) GetDuplicateMakes(ctx context.Context, threshold float64) ([]*vehiclemodels.DuplicateCandidate, error) {
+	query := `
+		SELECT a.make_id, a.make_name, b.make_id, b.make_name, '',
+			   name_similarity(a.make_name, b.make_name) AS score
+		FROM vehicle_makes a
+		JOIN vehicle_makes b ON b.make_id > a.make_id
+		WHERE name_similarity(a.make_name, b.make_name) >= $1
+		ORDER BY score DESC, a.make_name
+	`
+
+	return r.getDuplicates(ctx, vehiclemodels.EntityMake, query, threshold)
+}
+
+// GetDuplicateModels compares the models of each make with one another
+func (r *PostgresMergeRepository) GetDuplicateModels(ctx context.Context, threshold float64) ([]*vehiclemodels.DuplicateCandidate, error) {
+	query := `
+		SELECT a.model_id, a.model_name, b.model_id, b.model_name, mk.make_name,
+			   name_similarity(a.model_name, b.model_name) AS score
+		FROM vehicle_models a
+		JOIN vehicle_models b ON b.make_id = a.make_id AND b.model_id > a.model_id
+		JOIN vehicle_makes mk ON mk.make_id = a.make_id
+		WHERE name_similarity(a.model_name, b.model_name) >= $1
+		ORDER BY score DESC, mk.make_name, a.model_name
+	`
+
+	return r.getDuplicates(ctx, vehiclemodels.EntityModel, query, threshold)
+}
+
+// GetDuplicateSubmodels compares the submodels of each model with one another.
+// Submodels are only alike if their years overlap and their fuel and engine
+// size, where both are known, are the same.
+func (r *PostgresMergeRepository) GetDuplicateSubmodels(ctx context.Context, threshold float64) ([]*vehiclemodels.DuplicateCandidate, error) {
+	query := `
+		SELECT a.submodel_id, a.submodel_name, b.submodel_id, b.submodel_name,
+			   mk.make_name || ' ' || m.model_name,
+			   name_similarity(a.submodel_name, b.submodel_name) AS score
+		FROM vehicle_submodels a
+		JOIN vehicle_submodels b ON b.model_id = a.model_id AND b.submodel_id > a.submodel_id
+		JOIN vehicle_models m ON m.model_id = a.model_id
+		JOIN vehicle_makes mk ON mk.make_id = m.make_id
+		WHERE name_similarity(a.submodel_name, b.submodel_name) >= $1
+			AND a.year_from <= COALESCE(b.year_to, a.year_from)
+			AND b.year_from <= COALESCE(a.year_to, b.year_from)
+			AND (a.fuel_type IS NULL OR b.fuel_type IS NULL OR lower(a.fuel_type) = lower(b.fuel_type))
+			AND (a.engine_displacement IS NULL OR b.engine_displacement IS NULL OR a.engine_displacement = b.engine_displacement)
+		ORDER BY score DESC, mk.make_name, m.model_name, a.submodel_name
+	`
+
+	return r.getDuplicates(ctx, vehiclemodels.EntitySubmodel, query, threshold)
+}
+
+func (r *PostgresMergeRepository) getDuplicates(ctx context.Context, entityType, query string, threshold float64) ([]*vehiclemodels.DuplicateCandidate, error) {
+	rows, err := r.db.Pool.Query(ctx, query, threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*vehiclemodels.DuplicateCandidate
+	for rows.Next() {
+		candidate := &vehiclemodels.DuplicateCandidate{EntityType: entityType}
+		err := rows.Scan(
+			&candidate.ID, &candidate.Name, &candidate.DuplicateID, &candidate.DuplicateName,
+			&candidate.Parent, &candidate.Similarity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// MergeMakes merges the duplicate make into the survivor. Models of the same
+// name are merged into the survivor's; the rest move over with the VIN
+// manufacturer codes.
+func (r *PostgresMergeRepository) MergeMakes(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error) {
+	return r.merge(ctx, func(tx pgx.Tx) (*vehiclemodels.MergeRecord, error) {
+		return mergeMake(ctx, tx, survivorID, request)
+	})
+}
+
+// MergeModels merges the duplicate model into the survivor. Submodels of the
+// same name and first year are merged into the survivor's; the rest move over.
+func (r *PostgresMergeRepository) MergeModels(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error) {
+	return r.merge(ctx, func(tx pgx.Tx) (*vehiclemodels.MergeRecord, error) {
+		return mergeModel(ctx, tx, survivorID, request.DuplicateID, request)
+	})
+}
+
+// MergeSubmodels merges the duplicate submodel into the survivor, moving its
+// compatibility, VIN patterns and customer vehicles over. Fitment the
+// survivor already has is kept as it is.
+func (r *PostgresMergeRepository) MergeSubmodels(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error) {
+	return r.merge(ctx, func(tx pgx.Tx) (*vehiclemodels.MergeRecord, error) {
+		return mergeSubmodel(ctx, tx, survivorID, request.DuplicateID, request)
+	})
+}
+
+func (r *PostgresMergeRepository) merge(ctx context.Context, fn func(tx pgx.Tx) (*vehiclemodels.MergeRecord, error)) (*vehiclemodels.MergeRecord, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	record, err := fn(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func mergeMake(ctx context.Context, tx pgx.Tx, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error) {
+	duplicateID := request.DuplicateID
+	counts := map[string]int64{}
+
+	pairs, err := matchingPairs(ctx, tx, `
+		SELECT s.model_id, d.model_id
+		FROM vehicle_models d
+		JOIN vehicle_models s ON s.make_id = $1 AND normalize_code(s.model_name) = normalize_code(d.model_name)
+		WHERE d.make_id = $2
+	`, survivorID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		if _, err := mergeModel(ctx, tx, pair[0], pair[1], request); err != nil {
+			return nil, err
+		}
+		counts["vehicle_models_merged"]++
+	}
+
+	err = execCounted(ctx, tx, counts, "vehicle_models",
+		`UPDATE vehicle_models SET make_id = $1 WHERE make_id = $2`, survivorID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	err = execCounted(ctx, tx, counts, "vin_wmi_codes",
+		`UPDATE vin_wmi_codes SET make_id = $1 WHERE make_id = $2`, survivorID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	return finishMerge(ctx, tx, vehiclemodels.EntityMake, survivorID, request, counts,
+		`SELECT m.make_name, to_jsonb(m) FROM vehicle_makes m WHERE m.make_id = $1`,
+		`DELETE FROM vehicle_makes WHERE make_id = $1`)
+}
+
+func mergeModel(ctx context.Context, tx pgx.Tx, survivorID, duplicateID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error) {
+	counts := map[string]int64{}
+
+	pairs, err := matchingPairs(ctx, tx, `
+		SELECT s.submodel_id, d.submodel_id
+		FROM vehicle_submodels d
+		JOIN vehicle_submodels s ON s.model_id = $1
+			AND normalize_code(s.submodel_name) = normalize_code(d.submodel_name)
+			AND s.year_from = d.year_from
+		WHERE d.model_id = $2
+	`, survivorID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		if _, err := mergeSubmodel(ctx, tx, pair[0], pair[1], request); err != nil {
+			return nil, err
+		}
+		counts["vehicle_submodels_merged"]++
+	}
+
+	err = execCounted(ctx, tx, counts, "vehicle_submodels",
+		`UPDATE vehicle_submodels SET model_id = $1 WHERE model_id = $2`, survivorID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	nested := *request
+	nested.DuplicateID = duplicateID
+	return finishMerge(ctx, tx, vehiclemodels.EntityModel, survivorID, &nested, counts,
+		`SELECT m.model_name, to_jsonb(m) FROM vehicle_models m WHERE m.model_id = $1`,
+		`DELETE FROM vehicle_models WHERE model_id = $1`)
+}
+
+func mergeSubmodel(ctx context.Context, tx pgx.Tx, survivorID, duplicateID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error) {
+	counts := map[string]int64{}
+
+	statements := []struct {
+		table string
+		query string
+	}{
+		{"compatibility_dropped", `
+			DELETE FROM compatibility d
+			WHERE d.submodel_id = $2
+				AND EXISTS (
+					SELECT 1 FROM compatibility s
+					WHERE s.submodel_id = $1 AND s.item_id = d.item_id
+						AND s.year_from IS NOT DISTINCT FROM d.year_from AND s.year_to IS NOT DISTINCT FROM d.year_to
+						AND s.position IS NOT DISTINCT FROM d.position AND s.engine_code IS NOT DISTINCT FROM d.engine_code
+				)
+		`},
+		{"compatibility", `UPDATE compatibility SET submodel_id = $1 WHERE submodel_id = $2`},
+		{"vin_patterns_dropped", `
+			DELETE FROM vin_patterns d
+			WHERE d.submodel_id = $2
+				AND EXISTS (
+					SELECT 1 FROM vin_patterns s
+					WHERE s.submodel_id = $1 AND s.wmi = d.wmi AND s.vds_pattern = d.vds_pattern
+				)
+		`},
+		{"vin_patterns", `UPDATE vin_patterns SET submodel_id = $1 WHERE submodel_id = $2`},
+		{"customer_vehicles", `UPDATE customer_vehicles SET submodel_id = $1 WHERE submodel_id = $2`},
+	}
+	for _, statement := range statements {
+		if err := execCounted(ctx, tx, counts, statement.table, statement.query, survivorID, duplicateID); err != nil {
+			return nil, err
+		}
+	}
+
+	nested := *request
+	nested.DuplicateID = duplicateID
+	return finishMerge(ctx, tx, vehiclemodels.EntitySubmodel, survivorID, &nested, counts,
+		`SELECT s.submodel_name, to_jsonb(s) FROM vehicle_submodels s WHERE s.submodel_id = $1`,
+		`DELETE FROM vehicle_submodels WHERE submodel_id = $1`)
+}
+
+func (r *PostgresMergeRepository) GetMergeHistory(ctx context.Context, filter *vehiclemodels.MergeHistoryFilter) ([]*vehiclemodels.MergeRecord, error) {
+	query := `
+		SELECT merge_id, entity_type, survivor_id, merged_id, merged_name, repointed,
+			   merged_by, notes, merged_at
+		FROM merge_audit
+		WHERE entity_type IN ('make', 'model', 'submodel')
+			AND ($1::text IS NULL OR entity_type = $1)
+			AND ($2::int IS NULL OR survivor_id = $2 OR merged_id = $2)
+		ORDER BY merged_at DESC, merge_id DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.EntityType, filter.RecordID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*vehiclemodels.MergeRecord
+	for rows.Next() {
+		record := &vehiclemodels.MergeRecord{}
+		err := rows.Scan(
+			&record.MergeID, &record.EntityType, &record.SurvivorID, &record.MergedID, &record.MergedName,
+			&record.Repointed, &record.MergedBy, &record.Notes, &record.MergedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Helper functions
+
+// matchingPairs reads the survivor and duplicate ids of child records that
+// have to be merged before their parents can be
+func matchingPairs(ctx context.Context, tx pgx.Tx, query string, survivorID, duplicateID int) ([][2]int, error) {
+	rows, err := tx.Query(ctx, query, survivorID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs [][2]int
+	for rows.Next() {
+		var pair [2]int
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, rows.Err()
+}
+
+// execCounted runs a statement and adds the rows it touched to the counts
+func execCounted(ctx context.Context, tx pgx.Tx, counts map[string]int64, table, query string, args ...any) error {
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n := result.RowsAffected(); n > 0 {
+		counts[table] += n
+	}
+	return nil
+}
+
+// finishMerge writes the audit entry with the duplicate as it is now, then
+// deletes it
+func finishMerge(
+	ctx context.Context,
+	tx pgx.Tx,
+	entityType string,
+	survivorID int,
+	request *vehiclemodels.MergeRequest,
+	counts map[string]int64,
+	recordQuery string,
+	deleteQuery string,
+) (*vehiclemodels.MergeRecord, error) {
+	record := &vehiclemodels.MergeRecord{
+		EntityType: entityType,
+		SurvivorID: survivorID,
+		MergedID:   request.DuplicateID,
+		Repointed:  counts,
+		MergedBy:   request.MergedBy,
+		Notes:      request.Notes,
+	}
+
+	var merged []byte
+	if err := tx.QueryRow(ctx, recordQuery, request.DuplicateID).Scan(&record.MergedName, &merged); err != nil {
+		return nil, err
+	}
+
+	err := tx.QueryRow(ctx, `
+		INSERT INTO merge_audit (
+			entity_type, survivor_id, merged_id, merged_name, merged_record, repointed, merged_by, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING merge_id, merged_at
+	`,
+		record.EntityType, record.SurvivorID, record.MergedID, record.MergedName, merged,
+		record.Repointed, record.MergedBy, record.Notes,
+	).Scan(&record.MergeID, &record.MergedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, deleteQuery, request.DuplicateID); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
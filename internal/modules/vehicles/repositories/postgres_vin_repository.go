@@ -0,0 +1,336 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresVINRepository struct {
+	db *db.Database
+}
+
+func NewPostgresVINRepository(database *db.Database) VINRepository {
+	return &PostgresVINRepository{
+		db: database,
+	}
+}
+
+const wmiQuery = `
+	SELECT w.wmi, w.make_id, w.manufacturer, w.country, w.created_at, mk.make_name
+	FROM vin_wmi_codes w
+	JOIN vehicle_makes mk ON w.make_id = mk.make_id
+`
+
+const vinPatternQuery = `
+	SELECT p.pattern_id, p.wmi, p.vds_pattern, p.year_from, p.year_to,
+		   p.submodel_id, p.notes, p.created_at, p.updated_at,
+		   s.submodel_name, m.model_name, mk.make_name
+	FROM vin_patterns p
+	JOIN vehicle_submodels s ON p.submodel_id = s.submodel_id
+	JOIN vehicle_models m ON s.model_id = m.model_id
+	JOIN vehicle_makes mk ON m.make_id = mk.make_id
+`
+
+func scanWMI(row pgx.Row) (*vehiclemodels.WMI, error) {
+	wmi := &vehiclemodels.WMI{}
+	err := row.Scan(
+		&wmi.WMI,
+		&wmi.MakeID,
+		&wmi.Manufacturer,
+		&wmi.Country,
+		&wmi.CreatedAt,
+		&wmi.MakeName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return wmi, nil
+}
+
+func scanVINPattern(row pgx.Row) (*vehiclemodels.VINPattern, error) {
+	pattern := &vehiclemodels.VINPattern{}
+	err := row.Scan(
+		&pattern.PatternID,
+		&pattern.WMI,
+		&pattern.VDSPattern,
+		&pattern.YearFrom,
+		&pattern.YearTo,
+		&pattern.SubmodelID,
+		&pattern.Notes,
+		&pattern.CreatedAt,
+		&pattern.UpdatedAt,
+		&pattern.SubmodelName,
+		&pattern.ModelName,
+		&pattern.MakeName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pattern, nil
+}
+
+// Manufacturer identifiers
+func (r *PostgresVINRepository) GetAllWMIs(ctx context.Context) ([]*vehiclemodels.WMI, error) {
+	rows, err := r.db.Pool.Query(ctx, wmiQuery+" ORDER BY mk.make_name, w.wmi")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wmis []*vehiclemodels.WMI
+	for rows.Next() {
+		wmi, err := scanWMI(rows)
+		if err != nil {
+			return nil, err
+		}
+		wmis = append(wmis, wmi)
+	}
+
+	return wmis, rows.Err()
+}
+
+func (r *PostgresVINRepository) GetWMI(ctx context.Context, code string) (*vehiclemodels.WMI, error) {
+	wmi, err := scanWMI(r.db.Pool.QueryRow(ctx, wmiQuery+" WHERE w.wmi = $1", code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return wmi, nil
+}
+
+func (r *PostgresVINRepository) CreateWMI(ctx context.Context, wmi *vehiclemodels.WMI) error {
+	query := `
+		INSERT INTO vin_wmi_codes (wmi, make_id, manufacturer, country)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, wmi.WMI, wmi.MakeID, wmi.Manufacturer, wmi.Country)
+	return err
+}
+
+func (r *PostgresVINRepository) DeleteWMI(ctx context.Context, wmi string) error {
+	result, err := r.db.Pool.Exec(ctx, "DELETE FROM vin_wmi_codes WHERE wmi = $1", wmi)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("WMI not found")
+	}
+
+	return nil
+}
+
+// Patterns
+func (r *PostgresVINRepository) GetAllPatterns(ctx context.Context, filter *vehiclemodels.VINPatternFilter) ([]*vehiclemodels.VINPattern, error) {
+	query := vinPatternQuery + " WHERE 1=1"
+
+	var params []interface{}
+	paramCount := 1
+
+	if filter.WMI != nil {
+		query += fmt.Sprintf(" AND p.wmi = $%d", paramCount)
+		params = append(params, *filter.WMI)
+		paramCount++
+	}
+
+	if filter.SubmodelID != nil {
+		query += fmt.Sprintf(" AND p.submodel_id = $%d", paramCount)
+		params = append(params, *filter.SubmodelID)
+		paramCount++
+	}
+
+	query += " ORDER BY p.wmi, p.vds_pattern, p.year_from"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var patterns []*vehiclemodels.VINPattern
+	for rows.Next() {
+		pattern, err := scanVINPattern(rows)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, rows.Err()
+}
+
+func (r *PostgresVINRepository) GetPatternByID(ctx context.Context, id int) (*vehiclemodels.VINPattern, error) {
+	pattern, err := scanVINPattern(r.db.Pool.QueryRow(ctx, vinPatternQuery+" WHERE p.pattern_id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
+func (r *PostgresVINRepository) PatternExists(ctx context.Context, pattern *vehiclemodels.VINPattern) (bool, error) {
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM vin_patterns
+			WHERE wmi = $1 AND vds_pattern = $2 AND submodel_id = $3
+		)
+	`, pattern.WMI, pattern.VDSPattern, pattern.SubmodelID).Scan(&exists)
+	return exists, err
+}
+
+func (r *PostgresVINRepository) CreatePattern(ctx context.Context, pattern *vehiclemodels.VINPattern) (int, error) {
+	query := `
+		INSERT INTO vin_patterns (wmi, vds_pattern, year_from, year_to, submodel_id, notes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING pattern_id
+	`
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		pattern.WMI,
+		pattern.VDSPattern,
+		pattern.YearFrom,
+		pattern.YearTo,
+		pattern.SubmodelID,
+		pattern.Notes,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresVINRepository) DeletePattern(ctx context.Context, id int) error {
+	result, err := r.db.Pool.Exec(ctx, "DELETE FROM vin_patterns WHERE pattern_id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("VIN pattern not found")
+	}
+
+	return nil
+}
+
+// Decoding
+
+// MatchSubmodels finds the submodels whose patterns match the VIN for its
+// model year. Only the most specific matches are kept, so a pattern naming
+// the engine code wins over one that leaves it open.
+func (r *PostgresVINRepository) MatchSubmodels(ctx context.Context, wmi, vds string, modelYear int) ([]*vehiclemodels.Submodel, error) {
+	query := `
+		WITH matches AS (
+			SELECT p.submodel_id, MAX(LENGTH(REPLACE(p.vds_pattern, '*', ''))) AS specificity
+			FROM vin_patterns p
+			WHERE p.wmi = $1
+				AND $2 LIKE REPLACE(p.vds_pattern, '*', '_')
+				AND (p.year_from IS NULL OR p.year_from <= $3)
+				AND (p.year_to IS NULL OR p.year_to >= $3)
+			GROUP BY p.submodel_id
+		)
+		SELECT s.submodel_id, s.model_id, s.submodel_name, s.year_from, s.year_to,
+			   s.engine_type, s.engine_displacement, s.fuel_type, s.transmission_type,
+			   s.body_type, s.created_at, s.updated_at,
+			   m.model_name, mk.make_name
+		FROM matches mt
+		JOIN vehicle_submodels s ON mt.submodel_id = s.submodel_id
+		JOIN vehicle_models m ON s.model_id = m.model_id
+		JOIN vehicle_makes mk ON m.make_id = mk.make_id
+		WHERE mt.specificity = (SELECT MAX(specificity) FROM matches)
+		ORDER BY m.model_name, s.submodel_name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, wmi, vds, modelYear)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submodels []*vehiclemodels.Submodel
+	for rows.Next() {
+		submodel := &vehiclemodels.Submodel{}
+		err := rows.Scan(
+			&submodel.SubmodelID,
+			&submodel.ModelID,
+			&submodel.SubmodelName,
+			&submodel.YearFrom,
+			&submodel.YearTo,
+			&submodel.EngineType,
+			&submodel.EngineDisplacement,
+			&submodel.FuelType,
+			&submodel.TransmissionType,
+			&submodel.BodyType,
+			&submodel.CreatedAt,
+			&submodel.UpdatedAt,
+			&submodel.ModelName,
+			&submodel.MakeName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		submodels = append(submodels, submodel)
+	}
+
+	return submodels, rows.Err()
+}
+
+// GetCompatibleItems lists the active items that fit any of the submodels in
+// the model year, as the inventory's compatible items lookup does for a
+// single submodel
+func (r *PostgresVINRepository) GetCompatibleItems(ctx context.Context, submodelIDs []int, modelYear int) ([]*vehiclemodels.CompatibleItem, error) {
+	query := `
+		SELECT DISTINCT i.item_id, i.part_number, i.description, c.category_name,
+			   i.sell_price, i.current_stock, available_stock(i.item_id)
+		FROM items i
+		LEFT JOIN categories c ON i.category_id = c.category_id
+		JOIN compatibility comp ON i.item_id = comp.item_id
+		JOIN vehicle_submodels vs ON comp.submodel_id = vs.submodel_id
+		WHERE comp.submodel_id = ANY($1) AND i.is_active = true
+		  AND COALESCE(comp.year_from, vs.year_from) <= $2
+		  AND COALESCE(comp.year_to, vs.year_to, $2) >= $2
+		ORDER BY i.part_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, submodelIDs, modelYear)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*vehiclemodels.CompatibleItem
+	for rows.Next() {
+		item := &vehiclemodels.CompatibleItem{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.Description,
+			&item.CategoryName,
+			&item.SellPrice,
+			&item.CurrentStock,
+			&item.AvailableStock,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
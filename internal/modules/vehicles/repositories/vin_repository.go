@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+)
+
+// VINRepository defines the interface for VIN decoding tables
+type VINRepository interface {
+	// Manufacturer identifiers
+	GetAllWMIs(ctx context.Context) ([]*vehiclemodels.WMI, error)
+	GetWMI(ctx context.Context, wmi string) (*vehiclemodels.WMI, error)
+	CreateWMI(ctx context.Context, wmi *vehiclemodels.WMI) error
+	DeleteWMI(ctx context.Context, wmi string) error
+
+	// Patterns
+	GetAllPatterns(ctx context.Context, filter *vehiclemodels.VINPatternFilter) ([]*vehiclemodels.VINPattern, error)
+	GetPatternByID(ctx context.Context, id int) (*vehiclemodels.VINPattern, error)
+	PatternExists(ctx context.Context, pattern *vehiclemodels.VINPattern) (bool, error)
+	CreatePattern(ctx context.Context, pattern *vehiclemodels.VINPattern) (int, error)
+	DeletePattern(ctx context.Context, id int) error
+
+	// Decoding
+	MatchSubmodels(ctx context.Context, wmi, vds string, modelYear int) ([]*vehiclemodels.Submodel, error)
+	GetCompatibleItems(ctx context.Context, submodelIDs []int, modelYear int) ([]*vehiclemodels.CompatibleItem, error)
+}
@@ -13,11 +13,21 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	// Initialize repository
 	repo := repositories.NewPostgresVehicleRepository(database)
 
+	vinRepo := repositories.NewPostgresVINRepository(database)
+	catalogueRepo := repositories.NewPostgresCatalogueRepository(database)
+	mergeRepo := repositories.NewPostgresMergeRepository(database)
+
 	// Initialize service
 	service := services.NewVehicleService(repo)
+	vinService := services.NewVINService(vinRepo, repo)
+	catalogueService := services.NewCatalogueService(catalogueRepo)
+	mergeService := services.NewMergeService(mergeRepo, repo)
 
 	// Initialize handler
 	handler := handlers.NewVehicleHandler(service)
+	vinHandler := handlers.NewVINHandler(vinService)
+	catalogueHandler := handlers.NewCatalogueHandler(catalogueService)
+	mergeHandler := handlers.NewMergeHandler(mergeService)
 
 	// Vehicle makes routes
 	makes := api.Group("/makes")
@@ -27,6 +37,7 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	makes.PUT("/:id", handler.UpdateMake)
 	makes.DELETE("/:id", handler.DeleteMake)
 	makes.GET("/:makeId/models", handler.GetModelsByMake) // Get models for a specific make
+	makes.POST("/:id/merge", mergeHandler.MergeMakes)
 
 	// Vehicle models routes
 	models := api.Group("/models")
@@ -36,6 +47,7 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	models.PUT("/:id", handler.UpdateModel)
 	models.DELETE("/:id", handler.DeleteModel)
 	models.GET("/:modelId/submodels", handler.GetSubmodelsByModel) // Get submodels for a specific model
+	models.POST("/:id/merge", mergeHandler.MergeModels)
 
 	// Vehicle submodels routes
 	submodels := api.Group("/submodels")
@@ -44,4 +56,20 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	submodels.POST("", handler.CreateSubmodel)
 	submodels.PUT("/:id", handler.UpdateSubmodel)
 	submodels.DELETE("/:id", handler.DeleteSubmodel)
+	submodels.POST("/:id/merge", mergeHandler.MergeSubmodels)
+
+	// Catalogue search, VIN decoding and duplicate merging routes
+	vehicles := api.Group("/vehicles")
+	vehicles.GET("/search", catalogueHandler.SearchVehicles)
+	vehicles.GET("/tree", catalogueHandler.GetCatalogueTree)
+	vehicles.GET("/vin/:vin", vinHandler.DecodeVIN)
+	vehicles.GET("/vin/:vin/compatible-items", vinHandler.GetVINCompatibleItems)
+	vehicles.GET("/wmi", vinHandler.GetAllWMIs)
+	vehicles.POST("/wmi", vinHandler.CreateWMI)
+	vehicles.DELETE("/wmi/:wmi", vinHandler.DeleteWMI)
+	vehicles.GET("/vin-patterns", vinHandler.GetAllPatterns)
+	vehicles.POST("/vin-patterns", vinHandler.CreatePattern)
+	vehicles.DELETE("/vin-patterns/:id", vinHandler.DeletePattern)
+	vehicles.GET("/duplicates", mergeHandler.GetDuplicates)
+	vehicles.GET("/merges", mergeHandler.GetMergeHistory)
 }
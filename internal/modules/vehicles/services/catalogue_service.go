@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/internal/modules/vehicles/repositories"
+)
+
+var (
+	ErrEmptyVehicleSearch = errors.New("search needs a make, model, year, fuel or engine")
+	ErrInvalidTreeYear    = errors.New("year must be between 1900 and 2100")
+)
+
+// Matches returned by a vehicle search when no limit is given
+const defaultVehicleSearchLimit = 50
+
+type CatalogueService interface {
+	Search(ctx context.Context, query string, limit int) (*vehiclemodels.VehicleSearch, error)
+	GetTree(ctx context.Context, filter *vehiclemodels.CatalogueTreeFilter) ([]*vehiclemodels.MakeNode, error)
+}
+
+type catalogueService struct {
+	repo repositories.CatalogueRepository
+}
+
+func NewCatalogueService(repo repositories.CatalogueRepository) CatalogueService {
+	return &catalogueService{
+		repo: repo,
+	}
+}
+
+// Search reads a free-text query such as "golf 2017 diesel" and returns the
+// submodels that match all of it, best matches first
+func (s *catalogueService) Search(ctx context.Context, query string, limit int) (*vehiclemodels.VehicleSearch, error) {
+	search := parseVehicleQuery(strings.TrimSpace(query))
+	if len(search.Terms) == 0 && search.Year == nil && search.FuelType == nil && search.EngineDisplacement == nil {
+		return nil, ErrEmptyVehicleSearch
+	}
+	if limit <= 0 {
+		limit = defaultVehicleSearchLimit
+	}
+
+	catalogue, err := s.repo.GetSearchCatalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	search.Matches = []*vehiclemodels.VehicleMatch{}
+	for _, submodel := range catalogue {
+		if score, ok := scoreVehicle(search, submodel); ok {
+			search.Matches = append(search.Matches, &vehiclemodels.VehicleMatch{Submodel: submodel, Score: score})
+		}
+	}
+
+	// The catalogue comes sorted by name, so a stable sort keeps equal
+	// scores in name order
+	sort.SliceStable(search.Matches, func(i, j int) bool {
+		return search.Matches[i].Score > search.Matches[j].Score
+	})
+	if len(search.Matches) > limit {
+		search.Matches = search.Matches[:limit]
+	}
+
+	return search, nil
+}
+
+// GetTree returns the catalogue as makes, models and submodels with the
+// number of compatible items at each level. Limited to a year, makes and
+// models with no submodels built that year are left out.
+func (s *catalogueService) GetTree(ctx context.Context, filter *vehiclemodels.CatalogueTreeFilter) ([]*vehiclemodels.MakeNode, error) {
+	if filter.Year != nil && (*filter.Year < 1900 || *filter.Year > 2100) {
+		return nil, ErrInvalidTreeYear
+	}
+
+	makes, err := s.repo.GetMakeNodes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	models, err := s.repo.GetModelNodes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	submodels, err := s.repo.GetSubmodelNodes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	modelsByID := make(map[int]*vehiclemodels.ModelNode, len(models))
+	for _, model := range models {
+		model.Submodels = []*vehiclemodels.SubmodelNode{}
+		modelsByID[model.ModelID] = model
+	}
+	for _, submodel := range submodels {
+		if model, ok := modelsByID[submodel.ModelID]; ok {
+			model.Submodels = append(model.Submodels, submodel)
+		}
+	}
+
+	makesByID := make(map[int]*vehiclemodels.MakeNode, len(makes))
+	for _, makeNode := range makes {
+		makeNode.Models = []*vehiclemodels.ModelNode{}
+		makesByID[makeNode.MakeID] = makeNode
+	}
+	for _, model := range models {
+		if filter.Year != nil && len(model.Submodels) == 0 {
+			continue
+		}
+		if makeNode, ok := makesByID[model.MakeID]; ok {
+			makeNode.Models = append(makeNode.Models, model)
+		}
+	}
+
+	tree := []*vehiclemodels.MakeNode{}
+	for _, makeNode := range makes {
+		if filter.Year != nil && len(makeNode.Models) == 0 {
+			continue
+		}
+		tree = append(tree, makeNode)
+	}
+	return tree, nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/internal/modules/vehicles/repositories"
+)
+
+var (
+	ErrInvalidMergeEntity        = errors.New("entity type must be make, model or submodel")
+	ErrInvalidMergeThreshold     = errors.New("threshold must be greater than 0 and at most 1")
+	ErrInvalidDuplicateID        = errors.New("invalid duplicate ID")
+	ErrMergeIntoSelf             = errors.New("a record cannot be merged into itself")
+	ErrDuplicateMakeNotFound     = errors.New("duplicate make not found")
+	ErrDuplicateModelNotFound    = errors.New("duplicate model not found")
+	ErrDuplicateSubmodelNotFound = errors.New("duplicate submodel not found")
+)
+
+// Similarity two names need to be reported as duplicates when no threshold
+// is given
+const defaultDuplicateThreshold = 0.6
+
+type MergeService interface {
+	GetDuplicates(ctx context.Context, filter *vehiclemodels.DuplicateFilter) ([]*vehiclemodels.DuplicateCandidate, error)
+	MergeMakes(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error)
+	MergeModels(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error)
+	MergeSubmodels(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error)
+	GetMergeHistory(ctx context.Context, filter *vehiclemodels.MergeHistoryFilter) ([]*vehiclemodels.MergeRecord, error)
+}
+
+type mergeService struct {
+	repo     repositories.MergeRepository
+	vehicles repositories.VehicleRepository
+}
+
+func NewMergeService(repo repositories.MergeRepository, vehicles repositories.VehicleRepository) MergeService {
+	return &mergeService{
+		repo:     repo,
+		vehicles: vehicles,
+	}
+}
+
+// GetDuplicates returns the pairs of makes, models and submodels whose names
+// are at least as alike as the threshold, most alike first
+func (s *mergeService) GetDuplicates(ctx context.Context, filter *vehiclemodels.DuplicateFilter) ([]*vehiclemodels.DuplicateCandidate, error) {
+	threshold := defaultDuplicateThreshold
+	if filter.Threshold != nil {
+		threshold = *filter.Threshold
+	}
+	if threshold <= 0 || threshold > 1 {
+		return nil, ErrInvalidMergeThreshold
+	}
+
+	finders := map[string]func(context.Context, float64) ([]*vehiclemodels.DuplicateCandidate, error){
+		vehiclemodels.EntityMake:     s.repo.GetDuplicateMakes,
+		vehiclemodels.EntityModel:    s.repo.GetDuplicateModels,
+		vehiclemodels.EntitySubmodel: s.repo.GetDuplicateSubmodels,
+	}
+	entityTypes := []string{vehiclemodels.EntityMake, vehiclemodels.EntityModel, vehiclemodels.EntitySubmodel}
+	if filter.EntityType != nil {
+		if _, ok := finders[*filter.EntityType]; !ok {
+			return nil, ErrInvalidMergeEntity
+		}
+		entityTypes = []string{*filter.EntityType}
+	}
+
+	candidates := []*vehiclemodels.DuplicateCandidate{}
+	for _, entityType := range entityTypes {
+		found, err := finders[entityType](ctx, threshold)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, found...)
+	}
+	return candidates, nil
+}
+
+// MergeMakes merges the duplicate make, with its models and submodels, into
+// the survivor
+func (s *mergeService) MergeMakes(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error) {
+	if err := checkMergeRequest(survivorID, request); err != nil {
+		return nil, err
+	}
+
+	survivor, err := s.vehicles.GetMakeByID(ctx, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	if survivor == nil {
+		return nil, ErrMakeNotFound
+	}
+	duplicate, err := s.vehicles.GetMakeByID(ctx, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate == nil {
+		return nil, ErrDuplicateMakeNotFound
+	}
+
+	return s.repo.MergeMakes(ctx, survivorID, request)
+}
+
+// MergeModels merges the duplicate model, with its submodels, into the
+// survivor. The two need not belong to the same make.
+func (s *mergeService) MergeModels(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error) {
+	if err := checkMergeRequest(survivorID, request); err != nil {
+		return nil, err
+	}
+
+	survivor, err := s.vehicles.GetModelByID(ctx, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	if survivor == nil {
+		return nil, ErrModelNotFound
+	}
+	duplicate, err := s.vehicles.GetModelByID(ctx, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate == nil {
+		return nil, ErrDuplicateModelNotFound
+	}
+
+	return s.repo.MergeModels(ctx, survivorID, request)
+}
+
+func (s *mergeService) MergeSubmodels(ctx context.Context, survivorID int, request *vehiclemodels.MergeRequest) (*vehiclemodels.MergeRecord, error) {
+	if err := checkMergeRequest(survivorID, request); err != nil {
+		return nil, err
+	}
+
+	survivor, err := s.vehicles.GetSubmodelByID(ctx, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	if survivor == nil {
+		return nil, ErrSubmodelNotFound
+	}
+	duplicate, err := s.vehicles.GetSubmodelByID(ctx, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate == nil {
+		return nil, ErrDuplicateSubmodelNotFound
+	}
+
+	return s.repo.MergeSubmodels(ctx, survivorID, request)
+}
+
+func (s *mergeService) GetMergeHistory(ctx context.Context, filter *vehiclemodels.MergeHistoryFilter) ([]*vehiclemodels.MergeRecord, error) {
+	if filter.EntityType != nil {
+		switch *filter.EntityType {
+		case vehiclemodels.EntityMake, vehiclemodels.EntityModel, vehiclemodels.EntitySubmodel:
+		default:
+			return nil, ErrInvalidMergeEntity
+		}
+	}
+
+	records, err := s.repo.GetMergeHistory(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []*vehiclemodels.MergeRecord{}
+	}
+	return records, nil
+}
+
+// Helper functions
+
+func checkMergeRequest(survivorID int, request *vehiclemodels.MergeRequest) error {
+	if request.DuplicateID <= 0 {
+		return ErrInvalidDuplicateID
+	}
+	if request.DuplicateID == survivorID {
+		return ErrMergeIntoSelf
+	}
+	return nil
+}
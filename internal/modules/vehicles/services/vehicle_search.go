@@ -0,0 +1,151 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+)
+
+// fuelKeywords maps the words people type for a fuel to the fuel type the
+// catalogue records
+var fuelKeywords = map[string]string{
+	"gasoline": "gasoline",
+	"petrol":   "gasoline",
+	"gas":      "gasoline",
+	"diesel":   "diesel",
+	"hybrid":   "hybrid",
+	"electric": "electric",
+	"ev":       "electric",
+}
+
+// Scores for a term matching each part of a vehicle
+const (
+	scoreMake     = 3
+	scoreModel    = 3
+	scoreSubmodel = 2
+	scoreDetail   = 1
+)
+
+// parseVehicleQuery splits a free-text vehicle search into a model year, a
+// fuel, an engine size such as "2.0" or "2.0l", and the remaining terms. A
+// second year, fuel or engine size is kept as a plain term.
+func parseVehicleQuery(query string) *vehiclemodels.VehicleSearch {
+	search := &vehiclemodels.VehicleSearch{
+		Query: query,
+		Terms: []string{},
+	}
+
+	tokens := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+	for _, token := range tokens {
+		if strings.Trim(token, "-/") == "" {
+			continue
+		}
+		if year, ok := parseSearchYear(token); ok && search.Year == nil {
+			search.Year = &year
+			continue
+		}
+		if fuel, ok := fuelKeywords[token]; ok && search.FuelType == nil {
+			search.FuelType = &fuel
+			continue
+		}
+		if displacement, ok := parseDisplacement(token); ok && search.EngineDisplacement == nil {
+			search.EngineDisplacement = &displacement
+			continue
+		}
+		search.Terms = append(search.Terms, token)
+	}
+
+	return search
+}
+
+func parseSearchYear(token string) (int, bool) {
+	if len(token) != 4 {
+		return 0, false
+	}
+	year, err := strconv.Atoi(token)
+	if err != nil || year < 1900 || year > 2100 {
+		return 0, false
+	}
+	return year, true
+}
+
+// parseDisplacement reads an engine size in litres with one decimal place
+func parseDisplacement(token string) (float64, bool) {
+	token = strings.TrimSuffix(token, "l")
+	dot := strings.IndexByte(token, '.')
+	if dot < 1 || dot != len(token)-2 {
+		return 0, false
+	}
+	displacement, err := strconv.ParseFloat(token, 64)
+	if err != nil || displacement <= 0 {
+		return 0, false
+	}
+	return displacement, true
+}
+
+// scoreVehicle returns how well a submodel matches the search, or false if it
+// does not match. Every term has to match some part of the vehicle.
+func scoreVehicle(search *vehiclemodels.VehicleSearch, submodel *vehiclemodels.Submodel) (int, bool) {
+	if search.Year != nil {
+		if submodel.YearFrom > *search.Year || (submodel.YearTo != nil && *submodel.YearTo < *search.Year) {
+			return 0, false
+		}
+	}
+	if search.FuelType != nil && !strings.EqualFold(submodel.FuelType, *search.FuelType) {
+		return 0, false
+	}
+	if search.EngineDisplacement != nil {
+		diff := submodel.EngineDisplacement - *search.EngineDisplacement
+		if diff > 0.05 || diff < -0.05 {
+			return 0, false
+		}
+	}
+
+	fields := []struct {
+		value string
+		score int
+	}{
+		{submodel.MakeName, scoreMake},
+		{submodel.ModelName, scoreModel},
+		{submodel.SubmodelName, scoreSubmodel},
+		{submodel.EngineType, scoreDetail},
+		{submodel.TransmissionType, scoreDetail},
+		{submodel.BodyType, scoreDetail},
+	}
+
+	total := 0
+	for _, term := range search.Terms {
+		best := 0
+		for _, field := range fields {
+			if field.score > best && matchesTerm(field.value, term) {
+				best = field.score
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+	return total, true
+}
+
+// matchesTerm reports whether a word of the value starts with the term, or
+// the value written without spaces or dashes contains it, so "f150" finds
+// "F-150" and "a3" finds "A3 Sedan"
+func matchesTerm(value, term string) bool {
+	value = strings.ToLower(value)
+	for _, word := range strings.FieldsFunc(value, isNameSeparator) {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	compact := strings.Join(strings.FieldsFunc(value, isNameSeparator), "")
+	return strings.Contains(compact, strings.Join(strings.FieldsFunc(term, isNameSeparator), ""))
+}
+
+func isNameSeparator(r rune) bool {
+	return r == ' ' || r == '-' || r == '/'
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"strings"
+	"time"
+)
+
+// VIN decoding follows ISO 3779 and, for vehicles built for North America,
+// 49 CFR Part 565, which also makes the check digit mandatory.
+
+// vinCharValues transliterates VIN letters for the check digit. I, O and Q
+// are never used in a VIN.
+var vinCharValues = map[rune]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+var vinWeights = [17]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// Model year codes in position 10, repeating every 30 years from 1980
+const modelYearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// normalizeVIN upper-cases a VIN and drops the spaces and dashes it is often
+// written with. It returns false unless 17 valid characters remain.
+func normalizeVIN(vin string) (string, bool) {
+	vin = strings.ToUpper(vin)
+	vin = strings.NewReplacer(" ", "", "-", "").Replace(vin)
+
+	if len(vin) != 17 {
+		return "", false
+	}
+	for _, ch := range vin {
+		if _, ok := vinCharValue(ch); !ok {
+			return "", false
+		}
+	}
+
+	return vin, true
+}
+
+func vinCharValue(ch rune) (int, bool) {
+	if ch >= '0' && ch <= '9' {
+		return int(ch - '0'), true
+	}
+	value, ok := vinCharValues[ch]
+	return value, ok
+}
+
+// vinCheckDigit calculates the check digit for position 9
+func vinCheckDigit(vin string) byte {
+	sum := 0
+	for i, ch := range vin {
+		value, _ := vinCharValue(ch)
+		sum += value * vinWeights[i]
+	}
+
+	remainder := sum % 11
+	if remainder == 10 {
+		return 'X'
+	}
+	return byte('0' + remainder)
+}
+
+// isNorthAmericanVIN reports whether the VIN was issued for North America,
+// where the check digit and the year rule in position 7 apply
+func isNorthAmericanVIN(vin string) bool {
+	return vin[0] >= '1' && vin[0] <= '5'
+}
+
+// vinModelYear reads the model year from position 10. North American VINs
+// mark 2010 onwards with a letter in position 7; elsewhere the latest year
+// that is not past next year's models is taken.
+func vinModelYear(vin string, now time.Time) (int, bool) {
+	index := strings.IndexByte(modelYearCodes, vin[9])
+	if index < 0 {
+		return 0, false
+	}
+	year := 1980 + index
+
+	if isNorthAmericanVIN(vin) {
+		if vin[6] < '0' || vin[6] > '9' {
+			year += 30
+		}
+		return year, true
+	}
+
+	for year+30 <= now.Year()+1 {
+		year += 30
+	}
+	return year, true
+}
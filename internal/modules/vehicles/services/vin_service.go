@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/internal/modules/vehicles/repositories"
+)
+
+var (
+	ErrInvalidVIN          = errors.New("VIN must be 17 letters and digits, without I, O or Q")
+	ErrInvalidCheckDigit   = errors.New("VIN check digit does not match")
+	ErrInvalidModelYear    = errors.New("VIN has no valid model year in position 10")
+	ErrVINNotMatched       = errors.New("no submodel matches this VIN; add a VIN pattern for it")
+	ErrInvalidWMI          = errors.New("WMI must be 3 letters and digits, without I, O or Q")
+	ErrWMIExists           = errors.New("WMI already exists")
+	ErrWMINotFound         = errors.New("WMI not found")
+	ErrInvalidVDSPattern   = errors.New("VDS pattern must be 5 letters, digits or * for VIN positions 4-8")
+	ErrInvalidYearRange    = errors.New("year to cannot be before year from")
+	ErrVINPatternExists    = errors.New("VIN pattern already exists for this submodel")
+	ErrVINPatternNotFound  = errors.New("VIN pattern not found")
+	ErrInvalidVINPatternID = errors.New("invalid VIN pattern ID")
+)
+
+type VINService interface {
+	DecodeVIN(ctx context.Context, vin string) (*vehiclemodels.VINDecode, error)
+	GetCompatibleItems(ctx context.Context, vin string) (*vehiclemodels.VINCompatibleItems, error)
+
+	// Manufacturer identifiers
+	GetAllWMIs(ctx context.Context) ([]*vehiclemodels.WMI, error)
+	CreateWMI(ctx context.Context, wmi *vehiclemodels.WMI) (*vehiclemodels.WMI, error)
+	DeleteWMI(ctx context.Context, wmi string) error
+
+	// Patterns
+	GetAllPatterns(ctx context.Context, filter *vehiclemodels.VINPatternFilter) ([]*vehiclemodels.VINPattern, error)
+	GetPatternByID(ctx context.Context, id int) (*vehiclemodels.VINPattern, error)
+	CreatePattern(ctx context.Context, pattern *vehiclemodels.VINPattern) (int, error)
+	DeletePattern(ctx context.Context, id int) error
+}
+
+type vinService struct {
+	repo     repositories.VINRepository
+	vehicles repositories.VehicleRepository
+}
+
+func NewVINService(repo repositories.VINRepository, vehicles repositories.VehicleRepository) VINService {
+	return &vinService{
+		repo:     repo,
+		vehicles: vehicles,
+	}
+}
+
+// DecodeVIN validates a VIN, reads its manufacturer and model year, and finds
+// the submodels it matches. A wrong check digit is only rejected for North
+// American VINs; elsewhere position 9 is often not a check digit at all.
+func (s *vinService) DecodeVIN(ctx context.Context, vin string) (*vehiclemodels.VINDecode, error) {
+	vin, ok := normalizeVIN(vin)
+	if !ok {
+		return nil, ErrInvalidVIN
+	}
+
+	decoded := &vehiclemodels.VINDecode{
+		VIN:             vin,
+		WMI:             vin[0:3],
+		VDS:             vin[3:8],
+		VIS:             vin[9:17],
+		CheckDigit:      vin[8:9],
+		CheckDigitValid: vin[8] == vinCheckDigit(vin),
+		Submodels:       []*vehiclemodels.Submodel{},
+	}
+	if !decoded.CheckDigitValid && isNorthAmericanVIN(vin) {
+		return nil, ErrInvalidCheckDigit
+	}
+
+	decoded.ModelYear, ok = vinModelYear(vin, time.Now())
+	if !ok {
+		return nil, ErrInvalidModelYear
+	}
+
+	wmi, err := s.repo.GetWMI(ctx, decoded.WMI)
+	if err != nil {
+		return nil, err
+	}
+	if wmi == nil {
+		return decoded, nil
+	}
+
+	decoded.MakeID = &wmi.MakeID
+	decoded.MakeName = &wmi.MakeName
+	decoded.Manufacturer = wmi.Manufacturer
+	decoded.Country = wmi.Country
+
+	submodels, err := s.repo.MatchSubmodels(ctx, decoded.WMI, decoded.VDS, decoded.ModelYear)
+	if err != nil {
+		return nil, err
+	}
+	if submodels != nil {
+		decoded.Submodels = submodels
+	}
+
+	return decoded, nil
+}
+
+// GetCompatibleItems decodes a VIN and lists the items that fit any of the
+// submodels it matches in its model year
+func (s *vinService) GetCompatibleItems(ctx context.Context, vin string) (*vehiclemodels.VINCompatibleItems, error) {
+	decoded, err := s.DecodeVIN(ctx, vin)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded.Submodels) == 0 {
+		return nil, ErrVINNotMatched
+	}
+
+	submodelIDs := make([]int, len(decoded.Submodels))
+	for i, submodel := range decoded.Submodels {
+		submodelIDs[i] = submodel.SubmodelID
+	}
+
+	items, err := s.repo.GetCompatibleItems(ctx, submodelIDs, decoded.ModelYear)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*vehiclemodels.CompatibleItem{}
+	}
+
+	return &vehiclemodels.VINCompatibleItems{
+		Vehicle: decoded,
+		Items:   items,
+	}, nil
+}
+
+// Manufacturer identifiers
+func (s *vinService) GetAllWMIs(ctx context.Context) ([]*vehiclemodels.WMI, error) {
+	wmis, err := s.repo.GetAllWMIs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if wmis == nil {
+		wmis = []*vehiclemodels.WMI{}
+	}
+
+	return wmis, nil
+}
+
+func (s *vinService) CreateWMI(ctx context.Context, wmi *vehiclemodels.WMI) (*vehiclemodels.WMI, error) {
+	wmi.WMI = strings.ToUpper(strings.TrimSpace(wmi.WMI))
+	if !isValidVINSection(wmi.WMI, 3, false) {
+		return nil, ErrInvalidWMI
+	}
+
+	make, err := s.vehicles.GetMakeByID(ctx, wmi.MakeID)
+	if err != nil {
+		return nil, err
+	}
+	if make == nil {
+		return nil, ErrMakeNotFound
+	}
+
+	existing, err := s.repo.GetWMI(ctx, wmi.WMI)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrWMIExists
+	}
+
+	if err := s.repo.CreateWMI(ctx, wmi); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetWMI(ctx, wmi.WMI)
+}
+
+func (s *vinService) DeleteWMI(ctx context.Context, wmi string) error {
+	wmi = strings.ToUpper(wmi)
+
+	existing, err := s.repo.GetWMI(ctx, wmi)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrWMINotFound
+	}
+
+	return s.repo.DeleteWMI(ctx, wmi)
+}
+
+// Patterns
+func (s *vinService) GetAllPatterns(ctx context.Context, filter *vehiclemodels.VINPatternFilter) ([]*vehiclemodels.VINPattern, error) {
+	if filter.WMI != nil {
+		wmi := strings.ToUpper(*filter.WMI)
+		filter.WMI = &wmi
+	}
+
+	patterns, err := s.repo.GetAllPatterns(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if patterns == nil {
+		patterns = []*vehiclemodels.VINPattern{}
+	}
+
+	return patterns, nil
+}
+
+func (s *vinService) GetPatternByID(ctx context.Context, id int) (*vehiclemodels.VINPattern, error) {
+	if id <= 0 {
+		return nil, ErrInvalidVINPatternID
+	}
+
+	pattern, err := s.repo.GetPatternByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pattern == nil {
+		return nil, ErrVINPatternNotFound
+	}
+
+	return pattern, nil
+}
+
+func (s *vinService) CreatePattern(ctx context.Context, pattern *vehiclemodels.VINPattern) (int, error) {
+	pattern.WMI = strings.ToUpper(strings.TrimSpace(pattern.WMI))
+	pattern.VDSPattern = strings.ToUpper(strings.TrimSpace(pattern.VDSPattern))
+
+	if !isValidVINSection(pattern.VDSPattern, 5, true) {
+		return 0, ErrInvalidVDSPattern
+	}
+	if pattern.YearFrom != nil && pattern.YearTo != nil && *pattern.YearTo < *pattern.YearFrom {
+		return 0, ErrInvalidYearRange
+	}
+
+	wmi, err := s.repo.GetWMI(ctx, pattern.WMI)
+	if err != nil {
+		return 0, err
+	}
+	if wmi == nil {
+		return 0, ErrWMINotFound
+	}
+
+	submodel, err := s.vehicles.GetSubmodelByID(ctx, pattern.SubmodelID)
+	if err != nil {
+		return 0, err
+	}
+	if submodel == nil {
+		return 0, ErrSubmodelNotFound
+	}
+
+	exists, err := s.repo.PatternExists(ctx, pattern)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, ErrVINPatternExists
+	}
+
+	return s.repo.CreatePattern(ctx, pattern)
+}
+
+func (s *vinService) DeletePattern(ctx context.Context, id int) error {
+	if _, err := s.GetPatternByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.DeletePattern(ctx, id)
+}
+
+// Helper functions
+
+// isValidVINSection checks part of a VIN, optionally allowing * wildcards
+func isValidVINSection(section string, length int, wildcards bool) bool {
+	if len(section) != length {
+		return false
+	}
+	for _, ch := range section {
+		if wildcards && ch == '*' {
+			continue
+		}
+		if _, ok := vinCharValue(ch); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/services"
+	"github.com/labstack/echo/v4"
+)
+
+type WarehouseHandler struct {
+	service services.WarehouseService
+}
+
+func NewWarehouseHandler(service services.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{
+		service: service,
+	}
+}
+
+// GetWarehouses handles retrieval of all warehouses with their stock totals
+func (h *WarehouseHandler) GetWarehouses(c echo.Context) error {
+	var isActive *bool
+	if value := c.QueryParam("is_active"); value != "" {
+		active := value == "true"
+		isActive = &active
+	}
+
+	ctx := c.Request().Context()
+	warehouses, err := h.service.GetWarehouses(ctx, isActive)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, warehouses)
+}
+
+// GetWarehouseByID handles retrieval of a single warehouse
+func (h *WarehouseHandler) GetWarehouseByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	ctx := c.Request().Context()
+	warehouse, err := h.service.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, warehouse)
+}
+
+// CreateWarehouse handles creation of a new warehouse
+func (h *WarehouseHandler) CreateWarehouse(c echo.Context) error {
+	warehouse := &warehousemodels.Warehouse{IsActive: true}
+	if err := c.Bind(warehouse); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.CreateWarehouse(ctx, warehouse)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	created, err := h.service.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateWarehouse handles updating an existing warehouse
+func (h *WarehouseHandler) UpdateWarehouse(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	warehouse := &warehousemodels.Warehouse{IsActive: true}
+	if err := c.Bind(warehouse); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	warehouse.WarehouseID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.UpdateWarehouse(ctx, warehouse); err != nil {
+		return warehouseError(err)
+	}
+
+	updated, err := h.service.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// GetBins handles retrieval of the bin locations in a warehouse
+func (h *WarehouseHandler) GetBins(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	ctx := c.Request().Context()
+	bins, err := h.service.GetBins(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, bins)
+}
+
+// CreateBin handles adding a bin location to a warehouse
+func (h *WarehouseHandler) CreateBin(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	bin := new(warehousemodels.BinLocation)
+	if err := c.Bind(bin); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	bin.WarehouseID = id
+
+	ctx := c.Request().Context()
+	binID, err := h.service.CreateBin(ctx, bin)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	bin.BinID = binID
+	return c.JSON(http.StatusCreated, bin)
+}
+
+// DeleteBin handles removal of a bin location
+func (h *WarehouseHandler) DeleteBin(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	binID, err := strconv.Atoi(c.Param("binId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid bin ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.DeleteBin(ctx, id, binID); err != nil {
+		return warehouseError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetWarehouseStock handles retrieval of the items held at a warehouse
+func (h *WarehouseHandler) GetWarehouseStock(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	filter := &warehousemodels.StockFilter{
+		IncludeEmpty: c.QueryParam("include_empty") == "true",
+	}
+
+	if search := c.QueryParam("search"); search != "" {
+		filter.SearchTerm = &search
+	}
+
+	if binID, err := strconv.Atoi(c.QueryParam("bin_id")); err == nil {
+		filter.BinID = &binID
+	}
+
+	ctx := c.Request().Context()
+	stock, err := h.service.GetWarehouseStock(ctx, id, filter)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// GetItemStock handles retrieval of an item's stock at every warehouse
+func (h *WarehouseHandler) GetItemStock(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	stock, err := h.service.GetItemStock(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// AssignBin handles setting the bin an item is kept in at a warehouse
+func (h *WarehouseHandler) AssignBin(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	warehouseID, err := strconv.Atoi(c.Param("warehouseId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	var req warehousemodels.BinAssignment
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.AssignBin(ctx, id, warehouseID, req.BinID); err != nil {
+		return warehouseError(err)
+	}
+
+	stock, err := h.service.GetItemStock(ctx, id)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// AdjustStock handles a stock correction for an item at one warehouse
+func (h *WarehouseHandler) AdjustStock(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	adjustment := new(warehousemodels.StockAdjustment)
+	if err := c.Bind(adjustment); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	stock, err := h.service.AdjustStock(ctx, id, adjustment)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// GetMovements handles retrieval of an item's stock movement history
+func (h *WarehouseHandler) GetMovements(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	filter := &warehousemodels.MovementFilter{}
+
+	if warehouseID, err := strconv.Atoi(c.QueryParam("warehouse_id")); err == nil {
+		filter.WarehouseID = &warehouseID
+	}
+
+	if movementType := c.QueryParam("type"); movementType != "" {
+		filter.MovementType = &movementType
+	}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		date, err := parseDate(startDate)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid start_date")
+		}
+		filter.StartDate = &date
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		date, err := parseDate(endDate)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid end_date")
+		}
+		// A bare date includes the whole day
+		if len(endDate) == len("2006-01-02") {
+			date = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		filter.EndDate = &date
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		filter.Limit = n
+	}
+
+	ctx := c.Request().Context()
+	movements, err := h.service.GetMovements(ctx, id, filter)
+	if err != nil {
+		return warehouseError(err)
+	}
+
+	return c.JSON(http.StatusOK, movements)
+}
+
+func warehouseError(err error) error {
+	switch err {
+	case services.ErrWarehouseNotFound, services.ErrBinNotFound, services.ErrItemNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrInvalidWarehouseID, services.ErrWarehouseCodeRequired, services.ErrWarehouseNameRequired,
+		services.ErrDefaultInactive, services.ErrInvalidBinID, services.ErrBinCodeRequired,
+		services.ErrInvalidItemID, services.ErrInvalidQuantity, services.ErrInvalidMovementType,
+		services.ErrInvalidLimit:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrDuplicateWarehouse, services.ErrDuplicateBin:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrDefaultRequired, services.ErrWarehouseHasStock, services.ErrWarehouseInactive,
+		services.ErrInsufficientStock, services.ErrItemIsKit, services.ErrTrackedStock:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/services"
+	"github.com/labstack/echo/v4"
+)
+
+type TransferHandler struct {
+	service services.TransferService
+}
+
+func NewTransferHandler(service services.TransferService) *TransferHandler {
+	return &TransferHandler{
+		service: service,
+	}
+}
+
+// GetTransfers handles retrieval of stock transfers with optional filtering
+func (h *TransferHandler) GetTransfers(c echo.Context) error {
+	filter := &warehousemodels.TransferFilter{}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if warehouseID, err := strconv.Atoi(c.QueryParam("warehouse_id")); err == nil {
+		filter.WarehouseID = &warehouseID
+	}
+
+	if itemID, err := strconv.Atoi(c.QueryParam("item_id")); err == nil {
+		filter.ItemID = &itemID
+	}
+
+	ctx := c.Request().Context()
+	transfers, err := h.service.GetTransfers(ctx, filter)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfers)
+}
+
+// GetTransferByID handles retrieval of a stock transfer with its lines
+func (h *TransferHandler) GetTransferByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.GetTransferByID(ctx, id)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// CreateTransfer handles creation of a draft stock transfer
+func (h *TransferHandler) CreateTransfer(c echo.Context) error {
+	req := new(warehousemodels.CreateTransferRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.CreateTransfer(ctx, req)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusCreated, transfer)
+}
+
+// AddTransferLine handles adding an item to a draft transfer
+func (h *TransferHandler) AddTransferLine(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	line := new(warehousemodels.TransferLine)
+	if err := c.Bind(line); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	line.TransferID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.AddLine(ctx, line); err != nil {
+		return transferError(err)
+	}
+
+	transfer, err := h.service.GetTransferByID(ctx, id)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusCreated, transfer)
+}
+
+// UpdateTransferLine handles changing the quantity of a draft line
+func (h *TransferHandler) UpdateTransferLine(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	lineID, err := strconv.Atoi(c.Param("lineId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid line ID")
+	}
+
+	line := new(warehousemodels.TransferLine)
+	if err := c.Bind(line); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	line.TransferID = id
+	line.LineID = lineID
+
+	ctx := c.Request().Context()
+	if err := h.service.UpdateLine(ctx, line); err != nil {
+		return transferError(err)
+	}
+
+	transfer, err := h.service.GetTransferByID(ctx, id)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// RemoveTransferLine handles removing a line from a draft transfer
+func (h *TransferHandler) RemoveTransferLine(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	lineID, err := strconv.Atoi(c.Param("lineId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid line ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.RemoveLine(ctx, id, lineID); err != nil {
+		return transferError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DispatchTransfer handles sending a draft transfer on its way
+func (h *TransferHandler) DispatchTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	var req struct {
+		DispatchedBy *string `json:"dispatched_by,omitempty"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.Dispatch(ctx, id, req.DispatchedBy)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// ReceiveTransfer handles booking a transfer in at the destination
+func (h *TransferHandler) ReceiveTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	req := new(warehousemodels.ReceiveTransferRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.Receive(ctx, id, req)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// CancelTransfer handles cancelling a transfer that has not been received
+func (h *TransferHandler) CancelTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.Cancel(ctx, id)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+func transferError(err error) error {
+	switch err {
+	case services.ErrInvalidTransferID, services.ErrInvalidTransferStatus, services.ErrSameWarehouse,
+		services.ErrInvalidWarehouseID, services.ErrInvalidItemID, services.ErrInvalidTransferQuantity,
+		services.ErrInvalidReceivedQuantity, services.ErrDuplicateReceiptLine, services.ErrTransferSerialsRequired,
+		services.ErrItemNotSerialTracked:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrTransferNotFound, services.ErrTransferLineNotFound,
+		services.ErrWarehouseNotFound, services.ErrItemNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrTransferNotDraft, services.ErrTransferNotInTransit,
+		services.ErrTransferNotCancelable, services.ErrDuplicateTransferItem:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrTransferEmpty, services.ErrTransferExceedsStock, services.ErrWarehouseInactive,
+		services.ErrItemIsKit, services.ErrSerialNotAtSource, services.ErrTrackedDiscrepancy:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
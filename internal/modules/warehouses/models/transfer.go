@@ -0,0 +1,85 @@
+package warehousemodels
+
+import "time"
+
+// Stock transfer statuses
+const (
+	TransferStatusDraft     = "draft"
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+type StockTransfer struct {
+	TransferID      int        `json:"transfer_id" db:"transfer_id"`
+	TransferNumber  string     `json:"transfer_number" db:"transfer_number"`
+	FromWarehouseID int        `json:"from_warehouse_id" db:"from_warehouse_id"`
+	ToWarehouseID   int        `json:"to_warehouse_id" db:"to_warehouse_id"`
+	Status          string     `json:"status" db:"status"`
+	Notes           *string    `json:"notes,omitempty" db:"notes"`
+	CreatedBy       *string    `json:"created_by,omitempty" db:"created_by"`
+	DispatchedAt    *time.Time `json:"dispatched_at,omitempty" db:"dispatched_at"`
+	DispatchedBy    *string    `json:"dispatched_by,omitempty" db:"dispatched_by"`
+	ReceivedAt      *time.Time `json:"received_at,omitempty" db:"received_at"`
+	ReceivedBy      *string    `json:"received_by,omitempty" db:"received_by"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	FromWarehouseCode string          `json:"from_warehouse_code" db:"from_warehouse_code"`
+	ToWarehouseCode   string          `json:"to_warehouse_code" db:"to_warehouse_code"`
+	LineCount         int             `json:"line_count" db:"line_count"`
+	TotalUnits        int             `json:"total_units" db:"total_units"`
+	Lines             []*TransferLine `json:"lines,omitempty" db:"-"`
+}
+
+type TransferLine struct {
+	LineID           int     `json:"line_id" db:"line_id"`
+	TransferID       int     `json:"transfer_id" db:"transfer_id"`
+	ItemID           int     `json:"item_id" db:"item_id"`
+	Quantity         int     `json:"quantity" db:"quantity"`
+	QuantityReceived *int    `json:"quantity_received,omitempty" db:"quantity_received"`
+	DiscrepancyNote  *string `json:"discrepancy_note,omitempty" db:"discrepancy_note"`
+
+	// One per unit when the item is serial tracked
+	SerialNumbers []string `json:"serial_numbers,omitempty" db:"-"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+	TrackingMode    string `json:"tracking_mode,omitempty" db:"tracking_mode"`
+	Discrepancy     int    `json:"discrepancy" db:"-"` // Received less dispatched, once received
+}
+
+type TransferFilter struct {
+	Status      *string `query:"status"`
+	WarehouseID *int    `query:"warehouse_id"` // Either end of the transfer
+	ItemID      *int    `query:"item_id"`
+}
+
+type CreateTransferRequest struct {
+	FromWarehouseID int                    `json:"from_warehouse_id"`
+	ToWarehouseID   int                    `json:"to_warehouse_id"`
+	Notes           *string                `json:"notes,omitempty"`
+	CreatedBy       *string                `json:"created_by,omitempty"`
+	Lines           []*TransferLineRequest `json:"lines"`
+}
+
+type TransferLineRequest struct {
+	ItemID        int      `json:"item_id"`
+	Quantity      int      `json:"quantity"`
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+}
+
+// ReceiveTransferRequest books a transfer in at the destination. Lines left
+// out are taken as received in full.
+type ReceiveTransferRequest struct {
+	ReceivedBy *string                `json:"received_by,omitempty"`
+	Lines      []*ReceiveTransferLine `json:"lines,omitempty"`
+}
+
+type ReceiveTransferLine struct {
+	LineID           int     `json:"line_id"`
+	QuantityReceived int     `json:"quantity_received"`
+	Note             *string `json:"note,omitempty"`
+}
@@ -0,0 +1,110 @@
+package warehousemodels
+
+import "time"
+
+// Stock movement types
+const (
+	MovementOpening     = "opening"
+	MovementPurchase    = "purchase"
+	MovementSale        = "sale"
+	MovementAdjustment  = "adjustment"
+	MovementTransferOut = "transfer_out"
+	MovementTransferIn  = "transfer_in"
+)
+
+type Warehouse struct {
+	WarehouseID int       `json:"warehouse_id" db:"warehouse_id"`
+	Code        string    `json:"code" db:"code"`
+	Name        string    `json:"name" db:"name"`
+	Address     *string   `json:"address,omitempty" db:"address"`
+	IsDefault   bool      `json:"is_default" db:"is_default"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	Notes       *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	ItemCount  int     `json:"item_count" db:"item_count"`
+	TotalUnits int     `json:"total_units" db:"total_units"`
+	StockValue float64 `json:"stock_value" db:"stock_value"`
+}
+
+type BinLocation struct {
+	BinID       int       `json:"bin_id" db:"bin_id"`
+	WarehouseID int       `json:"warehouse_id" db:"warehouse_id"`
+	Code        string    `json:"code" db:"code"`
+	Aisle       *string   `json:"aisle,omitempty" db:"aisle"`
+	Shelf       *string   `json:"shelf,omitempty" db:"shelf"`
+	Bin         *string   `json:"bin,omitempty" db:"bin"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	ItemCount int `json:"item_count" db:"item_count"`
+}
+
+// LocationStock is the stock of one item at one warehouse
+type LocationStock struct {
+	ItemID        int       `json:"item_id" db:"item_id"`
+	PartNumber    string    `json:"part_number" db:"part_number"`
+	Description   string    `json:"description" db:"description"`
+	WarehouseID   int       `json:"warehouse_id" db:"warehouse_id"`
+	WarehouseCode string    `json:"warehouse_code" db:"warehouse_code"`
+	WarehouseName string    `json:"warehouse_name" db:"warehouse_name"`
+	BinID         *int      `json:"bin_id,omitempty" db:"bin_id"`
+	BinCode       *string   `json:"bin_code,omitempty" db:"bin_code"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ItemStock is an item's stock across all warehouses. TotalStock includes
+// the units in transit between warehouses.
+type ItemStock struct {
+	ItemID       int              `json:"item_id"`
+	PartNumber   string           `json:"part_number"`
+	TotalStock   int              `json:"total_stock"`
+	IsKit        bool             `json:"is_kit"`
+	TrackingMode string           `json:"tracking_mode"`
+	InTransit    int              `json:"in_transit"`
+	Locations    []*LocationStock `json:"locations"`
+}
+
+type StockFilter struct {
+	SearchTerm   *string `query:"search"`
+	BinID        *int    `query:"bin_id"`
+	IncludeEmpty bool    `query:"include_empty"`
+}
+
+type StockMovement struct {
+	MovementID    int       `json:"movement_id" db:"movement_id"`
+	ItemID        int       `json:"item_id" db:"item_id"`
+	WarehouseID   int       `json:"warehouse_id" db:"warehouse_id"`
+	MovementType  string    `json:"movement_type" db:"movement_type"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	BalanceAfter  int       `json:"balance_after" db:"balance_after"`
+	ReferenceID   *int      `json:"reference_id,omitempty" db:"reference_id"`
+	Notes         *string   `json:"notes,omitempty" db:"notes"`
+	MovedAt       time.Time `json:"moved_at" db:"moved_at"`
+	WarehouseCode string    `json:"warehouse_code" db:"warehouse_code"`
+}
+
+type MovementFilter struct {
+	WarehouseID  *int       `query:"warehouse_id"`
+	MovementType *string    `query:"type"`
+	StartDate    *time.Time `query:"start_date"`
+	EndDate      *time.Time `query:"end_date"`
+	Limit        int        `query:"limit"`
+}
+
+// StockAdjustment corrects the stock of an item at one warehouse, e.g. after
+// a count. Quantity is the signed change.
+type StockAdjustment struct {
+	WarehouseID int     `json:"warehouse_id"`
+	Quantity    int     `json:"quantity"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+type BinAssignment struct {
+	BinID *int `json:"bin_id"`
+}
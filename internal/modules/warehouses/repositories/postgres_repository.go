@@ -0,0 +1,520 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresWarehouseRepository struct {
+	db *db.Database
+}
+
+func NewPostgresWarehouseRepository(database *db.Database) WarehouseRepository {
+	return &PostgresWarehouseRepository{
+		db: database,
+	}
+}
+
+// Warehouses with the number of items, units and value of the stock they hold
+const warehouseQuery = `
+	SELECT
+		w.warehouse_id, w.code, w.name, w.address, w.is_default, w.is_active,
+		w.notes, w.created_at, w.updated_at,
+		COUNT(st.item_id) FILTER (WHERE st.quantity > 0)::int as item_count,
+		COALESCE(SUM(st.quantity), 0)::int as total_units,
+		COALESCE(SUM(st.quantity * COALESCE(i.average_cost, i.buy_price)), 0) as stock_value
+	FROM warehouses w
+	LEFT JOIN item_stock st ON st.warehouse_id = w.warehouse_id
+	LEFT JOIN items i ON st.item_id = i.item_id
+`
+
+func scanWarehouse(row pgx.Row) (*warehousemodels.Warehouse, error) {
+	warehouse := &warehousemodels.Warehouse{}
+	err := row.Scan(
+		&warehouse.WarehouseID,
+		&warehouse.Code,
+		&warehouse.Name,
+		&warehouse.Address,
+		&warehouse.IsDefault,
+		&warehouse.IsActive,
+		&warehouse.Notes,
+		&warehouse.CreatedAt,
+		&warehouse.UpdatedAt,
+		&warehouse.ItemCount,
+		&warehouse.TotalUnits,
+		&warehouse.StockValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (r *PostgresWarehouseRepository) GetWarehouses(ctx context.Context, isActive *bool) ([]*warehousemodels.Warehouse, error) {
+	query := warehouseQuery + " WHERE 1=1"
+
+	params := []interface{}{}
+	if isActive != nil {
+		query += " AND w.is_active = $1"
+		params = append(params, *isActive)
+	}
+
+	query += " GROUP BY w.warehouse_id ORDER BY w.is_default DESC, w.code"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []*warehousemodels.Warehouse
+	for rows.Next() {
+		warehouse, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+
+	return warehouses, rows.Err()
+}
+
+func (r *PostgresWarehouseRepository) GetWarehouseByID(ctx context.Context, id int) (*warehousemodels.Warehouse, error) {
+	query := warehouseQuery + " WHERE w.warehouse_id = $1 GROUP BY w.warehouse_id"
+
+	warehouse, err := scanWarehouse(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (r *PostgresWarehouseRepository) GetWarehouseByCode(ctx context.Context, code string) (*warehousemodels.Warehouse, error) {
+	query := warehouseQuery + " WHERE w.code = $1 GROUP BY w.warehouse_id"
+
+	warehouse, err := scanWarehouse(r.db.Pool.QueryRow(ctx, query, code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+// CreateWarehouse inserts the warehouse. A new default warehouse takes over
+// from the previous one.
+func (r *PostgresWarehouseRepository) CreateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if warehouse.IsDefault {
+		if _, err := tx.Exec(ctx, `UPDATE warehouses SET is_default = FALSE WHERE is_default`); err != nil {
+			return 0, err
+		}
+	}
+
+	query := `
+		INSERT INTO warehouses (code, name, address, is_default, is_active, notes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING warehouse_id
+	`
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		warehouse.Code,
+		warehouse.Name,
+		warehouse.Address,
+		warehouse.IsDefault,
+		warehouse.IsActive,
+		warehouse.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateWarehouse saves the warehouse. Making it the default clears the flag
+// on the previous default.
+func (r *PostgresWarehouseRepository) UpdateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if warehouse.IsDefault {
+		_, err := tx.Exec(ctx, `
+			UPDATE warehouses SET is_default = FALSE
+			WHERE is_default AND warehouse_id <> $1
+		`, warehouse.WarehouseID)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE warehouses SET
+			code = $2,
+			name = $3,
+			address = $4,
+			is_default = $5,
+			is_active = $6,
+			notes = $7
+		WHERE warehouse_id = $1
+	`
+
+	result, err := tx.Exec(
+		ctx, query,
+		warehouse.WarehouseID,
+		warehouse.Code,
+		warehouse.Name,
+		warehouse.Address,
+		warehouse.IsDefault,
+		warehouse.IsActive,
+		warehouse.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("warehouse not found")
+	}
+
+	return tx.Commit(ctx)
+}
+
+const binColumns = `
+	b.bin_id, b.warehouse_id, b.code, b.aisle, b.shelf, b.bin, b.is_active,
+	b.created_at, b.updated_at,
+	(SELECT COUNT(*) FROM item_stock st WHERE st.bin_id = b.bin_id)::int as item_count
+`
+
+func scanBin(row pgx.Row) (*warehousemodels.BinLocation, error) {
+	bin := &warehousemodels.BinLocation{}
+	err := row.Scan(
+		&bin.BinID,
+		&bin.WarehouseID,
+		&bin.Code,
+		&bin.Aisle,
+		&bin.Shelf,
+		&bin.Bin,
+		&bin.IsActive,
+		&bin.CreatedAt,
+		&bin.UpdatedAt,
+		&bin.ItemCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return bin, nil
+}
+
+func (r *PostgresWarehouseRepository) GetBins(ctx context.Context, warehouseID int) ([]*warehousemodels.BinLocation, error) {
+	query := `
+		SELECT ` + binColumns + `
+		FROM bin_locations b
+		WHERE b.warehouse_id = $1
+		ORDER BY b.code
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bins []*warehousemodels.BinLocation
+	for rows.Next() {
+		bin, err := scanBin(rows)
+		if err != nil {
+			return nil, err
+		}
+		bins = append(bins, bin)
+	}
+
+	return bins, rows.Err()
+}
+
+func (r *PostgresWarehouseRepository) GetBinByID(ctx context.Context, id int) (*warehousemodels.BinLocation, error) {
+	query := `
+		SELECT ` + binColumns + `
+		FROM bin_locations b
+		WHERE b.bin_id = $1
+	`
+
+	bin, err := scanBin(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return bin, nil
+}
+
+func (r *PostgresWarehouseRepository) CreateBin(ctx context.Context, bin *warehousemodels.BinLocation) (int, error) {
+	query := `
+		INSERT INTO bin_locations (warehouse_id, code, aisle, shelf, bin, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING bin_id
+	`
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		bin.WarehouseID,
+		bin.Code,
+		bin.Aisle,
+		bin.Shelf,
+		bin.Bin,
+		bin.IsActive,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// DeleteBin removes the bin; stock kept in it is left without a bin
+func (r *PostgresWarehouseRepository) DeleteBin(ctx context.Context, warehouseID, binID int) error {
+	query := `DELETE FROM bin_locations WHERE bin_id = $1 AND warehouse_id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, binID, warehouseID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("bin not found")
+	}
+
+	return nil
+}
+
+const locationStockQuery = `
+	SELECT
+		st.item_id, i.part_number, i.description,
+		st.warehouse_id, w.code, w.name,
+		st.bin_id, b.code, st.quantity, st.updated_at
+	FROM item_stock st
+	JOIN items i ON st.item_id = i.item_id
+	JOIN warehouses w ON st.warehouse_id = w.warehouse_id
+	LEFT JOIN bin_locations b ON st.bin_id = b.bin_id
+`
+
+func (r *PostgresWarehouseRepository) queryLocationStock(ctx context.Context, query string, params ...interface{}) ([]*warehousemodels.LocationStock, error) {
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []*warehousemodels.LocationStock
+	for rows.Next() {
+		level := &warehousemodels.LocationStock{}
+		err := rows.Scan(
+			&level.ItemID,
+			&level.PartNumber,
+			&level.Description,
+			&level.WarehouseID,
+			&level.WarehouseCode,
+			&level.WarehouseName,
+			&level.BinID,
+			&level.BinCode,
+			&level.Quantity,
+			&level.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stock = append(stock, level)
+	}
+
+	return stock, rows.Err()
+}
+
+func (r *PostgresWarehouseRepository) GetWarehouseStock(ctx context.Context, warehouseID int, filter *warehousemodels.StockFilter) ([]*warehousemodels.LocationStock, error) {
+	query := locationStockQuery + " WHERE st.warehouse_id = $1"
+
+	params := []interface{}{warehouseID}
+	paramCount := 2
+
+	if filter != nil {
+		if !filter.IncludeEmpty {
+			query += " AND st.quantity > 0"
+		}
+
+		if filter.SearchTerm != nil {
+			query += fmt.Sprintf(" AND (i.part_number ILIKE $%d OR i.description ILIKE $%d)", paramCount, paramCount)
+			params = append(params, "%"+*filter.SearchTerm+"%")
+			paramCount++
+		}
+
+		if filter.BinID != nil {
+			query += fmt.Sprintf(" AND st.bin_id = $%d", paramCount)
+			params = append(params, *filter.BinID)
+			paramCount++
+		}
+	}
+
+	query += " ORDER BY b.code NULLS LAST, i.part_number"
+
+	return r.queryLocationStock(ctx, query, params...)
+}
+
+func (r *PostgresWarehouseRepository) GetItemStock(ctx context.Context, itemID int) (*warehousemodels.ItemStock, error) {
+	stock := &warehousemodels.ItemStock{ItemID: itemID}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT
+			i.part_number, i.current_stock, i.is_kit, i.tracking_mode,
+			COALESCE((
+				SELECT SUM(l.quantity)
+				FROM stock_transfer_lines l
+				JOIN stock_transfers t ON l.transfer_id = t.transfer_id
+				WHERE l.item_id = i.item_id AND t.status = 'in_transit'
+			), 0)::int as in_transit
+		FROM items i
+		WHERE i.item_id = $1
+	`, itemID).Scan(&stock.PartNumber, &stock.TotalStock, &stock.IsKit, &stock.TrackingMode, &stock.InTransit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	stock.Locations, err = r.queryLocationStock(ctx, locationStockQuery+" WHERE st.item_id = $1 ORDER BY w.is_default DESC, w.code", itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+// AssignBin sets the bin an item is kept in at a warehouse, creating an empty
+// stock record if the item has not been held there yet
+func (r *PostgresWarehouseRepository) AssignBin(ctx context.Context, itemID, warehouseID int, binID *int) error {
+	query := `
+		INSERT INTO item_stock (item_id, warehouse_id, bin_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (item_id, warehouse_id) DO UPDATE SET bin_id = EXCLUDED.bin_id
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, itemID, warehouseID, binID)
+	return err
+}
+
+// AdjustStock books the change through adjust_location_stock, which also
+// keeps the cost layers and the item's total in step. Returns the new
+// balance at the warehouse.
+func (r *PostgresWarehouseRepository) AdjustStock(ctx context.Context, itemID int, adjustment *warehousemodels.StockAdjustment) (int, error) {
+	var balance int
+	err := r.db.Pool.QueryRow(
+		ctx, `SELECT adjust_location_stock($1, $2, $3, $4)`,
+		itemID,
+		adjustment.WarehouseID,
+		adjustment.Quantity,
+		adjustment.Notes,
+	).Scan(&balance)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (r *PostgresWarehouseRepository) GetMovements(ctx context.Context, itemID int, filter *warehousemodels.MovementFilter) ([]*warehousemodels.StockMovement, error) {
+	query := `
+		SELECT
+			m.movement_id, m.item_id, m.warehouse_id, m.movement_type, m.quantity,
+			m.balance_after, m.reference_id, m.notes, m.moved_at, w.code
+		FROM stock_movements m
+		JOIN warehouses w ON m.warehouse_id = w.warehouse_id
+		WHERE m.item_id = $1
+	`
+
+	params := []interface{}{itemID}
+	paramCount := 2
+
+	if filter.WarehouseID != nil {
+		query += fmt.Sprintf(" AND m.warehouse_id = $%d", paramCount)
+		params = append(params, *filter.WarehouseID)
+		paramCount++
+	}
+
+	if filter.MovementType != nil {
+		query += fmt.Sprintf(" AND m.movement_type = $%d", paramCount)
+		params = append(params, *filter.MovementType)
+		paramCount++
+	}
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(" AND m.moved_at >= $%d", paramCount)
+		params = append(params, *filter.StartDate)
+		paramCount++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(" AND m.moved_at <= $%d", paramCount)
+		params = append(params, *filter.EndDate)
+		paramCount++
+	}
+
+	query += fmt.Sprintf(" ORDER BY m.moved_at DESC, m.movement_id DESC LIMIT $%d", paramCount)
+	params = append(params, filter.Limit)
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []*warehousemodels.StockMovement
+	for rows.Next() {
+		movement := &warehousemodels.StockMovement{}
+		err := rows.Scan(
+			&movement.MovementID,
+			&movement.ItemID,
+			&movement.WarehouseID,
+			&movement.MovementType,
+			&movement.Quantity,
+			&movement.BalanceAfter,
+			&movement.ReferenceID,
+			&movement.Notes,
+			&movement.MovedAt,
+			&movement.WarehouseCode,
+		)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, rows.Err()
+}
@@ -0,0 +1,504 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresTransferRepository struct {
+	db *db.Database
+}
+
+func NewPostgresTransferRepository(database *db.Database) TransferRepository {
+	return &PostgresTransferRepository{
+		db: database,
+	}
+}
+
+const transferColumns = `
+	t.transfer_id, t.transfer_number, t.from_warehouse_id, t.to_warehouse_id,
+	t.status, t.notes, t.created_by, t.dispatched_at, t.dispatched_by,
+	t.received_at, t.received_by, t.created_at, t.updated_at,
+	fw.code as from_warehouse_code, tw.code as to_warehouse_code,
+	(SELECT COUNT(*) FROM stock_transfer_lines l WHERE l.transfer_id = t.transfer_id)::int as line_count,
+	COALESCE((
+		SELECT SUM(l.quantity)
+		FROM stock_transfer_lines l
+		WHERE l.transfer_id = t.transfer_id
+	), 0)::int as total_units
+`
+
+const transferJoins = `
+	FROM stock_transfers t
+	JOIN warehouses fw ON t.from_warehouse_id = fw.warehouse_id
+	JOIN warehouses tw ON t.to_warehouse_id = tw.warehouse_id
+`
+
+func scanTransfer(row pgx.Row) (*warehousemodels.StockTransfer, error) {
+	transfer := &warehousemodels.StockTransfer{}
+	err := row.Scan(
+		&transfer.TransferID,
+		&transfer.TransferNumber,
+		&transfer.FromWarehouseID,
+		&transfer.ToWarehouseID,
+		&transfer.Status,
+		&transfer.Notes,
+		&transfer.CreatedBy,
+		&transfer.DispatchedAt,
+		&transfer.DispatchedBy,
+		&transfer.ReceivedAt,
+		&transfer.ReceivedBy,
+		&transfer.CreatedAt,
+		&transfer.UpdatedAt,
+		&transfer.FromWarehouseCode,
+		&transfer.ToWarehouseCode,
+		&transfer.LineCount,
+		&transfer.TotalUnits,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (r *PostgresTransferRepository) GetTransfers(ctx context.Context, filter *warehousemodels.TransferFilter) ([]*warehousemodels.StockTransfer, error) {
+	query := "SELECT " + transferColumns + transferJoins + " WHERE 1=1"
+
+	var params []interface{}
+	paramCount := 1
+
+	if filter.Status != nil {
+		query += fmt.Sprintf(" AND t.status = $%d", paramCount)
+		params = append(params, *filter.Status)
+		paramCount++
+	}
+
+	if filter.WarehouseID != nil {
+		query += fmt.Sprintf(" AND (t.from_warehouse_id = $%d OR t.to_warehouse_id = $%d)", paramCount, paramCount)
+		params = append(params, *filter.WarehouseID)
+		paramCount++
+	}
+
+	if filter.ItemID != nil {
+		query += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM stock_transfer_lines l
+			WHERE l.transfer_id = t.transfer_id AND l.item_id = $%d
+		)`, paramCount)
+		params = append(params, *filter.ItemID)
+		paramCount++
+	}
+
+	query += " ORDER BY t.created_at DESC, t.transfer_id DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*warehousemodels.StockTransfer
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, rows.Err()
+}
+
+func (r *PostgresTransferRepository) GetTransferByID(ctx context.Context, id int) (*warehousemodels.StockTransfer, error) {
+	query := "SELECT " + transferColumns + transferJoins + " WHERE t.transfer_id = $1"
+
+	transfer, err := scanTransfer(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (r *PostgresTransferRepository) GetTransferLines(ctx context.Context, transferID int) ([]*warehousemodels.TransferLine, error) {
+	query := `
+		SELECT
+			l.line_id, l.transfer_id, l.item_id, l.quantity, l.quantity_received,
+			l.discrepancy_note, i.part_number, i.description, i.tracking_mode,
+			ARRAY(
+				SELECT sn.serial_number
+				FROM stock_transfer_serials ts
+				JOIN item_serials sn ON ts.serial_id = sn.serial_id
+				WHERE ts.line_id = l.line_id
+				ORDER BY sn.serial_number
+			)
+		FROM stock_transfer_lines l
+		JOIN items i ON l.item_id = i.item_id
+		WHERE l.transfer_id = $1
+		ORDER BY l.line_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*warehousemodels.TransferLine
+	for rows.Next() {
+		line := &warehousemodels.TransferLine{}
+		err := rows.Scan(
+			&line.LineID,
+			&line.TransferID,
+			&line.ItemID,
+			&line.Quantity,
+			&line.QuantityReceived,
+			&line.DiscrepancyNote,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+			&line.TrackingMode,
+			&line.SerialNumbers,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if line.QuantityReceived != nil {
+			line.Discrepancy = *line.QuantityReceived - line.Quantity
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// CreateTransfer writes a draft transfer with its lines and returns its ID.
+// The transfer number is derived from the ID.
+func (r *PostgresTransferRepository) CreateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `SELECT nextval('stock_transfer_id_seq')`).Scan(&transfer.TransferID)
+	if err != nil {
+		return 0, err
+	}
+	transfer.TransferNumber = fmt.Sprintf("TR-%06d", transfer.TransferID)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO stock_transfers (
+			transfer_id, transfer_number, from_warehouse_id, to_warehouse_id,
+			status, notes, created_by
+		) VALUES ($1, $2, $3, $4, 'draft', $5, $6)
+	`,
+		transfer.TransferID,
+		transfer.TransferNumber,
+		transfer.FromWarehouseID,
+		transfer.ToWarehouseID,
+		transfer.Notes,
+		transfer.CreatedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range transfer.Lines {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO stock_transfer_lines (transfer_id, item_id, quantity)
+			VALUES ($1, $2, $3)
+			RETURNING line_id
+		`, transfer.TransferID, line.ItemID, line.Quantity).Scan(&line.LineID)
+		if err != nil {
+			return 0, err
+		}
+
+		if err := saveLineSerials(ctx, tx, line); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return transfer.TransferID, nil
+}
+
+func (r *PostgresTransferRepository) AddLine(ctx context.Context, line *warehousemodels.TransferLine) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO stock_transfer_lines (transfer_id, item_id, quantity)
+		VALUES ($1, $2, $3)
+		RETURNING line_id
+	`
+
+	err = tx.QueryRow(ctx, query, line.TransferID, line.ItemID, line.Quantity).Scan(&line.LineID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := saveLineSerials(ctx, tx, line); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return line.LineID, nil
+}
+
+func (r *PostgresTransferRepository) UpdateLine(ctx context.Context, line *warehousemodels.TransferLine) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE stock_transfer_lines SET quantity = $3
+		WHERE line_id = $1 AND transfer_id = $2
+	`
+
+	result, err := tx.Exec(ctx, query, line.LineID, line.TransferID, line.Quantity)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("transfer line not found")
+	}
+
+	if err := saveLineSerials(ctx, tx, line); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresTransferRepository) RemoveLine(ctx context.Context, transferID, lineID int) error {
+	query := `DELETE FROM stock_transfer_lines WHERE line_id = $1 AND transfer_id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, lineID, transferID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("transfer line not found")
+	}
+
+	return nil
+}
+
+// Dispatch takes every line out of the source warehouse. The status changes
+// first so the item totals keep counting the units while they are in
+// transit.
+func (r *PostgresTransferRepository) Dispatch(ctx context.Context, transfer *warehousemodels.StockTransfer, dispatchedBy *string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	result, err := tx.Exec(ctx, `
+		UPDATE stock_transfers SET
+			status = 'in_transit',
+			dispatched_at = $2,
+			dispatched_by = $3
+		WHERE transfer_id = $1 AND status = 'draft'
+	`, transfer.TransferID, now, dispatchedBy)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("transfer is not a draft")
+	}
+
+	notes := fmt.Sprintf("%s to %s", transfer.TransferNumber, transfer.ToWarehouseCode)
+	for _, line := range transfer.Lines {
+		_, err := tx.Exec(ctx, `SELECT apply_stock_movement($1, $2, $3, 'transfer_out', $4, $5, $6)`,
+			line.ItemID, transfer.FromWarehouseID, -line.Quantity, transfer.TransferID, notes, now)
+		if err != nil {
+			return err
+		}
+
+		// Serial numbered units belong to no warehouse while in transit
+		result, err := tx.Exec(ctx, `
+			UPDATE item_serials SET warehouse_id = NULL
+			WHERE serial_id IN (SELECT serial_id FROM stock_transfer_serials WHERE line_id = $1)
+				AND warehouse_id = $2 AND status = 'in_stock'
+		`, line.LineID, transfer.FromWarehouseID)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() != int64(len(line.SerialNumbers)) {
+			return errors.New("serial number is no longer in stock at the source warehouse")
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Receive books every line in at the destination in full and then corrects
+// any line that arrived short or over with a stock adjustment, so the
+// discrepancy is costed and shows in the movement history. received holds
+// an entry for every line.
+func (r *PostgresTransferRepository) Receive(ctx context.Context, transfer *warehousemodels.StockTransfer, received map[int]*warehousemodels.ReceiveTransferLine, receivedBy *string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	result, err := tx.Exec(ctx, `
+		UPDATE stock_transfers SET
+			status = 'received',
+			received_at = $2,
+			received_by = $3
+		WHERE transfer_id = $1 AND status = 'in_transit'
+	`, transfer.TransferID, now, receivedBy)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("transfer is not in transit")
+	}
+
+	notes := fmt.Sprintf("%s from %s", transfer.TransferNumber, transfer.FromWarehouseCode)
+	for _, line := range transfer.Lines {
+		receipt, ok := received[line.LineID]
+		if !ok {
+			return fmt.Errorf("no receipt for line %d", line.LineID)
+		}
+
+		_, err := tx.Exec(ctx, `
+			UPDATE stock_transfer_lines SET quantity_received = $2, discrepancy_note = $3
+			WHERE line_id = $1
+		`, line.LineID, receipt.QuantityReceived, receipt.Note)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `SELECT apply_stock_movement($1, $2, $3, 'transfer_in', $4, $5, $6)`,
+			line.ItemID, transfer.ToWarehouseID, line.Quantity, transfer.TransferID, notes, now)
+		if err != nil {
+			return err
+		}
+
+		if err := moveLineSerials(ctx, tx, line.LineID, transfer.ToWarehouseID); err != nil {
+			return err
+		}
+
+		if difference := receipt.QuantityReceived - line.Quantity; difference != 0 {
+			adjustment := fmt.Sprintf("%s: received %d of %d", transfer.TransferNumber, receipt.QuantityReceived, line.Quantity)
+			if receipt.Note != nil {
+				adjustment += " - " + *receipt.Note
+			}
+			_, err := tx.Exec(ctx, `SELECT adjust_location_stock($1, $2, $3, $4)`,
+				line.ItemID, transfer.ToWarehouseID, difference, adjustment)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Cancel closes a draft, or returns the stock of a transfer in transit to
+// the source warehouse
+func (r *PostgresTransferRepository) Cancel(ctx context.Context, transfer *warehousemodels.StockTransfer) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE stock_transfers SET status = 'cancelled'
+		WHERE transfer_id = $1 AND status = $2
+	`, transfer.TransferID, transfer.Status)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("transfer cannot be cancelled")
+	}
+
+	if transfer.Status == warehousemodels.TransferStatusInTransit {
+		notes := fmt.Sprintf("%s cancelled, returned from transit", transfer.TransferNumber)
+		for _, line := range transfer.Lines {
+			_, err := tx.Exec(ctx, `SELECT apply_stock_movement($1, $2, $3, 'transfer_in', $4, $5, CURRENT_TIMESTAMP)`,
+				line.ItemID, transfer.FromWarehouseID, line.Quantity, transfer.TransferID, notes)
+			if err != nil {
+				return err
+			}
+
+			if err := moveLineSerials(ctx, tx, line.LineID, transfer.FromWarehouseID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetInStockSerials returns those of the serial numbers that are in stock
+// for the item at the warehouse
+func (r *PostgresTransferRepository) GetInStockSerials(ctx context.Context, itemID, warehouseID int, serialNumbers []string) ([]string, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT serial_number FROM item_serials
+		WHERE item_id = $1 AND warehouse_id = $2 AND serial_number = ANY($3::text[])
+			AND status = 'in_stock'
+	`, itemID, warehouseID, serialNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// saveLineSerials replaces the serial numbered units listed on a line
+func saveLineSerials(ctx context.Context, tx pgx.Tx, line *warehousemodels.TransferLine) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM stock_transfer_serials WHERE line_id = $1`, line.LineID); err != nil {
+		return err
+	}
+	if len(line.SerialNumbers) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO stock_transfer_serials (line_id, serial_id)
+		SELECT $1, serial_id
+		FROM item_serials
+		WHERE item_id = $2 AND serial_number = ANY($3::text[])
+	`, line.LineID, line.ItemID, line.SerialNumbers)
+	return err
+}
+
+// moveLineSerials books the serial numbered units on a line in at a
+// warehouse
+func moveLineSerials(ctx context.Context, tx pgx.Tx, lineID, warehouseID int) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE item_serials SET warehouse_id = $2
+		WHERE serial_id IN (SELECT serial_id FROM stock_transfer_serials WHERE line_id = $1)
+	`, lineID, warehouseID)
+	return err
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+)
+
+type WarehouseRepository interface {
+	// Warehouse operations
+	GetWarehouses(ctx context.Context, isActive *bool) ([]*warehousemodels.Warehouse, error)
+	GetWarehouseByID(ctx context.Context, id int) (*warehousemodels.Warehouse, error)
+	GetWarehouseByCode(ctx context.Context, code string) (*warehousemodels.Warehouse, error)
+	CreateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) (int, error)
+	UpdateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) error
+
+	// Bin operations
+	GetBins(ctx context.Context, warehouseID int) ([]*warehousemodels.BinLocation, error)
+	GetBinByID(ctx context.Context, id int) (*warehousemodels.BinLocation, error)
+	CreateBin(ctx context.Context, bin *warehousemodels.BinLocation) (int, error)
+	DeleteBin(ctx context.Context, warehouseID, binID int) error
+
+	// Stock operations
+	GetWarehouseStock(ctx context.Context, warehouseID int, filter *warehousemodels.StockFilter) ([]*warehousemodels.LocationStock, error)
+	GetItemStock(ctx context.Context, itemID int) (*warehousemodels.ItemStock, error)
+	AssignBin(ctx context.Context, itemID, warehouseID int, binID *int) error
+	AdjustStock(ctx context.Context, itemID int, adjustment *warehousemodels.StockAdjustment) (int, error)
+	GetMovements(ctx context.Context, itemID int, filter *warehousemodels.MovementFilter) ([]*warehousemodels.StockMovement, error)
+}
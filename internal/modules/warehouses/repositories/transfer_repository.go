@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+)
+
+type TransferRepository interface {
+	GetTransfers(ctx context.Context, filter *warehousemodels.TransferFilter) ([]*warehousemodels.StockTransfer, error)
+	GetTransferByID(ctx context.Context, id int) (*warehousemodels.StockTransfer, error)
+	GetTransferLines(ctx context.Context, transferID int) ([]*warehousemodels.TransferLine, error)
+	CreateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) (int, error)
+	AddLine(ctx context.Context, line *warehousemodels.TransferLine) (int, error)
+	UpdateLine(ctx context.Context, line *warehousemodels.TransferLine) error
+	RemoveLine(ctx context.Context, transferID, lineID int) error
+	Dispatch(ctx context.Context, transfer *warehousemodels.StockTransfer, dispatchedBy *string) error
+	Receive(ctx context.Context, transfer *warehousemodels.StockTransfer, received map[int]*warehousemodels.ReceiveTransferLine, receivedBy *string) error
+	Cancel(ctx context.Context, transfer *warehousemodels.StockTransfer) error
+	GetInStockSerials(ctx context.Context, itemID, warehouseID int, serialNumbers []string) ([]string, error)
+}
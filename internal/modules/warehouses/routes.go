@@ -0,0 +1,52 @@
+package warehouses
+
+import (
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/services"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repository
+	repo := repositories.NewPostgresWarehouseRepository(database)
+	transferRepo := repositories.NewPostgresTransferRepository(database)
+
+	// Initialize service
+	service := services.NewWarehouseService(repo)
+	transferService := services.NewTransferService(transferRepo, repo)
+
+	// Initialize handler
+	handler := handlers.NewWarehouseHandler(service)
+	transferHandler := handlers.NewTransferHandler(transferService)
+
+	// Register routes
+	warehouses := api.Group("/warehouses")
+	warehouses.GET("", handler.GetWarehouses)
+	warehouses.POST("", handler.CreateWarehouse)
+	warehouses.GET("/:id", handler.GetWarehouseByID)
+	warehouses.PUT("/:id", handler.UpdateWarehouse)
+	warehouses.GET("/:id/bins", handler.GetBins)
+	warehouses.POST("/:id/bins", handler.CreateBin)
+	warehouses.DELETE("/:id/bins/:binId", handler.DeleteBin)
+	warehouses.GET("/:id/stock", handler.GetWarehouseStock)
+
+	items := api.Group("/items")
+	items.GET("/:id/stock", handler.GetItemStock)
+	items.POST("/:id/stock/adjustments", handler.AdjustStock)
+	items.PUT("/:id/stock/:warehouseId/bin", handler.AssignBin)
+	items.GET("/:id/movements", handler.GetMovements)
+
+	// Stock transfer routes
+	transfers := api.Group("/transfers")
+	transfers.GET("", transferHandler.GetTransfers)
+	transfers.POST("", transferHandler.CreateTransfer)
+	transfers.GET("/:id", transferHandler.GetTransferByID)
+	transfers.POST("/:id/lines", transferHandler.AddTransferLine)
+	transfers.PUT("/:id/lines/:lineId", transferHandler.UpdateTransferLine)
+	transfers.DELETE("/:id/lines/:lineId", transferHandler.RemoveTransferLine)
+	transfers.POST("/:id/dispatch", transferHandler.DispatchTransfer)
+	transfers.POST("/:id/receive", transferHandler.ReceiveTransfer)
+	transfers.POST("/:id/cancel", transferHandler.CancelTransfer)
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/repositories"
+)
+
+const (
+	DefaultMovementLimit = 100
+	maxMovementLimit     = 1000
+)
+
+var (
+	ErrWarehouseNotFound     = errors.New("warehouse not found")
+	ErrInvalidWarehouseID    = errors.New("invalid warehouse ID")
+	ErrWarehouseCodeRequired = errors.New("warehouse code is required")
+	ErrWarehouseNameRequired = errors.New("warehouse name is required")
+	ErrDuplicateWarehouse    = errors.New("warehouse code already exists")
+	ErrWarehouseInactive     = errors.New("warehouse is inactive")
+	ErrDefaultInactive       = errors.New("the default warehouse must be active")
+	ErrDefaultRequired       = errors.New("make another warehouse the default instead")
+	ErrWarehouseHasStock     = errors.New("warehouse still holds stock")
+	ErrBinNotFound           = errors.New("bin not found")
+	ErrInvalidBinID          = errors.New("invalid bin ID")
+	ErrBinCodeRequired       = errors.New("bin code or aisle, shelf and bin are required")
+	ErrDuplicateBin          = errors.New("bin code already exists in this warehouse")
+	ErrItemNotFound          = errors.New("item not found")
+	ErrInvalidItemID         = errors.New("invalid item ID")
+	ErrItemIsKit             = errors.New("kits hold no stock; move their components instead")
+	ErrTrackedStock          = errors.New("stock of a serial or lot tracked item only changes through receipts and sales")
+	ErrInvalidQuantity       = errors.New("quantity change cannot be zero")
+	ErrInsufficientStock     = errors.New("adjustment would take the warehouse's stock below zero")
+	ErrInvalidMovementType   = errors.New("type must be opening, purchase, sale, adjustment, transfer_out or transfer_in")
+	ErrInvalidLimit          = errors.New("limit must be between 1 and 1000")
+)
+
+type WarehouseService interface {
+	// Warehouse operations
+	GetWarehouses(ctx context.Context, isActive *bool) ([]*warehousemodels.Warehouse, error)
+	GetWarehouseByID(ctx context.Context, id int) (*warehousemodels.Warehouse, error)
+	CreateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) (int, error)
+	UpdateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) error
+
+	// Bin operations
+	GetBins(ctx context.Context, warehouseID int) ([]*warehousemodels.BinLocation, error)
+	CreateBin(ctx context.Context, bin *warehousemodels.BinLocation) (int, error)
+	DeleteBin(ctx context.Context, warehouseID, binID int) error
+
+	// Stock operations
+	GetWarehouseStock(ctx context.Context, warehouseID int, filter *warehousemodels.StockFilter) ([]*warehousemodels.LocationStock, error)
+	GetItemStock(ctx context.Context, itemID int) (*warehousemodels.ItemStock, error)
+	AssignBin(ctx context.Context, itemID, warehouseID int, binID *int) error
+	AdjustStock(ctx context.Context, itemID int, adjustment *warehousemodels.StockAdjustment) (*warehousemodels.ItemStock, error)
+	GetMovements(ctx context.Context, itemID int, filter *warehousemodels.MovementFilter) ([]*warehousemodels.StockMovement, error)
+}
+
+type warehouseService struct {
+	repo repositories.WarehouseRepository
+}
+
+func NewWarehouseService(repo repositories.WarehouseRepository) WarehouseService {
+	return &warehouseService{
+		repo: repo,
+	}
+}
+
+// Warehouse operations
+func (s *warehouseService) GetWarehouses(ctx context.Context, isActive *bool) ([]*warehousemodels.Warehouse, error) {
+	warehouses, err := s.repo.GetWarehouses(ctx, isActive)
+	if err != nil {
+		return nil, err
+	}
+	if warehouses == nil {
+		warehouses = []*warehousemodels.Warehouse{}
+	}
+	return warehouses, nil
+}
+
+func (s *warehouseService) GetWarehouseByID(ctx context.Context, id int) (*warehousemodels.Warehouse, error) {
+	if id <= 0 {
+		return nil, ErrInvalidWarehouseID
+	}
+
+	warehouse, err := s.repo.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if warehouse == nil {
+		return nil, ErrWarehouseNotFound
+	}
+
+	return warehouse, nil
+}
+
+func (s *warehouseService) CreateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) (int, error) {
+	if err := validateWarehouse(warehouse); err != nil {
+		return 0, err
+	}
+
+	existing, err := s.repo.GetWarehouseByCode(ctx, warehouse.Code)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil {
+		return 0, ErrDuplicateWarehouse
+	}
+
+	return s.repo.CreateWarehouse(ctx, warehouse)
+}
+
+// UpdateWarehouse saves the warehouse. The default warehouse can only be
+// replaced by making another one the default, and a warehouse holding stock
+// cannot be deactivated.
+func (s *warehouseService) UpdateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) error {
+	if err := validateWarehouse(warehouse); err != nil {
+		return err
+	}
+
+	current, err := s.GetWarehouseByID(ctx, warehouse.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	if current.IsDefault && !warehouse.IsDefault {
+		return ErrDefaultRequired
+	}
+	if current.IsActive && !warehouse.IsActive && current.TotalUnits > 0 {
+		return ErrWarehouseHasStock
+	}
+
+	if warehouse.Code != current.Code {
+		existing, err := s.repo.GetWarehouseByCode(ctx, warehouse.Code)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrDuplicateWarehouse
+		}
+	}
+
+	return s.repo.UpdateWarehouse(ctx, warehouse)
+}
+
+// Bin operations
+func (s *warehouseService) GetBins(ctx context.Context, warehouseID int) ([]*warehousemodels.BinLocation, error) {
+	if _, err := s.GetWarehouseByID(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+
+	bins, err := s.repo.GetBins(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if bins == nil {
+		bins = []*warehousemodels.BinLocation{}
+	}
+	return bins, nil
+}
+
+// CreateBin adds a bin to a warehouse. Without a code, one is built from the
+// aisle, shelf and bin in the same format as the item locations.
+func (s *warehouseService) CreateBin(ctx context.Context, bin *warehousemodels.BinLocation) (int, error) {
+	if _, err := s.GetWarehouseByID(ctx, bin.WarehouseID); err != nil {
+		return 0, err
+	}
+
+	bin.Code = strings.TrimSpace(bin.Code)
+	if bin.Code == "" {
+		if isBlank(bin.Aisle) || isBlank(bin.Shelf) || isBlank(bin.Bin) {
+			return 0, ErrBinCodeRequired
+		}
+		bin.Code = strings.TrimSpace(*bin.Aisle) + "-" + strings.TrimSpace(*bin.Shelf) + "-" + strings.TrimSpace(*bin.Bin)
+	}
+
+	bins, err := s.repo.GetBins(ctx, bin.WarehouseID)
+	if err != nil {
+		return 0, err
+	}
+	for _, existing := range bins {
+		if strings.EqualFold(existing.Code, bin.Code) {
+			return 0, ErrDuplicateBin
+		}
+	}
+
+	bin.IsActive = true
+	return s.repo.CreateBin(ctx, bin)
+}
+
+func (s *warehouseService) DeleteBin(ctx context.Context, warehouseID, binID int) error {
+	if binID <= 0 {
+		return ErrInvalidBinID
+	}
+
+	bin, err := s.repo.GetBinByID(ctx, binID)
+	if err != nil {
+		return err
+	}
+	if bin == nil || bin.WarehouseID != warehouseID {
+		return ErrBinNotFound
+	}
+
+	return s.repo.DeleteBin(ctx, warehouseID, binID)
+}
+
+// Stock operations
+func (s *warehouseService) GetWarehouseStock(ctx context.Context, warehouseID int, filter *warehousemodels.StockFilter) ([]*warehousemodels.LocationStock, error) {
+	if _, err := s.GetWarehouseByID(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+
+	stock, err := s.repo.GetWarehouseStock(ctx, warehouseID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if stock == nil {
+		stock = []*warehousemodels.LocationStock{}
+	}
+	return stock, nil
+}
+
+func (s *warehouseService) GetItemStock(ctx context.Context, itemID int) (*warehousemodels.ItemStock, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	stock, err := s.repo.GetItemStock(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if stock == nil {
+		return nil, ErrItemNotFound
+	}
+	if stock.Locations == nil {
+		stock.Locations = []*warehousemodels.LocationStock{}
+	}
+
+	return stock, nil
+}
+
+// AssignBin sets or clears the bin an item is kept in at a warehouse
+func (s *warehouseService) AssignBin(ctx context.Context, itemID, warehouseID int, binID *int) error {
+	if _, err := s.GetItemStock(ctx, itemID); err != nil {
+		return err
+	}
+	if _, err := s.GetWarehouseByID(ctx, warehouseID); err != nil {
+		return err
+	}
+
+	if binID != nil {
+		bin, err := s.repo.GetBinByID(ctx, *binID)
+		if err != nil {
+			return err
+		}
+		if bin == nil || bin.WarehouseID != warehouseID {
+			return ErrBinNotFound
+		}
+	}
+
+	return s.repo.AssignBin(ctx, itemID, warehouseID, binID)
+}
+
+// AdjustStock corrects an item's stock at one warehouse and returns its
+// updated stock across all warehouses
+func (s *warehouseService) AdjustStock(ctx context.Context, itemID int, adjustment *warehousemodels.StockAdjustment) (*warehousemodels.ItemStock, error) {
+	if adjustment.Quantity == 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	warehouse, err := s.GetWarehouseByID(ctx, adjustment.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if !warehouse.IsActive {
+		return nil, ErrWarehouseInactive
+	}
+
+	stock, err := s.GetItemStock(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if stock.IsKit {
+		return nil, ErrItemIsKit
+	}
+	if stock.TrackingMode != "none" {
+		return nil, ErrTrackedStock
+	}
+
+	onHand := 0
+	for _, location := range stock.Locations {
+		if location.WarehouseID == adjustment.WarehouseID {
+			onHand = location.Quantity
+		}
+	}
+	if onHand+adjustment.Quantity < 0 {
+		return nil, ErrInsufficientStock
+	}
+
+	if _, err := s.repo.AdjustStock(ctx, itemID, adjustment); err != nil {
+		return nil, err
+	}
+
+	return s.GetItemStock(ctx, itemID)
+}
+
+func (s *warehouseService) GetMovements(ctx context.Context, itemID int, filter *warehousemodels.MovementFilter) ([]*warehousemodels.StockMovement, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	if filter.MovementType != nil {
+		switch *filter.MovementType {
+		case warehousemodels.MovementOpening, warehousemodels.MovementPurchase,
+			warehousemodels.MovementSale, warehousemodels.MovementAdjustment,
+			warehousemodels.MovementTransferOut, warehousemodels.MovementTransferIn:
+		default:
+			return nil, ErrInvalidMovementType
+		}
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultMovementLimit
+	}
+	if filter.Limit < 1 || filter.Limit > maxMovementLimit {
+		return nil, ErrInvalidLimit
+	}
+
+	movements, err := s.repo.GetMovements(ctx, itemID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if movements == nil {
+		movements = []*warehousemodels.StockMovement{}
+	}
+	return movements, nil
+}
+
+// Helper functions
+
+func validateWarehouse(warehouse *warehousemodels.Warehouse) error {
+	warehouse.Code = strings.ToUpper(strings.TrimSpace(warehouse.Code))
+	warehouse.Name = strings.TrimSpace(warehouse.Name)
+
+	if warehouse.Code == "" {
+		return ErrWarehouseCodeRequired
+	}
+	if warehouse.Name == "" {
+		return ErrWarehouseNameRequired
+	}
+	if warehouse.IsDefault && !warehouse.IsActive {
+		return ErrDefaultInactive
+	}
+	return nil
+}
+
+func isBlank(value *string) bool {
+	return value == nil || strings.TrimSpace(*value) == ""
+}
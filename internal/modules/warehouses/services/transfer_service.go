@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/repositories"
+)
+
+var (
+	ErrTransferNotFound        = errors.New("transfer not found")
+	ErrInvalidTransferID       = errors.New("invalid transfer ID")
+	ErrTransferLineNotFound    = errors.New("transfer line not found")
+	ErrInvalidTransferStatus   = errors.New("status must be draft, in_transit, received or cancelled")
+	ErrSameWarehouse           = errors.New("source and destination warehouses must differ")
+	ErrTransferEmpty           = errors.New("transfer has no lines")
+	ErrTransferNotDraft        = errors.New("only draft transfers can be changed or dispatched")
+	ErrTransferNotInTransit    = errors.New("only transfers in transit can be received")
+	ErrTransferNotCancelable   = errors.New("transfer is already received or cancelled")
+	ErrDuplicateTransferItem   = errors.New("item is already on this transfer")
+	ErrInvalidTransferQuantity = errors.New("quantity must be greater than zero")
+	ErrInvalidReceivedQuantity = errors.New("received quantity cannot be negative")
+	ErrDuplicateReceiptLine    = errors.New("line is listed more than once")
+	ErrTransferExceedsStock    = errors.New("not enough stock at the source warehouse")
+	ErrTransferSerialsRequired = errors.New("one serial number is required per unit of a serial tracked item")
+	ErrItemNotSerialTracked    = errors.New("item is not serial tracked")
+	ErrSerialNotAtSource       = errors.New("serial number is not in stock at the source warehouse")
+	ErrTrackedDiscrepancy      = errors.New("serial or lot tracked lines must be received in full")
+)
+
+type TransferService interface {
+	GetTransfers(ctx context.Context, filter *warehousemodels.TransferFilter) ([]*warehousemodels.StockTransfer, error)
+	GetTransferByID(ctx context.Context, id int) (*warehousemodels.StockTransfer, error)
+	CreateTransfer(ctx context.Context, req *warehousemodels.CreateTransferRequest) (*warehousemodels.StockTransfer, error)
+	AddLine(ctx context.Context, line *warehousemodels.TransferLine) error
+	UpdateLine(ctx context.Context, line *warehousemodels.TransferLine) error
+	RemoveLine(ctx context.Context, transferID, lineID int) error
+	Dispatch(ctx context.Context, id int, dispatchedBy *string) (*warehousemodels.StockTransfer, error)
+	Receive(ctx context.Context, id int, req *warehousemodels.ReceiveTransferRequest) (*warehousemodels.StockTransfer, error)
+	Cancel(ctx context.Context, id int) (*warehousemodels.StockTransfer, error)
+}
+
+type transferService struct {
+	repo          repositories.TransferRepository
+	warehouseRepo repositories.WarehouseRepository
+}
+
+func NewTransferService(repo repositories.TransferRepository, warehouseRepo repositories.WarehouseRepository) TransferService {
+	return &transferService{
+		repo:          repo,
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+func (s *transferService) GetTransfers(ctx context.Context, filter *warehousemodels.TransferFilter) ([]*warehousemodels.StockTransfer, error) {
+	if filter.Status != nil {
+		switch *filter.Status {
+		case warehousemodels.TransferStatusDraft, warehousemodels.TransferStatusInTransit,
+			warehousemodels.TransferStatusReceived, warehousemodels.TransferStatusCancelled:
+		default:
+			return nil, ErrInvalidTransferStatus
+		}
+	}
+
+	transfers, err := s.repo.GetTransfers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if transfers == nil {
+		transfers = []*warehousemodels.StockTransfer{}
+	}
+	return transfers, nil
+}
+
+func (s *transferService) GetTransferByID(ctx context.Context, id int) (*warehousemodels.StockTransfer, error) {
+	if id <= 0 {
+		return nil, ErrInvalidTransferID
+	}
+
+	transfer, err := s.repo.GetTransferByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, ErrTransferNotFound
+	}
+
+	transfer.Lines, err = s.repo.GetTransferLines(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Lines == nil {
+		transfer.Lines = []*warehousemodels.TransferLine{}
+	}
+
+	return transfer, nil
+}
+
+// CreateTransfer opens a draft transfer between two active warehouses.
+// Stock is only checked when the transfer is dispatched.
+func (s *transferService) CreateTransfer(ctx context.Context, req *warehousemodels.CreateTransferRequest) (*warehousemodels.StockTransfer, error) {
+	if req.FromWarehouseID == req.ToWarehouseID {
+		return nil, ErrSameWarehouse
+	}
+	if err := s.checkWarehouse(ctx, req.FromWarehouseID); err != nil {
+		return nil, err
+	}
+	if err := s.checkWarehouse(ctx, req.ToWarehouseID); err != nil {
+		return nil, err
+	}
+
+	transfer := &warehousemodels.StockTransfer{
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		Notes:           req.Notes,
+		CreatedBy:       req.CreatedBy,
+	}
+
+	seen := make(map[int]bool)
+	for _, requested := range req.Lines {
+		line := &warehousemodels.TransferLine{
+			ItemID:        requested.ItemID,
+			Quantity:      requested.Quantity,
+			SerialNumbers: requested.SerialNumbers,
+		}
+		if err := s.validateLine(ctx, line, req.FromWarehouseID); err != nil {
+			return nil, err
+		}
+		if seen[line.ItemID] {
+			return nil, ErrDuplicateTransferItem
+		}
+		seen[line.ItemID] = true
+		transfer.Lines = append(transfer.Lines, line)
+	}
+
+	id, err := s.repo.CreateTransfer(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
+
+func (s *transferService) AddLine(ctx context.Context, line *warehousemodels.TransferLine) error {
+	transfer, err := s.getDraft(ctx, line.TransferID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateLine(ctx, line, transfer.FromWarehouseID); err != nil {
+		return err
+	}
+	for _, existing := range transfer.Lines {
+		if existing.ItemID == line.ItemID {
+			return ErrDuplicateTransferItem
+		}
+	}
+
+	id, err := s.repo.AddLine(ctx, line)
+	if err != nil {
+		return err
+	}
+
+	line.LineID = id
+	return nil
+}
+
+func (s *transferService) UpdateLine(ctx context.Context, line *warehousemodels.TransferLine) error {
+	if line.Quantity <= 0 {
+		return ErrInvalidTransferQuantity
+	}
+
+	transfer, err := s.getDraft(ctx, line.TransferID)
+	if err != nil {
+		return err
+	}
+	existing := findTransferLine(transfer.Lines, line.LineID)
+	if existing == nil {
+		return ErrTransferLineNotFound
+	}
+
+	line.ItemID = existing.ItemID
+	if err := s.validateLine(ctx, line, transfer.FromWarehouseID); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateLine(ctx, line)
+}
+
+func (s *transferService) RemoveLine(ctx context.Context, transferID, lineID int) error {
+	transfer, err := s.getDraft(ctx, transferID)
+	if err != nil {
+		return err
+	}
+	if findTransferLine(transfer.Lines, lineID) == nil {
+		return ErrTransferLineNotFound
+	}
+
+	return s.repo.RemoveLine(ctx, transferID, lineID)
+}
+
+// Dispatch takes the stock out of the source warehouse and puts it in
+// transit. Every line must be covered by the stock held at the source.
+func (s *transferService) Dispatch(ctx context.Context, id int, dispatchedBy *string) (*warehousemodels.StockTransfer, error) {
+	transfer, err := s.getDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(transfer.Lines) == 0 {
+		return nil, ErrTransferEmpty
+	}
+	if err := s.checkWarehouse(ctx, transfer.FromWarehouseID); err != nil {
+		return nil, err
+	}
+	if err := s.checkWarehouse(ctx, transfer.ToWarehouseID); err != nil {
+		return nil, err
+	}
+
+	for _, line := range transfer.Lines {
+		stock, err := s.warehouseRepo.GetItemStock(ctx, line.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		if stock == nil {
+			return nil, ErrItemNotFound
+		}
+		if stock.IsKit {
+			return nil, ErrItemIsKit
+		}
+
+		onHand := 0
+		for _, location := range stock.Locations {
+			if location.WarehouseID == transfer.FromWarehouseID {
+				onHand = location.Quantity
+			}
+		}
+		if onHand < line.Quantity {
+			return nil, ErrTransferExceedsStock
+		}
+
+		// The units listed may have been sold or moved since
+		if err := s.checkSerials(ctx, line, stock.TrackingMode, transfer.FromWarehouseID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Dispatch(ctx, transfer, dispatchedBy); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
+
+// Receive books a transfer in at the destination. Lines not listed in the
+// request are taken as received in full; a line received short or over is
+// adjusted at the destination and keeps the note as the discrepancy.
+func (s *transferService) Receive(ctx context.Context, id int, req *warehousemodels.ReceiveTransferRequest) (*warehousemodels.StockTransfer, error) {
+	transfer, err := s.GetTransferByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != warehousemodels.TransferStatusInTransit {
+		return nil, ErrTransferNotInTransit
+	}
+
+	received := make(map[int]*warehousemodels.ReceiveTransferLine)
+	for _, receipt := range req.Lines {
+		line := findTransferLine(transfer.Lines, receipt.LineID)
+		if line == nil {
+			return nil, ErrTransferLineNotFound
+		}
+		if receipt.QuantityReceived < 0 {
+			return nil, ErrInvalidReceivedQuantity
+		}
+		if _, ok := received[receipt.LineID]; ok {
+			return nil, ErrDuplicateReceiptLine
+		}
+
+		// Every unit of a tracked item is accounted for by its serial number
+		// or lot, so a shortage cannot be written off here
+		if line.TrackingMode != "none" && receipt.QuantityReceived != line.Quantity {
+			return nil, ErrTrackedDiscrepancy
+		}
+		received[receipt.LineID] = receipt
+	}
+
+	for _, line := range transfer.Lines {
+		if _, ok := received[line.LineID]; !ok {
+			received[line.LineID] = &warehousemodels.ReceiveTransferLine{
+				LineID:           line.LineID,
+				QuantityReceived: line.Quantity,
+			}
+		}
+	}
+
+	if err := s.repo.Receive(ctx, transfer, received, req.ReceivedBy); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
+
+// Cancel closes a draft or in-transit transfer. Stock already dispatched
+// goes back to the source warehouse.
+func (s *transferService) Cancel(ctx context.Context, id int) (*warehousemodels.StockTransfer, error) {
+	transfer, err := s.GetTransferByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch transfer.Status {
+	case warehousemodels.TransferStatusReceived, warehousemodels.TransferStatusCancelled:
+		return nil, ErrTransferNotCancelable
+	}
+
+	if err := s.repo.Cancel(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
+
+// Helper functions
+
+func (s *transferService) getDraft(ctx context.Context, id int) (*warehousemodels.StockTransfer, error) {
+	transfer, err := s.GetTransferByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != warehousemodels.TransferStatusDraft {
+		return nil, ErrTransferNotDraft
+	}
+	return transfer, nil
+}
+
+func (s *transferService) checkWarehouse(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidWarehouseID
+	}
+
+	warehouse, err := s.warehouseRepo.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if warehouse == nil {
+		return ErrWarehouseNotFound
+	}
+	if !warehouse.IsActive {
+		return ErrWarehouseInactive
+	}
+	return nil
+}
+
+func (s *transferService) validateLine(ctx context.Context, line *warehousemodels.TransferLine, fromWarehouseID int) error {
+	if line.ItemID <= 0 {
+		return ErrInvalidItemID
+	}
+	if line.Quantity <= 0 {
+		return ErrInvalidTransferQuantity
+	}
+
+	stock, err := s.warehouseRepo.GetItemStock(ctx, line.ItemID)
+	if err != nil {
+		return err
+	}
+	if stock == nil {
+		return ErrItemNotFound
+	}
+	if stock.IsKit {
+		return ErrItemIsKit
+	}
+	return s.checkSerials(ctx, line, stock.TrackingMode, fromWarehouseID)
+}
+
+// checkSerials makes sure a line of a serial tracked item lists one serial
+// number per unit, each in stock at the source warehouse
+func (s *transferService) checkSerials(ctx context.Context, line *warehousemodels.TransferLine, trackingMode string, fromWarehouseID int) error {
+	if trackingMode != "serial" {
+		if len(line.SerialNumbers) > 0 {
+			return ErrItemNotSerialTracked
+		}
+		return nil
+	}
+	if len(line.SerialNumbers) != line.Quantity {
+		return ErrTransferSerialsRequired
+	}
+
+	seen := make(map[string]bool)
+	for i, serial := range line.SerialNumbers {
+		serial = strings.TrimSpace(serial)
+		if serial == "" || seen[serial] {
+			return ErrTransferSerialsRequired
+		}
+		seen[serial] = true
+		line.SerialNumbers[i] = serial
+	}
+
+	inStock, err := s.repo.GetInStockSerials(ctx, line.ItemID, fromWarehouseID, line.SerialNumbers)
+	if err != nil {
+		return err
+	}
+	if len(inStock) != len(line.SerialNumbers) {
+		return ErrSerialNotAtSource
+	}
+	return nil
+}
+
+func findTransferLine(lines []*warehousemodels.TransferLine, lineID int) *warehousemodels.TransferLine {
+	for _, line := range lines {
+		if line.LineID == lineID {
+			return line
+		}
+	}
+	return nil
+}
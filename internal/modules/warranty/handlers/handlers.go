@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	warrantymodels "github.com/hsrvms/autoparts/internal/modules/warranty/models"
+	"github.com/hsrvms/autoparts/internal/modules/warranty/services"
+	"github.com/labstack/echo/v4"
+)
+
+type WarrantyHandler struct {
+	service services.WarrantyService
+}
+
+func NewWarrantyHandler(service services.WarrantyService) *WarrantyHandler {
+	return &WarrantyHandler{
+		service: service,
+	}
+}
+
+// GetSaleWarranty handles checking whether a past sale is still under warranty
+func (h *WarrantyHandler) GetSaleWarranty(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sale ID")
+	}
+
+	ctx := c.Request().Context()
+	status, err := h.service.GetSaleWarranty(ctx, id)
+	if err != nil {
+		return warrantyError(err)
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+// GetSerialWarranty handles checking the warranty of a serial numbered unit
+func (h *WarrantyHandler) GetSerialWarranty(c echo.Context) error {
+	ctx := c.Request().Context()
+	statuses, err := h.service.GetSerialWarranty(ctx, c.Param("serialNumber"))
+	if err != nil {
+		return warrantyError(err)
+	}
+
+	return c.JSON(http.StatusOK, statuses)
+}
+
+// GetClaims handles retrieval of warranty claims with optional filtering
+func (h *WarrantyHandler) GetClaims(c echo.Context) error {
+	filter := &warrantymodels.ClaimFilter{}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	if saleID, err := strconv.Atoi(c.QueryParam("sale_id")); err == nil {
+		filter.SaleID = &saleID
+	}
+
+	if itemID, err := strconv.Atoi(c.QueryParam("item_id")); err == nil {
+		filter.ItemID = &itemID
+	}
+
+	if supplierID, err := strconv.Atoi(c.QueryParam("supplier_id")); err == nil {
+		filter.SupplierID = &supplierID
+	}
+
+	if customerID, err := strconv.Atoi(c.QueryParam("customer_id")); err == nil {
+		filter.CustomerID = &customerID
+	}
+
+	if serialNumber := c.QueryParam("serial_number"); serialNumber != "" {
+		filter.SerialNumber = &serialNumber
+	}
+
+	ctx := c.Request().Context()
+	claims, err := h.service.GetAllClaims(ctx, filter)
+	if err != nil {
+		return warrantyError(err)
+	}
+
+	return c.JSON(http.StatusOK, claims)
+}
+
+// GetClaimByID handles retrieval of a single warranty claim
+func (h *WarrantyHandler) GetClaimByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warranty claim ID")
+	}
+
+	ctx := c.Request().Context()
+	claim, err := h.service.GetClaimByID(ctx, id)
+	if err != nil {
+		return warrantyError(err)
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// CreateClaim handles recording a part returned by a customer as faulty
+func (h *WarrantyHandler) CreateClaim(c echo.Context) error {
+	claim := new(warrantymodels.Claim)
+	if err := c.Bind(claim); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.CreateClaim(ctx, claim)
+	if err != nil {
+		return warrantyError(err)
+	}
+
+	created, err := h.service.GetClaimByID(ctx, id)
+	if err != nil {
+		return warrantyError(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// SendClaimToSupplier handles returning a claimed part under a supplier RMA
+func (h *WarrantyHandler) SendClaimToSupplier(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warranty claim ID")
+	}
+
+	req := new(warrantymodels.RMARequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	claim, err := h.service.SendToSupplier(ctx, id, req)
+	if err != nil {
+		return warrantyError(err)
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// ResolveClaim handles closing a claim with its outcome
+func (h *WarrantyHandler) ResolveClaim(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warranty claim ID")
+	}
+
+	req := new(warrantymodels.ResolveRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	claim, err := h.service.ResolveClaim(ctx, id, req)
+	if err != nil {
+		return warrantyError(err)
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+func warrantyError(err error) error {
+	switch err {
+	case services.ErrInvalidClaimID, services.ErrInvalidStatus, services.ErrFaultRequired,
+		services.ErrInvalidQuantity, services.ErrSerialNumberRequired, services.ErrRMANumberRequired,
+		services.ErrInvalidOutcome, services.ErrInvalidSupplierOutcome, services.ErrRefundNotAllowed,
+		services.ErrInvalidRefundAmount:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrClaimNotFound, services.ErrSaleNotFound, services.ErrSerialNotSold,
+		services.ErrSupplierNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrSerialAlreadyClaimed, services.ErrClaimNotOpen, services.ErrClaimResolved:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrQuantityExceedsSale, services.ErrSerialNotOnSale, services.ErrSupplierRequired,
+		services.ErrSupplierOutcomeNotSent:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,104 @@
+package warrantymodels
+
+import "time"
+
+// Claim statuses
+const (
+	ClaimStatusOpen         = "open"
+	ClaimStatusWithSupplier = "with_supplier"
+	ClaimStatusResolved     = "resolved"
+)
+
+// Outcomes of a claim for the customer
+const (
+	OutcomeReplaced = "replaced"
+	OutcomeRefunded = "refunded"
+	OutcomeRejected = "rejected"
+)
+
+// Supplier responses to an RMA
+const (
+	SupplierReplaced = "replaced"
+	SupplierCredited = "credited"
+	SupplierRejected = "rejected"
+)
+
+// WarrantyStatus answers whether a past sale is still under warranty. The
+// expiry is fixed from the item's warranty when the sale is made.
+type WarrantyStatus struct {
+	SaleID            int        `json:"sale_id"`
+	TransactionNumber string     `json:"transaction_number"`
+	SaleDate          time.Time  `json:"sale_date"`
+	ItemID            int        `json:"item_id"`
+	ItemPartNumber    string     `json:"item_part_number"`
+	ItemDescription   string     `json:"item_description"`
+	Quantity          int        `json:"quantity"`
+	PricePerUnit      float64    `json:"price_per_unit"`
+	CustomerID        *int       `json:"customer_id,omitempty"`
+	CustomerName      *string    `json:"customer_name,omitempty"`
+	CustomerPhone     *string    `json:"customer_phone,omitempty"`
+	SerialNumbers     []string   `json:"serial_numbers,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"` // Nil when the item had no warranty
+	UnderWarranty     bool       `json:"under_warranty"`
+	DaysRemaining     int        `json:"days_remaining"`
+	QuantityClaimed   int        `json:"quantity_claimed"` // On claims not rejected
+}
+
+type Claim struct {
+	ClaimID          int        `json:"claim_id" db:"claim_id"`
+	ClaimNumber      string     `json:"claim_number" db:"claim_number"`
+	SaleID           int        `json:"sale_id" db:"sale_id"`
+	ItemID           int        `json:"item_id" db:"item_id"`
+	SerialNumber     *string    `json:"serial_number,omitempty" db:"serial_number"`
+	Quantity         int        `json:"quantity" db:"quantity"`
+	CustomerID       *int       `json:"customer_id,omitempty" db:"customer_id"`
+	CustomerName     *string    `json:"customer_name,omitempty" db:"customer_name"`
+	CustomerPhone    *string    `json:"customer_phone,omitempty" db:"customer_phone"`
+	FaultDescription string     `json:"fault_description" db:"fault_description"`
+	WithinWarranty   bool       `json:"within_warranty" db:"within_warranty"`
+	Status           string     `json:"status" db:"status"`
+	SupplierID       *int       `json:"supplier_id,omitempty" db:"supplier_id"`
+	RMANumber        *string    `json:"rma_number,omitempty" db:"rma_number"`
+	SentToSupplierAt *time.Time `json:"sent_to_supplier_at,omitempty" db:"sent_to_supplier_at"`
+	SupplierOutcome  *string    `json:"supplier_outcome,omitempty" db:"supplier_outcome"`
+	Outcome          *string    `json:"outcome,omitempty" db:"outcome"`
+	RefundAmount     *float64   `json:"refund_amount,omitempty" db:"refund_amount"`
+	ResolutionNotes  *string    `json:"resolution_notes,omitempty" db:"resolution_notes"`
+	ReceivedBy       *string    `json:"received_by,omitempty" db:"received_by"`
+	ResolvedBy       *string    `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	TransactionNumber string  `json:"transaction_number,omitempty" db:"transaction_number"`
+	ItemPartNumber    string  `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription   string  `json:"item_description,omitempty" db:"item_description"`
+	SupplierName      *string `json:"supplier_name,omitempty" db:"supplier_name"`
+}
+
+type ClaimFilter struct {
+	Status       *string `query:"status"`
+	SaleID       *int    `query:"sale_id"`
+	ItemID       *int    `query:"item_id"`
+	SupplierID   *int    `query:"supplier_id"`
+	CustomerID   *int    `query:"customer_id"`
+	SerialNumber *string `query:"serial_number"`
+}
+
+// RMARequest sends a claimed part back to the supplier. The supplier
+// defaults to the one the unit was bought from, then the item's supplier.
+type RMARequest struct {
+	SupplierID *int   `json:"supplier_id,omitempty"`
+	RMANumber  string `json:"rma_number"`
+}
+
+// ResolveRequest closes a claim with its outcome for the customer, and the
+// supplier's response when the part went back under an RMA
+type ResolveRequest struct {
+	Outcome         string   `json:"outcome"`
+	RefundAmount    *float64 `json:"refund_amount,omitempty"` // Refunded claims only
+	SupplierOutcome *string  `json:"supplier_outcome,omitempty"`
+	Notes           *string  `json:"notes,omitempty"`
+	ResolvedBy      *string  `json:"resolved_by,omitempty"`
+}
@@ -0,0 +1,364 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	warrantymodels "github.com/hsrvms/autoparts/internal/modules/warranty/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresWarrantyRepository struct {
+	db *db.Database
+}
+
+func NewPostgresWarrantyRepository(database *db.Database) WarrantyRepository {
+	return &PostgresWarrantyRepository{
+		db: database,
+	}
+}
+
+const claimQuery = `
+	SELECT
+		wc.claim_id, wc.claim_number, wc.sale_id, wc.item_id, wc.serial_number,
+		wc.quantity, wc.customer_id, wc.customer_name, wc.customer_phone,
+		wc.fault_description, wc.within_warranty, wc.status, wc.supplier_id,
+		wc.rma_number, wc.sent_to_supplier_at, wc.supplier_outcome, wc.outcome,
+		wc.refund_amount, wc.resolution_notes, wc.received_by, wc.resolved_by,
+		wc.resolved_at, wc.created_at, wc.updated_at,
+		COALESCE(s.transaction_number, ''),
+		i.part_number as item_part_number,
+		i.description as item_description,
+		su.name as supplier_name
+	FROM warranty_claims wc
+	JOIN sales s ON wc.sale_id = s.sale_id
+	JOIN items i ON wc.item_id = i.item_id
+	LEFT JOIN suppliers su ON wc.supplier_id = su.supplier_id
+`
+
+func scanClaim(row pgx.Row) (*warrantymodels.Claim, error) {
+	claim := &warrantymodels.Claim{}
+	err := row.Scan(
+		&claim.ClaimID,
+		&claim.ClaimNumber,
+		&claim.SaleID,
+		&claim.ItemID,
+		&claim.SerialNumber,
+		&claim.Quantity,
+		&claim.CustomerID,
+		&claim.CustomerName,
+		&claim.CustomerPhone,
+		&claim.FaultDescription,
+		&claim.WithinWarranty,
+		&claim.Status,
+		&claim.SupplierID,
+		&claim.RMANumber,
+		&claim.SentToSupplierAt,
+		&claim.SupplierOutcome,
+		&claim.Outcome,
+		&claim.RefundAmount,
+		&claim.ResolutionNotes,
+		&claim.ReceivedBy,
+		&claim.ResolvedBy,
+		&claim.ResolvedAt,
+		&claim.CreatedAt,
+		&claim.UpdatedAt,
+		&claim.TransactionNumber,
+		&claim.ItemPartNumber,
+		&claim.ItemDescription,
+		&claim.SupplierName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// GetSaleWarranty reads a sale with its warranty expiry, the serial numbers
+// sold on it and how much of it is already under claim
+func (r *PostgresWarrantyRepository) GetSaleWarranty(ctx context.Context, saleID int) (*warrantymodels.WarrantyStatus, error) {
+	query := `
+		SELECT
+			s.sale_id, COALESCE(s.transaction_number, ''), s.date, s.item_id,
+			i.part_number, i.description, s.quantity, s.price_per_unit,
+			s.customer_id, COALESCE(cu.name, s.customer_name),
+			COALESCE(s.customer_phone, cu.phone), s.warranty_expires_at,
+			COALESCE(s.warranty_expires_at > CURRENT_TIMESTAMP, FALSE),
+			COALESCE(GREATEST(s.warranty_expires_at::date - CURRENT_DATE, 0), 0),
+			(SELECT COALESCE(SUM(wc.quantity), 0) FROM warranty_claims wc
+			 WHERE wc.sale_id = s.sale_id AND wc.outcome IS DISTINCT FROM 'rejected')
+		FROM sales s
+		JOIN items i ON s.item_id = i.item_id
+		LEFT JOIN customers cu ON s.customer_id = cu.customer_id
+		WHERE s.sale_id = $1
+	`
+
+	status := &warrantymodels.WarrantyStatus{}
+	err := r.db.Pool.QueryRow(ctx, query, saleID).Scan(
+		&status.SaleID,
+		&status.TransactionNumber,
+		&status.SaleDate,
+		&status.ItemID,
+		&status.ItemPartNumber,
+		&status.ItemDescription,
+		&status.Quantity,
+		&status.PricePerUnit,
+		&status.CustomerID,
+		&status.CustomerName,
+		&status.CustomerPhone,
+		&status.ExpiresAt,
+		&status.UnderWarranty,
+		&status.DaysRemaining,
+		&status.QuantityClaimed,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT serial_number FROM item_serials WHERE sale_id = $1 ORDER BY serial_number
+	`, saleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var serialNumber string
+		if err := rows.Scan(&serialNumber); err != nil {
+			return nil, err
+		}
+		status.SerialNumbers = append(status.SerialNumbers, serialNumber)
+	}
+
+	return status, rows.Err()
+}
+
+// GetSerialSaleIDs finds the sales a serial number went out on, across all
+// items as the same number may be used by different manufacturers
+func (r *PostgresWarrantyRepository) GetSerialSaleIDs(ctx context.Context, serialNumber string) ([]int, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT sale_id FROM item_serials
+		WHERE serial_number = $1 AND sale_id IS NOT NULL
+		ORDER BY sold_at DESC
+	`, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var saleIDs []int
+	for rows.Next() {
+		var saleID int
+		if err := rows.Scan(&saleID); err != nil {
+			return nil, err
+		}
+		saleIDs = append(saleIDs, saleID)
+	}
+
+	return saleIDs, rows.Err()
+}
+
+func (r *PostgresWarrantyRepository) GetAllClaims(ctx context.Context, filter *warrantymodels.ClaimFilter) ([]*warrantymodels.Claim, error) {
+	query := claimQuery + " WHERE 1=1"
+
+	var params []interface{}
+	paramCount := 1
+
+	if filter.Status != nil {
+		query += fmt.Sprintf(" AND wc.status = $%d", paramCount)
+		params = append(params, *filter.Status)
+		paramCount++
+	}
+
+	if filter.SaleID != nil {
+		query += fmt.Sprintf(" AND wc.sale_id = $%d", paramCount)
+		params = append(params, *filter.SaleID)
+		paramCount++
+	}
+
+	if filter.ItemID != nil {
+		query += fmt.Sprintf(" AND wc.item_id = $%d", paramCount)
+		params = append(params, *filter.ItemID)
+		paramCount++
+	}
+
+	if filter.SupplierID != nil {
+		query += fmt.Sprintf(" AND wc.supplier_id = $%d", paramCount)
+		params = append(params, *filter.SupplierID)
+		paramCount++
+	}
+
+	if filter.CustomerID != nil {
+		query += fmt.Sprintf(" AND wc.customer_id = $%d", paramCount)
+		params = append(params, *filter.CustomerID)
+		paramCount++
+	}
+
+	if filter.SerialNumber != nil {
+		query += fmt.Sprintf(" AND wc.serial_number = $%d", paramCount)
+		params = append(params, *filter.SerialNumber)
+		paramCount++
+	}
+
+	query += " ORDER BY wc.created_at DESC, wc.claim_id DESC"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []*warrantymodels.Claim
+	for rows.Next() {
+		claim, err := scanClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, claim)
+	}
+
+	return claims, rows.Err()
+}
+
+func (r *PostgresWarrantyRepository) GetClaimByID(ctx context.Context, id int) (*warrantymodels.Claim, error) {
+	claim, err := scanClaim(r.db.Pool.QueryRow(ctx, claimQuery+" WHERE wc.claim_id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return claim, nil
+}
+
+func (r *PostgresWarrantyRepository) CreateClaim(ctx context.Context, claim *warrantymodels.Claim) (int, error) {
+	var id int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT nextval('warranty_claim_id_seq')`).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO warranty_claims (
+			claim_id, claim_number, sale_id, item_id, serial_number, quantity,
+			customer_id, customer_name, customer_phone, fault_description,
+			within_warranty, received_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.Pool.Exec(
+		ctx, query,
+		id,
+		fmt.Sprintf("WC-%06d", id),
+		claim.SaleID,
+		claim.ItemID,
+		claim.SerialNumber,
+		claim.Quantity,
+		claim.CustomerID,
+		claim.CustomerName,
+		claim.CustomerPhone,
+		claim.FaultDescription,
+		claim.WithinWarranty,
+		claim.ReceivedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresWarrantyRepository) SendToSupplier(ctx context.Context, id int, supplierID int, rmaNumber string) error {
+	query := `
+		UPDATE warranty_claims SET
+			status = 'with_supplier',
+			supplier_id = $2,
+			rma_number = $3,
+			sent_to_supplier_at = CURRENT_TIMESTAMP
+		WHERE claim_id = $1 AND status = 'open'
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, supplierID, rmaNumber)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("warranty claim is not open")
+	}
+
+	return nil
+}
+
+func (r *PostgresWarrantyRepository) ResolveClaim(ctx context.Context, id int, req *warrantymodels.ResolveRequest) error {
+	query := `
+		UPDATE warranty_claims SET
+			status = 'resolved',
+			outcome = $2,
+			refund_amount = $3,
+			supplier_outcome = $4,
+			resolution_notes = $5,
+			resolved_by = $6,
+			resolved_at = CURRENT_TIMESTAMP
+		WHERE claim_id = $1 AND status <> 'resolved'
+	`
+
+	result, err := r.db.Pool.Exec(
+		ctx, query,
+		id,
+		req.Outcome,
+		req.RefundAmount,
+		req.SupplierOutcome,
+		req.Notes,
+		req.ResolvedBy,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("warranty claim is already resolved")
+	}
+
+	return nil
+}
+
+// CountOpenSerialClaims counts the claims on a serial numbered unit that are
+// still in progress or ended with the unit being replaced or refunded
+func (r *PostgresWarrantyRepository) CountOpenSerialClaims(ctx context.Context, itemID int, serialNumber string) (int, error) {
+	var count int
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM warranty_claims
+		WHERE item_id = $1 AND serial_number = $2 AND outcome IS DISTINCT FROM 'rejected'
+	`, itemID, serialNumber).Scan(&count)
+	return count, err
+}
+
+// GetDefaultSupplier finds who to return a claimed part to: the supplier of
+// the purchase the serial numbered unit came in on, or the item's supplier
+func (r *PostgresWarrantyRepository) GetDefaultSupplier(ctx context.Context, claim *warrantymodels.Claim) (*int, error) {
+	query := `
+		SELECT COALESCE(
+			(SELECT p.supplier_id FROM item_serials sn
+			 JOIN purchases p ON sn.purchase_id = p.purchase_id
+			 WHERE sn.item_id = $1 AND sn.serial_number = $2),
+			(SELECT supplier_id FROM items WHERE item_id = $1)
+		)
+	`
+
+	var supplierID *int
+	err := r.db.Pool.QueryRow(ctx, query, claim.ItemID, claim.SerialNumber).Scan(&supplierID)
+	return supplierID, err
+}
+
+func (r *PostgresWarrantyRepository) SupplierExists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM suppliers WHERE supplier_id = $1)`, id).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	warrantymodels "github.com/hsrvms/autoparts/internal/modules/warranty/models"
+)
+
+type WarrantyRepository interface {
+	GetSaleWarranty(ctx context.Context, saleID int) (*warrantymodels.WarrantyStatus, error)
+	GetSerialSaleIDs(ctx context.Context, serialNumber string) ([]int, error)
+
+	GetAllClaims(ctx context.Context, filter *warrantymodels.ClaimFilter) ([]*warrantymodels.Claim, error)
+	GetClaimByID(ctx context.Context, id int) (*warrantymodels.Claim, error)
+	CreateClaim(ctx context.Context, claim *warrantymodels.Claim) (int, error)
+	SendToSupplier(ctx context.Context, id int, supplierID int, rmaNumber string) error
+	ResolveClaim(ctx context.Context, id int, req *warrantymodels.ResolveRequest) error
+
+	CountOpenSerialClaims(ctx context.Context, itemID int, serialNumber string) (int, error)
+	GetDefaultSupplier(ctx context.Context, claim *warrantymodels.Claim) (*int, error)
+	SupplierExists(ctx context.Context, id int) (bool, error)
+}
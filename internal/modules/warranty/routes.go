@@ -0,0 +1,31 @@
+package warranty
+
+import (
+	"github.com/hsrvms/autoparts/internal/modules/warranty/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/warranty/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/warranty/services"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repository
+	repo := repositories.NewPostgresWarrantyRepository(database)
+
+	// Initialize service
+	service := services.NewWarrantyService(repo)
+
+	// Initialize handler
+	handler := handlers.NewWarrantyHandler(service)
+
+	// Register routes
+	api.GET("/sales/:id/warranty", handler.GetSaleWarranty)
+	api.GET("/serials/:serialNumber/warranty", handler.GetSerialWarranty)
+
+	claims := api.Group("/warranty-claims")
+	claims.GET("", handler.GetClaims)
+	claims.POST("", handler.CreateClaim)
+	claims.GET("/:id", handler.GetClaimByID)
+	claims.POST("/:id/rma", handler.SendClaimToSupplier)
+	claims.POST("/:id/resolve", handler.ResolveClaim)
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	warrantymodels "github.com/hsrvms/autoparts/internal/modules/warranty/models"
+	"github.com/hsrvms/autoparts/internal/modules/warranty/repositories"
+)
+
+var (
+	ErrClaimNotFound          = errors.New("warranty claim not found")
+	ErrInvalidClaimID         = errors.New("invalid warranty claim ID")
+	ErrInvalidStatus          = errors.New("status must be open, with_supplier or resolved")
+	ErrSaleNotFound           = errors.New("sale not found")
+	ErrSerialNotSold          = errors.New("serial number has not been sold")
+	ErrFaultRequired          = errors.New("fault description is required")
+	ErrInvalidQuantity        = errors.New("quantity must be greater than zero")
+	ErrQuantityExceedsSale    = errors.New("quantity exceeds the units sold that are not already under claim")
+	ErrSerialNumberRequired   = errors.New("serial number is required for serial tracked items")
+	ErrSerialNotOnSale        = errors.New("serial number was not sold on this sale")
+	ErrSerialAlreadyClaimed   = errors.New("serial number is already under claim")
+	ErrRMANumberRequired      = errors.New("RMA number is required")
+	ErrSupplierRequired       = errors.New("supplier is required to send the claim back")
+	ErrSupplierNotFound       = errors.New("supplier not found")
+	ErrClaimNotOpen           = errors.New("only open claims can be sent to the supplier")
+	ErrClaimResolved          = errors.New("warranty claim is already resolved")
+	ErrInvalidOutcome         = errors.New("outcome must be replaced, refunded or rejected")
+	ErrInvalidSupplierOutcome = errors.New("supplier outcome must be replaced, credited or rejected")
+	ErrSupplierOutcomeNotSent = errors.New("supplier outcome can only be recorded for claims sent to the supplier")
+	ErrRefundNotAllowed       = errors.New("refund amount is only allowed for refunded claims")
+	ErrInvalidRefundAmount    = errors.New("refund amount must be greater than zero and not exceed the price paid")
+)
+
+type WarrantyService interface {
+	GetSaleWarranty(ctx context.Context, saleID int) (*warrantymodels.WarrantyStatus, error)
+	GetSerialWarranty(ctx context.Context, serialNumber string) ([]*warrantymodels.WarrantyStatus, error)
+
+	GetAllClaims(ctx context.Context, filter *warrantymodels.ClaimFilter) ([]*warrantymodels.Claim, error)
+	GetClaimByID(ctx context.Context, id int) (*warrantymodels.Claim, error)
+	CreateClaim(ctx context.Context, claim *warrantymodels.Claim) (int, error)
+	SendToSupplier(ctx context.Context, id int, req *warrantymodels.RMARequest) (*warrantymodels.Claim, error)
+	ResolveClaim(ctx context.Context, id int, req *warrantymodels.ResolveRequest) (*warrantymodels.Claim, error)
+}
+
+type warrantyService struct {
+	repo repositories.WarrantyRepository
+}
+
+func NewWarrantyService(repo repositories.WarrantyRepository) WarrantyService {
+	return &warrantyService{
+		repo: repo,
+	}
+}
+
+func (s *warrantyService) GetSaleWarranty(ctx context.Context, saleID int) (*warrantymodels.WarrantyStatus, error) {
+	status, err := s.repo.GetSaleWarranty(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, ErrSaleNotFound
+	}
+
+	return status, nil
+}
+
+// GetSerialWarranty returns the warranty of each sale a serial number went
+// out on, most recent first
+func (s *warrantyService) GetSerialWarranty(ctx context.Context, serialNumber string) ([]*warrantymodels.WarrantyStatus, error) {
+	saleIDs, err := s.repo.GetSerialSaleIDs(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(saleIDs) == 0 {
+		return nil, ErrSerialNotSold
+	}
+
+	statuses := make([]*warrantymodels.WarrantyStatus, 0, len(saleIDs))
+	for _, saleID := range saleIDs {
+		status, err := s.GetSaleWarranty(ctx, saleID)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (s *warrantyService) GetAllClaims(ctx context.Context, filter *warrantymodels.ClaimFilter) ([]*warrantymodels.Claim, error) {
+	if filter.Status != nil && !isValidStatus(*filter.Status) {
+		return nil, ErrInvalidStatus
+	}
+
+	claims, err := s.repo.GetAllClaims(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if claims == nil {
+		claims = []*warrantymodels.Claim{}
+	}
+
+	return claims, nil
+}
+
+func (s *warrantyService) GetClaimByID(ctx context.Context, id int) (*warrantymodels.Claim, error) {
+	if id <= 0 {
+		return nil, ErrInvalidClaimID
+	}
+
+	claim, err := s.repo.GetClaimByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return nil, ErrClaimNotFound
+	}
+
+	return claim, nil
+}
+
+// CreateClaim records a part brought back by the customer against the sale
+// it was bought on. Claims outside warranty are still recorded, marked as
+// such, so goodwill replacements have a paper trail.
+func (s *warrantyService) CreateClaim(ctx context.Context, claim *warrantymodels.Claim) (int, error) {
+	claim.FaultDescription = strings.TrimSpace(claim.FaultDescription)
+	if claim.FaultDescription == "" {
+		return 0, ErrFaultRequired
+	}
+
+	if claim.Quantity == 0 {
+		claim.Quantity = 1
+	}
+	if claim.Quantity < 0 {
+		return 0, ErrInvalidQuantity
+	}
+
+	sale, err := s.repo.GetSaleWarranty(ctx, claim.SaleID)
+	if err != nil {
+		return 0, err
+	}
+	if sale == nil {
+		return 0, ErrSaleNotFound
+	}
+
+	if claim.Quantity > sale.Quantity-sale.QuantityClaimed {
+		return 0, ErrQuantityExceedsSale
+	}
+
+	if err := s.checkSerialNumber(ctx, claim, sale); err != nil {
+		return 0, err
+	}
+
+	claim.ItemID = sale.ItemID
+	claim.CustomerID = sale.CustomerID
+	claim.CustomerName = sale.CustomerName
+	claim.CustomerPhone = sale.CustomerPhone
+	claim.WithinWarranty = sale.UnderWarranty
+
+	return s.repo.CreateClaim(ctx, claim)
+}
+
+// SendToSupplier records the part going back to the supplier under their
+// RMA number
+func (s *warrantyService) SendToSupplier(ctx context.Context, id int, req *warrantymodels.RMARequest) (*warrantymodels.Claim, error) {
+	claim, err := s.GetClaimByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Status != warrantymodels.ClaimStatusOpen {
+		return nil, ErrClaimNotOpen
+	}
+
+	req.RMANumber = strings.TrimSpace(req.RMANumber)
+	if req.RMANumber == "" {
+		return nil, ErrRMANumberRequired
+	}
+
+	supplierID := req.SupplierID
+	if supplierID == nil {
+		supplierID, err = s.repo.GetDefaultSupplier(ctx, claim)
+		if err != nil {
+			return nil, err
+		}
+		if supplierID == nil {
+			return nil, ErrSupplierRequired
+		}
+	} else {
+		exists, err := s.repo.SupplierExists(ctx, *supplierID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrSupplierNotFound
+		}
+	}
+
+	if err := s.repo.SendToSupplier(ctx, id, *supplierID, req.RMANumber); err != nil {
+		return nil, err
+	}
+
+	return s.GetClaimByID(ctx, id)
+}
+
+// ResolveClaim closes a claim with what the customer got: a replacement, a
+// refund or nothing
+func (s *warrantyService) ResolveClaim(ctx context.Context, id int, req *warrantymodels.ResolveRequest) (*warrantymodels.Claim, error) {
+	claim, err := s.GetClaimByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Status == warrantymodels.ClaimStatusResolved {
+		return nil, ErrClaimResolved
+	}
+
+	if err := s.validateResolution(ctx, claim, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ResolveClaim(ctx, id, req); err != nil {
+		return nil, err
+	}
+
+	return s.GetClaimByID(ctx, id)
+}
+
+// Helper functions
+
+// checkSerialNumber requires claims on serial tracked sales to name the unit,
+// which must be one sold on the sale and not already under claim
+func (s *warrantyService) checkSerialNumber(ctx context.Context, claim *warrantymodels.Claim, sale *warrantymodels.WarrantyStatus) error {
+	if claim.SerialNumber != nil {
+		serialNumber := strings.TrimSpace(*claim.SerialNumber)
+		if serialNumber == "" {
+			claim.SerialNumber = nil
+		} else {
+			claim.SerialNumber = &serialNumber
+		}
+	}
+
+	if len(sale.SerialNumbers) == 0 {
+		claim.SerialNumber = nil
+		return nil
+	}
+
+	if claim.SerialNumber == nil {
+		return ErrSerialNumberRequired
+	}
+	if claim.Quantity != 1 {
+		return ErrInvalidQuantity
+	}
+
+	found := false
+	for _, serialNumber := range sale.SerialNumbers {
+		if serialNumber == *claim.SerialNumber {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrSerialNotOnSale
+	}
+
+	count, err := s.repo.CountOpenSerialClaims(ctx, sale.ItemID, *claim.SerialNumber)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSerialAlreadyClaimed
+	}
+
+	return nil
+}
+
+func (s *warrantyService) validateResolution(ctx context.Context, claim *warrantymodels.Claim, req *warrantymodels.ResolveRequest) error {
+	switch req.Outcome {
+	case warrantymodels.OutcomeReplaced, warrantymodels.OutcomeRejected:
+		if req.RefundAmount != nil {
+			return ErrRefundNotAllowed
+		}
+	case warrantymodels.OutcomeRefunded:
+		sale, err := s.repo.GetSaleWarranty(ctx, claim.SaleID)
+		if err != nil {
+			return err
+		}
+		if sale == nil {
+			return ErrSaleNotFound
+		}
+
+		if req.RefundAmount == nil {
+			refund := sale.PricePerUnit * float64(claim.Quantity)
+			req.RefundAmount = &refund
+		}
+		if *req.RefundAmount <= 0 || *req.RefundAmount > sale.PricePerUnit*float64(claim.Quantity) {
+			return ErrInvalidRefundAmount
+		}
+	default:
+		return ErrInvalidOutcome
+	}
+
+	if req.SupplierOutcome != nil {
+		if claim.Status != warrantymodels.ClaimStatusWithSupplier {
+			return ErrSupplierOutcomeNotSent
+		}
+		switch *req.SupplierOutcome {
+		case warrantymodels.SupplierReplaced, warrantymodels.SupplierCredited, warrantymodels.SupplierRejected:
+		default:
+			return ErrInvalidSupplierOutcome
+		}
+	}
+
+	return nil
+}
+
+func isValidStatus(status string) bool {
+	switch status {
+	case warrantymodels.ClaimStatusOpen, warrantymodels.ClaimStatusWithSupplier, warrantymodels.ClaimStatusResolved:
+		return true
+	}
+	return false
+}
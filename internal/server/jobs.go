@@ -0,0 +1,13 @@
+package server
+
+import (
+	"github.com/hsrvms/autoparts/internal/modules/analytics"
+	"github.com/hsrvms/autoparts/internal/modules/forecasting"
+	"github.com/hsrvms/autoparts/internal/modules/sales"
+)
+
+func (s *Server) initJobs() {
+	forecasting.RegisterJobs(s.Scheduler, s.DB, s.Config.Jobs)
+	analytics.RegisterJobs(s.Scheduler, s.DB, s.Config.Jobs)
+	sales.RegisterJobs(s.Scheduler, s.DB, s.Config.Jobs)
+}
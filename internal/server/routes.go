@@ -3,13 +3,23 @@ package server
 import (
 	"net/http"
 
+	"github.com/hsrvms/autoparts/internal/modules/analytics"
 	"github.com/hsrvms/autoparts/internal/modules/categories"
+	"github.com/hsrvms/autoparts/internal/modules/cores"
+	"github.com/hsrvms/autoparts/internal/modules/customers"
 	"github.com/hsrvms/autoparts/internal/modules/dashboard"
+	"github.com/hsrvms/autoparts/internal/modules/forecasting"
 	"github.com/hsrvms/autoparts/internal/modules/inventory"
 	"github.com/hsrvms/autoparts/internal/modules/purchases"
+	"github.com/hsrvms/autoparts/internal/modules/replenishment"
+	"github.com/hsrvms/autoparts/internal/modules/reports"
 	"github.com/hsrvms/autoparts/internal/modules/sales"
+	"github.com/hsrvms/autoparts/internal/modules/specialorders"
 	"github.com/hsrvms/autoparts/internal/modules/suppliers"
+	"github.com/hsrvms/autoparts/internal/modules/valuation"
 	"github.com/hsrvms/autoparts/internal/modules/vehicles"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses"
+	"github.com/hsrvms/autoparts/internal/modules/warranty"
 	"github.com/labstack/echo/v4"
 )
 
@@ -28,7 +38,17 @@ func (s *Server) initRoutes() {
 	categories.RegisterRoutes(api, s.DB)
 	vehicles.RegisterRoutes(api, s.DB)
 	inventory.RegisterRoutes(api, s.DB)
+	warehouses.RegisterRoutes(api, s.DB)
 	suppliers.RegisterRoutes(api, s.DB)
 	purchases.RegisterRoutes(api, s.DB)
+	specialorders.RegisterRoutes(api, s.DB)
+	replenishment.RegisterRoutes(api, s.DB)
+	customers.RegisterRoutes(api, s.DB)
 	sales.RegisterRoutes(api, s.DB)
+	warranty.RegisterRoutes(api, s.DB)
+	cores.RegisterRoutes(api, s.DB)
+	forecasting.RegisterRoutes(api, s.DB)
+	valuation.RegisterRoutes(api, s.DB, s.Config.Inventory)
+	reports.RegisterRoutes(api, s.DB, s.Config.Inventory)
+	analytics.RegisterRoutes(api, s.DB, s.Config.Inventory)
 }
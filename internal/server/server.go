@@ -13,6 +13,7 @@ import (
 
 	"github.com/hsrvms/autoparts/pkg/config"
 	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/scheduler"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
@@ -28,9 +29,10 @@ func (t *TemplateRenderer) Render(w io.Writer, name string, data interface{}, c
 
 // Server represents our HTTP server
 type Server struct {
-	Echo   *echo.Echo
-	DB     *db.Database
-	Config *config.Config
+	Echo      *echo.Echo
+	DB        *db.Database
+	Config    *config.Config
+	Scheduler *scheduler.Scheduler
 }
 
 // New creates a new server instance
@@ -70,14 +72,18 @@ func New(cfg *config.Config, database *db.Database) *Server {
 
 	// Create server instance
 	server := &Server{
-		Echo:   e,
-		DB:     database,
-		Config: cfg,
+		Echo:      e,
+		DB:        database,
+		Config:    cfg,
+		Scheduler: scheduler.New(),
 	}
 
 	// Initialize routes
 	server.initRoutes()
 
+	// Register background jobs
+	server.initJobs()
+
 	return server
 }
 
@@ -103,6 +109,11 @@ func (s *Server) Start() {
 
 	log.Printf("Server started on %s", addr)
 
+	// Start background jobs
+	if s.Config.Jobs.Enabled {
+		s.Scheduler.Start()
+	}
+
 	// Wait for interrupt signal to gracefully shut down the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
@@ -112,6 +123,8 @@ func (s *Server) Start() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	s.Scheduler.Stop()
+
 	if err := s.Echo.Shutdown(ctx); err != nil {
 		log.Fatalf("Failed to gracefully shutdown server: %v", err)
 	}
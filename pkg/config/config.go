@@ -9,8 +9,10 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Jobs      JobsConfig
+	Inventory InventoryConfig
 }
 
 // ServerConfig holds all server-related configuration
@@ -31,6 +33,20 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// JobsConfig holds configuration for background jobs
+type JobsConfig struct {
+	Enabled                   bool
+	ForecastInterval          time.Duration
+	ForecastHorizonWeeks      int
+	AnalyticsRefreshInterval  time.Duration
+	ReservationExpiryInterval time.Duration
+}
+
+// InventoryConfig holds inventory accounting configuration
+type InventoryConfig struct {
+	CostingMethod string // "fifo" or "average"
+}
+
 // New returns a new Config
 func New() *Config {
 	return &Config{
@@ -48,6 +64,16 @@ func New() *Config {
 			DBName:   getEnv("DB_NAME", "autoparts"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
+		Jobs: JobsConfig{
+			Enabled:                   getEnvAsBool("JOBS_ENABLED", true),
+			ForecastInterval:          getEnvAsDuration("FORECAST_INTERVAL", 24*time.Hour),
+			ForecastHorizonWeeks:      getEnvAsInt("FORECAST_HORIZON_WEEKS", 12),
+			AnalyticsRefreshInterval:  getEnvAsDuration("ANALYTICS_REFRESH_INTERVAL", time.Hour),
+			ReservationExpiryInterval: getEnvAsDuration("RESERVATION_EXPIRY_INTERVAL", 15*time.Minute),
+		},
+		Inventory: InventoryConfig{
+			CostingMethod: getEnv("INVENTORY_COSTING_METHOD", "fifo"),
+		},
 	}
 }
 
@@ -81,6 +107,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value, exists := os.LookupEnv(key); exists {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -2,19 +2,60 @@
 -- MVP Version
 
 -- Drop tables if they exist (for clean reinstallation)
+DROP MATERIALIZED VIEW IF EXISTS sales_daily_summary;
+DROP TABLE IF EXISTS merge_audit CASCADE;
+DROP TABLE IF EXISTS item_forecast_periods CASCADE;
+DROP TABLE IF EXISTS item_forecasts CASCADE;
+DROP TABLE IF EXISTS special_orders CASCADE;
+DROP TABLE IF EXISTS warranty_claims CASCADE;
+DROP TABLE IF EXISTS core_supplier_return_lines CASCADE;
+DROP TABLE IF EXISTS core_supplier_returns CASCADE;
+DROP TABLE IF EXISTS core_returns CASCADE;
+DROP TABLE IF EXISTS sale_lots CASCADE;
+DROP TABLE IF EXISTS item_lots CASCADE;
+DROP TABLE IF EXISTS stock_transfer_serials CASCADE;
+DROP TABLE IF EXISTS item_serials CASCADE;
+DROP TABLE IF EXISTS stock_reservations CASCADE;
+DROP TABLE IF EXISTS stock_transfer_lines CASCADE;
+DROP TABLE IF EXISTS stock_transfers CASCADE;
+DROP TABLE IF EXISTS stock_movements CASCADE;
+DROP TABLE IF EXISTS item_stock CASCADE;
+DROP TABLE IF EXISTS item_cost_history CASCADE;
+DROP TABLE IF EXISTS cost_layer_consumptions CASCADE;
+DROP TABLE IF EXISTS cost_layers CASCADE;
+DROP TABLE IF EXISTS payment_allocations CASCADE;
+DROP TABLE IF EXISTS payments CASCADE;
 DROP TABLE IF EXISTS sales CASCADE;
+DROP TABLE IF EXISTS invoices CASCADE;
+DROP TABLE IF EXISTS customer_vehicles CASCADE;
+DROP TABLE IF EXISTS supplier_payments CASCADE;
 DROP TABLE IF EXISTS purchases CASCADE;
+DROP TABLE IF EXISTS purchase_order_lines CASCADE;
+DROP TABLE IF EXISTS purchase_orders CASCADE;
+DROP TABLE IF EXISTS supplier_invoices CASCADE;
+DROP TABLE IF EXISTS vin_patterns CASCADE;
+DROP TABLE IF EXISTS vin_wmi_codes CASCADE;
+DROP TABLE IF EXISTS item_supersessions CASCADE;
+DROP TABLE IF EXISTS kit_components CASCADE;
+DROP TABLE IF EXISTS item_attribute_values CASCADE;
 DROP TABLE IF EXISTS compatibility CASCADE;
 DROP TABLE IF EXISTS items CASCADE;
+DROP TABLE IF EXISTS bin_locations CASCADE;
+DROP TABLE IF EXISTS warehouses CASCADE;
+DROP TABLE IF EXISTS category_attributes CASCADE;
 DROP TABLE IF EXISTS categories CASCADE;
 DROP TABLE IF EXISTS vehicle_submodels CASCADE;
 DROP TABLE IF EXISTS vehicle_models CASCADE;
 DROP TABLE IF EXISTS vehicle_makes CASCADE;
 DROP TABLE IF EXISTS suppliers CASCADE;
+DROP TABLE IF EXISTS customers CASCADE;
 
 -- Create extension for UUID generation if needed
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
+-- Trigram similarity, used to find duplicate makes, suppliers and items
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 -- Create sequences for IDs
 CREATE SEQUENCE IF NOT EXISTS category_id_seq;
 CREATE SEQUENCE IF NOT EXISTS make_id_seq;
@@ -24,6 +65,26 @@ CREATE SEQUENCE IF NOT EXISTS item_id_seq;
 CREATE SEQUENCE IF NOT EXISTS supplier_id_seq;
 CREATE SEQUENCE IF NOT EXISTS purchase_id_seq;
 CREATE SEQUENCE IF NOT EXISTS sale_id_seq;
+CREATE SEQUENCE IF NOT EXISTS customer_id_seq;
+CREATE SEQUENCE IF NOT EXISTS invoice_id_seq;
+CREATE SEQUENCE IF NOT EXISTS payment_id_seq;
+CREATE SEQUENCE IF NOT EXISTS supplier_invoice_id_seq;
+CREATE SEQUENCE IF NOT EXISTS supplier_payment_id_seq;
+CREATE SEQUENCE IF NOT EXISTS purchase_order_id_seq;
+CREATE SEQUENCE IF NOT EXISTS cost_layer_id_seq;
+CREATE SEQUENCE IF NOT EXISTS warehouse_id_seq;
+CREATE SEQUENCE IF NOT EXISTS bin_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stock_movement_id_seq;
+CREATE SEQUENCE IF NOT EXISTS stock_transfer_id_seq;
+CREATE SEQUENCE IF NOT EXISTS reservation_id_seq;
+CREATE SEQUENCE IF NOT EXISTS special_order_id_seq;
+CREATE SEQUENCE IF NOT EXISTS item_serial_id_seq;
+CREATE SEQUENCE IF NOT EXISTS item_lot_id_seq;
+CREATE SEQUENCE IF NOT EXISTS warranty_claim_id_seq;
+CREATE SEQUENCE IF NOT EXISTS core_return_id_seq;
+CREATE SEQUENCE IF NOT EXISTS core_supplier_return_id_seq;
+CREATE SEQUENCE IF NOT EXISTS vin_pattern_id_seq;
+CREATE SEQUENCE IF NOT EXISTS customer_vehicle_id_seq;
 
 -- Categories table with hierarchical structure
 CREATE TABLE categories (
@@ -31,11 +92,42 @@ CREATE TABLE categories (
     category_name VARCHAR(100) NOT NULL,
     description TEXT,
     parent_category_id INTEGER REFERENCES categories(category_id) ON DELETE SET NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0, -- Position among categories with the same parent
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT unique_category_name UNIQUE (category_name)
 );
 
+-- Specifications items of a category carry, such as the thread size of a
+-- filter. Attributes apply to the category and every category below it, and
+-- a key is used once along any path through the tree.
+CREATE TABLE category_attributes (
+    attribute_id SERIAL PRIMARY KEY,
+    category_id INTEGER NOT NULL REFERENCES categories(category_id) ON DELETE CASCADE,
+    attribute_key VARCHAR(50) NOT NULL, -- Name used in item filters, e.g. thread_size
+    label VARCHAR(100) NOT NULL,
+    data_type VARCHAR(10) NOT NULL, -- text, number, boolean or enum
+    unit VARCHAR(20), -- Unit of a number, e.g. mm
+    allowed_values TEXT[], -- Choices of an enum
+    min_value DECIMAL(14,4), -- Bounds of a number
+    max_value DECIMAL(14,4),
+    is_required BOOLEAN NOT NULL DEFAULT FALSE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_category_attribute UNIQUE (category_id, attribute_key),
+    CONSTRAINT valid_attribute_key CHECK (attribute_key ~ '^[a-z][a-z0-9_]*$'),
+    CONSTRAINT valid_attribute_type CHECK (data_type IN ('text', 'number', 'boolean', 'enum')),
+    CONSTRAINT enum_attribute_values CHECK (
+        (data_type = 'enum' AND COALESCE(cardinality(allowed_values), 0) > 0) OR
+        (data_type <> 'enum' AND allowed_values IS NULL)
+    ),
+    CONSTRAINT number_attribute_bounds CHECK (
+        data_type = 'number' OR (unit IS NULL AND min_value IS NULL AND max_value IS NULL)
+    ),
+    CONSTRAINT valid_attribute_bounds CHECK (min_value IS NULL OR max_value IS NULL OR min_value <= max_value)
+);
+
 -- Vehicle Makes
 CREATE TABLE vehicle_makes (
     make_id INTEGER PRIMARY KEY DEFAULT nextval('make_id_seq'),
@@ -74,6 +166,33 @@ CREATE TABLE vehicle_submodels (
     CONSTRAINT unique_submodel UNIQUE (model_id, submodel_name, year_from)
 );
 
+-- World manufacturer identifiers, the first three characters of a VIN
+CREATE TABLE vin_wmi_codes (
+    wmi VARCHAR(3) PRIMARY KEY,
+    make_id INTEGER NOT NULL REFERENCES vehicle_makes(make_id) ON DELETE CASCADE,
+    manufacturer VARCHAR(200),
+    country VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_wmi CHECK (wmi ~ '^[A-HJ-NPR-Z0-9]{3}$')
+);
+
+-- Locally maintained patterns mapping the descriptor section of a VIN
+-- (positions 4 to 8, which carry the model, body and engine codes) to submodels
+CREATE TABLE vin_patterns (
+    pattern_id INTEGER PRIMARY KEY DEFAULT nextval('vin_pattern_id_seq'),
+    wmi VARCHAR(3) NOT NULL REFERENCES vin_wmi_codes(wmi) ON DELETE CASCADE,
+    vds_pattern VARCHAR(5) NOT NULL, -- * matches any character
+    year_from INTEGER, -- Model years the pattern applies to; NULL for open ended
+    year_to INTEGER,
+    submodel_id INTEGER NOT NULL REFERENCES vehicle_submodels(submodel_id) ON DELETE CASCADE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_vin_pattern UNIQUE (wmi, vds_pattern, submodel_id),
+    CONSTRAINT valid_vds_pattern CHECK (vds_pattern ~ '^[A-HJ-NPR-Z0-9*]{5}$'),
+    CONSTRAINT valid_vin_pattern_years CHECK (year_from IS NULL OR year_to IS NULL OR year_to >= year_from)
+);
+
 -- Suppliers
 CREATE TABLE suppliers (
     supplier_id INTEGER PRIMARY KEY DEFAULT nextval('supplier_id_seq'),
@@ -84,10 +203,116 @@ CREATE TABLE suppliers (
     address TEXT,
     tax_id VARCHAR(100),
     payment_terms VARCHAR(100),
+    lead_time_days INTEGER NOT NULL DEFAULT 7,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_supplier_name UNIQUE (name),
+    CONSTRAINT non_negative_lead_time CHECK (lead_time_days >= 0)
+);
+
+-- Customers (trade accounts that can buy on credit)
+CREATE TABLE customers (
+    customer_id INTEGER PRIMARY KEY DEFAULT nextval('customer_id_seq'),
+    name VARCHAR(200) NOT NULL,
+    contact_person VARCHAR(200),
+    phone VARCHAR(50),
+    email VARCHAR(200),
+    address TEXT,
+    tax_id VARCHAR(100),
+    credit_limit DECIMAL(12,2) NOT NULL DEFAULT 0,
+    payment_terms_days INTEGER NOT NULL DEFAULT 30,
+    is_active BOOLEAN DEFAULT TRUE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT non_negative_credit_limit CHECK (credit_limit >= 0),
+    CONSTRAINT non_negative_payment_terms CHECK (payment_terms_days >= 0)
+);
+
+-- Vehicles customers have registered, so sales can be recorded against them
+CREATE TABLE customer_vehicles (
+    vehicle_id INTEGER PRIMARY KEY DEFAULT nextval('customer_vehicle_id_seq'),
+    customer_id INTEGER NOT NULL REFERENCES customers(customer_id) ON DELETE CASCADE,
+    submodel_id INTEGER NOT NULL REFERENCES vehicle_submodels(submodel_id) ON DELETE RESTRICT,
+    model_year INTEGER NOT NULL,
+    vin VARCHAR(17),
+    license_plate VARCHAR(20),
+    mileage INTEGER, -- Latest known odometer reading, raised by sales that record one
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_customer_vehicle_vin CHECK (vin ~ '^[A-HJ-NPR-Z0-9]{17}$'),
+    CONSTRAINT non_negative_mileage CHECK (mileage >= 0)
+);
+
+-- Customer invoices, generated from one or more account sales
+CREATE TABLE invoices (
+    invoice_id INTEGER PRIMARY KEY DEFAULT nextval('invoice_id_seq'),
+    invoice_number VARCHAR(100) NOT NULL,
+    customer_id INTEGER NOT NULL REFERENCES customers(customer_id) ON DELETE RESTRICT,
+    issue_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    due_date DATE NOT NULL,
+    total_amount DECIMAL(12,2) NOT NULL,
+    amount_paid DECIMAL(12,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
     notes TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    CONSTRAINT unique_supplier_name UNIQUE (name)
+    CONSTRAINT unique_invoice_number UNIQUE (invoice_number),
+    CONSTRAINT valid_invoice_status CHECK (status IN ('open', 'partially_paid', 'paid', 'void')),
+    CONSTRAINT invoice_paid_within_total CHECK (amount_paid >= 0 AND amount_paid <= total_amount)
+);
+
+-- Invoices received from suppliers for one or more purchases
+CREATE TABLE supplier_invoices (
+    supplier_invoice_id INTEGER PRIMARY KEY DEFAULT nextval('supplier_invoice_id_seq'),
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(supplier_id) ON DELETE RESTRICT,
+    invoice_number VARCHAR(100) NOT NULL,
+    invoice_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    due_date DATE NOT NULL,
+    discount_date DATE,
+    discount_percent DECIMAL(5,2),
+    total_amount DECIMAL(12,2) NOT NULL,
+    amount_paid DECIMAL(12,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_supplier_invoice_number UNIQUE (supplier_id, invoice_number),
+    CONSTRAINT valid_supplier_invoice_status CHECK (status IN ('open', 'partially_paid', 'paid', 'void')),
+    CONSTRAINT supplier_invoice_paid_within_total CHECK (amount_paid >= 0 AND amount_paid <= total_amount)
+);
+
+-- Stock-holding locations: the shop and any warehouses. Sales and purchases
+-- without a location use the default one.
+CREATE TABLE warehouses (
+    warehouse_id INTEGER PRIMARY KEY DEFAULT nextval('warehouse_id_seq'),
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    address TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_warehouse_code UNIQUE (code),
+    CONSTRAINT active_default_warehouse CHECK (NOT is_default OR is_active)
+);
+
+-- Bin locations within a warehouse
+CREATE TABLE bin_locations (
+    bin_id INTEGER PRIMARY KEY DEFAULT nextval('bin_id_seq'),
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE CASCADE,
+    code VARCHAR(60) NOT NULL, -- Format: aisle-shelf-bin
+    aisle VARCHAR(50),
+    shelf VARCHAR(50),
+    bin VARCHAR(50),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_bin_code UNIQUE (warehouse_id, code)
 );
 
 -- Items (Auto Parts)
@@ -98,25 +323,49 @@ CREATE TABLE items (
     category_id INTEGER REFERENCES categories(category_id) ON DELETE SET NULL,
     buy_price DECIMAL(10,2) NOT NULL,
     sell_price DECIMAL(10,2) NOT NULL,
-    current_stock INTEGER NOT NULL DEFAULT 0,
+    current_stock INTEGER NOT NULL DEFAULT 0, -- Total across all warehouses, kept in step with item_stock
     minimum_stock INTEGER NOT NULL DEFAULT 5,
+    reorder_up_to INTEGER, -- Target stock level when replenishing; derived from sales velocity if NULL
+    average_cost DECIMAL(12,4), -- Perpetual weighted-average cost, maintained by the stock triggers
     barcode VARCHAR(100) UNIQUE,
     supplier_id INTEGER REFERENCES suppliers(supplier_id) ON DELETE SET NULL,
-    location_aisle VARCHAR(50),
+    location_aisle VARCHAR(50), -- Superseded by bin_locations; kept for existing clients
     location_shelf VARCHAR(50),
     location_bin VARCHAR(50),
     weight_kg DECIMAL(10,3),
     dimensions_cm VARCHAR(50), -- Format: LxWxH
-    warranty_period VARCHAR(50),
+    warranty_period VARCHAR(50), -- Superseded by warranty_length and warranty_unit; kept for existing clients
+    warranty_length INTEGER,
+    warranty_unit VARCHAR(10), -- days, months or years
     image_url VARCHAR(255),
     is_active BOOLEAN DEFAULT TRUE,
+    is_clearance BOOLEAN NOT NULL DEFAULT FALSE, -- Marked for clearance, typically from the dead-stock report
+    tracking_mode VARCHAR(10) NOT NULL DEFAULT 'none', -- none, serial or lot; tracked items record units on receipt and sale
+    core_charge DECIMAL(10,2), -- Deposit per unit refunded when the old unit is returned; NULL for items without a core
+    is_kit BOOLEAN NOT NULL DEFAULT FALSE, -- Sold as a bundle of kit_components; holds no stock of its own
+    kit_pricing VARCHAR(10), -- fixed (sell_price as entered) or discount (components' sell prices less kit_discount_percent)
+    kit_discount_percent DECIMAL(5,2),
     notes TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT unique_part_number UNIQUE (part_number),
     CONSTRAINT positive_buy_price CHECK (buy_price >= 0),
     CONSTRAINT positive_sell_price CHECK (sell_price >= 0),
-    CONSTRAINT non_negative_stock CHECK (current_stock >= 0)
+    CONSTRAINT non_negative_stock CHECK (current_stock >= 0),
+    CONSTRAINT reorder_up_to_above_minimum CHECK (reorder_up_to IS NULL OR reorder_up_to >= minimum_stock),
+    CONSTRAINT valid_tracking_mode CHECK (tracking_mode IN ('none', 'serial', 'lot')),
+    CONSTRAINT valid_warranty CHECK (
+        (warranty_length IS NULL AND warranty_unit IS NULL) OR
+        (warranty_length > 0 AND warranty_unit IN ('days', 'months', 'years'))
+    ),
+    CONSTRAINT positive_core_charge CHECK (core_charge IS NULL OR core_charge > 0),
+    CONSTRAINT kit_holds_no_stock CHECK (NOT is_kit OR current_stock = 0),
+    CONSTRAINT untracked_kit CHECK (NOT is_kit OR tracking_mode = 'none'),
+    CONSTRAINT valid_kit_pricing CHECK (
+        (NOT is_kit AND kit_pricing IS NULL AND kit_discount_percent IS NULL) OR
+        (is_kit AND kit_pricing = 'fixed' AND kit_discount_percent IS NULL) OR
+        (is_kit AND kit_pricing = 'discount' AND kit_discount_percent >= 0 AND kit_discount_percent < 100)
+    )
 );
 
 -- Compatibility mapping between parts and vehicle submodels
@@ -125,8 +374,82 @@ CREATE TABLE compatibility (
     item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
     submodel_id INTEGER NOT NULL REFERENCES vehicle_submodels(submodel_id) ON DELETE CASCADE,
     notes TEXT,
+    year_from INTEGER, -- Narrows the submodel's years when fitment changes mid-generation
+    year_to INTEGER,
+    position VARCHAR(20), -- Where on the vehicle the part goes, NULL when it does not matter
+    engine_code VARCHAR(50), -- Only fits this engine, NULL for any
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_compat_years CHECK (year_to IS NULL OR year_from IS NULL OR year_to >= year_from),
+    CONSTRAINT valid_compat_position CHECK (position IN ('front', 'rear', 'left', 'right', 'front_left', 'front_right', 'rear_left', 'rear_right'))
+);
+
+-- Component items of a kit and how many of each go into one kit. Selling a
+-- kit takes its components out of stock.
+CREATE TABLE kit_components (
+    kit_item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    component_item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (kit_item_id, component_item_id),
+    CONSTRAINT kit_not_own_component CHECK (kit_item_id <> component_item_id),
+    CONSTRAINT positive_kit_component_quantity CHECK (quantity > 0)
+);
+
+-- Manufacturer supersessions: the old part number is replaced by the new
+-- one from the given date. Each item is superseded at most once, so
+-- following the links from any item leads to the current part.
+CREATE TABLE item_supersessions (
+    supersession_id SERIAL PRIMARY KEY,
+    old_item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    new_item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    superseded_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    is_direct_interchange BOOLEAN NOT NULL DEFAULT TRUE, -- The new part fits wherever the old one did, without other changes
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_superseded_item UNIQUE (old_item_id),
+    CONSTRAINT item_not_own_supersession CHECK (old_item_id <> new_item_id)
+);
+
+-- An item's value for each attribute of its category, in the column for the
+-- attribute's type: text for text and enum attributes
+CREATE TABLE item_attribute_values (
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES category_attributes(attribute_id) ON DELETE CASCADE,
+    value_text TEXT,
+    value_number DECIMAL(14,4),
+    value_boolean BOOLEAN,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (item_id, attribute_id),
+    CONSTRAINT single_attribute_value CHECK (num_nonnulls(value_text, value_number, value_boolean) = 1)
+);
+
+-- Purchase orders sent to suppliers
+CREATE TABLE purchase_orders (
+    purchase_order_id INTEGER PRIMARY KEY DEFAULT nextval('purchase_order_id_seq'),
+    po_number VARCHAR(50) NOT NULL,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(supplier_id) ON DELETE RESTRICT,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    order_date DATE,
+    expected_date DATE,
+    notes TEXT,
+    created_by VARCHAR(100),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    CONSTRAINT unique_item_submodel UNIQUE (item_id, submodel_id)
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_po_number UNIQUE (po_number),
+    CONSTRAINT valid_purchase_order_status CHECK (status IN ('draft', 'ordered', 'received', 'cancelled'))
+);
+
+CREATE TABLE purchase_order_lines (
+    line_id SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders(purchase_order_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    quantity_received INTEGER NOT NULL DEFAULT 0,
+    unit_cost DECIMAL(10,2) NOT NULL,
+    CONSTRAINT unique_purchase_order_item UNIQUE (purchase_order_id, item_id),
+    CONSTRAINT positive_line_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_quantity_received CHECK (quantity_received >= 0 AND quantity_received <= quantity),
+    CONSTRAINT non_negative_unit_cost CHECK (unit_cost >= 0)
 );
 
 -- Purchases
@@ -141,6 +464,9 @@ CREATE TABLE purchases (
     invoice_number VARCHAR(100),
     received_by VARCHAR(100),
     notes TEXT,
+    supplier_invoice_id INTEGER REFERENCES supplier_invoices(supplier_invoice_id) ON DELETE SET NULL,
+    purchase_order_id INTEGER REFERENCES purchase_orders(purchase_order_id) ON DELETE SET NULL,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT, -- Set to the default warehouse if omitted
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT positive_quantity CHECK (quantity > 0),
@@ -162,26 +488,518 @@ CREATE TABLE sales (
     customer_email VARCHAR(200),
     sold_by VARCHAR(100),
     notes TEXT,
+    customer_id INTEGER REFERENCES customers(customer_id) ON DELETE SET NULL,
+    payment_method VARCHAR(20) NOT NULL DEFAULT 'cash',
+    payment_status VARCHAR(20) NOT NULL DEFAULT 'paid',
+    due_date DATE,
+    invoice_id INTEGER REFERENCES invoices(invoice_id) ON DELETE SET NULL,
+    cogs_fifo DECIMAL(12,2), -- Cost of goods sold, set by trigger_update_inventory_on_sale
+    cogs_average DECIMAL(12,2),
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT, -- Set to the default warehouse if omitted
+    warranty_expires_at TIMESTAMP WITH TIME ZONE, -- From the item's warranty at the time of sale, set by trigger
+    core_deposit DECIMAL(10,2) NOT NULL DEFAULT 0, -- Item's core charge times quantity, set by trigger; separate from total_price but billed with it on account sales
+    customer_vehicle_id INTEGER REFERENCES customer_vehicles(vehicle_id) ON DELETE SET NULL, -- Vehicle the parts were for
+    vehicle_mileage INTEGER, -- Odometer reading at the time of sale
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT positive_quantity CHECK (quantity > 0),
     CONSTRAINT positive_price_per_unit CHECK (price_per_unit >= 0),
-    CONSTRAINT positive_total_price CHECK (total_price >= 0)
+    CONSTRAINT positive_total_price CHECK (total_price >= 0),
+    CONSTRAINT valid_payment_method CHECK (payment_method IN ('cash', 'card', 'bank_transfer', 'account')),
+    CONSTRAINT valid_payment_status CHECK (payment_status IN ('paid', 'unpaid', 'partially_paid')),
+    CONSTRAINT account_sale_has_customer CHECK (payment_method <> 'account' OR customer_id IS NOT NULL),
+    CONSTRAINT non_negative_vehicle_mileage CHECK (vehicle_mileage >= 0)
+);
+
+-- Customer payments received against invoices
+CREATE TABLE payments (
+    payment_id INTEGER PRIMARY KEY DEFAULT nextval('payment_id_seq'),
+    customer_id INTEGER NOT NULL REFERENCES customers(customer_id) ON DELETE RESTRICT,
+    payment_date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    amount DECIMAL(12,2) NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    reference VARCHAR(100),
+    received_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_payment_amount CHECK (amount > 0),
+    CONSTRAINT valid_payment_method CHECK (method IN ('cash', 'card', 'bank_transfer', 'core_credit')) -- core_credit: core deposit refunded to the account
+);
+
+-- How each payment is split across invoices
+CREATE TABLE payment_allocations (
+    allocation_id SERIAL PRIMARY KEY,
+    payment_id INTEGER NOT NULL REFERENCES payments(payment_id) ON DELETE CASCADE,
+    invoice_id INTEGER NOT NULL REFERENCES invoices(invoice_id) ON DELETE RESTRICT,
+    amount DECIMAL(12,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_allocation_amount CHECK (amount > 0)
+);
+
+-- Payments made to suppliers against their invoices
+CREATE TABLE supplier_payments (
+    supplier_payment_id INTEGER PRIMARY KEY DEFAULT nextval('supplier_payment_id_seq'),
+    supplier_invoice_id INTEGER NOT NULL REFERENCES supplier_invoices(supplier_invoice_id) ON DELETE RESTRICT,
+    payment_date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    amount DECIMAL(12,2) NOT NULL,
+    discount_taken DECIMAL(12,2) NOT NULL DEFAULT 0,
+    method VARCHAR(20) NOT NULL,
+    reference VARCHAR(100),
+    paid_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_supplier_payment_amount CHECK (amount > 0),
+    CONSTRAINT non_negative_discount_taken CHECK (discount_taken >= 0),
+    CONSTRAINT valid_supplier_payment_method CHECK (method IN ('cash', 'card', 'bank_transfer', 'cheque'))
+);
+
+-- Cost layers: one per receipt of stock, drawn down oldest first
+CREATE TABLE cost_layers (
+    layer_id INTEGER PRIMARY KEY DEFAULT nextval('cost_layer_id_seq'),
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    purchase_id INTEGER REFERENCES purchases(purchase_id) ON DELETE SET NULL,
+    source VARCHAR(20) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    quantity INTEGER NOT NULL,
+    remaining_quantity INTEGER NOT NULL,
+    unit_cost DECIMAL(12,4) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_cost_layer_source CHECK (source IN ('opening', 'purchase', 'adjustment')),
+    CONSTRAINT positive_layer_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_remaining_quantity CHECK (remaining_quantity >= 0 AND remaining_quantity <= quantity),
+    CONSTRAINT non_negative_layer_cost CHECK (unit_cost >= 0)
+);
+
+-- Stock drawn from cost layers by sales and downward adjustments. layer_id is
+-- NULL for stock that was not covered by any layer. Deleting a sale does not
+-- put its stock back, so its consumptions stay with sale_id cleared.
+CREATE TABLE cost_layer_consumptions (
+    consumption_id SERIAL PRIMARY KEY,
+    layer_id INTEGER REFERENCES cost_layers(layer_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    sale_id INTEGER REFERENCES sales(sale_id) ON DELETE SET NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit_cost DECIMAL(12,4) NOT NULL,
+    CONSTRAINT positive_consumption_quantity CHECK (quantity > 0)
+);
+
+-- Quantity on hand and weighted-average cost after every stock movement
+CREATE TABLE item_cost_history (
+    history_id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    quantity_on_hand INTEGER NOT NULL,
+    average_cost DECIMAL(12,4) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    reference_id INTEGER,
+    CONSTRAINT valid_cost_history_source CHECK (source IN ('opening', 'purchase', 'sale', 'adjustment'))
+);
+
+-- Quantity on hand per item per warehouse, with the bin it is kept in
+CREATE TABLE item_stock (
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    bin_id INTEGER REFERENCES bin_locations(bin_id) ON DELETE SET NULL,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (item_id, warehouse_id),
+    CONSTRAINT non_negative_location_stock CHECK (quantity >= 0)
+);
+
+-- Every change to a location's stock, with the balance after it
+CREATE TABLE stock_movements (
+    movement_id INTEGER PRIMARY KEY DEFAULT nextval('stock_movement_id_seq'),
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    movement_type VARCHAR(20) NOT NULL,
+    quantity INTEGER NOT NULL, -- Signed: positive into the location, negative out of it
+    balance_after INTEGER NOT NULL,
+    reference_id INTEGER, -- purchase_id, sale_id or transfer_id, depending on the type
+    notes TEXT,
+    moved_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_movement_type CHECK (movement_type IN ('opening', 'purchase', 'sale', 'adjustment', 'transfer_out', 'transfer_in')),
+    CONSTRAINT non_zero_movement CHECK (quantity <> 0)
+);
+
+-- Stock moved between warehouses. Dispatching takes the stock out of the
+-- source and receiving books it in at the destination; in between it is in
+-- transit and still counts towards the item's total.
+CREATE TABLE stock_transfers (
+    transfer_id INTEGER PRIMARY KEY DEFAULT nextval('stock_transfer_id_seq'),
+    transfer_number VARCHAR(50) NOT NULL,
+    from_warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    to_warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    notes TEXT,
+    created_by VARCHAR(100),
+    dispatched_at TIMESTAMP WITH TIME ZONE,
+    dispatched_by VARCHAR(100),
+    received_at TIMESTAMP WITH TIME ZONE,
+    received_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_transfer_number UNIQUE (transfer_number),
+    CONSTRAINT valid_transfer_status CHECK (status IN ('draft', 'in_transit', 'received', 'cancelled')),
+    CONSTRAINT distinct_transfer_warehouses CHECK (from_warehouse_id <> to_warehouse_id)
+);
+
+CREATE TABLE stock_transfer_lines (
+    line_id SERIAL PRIMARY KEY,
+    transfer_id INTEGER NOT NULL REFERENCES stock_transfers(transfer_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    quantity_received INTEGER, -- Set on receipt; any difference is booked as an adjustment at the destination
+    discrepancy_note TEXT,
+    CONSTRAINT unique_transfer_item UNIQUE (transfer_id, item_id),
+    CONSTRAINT positive_transfer_quantity CHECK (quantity > 0),
+    CONSTRAINT non_negative_transfer_received CHECK (quantity_received IS NULL OR quantity_received >= 0)
+);
+
+-- Stock held for a customer or workshop job. An active reservation lowers
+-- the stock available to sell at its warehouse but not the stock on hand;
+-- it ends when converted into a sale, released, or expired.
+CREATE TABLE stock_reservations (
+    reservation_id INTEGER PRIMARY KEY DEFAULT nextval('reservation_id_seq'),
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    customer_id INTEGER REFERENCES customers(customer_id) ON DELETE SET NULL,
+    customer_name VARCHAR(100),
+    customer_phone VARCHAR(20),
+    reference VARCHAR(100), -- e.g. the workshop job number
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    sale_id INTEGER REFERENCES sales(sale_id) ON DELETE SET NULL,
+    notes TEXT,
+    created_by VARCHAR(100),
+    closed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_reservation_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_reservation_status CHECK (status IN ('active', 'converted', 'released', 'expired')),
+    CONSTRAINT reservation_customer CHECK (customer_id IS NOT NULL OR customer_name IS NOT NULL)
+);
+
+-- Parts ordered in for a specific customer. The part may not be in the
+-- catalogue yet (part_number and description only); an item is created when
+-- it is ordered. Orders for an item with no purchase order behave as
+-- backorders and are filled by the next delivery of that item. Received
+-- units are held by the order's reservation until it is collected.
+CREATE TABLE special_orders (
+    special_order_id INTEGER PRIMARY KEY DEFAULT nextval('special_order_id_seq'),
+    customer_id INTEGER REFERENCES customers(customer_id) ON DELETE SET NULL,
+    customer_name VARCHAR(100),
+    customer_phone VARCHAR(20),
+    customer_email VARCHAR(100),
+    item_id INTEGER REFERENCES items(item_id) ON DELETE RESTRICT,
+    part_number VARCHAR(100),
+    description TEXT,
+    quantity INTEGER NOT NULL,
+    quantity_received INTEGER NOT NULL DEFAULT 0,
+    supplier_id INTEGER REFERENCES suppliers(supplier_id) ON DELETE SET NULL,
+    unit_cost DECIMAL(10,2), -- Expected cost from the supplier
+    quoted_price DECIMAL(10,2), -- Unit price agreed with the customer
+    deposit_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested',
+    purchase_order_id INTEGER REFERENCES purchase_orders(purchase_order_id) ON DELETE SET NULL,
+    reservation_id INTEGER REFERENCES stock_reservations(reservation_id) ON DELETE SET NULL,
+    sale_id INTEGER REFERENCES sales(sale_id) ON DELETE SET NULL,
+    hold_days INTEGER NOT NULL DEFAULT 14, -- How long the stock is held once it arrives
+    ordered_at TIMESTAMP WITH TIME ZONE,
+    ready_at TIMESTAMP WITH TIME ZONE,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_special_order_status CHECK (status IN ('requested', 'ordered', 'ready', 'collected', 'cancelled')),
+    CONSTRAINT special_order_part CHECK (item_id IS NOT NULL OR part_number IS NOT NULL),
+    CONSTRAINT special_order_customer CHECK (customer_id IS NOT NULL OR customer_name IS NOT NULL),
+    CONSTRAINT positive_special_order_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_special_order_received CHECK (quantity_received >= 0 AND quantity_received <= quantity),
+    CONSTRAINT non_negative_deposit CHECK (deposit_amount >= 0),
+    CONSTRAINT positive_hold_days CHECK (hold_days > 0)
+);
+
+-- Serial numbered units of items tracked by serial, one row per unit
+-- received. The sale is set when the unit is sold.
+CREATE TABLE item_serials (
+    serial_id INTEGER PRIMARY KEY DEFAULT nextval('item_serial_id_seq'),
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    serial_number VARCHAR(100) NOT NULL,
+    purchase_id INTEGER REFERENCES purchases(purchase_id) ON DELETE CASCADE,
+    warehouse_id INTEGER REFERENCES warehouses(warehouse_id) ON DELETE RESTRICT, -- Where the unit is or was sold from; NULL while in transit
+    sale_id INTEGER REFERENCES sales(sale_id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_stock',
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    sold_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_item_serial UNIQUE (item_id, serial_number),
+    CONSTRAINT valid_serial_status CHECK (status IN ('in_stock', 'sold')),
+    CONSTRAINT sold_serial_has_date CHECK (status = 'in_stock' OR sold_at IS NOT NULL)
+);
+
+-- Batches of items tracked by lot, one row per lot per receipt
+CREATE TABLE item_lots (
+    lot_id INTEGER PRIMARY KEY DEFAULT nextval('item_lot_id_seq'),
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE CASCADE,
+    lot_number VARCHAR(100) NOT NULL,
+    purchase_id INTEGER REFERENCES purchases(purchase_id) ON DELETE CASCADE,
+    quantity_received INTEGER NOT NULL,
+    quantity_remaining INTEGER NOT NULL,
+    expiry_date DATE,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_purchase_lot UNIQUE (purchase_id, lot_number),
+    CONSTRAINT positive_lot_quantity CHECK (quantity_received > 0),
+    CONSTRAINT valid_lot_remaining CHECK (quantity_remaining >= 0 AND quantity_remaining <= quantity_received)
+);
+
+-- The serial numbered units sent on a transfer line
+CREATE TABLE stock_transfer_serials (
+    line_id INTEGER NOT NULL REFERENCES stock_transfer_lines(line_id) ON DELETE CASCADE,
+    serial_id INTEGER NOT NULL REFERENCES item_serials(serial_id) ON DELETE CASCADE,
+    PRIMARY KEY (line_id, serial_id)
+);
+
+-- The lots a sale was drawn from
+CREATE TABLE sale_lots (
+    sale_id INTEGER NOT NULL REFERENCES sales(sale_id) ON DELETE CASCADE,
+    lot_id INTEGER NOT NULL REFERENCES item_lots(lot_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (sale_id, lot_id),
+    CONSTRAINT positive_sale_lot_quantity CHECK (quantity > 0)
+);
+
+-- Warranty claims: a customer brings back a part bought on a sale, the part
+-- may be sent back to the supplier under an RMA, and the claim is closed with
+-- the outcome for the customer
+CREATE TABLE warranty_claims (
+    claim_id INTEGER PRIMARY KEY DEFAULT nextval('warranty_claim_id_seq'),
+    claim_number VARCHAR(50) NOT NULL,
+    sale_id INTEGER NOT NULL REFERENCES sales(sale_id) ON DELETE RESTRICT,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    serial_number VARCHAR(100),
+    quantity INTEGER NOT NULL DEFAULT 1,
+    customer_id INTEGER REFERENCES customers(customer_id) ON DELETE SET NULL,
+    customer_name VARCHAR(200),
+    customer_phone VARCHAR(50),
+    fault_description TEXT NOT NULL,
+    within_warranty BOOLEAN NOT NULL, -- Whether the sale was under warranty when the claim was made
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    supplier_id INTEGER REFERENCES suppliers(supplier_id) ON DELETE SET NULL,
+    rma_number VARCHAR(100),
+    sent_to_supplier_at TIMESTAMP WITH TIME ZONE,
+    supplier_outcome VARCHAR(20),
+    outcome VARCHAR(20),
+    refund_amount DECIMAL(10,2),
+    resolution_notes TEXT,
+    received_by VARCHAR(100),
+    resolved_by VARCHAR(100),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_claim_number UNIQUE (claim_number),
+    CONSTRAINT positive_claim_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_claim_status CHECK (status IN ('open', 'with_supplier', 'resolved')),
+    CONSTRAINT valid_supplier_outcome CHECK (supplier_outcome IS NULL OR supplier_outcome IN ('replaced', 'credited', 'rejected')),
+    CONSTRAINT valid_claim_outcome CHECK (outcome IS NULL OR outcome IN ('replaced', 'refunded', 'rejected')),
+    CONSTRAINT resolved_claim_has_outcome CHECK (status <> 'resolved' OR outcome IS NOT NULL),
+    CONSTRAINT non_negative_refund CHECK (refund_amount IS NULL OR refund_amount >= 0)
+);
+
+-- Old units (cores) brought back by customers against the deposit taken on a sale
+CREATE TABLE core_returns (
+    core_return_id INTEGER PRIMARY KEY DEFAULT nextval('core_return_id_seq'),
+    sale_id INTEGER NOT NULL REFERENCES sales(sale_id) ON DELETE RESTRICT,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    condition VARCHAR(20) NOT NULL DEFAULT 'good', -- good or damaged; damaged cores may get a partial refund
+    refund_amount DECIMAL(10,2) NOT NULL,
+    refund_method VARCHAR(20) NOT NULL, -- account for account sales, whose deposit was billed to the customer
+    payment_id INTEGER REFERENCES payments(payment_id) ON DELETE RESTRICT, -- Credit raised on the account
+    received_by VARCHAR(100),
+    notes TEXT,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_core_return_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_core_condition CHECK (condition IN ('good', 'damaged')),
+    CONSTRAINT non_negative_core_refund CHECK (refund_amount >= 0),
+    CONSTRAINT valid_core_refund_method CHECK (refund_method IN ('cash', 'card', 'bank_transfer', 'account'))
+);
+
+-- Cores sent back to a supplier for credit
+CREATE TABLE core_supplier_returns (
+    core_supplier_return_id INTEGER PRIMARY KEY DEFAULT nextval('core_supplier_return_id_seq'),
+    return_number VARCHAR(50) NOT NULL,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(supplier_id) ON DELETE RESTRICT,
+    status VARCHAR(20) NOT NULL DEFAULT 'sent',
+    expected_credit DECIMAL(12,2) NOT NULL,
+    credit_amount DECIMAL(12,2), -- As confirmed by the supplier
+    credit_reference VARCHAR(100),
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    credited_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_core_return_number UNIQUE (return_number),
+    CONSTRAINT valid_core_supplier_return_status CHECK (status IN ('sent', 'credited')),
+    CONSTRAINT credited_core_return_has_amount CHECK (status <> 'credited' OR credit_amount IS NOT NULL),
+    CONSTRAINT non_negative_core_credit CHECK (credit_amount IS NULL OR credit_amount >= 0)
+);
+
+CREATE TABLE core_supplier_return_lines (
+    core_supplier_return_id INTEGER NOT NULL REFERENCES core_supplier_returns(core_supplier_return_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    unit_credit DECIMAL(10,2) NOT NULL,
+    PRIMARY KEY (core_supplier_return_id, item_id),
+    CONSTRAINT positive_core_line_quantity CHECK (quantity > 0),
+    CONSTRAINT non_negative_core_unit_credit CHECK (unit_credit >= 0)
+);
+
+-- Demand forecasts, one per item, refreshed by the forecasting job
+CREATE TABLE item_forecasts (
+    item_id INTEGER PRIMARY KEY REFERENCES items(item_id) ON DELETE CASCADE,
+    method VARCHAR(50) NOT NULL,
+    history_weeks INTEGER NOT NULL,
+    horizon_weeks INTEGER NOT NULL,
+    average_weekly_demand DECIMAL(10,2) NOT NULL,
+    weekly_trend DECIMAL(10,4) NOT NULL DEFAULT 0,
+    seasonal BOOLEAN NOT NULL DEFAULT FALSE,
+    error_rmse DECIMAL(10,2),
+    confidence DECIMAL(4,3) NOT NULL,
+    lead_time_days INTEGER NOT NULL,
+    recommended_minimum_stock INTEGER,
+    generated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_forecast_confidence CHECK (confidence >= 0 AND confidence <= 1)
+);
+
+-- Predicted demand per item per week
+CREATE TABLE item_forecast_periods (
+    item_id INTEGER NOT NULL REFERENCES item_forecasts(item_id) ON DELETE CASCADE,
+    week_start DATE NOT NULL,
+    quantity DECIMAL(10,2) NOT NULL,
+    lower_bound DECIMAL(10,2) NOT NULL,
+    upper_bound DECIMAL(10,2) NOT NULL,
+    PRIMARY KEY (item_id, week_start)
+);
+
+-- Duplicate records merged into a survivor, with the merged row as it was
+-- before it was deleted. The ids are not foreign keys since either record
+-- may be merged or deleted later.
+CREATE TABLE merge_audit (
+    merge_id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL,
+    survivor_id INTEGER NOT NULL,
+    merged_id INTEGER NOT NULL,
+    merged_name VARCHAR(200) NOT NULL,
+    merged_record JSONB NOT NULL,
+    repointed JSONB NOT NULL DEFAULT '{}', -- Rows moved to the survivor or dropped as duplicates, by table
+    merged_by VARCHAR(100),
+    notes TEXT,
+    merged_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_merge_entity CHECK (entity_type IN ('make', 'model', 'submodel', 'supplier', 'item')),
+    CONSTRAINT distinct_merge_records CHECK (survivor_id <> merged_id)
 );
 
 -- Create indexes for performance
-CREATE INDEX idx_categories_parent ON categories(parent_category_id);
+CREATE INDEX idx_categories_parent ON categories(parent_category_id, sort_order);
+CREATE INDEX idx_category_attributes_category ON category_attributes(category_id);
+CREATE INDEX idx_item_attribute_values_attribute ON item_attribute_values(attribute_id);
 CREATE INDEX idx_vehicle_models_make ON vehicle_models(make_id);
 CREATE INDEX idx_items_category ON items(category_id);
 CREATE INDEX idx_items_supplier ON items(supplier_id);
 CREATE INDEX idx_items_barcode ON items(barcode);
 CREATE INDEX idx_compatibility_item ON compatibility(item_id);
 CREATE INDEX idx_compatibility_submodel ON compatibility(submodel_id);
+-- An item can fit a submodel several times over, for different years,
+-- positions or engines, but each fitment only once
+CREATE UNIQUE INDEX unique_item_fitment ON compatibility (
+    item_id, submodel_id, COALESCE(year_from, 0), COALESCE(year_to, 0),
+    COALESCE(position, ''), COALESCE(engine_code, '')
+);
+CREATE INDEX idx_kit_components_component ON kit_components(component_item_id);
+CREATE INDEX idx_item_supersessions_new ON item_supersessions(new_item_id);
+CREATE INDEX idx_merge_audit_entity ON merge_audit(entity_type, merged_at);
+CREATE INDEX idx_vin_wmi_codes_make ON vin_wmi_codes(make_id);
+CREATE INDEX idx_vin_patterns_wmi ON vin_patterns(wmi);
+CREATE INDEX idx_vin_patterns_submodel ON vin_patterns(submodel_id);
 CREATE INDEX idx_purchases_supplier ON purchases(supplier_id);
 CREATE INDEX idx_purchases_item ON purchases(item_id);
 CREATE INDEX idx_purchases_date ON purchases(date);
 CREATE INDEX idx_sales_item ON sales(item_id);
 CREATE INDEX idx_sales_date ON sales(date);
+CREATE INDEX idx_sales_customer ON sales(customer_id);
+CREATE INDEX idx_sales_invoice ON sales(invoice_id);
+CREATE INDEX idx_invoices_customer ON invoices(customer_id);
+CREATE INDEX idx_invoices_due_date ON invoices(due_date);
+CREATE INDEX idx_payments_customer ON payments(customer_id);
+CREATE INDEX idx_payment_allocations_payment ON payment_allocations(payment_id);
+CREATE INDEX idx_payment_allocations_invoice ON payment_allocations(invoice_id);
+CREATE INDEX idx_purchases_supplier_invoice ON purchases(supplier_invoice_id);
+CREATE INDEX idx_supplier_invoices_supplier ON supplier_invoices(supplier_id);
+CREATE INDEX idx_supplier_invoices_due_date ON supplier_invoices(due_date);
+CREATE INDEX idx_supplier_payments_invoice ON supplier_payments(supplier_invoice_id);
+CREATE INDEX idx_purchases_purchase_order ON purchases(purchase_order_id);
+CREATE INDEX idx_purchase_orders_supplier ON purchase_orders(supplier_id);
+CREATE INDEX idx_purchase_orders_status ON purchase_orders(status);
+CREATE INDEX idx_purchase_order_lines_order ON purchase_order_lines(purchase_order_id);
+CREATE INDEX idx_purchase_order_lines_item ON purchase_order_lines(item_id);
+CREATE INDEX idx_item_forecasts_generated ON item_forecasts(generated_at);
+CREATE INDEX idx_cost_layers_item ON cost_layers(item_id, received_at);
+CREATE INDEX idx_cost_layers_purchase ON cost_layers(purchase_id);
+CREATE INDEX idx_cost_layer_consumptions_layer ON cost_layer_consumptions(layer_id);
+CREATE INDEX idx_cost_layer_consumptions_sale ON cost_layer_consumptions(sale_id);
+CREATE INDEX idx_item_cost_history_item ON item_cost_history(item_id, effective_at);
+CREATE UNIQUE INDEX idx_warehouses_default ON warehouses(is_default) WHERE is_default;
+CREATE INDEX idx_bin_locations_warehouse ON bin_locations(warehouse_id);
+CREATE INDEX idx_item_stock_warehouse ON item_stock(warehouse_id);
+CREATE INDEX idx_stock_movements_item ON stock_movements(item_id, moved_at);
+CREATE INDEX idx_stock_movements_warehouse ON stock_movements(warehouse_id, moved_at);
+CREATE INDEX idx_sales_warehouse ON sales(warehouse_id);
+CREATE INDEX idx_sales_customer_vehicle ON sales(customer_vehicle_id);
+CREATE INDEX idx_customer_vehicles_customer ON customer_vehicles(customer_id);
+CREATE UNIQUE INDEX idx_customer_vehicles_vin ON customer_vehicles(vin) WHERE vin IS NOT NULL;
+CREATE INDEX idx_customer_vehicles_plate ON customer_vehicles(UPPER(license_plate));
+CREATE INDEX idx_purchases_warehouse ON purchases(warehouse_id);
+CREATE INDEX idx_stock_transfers_status ON stock_transfers(status);
+CREATE INDEX idx_stock_transfers_from ON stock_transfers(from_warehouse_id);
+CREATE INDEX idx_stock_transfers_to ON stock_transfers(to_warehouse_id);
+CREATE INDEX idx_stock_transfer_lines_transfer ON stock_transfer_lines(transfer_id);
+CREATE INDEX idx_stock_transfer_lines_item ON stock_transfer_lines(item_id);
+CREATE INDEX idx_stock_reservations_active ON stock_reservations(item_id, warehouse_id) WHERE status = 'active';
+CREATE INDEX idx_stock_reservations_expiry ON stock_reservations(expires_at) WHERE status = 'active';
+CREATE INDEX idx_stock_reservations_customer ON stock_reservations(customer_id);
+CREATE INDEX idx_special_orders_status ON special_orders(status);
+CREATE INDEX idx_special_orders_item ON special_orders(item_id);
+CREATE INDEX idx_special_orders_customer ON special_orders(customer_id);
+CREATE INDEX idx_special_orders_purchase_order ON special_orders(purchase_order_id);
+CREATE INDEX idx_item_serials_serial_number ON item_serials(serial_number);
+CREATE INDEX idx_item_serials_purchase ON item_serials(purchase_id);
+CREATE INDEX idx_item_serials_sale ON item_serials(sale_id);
+CREATE INDEX idx_item_serials_warehouse ON item_serials(item_id, warehouse_id) WHERE status = 'in_stock';
+CREATE INDEX idx_stock_transfer_serials_serial ON stock_transfer_serials(serial_id);
+CREATE INDEX idx_item_lots_item ON item_lots(item_id);
+CREATE INDEX idx_item_lots_lot_number ON item_lots(lot_number);
+CREATE INDEX idx_sale_lots_lot ON sale_lots(lot_id);
+CREATE INDEX idx_warranty_claims_sale ON warranty_claims(sale_id);
+CREATE INDEX idx_warranty_claims_status ON warranty_claims(status);
+CREATE INDEX idx_warranty_claims_supplier ON warranty_claims(supplier_id);
+CREATE INDEX idx_warranty_claims_serial ON warranty_claims(serial_number);
+CREATE INDEX idx_core_returns_sale ON core_returns(sale_id);
+CREATE INDEX idx_core_returns_item ON core_returns(item_id);
+CREATE INDEX idx_core_supplier_returns_supplier ON core_supplier_returns(supplier_id);
+CREATE INDEX idx_core_supplier_return_lines_item ON core_supplier_return_lines(item_id);
 
 -- Create triggers for updated_at timestamp
 CREATE OR REPLACE FUNCTION update_timestamp()
@@ -196,6 +1014,14 @@ CREATE TRIGGER update_categories_timestamp
 BEFORE UPDATE ON categories
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
 
+CREATE TRIGGER update_category_attributes_timestamp
+BEFORE UPDATE ON category_attributes
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_item_attribute_values_timestamp
+BEFORE UPDATE ON item_attribute_values
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
 CREATE TRIGGER update_vehicle_makes_timestamp
 BEFORE UPDATE ON vehicle_makes
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
@@ -208,6 +1034,10 @@ CREATE TRIGGER update_vehicle_submodels_timestamp
 BEFORE UPDATE ON vehicle_submodels
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
 
+CREATE TRIGGER update_vin_patterns_timestamp
+BEFORE UPDATE ON vin_patterns
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
 CREATE TRIGGER update_suppliers_timestamp
 BEFORE UPDATE ON suppliers
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
@@ -224,14 +1054,381 @@ CREATE TRIGGER update_sales_timestamp
 BEFORE UPDATE ON sales
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
 
--- Create a trigger to update inventory on purchase
+CREATE TRIGGER update_customers_timestamp
+BEFORE UPDATE ON customers
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_customer_vehicles_timestamp
+BEFORE UPDATE ON customer_vehicles
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_invoices_timestamp
+BEFORE UPDATE ON invoices
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_supplier_invoices_timestamp
+BEFORE UPDATE ON supplier_invoices
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_purchase_orders_timestamp
+BEFORE UPDATE ON purchase_orders
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_item_forecasts_timestamp
+BEFORE UPDATE ON item_forecasts
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_warehouses_timestamp
+BEFORE UPDATE ON warehouses
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_bin_locations_timestamp
+BEFORE UPDATE ON bin_locations
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_item_stock_timestamp
+BEFORE UPDATE ON item_stock
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_stock_transfers_timestamp
+BEFORE UPDATE ON stock_transfers
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_stock_reservations_timestamp
+BEFORE UPDATE ON stock_reservations
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_special_orders_timestamp
+BEFORE UPDATE ON special_orders
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_item_serials_timestamp
+BEFORE UPDATE ON item_serials
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_item_lots_timestamp
+BEFORE UPDATE ON item_lots
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_warranty_claims_timestamp
+BEFORE UPDATE ON warranty_claims
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_core_supplier_returns_timestamp
+BEFORE UPDATE ON core_supplier_returns
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+-- The warehouse used when a sale, purchase or stock edit does not name one
+CREATE OR REPLACE FUNCTION default_warehouse_id()
+RETURNS INTEGER AS $$
+DECLARE
+   result INTEGER;
+BEGIN
+   SELECT warehouse_id INTO result FROM warehouses WHERE is_default;
+   IF result IS NULL THEN
+      RAISE EXCEPTION 'no default warehouse is configured';
+   END IF;
+   RETURN result;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION assign_default_warehouse()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF NEW.warehouse_id IS NULL THEN
+      NEW.warehouse_id := default_warehouse_id();
+   END IF;
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_assign_purchase_warehouse
+BEFORE INSERT ON purchases
+FOR EACH ROW EXECUTE PROCEDURE assign_default_warehouse();
+
+CREATE TRIGGER trigger_assign_sale_warehouse
+BEFORE INSERT ON sales
+FOR EACH ROW EXECUTE PROCEDURE assign_default_warehouse();
+
+CREATE TRIGGER trigger_assign_reservation_warehouse
+BEFORE INSERT ON stock_reservations
+FOR EACH ROW EXECUTE PROCEDURE assign_default_warehouse();
+
+-- Units of an item held by active reservations, at one warehouse or at all
+-- of them. A reserved kit holds its components, so a component also counts
+-- the units that go into reserved kits. Reservations past their expiry stop
+-- counting straight away, even before the expiry job closes them.
+CREATE OR REPLACE FUNCTION reserved_stock(
+    p_item_id INTEGER,
+    p_warehouse_id INTEGER DEFAULT NULL,
+    p_exclude_id INTEGER DEFAULT NULL
+)
+RETURNS INTEGER AS $$
+   SELECT COALESCE(SUM(r.quantity * COALESCE(kc.quantity, 1)), 0)::int
+   FROM stock_reservations r
+   LEFT JOIN kit_components kc ON kc.kit_item_id = r.item_id AND kc.component_item_id = p_item_id
+   WHERE (r.item_id = p_item_id OR kc.component_item_id IS NOT NULL)
+      AND r.status = 'active'
+      AND r.expires_at > CURRENT_TIMESTAMP
+      AND (p_warehouse_id IS NULL OR r.warehouse_id = p_warehouse_id)
+      AND (p_exclude_id IS NULL OR r.reservation_id <> p_exclude_id);
+$$ LANGUAGE sql STABLE;
+
+-- Complete kits that can be made up from free component stock. Reserved kits
+-- already hold their components, so they are counted in the components'
+-- reservations. A kit has to be made up at one warehouse, so across all
+-- warehouses this is the sum of what each one can make rather than a pooled
+-- figure.
+CREATE OR REPLACE FUNCTION kit_available_stock(
+    p_kit_item_id INTEGER,
+    p_warehouse_id INTEGER DEFAULT NULL,
+    p_exclude_id INTEGER DEFAULT NULL
+)
+RETURNS INTEGER AS $$
+   SELECT COALESCE(SUM(kits), 0)::int
+   FROM (
+      SELECT w.warehouse_id,
+             MIN(GREATEST(COALESCE(st.quantity, 0) - reserved_stock(kc.component_item_id, w.warehouse_id, p_exclude_id), 0) / kc.quantity) AS kits
+      FROM warehouses w
+      CROSS JOIN kit_components kc
+      LEFT JOIN item_stock st ON st.item_id = kc.component_item_id AND st.warehouse_id = w.warehouse_id
+      WHERE kc.kit_item_id = p_kit_item_id
+         AND (p_warehouse_id IS NULL OR w.warehouse_id = p_warehouse_id)
+      GROUP BY w.warehouse_id
+   ) per_warehouse;
+$$ LANGUAGE sql STABLE;
+
+-- Stock on the shelves that is free to sell: on hand less reservations,
+-- including the units held by reserved kits. Stock in transit between
+-- warehouses is not available. Kits are available as far as their
+-- components are.
+CREATE OR REPLACE FUNCTION available_stock(
+    p_item_id INTEGER,
+    p_warehouse_id INTEGER DEFAULT NULL
+)
+RETURNS INTEGER AS $$
+   SELECT CASE
+      WHEN EXISTS (SELECT 1 FROM items WHERE item_id = p_item_id AND is_kit) THEN
+         kit_available_stock(p_item_id, p_warehouse_id)
+      ELSE GREATEST(
+         COALESCE((
+            SELECT SUM(quantity)
+            FROM item_stock
+            WHERE item_id = p_item_id
+               AND (p_warehouse_id IS NULL OR warehouse_id = p_warehouse_id)
+         ), 0)::int - reserved_stock(p_item_id, p_warehouse_id),
+         0
+      )
+   END;
+$$ LANGUAGE sql STABLE;
+
+-- Move stock in or out of one warehouse and record the movement. Returns the
+-- new balance at that location; a location can never go below zero.
+CREATE OR REPLACE FUNCTION apply_stock_movement(
+    p_item_id INTEGER,
+    p_warehouse_id INTEGER,
+    p_quantity INTEGER,
+    p_type VARCHAR,
+    p_reference_id INTEGER,
+    p_notes TEXT,
+    p_at TIMESTAMP WITH TIME ZONE
+)
+RETURNS INTEGER AS $$
+DECLARE
+   balance INTEGER;
+BEGIN
+   INSERT INTO item_stock (item_id, warehouse_id)
+   VALUES (p_item_id, p_warehouse_id)
+   ON CONFLICT (item_id, warehouse_id) DO NOTHING;
+
+   SELECT quantity INTO balance
+   FROM item_stock
+   WHERE item_id = p_item_id AND warehouse_id = p_warehouse_id
+   FOR UPDATE;
+
+   IF balance + p_quantity < 0 THEN
+      RAISE EXCEPTION 'insufficient stock for item % at warehouse %', p_item_id, p_warehouse_id
+         USING ERRCODE = 'check_violation';
+   END IF;
+
+   balance := balance + p_quantity;
+
+   UPDATE item_stock
+   SET quantity = balance
+   WHERE item_id = p_item_id AND warehouse_id = p_warehouse_id;
+
+   INSERT INTO stock_movements (item_id, warehouse_id, movement_type, quantity, balance_after, reference_id, notes, moved_at)
+   VALUES (p_item_id, p_warehouse_id, p_type, p_quantity, balance, p_reference_id, p_notes, COALESCE(p_at, CURRENT_TIMESTAMP));
+
+   RETURN balance;
+END;
+$$ LANGUAGE plpgsql;
+
+-- items.current_stock is the total over all warehouses plus stock in transit
+-- between them
+CREATE OR REPLACE FUNCTION sync_item_stock_total()
+RETURNS TRIGGER AS $$
+DECLARE
+   target INTEGER;
+   total INTEGER;
+BEGIN
+   IF TG_OP = 'DELETE' THEN
+      target := OLD.item_id;
+   ELSIF TG_OP = 'INSERT' AND NEW.quantity = 0 THEN
+      RETURN NULL;
+   ELSE
+      target := NEW.item_id;
+   END IF;
+
+   SELECT
+      (SELECT COALESCE(SUM(quantity), 0) FROM item_stock WHERE item_id = target) +
+      (SELECT COALESCE(SUM(l.quantity), 0)
+       FROM stock_transfer_lines l
+       JOIN stock_transfers t ON l.transfer_id = t.transfer_id
+       WHERE l.item_id = target AND t.status = 'in_transit')
+   INTO total;
+
+   UPDATE items
+   SET current_stock = total,
+       updated_at = CURRENT_TIMESTAMP
+   WHERE item_id = target AND current_stock <> total;
+
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_sync_item_stock_total
+AFTER INSERT OR DELETE OR UPDATE OF quantity ON item_stock
+FOR EACH ROW EXECUTE PROCEDURE sync_item_stock_total();
+
+-- Draw quantity from an item's cost layers, oldest first, and return the
+-- total cost. Any quantity not covered by a layer is costed at fallback_cost.
+CREATE OR REPLACE FUNCTION consume_cost_layers(
+    p_item_id INTEGER,
+    p_quantity INTEGER,
+    p_sale_id INTEGER,
+    p_at TIMESTAMP WITH TIME ZONE,
+    p_fallback_cost DECIMAL
+)
+RETURNS DECIMAL AS $$
+DECLARE
+   layer RECORD;
+   needed INTEGER := p_quantity;
+   take INTEGER;
+   total DECIMAL := 0;
+BEGIN
+   FOR layer IN
+      SELECT layer_id, remaining_quantity, unit_cost
+      FROM cost_layers
+      WHERE item_id = p_item_id AND remaining_quantity > 0
+      ORDER BY received_at, layer_id
+      FOR UPDATE
+   LOOP
+      EXIT WHEN needed = 0;
+      take := LEAST(needed, layer.remaining_quantity);
+
+      UPDATE cost_layers
+      SET remaining_quantity = remaining_quantity - take
+      WHERE layer_id = layer.layer_id;
+
+      INSERT INTO cost_layer_consumptions (layer_id, item_id, sale_id, consumed_at, quantity, unit_cost)
+      VALUES (layer.layer_id, p_item_id, p_sale_id, p_at, take, layer.unit_cost);
+
+      total := total + take * layer.unit_cost;
+      needed := needed - take;
+   END LOOP;
+
+   IF needed > 0 THEN
+      INSERT INTO cost_layer_consumptions (layer_id, item_id, sale_id, consumed_at, quantity, unit_cost)
+      VALUES (NULL, p_item_id, p_sale_id, p_at, needed, p_fallback_cost);
+
+      total := total + needed * p_fallback_cost;
+   END IF;
+
+   RETURN total;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Open a cost layer for stock added outside a purchase, or draw down layers
+-- for stock removed outside a sale
+CREATE OR REPLACE FUNCTION cost_stock_adjustment(
+    p_item_id INTEGER,
+    p_change INTEGER,
+    p_source VARCHAR,
+    p_unit_cost DECIMAL
+)
+RETURNS VOID AS $$
+BEGIN
+   IF p_change > 0 THEN
+      INSERT INTO cost_layers (item_id, source, received_at, quantity, remaining_quantity, unit_cost)
+      VALUES (p_item_id, p_source, CURRENT_TIMESTAMP, p_change, p_change, p_unit_cost);
+   ELSIF p_change < 0 THEN
+      PERFORM consume_cost_layers(p_item_id, -p_change, NULL, CURRENT_TIMESTAMP, p_unit_cost);
+   END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Adjust stock at one warehouse, e.g. after a count. Costed like a direct
+-- edit of the item's stock; returns the new balance at the location.
+CREATE OR REPLACE FUNCTION adjust_location_stock(
+    p_item_id INTEGER,
+    p_warehouse_id INTEGER,
+    p_change INTEGER,
+    p_notes TEXT
+)
+RETURNS INTEGER AS $$
+DECLARE
+   avg_cost DECIMAL(12,4);
+   balance INTEGER;
+BEGIN
+   SELECT COALESCE(average_cost, buy_price) INTO avg_cost
+   FROM items
+   WHERE item_id = p_item_id
+   FOR UPDATE;
+
+   PERFORM cost_stock_adjustment(p_item_id, p_change, 'adjustment', avg_cost);
+   balance := apply_stock_movement(p_item_id, p_warehouse_id, p_change, 'adjustment', NULL, p_notes, CURRENT_TIMESTAMP);
+
+   INSERT INTO item_cost_history (item_id, effective_at, quantity_on_hand, average_cost, source)
+   SELECT item_id, CURRENT_TIMESTAMP, current_stock, avg_cost, 'adjustment'
+   FROM items
+   WHERE item_id = p_item_id;
+
+   RETURN balance;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create a trigger to update inventory on purchase. Stock is received into
+-- the purchase's warehouse; each purchase also opens a cost layer and moves
+-- the item's weighted-average cost.
 CREATE OR REPLACE FUNCTION update_inventory_on_purchase()
 RETURNS TRIGGER AS $$
+DECLARE
+   on_hand INTEGER;
+   avg_cost DECIMAL(12,4);
+   received TIMESTAMP WITH TIME ZONE := COALESCE(NEW.date, CURRENT_TIMESTAMP);
 BEGIN
+   SELECT GREATEST(current_stock, 0), COALESCE(average_cost, buy_price)
+   INTO on_hand, avg_cost
+   FROM items
+   WHERE item_id = NEW.item_id
+   FOR UPDATE;
+
+   avg_cost := (on_hand * avg_cost + NEW.quantity * NEW.cost_per_unit) / (on_hand + NEW.quantity);
+
+   INSERT INTO cost_layers (item_id, purchase_id, source, received_at, quantity, remaining_quantity, unit_cost)
+   VALUES (NEW.item_id, NEW.purchase_id, 'purchase', received, NEW.quantity, NEW.quantity, NEW.cost_per_unit);
+
+   PERFORM apply_stock_movement(NEW.item_id, NEW.warehouse_id, NEW.quantity, 'purchase', NEW.purchase_id, NULL, received);
+
    UPDATE items
-   SET current_stock = current_stock + NEW.quantity,
+   SET average_cost = avg_cost,
        updated_at = CURRENT_TIMESTAMP
    WHERE item_id = NEW.item_id;
+
+   INSERT INTO item_cost_history (item_id, effective_at, quantity_on_hand, average_cost, source, reference_id)
+   VALUES (NEW.item_id, received, on_hand + NEW.quantity, avg_cost, 'purchase', NEW.purchase_id);
+
    RETURN NEW;
 END;
 $$ LANGUAGE plpgsql;
@@ -240,14 +1437,94 @@ CREATE TRIGGER trigger_update_inventory_on_purchase
 AFTER INSERT ON purchases
 FOR EACH ROW EXECUTE PROCEDURE update_inventory_on_purchase();
 
--- Create a trigger to update inventory on sale
+-- Take the components of a sold kit out of stock at the sale's warehouse.
+-- Each component is costed as if it had been sold on its own and the kit
+-- sale carries the total.
+CREATE OR REPLACE FUNCTION consume_kit_components(
+    p_sale_id INTEGER,
+    p_kit_item_id INTEGER,
+    p_warehouse_id INTEGER,
+    p_quantity INTEGER,
+    p_at TIMESTAMP WITH TIME ZONE
+)
+RETURNS VOID AS $$
+DECLARE
+   component RECORD;
+   needed INTEGER;
+   on_hand INTEGER;
+   avg_cost DECIMAL(12,4);
+   total_fifo DECIMAL := 0;
+   total_average DECIMAL := 0;
+BEGIN
+   IF NOT EXISTS (SELECT 1 FROM kit_components WHERE kit_item_id = p_kit_item_id) THEN
+      RAISE EXCEPTION 'kit % has no components', p_kit_item_id
+         USING ERRCODE = 'check_violation';
+   END IF;
+
+   FOR component IN
+      SELECT component_item_id, quantity
+      FROM kit_components
+      WHERE kit_item_id = p_kit_item_id
+      ORDER BY component_item_id
+   LOOP
+      needed := component.quantity * p_quantity;
+
+      SELECT current_stock, COALESCE(average_cost, buy_price)
+      INTO on_hand, avg_cost
+      FROM items
+      WHERE item_id = component.component_item_id
+      FOR UPDATE;
+
+      total_fifo := total_fifo + consume_cost_layers(component.component_item_id, needed, p_sale_id, p_at, avg_cost);
+      total_average := total_average + needed * avg_cost;
+
+      PERFORM apply_stock_movement(component.component_item_id, p_warehouse_id, -needed, 'sale', p_sale_id, NULL, p_at);
+
+      INSERT INTO item_cost_history (item_id, effective_at, quantity_on_hand, average_cost, source, reference_id)
+      VALUES (component.component_item_id, p_at, on_hand - needed, avg_cost, 'sale', p_sale_id);
+   END LOOP;
+
+   UPDATE sales
+   SET cogs_fifo = ROUND(total_fifo, 2),
+       cogs_average = ROUND(total_average, 2)
+   WHERE sale_id = p_sale_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create a trigger to update inventory on sale. Stock leaves the sale's
+-- warehouse, and the cost of goods is recorded under both FIFO and
+-- weighted-average costing.
 CREATE OR REPLACE FUNCTION update_inventory_on_sale()
 RETURNS TRIGGER AS $$
+DECLARE
+   on_hand INTEGER;
+   avg_cost DECIMAL(12,4);
+   fifo_cost DECIMAL;
+   sold_at TIMESTAMP WITH TIME ZONE := COALESCE(NEW.date, CURRENT_TIMESTAMP);
 BEGIN
-   UPDATE items
-   SET current_stock = current_stock - NEW.quantity,
-       updated_at = CURRENT_TIMESTAMP
-   WHERE item_id = NEW.item_id;
+   IF (SELECT is_kit FROM items WHERE item_id = NEW.item_id) THEN
+      PERFORM consume_kit_components(NEW.sale_id, NEW.item_id, NEW.warehouse_id, NEW.quantity, sold_at);
+      RETURN NEW;
+   END IF;
+
+   SELECT current_stock, COALESCE(average_cost, buy_price)
+   INTO on_hand, avg_cost
+   FROM items
+   WHERE item_id = NEW.item_id
+   FOR UPDATE;
+
+   fifo_cost := consume_cost_layers(NEW.item_id, NEW.quantity, NEW.sale_id, sold_at, avg_cost);
+
+   PERFORM apply_stock_movement(NEW.item_id, NEW.warehouse_id, -NEW.quantity, 'sale', NEW.sale_id, NULL, sold_at);
+
+   UPDATE sales
+   SET cogs_fifo = ROUND(fifo_cost, 2),
+       cogs_average = ROUND(NEW.quantity * avg_cost, 2)
+   WHERE sale_id = NEW.sale_id;
+
+   INSERT INTO item_cost_history (item_id, effective_at, quantity_on_hand, average_cost, source, reference_id)
+   VALUES (NEW.item_id, sold_at, on_hand - NEW.quantity, avg_cost, 'sale', NEW.sale_id);
+
    RETURN NEW;
 END;
 $$ LANGUAGE plpgsql;
@@ -256,6 +1533,467 @@ CREATE TRIGGER trigger_update_inventory_on_sale
 AFTER INSERT ON sales
 FOR EACH ROW EXECUTE PROCEDURE update_inventory_on_sale();
 
+-- Keep cost layers and location stock in step with stock entered or corrected
+-- directly on the item; the difference is booked to the default warehouse.
+-- Changes made by the other stock triggers are skipped since they record
+-- their own layers and movements.
+CREATE OR REPLACE FUNCTION record_cost_on_stock_change()
+RETURNS TRIGGER AS $$
+DECLARE
+   change INTEGER;
+   avg_cost DECIMAL(12,4) := COALESCE(NEW.average_cost, NEW.buy_price);
+   source_name VARCHAR(20) := 'adjustment';
+BEGIN
+   IF pg_trigger_depth() > 1 THEN
+      RETURN NEW;
+   END IF;
+
+   IF TG_OP = 'INSERT' THEN
+      change := NEW.current_stock;
+      source_name := 'opening';
+      UPDATE items SET average_cost = avg_cost WHERE item_id = NEW.item_id;
+   ELSE
+      change := NEW.current_stock - OLD.current_stock;
+   END IF;
+
+   PERFORM cost_stock_adjustment(NEW.item_id, change, source_name, avg_cost);
+
+   IF change <> 0 THEN
+      PERFORM apply_stock_movement(NEW.item_id, default_warehouse_id(), change, source_name, NULL, NULL, CURRENT_TIMESTAMP);
+   END IF;
+
+   IF change <> 0 OR TG_OP = 'INSERT' THEN
+      INSERT INTO item_cost_history (item_id, effective_at, quantity_on_hand, average_cost, source)
+      VALUES (NEW.item_id, CURRENT_TIMESTAMP, NEW.current_stock, avg_cost, source_name);
+   END IF;
+
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_record_cost_on_item_insert
+AFTER INSERT ON items
+FOR EACH ROW EXECUTE PROCEDURE record_cost_on_stock_change();
+
+CREATE TRIGGER trigger_record_cost_on_stock_change
+AFTER UPDATE OF current_stock ON items
+FOR EACH ROW EXECUTE PROCEDURE record_cost_on_stock_change();
+
+-- Fill waiting special orders from a delivery: orders placed on the
+-- delivery's purchase order first, then backorders for the item, oldest
+-- first. Received units are held for the customer at the receiving warehouse
+-- as they arrive, so an order already holding stock elsewhere waits for a
+-- delivery there. A complete order is marked ready.
+CREATE OR REPLACE FUNCTION fill_special_orders()
+RETURNS TRIGGER AS $$
+DECLARE
+   remaining INTEGER := NEW.quantity;
+   waiting RECORD;
+   filled INTEGER;
+   held_by INTEGER;
+BEGIN
+   -- Close holds past their expiry first so the orders start over
+   UPDATE stock_reservations r
+   SET status = 'expired', closed_at = r.expires_at
+   FROM special_orders so
+   WHERE so.reservation_id = r.reservation_id
+      AND so.item_id = NEW.item_id
+      AND so.status IN ('requested', 'ordered')
+      AND r.status = 'active'
+      AND r.expires_at <= CURRENT_TIMESTAMP;
+
+   FOR waiting IN
+      SELECT so.special_order_id, so.customer_id, so.customer_name, so.customer_phone,
+         so.quantity, so.quantity_received, so.hold_days, r.reservation_id
+      FROM special_orders so
+      LEFT JOIN stock_reservations r ON r.reservation_id = so.reservation_id AND r.status = 'active'
+      WHERE so.item_id = NEW.item_id
+         AND so.status IN ('requested', 'ordered')
+         AND (so.purchase_order_id IS NULL OR so.purchase_order_id = NEW.purchase_order_id)
+         AND (r.reservation_id IS NULL OR r.warehouse_id = NEW.warehouse_id)
+      ORDER BY (so.purchase_order_id IS NULL), so.created_at, so.special_order_id
+      FOR UPDATE OF so
+   LOOP
+      EXIT WHEN remaining <= 0;
+
+      filled := LEAST(remaining, waiting.quantity - waiting.quantity_received);
+      remaining := remaining - filled;
+
+      UPDATE stock_reservations
+      SET quantity = quantity + filled,
+          expires_at = CURRENT_TIMESTAMP + waiting.hold_days * INTERVAL '1 day'
+      WHERE reservation_id = waiting.reservation_id
+      RETURNING reservation_id INTO held_by;
+
+      IF held_by IS NULL THEN
+         INSERT INTO stock_reservations (
+            item_id, warehouse_id, quantity, customer_id, customer_name,
+            customer_phone, reference, expires_at, notes
+         ) VALUES (
+            NEW.item_id, NEW.warehouse_id, filled, waiting.customer_id,
+            waiting.customer_name, waiting.customer_phone,
+            'SPECIAL-' || waiting.special_order_id,
+            CURRENT_TIMESTAMP + waiting.hold_days * INTERVAL '1 day',
+            'Held for special order'
+         )
+         RETURNING reservation_id INTO held_by;
+      END IF;
+
+      IF waiting.quantity_received + filled < waiting.quantity THEN
+         UPDATE special_orders
+         SET quantity_received = quantity_received + filled,
+             reservation_id = held_by
+         WHERE special_order_id = waiting.special_order_id;
+      ELSE
+         UPDATE special_orders
+         SET quantity_received = quantity,
+             status = 'ready',
+             ready_at = CURRENT_TIMESTAMP,
+             reservation_id = held_by
+         WHERE special_order_id = waiting.special_order_id;
+      END IF;
+   END LOOP;
+
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_fill_special_orders
+AFTER INSERT ON purchases
+FOR EACH ROW EXECUTE PROCEDURE fill_special_orders();
+
+-- A ready special order is collected when its reservation is sold. The
+-- units held for an order still being received cannot be sold.
+CREATE OR REPLACE FUNCTION collect_special_order()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF NEW.status = 'converted' AND OLD.status <> 'converted' THEN
+      IF EXISTS (
+         SELECT 1 FROM special_orders
+         WHERE reservation_id = NEW.reservation_id
+            AND status IN ('requested', 'ordered')
+      ) THEN
+         RAISE EXCEPTION 'reservation % holds units for a special order that is not ready', NEW.reservation_id;
+      END IF;
+
+      UPDATE special_orders
+      SET status = 'collected',
+          sale_id = NEW.sale_id,
+          closed_at = CURRENT_TIMESTAMP
+      WHERE reservation_id = NEW.reservation_id AND status = 'ready';
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_collect_special_order
+AFTER UPDATE OF status ON stock_reservations
+FOR EACH ROW EXECUTE PROCEDURE collect_special_order();
+
+-- A special order's hold that expires or is released gives its units
+-- back. A ready order goes back to waiting for the next delivery of the
+-- item; one still being received waits for all of its units again.
+CREATE OR REPLACE FUNCTION lapse_special_order_hold()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF NEW.status IN ('expired', 'released') AND OLD.status = 'active' THEN
+      UPDATE special_orders
+      SET status = CASE WHEN status = 'ready' THEN 'requested' ELSE status END,
+          purchase_order_id = CASE WHEN status = 'ready' THEN NULL ELSE purchase_order_id END,
+          ordered_at = CASE WHEN status = 'ready' THEN NULL ELSE ordered_at END,
+          quantity_received = 0,
+          reservation_id = NULL,
+          ready_at = NULL,
+          notified_at = NULL
+      WHERE reservation_id = NEW.reservation_id
+         AND status IN ('requested', 'ordered', 'ready');
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_lapse_special_order_hold
+AFTER UPDATE OF status ON stock_reservations
+FOR EACH ROW EXECUTE PROCEDURE lapse_special_order_hold();
+
+-- Special orders on a cancelled purchase order go back to waiting, keeping
+-- anything already received
+CREATE OR REPLACE FUNCTION release_special_orders()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF NEW.status = 'cancelled' AND OLD.status <> 'cancelled' THEN
+      UPDATE special_orders
+      SET status = 'requested',
+          purchase_order_id = NULL,
+          ordered_at = NULL
+      WHERE purchase_order_id = NEW.purchase_order_id AND status = 'ordered';
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_release_special_orders
+AFTER UPDATE OF status ON purchase_orders
+FOR EACH ROW EXECUTE PROCEDURE release_special_orders();
+
+-- Whether the current transaction is merging duplicate records. Merges
+-- repoint sales to the surviving item without changing the warranty or core
+-- deposit the sale was made with.
+CREATE OR REPLACE FUNCTION merging_records()
+RETURNS BOOLEAN AS $$
+   SELECT COALESCE(current_setting('autoparts.merging', true), '') = 'on';
+$$ LANGUAGE sql STABLE;
+
+-- Length of a warranty as an interval, NULL for items without one
+CREATE OR REPLACE FUNCTION warranty_interval(p_length INTEGER, p_unit VARCHAR)
+RETURNS INTERVAL AS $$
+   SELECT CASE p_unit
+      WHEN 'days' THEN make_interval(days => p_length)
+      WHEN 'months' THEN make_interval(months => p_length)
+      WHEN 'years' THEN make_interval(years => p_length)
+   END;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Fix the warranty expiry of a sale from the item's warranty, so later
+-- changes to the item do not alter warranties already given
+CREATE OR REPLACE FUNCTION set_sale_warranty()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF TG_OP = 'UPDATE' AND merging_records() THEN
+      RETURN NEW;
+   END IF;
+
+   IF TG_OP = 'INSERT' OR NEW.item_id <> OLD.item_id OR NEW.date <> OLD.date THEN
+      SELECT NEW.date + warranty_interval(warranty_length, warranty_unit)
+      INTO NEW.warranty_expires_at
+      FROM items
+      WHERE item_id = NEW.item_id;
+   END IF;
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_set_sale_warranty
+BEFORE INSERT OR UPDATE OF item_id, date ON sales
+FOR EACH ROW EXECUTE PROCEDURE set_sale_warranty();
+
+-- Take the core deposit on a sale from the item's core charge. A quantity
+-- change keeps the per unit deposit the sale was made with.
+CREATE OR REPLACE FUNCTION set_sale_core_deposit()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF TG_OP = 'UPDATE' AND merging_records() THEN
+      RETURN NEW;
+   END IF;
+
+   IF TG_OP = 'INSERT' OR NEW.item_id <> OLD.item_id THEN
+      SELECT COALESCE(core_charge, 0) * NEW.quantity
+      INTO NEW.core_deposit
+      FROM items
+      WHERE item_id = NEW.item_id;
+   ELSIF NEW.quantity <> OLD.quantity THEN
+      NEW.core_deposit := OLD.core_deposit / OLD.quantity * NEW.quantity;
+   END IF;
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_set_sale_core_deposit
+BEFORE INSERT OR UPDATE OF item_id, quantity ON sales
+FOR EACH ROW EXECUTE PROCEDURE set_sale_core_deposit();
+
+-- Function to keep a customer vehicle's mileage at the highest reading a
+-- sale has recorded for it
+CREATE OR REPLACE FUNCTION update_vehicle_mileage_on_sale()
+RETURNS TRIGGER AS $$
+BEGIN
+   IF NEW.customer_vehicle_id IS NOT NULL AND NEW.vehicle_mileage IS NOT NULL THEN
+      UPDATE customer_vehicles
+      SET mileage = NEW.vehicle_mileage
+      WHERE vehicle_id = NEW.customer_vehicle_id
+        AND (mileage IS NULL OR mileage < NEW.vehicle_mileage);
+   END IF;
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_vehicle_mileage_on_sale
+AFTER INSERT OR UPDATE OF customer_vehicle_id, vehicle_mileage ON sales
+FOR EACH ROW EXECUTE PROCEDURE update_vehicle_mileage_on_sale();
+
+-- Bring a kit's prices in line with its components: the buy price is always
+-- the components' total, and a discount-priced kit sells at the components'
+-- total less its discount. Kits without components are left alone.
+CREATE OR REPLACE FUNCTION refresh_kit_prices(p_kit_item_id INTEGER)
+RETURNS VOID AS $$
+   UPDATE items k
+   SET buy_price = totals.buy_price,
+       sell_price = CASE
+          WHEN k.kit_pricing = 'discount' THEN ROUND(totals.sell_price * (100 - k.kit_discount_percent) / 100, 2)
+          ELSE k.sell_price
+       END
+   FROM (
+      SELECT SUM(c.buy_price * kc.quantity) AS buy_price,
+             SUM(c.sell_price * kc.quantity) AS sell_price
+      FROM kit_components kc
+      JOIN items c ON c.item_id = kc.component_item_id
+      WHERE kc.kit_item_id = p_kit_item_id
+   ) totals
+   WHERE k.item_id = p_kit_item_id
+      AND k.is_kit
+      AND totals.buy_price IS NOT NULL;
+$$ LANGUAGE sql;
+
+-- Reprice the kits an item goes into when its own prices change
+CREATE OR REPLACE FUNCTION refresh_kit_prices_on_price_change()
+RETURNS TRIGGER AS $$
+BEGIN
+   PERFORM refresh_kit_prices(kit_item_id)
+   FROM kit_components
+   WHERE component_item_id = NEW.item_id;
+
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_refresh_kit_prices_on_price_change
+AFTER UPDATE OF buy_price, sell_price ON items
+FOR EACH ROW
+WHEN (NOT NEW.is_kit AND (OLD.buy_price, OLD.sell_price) IS DISTINCT FROM (NEW.buy_price, NEW.sell_price))
+EXECUTE PROCEDURE refresh_kit_prices_on_price_change();
+
+-- The supersessions leading on from an item, nearest first
+CREATE OR REPLACE FUNCTION supersession_chain(p_item_id INTEGER)
+RETURNS TABLE (
+    supersession_id INTEGER,
+    old_item_id INTEGER,
+    new_item_id INTEGER,
+    is_direct_interchange BOOLEAN,
+    depth INTEGER
+) AS $$
+   WITH RECURSIVE chain AS (
+      SELECT s.supersession_id, s.old_item_id, s.new_item_id, s.is_direct_interchange, 1 AS depth
+      FROM item_supersessions s
+      WHERE s.old_item_id = p_item_id
+      UNION ALL
+      SELECT s.supersession_id, s.old_item_id, s.new_item_id, s.is_direct_interchange, c.depth + 1
+      FROM chain c
+      JOIN item_supersessions s ON s.old_item_id = c.new_item_id
+      WHERE c.depth < 50
+   )
+   SELECT * FROM chain ORDER BY depth;
+$$ LANGUAGE sql STABLE;
+
+-- The part that currently replaces an item: the end of its supersession
+-- chain, or the item itself when it has not been superseded
+CREATE OR REPLACE FUNCTION current_item_id(p_item_id INTEGER)
+RETURNS INTEGER AS $$
+   SELECT COALESCE(
+      (SELECT c.new_item_id FROM supersession_chain(p_item_id) c ORDER BY c.depth DESC LIMIT 1),
+      p_item_id
+   );
+$$ LANGUAGE sql STABLE;
+
+-- A name reduced to lower case words, so "Mercedes-Benz" and "mercedes benz"
+-- compare equal
+CREATE OR REPLACE FUNCTION normalize_name(p_name TEXT)
+RETURNS TEXT AS $$
+   SELECT btrim(regexp_replace(lower(p_name), '[^a-z0-9]+', ' ', 'g'));
+$$ LANGUAGE sql IMMUTABLE;
+
+-- A part number, phone number or the like with only its letters and digits
+CREATE OR REPLACE FUNCTION normalize_code(p_code TEXT)
+RETURNS TEXT AS $$
+   SELECT regexp_replace(lower(p_code), '[^a-z0-9]+', '', 'g');
+$$ LANGUAGE sql IMMUTABLE;
+
+-- How alike two names are, from 0 to 1. Names that differ only in spacing
+-- and punctuation score 1.
+CREATE OR REPLACE FUNCTION name_similarity(p_a TEXT, p_b TEXT)
+RETURNS REAL AS $$
+   SELECT CASE
+      WHEN normalize_code(p_a) = normalize_code(p_b) THEN 1
+      ELSE similarity(normalize_name(p_a), normalize_name(p_b))
+   END;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- A category and the categories above it, nearest first. The CYCLE clause
+-- ends the walk should the tree ever loop, however deep it is.
+CREATE OR REPLACE FUNCTION category_lineage(p_category_id INTEGER)
+RETURNS TABLE (category_id INTEGER, depth INTEGER) AS $$
+   WITH RECURSIVE lineage AS (
+      SELECT c.category_id, c.parent_category_id, 0 AS depth
+      FROM categories c
+      WHERE c.category_id = p_category_id
+      UNION ALL
+      SELECT c.category_id, c.parent_category_id, l.depth + 1
+      FROM lineage l
+      JOIN categories c ON c.category_id = l.parent_category_id
+   ) CYCLE category_id SET is_cycle USING path
+   SELECT l.category_id, l.depth FROM lineage l WHERE NOT l.is_cycle ORDER BY l.depth;
+$$ LANGUAGE sql STABLE;
+
+-- A category and every category below it
+CREATE OR REPLACE FUNCTION category_subtree(p_category_id INTEGER)
+RETURNS TABLE (category_id INTEGER, depth INTEGER) AS $$
+   WITH RECURSIVE subtree AS (
+      SELECT c.category_id, 0 AS depth
+      FROM categories c
+      WHERE c.category_id = p_category_id
+      UNION ALL
+      SELECT c.category_id, s.depth + 1
+      FROM subtree s
+      JOIN categories c ON c.parent_category_id = s.category_id
+   ) CYCLE category_id SET is_cycle USING path
+   SELECT s.category_id, s.depth FROM subtree s WHERE NOT s.is_cycle ORDER BY s.depth;
+$$ LANGUAGE sql STABLE;
+
+-- Drop the attribute values an item no longer has once it moves to a
+-- category without those attributes
+CREATE OR REPLACE FUNCTION prune_item_attribute_values()
+RETURNS TRIGGER AS $$
+BEGIN
+   DELETE FROM item_attribute_values v
+   USING category_attributes a
+   WHERE v.item_id = NEW.item_id
+      AND a.attribute_id = v.attribute_id
+      AND a.category_id NOT IN (SELECT l.category_id FROM category_lineage(NEW.category_id) l);
+
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_prune_item_attribute_values
+AFTER UPDATE OF category_id ON items
+FOR EACH ROW
+WHEN (OLD.category_id IS DISTINCT FROM NEW.category_id)
+EXECUTE PROCEDURE prune_item_attribute_values();
+
+-- Likewise for the items anywhere below a category that moves to a new
+-- parent, which may take attributes it inherited away from them
+CREATE OR REPLACE FUNCTION prune_subtree_attribute_values()
+RETURNS TRIGGER AS $$
+BEGIN
+   DELETE FROM item_attribute_values v
+   USING items i
+   WHERE v.item_id = i.item_id
+      AND i.category_id IN (SELECT s.category_id FROM category_subtree(NEW.category_id) s)
+      AND v.attribute_id NOT IN (
+         SELECT a.attribute_id
+         FROM category_lineage(i.category_id) l
+         JOIN category_attributes a ON a.category_id = l.category_id
+      );
+
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_prune_subtree_attribute_values
+AFTER UPDATE OF parent_category_id ON categories
+FOR EACH ROW
+WHEN (OLD.parent_category_id IS DISTINCT FROM NEW.parent_category_id)
+EXECUTE PROCEDURE prune_subtree_attribute_values();
+
 -- Insert some sample data for categories
 INSERT INTO categories (category_name, description) VALUES
 ('Engine Parts', 'Parts related to the engine system'),
@@ -280,6 +2018,14 @@ INSERT INTO categories (category_name, description, parent_category_id) VALUES
 ('Oil Filters', 'Filtration for engine oil', 6),
 ('Air Filters', 'Filtration for engine air intake', 6);
 
+-- Insert some sample category attributes
+INSERT INTO category_attributes (category_id, attribute_key, label, data_type, unit, allowed_values, min_value, max_value, is_required, sort_order) VALUES
+(9, 'friction_material', 'Friction Material', 'enum', NULL, ARRAY['Ceramic', 'Semi-metallic', 'Organic'], NULL, NULL, TRUE, 1),
+(9, 'axle', 'Axle', 'enum', NULL, ARRAY['Front', 'Rear'], NULL, NULL, TRUE, 2),
+(17, 'thread_size', 'Thread Size', 'text', NULL, NULL, NULL, NULL, FALSE, 1),
+(6, 'outer_diameter', 'Outer Diameter', 'number', 'mm', NULL, 0, 500, FALSE, 1),
+(13, 'bulb_type', 'Bulb Type', 'enum', NULL, ARRAY['H1', 'H4', 'H7', 'H11', 'D1S', 'D2S', 'LED'], NULL, NULL, FALSE, 1);
+
 -- Insert some sample vehicle makes
 INSERT INTO vehicle_makes (make_name, country) VALUES
 ('Toyota', 'Japan'),
@@ -362,12 +2108,49 @@ INSERT INTO vehicle_submodels (model_id, submodel_name, year_from, year_to, engi
 (10, 'A3 Sportback', 2016, 2020, 'Inline-4 Turbo', 1.4, 'Gasoline', 'Automatic', 'Sportback'),
 (10, 'A3 Hatchback', 2016, 2020, 'Inline-4 Turbo', 1.4, 'Gasoline', 'Manual', 'Hatchback');
 
+-- Insert manufacturer identifiers for the sample makes
+INSERT INTO vin_wmi_codes (wmi, make_id, manufacturer, country)
+SELECT w.wmi, mk.make_id, w.manufacturer, w.country
+FROM (VALUES
+    ('JTD', 'Toyota', 'Toyota Motor Corporation', 'Japan'),
+    ('4T1', 'Toyota', 'Toyota Motor Manufacturing Kentucky', 'USA'),
+    ('2T1', 'Toyota', 'Toyota Motor Manufacturing Canada', 'Canada'),
+    ('JHM', 'Honda', 'Honda Motor Co.', 'Japan'),
+    ('1HG', 'Honda', 'Honda of America Mfg.', 'USA'),
+    ('2HG', 'Honda', 'Honda of Canada Mfg.', 'Canada'),
+    ('1FA', 'Ford', 'Ford Motor Company (cars)', 'USA'),
+    ('1FT', 'Ford', 'Ford Motor Company (trucks)', 'USA'),
+    ('1G1', 'Chevrolet', 'General Motors (Chevrolet cars)', 'USA'),
+    ('1GC', 'Chevrolet', 'General Motors (Chevrolet trucks)', 'USA'),
+    ('WBA', 'BMW', 'BMW AG', 'Germany'),
+    ('WBS', 'BMW', 'BMW M GmbH', 'Germany'),
+    ('WDD', 'Mercedes-Benz', 'Mercedes-Benz AG', 'Germany'),
+    ('W1K', 'Mercedes-Benz', 'Mercedes-Benz AG', 'Germany'),
+    ('WVW', 'Volkswagen', 'Volkswagen AG (cars)', 'Germany'),
+    ('3VW', 'Volkswagen', 'Volkswagen de Mexico', 'Mexico'),
+    ('KMH', 'Hyundai', 'Hyundai Motor Company', 'South Korea'),
+    ('WAU', 'Audi', 'Audi AG', 'Germany'),
+    ('JN1', 'Nissan', 'Nissan Motor Co.', 'Japan'),
+    ('1N4', 'Nissan', 'Nissan North America', 'USA')
+) AS w(wmi, make_name, manufacturer, country)
+JOIN vehicle_makes mk ON mk.make_name = w.make_name;
+
 -- Insert some sample suppliers
-INSERT INTO suppliers (name, contact_person, phone, email, address, tax_id, payment_terms) VALUES
-('Auto Parts Wholesale Inc.', 'John Smith', '555-123-4567', 'john@apw.com', '123 Main St, Anytown, USA', 'APW-12345', 'Net 30'),
-('Quality Parts Supply', 'Jane Doe', '555-234-5678', 'jane@qps.com', '456 Second Ave, Othertown, USA', 'QPS-67890', 'Net 45'),
-('Import Auto Parts', 'Bob Johnson', '555-345-6789', 'bob@importauto.com', '789 Third Blvd, Somewhere, USA', 'IAP-24680', 'COD'),
-('OEM Suppliers Ltd.', 'Mary Wilson', '555-456-7890', 'mary@oemsuppliers.com', '321 Fourth St, Elsewhere, USA', 'OEM-13579', 'Net 60');
+INSERT INTO suppliers (name, contact_person, phone, email, address, tax_id, payment_terms, lead_time_days) VALUES
+('Auto Parts Wholesale Inc.', 'John Smith', '555-123-4567', 'john@apw.com', '123 Main St, Anytown, USA', 'APW-12345', 'Net 30', 3),
+('Quality Parts Supply', 'Jane Doe', '555-234-5678', 'jane@qps.com', '456 Second Ave, Othertown, USA', 'QPS-67890', 'Net 45', 5),
+('Import Auto Parts', 'Bob Johnson', '555-345-6789', 'bob@importauto.com', '789 Third Blvd, Somewhere, USA', 'IAP-24680', 'COD', 21),
+('OEM Suppliers Ltd.', 'Mary Wilson', '555-456-7890', 'mary@oemsuppliers.com', '321 Fourth St, Elsewhere, USA', 'OEM-13579', 'Net 60', 10);
+
+-- Insert some sample trade customers
+INSERT INTO customers (name, contact_person, phone, email, address, credit_limit, payment_terms_days) VALUES
+('Main Street Garage', 'Pete Lawson', '555-901-1001', 'accounts@mainstreetgarage.com', '12 Main St, Anytown, USA', 2500.00, 30),
+('Quick Fix Motors', 'Anna Reyes', '555-901-2002', 'anna@quickfixmotors.com', '88 Industrial Rd, Othertown, USA', 1000.00, 14);
+
+-- Insert the shop and the second warehouse
+INSERT INTO warehouses (code, name, address, is_default) VALUES
+('MAIN', 'Main Shop', '1 Market St, Anytown, USA', TRUE),
+('WH2', 'Second Warehouse', '40 Depot Rd, Anytown, USA', FALSE);
 
 -- Insert some sample items
 INSERT INTO items (part_number, description, category_id, buy_price, sell_price, current_stock, minimum_stock, barcode, supplier_id, location_aisle, location_shelf, location_bin) VALUES
@@ -382,6 +2165,27 @@ INSERT INTO items (part_number, description, category_id, buy_price, sell_price,
 ('AL-7890', 'Alternator - 120A', 8, 65.25, 129.99, 9, 5, 'AL7890120A', 1, 'D', '2', '4'),
 ('TB-8901', 'Timing Belt Kit', 2, 48.75, 94.99, 22, 8, 'TB8901KIT', 4, 'A', '3', '2');
 
+-- Insert a sample kit made up of stocked items
+INSERT INTO items (part_number, description, category_id, buy_price, sell_price, current_stock, minimum_stock, is_kit, kit_pricing, kit_discount_percent) VALUES
+('KIT-BP-FR', 'Brake Pad Set - Front and Rear', 3, 48.25, 86.38, 0, 0, TRUE, 'discount', 10.00);
+
+INSERT INTO kit_components (kit_item_id, component_item_id, quantity) VALUES
+(11, 1, 1),
+(11, 2, 1);
+
+-- Turn the items' shelf locations into bins at the main shop
+INSERT INTO bin_locations (warehouse_id, code, aisle, shelf, bin)
+SELECT DISTINCT 1, location_aisle || '-' || location_shelf || '-' || location_bin, location_aisle, location_shelf, location_bin
+FROM items
+WHERE location_aisle IS NOT NULL;
+
+UPDATE item_stock st
+SET bin_id = b.bin_id
+FROM items i
+JOIN bin_locations b ON b.warehouse_id = 1
+    AND b.code = i.location_aisle || '-' || i.location_shelf || '-' || i.location_bin
+WHERE st.item_id = i.item_id AND st.warehouse_id = 1;
+
 -- Insert some sample compatibility records
 INSERT INTO compatibility (item_id, submodel_id, notes) VALUES
 -- Front brake pads
@@ -433,6 +2237,17 @@ INSERT INTO compatibility (item_id, submodel_id, notes) VALUES
 (3, 31, 'Compatible with all engines'), -- Oil filter for Audi A3 Sportback
 (3, 32, 'Compatible with all engines'); -- Oil filter for Audi A3 Hatchback
 
+UPDATE compatibility SET position = 'front' WHERE item_id IN (1, 5);
+UPDATE compatibility SET position = 'rear' WHERE item_id = 2;
+UPDATE compatibility SET position = 'front_left' WHERE item_id = 7;
+UPDATE compatibility SET position = 'front_right' WHERE item_id = 8;
+
+-- Insert some sample customer vehicles
+INSERT INTO customer_vehicles (customer_id, submodel_id, model_year, vin, license_plate, mileage) VALUES
+(1, 1, 2019, '4T1B11HK8KU123456', 'MSG-101', 48200), -- Toyota Camry SE
+(1, 7, 2018, NULL, 'MSG-207', 61500), -- Honda Civic Sedan
+(2, 4, 2021, NULL, 'QFM-330', 22800); -- Toyota Corolla LE
+
 -- Insert some purchase records
 INSERT INTO purchases (supplier_id, item_id, quantity, cost_per_unit, total_cost, invoice_number, received_by) VALUES
 (1, 1, 20, 25.50, 510.00, 'INV-2023-001', 'Mike Johnson'),
@@ -508,6 +2323,19 @@ GROUP BY
 ORDER BY
     daily_sales_rate DESC;
 
+-- Units sales take out of stock, per item: the item sold, or for a kit each
+-- of its components times the kit quantity, by the kit's current make-up.
+-- Kits hold no stock, so they have no demand of their own.
+CREATE OR REPLACE VIEW item_sales_demand AS
+SELECT s.sale_id, s.date, s.item_id, s.quantity, s.warehouse_id
+FROM sales s
+JOIN items i ON i.item_id = s.item_id
+WHERE NOT i.is_kit
+UNION ALL
+SELECT s.sale_id, s.date, kc.component_item_id, s.quantity * kc.quantity, s.warehouse_id
+FROM sales s
+JOIN kit_components kc ON kc.kit_item_id = s.item_id;
+
 -- Create view for top selling items
 CREATE OR REPLACE VIEW top_selling_items AS
 SELECT
@@ -530,13 +2358,61 @@ GROUP BY
 ORDER BY
     total_revenue DESC;
 
+-- Daily sales totals per item and salesperson for the analytics API. Only
+-- completed days are included; the analytics job refreshes it and queries
+-- read anything newer straight from sales.
+CREATE MATERIALIZED VIEW sales_daily_summary AS
+SELECT
+    date_trunc('day', s.date)::date as day,
+    s.item_id,
+    COALESCE(s.sold_by, '') as sold_by,
+    COUNT(*) as sale_count,
+    SUM(s.quantity) as quantity,
+    SUM(s.total_price) as revenue,
+    SUM(COALESCE(s.cogs_fifo, s.quantity * i.buy_price)) as cost_fifo,
+    SUM(COALESCE(s.cogs_average, s.quantity * i.buy_price)) as cost_average
+FROM
+    sales s
+JOIN
+    items i ON s.item_id = i.item_id
+WHERE
+    s.date < CURRENT_DATE
+GROUP BY
+    1, 2, 3;
+
+-- Needed for REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX idx_sales_daily_summary_key ON sales_daily_summary(day, item_id, sold_by);
+CREATE INDEX idx_sales_daily_summary_item ON sales_daily_summary(item_id, day);
+
+-- Stock per item per warehouse, including locations that are empty
+CREATE OR REPLACE VIEW item_stock_levels AS
+SELECT
+    st.item_id,
+    i.part_number,
+    i.description,
+    st.warehouse_id,
+    w.code as warehouse_code,
+    w.name as warehouse_name,
+    st.bin_id,
+    b.code as bin_code,
+    st.quantity,
+    i.current_stock as total_stock
+FROM
+    item_stock st
+JOIN
+    items i ON st.item_id = i.item_id
+JOIN
+    warehouses w ON st.warehouse_id = w.warehouse_id
+LEFT JOIN
+    bin_locations b ON st.bin_id = b.bin_id;
+
 -- Create view for vehicle compatibility count
 CREATE OR REPLACE VIEW part_compatibility_summary AS
 SELECT
     i.item_id,
     i.part_number,
     i.description,
-    COUNT(c.compat_id) as compatible_vehicle_count,
+    COUNT(DISTINCT c.submodel_id) as compatible_vehicle_count,
     COUNT(DISTINCT vmod.model_id) as compatible_model_count,
     STRING_AGG(DISTINCT vm.make_name, ', ') as compatible_makes
 FROM
@@ -567,7 +2443,7 @@ RETURNS TABLE (
 ) AS $
 BEGIN
     RETURN QUERY
-    SELECT
+    SELECT DISTINCT
         i.item_id,
         i.part_number,
         i.description,
@@ -591,8 +2467,8 @@ BEGIN
         vma.make_name = make_name
         AND vm.model_name = model_name
         AND vsub.submodel_name = submodel_name
-        AND vsub.year_from <= model_year
-        AND (vsub.year_to IS NULL OR vsub.year_to >= model_year)
+        AND COALESCE(comp.year_from, vsub.year_from) <= model_year
+        AND COALESCE(comp.year_to, vsub.year_to, model_year) >= model_year
     ORDER BY
         c.category_name, i.part_number;
 END;
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// JobFunc is the work a scheduled job performs on each run
+type JobFunc func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       JobFunc
+}
+
+// Scheduler runs registered jobs in the background at fixed intervals.
+// Each job runs once when the scheduler starts and then on every tick; a
+// run that is still going when the next tick arrives skips that tick.
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []*job
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+}
+
+// New returns an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers fn to run every interval. Jobs registered after Start are
+// picked up on the next Start.
+func (s *Scheduler) Every(name string, interval time.Duration, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, &job{name: name, interval: interval, fn: fn})
+}
+
+// Start launches all registered jobs
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.running = true
+
+	for _, j := range s.jobs {
+		if j.interval <= 0 {
+			log.Printf("Scheduler: job %s has no interval, not starting", j.name)
+			continue
+		}
+
+		s.wg.Add(1)
+		go s.run(ctx, j)
+	}
+
+	log.Printf("Scheduler started with %d job(s)", len(s.jobs))
+}
+
+// Stop cancels all jobs and waits for in-flight runs to finish
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.cancel()
+	s.running = false
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	log.Println("Scheduler stopped")
+}
+
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	s.execute(ctx, j)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.execute(ctx, j)
+		}
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, j *job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduler: job %s panicked: %v", j.name, r)
+		}
+	}()
+
+	start := time.Now()
+	if err := j.fn(ctx); err != nil {
+		log.Printf("Scheduler: job %s failed: %v", j.name, err)
+		return
+	}
+	log.Printf("Scheduler: job %s finished in %s", j.name, time.Since(start).Round(time.Millisecond))
+}